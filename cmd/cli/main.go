@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -18,7 +19,7 @@ func main() {
 		fmt.Printf("Usage: acai-cli [command] [options]\n")
 		fmt.Println("Commands:")
 		fmt.Println("  ask        Create a new conversation with assistant or continue an existing one")
-		fmt.Println("  list       List existing conversations")
+		fmt.Println("  list       List existing conversations, optionally filtered with -tag and -folder")
 		fmt.Println("  show       Show conversation by ID")
 	}
 
@@ -39,7 +40,7 @@ func main() {
 
 	switch os.Args[1] {
 	case "ask":
-		fmt.Println("Press CMD+C to exit.")
+		fmt.Println("Press CMD+C to exit. Type /attach <path> to send an image or PDF with your next message.")
 		fmt.Println()
 
 		cid := ""
@@ -65,6 +66,7 @@ func main() {
 		}
 
 		reader := bufio.NewReader(os.Stdin)
+		var attachments []*pb.AttachmentUpload
 
 		for {
 			fmt.Printf("USER:\n")
@@ -84,10 +86,25 @@ func main() {
 
 			fmt.Println()
 
+			// "/attach <path>" queues a file to send with the next message
+			if path, ok := strings.CutPrefix(message, "/attach "); ok {
+				upload, err := readAttachment(strings.TrimSpace(path))
+				if err != nil {
+					fmt.Printf("Error reading attachment: %v\n\n", err)
+					continue
+				}
+
+				attachments = append(attachments, upload)
+				fmt.Printf("Attached %s (%s), it will be sent with your next message.\n\n", upload.GetFilename(), upload.GetContentType())
+				continue
+			}
+
 			if cid == "" {
 				out, err := cli.StartConversation(ctx, &pb.StartConversationRequest{
-					Message: message,
+					Message:     message,
+					Attachments: attachments,
 				})
+				attachments = nil
 
 				if err != nil {
 					fmt.Printf("Error starting conversation: %v\n", err)
@@ -101,13 +118,16 @@ func main() {
 
 				cid = out.GetConversationId()
 				fmt.Printf("ASSISTANT:\n%s\n\n", out.GetReply())
+				printSuggestions(out.GetSuggestions())
 				continue
 			}
 
 			out, err := cli.ContinueConversation(ctx, &pb.ContinueConversationRequest{
 				ConversationId: cid,
 				Message:        message,
+				Attachments:    attachments,
 			})
+			attachments = nil
 
 			if err != nil {
 				fmt.Printf("Error continuing conversation: %v\n", err)
@@ -115,10 +135,17 @@ func main() {
 			}
 
 			fmt.Printf("ASSISTANT:\n%s\n\n", out.GetReply())
+			printSuggestions(out.GetSuggestions())
 		}
 
 	case "list":
-		resp, err := cli.ListConversations(ctx, &pb.ListConversationsRequest{})
+		fs := flag.NewFlagSet("list", flag.ExitOnError)
+		tag := fs.String("tag", "", "only list conversations with this tag")
+		folder := fs.String("folder", "", "only list conversations in this folder")
+		favorites := fs.Bool("favorites", false, "only list favorite conversations")
+		_ = fs.Parse(os.Args[2:])
+
+		resp, err := cli.ListConversations(ctx, &pb.ListConversationsRequest{Tag: *tag, Folder: *folder, Favorites: *favorites})
 		if err != nil {
 			fmt.Printf("Error listing conversations: %v\n", err)
 			os.Exit(1)
@@ -131,7 +158,22 @@ func main() {
 
 		fmt.Println("ID                         TITLE")
 		for _, conv := range resp.Conversations {
-			fmt.Printf("%s   %s\n", conv.GetId(), conv.GetTitle())
+			labels := conv.GetTags()
+			if conv.GetFolder() != "" {
+				labels = append([]string{conv.GetFolder() + "/"}, labels...)
+			}
+			if conv.GetFavorite() {
+				labels = append([]string{"favorite"}, labels...)
+			}
+			if conv.GetPinnedAt() != nil {
+				labels = append([]string{"pinned"}, labels...)
+			}
+
+			if len(labels) == 0 {
+				fmt.Printf("%s   %s\n", conv.GetId(), conv.GetTitle())
+			} else {
+				fmt.Printf("%s   %s [%s]\n", conv.GetId(), conv.GetTitle(), strings.Join(labels, ", "))
+			}
 		}
 	case "show":
 		if len(os.Args) < 3 {
@@ -157,3 +199,28 @@ func main() {
 		}
 	}
 }
+
+func readAttachment(path string) (*pb.AttachmentUpload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AttachmentUpload{
+		Filename:    filepath.Base(path),
+		ContentType: http.DetectContentType(data),
+		Data:        data,
+	}, nil
+}
+
+func printSuggestions(suggestions []string) {
+	if len(suggestions) == 0 {
+		return
+	}
+
+	fmt.Println("You could ask:")
+	for _, s := range suggestions {
+		fmt.Println("  -", s)
+	}
+	fmt.Println()
+}
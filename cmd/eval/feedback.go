@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant/eval"
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant/eval/fixture"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/mongox"
+)
+
+// runFeedback drafts test cases from the replies users rated, for review before they
+// join a dataset.
+func runFeedback(args []string) {
+	fs := flag.NewFlagSet("feedback", flag.ExitOnError)
+	var (
+		rating      = fs.String("rating", model.RatingDown, "Rating of the replies to draft test cases from: up or down")
+		days        = fs.Int("days", 7, "Only replies rated in the last N days")
+		outputPath  = fs.String("output", "", "Path to save the drafted test cases (optional, auto-generated if not provided)")
+		datasetPath = fs.String("dataset", "", "Dataset the cases are meant for: replies already in it are skipped (optional)")
+		limit       = fs.Int("limit", 0, "Draft at most N test cases, most recent conversations first (0 = all)")
+		noSuggest   = fs.Bool("no-suggest", false, "Leave the expectations empty instead of asking GPT-5 to suggest them")
+		verbose     = fs.Bool("v", false, "Verbose logging")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s feedback [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Draft regression test cases from rated replies stored in MongoDB (MONGODB_URI, MONGODB_DATABASE).\n")
+		fmt.Fprintf(os.Stderr, "The cases are written as a dataset to review, then merge into the dataset they belong to.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  # Draft cases from last week's thumbs-down replies not yet in the multi-turn dataset:\n")
+		fmt.Fprintf(os.Stderr, "  %s feedback -dataset eval_datasets/multi_turn.json\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Then run the reviewed cases:\n")
+		fmt.Fprintf(os.Stderr, "  %s -dataset eval_review/feedback_20250601_120000.json -mock-tools\n\n", os.Args[0])
+	}
+	_ = fs.Parse(args)
+
+	setupLogging(*verbose)
+	ctx := context.Background()
+
+	if *rating != model.RatingUp && *rating != model.RatingDown {
+		slog.Error("Invalid -rating value", "value", *rating)
+		os.Exit(1)
+	}
+
+	var known []eval.TestCase
+	if *datasetPath != "" {
+		var err error
+		known, err = eval.LoadDataset(*datasetPath)
+		if err != nil {
+			slog.Error("Failed to load dataset", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	repo := model.New(mongox.MustConnect())
+	since := time.Now().AddDate(0, 0, -*days)
+	conversations, err := repo.ListRated(ctx, *rating, since)
+	if err != nil {
+		slog.Error("Failed to list rated conversations", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Loaded rated conversations", "count", len(conversations), "rating", *rating, "since", since.Format(time.DateOnly))
+
+	// The recorded tools are the ones the reply evaluations can run
+	tools := fixture.Registry(&model.Conversation{}).List()
+	slices.Sort(tools)
+	drafter := eval.NewDrafter(tools)
+
+	var drafted []eval.TestCase
+drafting:
+	for _, conv := range conversations {
+		for _, m := range conv.Messages {
+			if *limit > 0 && len(drafted) >= *limit {
+				break drafting
+			}
+			if m.Feedback == nil || m.Feedback.Rating != *rating || m.Feedback.CreatedAt.Before(since) {
+				continue
+			}
+			if slices.ContainsFunc(known, func(tc eval.TestCase) bool { return tc.ID == "feedback-"+m.ID.Hex() }) {
+				slog.Debug("Skipping reply already in the dataset", "message_id", m.ID.Hex())
+				continue
+			}
+
+			var tc eval.TestCase
+			if *noSuggest {
+				tc, err = eval.FromFeedback(conv, m)
+			} else {
+				tc, err = drafter.Draft(ctx, conv, m)
+				if err != nil && tc.ID != "" {
+					slog.Warn("Failed to suggest expectations, keeping the case without them", "test_case_id", tc.ID, "error", err)
+					err = nil
+				}
+			}
+			if err != nil {
+				slog.Warn("Skipping rated reply", "conversation_id", conv.ID.Hex(), "message_id", m.ID.Hex(), "error", err)
+				continue
+			}
+			drafted = append(drafted, tc)
+		}
+	}
+
+	if len(drafted) == 0 {
+		slog.Info("No new rated replies to draft test cases from")
+		return
+	}
+
+	outputFile := *outputPath
+	if outputFile == "" {
+		timestamp := time.Now().Format("20060102_150405")
+		outputFile = filepath.Join("eval_review", fmt.Sprintf("feedback_%s.json", timestamp))
+	}
+	if err := eval.SaveDataset(outputFile, drafted); err != nil {
+		slog.Error("Failed to save drafted test cases", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Drafted %d test cases for review: %s\n", len(drafted), outputFile)
+}
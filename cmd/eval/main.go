@@ -7,26 +7,54 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
 	"github.com/acai-travel/tech-challenge/internal/chat/assistant/eval"
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant/eval/fixture"
+	"github.com/acai-travel/tech-challenge/internal/logging"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "feedback" {
+		runFeedback(os.Args[2:])
+		return
+	}
+
 	var (
 		datasetPath = flag.String("dataset", "", "Path to test dataset JSON file (optional, uses default if not provided)")
 		outputPath  = flag.String("output", "", "Path to save evaluation report (optional, auto-generated if not provided)")
+		format      = flag.String("format", eval.FormatJSON, "Report format: "+strings.Join(eval.Formats, ", "))
+		weightsPath = flag.String("weights", "", "Path to a JSON file of rule evaluator scoring weights for the dataset (optional)")
 		useRuleOnly = flag.Bool("rule-only", false, "Use only rule-based evaluation (skip LLM judge)")
+		similarity  = flag.Float64("similarity", 0, "Also score the embedding similarity of titles with their message, failing below this threshold (e.g. 0.5; 0 = off)")
 		useLLMOnly  = flag.Bool("llm-only", false, "Use only LLM-as-judge evaluation (skip rule-based)")
 		saveDataset = flag.String("save-dataset", "", "Save default dataset to file and exit")
+		adversarial = flag.Bool("adversarial", false, "Add jailbreak, prompt-injection and encoding-attack variants of every test case")
 		verbose     = flag.Bool("v", false, "Verbose logging")
 		limitTests  = flag.Int("limit", 0, "Limit number of tests to run (0 = run all, useful for quick iteration)")
+		repeat      = flag.Int("repeat", 1, "Generate N titles per test case and report how much they vary")
+		toolChoice  = flag.String("tool-choice", "", "Tool choice for replies: auto, none, required or a tool name (default: chosen by intent)")
+		parallel    = flag.String("parallel-tools", "", "Allow parallel tool calls in replies: true or false (default: model default)")
+		mockTools   = flag.Bool("mock-tools", false, "Answer tool calls with recorded responses instead of calling third-party APIs")
+		moderation  = flag.Bool("moderation", false, "Also check replies with the OpenAI moderation model (default: rule lists only)")
+		cacheDir    = flag.String("cache", "", "Directory of cached generations: reuse the outputs of earlier runs of the same assistant and only rerun evaluation")
+		minPassRate = flag.Float64("min-pass-rate", 0, "Quality gate: minimum share of passed cases, 0-1 (replaces failing on any failed case)")
+		minScore    = flag.Float64("min-score", 0, "Quality gate: minimum average score, 0-1")
+		minCategory = flag.String("min-category", "", "Quality gate: minimum pass rate per category, e.g. weather=0.9,travel=0.8")
+		baseline    = flag.String("baseline", "", "Quality gate: JSON report to compare with, failing on more than -max-regressions newly failed cases")
+		maxRegress  = flag.Int("max-regressions", 0, "Quality gate: maximum cases passing in -baseline that fail now")
+		goldenDir   = flag.String("golden", "", "Directory of golden snapshots to compare outputs with (unchanged cases reuse their evaluation)")
+		update      = flag.Bool("update-golden", false, "Rewrite the golden snapshots with the current outputs (default directory: eval_golden)")
 	)
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Run title generation evaluations for the AI assistant.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s feedback [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Run title generation evaluations for the AI assistant, or draft test cases from rated replies.\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
@@ -36,29 +64,38 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s -dataset my_tests.json\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  # Run rule-based evaluation only (fast, no API calls):\n")
 		fmt.Fprintf(os.Stderr, "  %s -rule-only\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Catch off-topic titles with embeddings:\n")
+		fmt.Fprintf(os.Stderr, "  %s -rule-only -similarity %.1f\n\n", os.Args[0], eval.DefaultSimilarityThreshold)
 		fmt.Fprintf(os.Stderr, "  # Test first 3 cases with LLM judge (quick iteration):\n")
 		fmt.Fprintf(os.Stderr, "  %s -limit 3\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Find test cases with unstable titles:\n")
+		fmt.Fprintf(os.Stderr, "  %s -rule-only -repeat 5\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Red-team the first 2 cases with injected instructions:\n")
+		fmt.Fprintf(os.Stderr, "  %s -limit 2 -adversarial\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Evaluate multi-turn replies with recorded tool responses:\n")
+		fmt.Fprintf(os.Stderr, "  %s -dataset eval_datasets/multi_turn.json -mock-tools\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Iterate on evaluators without regenerating outputs:\n")
+		fmt.Fprintf(os.Stderr, "  %s -cache eval_cache\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Review output changes against golden snapshots, then accept them:\n")
+		fmt.Fprintf(os.Stderr, "  %s -golden eval_golden\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -update-golden\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Gate CI on pass rates and regressions instead of any failure:\n")
+		fmt.Fprintf(os.Stderr, "  %s -rule-only -min-pass-rate 0.9 -min-category weather=1 -baseline eval_results/main.json\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Save a JUnit XML report for CI:\n")
+		fmt.Fprintf(os.Stderr, "  %s -format junit -output eval_results/junit.xml\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  # Save default dataset to file:\n")
 		fmt.Fprintf(os.Stderr, "  %s -save-dataset dataset.json\n\n", os.Args[0])
 	}
 
 	flag.Parse()
 
-	// Configure logging
-	logLevel := slog.LevelInfo
-	if *verbose {
-		logLevel = slog.LevelDebug
-	}
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
-	slog.SetDefault(logger)
+	setupLogging(*verbose)
 
 	ctx := context.Background()
 
 	// Handle save-dataset command
 	if *saveDataset != "" {
-		if err := saveDefaultDataset(*saveDataset); err != nil {
+		if err := saveDefaultDataset(*saveDataset, *adversarial); err != nil {
 			slog.Error("Failed to save dataset", "error", err)
 			os.Exit(1)
 		}
@@ -72,6 +109,10 @@ func main() {
 		flag.Usage()
 		os.Exit(1)
 	}
+	if !slices.Contains(eval.Formats, *format) {
+		slog.Error("Invalid -format value", "value", *format, "formats", eval.Formats)
+		os.Exit(1)
+	}
 
 	// Load test cases
 	var testCases []eval.TestCase
@@ -97,7 +138,45 @@ func main() {
 		slog.Info("Limited test cases for quick iteration", "running", len(testCases))
 	}
 
+	if *adversarial {
+		variants := eval.GenerateAdversarial(testCases)
+		testCases = append(testCases, variants...)
+		slog.Info("Added adversarial variants", "variants", len(variants), "attacks", len(eval.Attacks))
+	}
+
+	// Quality gate policy
+	policy := eval.Policy{
+		MinPassRate:     *minPassRate,
+		MinAverageScore: *minScore,
+		MaxRegressions:  *maxRegress,
+	}
+	if *minCategory != "" {
+		policy.MinCategoryRate, err = parseCategoryMinimums(*minCategory)
+		if err != nil {
+			slog.Error("Invalid -min-category value", "error", err)
+			os.Exit(1)
+		}
+	}
+	if *baseline != "" {
+		policy.Baseline, err = eval.LoadReport(*baseline)
+		if err != nil {
+			slog.Error("Failed to load baseline report", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Create evaluators
+	var ruleOpts []eval.RuleOption
+	if *weightsPath != "" {
+		weights, err := eval.LoadScoringWeights(*weightsPath)
+		if err != nil {
+			slog.Error("Failed to load scoring weights", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Using scoring weights", "path", *weightsPath)
+		ruleOpts = append(ruleOpts, eval.WithScoringWeights(weights))
+	}
+
 	var evaluators []eval.Evaluator
 
 	if *useLLMOnly {
@@ -105,20 +184,60 @@ func main() {
 		evaluators = []eval.Evaluator{eval.NewLLMEvaluator()}
 	} else if *useRuleOnly {
 		slog.Info("Using rule-based evaluator only")
-		evaluators = []eval.Evaluator{eval.NewRuleEvaluator()}
+		evaluators = []eval.Evaluator{eval.NewRuleEvaluator(ruleOpts...)}
 	} else {
 		slog.Info("Using both rule-based and LLM-as-judge evaluators")
 		evaluators = []eval.Evaluator{
-			eval.NewRuleEvaluator(),
+			eval.NewRuleEvaluator(ruleOpts...),
 			eval.NewLLMEvaluator(),
 		}
 	}
+	if *similarity > 0 {
+		slog.Info("Using embedding similarity evaluator", "threshold", *similarity)
+		evaluators = append(evaluators, eval.NewSimilarityEvaluator(*similarity))
+	}
 
 	// Create assistant
-	asst := assistant.New()
+	var opts []assistant.Option
+	if *toolChoice != "" {
+		opts = append(opts, assistant.WithToolChoice(*toolChoice))
+	}
+	if *parallel != "" {
+		enabled, err := strconv.ParseBool(*parallel)
+		if err != nil {
+			slog.Error("Invalid -parallel-tools value", "value", *parallel)
+			os.Exit(1)
+		}
+		opts = append(opts, assistant.WithParallelToolCalls(enabled))
+	}
+	asst := assistant.New(opts...)
 
 	// Create runner
-	runner := eval.NewRunner(asst, evaluators)
+	var runnerOpts []eval.RunnerOption
+	if *mockTools {
+		slog.Info("Using recorded tool responses")
+		runnerOpts = append(runnerOpts, eval.WithToolRegistry(fixture.Registry, opts...))
+	}
+	if *cacheDir != "" {
+		slog.Info("Reusing cached generations", "dir", *cacheDir)
+		runnerOpts = append(runnerOpts, eval.WithGenerationCache(eval.NewGenerationCache(*cacheDir)))
+	}
+	if *moderation {
+		slog.Info("Checking replies with the moderation model")
+		runnerOpts = append(runnerOpts, eval.WithSafety(eval.NewSafetyEvaluator(eval.WithModeration())))
+	}
+	if *repeat > 1 {
+		slog.Info("Repeating title generation", "runs", *repeat)
+		runnerOpts = append(runnerOpts, eval.WithRepeat(*repeat))
+	}
+	if *update && *goldenDir == "" {
+		*goldenDir = "eval_golden"
+	}
+	if *goldenDir != "" {
+		slog.Info("Comparing with golden snapshots", "dir", *goldenDir, "update", *update)
+		runnerOpts = append(runnerOpts, eval.WithGolden(eval.NewGolden(*goldenDir, *update)))
+	}
+	runner := eval.NewRunner(asst, evaluators, runnerOpts...)
 
 	// Run evaluation
 	slog.Info("Starting evaluation run")
@@ -136,12 +255,12 @@ func main() {
 	if outputFile == "" {
 		// Auto-generate filename with timestamp
 		timestamp := time.Now().Format("20060102_150405")
-		outputFile = filepath.Join("eval_results", fmt.Sprintf("title_generation_%s.json", timestamp))
+		outputFile = filepath.Join("eval_results", fmt.Sprintf("title_generation_%s%s", timestamp, eval.FormatExtension(*format)))
 	}
 
 	// Save report
-	slog.Info("Saving evaluation report", "path", outputFile)
-	if err := eval.SaveReport(outputFile, *report); err != nil {
+	slog.Info("Saving evaluation report", "path", outputFile, "format", *format)
+	if err := eval.SaveReportAs(outputFile, *report, *format); err != nil {
 		slog.Error("Failed to save report", "error", err)
 		os.Exit(1)
 	}
@@ -152,13 +271,58 @@ func main() {
 	fmt.Println()
 	fmt.Printf("Full report saved to: %s\n", outputFile)
 
-	// Exit with error code if tests failed
+	// With a quality gate, exit with an error code if the run violates it, otherwise
+	// if any test failed
+	if *minPassRate > 0 || *minScore > 0 || *minCategory != "" || *baseline != "" {
+		violations := policy.Check(report)
+		if len(violations) > 0 {
+			fmt.Println("\nQuality gate failed:")
+			for _, v := range violations {
+				fmt.Printf("  - %s\n", v)
+			}
+			os.Exit(1)
+		}
+		fmt.Println("\nQuality gate passed")
+		return
+	}
 	if report.FailedTests > 0 {
 		os.Exit(1)
 	}
 }
 
-func saveDefaultDataset(path string) error {
+// setupLogging logs to stdout, with debug logs when verbose
+func setupLogging(verbose bool) {
+	logLevel := slog.LevelInfo
+	if verbose {
+		logLevel = slog.LevelDebug
+	}
+	logger := slog.New(logging.NewHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logLevel,
+	})))
+	slog.SetDefault(logger)
+}
+
+// parseCategoryMinimums parses a comma-separated list of category=rate pairs
+func parseCategoryMinimums(s string) (map[string]float64, error) {
+	minimums := make(map[string]float64)
+	for _, pair := range strings.Split(s, ",") {
+		category, rate, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || category == "" {
+			return nil, fmt.Errorf("%q is not category=rate", pair)
+		}
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil || r < 0 || r > 1 {
+			return nil, fmt.Errorf("rate of %s must be between 0 and 1, got %q", category, rate)
+		}
+		minimums[category] = r
+	}
+	return minimums, nil
+}
+
+func saveDefaultDataset(path string, adversarial bool) error {
 	testCases := eval.GetDefaultDataset()
+	if adversarial {
+		testCases = append(testCases, eval.GenerateAdversarial(testCases)...)
+	}
 	return eval.SaveDataset(path, testCases)
 }
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant/eval/fixture"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/acai-travel/tech-challenge/internal/mongox"
+	"github.com/acai-travel/tech-challenge/internal/tenant"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"github.com/acai-travel/tech-challenge/internal/vcr"
+)
+
+func main() {
+	var (
+		messageID  = flag.String("message", "", "ID of the reply to replay (default: the last reply of the conversation)")
+		tenantID   = flag.String("tenant", "", "Tenant of the conversation, from TENANTS_FILE: its database, reply model and instructions")
+		replyModel = flag.String("model", "", "Reply with this model instead of the configured one")
+		mockTools  = flag.Bool("mock-tools", false, "Answer tool calls with recorded responses instead of calling third-party APIs")
+		recordings = flag.String("recordings", "", "JSON file of recorded tool responses replacing the built-in ones of its tools (implies -mock-tools)")
+		cassette   = flag.String("cassette", "", "Replay the third-party API calls of the tools from this cassette (see -record)")
+		record     = flag.Bool("record", false, "Call the third-party APIs and record their calls into -cassette, redacting credentials")
+		noTools    = flag.Bool("no-tools", false, "Reply without tools, as when OpenAI tool calls are disabled")
+		exitCode   = flag.Bool("exit-code", false, "Exit with status 1 when the replayed reply differs from the stored one")
+		verbose    = flag.Bool("v", false, "Verbose logging")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <conversation id>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Replay a reply of a conversation stored in MongoDB (MONGODB_URI, MONGODB_DATABASE) with the current\n")
+		fmt.Fprintf(os.Stderr, "prompts and tools, and diff the new reply against the stored one. Nothing is written to the database.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  # Replay the last reply with the real tools:\n")
+		fmt.Fprintf(os.Stderr, "  %s 665f1c2e8a9b4d0012345678\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Replay an earlier reply of a tenant's conversation with recorded tool responses:\n")
+		fmt.Fprintf(os.Stderr, "  %s -tenant nordic -message 665f1c3a8a9b4d0012345679 -mock-tools 665f1c2e8a9b4d0012345678\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Check a reply against a tool response that broke it:\n")
+		fmt.Fprintf(os.Stderr, "  %s -recordings weather_outage.json 665f1c2e8a9b4d0012345678\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Record the API calls of a replay once, then replay it without API keys:\n")
+		fmt.Fprintf(os.Stderr, "  %s -cassette cassettes/lisbon.json -record 665f1c2e8a9b4d0012345678\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -cassette cassettes/lisbon.json 665f1c2e8a9b4d0012345678\n", os.Args[0])
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	setupLogging(*verbose)
+	ctx := context.Background()
+
+	db := mongox.MustConnect()
+	var opts []assistant.Option
+	if *tenantID != "" {
+		tenants, err := tenant.LoadFromEnv()
+		if err != nil {
+			slog.Error("Invalid tenants configuration", "error", err)
+			os.Exit(1)
+		}
+		i := slices.IndexFunc(tenants, func(cfg tenant.Config) bool { return cfg.ID == *tenantID })
+		if i < 0 {
+			slog.Error("Unknown tenant, check TENANTS_FILE", "tenant_id", *tenantID)
+			os.Exit(1)
+		}
+		cfg := tenants[i]
+		db = db.Client().Database(cfg.DatabaseName(db.Name()))
+		if cfg.Amadeus != nil {
+			opts = append(opts, assistant.WithToolMiddleware(tools.WithAmadeusCredentials(*cfg.Amadeus)))
+		}
+		if cfg.ReplyModel != "" {
+			opts = append(opts, assistant.WithReplyModel(cfg.ReplyModel))
+		}
+		if cfg.Instructions != "" {
+			opts = append(opts, assistant.WithInstructions(cfg.Instructions))
+		}
+	}
+	if *replyModel != "" {
+		opts = append(opts, assistant.WithReplyModel(*replyModel))
+	}
+	if *record && *cassette == "" {
+		slog.Error("-record needs a -cassette to record into")
+		os.Exit(2)
+	}
+	if *cassette != "" && (*mockTools || *recordings != "") {
+		slog.Error("-cassette replays the calls of the real tools, it can't be used with recorded tool responses")
+		os.Exit(2)
+	}
+	var tape *vcr.Cassette
+	if *cassette != "" {
+		mode := vcr.ModeReplay
+		if *record {
+			mode = vcr.ModeRecord
+		} else {
+			// The tools check that their keys are set before calling APIs that aren't called
+			for _, name := range []string{"WEATHER_API_KEY", "AMADEUS_API_KEY", "AMADEUS_API_SECRET"} {
+				if os.Getenv(name) == "" {
+					os.Setenv(name, vcr.Redacted)
+				}
+			}
+		}
+		var err error
+		if tape, err = vcr.Open(*cassette, mode); err != nil {
+			slog.Error("Failed to open cassette", "error", err)
+			os.Exit(1)
+		}
+		opts = append(opts, assistant.WithToolMiddleware(tools.WithTransport(tape)))
+	}
+
+	conv, err := model.New(db).DescribeConversation(ctx, flag.Arg(0))
+	if err != nil {
+		slog.Error("Failed to load conversation", "conversation_id", flag.Arg(0), "error", err)
+		os.Exit(1)
+	}
+	stored, err := truncate(conv, *messageID)
+	if err != nil {
+		slog.Error("Nothing to replay", "error", err)
+		os.Exit(1)
+	}
+	question := conv.Messages[len(conv.Messages)-1]
+	storedTools := question.ToolCalls
+	conv.ToolsDisabled = conv.ToolsDisabled || *noTools
+
+	var asst *assistant.Assistant
+	switch {
+	case *recordings != "":
+		r, err := fixture.LoadRecordings(*recordings)
+		if err != nil {
+			slog.Error("Failed to load recordings", "error", err)
+			os.Exit(1)
+		}
+		asst = assistant.NewWithRegistryFactory(r.Registry, opts...)
+	case *mockTools:
+		asst = assistant.NewWithRegistryFactory(fixture.Registry, opts...)
+	default:
+		asst = assistant.New(opts...)
+	}
+
+	fmt.Printf("Conversation %s: %q\n", conv.ID.Hex(), conv.Title)
+	fmt.Printf("Replaying reply %s to %q\n\n", stored.ID.Hex(), question.Content)
+
+	reply, err := asst.Reply(ctx, conv)
+	if err != nil {
+		slog.Error("Failed to replay reply", "error", err)
+		os.Exit(1)
+	}
+	if tape != nil && tape.Mode() == vcr.ModeRecord {
+		if err := tape.Save(); err != nil {
+			slog.Error("Failed to save cassette", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Recorded API calls", "cassette", *cassette, "interactions", len(tape.Interactions()))
+	}
+
+	fmt.Printf("Tools: stored %s, replayed %s\n", toolList(storedTools), toolList(question.ToolCalls))
+	if reply == stored.Content {
+		fmt.Println("Reply unchanged.")
+		return
+	}
+	fmt.Println("--- stored")
+	fmt.Println("+++ replayed")
+	fmt.Print(diff(stored.Content, reply))
+	if *exitCode {
+		os.Exit(1)
+	}
+}
+
+// truncate removes the reply to replay from conv, and everything after it, leaving the
+// user messages it answered last. The reply is the message messageID, or the last
+// reply of the conversation when empty.
+func truncate(conv *model.Conversation, messageID string) (*model.Message, error) {
+	i := len(conv.Messages) - 1
+	for ; i >= 0; i-- {
+		m := conv.Messages[i]
+		if messageID == "" && m.Role == model.RoleAssistant && m.State() == model.MessageStatusCompleted {
+			break
+		}
+		if messageID != "" && m.ID.Hex() == messageID {
+			break
+		}
+	}
+	if i < 0 {
+		if messageID != "" {
+			return nil, fmt.Errorf("message %s is not part of conversation %s", messageID, conv.ID.Hex())
+		}
+		return nil, fmt.Errorf("conversation %s has no reply", conv.ID.Hex())
+	}
+
+	reply := conv.Messages[i]
+	if reply.Role != model.RoleAssistant {
+		return nil, fmt.Errorf("message %s is not a reply", reply.ID.Hex())
+	}
+	if i == 0 || conv.Messages[i-1].Role != model.RoleUser {
+		return nil, fmt.Errorf("reply %s answers no user message", reply.ID.Hex())
+	}
+	conv.Messages = conv.Messages[:i]
+	return reply, nil
+}
+
+// toolList lists the tools called to answer a message.
+func toolList(calls []string) string {
+	if len(calls) == 0 {
+		return "(none)"
+	}
+	return "[" + strings.Join(calls, ", ") + "]"
+}
+
+func setupLogging(verbose bool) {
+	logLevel := slog.LevelInfo
+	if verbose {
+		logLevel = slog.LevelDebug
+	}
+	// The diff goes to stdout, the logs to stderr
+	logger := slog.New(logging.NewHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: logLevel,
+	})))
+	slog.SetDefault(logger)
+}
@@ -7,22 +7,58 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"github.com/acai-travel/tech-challenge/internal/analytics"
+	"github.com/acai-travel/tech-challenge/internal/apikey"
+	"github.com/acai-travel/tech-challenge/internal/attachment"
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/budget"
 	"github.com/acai-travel/tech-challenge/internal/chat"
 	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/checkpoint"
+	"github.com/acai-travel/tech-challenge/internal/gcal"
 	"github.com/acai-travel/tech-challenge/internal/httpx"
+	"github.com/acai-travel/tech-challenge/internal/idle"
+	"github.com/acai-travel/tech-challenge/internal/inbound"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/acai-travel/tech-challenge/internal/jobs"
+	"github.com/acai-travel/tech-challenge/internal/lockx"
+	"github.com/acai-travel/tech-challenge/internal/logging"
 	"github.com/acai-travel/tech-challenge/internal/mongox"
+	"github.com/acai-travel/tech-challenge/internal/notify"
+	"github.com/acai-travel/tech-challenge/internal/openaix"
 	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/profile"
+	"github.com/acai-travel/tech-challenge/internal/progress"
+	"github.com/acai-travel/tech-challenge/internal/quickstart"
+	"github.com/acai-travel/tech-challenge/internal/recall"
+	"github.com/acai-travel/tech-challenge/internal/reminder"
+	"github.com/acai-travel/tech-challenge/internal/review"
+	"github.com/acai-travel/tech-challenge/internal/sampling"
+	"github.com/acai-travel/tech-challenge/internal/sanitize"
+	"github.com/acai-travel/tech-challenge/internal/share"
+	"github.com/acai-travel/tech-challenge/internal/slack"
+	"github.com/acai-travel/tech-challenge/internal/slo"
+	"github.com/acai-travel/tech-challenge/internal/speech"
+	"github.com/acai-travel/tech-challenge/internal/status"
+	"github.com/acai-travel/tech-challenge/internal/takeout"
+	"github.com/acai-travel/tech-challenge/internal/tenant"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"github.com/acai-travel/tech-challenge/internal/whatsapp"
 	"github.com/gorilla/mux"
+	"github.com/openai/openai-go/v2"
 	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
@@ -40,8 +76,9 @@ func initResource() (*resource.Resource, error) {
 	)
 }
 
-// initMeterProvider initializes an OpenTelemetry MeterProvider with a stdout exporter
-func initMeterProvider() (*metric.MeterProvider, error) {
+// initMeterProvider initializes an OpenTelemetry MeterProvider with a stdout exporter.
+// The SLO monitor reads the same metrics from sloReader.
+func initMeterProvider(sloReader metric.Reader) (*metric.MeterProvider, error) {
 	// Create stdout exporter
 	exporter, err := stdoutmetric.New()
 	if err != nil {
@@ -55,11 +92,14 @@ func initMeterProvider() (*metric.MeterProvider, error) {
 	}
 
 	// Create a meter provider with a periodic reader
-	// The reader will export metrics every 10 seconds
+	// The reader will export metrics every 10 seconds. Measurements recorded within a
+	// sampled span keep its trace and span IDs as exemplars, linking metrics to traces.
 	meterProvider := metric.NewMeterProvider(
 		metric.WithReader(metric.NewPeriodicReader(exporter,
 			metric.WithInterval(10*time.Second))),
+		metric.WithReader(sloReader),
 		metric.WithResource(res),
+		metric.WithExemplarFilter(exemplar.TraceBasedFilter),
 	)
 
 	// Set the global meter provider
@@ -84,10 +124,17 @@ func initTracerProvider() (*sdktrace.TracerProvider, error) {
 		return nil, err
 	}
 
+	// Sample according to OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG
+	sampler, err := sampling.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a tracer provider with a batch span processor
 	tracerProvider := sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
 	)
 
 	// Set the global tracer provider
@@ -99,9 +146,25 @@ func initTracerProvider() (*sdktrace.TracerProvider, error) {
 	return tracerProvider, nil
 }
 
+// maxBodySize bounds request bodies, but for the methods carrying uploads.
+const maxBodySize = 1 << 20
+
+// uploadBodySizes bound the bodies of the methods carrying uploads, with room for the
+// largest uploads, base64-encoded in JSON requests.
+var uploadBodySizes = map[string]int64{
+	"StartConversation":    maxBodySize + attachment.MaxPerMessage*attachment.MaxSize*4/3,
+	"ContinueConversation": maxBodySize + attachment.MaxPerMessage*attachment.MaxSize*4/3,
+	"SendVoiceMessage":     maxBodySize + speech.MaxAudioSize*4/3,
+	"ImportUserData":       maxBodySize + takeout.MaxSize*4/3,
+}
+
 func main() {
+	// Add trace and conversation IDs from the context to every log line
+	slog.SetDefault(slog.New(logging.NewHandler(slog.NewTextHandler(os.Stderr, nil))))
+
 	// Initialize OpenTelemetry meter provider
-	meterProvider, err := initMeterProvider()
+	sloReader := metric.NewManualReader()
+	meterProvider, err := initMeterProvider(sloReader)
 	if err != nil {
 		slog.Error("Failed to initialize meter provider", "error", err)
 		panic(err)
@@ -126,32 +189,256 @@ func main() {
 		}
 	}()
 
-	mongo := mongox.MustConnect()
+	mongoConfig, err := mongox.ConfigFromEnv()
+	if err != nil {
+		slog.Error("Invalid Mongo configuration", "error", err)
+		panic(err)
+	}
+	db, err := mongox.Connect(mongoConfig)
+	if err != nil {
+		slog.Error("Failed to connect to Mongo", "error", err)
+		panic(err)
+	}
+
+	shared := deployment{
+		shareSigner: share.NewSignerFromEnv(),
+		publicURL:   os.Getenv("PUBLIC_BASE_URL"),
+		adminToken:  os.Getenv("ADMIN_TOKEN"),
+	}
+	if shared.publicURL == "" {
+		shared.publicURL = "http://localhost:8080"
+	}
+
+	if v := os.Getenv("TOOL_CHOICE"); v != "" {
+		shared.assistantOpts = append(shared.assistantOpts, assistant.WithToolChoice(v))
+	}
+	if v := os.Getenv("PARALLEL_TOOL_CALLS"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Error("Invalid PARALLEL_TOOL_CALLS value", "value", v)
+			panic(err)
+		}
+		shared.assistantOpts = append(shared.assistantOpts, assistant.WithParallelToolCalls(enabled))
+	}
+	if v := os.Getenv("HEALTH_REQUIREMENTS_TOOL"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Error("Invalid HEALTH_REQUIREMENTS_TOOL value", "value", v)
+			panic(err)
+		}
+		if enabled {
+			health := tools.NewStaticHealthProvider()
+			shared.assistantOpts = append(shared.assistantOpts, assistant.WithTools(func(*model.Conversation) tools.Tool {
+				return tools.NewGetHealthRequirementsTool(health)
+			}))
+		}
+	}
+	if v := os.Getenv("CONVERSATION_RECALL"); v != "" {
+		shared.recall, err = strconv.ParseBool(v)
+		if err != nil {
+			slog.Error("Invalid CONVERSATION_RECALL value", "value", v)
+			panic(err)
+		}
+	}
+	if v := os.Getenv("REPLY_TIMEOUT"); v != "" {
+		total, err := time.ParseDuration(v)
+		if err != nil {
+			slog.Error("Invalid REPLY_TIMEOUT value", "value", v)
+			panic(err)
+		}
+		shared.assistantOpts = append(shared.assistantOpts, assistant.WithBudget(assistant.Budget{Total: total}))
+	}
+
+	shared.idleWindow, err = idle.WindowFromEnv()
+	if err != nil {
+		slog.Error("Invalid IDLE_CLOSE_DAYS value", "error", err)
+		panic(err)
+	}
+
+	shared.schedules = make(map[string]jobs.Schedule)
+	for name, def := range map[string]string{"analytics": "@hourly", "idle": "30 * * * *"} {
+		shared.schedules[name], err = jobs.ScheduleFromEnv(name, def)
+		if err != nil {
+			slog.Error("Invalid job schedule", "job", name, "error", err)
+			panic(err)
+		}
+	}
+
+	shared.toolPolicy, err = tools.LoadPolicyFromEnv()
+	if err != nil {
+		slog.Error("Invalid tool policy", "error", err)
+		panic(err)
+	}
+
+	shared.replyPolicy, err = sanitize.NewPolicyFromEnv()
+	if err != nil {
+		slog.Error("Invalid link policy", "error", err)
+		panic(err)
+	}
+
+	shared.queueWorkers, err = chat.QueueWorkersFromEnv()
+	if err != nil {
+		slog.Error("Invalid REPLY_WORKERS value", "error", err)
+		panic(err)
+	}
+
+	shared.replyLockWait, err = chat.ReplyLockWaitFromEnv()
+	if err != nil {
+		slog.Error("Invalid REPLY_LOCK_WAIT value", "error", err)
+		panic(err)
+	}
+
+	shared.redisLocks, err = lockx.RedisFromEnv()
+	if err != nil {
+		slog.Error("Invalid REDIS_URL value", "error", err)
+		panic(err)
+	}
+
+	shared.googleCalendar, err = gcal.ConfigFromEnv()
+	if err != nil {
+		slog.Error("Invalid Google Calendar configuration", "error", err)
+		panic(err)
+	}
+
+	shared.inboundEmail, err = inbound.ConfigFromEnv()
+	if err != nil {
+		slog.Error("Invalid inbound email configuration", "error", err)
+		panic(err)
+	}
+
+	shared.whatsapp, err = whatsapp.ConfigFromEnv()
+	if err != nil {
+		slog.Error("Invalid WhatsApp configuration", "error", err)
+		panic(err)
+	}
+
+	shared.slack, err = slack.ConfigFromEnv()
+	if err != nil {
+		slog.Error("Invalid Slack configuration", "error", err)
+		panic(err)
+	}
+
+	sloConfig, err := slo.ConfigFromEnv()
+	if err != nil {
+		slog.Error("Invalid SLO configuration", "error", err)
+		panic(err)
+	}
+
+	// Every OpenAI client of every tenant shares the bound on calls in flight
+	maxInFlight, err := openaix.MaxInFlightFromEnv()
+	if err != nil {
+		slog.Error("Invalid OPENAI_MAX_IN_FLIGHT value", "error", err)
+		panic(err)
+	}
+	shared.openaiLimiter = openaix.NewLimiter(maxInFlight)
+	shared.assistantOpts = append(shared.assistantOpts, assistant.WithClientOptions(shared.openaiLimiter.Option()))
+	if v := os.Getenv("REPLY_FALLBACK_MODEL"); v != "" {
+		shared.assistantOpts = append(shared.assistantOpts, assistant.WithFallbackModel(v, shared.openaiLimiter.NearLimit))
+	}
+
+	// API keys are managed for every tenant in the default database
+	shared.apiKeys = apikey.NewRepository(db)
+	if err := shared.apiKeys.EnsureIndexes(context.Background()); err != nil {
+		slog.Warn("Failed to create API key indexes", "error", err)
+	}
+
+	tenants, err := tenant.LoadFromEnv()
+	if err != nil {
+		slog.Error("Invalid tenants configuration", "error", err)
+		panic(err)
+	}
+
+	// Deliver reminders, aggregate usage metrics and close idle conversations in the
+	// background until shutdown
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+
+	// Every server tracks the objectives on its own metrics, recording them with the
+	// default tenant's data
+	sloWindows := slo.NewRepository(db)
+	if err := sloWindows.EnsureIndexes(context.Background()); err != nil {
+		slog.Warn("Failed to create SLO window indexes", "error", err)
+	}
+	sloMonitor := slo.NewMonitor(sloConfig, sloReader, sloWindows)
+	go sloMonitor.Run(workerCtx)
 
-	repo := model.New(mongo)
-	assist := assistant.New()
+	// Every tenant gets its own stack on its own database. Requests without a tenant go
+	// to the default one, on MONGODB_DATABASE.
+	defaultApp := newApp(workerCtx, db, tenant.Config{}, shared)
+	apps := []*app{defaultApp}
+	tenantHandlers := make(map[string]http.Handler, len(tenants))
+	ingesters := map[string]*inbound.Ingester{"": defaultApp.ingester}
+	for _, cfg := range tenants {
+		a := newApp(workerCtx, db.Client().Database(cfg.DatabaseName(db.Name())), cfg, shared)
+		apps = append(apps, a)
+		tenantHandlers[cfg.ID] = a.handler
+		ingesters[cfg.ID] = a.ingester
+		slog.Info("Configured tenant", "tenant_id", cfg.ID, "database", cfg.DatabaseName(db.Name()))
+	}
+
+	// The status board reads the same metrics as the SLO monitor, and the reply queue
+	// of every tenant
+	queues := make(map[string]status.QueueDepth)
+	if defaultApp.queued != nil {
+		queues["replies"] = defaultApp.queued
+	}
+	for i, cfg := range tenants {
+		if q := apps[i+1].queued; q != nil {
+			queues["replies:"+cfg.ID] = q
+		}
+	}
+	statusBoard := status.NewBoard(sloReader, queues)
+	go statusBoard.Run(workerCtx)
 
-	server := chat.NewServer(repo, assist)
+	cors, err := httpx.NewCORSConfigFromEnv()
+	if err != nil {
+		slog.Error("Invalid CORS configuration", "error", err)
+		panic(err)
+	}
+	hstsMaxAge, err := httpx.HSTSMaxAgeFromEnv()
+	if err != nil {
+		slog.Error("Invalid HSTS_MAX_AGE value", "error", err)
+		panic(err)
+	}
 
 	// Configure handler
 	handler := mux.NewRouter()
 	handler.Use(
 		httpx.Tracing(), // Add tracing middleware (first to capture entire request)
+		httpx.CORS(cors),
+		httpx.SecurityHeaders(hstsMaxAge),
+		httpx.Identity(),
 		httpx.Logger(),
 		httpx.Recovery(),
 		httpx.Metrics(), // Add metrics middleware
+		httpx.Compress(),
+		httpx.NotModified(),
+		httpx.MaxBodySize(maxBodySize, uploadBodySizes),
 	)
 
 	handler.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		_, _ = fmt.Fprint(w, "Hi, my name is Clippy!")
 	})
-
-	handler.PathPrefix("/twirp/").Handler(pb.NewChatServiceServer(server, twirp.WithServerJSONSkipDefaults(true)))
+	// Key changes are audited with the default tenant's changes
+	keyStore := audit.APIKeys(shared.apiKeys, audit.NewRepository(db))
+	handler.PathPrefix("/admin/apikeys").Handler(http.StripPrefix("/admin/apikeys", apikey.Handler(shared.adminToken, keyStore)))
+	handler.Handle("/admin/slo", slo.Handler(shared.adminToken, sloMonitor))
+	handler.Handle("/internal/status", status.Handler(shared.adminToken, statusBoard))
+	// Forwarded emails name their tenant in their address
+	if shared.inboundEmail != nil {
+		handler.Handle("/inbound/email", inbound.Handler(shared.inboundEmail, ingesters))
+	}
+	handler.PathPrefix("/").Handler(tenant.Handler(tenantHandlers, defaultApp.handler))
 
 	// Create HTTP server with graceful shutdown support
 	httpServer := &http.Server{
-		Addr:    ":8080",
-		Handler: handler,
+		Addr:              ":8080",
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       2 * time.Minute, // Room for the largest uploads on slow connections
+		WriteTimeout:      6 * time.Minute, // Longer than the longest reply a client may wait for
+		IdleTimeout:       2 * time.Minute,
+		MaxHeaderBytes:    64 << 10,
 	}
 
 	// Channel to listen for shutdown signals
@@ -179,5 +466,313 @@ func main() {
 		slog.Error("Server shutdown error", "error", err)
 	}
 
+	// Stop the background workers and let in-flight notifications finish
+	stopWorker()
+	for _, a := range apps {
+		if a.whatsapp != nil {
+			a.whatsapp.Wait()
+		}
+		if a.slack != nil {
+			a.slack.Wait()
+		}
+		a.notifier.Wait()
+	}
+
 	slog.Info("Server stopped")
 }
+
+// deployment is the configuration shared by every tenant.
+type deployment struct {
+	shareSigner   *share.Signer
+	publicURL     string
+	adminToken    string
+	apiKeys       *apikey.Repository
+	assistantOpts []assistant.Option
+	idleWindow    time.Duration
+	// schedules of the background jobs, by job name
+	schedules     map[string]jobs.Schedule
+	replyPolicy   sanitize.Policy
+	queueWorkers  int
+	replyLockWait time.Duration
+	// redisLocks locks across the replicas of every tenant, if set, instead of the
+	// database of each tenant
+	redisLocks    *lockx.Redis
+	openaiLimiter *openaix.Limiter
+	// googleCalendar is the OAuth client of the Google Calendar integration, if enabled
+	googleCalendar *gcal.Config
+	// inboundEmail receives the booking confirmations forwarded by users, if enabled
+	inboundEmail *inbound.Config
+	// whatsapp is the WhatsApp Business number of the default tenant, if enabled
+	whatsapp *whatsapp.Config
+	// slack is the Slack app of the default tenant, if enabled
+	slack *slack.Config
+	// recall remembers closed conversations for later replies
+	recall bool
+	// toolPolicy restricts the tools of every tenant's users
+	toolPolicy *tools.Policy
+}
+
+// tick runs the tick of a worker as a job, its errors being logged by the worker.
+func tick(fn func(ctx context.Context)) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		fn(ctx)
+		return nil
+	}
+}
+
+// app is the service stack of a tenant.
+type app struct {
+	handler  http.Handler
+	notifier *notify.Notifier
+	// ingester ingests the emails forwarded to the tenant's trips, if enabled
+	ingester *inbound.Ingester
+	// whatsapp answers the tenant's WhatsApp users, if enabled
+	whatsapp *whatsapp.Bridge
+	// slack answers the tenant's Slack threads, if enabled
+	slack *slack.Bridge
+	// queued counts the tenant's queued replies, if replies are queued
+	queued status.QueueDepth
+}
+
+// newApp builds the stack of tenant cfg on db, and starts its background workers until
+// workerCtx is cancelled.
+func newApp(workerCtx context.Context, db *mongo.Database, cfg tenant.Config, shared deployment) *app {
+	repo := model.New(db)
+	if err := repo.EnsureMessageIndexes(context.Background()); err != nil {
+		slog.Warn("Failed to create message indexes", "tenant_id", cfg.ID, "error", err)
+	}
+	// Conversations stored with their messages embedded get them moved out in the
+	// background, being readable either way
+	go func() {
+		migrated, err := repo.MigrateMessages(workerCtx)
+		if err != nil {
+			slog.Error("Failed to migrate conversation messages", "tenant_id", cfg.ID, "error", err)
+			return
+		}
+		if migrated > 0 {
+			slog.Info("Migrated conversation messages", "tenant_id", cfg.ID, "conversations", migrated)
+		}
+	}()
+	webhooks := notify.NewRepository(db)
+	profiles := profile.NewRepository(db)
+	reminders := reminder.NewRepository(db)
+	budgets := budget.NewRepository(db)
+	itineraries := itinerary.NewRepository(db)
+	shares := share.NewRepository(db)
+	usage := analytics.NewRepository(db)
+
+	templates := quickstart.NewRepository(db)
+	if err := templates.Seed(context.Background(), quickstart.Defaults()); err != nil {
+		slog.Warn("Failed to seed conversation templates", "tenant_id", cfg.ID, "error", err)
+	}
+
+	channels := []notify.Channel{notify.NewSlackChannel()}
+	if email := notify.NewEmailChannelFromEnv(); email != nil {
+		channels = append(channels, email)
+	}
+	// Tenants bring their own WhatsApp number, the default tenant's being configured by
+	// the environment
+	wa := cfg.WhatsApp
+	if cfg.ID == "" {
+		wa = shared.whatsapp
+	}
+	var (
+		waClient  *whatsapp.Client
+		waThreads *whatsapp.Repository
+	)
+	if wa != nil {
+		waClient, waThreads = whatsapp.NewClient(wa), whatsapp.NewRepository(db)
+		channels = append(channels, whatsapp.NewChannel(wa, waClient, waThreads))
+	}
+	notifier := notify.NewNotifier(notify.NewDispatcher(webhooks), profiles, channels...)
+
+	attachments, err := attachment.NewGridFSStore(db)
+	if err != nil {
+		slog.Error("Failed to initialize attachment store", "tenant_id", cfg.ID, "error", err)
+		panic(err)
+	}
+
+	auditLog := audit.NewRepository(db)
+	if err := auditLog.EnsureIndexes(context.Background()); err != nil {
+		slog.Warn("Failed to create audit log indexes", "tenant_id", cfg.ID, "error", err)
+	}
+
+	// Only one replica replies in a conversation or runs a job of the tenant at a time
+	var locker lockx.Locker = shared.redisLocks
+	if shared.redisLocks == nil {
+		locks := lockx.NewMongo(db)
+		if err := locks.EnsureIndexes(context.Background()); err != nil {
+			slog.Warn("Failed to create lock indexes", "tenant_id", cfg.ID, "error", err)
+		}
+		locker = locks
+	}
+
+	reviews := review.NewRepository(db)
+	if err := reviews.EnsureIndexes(context.Background()); err != nil {
+		slog.Warn("Failed to create review queue indexes", "tenant_id", cfg.ID, "error", err)
+	}
+
+	checkpoints := checkpoint.NewRepository(db)
+	if err := checkpoints.EnsureIndexes(context.Background()); err != nil {
+		slog.Warn("Failed to create reply checkpoint indexes", "tenant_id", cfg.ID, "error", err)
+	}
+
+	// Browsers reach the tenant's shares and OAuth callbacks under its path, as they
+	// don't send X-Tenant-ID
+	baseURL := shared.publicURL
+	if cfg.ID != "" {
+		baseURL += tenant.PathPrefix + cfg.ID
+	}
+
+	assistantOpts := []assistant.Option{
+		assistant.WithAttachments(attachments),
+		assistant.WithCheckpoints(checkpoints),
+		assistant.WithTools(func(conv *model.Conversation) tools.Tool {
+			return tools.NewSetReminderTool(conv, reminders)
+		}),
+		assistant.WithTools(
+			func(conv *model.Conversation) tools.Tool { return tools.NewSetTripBudgetTool(conv, budgets) },
+			func(conv *model.Conversation) tools.Tool { return tools.NewAddExpenseTool(conv, budgets) },
+			func(conv *model.Conversation) tools.Tool { return tools.NewGetBudgetStatusTool(conv, budgets) },
+		),
+		assistant.WithTools(func(conv *model.Conversation) tools.Tool {
+			return tools.NewAddItineraryItemTool(conv, itineraries)
+		}),
+	}
+	var calendar *gcal.Client
+	if shared.googleCalendar != nil {
+		oauth := *shared.googleCalendar
+		oauth.RedirectURL = baseURL + gcal.CallbackPath
+		calendar = gcal.NewClient(&oauth, gcal.NewRepository(db))
+		assistantOpts = append(assistantOpts, assistant.WithTools(
+			func(conv *model.Conversation) tools.Tool { return tools.NewGetCalendarTripsTool(conv, calendar) },
+			func(conv *model.Conversation) tools.Tool {
+				return tools.NewAddItineraryToCalendarTool(conv, itineraries, calendar)
+			},
+		))
+	}
+	var ingester *inbound.Ingester
+	if shared.inboundEmail != nil {
+		receipts := inbound.NewRepository(db)
+		if err := receipts.EnsureIndexes(context.Background()); err != nil {
+			slog.Warn("Failed to create inbound email indexes", "tenant_id", cfg.ID, "error", err)
+		}
+		parser := inbound.NewOpenAIParser(openai.NewClient(shared.openaiLimiter.Option()))
+		ingester = inbound.NewIngester(repo, itineraries, parser, receipts, notifier)
+		assistantOpts = append(assistantOpts, assistant.WithTools(func(conv *model.Conversation) tools.Tool {
+			return tools.NewGetBookingEmailAddressTool(shared.inboundEmail.Address(cfg.ID, conv.ID))
+		}))
+	}
+	assistantOpts = append(assistantOpts, shared.assistantOpts...)
+	if cfg.Amadeus != nil {
+		assistantOpts = append(assistantOpts, assistant.WithToolMiddleware(tools.WithAmadeusCredentials(*cfg.Amadeus)))
+	}
+	if cfg.ReplyModel != "" {
+		assistantOpts = append(assistantOpts, assistant.WithReplyModel(cfg.ReplyModel))
+	}
+	if cfg.Instructions != "" {
+		assistantOpts = append(assistantOpts, assistant.WithInstructions(cfg.Instructions))
+	}
+	if policy := shared.toolPolicy.Merge(cfg.ToolPolicy); policy != nil {
+		assistantOpts = append(assistantOpts, assistant.WithToolPolicy(policy))
+	}
+	var memories *recall.Index
+	if shared.recall {
+		store := recall.NewRepository(db)
+		if err := store.EnsureIndexes(context.Background()); err != nil {
+			slog.Warn("Failed to create conversation memory indexes", "tenant_id", cfg.ID, "error", err)
+		}
+		memories = recall.NewIndex(store, recall.NewOpenAIEmbedder(openai.NewClient(shared.openaiLimiter.Option())))
+		assistantOpts = append(assistantOpts, assistant.WithRecall(memories))
+	}
+	assist := assistant.New(assistantOpts...)
+
+	go reminder.NewWorker(reminders, notifier).Run(workerCtx)
+
+	// Only the replica elected leader runs the scheduled jobs of the tenant
+	scheduled := []jobs.Job{{
+		Name:     "analytics",
+		Schedule: shared.schedules["analytics"],
+		Run:      tick(analytics.NewWorker(usage).Tick),
+	}}
+	if shared.idleWindow > 0 {
+		var idleOpts []idle.Option
+		if memories != nil {
+			idleOpts = append(idleOpts, idle.WithMemory(memories))
+		}
+		scheduled = append(scheduled, jobs.Job{
+			Name:     "idle",
+			Schedule: shared.schedules["idle"],
+			Run:      tick(idle.NewWorker(repo, assist, shared.idleWindow, idleOpts...).Tick),
+		})
+	}
+	go jobs.NewScheduler(jobs.NewRepository(db), locker, "jobs:leader:"+cfg.ID, scheduled...).Run(workerCtx)
+
+	replyProgress := progress.NewHub()
+
+	serverOpts := []chat.Option{
+		chat.WithWebhooks(webhooks, notifier),
+		chat.WithProfiles(profiles),
+		chat.WithSharing(shares, shared.shareSigner, baseURL),
+		chat.WithAttachments(attachments),
+		chat.WithTranscriber(speech.NewWhisperTranscriber(openai.NewClient(shared.openaiLimiter.Option()))),
+		chat.WithSynthesizer(speech.NewOpenAISynthesizer(openai.NewClient(shared.openaiLimiter.Option()))),
+		chat.WithFailureRecorder(usage),
+		chat.WithTemplates(templates),
+		chat.WithProgress(replyProgress),
+		chat.WithReplyPolicy(shared.replyPolicy),
+		chat.WithAudit(auditLog),
+		chat.WithReviewQueue(reviews),
+		chat.WithItineraries(itineraries),
+		chat.WithTakeout(itineraries, reminders),
+		chat.WithSummarizer(assist),
+		chat.WithBranding(cfg.DocumentBranding()),
+		chat.WithReplyLocks(chat.NewLeaseLocks(locker), shared.replyLockWait),
+	}
+	if shared.queueWorkers > 0 {
+		if err := repo.EnsureQueueIndex(context.Background()); err != nil {
+			slog.Warn("Failed to create reply queue index", "tenant_id", cfg.ID, "error", err)
+		}
+		serverOpts = append(serverOpts, chat.WithReplyQueue())
+	}
+	if memories != nil {
+		serverOpts = append(serverOpts, chat.WithMemory(memories))
+	}
+	server := chat.NewServer(repo, assist, serverOpts...)
+	var queued status.QueueDepth
+	if shared.queueWorkers > 0 {
+		go chat.NewQueueWorker(server, shared.queueWorkers).Run(workerCtx)
+		queued = repo.CountQueued
+	}
+
+	router := mux.NewRouter()
+	router.Handle("/admin/analytics", analytics.Handler(shared.adminToken, usage))
+	router.Handle("/admin/audit", audit.Handler(shared.adminToken, auditLog))
+	router.PathPrefix("/admin/templates").Handler(http.StripPrefix("/admin/templates", quickstart.Handler(shared.adminToken, audit.Templates(templates, auditLog))))
+	router.PathPrefix("/admin/reviews").Handler(http.StripPrefix("/admin/reviews", review.Handler(shared.adminToken, audit.Reviews(reviews, auditLog), repo)))
+	router.PathPrefix("/progress/").Handler(progress.Handler(replyProgress))
+	router.PathPrefix("/shared/").Handler(share.Handler(shared.shareSigner, shares, repo))
+	if calendar != nil {
+		router.PathPrefix("/calendar/google/").Handler(gcal.Handler(calendar))
+	}
+	var bridge *whatsapp.Bridge
+	if wa != nil {
+		bridge = whatsapp.NewBridge(waClient, server, waThreads, cfg.ID)
+		router.Handle(whatsapp.WebhookPath, whatsapp.Handler(wa, bridge))
+	}
+	sl := cfg.Slack
+	if cfg.ID == "" {
+		sl = shared.slack
+	}
+	var slackBridge *slack.Bridge
+	if sl != nil {
+		slackBridge = slack.NewBridge(slack.NewClient(sl), server, slack.NewRepository(db), replyProgress, cfg.ID)
+		slackHandler := slack.Handler(sl, slackBridge)
+		router.Handle(slack.EventsPath, slackHandler)
+		router.Handle(slack.CommandsPath, slackHandler)
+		router.Handle(slack.InteractionsPath, slackHandler)
+	}
+	router.PathPrefix("/twirp/").Handler(pb.NewChatServiceServer(server, twirp.WithServerJSONSkipDefaults(true)))
+
+	return &app{handler: httpx.APIKeys(shared.apiKeys, cfg.ID)(router), notifier: notifier, ingester: ingester, whatsapp: bridge, slack: slackBridge, queued: queued}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/acai-travel/tech-challenge/internal/mongox"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/telegram"
+)
+
+func main() {
+	slog.SetDefault(slog.New(logging.NewHandler(slog.NewTextHandler(os.Stderr, nil))))
+
+	cfg, err := telegram.ConfigFromEnv()
+	if err != nil {
+		slog.Error("Invalid Telegram configuration", "error", err)
+		panic(err)
+	}
+
+	// Replies with several tool calls take a while
+	chat := pb.NewChatServiceJSONClient(cfg.ChatURL, &http.Client{Timeout: 3 * time.Minute})
+	bot := telegram.NewBot(telegram.NewClient(cfg), chat, telegram.NewRepository(mongox.MustConnect()), telegram.NewProgressStream(cfg), cfg.TenantID)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("Telegram bot started", "chat_url", cfg.ChatURL, "tenant_id", cfg.TenantID)
+	if err := bot.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error("Telegram bot stopped", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Telegram bot stopped")
+}
@@ -0,0 +1,41 @@
+package analytics
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DayLayout formats the day a Daily row covers. Days are in UTC.
+const DayLayout = "2006-01-02"
+
+// Daily holds the usage metrics of one day.
+type Daily struct {
+	Day                  string           `bson:"_id" json:"day"`
+	ConversationsStarted int64            `bson:"conversations_started" json:"conversations_started"`
+	UserMessages         int64            `bson:"user_messages" json:"user_messages"`
+	AssistantMessages    int64            `bson:"assistant_messages" json:"assistant_messages"`
+	ToolCalls            map[string]int64 `bson:"tool_calls,omitempty" json:"tool_calls,omitempty"`
+	Replies              int64            `bson:"replies" json:"replies"`
+	AvgReplyLatencyMs    float64          `bson:"avg_reply_latency_ms" json:"avg_reply_latency_ms"`
+	FailedReplies        int64            `bson:"failed_replies" json:"failed_replies"`
+	// FailureRate is the share of reply attempts that failed, between 0 and 1.
+	FailureRate float64   `bson:"failure_rate" json:"failure_rate"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Failure records a reply the assistant could not generate. Failed replies are not
+// stored with the conversation, so they are kept apart to compute failure rates.
+type Failure struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	UserID         string             `bson:"user_id,omitempty"`
+	ConversationID primitive.ObjectID `bson:"conversation_id"`
+	Error          string             `bson:"error"`
+	CreatedAt      time.Time          `bson:"created_at"`
+}
+
+// dayStart truncates t to the start of its UTC day.
+func dayStart(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
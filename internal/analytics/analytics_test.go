@@ -0,0 +1,132 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	saved    map[string]*Daily
+	failDay  string
+	from, to time.Time
+}
+
+func (m *memoryStore) Aggregate(ctx context.Context, day time.Time) (*Daily, error) {
+	d := dayStart(day).Format(DayLayout)
+	if d == m.failDay {
+		return nil, errors.New("aggregation failed")
+	}
+	return &Daily{Day: d, Replies: 3}, nil
+}
+
+func (m *memoryStore) SaveDaily(ctx context.Context, d *Daily) error {
+	m.saved[d.Day] = d
+	return nil
+}
+
+func (m *memoryStore) ListDaily(ctx context.Context, from, to time.Time) ([]*Daily, error) {
+	m.from, m.to = from, to
+	return []*Daily{{Day: from.Format(DayLayout)}}, nil
+}
+
+func TestWorker_Tick(t *testing.T) {
+	now := time.Date(2025, 10, 18, 0, 30, 0, 0, time.UTC)
+
+	t.Run("aggregates the previous and current day", func(t *testing.T) {
+		store := &memoryStore{saved: map[string]*Daily{}}
+		w := NewWorker(store)
+		w.now = func() time.Time { return now }
+
+		w.Tick(context.Background())
+
+		for _, day := range []string{"2025-10-17", "2025-10-18"} {
+			d, ok := store.saved[day]
+			if !ok {
+				t.Fatalf("expected %s to be saved, got %v", day, store.saved)
+			}
+			if !d.UpdatedAt.Equal(now) {
+				t.Errorf("%s updated at %v, want %v", day, d.UpdatedAt, now)
+			}
+		}
+	})
+
+	t.Run("a failed day does not stop the others", func(t *testing.T) {
+		store := &memoryStore{saved: map[string]*Daily{}, failDay: "2025-10-17"}
+		w := NewWorker(store)
+		w.now = func() time.Time { return now }
+
+		w.Tick(context.Background())
+
+		if _, ok := store.saved["2025-10-18"]; !ok || len(store.saved) != 1 {
+			t.Errorf("expected only 2025-10-18 to be saved, got %v", store.saved)
+		}
+	})
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		auth       string
+		query      string
+		wantStatus int
+		wantFrom   string
+		wantTo     string
+	}{
+		{name: "disabled without token", auth: "Bearer secret", wantStatus: http.StatusNotFound},
+		{name: "missing credentials", token: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", auth: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "invalid day", token: "secret", auth: "Bearer secret", query: "from=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "reversed range", token: "secret", auth: "Bearer secret", query: "from=2025-10-18&to=2025-10-01", wantStatus: http.StatusBadRequest},
+		{
+			name: "explicit range", token: "secret", auth: "Bearer secret", query: "from=2025-10-01&to=2025-10-18",
+			wantStatus: http.StatusOK, wantFrom: "2025-10-01", wantTo: "2025-10-18",
+		},
+		{
+			name: "defaults to the 30 days before to", token: "secret", auth: "Bearer secret", query: "to=2025-10-30",
+			wantStatus: http.StatusOK, wantFrom: "2025-10-01", wantTo: "2025-10-30",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{}
+			req := httptest.NewRequest(http.MethodGet, "/admin/analytics?"+tt.query, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+
+			Handler(tt.token, store).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if got := store.from.Format(DayLayout); got != tt.wantFrom {
+				t.Errorf("from = %s, want %s", got, tt.wantFrom)
+			}
+			if got := store.to.Format(DayLayout); got != tt.wantTo {
+				t.Errorf("to = %s, want %s", got, tt.wantTo)
+			}
+
+			var body struct {
+				Days []Daily `json:"days"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if len(body.Days) != 1 {
+				t.Errorf("expected 1 day, got %d", len(body.Days))
+			}
+		})
+	}
+}
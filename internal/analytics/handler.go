@@ -0,0 +1,81 @@
+package analytics
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultRange is how many days the admin endpoint returns when no range is given.
+const defaultRange = 30
+
+// Reader lists stored daily metrics.
+type Reader interface {
+	ListDaily(ctx context.Context, from, to time.Time) ([]*Daily, error)
+}
+
+// Handler serves the stored daily metrics as JSON to admins, who authenticate with
+// "Authorization: Bearer <token>". The days are picked with the "from" and "to" query
+// parameters (YYYY-MM-DD, both included) and default to the last 30 days. An empty
+// token disables the endpoint.
+func Handler(token string, store Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := r.Context()
+		now := time.Now()
+
+		to, err := parseDay(r.URL.Query().Get("to"), now)
+		if err != nil {
+			http.Error(w, "Invalid to: expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		from, err := parseDay(r.URL.Query().Get("from"), to.AddDate(0, 0, 1-defaultRange))
+		if err != nil {
+			http.Error(w, "Invalid from: expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		if from.After(to) {
+			http.Error(w, "from must not be after to", http.StatusBadRequest)
+			return
+		}
+
+		days, err := store.ListDaily(ctx, from, to)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to list daily metrics", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"days": days})
+	})
+}
+
+func parseDay(v string, fallback time.Time) (time.Time, error) {
+	if v == "" {
+		return dayStart(fallback), nil
+	}
+	return time.Parse(DayLayout, v)
+}
@@ -0,0 +1,213 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName = "github.com/acai-travel/tech-challenge/internal/analytics"
+
+	conversationCollection = "conversations"
+	failureCollection      = "reply_failures"
+	dailyCollection        = "daily_metrics"
+)
+
+type Repository struct {
+	conn *mongo.Database
+	// replica serves the aggregations and dashboards, which tolerate stale data
+	replica *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn, replica: mongox.Replica(conn)}
+}
+
+// RecordFailure stores a failed reply.
+func (r *Repository) RecordFailure(ctx context.Context, f *Failure) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.RecordFailure")
+	span.SetAttributes(attribute.String("conversation.id", f.ConversationID.Hex()))
+	defer span.End()
+
+	if _, err := r.conn.Collection(failureCollection).InsertOne(ctx, f); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to record reply failure")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "reply failure recorded")
+	return nil
+}
+
+// dailyPipeline aggregates the messages, tool calls and reply latency of the
+// conversations active between from and to.
+func dailyPipeline(from, to time.Time) []bson.M {
+	inDay := bson.M{"$gte": from, "$lt": to}
+
+	pipeline := []bson.M{{"$match": bson.M{"created_at": bson.M{"$lt": to}, "updated_at": bson.M{"$gte": from}}}}
+	pipeline = append(pipeline, model.LookupMessages("role", "tool_calls", "created_at")...)
+	return append(pipeline, []bson.M{
+		{"$facet": bson.M{
+			"started": bson.A{
+				bson.M{"$match": bson.M{"created_at": inDay}},
+				bson.M{"$count": "n"},
+			},
+			"messages": bson.A{
+				bson.M{"$unwind": "$messages"},
+				bson.M{"$match": bson.M{"messages.created_at": inDay}},
+				bson.M{"$group": bson.M{"_id": "$messages.role", "n": bson.M{"$sum": 1}}},
+			},
+			"tools": bson.A{
+				bson.M{"$unwind": "$messages"},
+				bson.M{"$match": bson.M{"messages.created_at": inDay}},
+				bson.M{"$unwind": "$messages.tool_calls"},
+				bson.M{"$group": bson.M{"_id": "$messages.tool_calls", "n": bson.M{"$sum": 1}}},
+			},
+			// A reply's latency is the time between a user message and the assistant
+			// message that follows it.
+			"replies": bson.A{
+				bson.M{"$project": bson.M{"pairs": bson.M{"$zip": bson.M{"inputs": bson.A{
+					"$messages",
+					bson.M{"$slice": bson.A{"$messages", 1, bson.M{"$max": bson.A{bson.M{"$size": "$messages"}, 1}}}},
+				}}}}},
+				bson.M{"$unwind": "$pairs"},
+				bson.M{"$project": bson.M{
+					"question": bson.M{"$arrayElemAt": bson.A{"$pairs", 0}},
+					"answer":   bson.M{"$arrayElemAt": bson.A{"$pairs", 1}},
+				}},
+				bson.M{"$match": bson.M{"question.role": "user", "answer.role": "assistant", "answer.created_at": inDay}},
+				bson.M{"$group": bson.M{
+					"_id":     nil,
+					"n":       bson.M{"$sum": 1},
+					"latency": bson.M{"$avg": bson.M{"$subtract": bson.A{"$answer.created_at", "$question.created_at"}}},
+				}},
+			},
+		}},
+	}...)
+}
+
+type dailyCount struct {
+	ID      string  `bson:"_id"`
+	N       int64   `bson:"n"`
+	Latency float64 `bson:"latency"`
+}
+
+// Aggregate computes the metrics of the UTC day containing day.
+func (r *Repository) Aggregate(ctx context.Context, day time.Time) (*Daily, error) {
+	from := dayStart(day)
+	to := from.AddDate(0, 0, 1)
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.Aggregate")
+	span.SetAttributes(attribute.String("analytics.day", from.Format(DayLayout)))
+	defer span.End()
+
+	cursor, err := r.replica.Collection(conversationCollection).Aggregate(ctx, dailyPipeline(from, to))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to aggregate conversations")
+		return nil, err
+	}
+
+	var facets []struct {
+		Started  []dailyCount `bson:"started"`
+		Messages []dailyCount `bson:"messages"`
+		Tools    []dailyCount `bson:"tools"`
+		Replies  []dailyCount `bson:"replies"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode aggregation")
+		return nil, err
+	}
+
+	failed, err := r.replica.Collection(failureCollection).CountDocuments(ctx, bson.M{"created_at": bson.M{"$gte": from, "$lt": to}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count reply failures")
+		return nil, err
+	}
+
+	d := &Daily{Day: from.Format(DayLayout), FailedReplies: failed}
+	if len(facets) > 0 {
+		f := facets[0]
+		if len(f.Started) > 0 {
+			d.ConversationsStarted = f.Started[0].N
+		}
+		for _, m := range f.Messages {
+			switch m.ID {
+			case "user":
+				d.UserMessages = m.N
+			case "assistant":
+				d.AssistantMessages = m.N
+			}
+		}
+		for _, t := range f.Tools {
+			if d.ToolCalls == nil {
+				d.ToolCalls = make(map[string]int64)
+			}
+			d.ToolCalls[t.ID] = t.N
+		}
+		if len(f.Replies) > 0 {
+			d.Replies = f.Replies[0].N
+			d.AvgReplyLatencyMs = f.Replies[0].Latency
+		}
+	}
+	if attempts := d.Replies + d.FailedReplies; attempts > 0 {
+		d.FailureRate = float64(d.FailedReplies) / float64(attempts)
+	}
+
+	span.SetStatus(codes.Ok, "day aggregated")
+	return d, nil
+}
+
+// SaveDaily stores the metrics of a day, replacing any previous aggregation.
+func (r *Repository) SaveDaily(ctx context.Context, d *Daily) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SaveDaily")
+	span.SetAttributes(attribute.String("analytics.day", d.Day))
+	defer span.End()
+
+	_, err := r.conn.Collection(dailyCollection).ReplaceOne(ctx, bson.M{"_id": d.Day}, d, options.Replace().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to save daily metrics")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "daily metrics saved")
+	return nil
+}
+
+// ListDaily returns the stored metrics of the days between from and to, both
+// included, oldest first.
+func (r *Repository) ListDaily(ctx context.Context, from, to time.Time) ([]*Daily, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListDaily")
+	defer span.End()
+
+	filter := bson.M{"_id": bson.M{"$gte": from.UTC().Format(DayLayout), "$lte": to.UTC().Format(DayLayout)}}
+	cursor, err := r.replica.Collection(dailyCollection).Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list daily metrics")
+		return nil, err
+	}
+
+	days := []*Daily{}
+	if err := cursor.All(ctx, &days); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode daily metrics")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("analytics.days", len(days)))
+	span.SetStatus(codes.Ok, "daily metrics listed")
+	return days, nil
+}
@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Store computes and stores daily metrics.
+type Store interface {
+	Aggregate(ctx context.Context, day time.Time) (*Daily, error)
+	SaveDaily(ctx context.Context, d *Daily) error
+}
+
+// Worker periodically aggregates the usage metrics of the current day.
+type Worker struct {
+	store    Store
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewWorker(store Store) *Worker {
+	return &Worker{
+		store:    store,
+		interval: time.Hour,
+		now:      time.Now,
+	}
+}
+
+// Run aggregates metrics on every tick until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick aggregates the previous and the current day. Aggregating the previous day
+// again completes it with the activity that happened after the last tick before
+// midnight.
+func (w *Worker) Tick(ctx context.Context) {
+	now := w.now()
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+		if ctx.Err() != nil {
+			return
+		}
+
+		d, err := w.store.Aggregate(ctx, day)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to aggregate daily metrics", "day", dayStart(day).Format(DayLayout), "error", err)
+			continue
+		}
+
+		d.UpdatedAt = now
+		if err := w.store.SaveDaily(ctx, d); err != nil {
+			slog.ErrorContext(ctx, "Failed to save daily metrics", "day", d.Day, "error", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package apikey manages the API keys partners call the chat API with. Keys are stored
+// hashed, act as a user of a tenant, and are limited to scopes.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Prefix starts every API key, telling them apart from other bearer tokens.
+const Prefix = "acai_"
+
+// RotationGrace is how long the secret of a rotated key keeps working, for clients to
+// switch to the new one.
+const RotationGrace = 24 * time.Hour
+
+// Scopes of API keys.
+const (
+	// ScopeRead allows reading conversations, templates, profiles and reply progress.
+	ScopeRead = "read"
+	// ScopeWrite allows starting, continuing and changing conversations.
+	ScopeWrite = "write"
+	// ScopeWebhooks allows managing webhooks.
+	ScopeWebhooks = "webhooks"
+)
+
+// Scopes are all the scopes keys can have.
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeWebhooks}
+
+// methodScopes are the scopes of the API methods not requiring ScopeWrite.
+var methodScopes = map[string]string{
+	"ListConversationTemplates": ScopeRead,
+	"ListConversations":         ScopeRead,
+	"DescribeConversation":      ScopeRead,
+	"SyncConversation":          ScopeRead,
+	"GetProfile":                ScopeRead,
+	"GetConversationStats":      ScopeRead,
+	"ExportItinerary":           ScopeRead,
+	"ExportTripPDF":             ScopeRead,
+	"ExportUserData":            ScopeRead,
+	"CreateWebhook":             ScopeWebhooks,
+	"ListWebhooks":              ScopeWebhooks,
+	"DeleteWebhook":             ScopeWebhooks,
+	"ListWebhookDeliveries":     ScopeWebhooks,
+}
+
+// Key is an API key, without its secret.
+type Key struct {
+	ID   primitive.ObjectID `bson:"_id" json:"id"`
+	Name string             `bson:"name" json:"name"`
+	// UserID is the user requests made with the key act as.
+	UserID string `bson:"user_id" json:"user_id"`
+	// TenantID is the tenant the key is valid for, the default one when empty.
+	TenantID string   `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Scopes   []string `bson:"scopes" json:"scopes"`
+	// Hint is the start of the secret, to recognize the key.
+	Hint string `bson:"hint" json:"hint"`
+	Hash string `bson:"hash" json:"-"`
+	// PreviousHash is the hash of the secret before the last rotation, valid until
+	// PreviousExpiresAt.
+	PreviousHash      string     `bson:"previous_hash,omitempty" json:"-"`
+	PreviousExpiresAt *time.Time `bson:"previous_expires_at,omitempty" json:"previous_expires_at,omitempty"`
+	CreatedAt         time.Time  `bson:"created_at" json:"created_at"`
+	RotatedAt         *time.Time `bson:"rotated_at,omitempty" json:"rotated_at,omitempty"`
+	RevokedAt         *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	LastUsedAt        *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+}
+
+// Validate reports whether the key can be created.
+func (k *Key) Validate() error {
+	if strings.TrimSpace(k.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(k.UserID) == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	if len(k.Scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	for _, s := range k.Scopes {
+		if !slices.Contains(Scopes, s) {
+			return fmt.Errorf("unknown scope %q, want one of %s", s, strings.Join(Scopes, ", "))
+		}
+	}
+	return nil
+}
+
+// Allows reports whether the key may make requests to the HTTP path p.
+func (k *Key) Allows(p string) bool {
+	return slices.Contains(k.Scopes, RequiredScope(p))
+}
+
+// RequiredScope returns the scope requests to the HTTP path p need: API methods need
+// the scope of the method, other paths, like reply progress, ScopeRead.
+func RequiredScope(p string) string {
+	if !strings.Contains(p, "/twirp/") {
+		return ScopeRead
+	}
+	if s, ok := methodScopes[path.Base(p)]; ok {
+		return s
+	}
+	return ScopeWrite
+}
+
+// Generate returns a new secret and its hash and hint.
+func Generate() (secret, hash, hint string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	secret = Prefix + hex.EncodeToString(b)
+	return secret, Hash(secret), secret[:len(Prefix)+6], nil
+}
+
+// Hash returns the hash keys are stored and looked up by. Secrets are random, so a
+// plain SHA-256 is enough.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
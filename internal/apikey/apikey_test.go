@@ -0,0 +1,194 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twitchtv/twirp"
+)
+
+func TestKey_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     Key
+		wantErr bool
+	}{
+		{name: "valid", key: Key{Name: "Partner", UserID: "partner-1", Scopes: []string{ScopeRead, ScopeWrite}}},
+		{name: "no name", key: Key{UserID: "partner-1", Scopes: []string{ScopeRead}}, wantErr: true},
+		{name: "no user", key: Key{Name: "Partner", Scopes: []string{ScopeRead}}, wantErr: true},
+		{name: "no scopes", key: Key{Name: "Partner", UserID: "partner-1"}, wantErr: true},
+		{name: "unknown scope", key: Key{Name: "Partner", UserID: "partner-1", Scopes: []string{"admin"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.key.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/twirp/acai.chat.ChatService/ListConversations", want: ScopeRead},
+		{path: "/twirp/acai.chat.ChatService/ContinueConversation", want: ScopeWrite},
+		{path: "/twirp/acai.chat.ChatService/CreateWebhook", want: ScopeWebhooks},
+		{path: "/twirp/acai.chat.ChatService/SomethingNew", want: ScopeWrite},
+		{path: "/progress/attempt-1", want: ScopeRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := RequiredScope(tt.path); got != tt.want {
+				t.Errorf("RequiredScope() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	k := &Key{Scopes: []string{ScopeRead}}
+	if !k.Allows("/twirp/acai.chat.ChatService/DescribeConversation") || k.Allows("/twirp/acai.chat.ChatService/StartConversation") {
+		t.Error("read-only key allows writes, or doesn't allow reads")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	secret, hash, hint, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.HasPrefix(secret, Prefix) || !strings.HasPrefix(secret, hint) || hint == secret {
+		t.Errorf("Generate() = %q, %q, want a prefixed secret and its start", secret, hint)
+	}
+	if hash != Hash(secret) || strings.Contains(hash, secret) {
+		t.Errorf("Generate() hash = %q, want the hash of the secret", hash)
+	}
+
+	other, _, _, _ := Generate()
+	if other == secret {
+		t.Error("Generate() returned the same secret twice")
+	}
+}
+
+type memoryStore map[string]*Key
+
+func (s memoryStore) CreateKey(_ context.Context, k *Key) error {
+	s[k.ID.Hex()] = k
+	return nil
+}
+
+func (s memoryStore) ListKeys(context.Context) ([]*Key, error) {
+	var out []*Key
+	for _, k := range s {
+		out = append(out, k)
+	}
+	return out, nil
+}
+
+func (s memoryStore) DescribeKey(_ context.Context, id string) (*Key, error) {
+	if k, ok := s[id]; ok {
+		return k, nil
+	}
+	return nil, twirp.NotFoundError("key not found")
+}
+
+func (s memoryStore) RotateKey(_ context.Context, id, hash, hint string, now time.Time) (*Key, error) {
+	k, ok := s[id]
+	if !ok || k.RevokedAt != nil {
+		return nil, twirp.NotFoundError("key not found")
+	}
+	expires := now.Add(RotationGrace)
+	k.PreviousHash, k.PreviousExpiresAt = k.Hash, &expires
+	k.Hash, k.Hint, k.RotatedAt = hash, hint, &now
+	return k, nil
+}
+
+func (s memoryStore) RevokeKey(_ context.Context, id string, now time.Time) error {
+	k, ok := s[id]
+	if !ok || k.RevokedAt != nil {
+		return twirp.NotFoundError("key not found")
+	}
+	k.RevokedAt = &now
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	store := memoryStore{}
+	h := Handler("secret", store)
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodGet, "/", "", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET with a wrong token = %d, want 401", rec.Code)
+	}
+
+	if rec := do(http.MethodPost, "/", `{"name": "Partner", "user_id": "partner-1", "scopes": ["admin"]}`, "secret"); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST with an unknown scope = %d, want 400", rec.Code)
+	}
+
+	rec := do(http.MethodPost, "/", `{"name": "Partner", "user_id": "partner-1", "tenant_id": "nordic", "scopes": ["read"]}`, "secret")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST = %d %s, want 201", rec.Code, rec.Body)
+	}
+	var c struct {
+		Key    Key    `json:"key"`
+		Secret string `json:"secret"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&c); err != nil {
+		t.Fatalf("decode created key: %v", err)
+	}
+	id := c.Key.ID.Hex()
+	stored := store[id]
+	if stored == nil || stored.Hash != Hash(c.Secret) || stored.TenantID != "nordic" || stored.UserID != "partner-1" {
+		t.Fatalf("stored key = %+v, want the partner's key, hashed", stored)
+	}
+	if strings.Contains(rec.Body.String(), stored.Hash) {
+		t.Error("POST response contains the hash of the key")
+	}
+
+	rec = do(http.MethodPost, "/"+id+"/rotate", "", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST rotate = %d %s, want 200", rec.Code, rec.Body)
+	}
+	var rotated struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&rotated); err != nil || rotated.Secret == c.Secret {
+		t.Fatalf("rotated secret = %q, %v, want a new one", rotated.Secret, err)
+	}
+	if stored.Hash != Hash(rotated.Secret) || stored.PreviousHash != Hash(c.Secret) {
+		t.Errorf("rotated key = %+v, want the new secret and the previous one in grace", stored)
+	}
+
+	if rec := do(http.MethodDelete, "/"+id, "", "secret"); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d, want 204", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/"+id+"/rotate", "", "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("POST rotate of a revoked key = %d, want 404", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/"+id, "", "secret"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "revoked_at") {
+		t.Errorf("GET of a revoked key = %d %s, want it with its revocation", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	Handler("", store).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET without an admin token configured = %d, want 404", rec.Code)
+	}
+}
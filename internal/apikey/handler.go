@@ -0,0 +1,178 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxKeySize bounds the JSON body of a new key.
+const maxKeySize = 16 << 10
+
+// Store lists and manages the stored keys.
+type Store interface {
+	CreateKey(ctx context.Context, k *Key) error
+	ListKeys(ctx context.Context) ([]*Key, error)
+	DescribeKey(ctx context.Context, id string) (*Key, error)
+	RotateKey(ctx context.Context, id, hash, hint string, now time.Time) (*Key, error)
+	RevokeKey(ctx context.Context, id string, now time.Time) error
+}
+
+// created is a key along with its secret, only ever shown when created or rotated.
+type created struct {
+	Key    *Key   `json:"key"`
+	Secret string `json:"secret"`
+}
+
+// Handler serves the API key admin API, for admins who authenticate with
+// "Authorization: Bearer <token>". Mounted with its prefix stripped, it serves
+//
+//	GET    /             all keys
+//	POST   /             create a key from its JSON name, user_id, tenant_id and scopes
+//	GET    /{id}         a key
+//	POST   /{id}/rotate  replace the secret of a key
+//	DELETE /{id}         revoke a key
+//
+// Creating and rotating return the secret, which is not stored. An empty token disables
+// the API.
+func Handler(token string, store Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := r.Context()
+		w.Header().Set("Cache-Control", "private, no-store")
+
+		id, action, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case id == "":
+			switch r.Method {
+			case http.MethodGet, http.MethodHead:
+				keys, err := store.ListKeys(ctx)
+				if err != nil {
+					fail(ctx, w, "Failed to list API keys", err)
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+
+			case http.MethodPost:
+				var k Key
+				if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxKeySize)).Decode(&k); err != nil {
+					http.Error(w, "Invalid key: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+				if err := k.Validate(); err != nil {
+					http.Error(w, "Invalid key: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				secret, hash, hint, err := Generate()
+				if err != nil {
+					fail(ctx, w, "Failed to generate API key", err)
+					return
+				}
+				key := &Key{
+					ID:        primitive.NewObjectID(),
+					Name:      k.Name,
+					UserID:    k.UserID,
+					TenantID:  k.TenantID,
+					Scopes:    k.Scopes,
+					Hint:      hint,
+					Hash:      hash,
+					CreatedAt: time.Now(),
+				}
+				if err := store.CreateKey(ctx, key); err != nil {
+					fail(ctx, w, "Failed to store API key", err)
+					return
+				}
+				slog.InfoContext(ctx, "API key created", "api_key_id", key.ID.Hex(), "user_id", key.UserID, "tenant_id", key.TenantID)
+				writeJSON(w, http.StatusCreated, created{Key: key, Secret: secret})
+
+			default:
+				w.Header().Set("Allow", "GET, HEAD, POST")
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			}
+
+		case action == "rotate":
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", "POST")
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			secret, hash, hint, err := Generate()
+			if err != nil {
+				fail(ctx, w, "Failed to generate API key", err)
+				return
+			}
+			key, err := store.RotateKey(ctx, id, hash, hint, time.Now())
+			if err != nil {
+				fail(ctx, w, "Failed to rotate API key", err)
+				return
+			}
+			slog.InfoContext(ctx, "API key rotated", "api_key_id", id)
+			writeJSON(w, http.StatusOK, created{Key: key, Secret: secret})
+
+		case action != "":
+			http.NotFound(w, r)
+
+		default:
+			switch r.Method {
+			case http.MethodGet, http.MethodHead:
+				key, err := store.DescribeKey(ctx, id)
+				if err != nil {
+					fail(ctx, w, "Failed to describe API key", err)
+					return
+				}
+				writeJSON(w, http.StatusOK, key)
+
+			case http.MethodDelete:
+				if err := store.RevokeKey(ctx, id, time.Now()); err != nil {
+					fail(ctx, w, "Failed to revoke API key", err)
+					return
+				}
+				slog.InfoContext(ctx, "API key revoked", "api_key_id", id)
+				w.WriteHeader(http.StatusNoContent)
+
+			default:
+				w.Header().Set("Allow", "GET, HEAD, DELETE")
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			}
+		}
+	})
+}
+
+// fail answers 404 for keys that don't exist or are revoked, and 500 otherwise.
+func fail(ctx context.Context, w http.ResponseWriter, msg string, err error) {
+	var terr twirp.Error
+	if errors.As(err, &terr) && terr.Code() == twirp.NotFound {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+
+	slog.ErrorContext(ctx, msg, "error", err)
+	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,221 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName    = "github.com/acai-travel/tech-challenge/internal/apikey"
+	keyCollection = "api_keys"
+)
+
+// lastUsedResolution bounds how often a key's last use is recorded.
+const lastUsedResolution = time.Minute
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+// EnsureIndexes creates the indexes keys are looked up by.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.conn.Collection(keyCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "previous_hash", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	return err
+}
+
+func (r *Repository) CreateKey(ctx context.Context, k *Key) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.CreateKey")
+	span.SetAttributes(attribute.String("api_key.id", k.ID.Hex()))
+	defer span.End()
+
+	if _, err := r.conn.Collection(keyCollection).InsertOne(ctx, k); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create key")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "key created")
+	return nil
+}
+
+// ListKeys returns all keys, the most recently created first.
+func (r *Repository) ListKeys(ctx context.Context) ([]*Key, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListKeys")
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.conn.Collection(keyCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query keys")
+		return nil, err
+	}
+
+	var items []*Key
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode keys")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("api_keys.count", len(items)))
+	span.SetStatus(codes.Ok, "keys listed")
+	return items, nil
+}
+
+func (r *Repository) DescribeKey(ctx context.Context, id string) (*Key, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeKey")
+	span.SetAttributes(attribute.String("api_key.id", id))
+	defer span.End()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid key ID")
+		return nil, twirp.NotFoundError("key not found")
+	}
+
+	var k Key
+	err = r.conn.Collection(keyCollection).FindOne(ctx, bson.M{"_id": oid}).Decode(&k)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Error, "key not found")
+		return nil, twirp.NotFoundError("key not found")
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database error")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "key found")
+	return &k, nil
+}
+
+// RotateKey replaces the secret of an active key by the one hashed to hash. The previous
+// secret keeps working for RotationGrace.
+func (r *Repository) RotateKey(ctx context.Context, id, hash, hint string, now time.Time) (*Key, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.RotateKey")
+	span.SetAttributes(attribute.String("api_key.id", id))
+	defer span.End()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid key ID")
+		return nil, twirp.NotFoundError("key not found")
+	}
+
+	var k Key
+	err = r.conn.Collection(keyCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": oid, "revoked_at": nil},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"previous_hash":       "$hash",
+			"previous_expires_at": now.Add(RotationGrace),
+			"hash":                hash,
+			"hint":                hint,
+			"rotated_at":          now,
+		}}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&k)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Error, "key not found")
+		return nil, twirp.NotFoundError("key not found")
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to rotate key")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "key rotated")
+	return &k, nil
+}
+
+// RevokeKey revokes a key, along with the previous secret of a rotated one.
+func (r *Repository) RevokeKey(ctx context.Context, id string, now time.Time) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.RevokeKey")
+	span.SetAttributes(attribute.String("api_key.id", id))
+	defer span.End()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid key ID")
+		return twirp.NotFoundError("key not found")
+	}
+
+	res, err := r.conn.Collection(keyCollection).UpdateOne(ctx,
+		bson.M{"_id": oid, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to revoke key")
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		span.SetStatus(codes.Error, "key not found")
+		return twirp.NotFoundError("key not found")
+	}
+
+	span.SetStatus(codes.Ok, "key revoked")
+	return nil
+}
+
+// Authenticate returns the active key with the given secret, or nil if there is none,
+// and records its use.
+func (r *Repository) Authenticate(ctx context.Context, secret string) (*Key, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.Authenticate")
+	defer span.End()
+
+	now := time.Now()
+	hash := Hash(secret)
+
+	var k Key
+	err := r.conn.Collection(keyCollection).FindOne(ctx, bson.M{
+		"revoked_at": nil,
+		"$or": bson.A{
+			bson.M{"hash": hash},
+			bson.M{"previous_hash": hash, "previous_expires_at": bson.M{"$gt": now}},
+		},
+	}).Decode(&k)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "no such key")
+		return nil, nil
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database error")
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("api_key.id", k.ID.Hex()))
+
+	// Recording every use would write on every request
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= lastUsedResolution {
+		if _, err := r.conn.Collection(keyCollection).UpdateOne(ctx,
+			bson.M{"_id": k.ID},
+			bson.M{"$set": bson.M{"last_used_at": now}}); err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	span.SetStatus(codes.Ok, "key authenticated")
+	return &k, nil
+}
@@ -0,0 +1,107 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName = "github.com/acai-travel/tech-challenge/internal/attachment"
+	bucketName = "attachments"
+)
+
+// GridFSStore stores attachments in a MongoDB GridFS bucket.
+type GridFSStore struct {
+	bucket *gridfs.Bucket
+}
+
+func NewGridFSStore(conn *mongo.Database) (*GridFSStore, error) {
+	bucket, err := gridfs.NewBucket(conn, options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GridFSStore{bucket: bucket}, nil
+}
+
+func (s *GridFSStore) Put(ctx context.Context, filename, contentType string, data []byte) (string, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "GridFSStore.Put")
+	span.SetAttributes(
+		attribute.String("attachment.content_type", contentType),
+		attribute.Int("attachment.size", len(data)),
+	)
+	defer span.End()
+
+	opts := options.GridFSUpload().SetMetadata(bson.M{"content_type": contentType})
+	id, err := s.bucket.UploadFromStream(filename, bytes.NewReader(data), opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to upload attachment")
+		return "", err
+	}
+
+	span.SetAttributes(attribute.String("attachment.id", id.Hex()))
+	span.SetStatus(codes.Ok, "attachment stored")
+	return id.Hex(), nil
+}
+
+func (s *GridFSStore) Get(ctx context.Context, id string) ([]byte, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "GridFSStore.Get")
+	span.SetAttributes(attribute.String("attachment.id", id))
+	defer span.End()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid attachment ID")
+		return nil, ErrNotFound
+	}
+
+	var buf bytes.Buffer
+	_, err = s.bucket.DownloadToStream(oid, &buf)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		span.SetStatus(codes.Error, "attachment not found")
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to download attachment")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "attachment loaded")
+	return buf.Bytes(), nil
+}
+
+func (s *GridFSStore) Delete(ctx context.Context, id string) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "GridFSStore.Delete")
+	span.SetAttributes(attribute.String("attachment.id", id))
+	defer span.End()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.SetStatus(codes.Ok, "invalid attachment ID, nothing to delete")
+		return nil
+	}
+
+	err = s.bucket.DeleteContext(ctx, oid)
+	if err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete attachment")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "attachment deleted")
+	return nil
+}
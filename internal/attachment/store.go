@@ -0,0 +1,54 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	// MaxSize is the largest accepted attachment, in bytes.
+	MaxSize = 10 << 20
+
+	// MaxPerMessage is the number of attachments a single message may carry.
+	MaxPerMessage = 5
+)
+
+// ImageTypes are the content types passed to the model as images.
+var ImageTypes = []string{"image/png", "image/jpeg", "image/webp", "image/gif"}
+
+// ContentTypePDF is passed to the model as a file.
+const ContentTypePDF = "application/pdf"
+
+var ErrNotFound = errors.New("attachment not found")
+
+// Store keeps attachment contents. Implementations may be backed by GridFS, S3 or similar.
+type Store interface {
+	Put(ctx context.Context, filename, contentType string, data []byte) (id string, err error)
+	Get(ctx context.Context, id string) ([]byte, error)
+	// Delete removes an attachment. Deleting a missing attachment is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// IsImage reports whether the content type is an accepted image type.
+func IsImage(contentType string) bool {
+	return slices.Contains(ImageTypes, contentType)
+}
+
+// Validate checks an upload against the accepted content types and size limit.
+func Validate(contentType string, size int) error {
+	if !IsImage(contentType) && contentType != ContentTypePDF {
+		return fmt.Errorf("unsupported content type %q", contentType)
+	}
+
+	if size == 0 {
+		return errors.New("attachment is empty")
+	}
+
+	if size > MaxSize {
+		return fmt.Errorf("attachment exceeds %d MB", MaxSize>>20)
+	}
+
+	return nil
+}
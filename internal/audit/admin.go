@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/apikey"
+	"github.com/acai-travel/tech-challenge/internal/quickstart"
+	"github.com/acai-travel/tech-challenge/internal/review"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Templates records the changes admins make to conversation templates through store.
+func Templates(store quickstart.Store, rec Recorder) quickstart.Store {
+	return &templateStore{Store: store, rec: rec}
+}
+
+type templateStore struct {
+	quickstart.Store
+	rec Recorder
+}
+
+func (s *templateStore) PutTemplate(ctx context.Context, t *quickstart.Template) error {
+	// A template that doesn't exist yet has nothing to snapshot
+	before, _ := s.DescribeTemplate(ctx, t.ID)
+	if err := s.Store.PutTemplate(ctx, t); err != nil {
+		return err
+	}
+
+	e := &Entry{Action: TemplatePut, Admin: true, ResourceID: t.ID, After: Snapshot(t)}
+	if before != nil {
+		e.Before = Snapshot(before)
+	}
+	Record(ctx, s.rec, e)
+	return nil
+}
+
+func (s *templateStore) DeleteTemplate(ctx context.Context, id string) error {
+	before, _ := s.DescribeTemplate(ctx, id)
+	if err := s.Store.DeleteTemplate(ctx, id); err != nil {
+		return err
+	}
+
+	e := &Entry{Action: TemplateDelete, Admin: true, ResourceID: id}
+	if before != nil {
+		e.Before = Snapshot(before)
+	}
+	Record(ctx, s.rec, e)
+	return nil
+}
+
+// APIKeys records the API keys admins create, rotate and revoke through store. Entries
+// never contain secrets or their hashes.
+func APIKeys(store apikey.Store, rec Recorder) apikey.Store {
+	return &keyStore{Store: store, rec: rec}
+}
+
+type keyStore struct {
+	apikey.Store
+	rec Recorder
+}
+
+func (s *keyStore) CreateKey(ctx context.Context, k *apikey.Key) error {
+	if err := s.Store.CreateKey(ctx, k); err != nil {
+		return err
+	}
+	Record(ctx, s.rec, &Entry{Action: APIKeyCreate, Admin: true, ResourceID: k.ID.Hex(), After: keySnapshot(k)})
+	return nil
+}
+
+func (s *keyStore) RotateKey(ctx context.Context, id, hash, hint string, now time.Time) (*apikey.Key, error) {
+	k, err := s.Store.RotateKey(ctx, id, hash, hint, now)
+	if err != nil {
+		return nil, err
+	}
+	Record(ctx, s.rec, &Entry{Action: APIKeyRotate, Admin: true, ResourceID: id, After: keySnapshot(k)})
+	return k, nil
+}
+
+func (s *keyStore) RevokeKey(ctx context.Context, id string, now time.Time) error {
+	before, _ := s.DescribeKey(ctx, id)
+	if err := s.Store.RevokeKey(ctx, id, now); err != nil {
+		return err
+	}
+
+	e := &Entry{Action: APIKeyRevoke, Admin: true, ResourceID: id}
+	if before != nil {
+		e.Before = keySnapshot(before)
+	}
+	Record(ctx, s.rec, e)
+	return nil
+}
+
+// keySnapshot is the snapshot of k, without the hashes of its secrets.
+func keySnapshot(k *apikey.Key) bson.M {
+	m := Snapshot(k)
+	delete(m, "hash")
+	delete(m, "previous_hash")
+	return m
+}
+
+// Reviews records the notes and resolutions admins add to the review queue through
+// store. Flags are recorded by the chat API, or not at all when the safety layer
+// raises them.
+func Reviews(store review.Store, rec Recorder) review.Store {
+	return &reviewStore{Store: store, rec: rec}
+}
+
+type reviewStore struct {
+	review.Store
+	rec Recorder
+}
+
+func (s *reviewStore) Annotate(ctx context.Context, id primitive.ObjectID, note review.Note) (*review.Item, error) {
+	item, err := s.Store.Annotate(ctx, id, note)
+	if err != nil {
+		return nil, err
+	}
+	Record(ctx, s.rec, &Entry{Action: ReviewAnnotate, Admin: true, ResourceID: id.Hex(), After: Snapshot(note)})
+	return item, nil
+}
+
+func (s *reviewStore) Resolve(ctx context.Context, id primitive.ObjectID, resolution string, now time.Time) (*review.Item, error) {
+	before, _ := s.DescribeItem(ctx, id)
+	item, err := s.Store.Resolve(ctx, id, resolution, now)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Entry{Action: ReviewResolve, Admin: true, ResourceID: id.Hex(), After: Snapshot(item)}
+	if before != nil {
+		e.Before = Snapshot(before)
+	}
+	Record(ctx, s.rec, e)
+	return item, nil
+}
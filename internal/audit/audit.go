@@ -0,0 +1,123 @@
+// Package audit records who changed what, for compliance reviews: every mutating call
+// to the chat API and every admin configuration change becomes an Entry, with snapshots
+// of what destructive operations removed or replaced.
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Actions of audit entries, "<resource>.<verb>".
+const (
+	ConversationStart      = "conversation.start"
+	ConversationContinue   = "conversation.continue"
+	ConversationRelabel    = "conversation.relabel"
+	ConversationArchive    = "conversation.archive"
+	ConversationUnarchive  = "conversation.unarchive"
+	ConversationPin        = "conversation.pin"
+	ConversationUnpin      = "conversation.unpin"
+	ConversationFavorite   = "conversation.favorite"
+	ConversationUnfavorite = "conversation.unfavorite"
+	ConversationDelete     = "conversation.delete"
+	ConversationFlag       = "conversation.flag"
+	ConversationRefresh    = "conversation.refresh_reply"
+	MessageFeedback        = "message.feedback"
+	WebhookCreate          = "webhook.create"
+	WebhookDelete          = "webhook.delete"
+	ProfileUpdate          = "profile.update"
+	UserDataImport         = "user_data.import"
+	ShareCreate            = "share.create"
+	ShareRevoke            = "share.revoke"
+	TemplatePut            = "template.put"
+	TemplateDelete         = "template.delete"
+	APIKeyCreate           = "api_key.create"
+	APIKeyRotate           = "api_key.rotate"
+	APIKeyRevoke           = "api_key.revoke"
+	ReviewAnnotate         = "review.annotate"
+	ReviewResolve          = "review.resolve"
+)
+
+// Entry is a change made to a resource.
+type Entry struct {
+	ID     primitive.ObjectID `bson:"_id" json:"id"`
+	Time   time.Time          `bson:"time" json:"time"`
+	Action string             `bson:"action" json:"action"`
+	// UserID is the user who made the change, empty for anonymous users and admins.
+	UserID string `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	// APIKeyID is the API key the change was made with, if any.
+	APIKeyID string `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"`
+	// Admin tells changes made through the admin API.
+	Admin bool `bson:"admin,omitempty" json:"admin,omitempty"`
+	// RequestID is the trace ID of the request making the change.
+	RequestID  string `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	ResourceID string `bson:"resource_id" json:"resource_id"`
+	// Before is the resource as it was before a destructive change, After as it is
+	// after a change replacing it.
+	Before bson.M `bson:"before,omitempty" json:"before,omitempty"`
+	After  bson.M `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// Recorder stores audit entries.
+type Recorder interface {
+	RecordEntry(ctx context.Context, e *Entry) error
+}
+
+// Record stores the entry with the time, the user, API key and request ID of ctx. Audit
+// is best effort: failures are logged, and don't fail the change. A nil rec records
+// nothing.
+func Record(ctx context.Context, rec Recorder, e *Entry) {
+	if rec == nil {
+		return
+	}
+
+	e.ID = primitive.NewObjectID()
+	e.Time = time.Now()
+	if !e.Admin {
+		e.UserID = auth.UserID(ctx)
+	}
+	e.APIKeyID = auth.APIKeyID(ctx)
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		e.RequestID = sc.TraceID().String()
+	}
+
+	// The change is made: record it even if the client has gone
+	if err := rec.RecordEntry(context.WithoutCancel(ctx), e); err != nil {
+		slog.ErrorContext(ctx, "Failed to record audit entry", "action", e.Action, "resource_id", e.ResourceID, "error", err)
+	}
+}
+
+// Snapshot returns v as stored, for the Before and After of entries. It is nil when v is
+// nil or can't be stored.
+func Snapshot(v any) bson.M {
+	if v == nil {
+		return nil
+	}
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m bson.M
+	if err := bson.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// Query filters the entries listed. Empty fields match every entry.
+type Query struct {
+	UserID     string
+	Action     string
+	ResourceID string
+	RequestID  string
+	Since      time.Time
+	Until      time.Time
+	// Limit bounds the entries listed, the most recent first.
+	Limit int
+}
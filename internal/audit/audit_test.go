@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/apikey"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/quickstart"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// memoryLog is an in-memory audit log.
+type memoryLog struct {
+	entries []*Entry
+	query   Query
+}
+
+func (l *memoryLog) RecordEntry(ctx context.Context, e *Entry) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	l.entries = append(l.entries, e)
+	return nil
+}
+
+func (l *memoryLog) ListEntries(ctx context.Context, q Query) ([]*Entry, error) {
+	l.query = q
+	return l.entries, nil
+}
+
+func TestRecord(t *testing.T) {
+	log := &memoryLog{}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = auth.WithUserID(ctx, "user-1")
+	ctx = auth.WithAPIKeyID(ctx, "68f0a1b2c3d4e5f6a7b8c9d0")
+
+	// The change is recorded even if the client is gone
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	Record(ctx, log, &Entry{Action: ConversationStart, ResourceID: "conv-1"})
+	Record(ctx, log, &Entry{Action: TemplateDelete, Admin: true, ResourceID: "city-break"})
+	Record(ctx, nil, &Entry{Action: ConversationStart})
+
+	if len(log.entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2", len(log.entries))
+	}
+	e := log.entries[0]
+	if e.ID.IsZero() || e.Time.IsZero() || e.UserID != "user-1" || e.APIKeyID != "68f0a1b2c3d4e5f6a7b8c9d0" || e.RequestID != sc.TraceID().String() {
+		t.Errorf("entry = %+v, want it stamped with the time, user, API key and request ID", e)
+	}
+	if log.entries[1].UserID != "" {
+		t.Errorf("admin entry user = %q, want none", log.entries[1].UserID)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	tmpl := &quickstart.Template{ID: "city-break", Name: "City break"}
+	if got := Snapshot(tmpl); got["_id"] != "city-break" || got["name"] != "City break" {
+		t.Errorf("Snapshot() = %v, want the stored template", got)
+	}
+	if got := Snapshot(nil); got != nil {
+		t.Errorf("Snapshot(nil) = %v, want nil", got)
+	}
+	if got := Snapshot((*quickstart.Template)(nil)); got != nil {
+		t.Errorf("Snapshot of a nil template = %v, want nil", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	log := &memoryLog{entries: []*Entry{{ID: primitive.NewObjectID(), Action: ConversationDelete, ResourceID: "conv-1"}}}
+	h := Handler("secret", log)
+
+	do := func(query, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit"+query, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do("", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET with a wrong token = %d, want 401", rec.Code)
+	}
+	for _, query := range []string{"?since=yesterday", "?until=2025-10-18", "?limit=0", "?limit=5000"} {
+		if rec := do(query, "secret"); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", query, rec.Code)
+		}
+	}
+
+	rec := do("?user_id=user-1&action=conversation.delete&since=2025-10-01T00:00:00Z&limit=10", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET = %d %s, want 200", rec.Code, rec.Body)
+	}
+	want := Query{UserID: "user-1", Action: ConversationDelete, Since: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), Limit: 10}
+	if log.query != want {
+		t.Errorf("query = %+v, want %+v", log.query, want)
+	}
+	var body struct {
+		Entries []*Entry `json:"entries"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || len(body.Entries) != 1 {
+		t.Errorf("GET = %v, %v, want the entry", body.Entries, err)
+	}
+
+	if do("", "secret"); log.query.Limit != defaultLimit {
+		t.Errorf("default limit = %d, want %d", log.query.Limit, defaultLimit)
+	}
+}
+
+type memoryTemplates map[string]*quickstart.Template
+
+func (s memoryTemplates) ListTemplates(context.Context) ([]*quickstart.Template, error) {
+	return nil, nil
+}
+
+func (s memoryTemplates) DescribeTemplate(_ context.Context, id string) (*quickstart.Template, error) {
+	if t, ok := s[id]; ok {
+		return t, nil
+	}
+	return nil, twirp.NotFoundError("template not found")
+}
+
+func (s memoryTemplates) PutTemplate(_ context.Context, t *quickstart.Template) error {
+	s[t.ID] = t
+	return nil
+}
+
+func (s memoryTemplates) DeleteTemplate(_ context.Context, id string) error {
+	if _, ok := s[id]; !ok {
+		return twirp.NotFoundError("template not found")
+	}
+	delete(s, id)
+	return nil
+}
+
+func TestTemplates(t *testing.T) {
+	log := &memoryLog{}
+	store := Templates(memoryTemplates{}, log)
+	ctx := context.Background()
+
+	_ = store.PutTemplate(ctx, &quickstart.Template{ID: "city-break", Name: "City break"})
+	_ = store.PutTemplate(ctx, &quickstart.Template{ID: "city-break", Name: "City weekend"})
+	_ = store.DeleteTemplate(ctx, "city-break")
+	if err := store.DeleteTemplate(ctx, "city-break"); err == nil {
+		t.Error("deleting a missing template succeeded")
+	}
+
+	if len(log.entries) != 3 {
+		t.Fatalf("recorded %d entries, want 3", len(log.entries))
+	}
+	created, replaced, deleted := log.entries[0], log.entries[1], log.entries[2]
+	if created.Action != TemplatePut || created.Before != nil || created.After["name"] != "City break" || !created.Admin {
+		t.Errorf("creation = %+v, want an admin put without a before", created)
+	}
+	if replaced.Before["name"] != "City break" || replaced.After["name"] != "City weekend" {
+		t.Errorf("replacement = %+v, want the template before and after", replaced)
+	}
+	if deleted.Action != TemplateDelete || deleted.Before["name"] != "City weekend" {
+		t.Errorf("deletion = %+v, want the deleted template", deleted)
+	}
+}
+
+type memoryKeys map[string]*apikey.Key
+
+func (s memoryKeys) CreateKey(_ context.Context, k *apikey.Key) error {
+	s[k.ID.Hex()] = k
+	return nil
+}
+
+func (s memoryKeys) ListKeys(context.Context) ([]*apikey.Key, error) {
+	return nil, nil
+}
+
+func (s memoryKeys) DescribeKey(_ context.Context, id string) (*apikey.Key, error) {
+	if k, ok := s[id]; ok {
+		return k, nil
+	}
+	return nil, twirp.NotFoundError("key not found")
+}
+
+func (s memoryKeys) RotateKey(_ context.Context, id, hash, hint string, now time.Time) (*apikey.Key, error) {
+	k, ok := s[id]
+	if !ok {
+		return nil, twirp.NotFoundError("key not found")
+	}
+	k.PreviousHash, k.Hash, k.Hint = k.Hash, hash, hint
+	return k, nil
+}
+
+func (s memoryKeys) RevokeKey(_ context.Context, id string, now time.Time) error {
+	k, ok := s[id]
+	if !ok {
+		return errors.New("key not found")
+	}
+	k.RevokedAt = &now
+	return nil
+}
+
+func TestAPIKeys(t *testing.T) {
+	log := &memoryLog{}
+	store := APIKeys(memoryKeys{}, log)
+	ctx := context.Background()
+
+	k := &apikey.Key{ID: primitive.NewObjectID(), Name: "Partner", UserID: "partner-1", Hash: "hash-1", Scopes: []string{apikey.ScopeRead}}
+	_ = store.CreateKey(ctx, k)
+	_, _ = store.RotateKey(ctx, k.ID.Hex(), "hash-2", "acai_123456", time.Now())
+	_ = store.RevokeKey(ctx, k.ID.Hex(), time.Now())
+
+	if len(log.entries) != 3 {
+		t.Fatalf("recorded %d entries, want 3", len(log.entries))
+	}
+	for i, action := range []string{APIKeyCreate, APIKeyRotate, APIKeyRevoke} {
+		e := log.entries[i]
+		if e.Action != action || e.ResourceID != k.ID.Hex() || !e.Admin {
+			t.Errorf("entry %d = %+v, want an admin %s", i, e, action)
+		}
+		for _, snapshot := range []map[string]any{e.Before, e.After} {
+			if _, ok := snapshot["hash"]; ok {
+				t.Errorf("entry %d has a key hash", i)
+			}
+			if _, ok := snapshot["previous_hash"]; ok {
+				t.Errorf("entry %d has a previous key hash", i)
+			}
+		}
+	}
+	if log.entries[2].Before["name"] != "Partner" {
+		t.Errorf("revocation before = %v, want the key", log.entries[2].Before)
+	}
+}
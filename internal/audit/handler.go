@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultLimit is how many entries the admin endpoint returns when no limit is given.
+	defaultLimit = 100
+	// maxLimit bounds the entries of a single response.
+	maxLimit = 1000
+)
+
+// Reader lists stored audit entries.
+type Reader interface {
+	ListEntries(ctx context.Context, q Query) ([]*Entry, error)
+}
+
+// Handler serves audit entries as JSON to admins, who authenticate with
+// "Authorization: Bearer <token>", the most recent first. They are filtered with the
+// "user_id", "action", "resource_id" and "request_id" query parameters, "since" and
+// "until" (RFC 3339), and bounded by "limit" (100 by default, up to 1000). An empty token
+// disables the endpoint.
+func Handler(token string, store Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := r.Context()
+		params := r.URL.Query()
+		q := Query{
+			UserID:     params.Get("user_id"),
+			Action:     params.Get("action"),
+			ResourceID: params.Get("resource_id"),
+			RequestID:  params.Get("request_id"),
+			Limit:      defaultLimit,
+		}
+
+		var err error
+		if q.Since, err = parseTime(params.Get("since")); err != nil {
+			http.Error(w, "Invalid since: expected an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		if q.Until, err = parseTime(params.Get("until")); err != nil {
+			http.Error(w, "Invalid until: expected an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		if v := params.Get("limit"); v != "" {
+			if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 1 || q.Limit > maxLimit {
+				http.Error(w, "Invalid limit: expected 1 to "+strconv.Itoa(maxLimit), http.StatusBadRequest)
+				return
+			}
+		}
+
+		entries, err := store.ListEntries(ctx, q)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to list audit entries", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"entries": entries})
+	})
+}
+
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/acai-travel/tech-challenge/internal/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName      = "github.com/acai-travel/tech-challenge/internal/audit"
+	entryCollection = "audit_log"
+)
+
+type Repository struct {
+	conn *mongo.Database
+	// replica serves the searches of entries, which tolerate stale data
+	replica *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn, replica: mongox.Replica(conn)}
+}
+
+// EnsureIndexes creates the indexes of the queries of compliance reviews: by user, by
+// resource and by request, the most recent first.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.conn.Collection(entryCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "time", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "time", Value: -1}}},
+		{Keys: bson.D{{Key: "resource_id", Value: 1}, {Key: "time", Value: -1}}},
+		{Keys: bson.D{{Key: "request_id", Value: 1}}},
+	})
+	return err
+}
+
+func (r *Repository) RecordEntry(ctx context.Context, e *Entry) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.RecordEntry")
+	span.SetAttributes(
+		attribute.String("audit.action", e.Action),
+		attribute.String("audit.resource_id", e.ResourceID),
+	)
+	defer span.End()
+
+	if _, err := r.conn.Collection(entryCollection).InsertOne(ctx, e); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to record entry")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "entry recorded")
+	return nil
+}
+
+// ListEntries returns the entries matching q, the most recent first.
+func (r *Repository) ListEntries(ctx context.Context, q Query) ([]*Entry, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListEntries")
+	defer span.End()
+
+	filter := bson.M{}
+	for field, v := range map[string]string{
+		"user_id":     q.UserID,
+		"action":      q.Action,
+		"resource_id": q.ResourceID,
+		"request_id":  q.RequestID,
+	} {
+		if v != "" {
+			filter[field] = v
+		}
+	}
+	if !q.Since.IsZero() || !q.Until.IsZero() {
+		period := bson.M{}
+		if !q.Since.IsZero() {
+			period["$gte"] = q.Since
+		}
+		if !q.Until.IsZero() {
+			period["$lt"] = q.Until
+		}
+		filter["time"] = period
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "time", Value: -1}}).
+		SetLimit(int64(q.Limit))
+
+	cursor, err := r.replica.Collection(entryCollection).Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query entries")
+		return nil, err
+	}
+
+	var items []*Entry
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode entries")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("audit.entries", len(items)))
+	span.SetStatus(codes.Ok, "entries listed")
+	return items, nil
+}
@@ -0,0 +1,46 @@
+package auth
+
+import "context"
+
+type userIDKey struct{}
+
+// WithUserID returns a copy of ctx carrying the ID of the user making the request.
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+// UserID returns the ID of the user making the request, or an empty string if unknown.
+func UserID(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
+
+type tenantIDKey struct{}
+
+// WithTenantID returns a copy of ctx carrying the ID of the tenant, the travel brand, the
+// request is made to.
+func WithTenantID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantIDKey{}, id)
+}
+
+// TenantID returns the ID of the tenant the request is made to, or an empty string for
+// the default tenant.
+func TenantID(ctx context.Context) string {
+	id, _ := ctx.Value(tenantIDKey{}).(string)
+	return id
+}
+
+type apiKeyIDKey struct{}
+
+// WithAPIKeyID returns a copy of ctx carrying the ID of the API key the request is made
+// with.
+func WithAPIKeyID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, apiKeyIDKey{}, id)
+}
+
+// APIKeyID returns the ID of the API key the request is made with, or an empty string
+// for requests made without one.
+func APIKeyID(ctx context.Context) string {
+	id, _ := ctx.Value(apiKeyIDKey{}).(string)
+	return id
+}
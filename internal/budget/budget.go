@@ -0,0 +1,112 @@
+// Package budget tracks the spend of trips against their target budget. A trip is a
+// conversation: its budget holds the target and the expenses added as flights, stays
+// and activities get planned.
+package budget
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pricing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Categories of expenses.
+const (
+	CategoryFlight    = "flight"
+	CategoryLodging   = "lodging"
+	CategoryTransport = "transport"
+	CategoryActivity  = "activity"
+	CategoryFood      = "food"
+	CategoryOther     = "other"
+)
+
+// Categories are the categories of expenses, in the order of status reports.
+var Categories = []string{CategoryFlight, CategoryLodging, CategoryTransport, CategoryActivity, CategoryFood, CategoryOther}
+
+// MaxExpenses bounds the expenses of a budget.
+const MaxExpenses = 200
+
+// Budget is the budget of the trip of a conversation.
+type Budget struct {
+	ConversationID primitive.ObjectID `bson:"_id"`
+	UserID         string             `bson:"user_id"`
+	// Target is the spend aimed at, in the currency totals are reported in
+	Target    pricing.Money `bson:"target"`
+	Expenses  []Expense     `bson:"expenses"`
+	CreatedAt time.Time     `bson:"created_at"`
+	UpdatedAt time.Time     `bson:"updated_at"`
+}
+
+// Expense is a cost of the trip, booked or estimated.
+type Expense struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Category    string             `bson:"category"`
+	Description string             `bson:"description"`
+	Amount      pricing.Money      `bson:"amount"`
+	// Estimated is set on costs not booked yet, like the price of a flight offer
+	Estimated bool      `bson:"estimated,omitempty"`
+	AddedAt   time.Time `bson:"added_at"`
+}
+
+// Store stores budgets, one per conversation.
+type Store interface {
+	// DescribeBudget returns the budget of the conversation, nil when it has none.
+	DescribeBudget(ctx context.Context, conversationID primitive.ObjectID) (*Budget, error)
+	// SetTarget sets the target of the budget of the conversation, creating it if needed.
+	SetTarget(ctx context.Context, conversationID primitive.ObjectID, userID string, target pricing.Money, now time.Time) (*Budget, error)
+	// AddExpense adds an expense to the budget of the conversation, creating it if needed.
+	AddExpense(ctx context.Context, conversationID primitive.ObjectID, userID string, e Expense) (*Budget, error)
+}
+
+// Status is where a budget stands, in the currency of its target.
+type Status struct {
+	// Target is zero until set
+	Target pricing.Money
+	// Spent adds up every expense, Booked only those not estimated
+	Spent  pricing.Money
+	Booked pricing.Money
+	// Remaining is negative once over budget
+	Remaining  pricing.Money
+	ByCategory map[string]pricing.Money
+}
+
+// ValidCategory reports whether category is one of Categories.
+func ValidCategory(category string) bool {
+	return slices.Contains(Categories, category)
+}
+
+// Status totals the expenses of b in the currency of its target, or of its first
+// expense until it has one, converting the others with rates.
+func (b *Budget) Status(ctx context.Context, rates pricing.Rates) (*Status, error) {
+	currency := b.Target.Currency
+	if currency == "" && len(b.Expenses) > 0 {
+		currency = b.Expenses[0].Amount.Currency
+	}
+	st := &Status{
+		Target:     b.Target,
+		Spent:      pricing.Money{Currency: currency},
+		Booked:     pricing.Money{Currency: currency},
+		ByCategory: make(map[string]pricing.Money),
+	}
+
+	for _, e := range b.Expenses {
+		amount, err := pricing.Convert(ctx, rates, e.Amount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s: %w", e.Amount, err)
+		}
+		st.Spent.Amount += amount.Amount
+		if !e.Estimated {
+			st.Booked.Amount += amount.Amount
+		}
+		category := st.ByCategory[e.Category]
+		category.Currency = currency
+		category.Amount += amount.Amount
+		st.ByCategory[e.Category] = category
+	}
+
+	st.Remaining = pricing.Money{Amount: b.Target.Amount - st.Spent.Amount, Currency: currency}
+	return st, nil
+}
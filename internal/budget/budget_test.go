@@ -0,0 +1,54 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/pricing"
+)
+
+// fixedRates converts from USD to EUR only.
+type fixedRates struct{}
+
+func (fixedRates) Rate(_ context.Context, from, to string) (float64, error) {
+	if from == "USD" && to == "EUR" {
+		return 0.9, nil
+	}
+	return 0, fmt.Errorf("no rate from %s to %s", from, to)
+}
+
+func TestBudget_Status(t *testing.T) {
+	b := &Budget{
+		Target: pricing.Money{Amount: 100000, Currency: "EUR"},
+		Expenses: []Expense{
+			{Category: CategoryFlight, Amount: pricing.Money{Amount: 43220, Currency: "EUR"}},
+			{Category: CategoryLodging, Amount: pricing.Money{Amount: 50000, Currency: "USD"}, Estimated: true},
+			{Category: CategoryFlight, Amount: pricing.Money{Amount: 12000, Currency: "EUR"}},
+		},
+	}
+
+	st, err := b.Status(context.Background(), fixedRates{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Spent.String() != "1002.20 EUR" || st.Booked.String() != "552.20 EUR" || st.Remaining.String() != "-2.20 EUR" {
+		t.Errorf("Status() spent %s, booked %s, remaining %s", st.Spent, st.Booked, st.Remaining)
+	}
+	if st.ByCategory[CategoryFlight].String() != "552.20 EUR" || st.ByCategory[CategoryLodging].String() != "450.00 EUR" {
+		t.Errorf("Status() by category = %v", st.ByCategory)
+	}
+
+	b.Expenses = append(b.Expenses, Expense{Amount: pricing.Money{Amount: 100, Currency: "GBP"}})
+	if _, err := b.Status(context.Background(), fixedRates{}); err == nil {
+		t.Error("Status() with an expense without a rate succeeded")
+	}
+}
+
+func TestBudget_StatusWithoutTarget(t *testing.T) {
+	b := &Budget{Expenses: []Expense{{Category: CategoryFood, Amount: pricing.Money{Amount: 2000, Currency: "USD"}}}}
+	st, err := b.Status(context.Background(), fixedRates{})
+	if err != nil || st.Spent.String() != "20.00 USD" || !st.Target.IsZero() {
+		t.Errorf("Status() = %+v, %v, want totals in the currency of the expenses", st, err)
+	}
+}
@@ -0,0 +1,109 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pricing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName       = "github.com/acai-travel/tech-challenge/internal/budget"
+	budgetCollection = "budgets"
+)
+
+// ErrTooManyExpenses is returned adding an expense to a budget with MaxExpenses already.
+var ErrTooManyExpenses = errors.New("the budget has too many expenses")
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) DescribeBudget(ctx context.Context, conversationID primitive.ObjectID) (*Budget, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeBudget")
+	span.SetAttributes(attribute.String("conversation.id", conversationID.Hex()))
+	defer span.End()
+
+	var b Budget
+	err := r.conn.Collection(budgetCollection).FindOne(ctx, bson.M{"_id": conversationID}).Decode(&b)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "no budget")
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to describe budget")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "budget described")
+	return &b, nil
+}
+
+func (r *Repository) SetTarget(ctx context.Context, conversationID primitive.ObjectID, userID string, target pricing.Money, now time.Time) (*Budget, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SetTarget")
+	span.SetAttributes(attribute.String("conversation.id", conversationID.Hex()))
+	defer span.End()
+
+	b, err := r.upsert(ctx, bson.M{"_id": conversationID}, bson.M{
+		"$set":         bson.M{"target": target, "updated_at": now},
+		"$setOnInsert": bson.M{"user_id": userID, "expenses": bson.A{}, "created_at": now},
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to set budget target")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "budget target set")
+	return b, nil
+}
+
+func (r *Repository) AddExpense(ctx context.Context, conversationID primitive.ObjectID, userID string, e Expense) (*Budget, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.AddExpense")
+	span.SetAttributes(attribute.String("conversation.id", conversationID.Hex()))
+	defer span.End()
+
+	// A full budget doesn't match, so the upsert inserts a second document with its
+	// ID, which fails
+	b, err := r.upsert(ctx,
+		bson.M{"_id": conversationID, "expenses." + strconv.Itoa(MaxExpenses-1): bson.M{"$exists": false}},
+		bson.M{
+			"$push":        bson.M{"expenses": e},
+			"$set":         bson.M{"updated_at": e.AddedAt},
+			"$setOnInsert": bson.M{"user_id": userID, "created_at": e.AddedAt},
+		})
+	if mongo.IsDuplicateKeyError(err) {
+		err = ErrTooManyExpenses
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to add expense")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "expense added")
+	return b, nil
+}
+
+func (r *Repository) upsert(ctx context.Context, filter, update bson.M) (*Budget, error) {
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var b Budget
+	if err := r.conn.Collection(budgetCollection).FindOneAndUpdate(ctx, filter, update, opts).Decode(&b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
@@ -2,137 +2,276 @@ package assistant
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
+	"github.com/acai-travel/tech-challenge/internal/attachment"
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/checkpoint"
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/acai-travel/tech-challenge/internal/logging"
 	"github.com/acai-travel/tech-challenge/internal/tools"
 	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// Models and system prompts of titles and replies. Changing them changes Fingerprint.
+const (
+	titleModel        = openai.ChatModelGPT5
+	titlePrompt       = "Return ONLY a concise 2–6 word title summarizing the user's question. Do not answer the question. No punctuation or emojis. Max 80 chars."
+	defaultReplyModel = openai.ChatModelGPT4_1
+	replyPrompt       = "You are a helpful, concise AI assistant. Provide accurate, safe, and clear responses. For time-sensitive queries (flights, weather forecasts, holidays, etc.), always use the get_today_date tool first to ensure you have the correct current date before making other API calls. If a tool result has the status \"needs_clarification\", ask the user its question instead of guessing the missing information."
+	noToolsPrompt     = "You are a helpful, concise AI assistant. Provide accurate, safe, and clear responses. You cannot look anything up in this conversation: answer from what you know, and when a question needs live data (flights, weather forecasts, holidays, today's date), say that you can't check it right now."
+)
+
+var repliesInFlight metric.Int64UpDownCounter
+
+func init() {
+	var err error
+	repliesInFlight, err = otel.Meter("github.com/acai-travel/tech-challenge/internal/chat/assistant").Int64UpDownCounter(
+		"assistant.reply.in_flight",
+		metric.WithDescription("Number of replies being generated"),
+		metric.WithUnit("{reply}"),
+	)
+	if err != nil {
+		// If metric creation fails, the counter will be nil and won't record anything
+	}
+}
+
 type Assistant struct {
-	cli           openai.Client
-	buildRegistry func(conv *model.Conversation) *tools.Registry
+	cli            openai.Client
+	buildRegistry  func(conv *model.Conversation) *tools.Registry
+	extraTools     []ToolFactory
+	toolMiddleware []tools.Middleware
+	attachments    AttachmentLoader
+	replyOptions   ReplyOptions
+	budget         Budget
+	checkpoints    CheckpointStore
+	replyModel     openai.ChatModel
+	fallbackModel  openai.ChatModel
+	nearLimit      func(model string) bool
+	instructions   string
+	recall         Recaller
+	toolPolicy     *tools.Policy
 }
 
-func New() *Assistant {
-	return &Assistant{
-		cli: openai.NewClient(),
-		buildRegistry: func(conv *model.Conversation) *tools.Registry {
-			r := tools.NewRegistry()
-			r.Register(tools.NewGetWeatherTool(conv))
-			r.Register(tools.NewGetWeatherForecastTool(conv))
-			r.Register(tools.NewGetTodayDateTool())
-			r.Register(tools.NewGetHolidaysTool())
-			r.Register(tools.NewGetFlightPricesTool(conv))
-			return r
-		},
-	}
+// AttachmentLoader loads the contents of message attachments.
+type AttachmentLoader interface {
+	Get(ctx context.Context, id string) ([]byte, error)
 }
 
-// NewWithRegistryFactory allows injecting a custom per-conversation registry builder.
-func NewWithRegistryFactory(build func(*model.Conversation) *tools.Registry) *Assistant {
-	return &Assistant{
-		cli:           openai.NewClient(),
-		buildRegistry: build,
+// ToolFactory builds a tool bound to a single conversation.
+type ToolFactory func(conv *model.Conversation) tools.Tool
+
+type Option func(*Assistant)
+
+// WithTools registers additional tools on top of the default ones, typically tools
+// that depend on stores only available to the server.
+func WithTools(factories ...ToolFactory) Option {
+	return func(a *Assistant) {
+		a.extraTools = append(a.extraTools, factories...)
 	}
 }
 
-func (a *Assistant) Title(ctx context.Context, conv *model.Conversation) (string, error) {
-	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant")
-	ctx, span := tracer.Start(ctx, "Assistant.Title",
-		trace.WithAttributes(
-			attribute.String("conversation.id", conv.ID.Hex()),
-			attribute.Int("conversation.message_count", len(conv.Messages)),
-		),
-	)
-	defer span.End()
-
-	if len(conv.Messages) == 0 {
-		return "An empty conversation", nil
+// WithToolMiddleware wraps the execution of every tool of the default registry.
+func WithToolMiddleware(mw ...tools.Middleware) Option {
+	return func(a *Assistant) {
+		a.toolMiddleware = append(a.toolMiddleware, mw...)
 	}
+}
 
-	slog.InfoContext(ctx, "Generating title for conversation", "conversation_id", conv.ID)
+// WithToolPolicy restricts the tools of the default registry, and their arguments, by
+// the user and persona of each conversation.
+func WithToolPolicy(p *tools.Policy) Option {
+	return func(a *Assistant) {
+		a.toolPolicy = p
+	}
+}
 
-	systemPrompt := "Return ONLY a concise 2–6 word title summarizing the user's question. Do not answer the question. No punctuation or emojis. Max 80 chars."
-	userMessage := conv.Messages[0].Content
+// WithAttachments lets Reply pass message attachments (images and PDFs) to the model.
+func WithAttachments(loader AttachmentLoader) Option {
+	return func(a *Assistant) {
+		a.attachments = loader
+	}
+}
 
-	// Logging the system prompt and user message
-	slog.InfoContext(ctx, "API Request",
-		"system_prompt", systemPrompt,
-		"user_message", userMessage)
+// WithReplyModel replies with m instead of the default model.
+func WithReplyModel(m openai.ChatModel) Option {
+	return func(a *Assistant) {
+		a.replyModel = m
+	}
+}
 
-	msgs := []openai.ChatCompletionMessageParamUnion{
-		openai.SystemMessage(systemPrompt),
-		openai.UserMessage(userMessage),
+// WithFallbackModel replies with fallback instead of the reply model while nearLimit
+// reports the reply model close to its OpenAI rate limits, rather than slowing down
+// until they reset.
+func WithFallbackModel(fallback openai.ChatModel, nearLimit func(model string) bool) Option {
+	return func(a *Assistant) {
+		a.fallbackModel = fallback
+		a.nearLimit = nearLimit
 	}
+}
 
-	// Create a child span for the OpenAI API call
-	_, apiSpan := tracer.Start(ctx, "OpenAI.ChatCompletion.Title",
-		trace.WithAttributes(
-			attribute.String("openai.model", string(openai.ChatModelGPT5Nano)),
-			attribute.Int("openai.messages", len(msgs)),
-		),
-	)
+// modelFor returns the model of the next reply, the fallback one while the reply model
+// is close to its rate limits.
+func (a *Assistant) modelFor(ctx context.Context) openai.ChatModel {
+	if a.nearLimit == nil || a.fallbackModel == "" || a.fallbackModel == a.replyModel || !a.nearLimit(a.replyModel) {
+		return a.replyModel
+	}
+	slog.WarnContext(ctx, "Reply model is close to its rate limits, switching", "model", a.replyModel, "fallback", a.fallbackModel)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("reply.fallback_model", a.fallbackModel))
+	return a.fallbackModel
+}
 
-	resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Model:    openai.ChatModelGPT5,
-		Messages: msgs,
-	})
+// WithClientOptions configures the OpenAI client of the assistant, like a limit on the
+// calls in flight shared with other clients.
+func WithClientOptions(opts ...option.RequestOption) Option {
+	return func(a *Assistant) {
+		a.cli = openai.NewClient(opts...)
+	}
+}
 
-	apiSpan.End()
+// WithInstructions adds a system prompt to every reply, on top of the default one and
+// before the conversation's own instructions.
+func WithInstructions(instructions string) Option {
+	return func(a *Assistant) {
+		a.instructions = instructions
+	}
+}
 
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "OpenAI API call failed")
-		return "", err
+func New(opts ...Option) *Assistant {
+	a := &Assistant{cli: openai.NewClient(), budget: DefaultBudget, replyModel: defaultReplyModel}
+	for _, opt := range opts {
+		opt(a)
 	}
 
-	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
-		err := errors.New("empty response from OpenAI for title generation")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "empty response")
-		return "", err
+	routes := tools.NewRouteProviderFromEnv()
+	a.buildRegistry = func(conv *model.Conversation) *tools.Registry {
+		r := tools.NewRegistry()
+		r.Register(tools.NewGetWeatherTool(conv))
+		r.Register(tools.NewGetWeatherForecastTool(conv))
+		r.Register(tools.NewGetTodayDateTool())
+		r.Register(tools.NewGetHolidaysTool())
+		r.Register(tools.NewGetFlightPricesTool(conv))
+		r.Register(tools.NewGetFlightStatusTool())
+		r.Register(tools.NewSearchTransfersTool(conv))
+		r.Register(tools.NewGeneratePackingListTool(conv))
+		r.Register(tools.NewGetTravelTimeTool(routes))
+		for _, build := range a.extraTools {
+			r.Register(build(conv))
+		}
+		// Refused calls are left out of the conversation's entities
+		subject := tools.Subject{UserID: conv.UserID, Persona: conv.Persona}
+		a.toolPolicy.Restrict(r, subject)
+		r.Use(tools.EnforcePolicy(a.toolPolicy, subject))
+		r.Use(tools.TrackEntities(conv))
+		r.Use(a.toolMiddleware...)
+		return r
 	}
 
-	slog.InfoContext(ctx, "Title API Response", "raw_title", resp.Choices[0].Message.Content)
+	return a
+}
 
-	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
-		err := errors.New("empty response from OpenAI for title generation")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "empty response")
-		return "", err
+// NewWithRegistryFactory allows injecting a custom per-conversation registry builder.
+// Options adding tools to the default registry don't apply.
+func NewWithRegistryFactory(build func(*model.Conversation) *tools.Registry, opts ...Option) *Assistant {
+	a := &Assistant{
+		cli:           openai.NewClient(),
+		buildRegistry: build,
+		budget:        DefaultBudget,
+		replyModel:    defaultReplyModel,
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	return a
+}
+
+func (a *Assistant) Title(ctx context.Context, conv *model.Conversation) (string, error) {
+	ctx = logging.WithConversationID(ctx, conv.ID.Hex())
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant")
+	ctx, span := tracer.Start(ctx, "Assistant.Title",
+		trace.WithAttributes(
+			semconv.GenAIConversationID(conv.ID.Hex()),
+			attribute.Int("conversation.message_count", len(conv.Messages)),
+		),
+	)
+	defer span.End()
 
-	title := resp.Choices[0].Message.Content
-	title = strings.ReplaceAll(title, "\n", " ")
-	title = strings.Trim(title, " \t\r\n-\"'")
+	if len(conv.Messages) == 0 {
+		return "An empty conversation", nil
+	}
 
-	if len(title) > 80 {
-		title = title[:80]
+	slog.InfoContext(ctx, "Generating title for conversation")
+
+	// The title model, then a cheaper one, then the first words of the message: a
+	// conversation always gets a title, unless the request itself is cancelled
+	message := conv.Messages[0].Content
+	title, source := "", model.TitleSourceDefault
+	for _, m := range []struct {
+		model  openai.ChatModel
+		source string
+	}{
+		{titleModel, model.TitleSourceModel},
+		{titleFallbackModel, model.TitleSourceFallbackModel},
+	} {
+		t, err := a.requestTitle(ctx, m.model, message)
+		if err == nil {
+			title, source = t, m.source
+			break
+		}
+		if ctx.Err() != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "request cancelled")
+			return "", ctx.Err()
+		}
+		slog.WarnContext(ctx, "Failed to generate title", "model", m.model, "error", err)
+	}
+	if title == "" {
+		if title = heuristicTitle(message); title != "" {
+			source = model.TitleSourceHeuristic
+		} else {
+			title = DefaultTitle
+		}
 	}
 
-	span.SetAttributes(attribute.String("title.generated", title))
+	conv.TitleSource = source
+	recordTitleSource(ctx, source)
+	span.SetAttributes(
+		attribute.String("title.generated", title),
+		attribute.String("title.source", source),
+	)
 	span.SetStatus(codes.Ok, "title generated successfully")
 
 	return title, nil
 }
 
 func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string, error) {
+	ctx = logging.WithConversationID(ctx, conv.ID.Hex())
 	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant")
 	ctx, span := tracer.Start(ctx, "Assistant.Reply",
 		trace.WithAttributes(
-			attribute.String("conversation.id", conv.ID.Hex()),
+			semconv.GenAIConversationID(conv.ID.Hex()),
 			attribute.Int("conversation.message_count", len(conv.Messages)),
 		),
 	)
 	defer span.End()
 
+	if repliesInFlight != nil {
+		repliesInFlight.Add(ctx, 1)
+		defer repliesInFlight.Add(context.WithoutCancel(ctx), -1)
+	}
+
 	if len(conv.Messages) == 0 {
 		err := errors.New("conversation has no messages")
 		span.RecordError(err)
@@ -140,47 +279,117 @@ func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string
 		return "", err
 	}
 
-	slog.InfoContext(ctx, "Generating reply for conversation", "conversation_id", conv.ID)
+	slog.InfoContext(ctx, "Generating reply for conversation")
+
+	opts := a.replyOptionsFor(ctx)
+	if opts.DisableTools || conv.ToolsDisabled {
+		span.SetAttributes(attribute.Bool("reply.tools_disabled", true))
+		reply, err := a.replyWithoutTools(ctx, conv)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "reply without tools failed")
+			return "", expired(ctx, err, 0, nil)
+		}
+		span.SetAttributes(attribute.String("reply.content", reply))
+		span.SetStatus(codes.Ok, "reply generated successfully")
+		return reply, nil
+	}
 
-	// Build a per-conversation registry
+	// Build a per-conversation registry, with the tools of the conversation's persona
 	registry := a.buildRegistry(conv)
+	restrictTools(registry, conv)
 
-	msgs := []openai.ChatCompletionMessageParamUnion{
-		openai.SystemMessage("You are a helpful, concise AI assistant. Provide accurate, safe, and clear responses. For time-sensitive queries (flights, weather forecasts, holidays, etc.), always use the get_today_date tool first to ensure you have the correct current date before making other API calls."),
+	if err := opts.validate(registry); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid reply options")
+		return "", err
 	}
 
-	for _, m := range conv.Messages {
-		switch m.Role {
-		case model.RoleUser:
-			msgs = append(msgs, openai.UserMessage(m.Content))
-		case model.RoleAssistant:
-			msgs = append(msgs, openai.AssistantMessage(m.Content))
-		}
+	// A retried attempt resumes from its checkpoint instead of re-running completed steps
+	run := a.startAttempt(ctx, conv)
+	if reply, ok := run.completed(); ok {
+		reportCitations(ctx, run.citations())
+		span.SetAttributes(attribute.Bool("reply.resumed", true))
+		span.SetStatus(codes.Ok, "reply restored from checkpoint")
+		return reply, nil
 	}
 
-	for i := 0; i < 15; i++ {
+	// Classify the messages waiting for a reply to route the tool loop. The intent is
+	// stored on them so it is persisted with the conversation.
+	waiting := pending(conv)
+	last := waiting[len(waiting)-1]
+	intent := run.intent()
+	if intent == "" {
+		intent = a.classifyIntent(ctx, pendingContent(waiting))
+		run.setIntent(ctx, intent)
+	}
+	for _, m := range waiting {
+		m.Intent = string(intent)
+	}
+	span.SetAttributes(attribute.String("assistant.intent", string(intent)))
+
+	msgs := a.history(ctx, conv, replyPrompt)
+
+	// The tools called and tokens spent are stored on the user message, for statistics
+	toolCalls := run.toolCalls()
+	usage := &model.Usage{}
+	// The tool results taken from external sources are cited by the reply
+	citations := run.citations()
+
+	resumed := run.steps()
+	msgs = run.replay(msgs)
+	if resumed > 0 {
+		slog.InfoContext(ctx, "Resuming reply from checkpoint", "steps", resumed)
+		span.SetAttributes(attribute.Int("reply.resumed_steps", resumed))
+	}
+
+	// The loop stops early enough to leave the reserve for a best-effort answer. A
+	// closer request deadline shrinks the steps and the reserve to fit.
+	now := time.Now()
+	deadline := a.budget.deadline(ctx, now)
+	budget := a.budget.fit(deadline.Sub(now))
+	loopCtx, cancelLoop := context.WithDeadline(ctx, deadline.Add(-budget.Reserve))
+	defer cancelLoop()
+
+	replyModel := a.modelFor(ctx)
+	steps := resumed
+	for i := resumed; i < budget.MaxIterations && loopCtx.Err() == nil; i++ {
+		iterCtx, cancelIter := context.WithTimeout(loopCtx, budget.PerIteration)
+
+		params := openai.ChatCompletionNewParams{
+			Model:      replyModel,
+			Messages:   msgs,
+			Tools:      registry.Definitions(),
+			ToolChoice: opts.toolChoice(intent, registry, i),
+		}
+		if opts.ParallelToolCalls != nil {
+			params.ParallelToolCalls = openai.Bool(*opts.ParallelToolCalls)
+		}
+		applyCreativity(&params, conv.Settings)
+
 		// Create a child span for each OpenAI API call iteration
-		_, iterSpan := tracer.Start(ctx, "OpenAI.ChatCompletion.Reply",
-			trace.WithAttributes(
-				attribute.String("openai.model", string(openai.ChatModelGPT5Mini)),
-				attribute.Int("openai.messages", len(msgs)),
-				attribute.Int("iteration", i),
-			),
-		)
+		callCtx, iterSpan := genai.StartChat(iterCtx, tracer, params.Model, attribute.Int("iteration", i))
+
+		resp, err := a.cli.Chat.Completions.New(callCtx, params)
+		genai.RecordResponse(iterSpan, resp)
+		addUsage(usage, resp)
 
-		resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-			Model:    openai.ChatModelGPT4_1,
-			Messages: msgs,
-			Tools:    registry.Definitions(),
-		})
+		if budgetExceeded(ctx, err) {
+			iterSpan.RecordError(err)
+			iterSpan.SetStatus(codes.Error, "budget exceeded")
+			iterSpan.End()
+			cancelIter()
+			break
+		}
 
 		if err != nil {
 			iterSpan.RecordError(err)
 			iterSpan.SetStatus(codes.Error, "API call failed")
 			iterSpan.End()
+			cancelIter()
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "OpenAI API call failed")
-			return "", err
+			return "", expired(ctx, err, steps, toolCalls)
 		}
 
 		if len(resp.Choices) == 0 {
@@ -188,36 +397,71 @@ func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string
 			iterSpan.RecordError(err)
 			iterSpan.SetStatus(codes.Error, "no choices")
 			iterSpan.End()
+			cancelIter()
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "no choices")
 			return "", err
 		}
 
 		if message := resp.Choices[0].Message; len(message.ToolCalls) > 0 {
-			iterSpan.SetAttributes(attribute.Int("tool_calls.count", len(message.ToolCalls)))
 			iterSpan.End()
 
 			msgs = append(msgs, message.ToParam())
 
-			for _, call := range message.ToolCalls {
+			// Every call gets a result, even when the budget runs out, so the
+			// conversation stays valid for the best-effort answer
+			step := checkpoint.Step{Content: message.Content}
+			for j, call := range message.ToolCalls {
 				slog.InfoContext(ctx, "Tool call received", "name", call.Function.Name, "args", call.Function.Arguments)
 
-				result, err := registry.Execute(ctx, call.Function.Name, []byte(call.Function.Arguments))
+				reportProgress(ctx, call.Function.Name)
+				shareCtx, cancelShare := withToolShare(iterCtx, len(message.ToolCalls)-j)
+				toolCtx, toolSpan := genai.StartTool(shareCtx, tracer, call.Function.Name, call.ID)
+				tc := checkpoint.ToolCall{
+					ID:        call.ID,
+					Name:      call.Function.Name,
+					Arguments: call.Function.Arguments,
+					FetchedAt: time.Now(),
+				}
+				result, err := registry.Execute(toolCtx, call.Function.Name, []byte(call.Function.Arguments))
 				if err != nil {
 					slog.ErrorContext(ctx, "Tool execution failed", "tool", call.Function.Name, "error", err)
-					msgs = append(msgs, openai.ToolMessage(err.Error(), call.ID))
-				} else {
-					msgs = append(msgs, openai.ToolMessage(result, call.ID))
+					toolSpan.RecordError(err)
+					toolSpan.SetStatus(codes.Error, "tool execution failed")
+					result = err.Error()
+				} else if c, ok := tools.ParseClarification(result); ok {
+					slog.InfoContext(ctx, "Tool needs clarification", "tool", call.Function.Name, "missing", c.Missing)
+					toolSpan.SetAttributes(attribute.String("tool.clarification.missing", c.Missing))
+				} else if d, ok := tools.ParseDenial(result); ok {
+					slog.InfoContext(ctx, "Tool call denied by policy", "tool", call.Function.Name, "reason", d.Reason)
+					toolSpan.SetAttributes(attribute.String("tool.denied.reason", d.Reason))
+				} else if c, ok := cite(registry, tc.Name, tc.Arguments, tc.FetchedAt); ok {
+					citations = append(citations, c)
+					tc.Source, tc.ExpiresAt = c.Source, c.ExpiresAt
 				}
+				toolSpan.End()
+				cancelShare()
+
+				msgs = append(msgs, openai.ToolMessage(result, call.ID))
+				toolCalls = append(toolCalls, call.Function.Name)
+				tc.Result = result
+				step.ToolCalls = append(step.ToolCalls, tc)
 			}
 
+			run.addStep(ctx, step)
+			steps++
+			cancelIter()
 			continue
 		}
 
 		iterSpan.SetStatus(codes.Ok, "reply generated")
 		iterSpan.End()
+		cancelIter()
 
-		reply := resp.Choices[0].Message.Content
+		reply := formatReply(resp.Choices[0].Message.Content, conv.Settings)
+		run.finish(ctx, reply)
+		recordTurn(last, toolCalls, usage)
+		reportCitations(ctx, citations)
 		span.SetAttributes(
 			attribute.String("reply.content", reply),
 			attribute.Int("iterations", i+1),
@@ -226,8 +470,157 @@ func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string
 		return reply, nil
 	}
 
-	err := errors.New("too many tool calls, unable to generate reply")
-	span.RecordError(err)
-	span.SetStatus(codes.Error, "too many iterations")
-	return "", err
+	if err := ctx.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "request cancelled")
+		return "", expired(ctx, err, steps, toolCalls)
+	}
+
+	// Out of time or steps: answer with what was gathered so far
+	slog.WarnContext(ctx, "Reply budget exhausted, answering with best effort", "deadline_exceeded", loopCtx.Err() != nil)
+	span.SetAttributes(attribute.Bool("reply.budget_exhausted", true))
+
+	bestEffortCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	reply, err := a.bestEffort(bestEffortCtx, replyModel, msgs, registry.Definitions(), conv.Settings, usage)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "best-effort reply failed")
+		return "", expired(ctx, fmt.Errorf("unable to generate reply within budget: %w", err), steps, toolCalls)
+	}
+	reply = formatReply(reply, conv.Settings)
+	run.finish(ctx, reply)
+	recordTurn(last, toolCalls, usage)
+	reportCitations(ctx, citations)
+
+	span.SetAttributes(attribute.String("reply.content", reply))
+	span.SetStatus(codes.Ok, "best-effort reply generated")
+	return reply, nil
+}
+
+// history returns the messages of conv for the model, after the system prompt, the
+// conversation's persona, the instructions of its template, and its verbosity,
+// format and length.
+func (a *Assistant) history(ctx context.Context, conv *model.Conversation, prompt string) []openai.ChatCompletionMessageParamUnion {
+	msgs := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(prompt),
+	}
+	if p, ok := personas[conv.Persona]; ok {
+		msgs = append(msgs, openai.SystemMessage(p.prompt))
+	}
+	if a.instructions != "" {
+		msgs = append(msgs, openai.SystemMessage(a.instructions))
+	}
+	if conv.Instructions != "" {
+		msgs = append(msgs, openai.SystemMessage(conv.Instructions))
+	}
+	if p, ok := verbosityPrompts[conv.Settings.Verbosity]; ok {
+		msgs = append(msgs, openai.SystemMessage(p))
+	}
+	if p, ok := formatPrompts[conv.Settings.Format]; ok {
+		msgs = append(msgs, openai.SystemMessage(p))
+	}
+	if conv.Settings.MaxLength > 0 {
+		msgs = append(msgs, openai.SystemMessage(lengthPrompt(conv.Settings.MaxLength)))
+	}
+
+	// Entities remembered from earlier tool calls let follow-ups like "and the weekend?"
+	// resolve against the destination discussed before
+	if !conv.Entities.IsZero() {
+		msgs = append(msgs, openai.SystemMessage("Remembered from this conversation: "+conv.Entities.String()+". Use these when the user doesn't say otherwise."))
+	}
+	if m, ok := a.recalled(ctx, conv); ok {
+		msgs = append(msgs, m)
+	}
+
+	for _, m := range conv.Messages {
+		switch m.Role {
+		case model.RoleUser:
+			msgs = append(msgs, a.userMessage(ctx, m))
+		case model.RoleAssistant:
+			// Replies that failed or are still being generated have nothing to say yet
+			if m.State() == model.MessageStatusCompleted {
+				msgs = append(msgs, openai.AssistantMessage(m.Content))
+			}
+		}
+	}
+	if len(pending(conv)) > 1 {
+		msgs = append(msgs, openai.SystemMessage(batchPrompt))
+	}
+	return msgs
+}
+
+// batchPrompt asks for a single reply to user messages sent at once.
+const batchPrompt = "The user sent their last messages in quick succession. Answer them together in a single, coherent reply rather than one by one."
+
+// pending returns the user messages of conv waiting for a reply: the last one, or the
+// several a client sent at once, in order.
+func pending(conv *model.Conversation) []*model.Message {
+	i := len(conv.Messages) - 1
+	for i > 0 && conv.Messages[i-1].Role == model.RoleUser {
+		i--
+	}
+	return conv.Messages[i:]
+}
+
+// pendingContent joins the contents of the messages waiting for a reply, one per line.
+func pendingContent(waiting []*model.Message) string {
+	contents := make([]string, 0, len(waiting))
+	for _, m := range waiting {
+		contents = append(contents, m.Content)
+	}
+	return strings.Join(contents, "\n")
+}
+
+// addUsage adds the tokens of a chat completion to usage.
+func addUsage(usage *model.Usage, resp *openai.ChatCompletion) {
+	if resp == nil {
+		return
+	}
+	usage.InputTokens += resp.Usage.PromptTokens
+	usage.OutputTokens += resp.Usage.CompletionTokens
+}
+
+// recordTurn stores the tools called and tokens spent to answer the user message m.
+func recordTurn(m *model.Message, toolCalls []string, usage *model.Usage) {
+	m.ToolCalls = toolCalls
+	if usage.InputTokens > 0 || usage.OutputTokens > 0 {
+		m.Usage = usage
+	}
+}
+
+// userMessage converts a user message, adding its attachments as image or file parts
+// for the vision-capable model.
+func (a *Assistant) userMessage(ctx context.Context, m *model.Message) openai.ChatCompletionMessageParamUnion {
+	if len(m.Attachments) == 0 {
+		return openai.UserMessage(m.Content)
+	}
+
+	parts := []openai.ChatCompletionContentPartUnionParam{openai.TextContentPart(m.Content)}
+	for _, att := range m.Attachments {
+		var data []byte
+		err := errors.New("attachments are not enabled")
+		if a.attachments != nil {
+			data, err = a.attachments.Get(ctx, att.ID)
+		}
+
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to load attachment", "attachment_id", att.ID, "error", err)
+			parts = append(parts, openai.TextContentPart(fmt.Sprintf("[Attachment %q could not be loaded]", att.Filename)))
+			continue
+		}
+
+		dataURL := "data:" + att.ContentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+		if attachment.IsImage(att.ContentType) {
+			parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: dataURL}))
+		} else {
+			parts = append(parts, openai.FileContentPart(openai.ChatCompletionContentPartFileFileParam{
+				FileData: openai.String(dataURL),
+				Filename: openai.String(att.Filename),
+			}))
+		}
+	}
+
+	return openai.UserMessage(parts)
 }
@@ -1,12 +1,25 @@
 package assistant
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/checkpoint"
+	"github.com/acai-travel/tech-challenge/internal/recall"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -367,3 +380,997 @@ func TestAssistant_Title_Format(t *testing.T) {
 		})
 	}
 }
+
+type attachmentLoader map[string][]byte
+
+func (l attachmentLoader) Get(ctx context.Context, id string) ([]byte, error) {
+	if data, ok := l[id]; ok {
+		return data, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestAssistant_userMessage(t *testing.T) {
+	a := New(WithAttachments(attachmentLoader{
+		"img": []byte("png-bytes"),
+		"pdf": []byte("%PDF-1.7"),
+	}))
+
+	msg := a.userMessage(context.Background(), &model.Message{
+		Role:    model.RoleUser,
+		Content: "What time is my flight?",
+		Attachments: []*model.Attachment{
+			{ID: "img", Filename: "booking.png", ContentType: "image/png"},
+			{ID: "pdf", Filename: "ticket.pdf", ContentType: "application/pdf"},
+			{ID: "missing", Filename: "lost.jpg", ContentType: "image/jpeg"},
+		},
+	})
+
+	parts := msg.OfUser.Content.OfArrayOfContentParts
+	if len(parts) != 4 {
+		t.Fatalf("got %d content parts, want 4", len(parts))
+	}
+
+	if parts[0].OfText == nil || parts[0].OfText.Text != "What time is my flight?" {
+		t.Errorf("first part should be the message text")
+	}
+	if parts[1].OfImageURL == nil || !strings.HasPrefix(parts[1].OfImageURL.ImageURL.URL, "data:image/png;base64,") {
+		t.Errorf("second part should be the image as a data URL")
+	}
+	if parts[2].OfFile == nil || parts[2].OfFile.File.Filename.Value != "ticket.pdf" {
+		t.Errorf("third part should be the PDF file")
+	}
+	if parts[3].OfText == nil || !strings.Contains(parts[3].OfText.Text, "lost.jpg") {
+		t.Errorf("missing attachment should be replaced by a note")
+	}
+
+	plain := a.userMessage(context.Background(), &model.Message{Role: model.RoleUser, Content: "Hi"})
+	if plain.OfUser.Content.OfString.Value != "Hi" {
+		t.Errorf("messages without attachments should stay plain text")
+	}
+}
+
+func TestParseFollowUps(t *testing.T) {
+	got, err := parseFollowUps(`{"suggestions": ["What about Porto?", " ", "what about porto?", "Any festivals?", "Best beaches?", "Cheap flights?"]}`)
+	if err != nil {
+		t.Fatalf("parseFollowUps() error = %v", err)
+	}
+
+	want := []string{"What about Porto?", "Any festivals?", "Best beaches?"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("parseFollowUps() = %q, want %q", got, want)
+	}
+
+	if _, err := parseFollowUps("not json"); err == nil {
+		t.Error("parseFollowUps() expected error for invalid JSON")
+	}
+}
+
+func TestReplyOptions_toolChoice(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.Register(tools.NewGetHolidaysTool())
+
+	tests := []struct {
+		name      string
+		opts      ReplyOptions
+		intent    Intent
+		iteration int
+		want      string
+		wantTool  string
+	}{
+		{name: "intent with tool requires a tool call", intent: IntentHolidays, want: "required"},
+		{name: "later iterations are free", intent: IntentHolidays, iteration: 1},
+		{name: "intent without registered tool is free", intent: IntentWeather},
+		{name: "general chat is free", intent: IntentGeneral},
+		{name: "explicit auto overrides intent", opts: ReplyOptions{ToolChoice: ToolChoiceAuto}, intent: IntentHolidays, want: "auto"},
+		{name: "none disables tools on every iteration", opts: ReplyOptions{ToolChoice: ToolChoiceNone}, iteration: 3, want: "none"},
+		{name: "named tool is forced on first iteration", opts: ReplyOptions{ToolChoice: "get_holidays"}, wantTool: "get_holidays"},
+		{name: "named tool is released afterwards", opts: ReplyOptions{ToolChoice: "get_holidays"}, iteration: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.opts.toolChoice(tt.intent, registry, tt.iteration)
+			if got.OfAuto.Value != tt.want {
+				t.Errorf("toolChoice() = %q, want %q", got.OfAuto.Value, tt.want)
+			}
+
+			var gotTool string
+			if got.OfFunctionToolChoice != nil {
+				gotTool = got.OfFunctionToolChoice.Function.Name
+			}
+			if gotTool != tt.wantTool {
+				t.Errorf("toolChoice() tool = %q, want %q", gotTool, tt.wantTool)
+			}
+		})
+	}
+
+	if err := (ReplyOptions{ToolChoice: "get_weather"}).validate(registry); err == nil {
+		t.Error("validate() expected error for unavailable tool")
+	}
+}
+
+func TestAssistant_replyOptionsFor(t *testing.T) {
+	a := New(WithToolChoice(ToolChoiceRequired), WithParallelToolCalls(false))
+
+	got := a.replyOptionsFor(context.Background())
+	if got.ToolChoice != ToolChoiceRequired || got.ParallelToolCalls == nil || *got.ParallelToolCalls {
+		t.Errorf("defaults = %+v, want required tool choice without parallel calls", got)
+	}
+
+	ctx := WithReplyOptions(context.Background(), ReplyOptions{ToolChoice: ToolChoiceNone})
+	got = a.replyOptionsFor(ctx)
+	if got.ToolChoice != ToolChoiceNone || got.ParallelToolCalls == nil || *got.ParallelToolCalls {
+		t.Errorf("overridden = %+v, want none tool choice keeping the parallel default", got)
+	}
+}
+
+// fakeOpenAI answers chat completions: intent classification, a tool call to
+// get_today_date for every tool loop step (after an optional delay), and a final
+// answer once tools are disabled.
+func fakeOpenAI(t *testing.T, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		content, toolCalls := "", "null"
+		switch {
+		case req["response_format"] != nil:
+			content = `{\"intent\": \"general\"}`
+		case req["tool_choice"] == "none":
+			content = "Best effort answer"
+		default:
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			toolCalls = `[{"id": "call_1", "type": "function", "function": {"name": "get_today_date", "arguments": "{}"}}]`
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id": "1", "object": "chat.completion", "model": "gpt-4.1", "choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "%s", "tool_calls": %s}}]}`, content, toolCalls)
+	}))
+}
+
+func TestAssistant_Title_Fallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		failing    []string
+		message    string
+		want       string
+		wantSource string
+	}{
+		{
+			name:       "title model",
+			message:    "What's the weather in Lisbon this weekend?",
+			want:       "Lisbon Weekend Weather",
+			wantSource: model.TitleSourceModel,
+		},
+		{
+			name:       "cheaper model when the title model fails",
+			failing:    []string{titleModel},
+			message:    "What's the weather in Lisbon this weekend?",
+			want:       "Lisbon Weekend Weather",
+			wantSource: model.TitleSourceFallbackModel,
+		},
+		{
+			name:       "first words when both models fail",
+			failing:    []string{titleModel, titleFallbackModel},
+			message:    "what's the weather in Lisbon this weekend? We land at 9.",
+			want:       "What's the weather in Lisbon this",
+			wantSource: model.TitleSourceHeuristic,
+		},
+		{
+			name:       "default without words",
+			failing:    []string{titleModel, titleFallbackModel},
+			message:    "🌍✈️",
+			want:       DefaultTitle,
+			wantSource: model.TitleSourceDefault,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Model string `json:"model"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				if slices.Contains(tt.failing, req.Model) {
+					http.Error(w, `{"error": {"message": "overloaded"}}`, http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprint(w, `{"id": "1", "object": "chat.completion", "model": "gpt-5", "choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "Lisbon Weekend Weather"}}]}`)
+			}))
+			defer srv.Close()
+
+			a := New()
+			a.cli = openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+			conv := &model.Conversation{
+				ID:       primitive.NewObjectID(),
+				Messages: []*model.Message{{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: tt.message}},
+			}
+
+			got, err := a.Title(context.Background(), conv)
+			if err != nil {
+				t.Fatalf("Title() error = %v", err)
+			}
+			if got != tt.want || conv.TitleSource != tt.wantSource {
+				t.Errorf("Title() = %q from %q, want %q from %q", got, conv.TitleSource, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestAssistant_Reply_Budget(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget Budget
+		delay  time.Duration
+	}{
+		{
+			name:   "max iterations falls back to best effort",
+			budget: Budget{MaxIterations: 2},
+		},
+		{
+			name:   "per-iteration timeout falls back to best effort",
+			budget: Budget{PerIteration: 20 * time.Millisecond},
+			delay:  time.Second,
+		},
+		{
+			name:   "total deadline falls back to best effort",
+			budget: Budget{Total: 300 * time.Millisecond, Reserve: 200 * time.Millisecond},
+			delay:  50 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeOpenAI(t, tt.delay)
+			defer srv.Close()
+
+			a := NewWithRegistryFactory(func(*model.Conversation) *tools.Registry {
+				r := tools.NewRegistry()
+				r.Register(tools.NewGetTodayDateTool())
+				return r
+			})
+			WithBudget(tt.budget)(a)
+			a.cli = openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+
+			conv := &model.Conversation{
+				ID:       primitive.NewObjectID(),
+				Messages: []*model.Message{{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "What day is it?"}},
+			}
+
+			reply, err := a.Reply(context.Background(), conv)
+			if err != nil {
+				t.Fatalf("Reply() error = %v", err)
+			}
+			if reply != "Best effort answer" {
+				t.Errorf("Reply() = %q, want the best-effort answer", reply)
+			}
+		})
+	}
+}
+
+func TestAssistant_Reply_BestEffortModel(t *testing.T) {
+	fake := fakeOpenAI(t, 0)
+	defer fake.Close()
+
+	// The best-effort answer is the request without tool calls
+	bestEffort := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read request: %v", err)
+		}
+		var req struct {
+			Model      string `json:"model"`
+			ToolChoice any    `json:"tool_choice"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.ToolChoice == "none" {
+			bestEffort <- req.Model
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	a := NewWithRegistryFactory(func(*model.Conversation) *tools.Registry {
+		r := tools.NewRegistry()
+		r.Register(tools.NewGetTodayDateTool())
+		return r
+	}, WithReplyModel(openai.ChatModelGPT4oMini), WithBudget(Budget{MaxIterations: 1}))
+	a.cli = openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+
+	conv := &model.Conversation{
+		ID:       primitive.NewObjectID(),
+		Messages: []*model.Message{{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "What day is it?"}},
+	}
+	if _, err := a.Reply(context.Background(), conv); err != nil {
+		t.Fatalf("Reply() error = %v", err)
+	}
+
+	select {
+	case m := <-bestEffort:
+		if m != openai.ChatModelGPT4oMini {
+			t.Errorf("best-effort answer asked %q, want the reply model %q", m, openai.ChatModelGPT4oMini)
+		}
+	default:
+		t.Fatal("Reply() did not fall back to a best-effort answer")
+	}
+}
+
+func TestBudget_fit(t *testing.T) {
+	b := Budget{Total: 90 * time.Second, PerIteration: 30 * time.Second, MaxIterations: 15, Reserve: 15 * time.Second}
+
+	tests := []struct {
+		name      string
+		remaining time.Duration
+		want      Budget
+	}{
+		{
+			name:      "no closer deadline keeps the budget",
+			remaining: 2 * time.Minute,
+			want:      b,
+		},
+		{
+			name:      "closer deadline shrinks the reserve",
+			remaining: 60 * time.Second,
+			want:      Budget{Total: 60 * time.Second, PerIteration: 30 * time.Second, MaxIterations: 15, Reserve: 10 * time.Second},
+		},
+		{
+			name:      "short deadline shrinks the steps to the loop",
+			remaining: 6 * time.Second,
+			want:      Budget{Total: 6 * time.Second, PerIteration: 5 * time.Second, MaxIterations: 15, Reserve: time.Second},
+		},
+		{
+			name:      "passed deadline leaves nothing",
+			remaining: -time.Second,
+			want:      Budget{MaxIterations: 15},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.fit(tt.remaining); got != tt.want {
+				t.Errorf("fit(%v) = %+v, want %+v", tt.remaining, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssistant_Reply_Deadline(t *testing.T) {
+	// The model never answers in time, not even for the best-effort reply
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	a := NewWithRegistryFactory(func(*model.Conversation) *tools.Registry {
+		r := tools.NewRegistry()
+		r.Register(tools.NewGetTodayDateTool())
+		return r
+	})
+	a.cli = openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+
+	conv := &model.Conversation{
+		ID:       primitive.NewObjectID(),
+		Messages: []*model.Message{{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "What day is it?"}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := a.Reply(ctx, conv)
+	var de *DeadlineError
+	if !errors.As(err, &de) {
+		t.Fatalf("Reply() error = %v, want a DeadlineError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("DeadlineError should unwrap to context.DeadlineExceeded")
+	}
+}
+
+// memoryCheckpoints is an in-memory CheckpointStore.
+type memoryCheckpoints map[string]checkpoint.Checkpoint
+
+func (m memoryCheckpoints) LoadCheckpoint(ctx context.Context, attemptID string) (*checkpoint.Checkpoint, error) {
+	c, ok := m[attemptID]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (m memoryCheckpoints) SaveCheckpoint(ctx context.Context, c *checkpoint.Checkpoint) error {
+	saved := *c
+	saved.Steps = slices.Clone(c.Steps)
+	m[c.AttemptID] = saved
+	return nil
+}
+
+func TestAssistant_Reply_Resume(t *testing.T) {
+	// The first reply call asks for a tool, the second one fails as if the stream died,
+	// and later calls answer once the tool result is in the conversation.
+	var classifications, replies int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResponseFormat any              `json:"response_format"`
+			Messages       []map[string]any `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		content, toolCalls := "", "null"
+		switch {
+		case req.ResponseFormat != nil:
+			classifications++
+			content = `{\"intent\": \"general\"}`
+		default:
+			replies++
+			switch {
+			case replies == 1:
+				toolCalls = `[{"id": "call_1", "type": "function", "function": {"name": "get_today_date", "arguments": "{}"}}]`
+			case replies == 2:
+				http.Error(w, `{"error": {"message": "stream died"}}`, http.StatusInternalServerError)
+				return
+			default:
+				if last := req.Messages[len(req.Messages)-1]; last["role"] != "tool" || last["tool_call_id"] != "call_1" {
+					t.Errorf("resumed request should end with the checkpointed tool result, got %v", last)
+				}
+				content = "Today is Monday"
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id": "1", "object": "chat.completion", "model": "gpt-4.1", "choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "%s", "tool_calls": %s}}]}`, content, toolCalls)
+	}))
+	defer srv.Close()
+
+	store := memoryCheckpoints{}
+	a := NewWithRegistryFactory(func(*model.Conversation) *tools.Registry {
+		r := tools.NewRegistry()
+		r.Register(tools.NewGetTodayDateTool())
+		return r
+	})
+	WithCheckpoints(store)(a)
+	a.cli = openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+
+	conv := &model.Conversation{
+		ID:       primitive.NewObjectID(),
+		Messages: []*model.Message{{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "What day is it?"}},
+	}
+	ctx := checkpoint.WithAttempt(context.Background(), "attempt-1")
+
+	if _, err := a.Reply(ctx, conv); err == nil {
+		t.Fatal("expected the first attempt to fail")
+	}
+	if got := len(store["attempt-1"].Steps); got != 1 {
+		t.Fatalf("checkpoint has %d steps, want 1", got)
+	}
+
+	reply, err := a.Reply(ctx, conv)
+	if err != nil {
+		t.Fatalf("Reply() error = %v", err)
+	}
+	if reply != "Today is Monday" {
+		t.Errorf("Reply() = %q, want %q", reply, "Today is Monday")
+	}
+	if classifications != 1 || replies != 3 {
+		t.Errorf("got %d classifications and %d reply calls, want 1 and 3", classifications, replies)
+	}
+	if got := conv.Messages[0].ToolCalls; !slices.Equal(got, []string{"get_today_date"}) {
+		t.Errorf("tool calls recorded on the user message = %q, want the checkpointed call", got)
+	}
+
+	// A finished attempt returns its reply without calling the model again
+	if reply, err := a.Reply(ctx, conv); err != nil || reply != "Today is Monday" {
+		t.Errorf("Reply() of a finished attempt = %q, %v", reply, err)
+	}
+	if replies != 3 {
+		t.Errorf("finished attempt called the model again")
+	}
+}
+
+func TestAssistant_Fingerprint(t *testing.T) {
+	base := New().Fingerprint()
+	if got := New().Fingerprint(); got != base {
+		t.Errorf("Fingerprint() = %q, then %q for the same assistant", base, got)
+	}
+
+	for name, a := range map[string]*Assistant{
+		"tool choice": New(WithToolChoice(ToolChoiceNone)),
+		"extra tool":  New(WithTools(func(conv *model.Conversation) tools.Tool { return tools.NewSetReminderTool(conv, nil) })),
+		"tool set": NewWithRegistryFactory(func(*model.Conversation) *tools.Registry {
+			r := tools.NewRegistry()
+			r.Register(tools.NewGetHolidaysTool())
+			return r
+		}),
+	} {
+		if got := a.Fingerprint(); got == base {
+			t.Errorf("Fingerprint() with a different %s = %q, want a different one", name, got)
+		}
+	}
+}
+
+func TestAssistant_Reply_DisableTools(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		calls++
+		if req["response_format"] != nil {
+			t.Error("reply without tools should not classify the intent")
+		}
+		if req["tools"] != nil || req["tool_choice"] != nil {
+			t.Errorf("reply without tools sent tools %v and tool choice %v", req["tools"], req["tool_choice"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"id": "1", "object": "chat.completion", "model": "gpt-4.1", "choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "Hi there!"}}], "usage": {"prompt_tokens": 12, "completion_tokens": 3, "total_tokens": 15}}`)
+	}))
+	defer srv.Close()
+
+	a := New()
+	a.cli = openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		disabled bool
+	}{
+		{name: "per request", ctx: WithReplyOptions(context.Background(), ReplyOptions{DisableTools: true})},
+		{name: "per conversation", ctx: context.Background(), disabled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			conv := &model.Conversation{
+				ID:            primitive.NewObjectID(),
+				ToolsDisabled: tt.disabled,
+				Messages:      []*model.Message{{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "Hello!"}},
+			}
+
+			reply, err := a.Reply(tt.ctx, conv)
+			if err != nil {
+				t.Fatalf("Reply() error = %v", err)
+			}
+			if reply != "Hi there!" {
+				t.Errorf("Reply() = %q, want %q", reply, "Hi there!")
+			}
+			if calls != 1 {
+				t.Errorf("got %d model calls, want 1", calls)
+			}
+			if u := conv.Messages[0].Usage; u == nil || u.InputTokens != 12 || u.OutputTokens != 3 {
+				t.Errorf("usage recorded on the user message = %+v, want 12 input and 3 output tokens", u)
+			}
+		})
+	}
+}
+
+func TestApplyCreativity(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      openai.ChatModel
+		creativity string
+		want       float64 // 0 when no temperature is set
+	}{
+		{name: "precise", model: openai.ChatModelGPT4_1, creativity: model.CreativityPrecise, want: 0.2},
+		{name: "creative", model: openai.ChatModelGPT4_1, creativity: model.CreativityCreative, want: 1.2},
+		{name: "balanced keeps the default", model: openai.ChatModelGPT4_1, creativity: model.CreativityBalanced},
+		{name: "unset keeps the default", model: openai.ChatModelGPT4_1},
+		{name: "reasoning model keeps the default", model: openai.ChatModelGPT5, creativity: model.CreativityPrecise},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := openai.ChatCompletionNewParams{Model: tt.model}
+			applyCreativity(&params, model.Settings{Creativity: tt.creativity})
+
+			if got := params.Temperature.Or(0); got != tt.want {
+				t.Errorf("temperature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssistant_history_Verbosity(t *testing.T) {
+	a := New()
+	conv := &model.Conversation{
+		Settings: model.Settings{Verbosity: model.VerbosityBrief},
+		Messages: []*model.Message{{Role: model.RoleUser, Content: "Tell me about Lisbon"}},
+	}
+
+	msgs := a.history(context.Background(), conv, replyPrompt)
+	if len(msgs) != 3 || msgs[1].OfSystem == nil || msgs[1].OfSystem.Content.OfString.Value != verbosityPrompts[model.VerbosityBrief] {
+		t.Errorf("history() = %+v, want the reply prompt, the brief verbosity prompt and the user message", msgs)
+	}
+
+	conv.Settings.Verbosity = model.VerbosityNormal
+	if msgs := a.history(context.Background(), conv, replyPrompt); len(msgs) != 2 {
+		t.Errorf("history() with normal verbosity has %d messages, want 2", len(msgs))
+	}
+}
+
+func TestAssistant_history_Format(t *testing.T) {
+	a := New()
+	conv := &model.Conversation{
+		Settings: model.Settings{Format: model.FormatBullets, MaxLength: 200},
+		Messages: []*model.Message{{Role: model.RoleUser, Content: "What should I see in Porto?"}},
+	}
+
+	msgs := a.history(context.Background(), conv, replyPrompt)
+	if len(msgs) != 4 || msgs[1].OfSystem.Content.OfString.Value != formatPrompts[model.FormatBullets] || msgs[2].OfSystem.Content.OfString.Value != lengthPrompt(200) {
+		t.Errorf("history() = %+v, want the reply prompt, the bullets format prompt, the length prompt and the user message", msgs)
+	}
+}
+
+func TestFormatReply(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		settings model.Settings
+		want     string
+	}{
+		{
+			name:  "no format or length keeps the reply",
+			reply: "## Porto\n\n**Ribeira** is a must.",
+			want:  "## Porto\n\n**Ribeira** is a must.",
+		},
+		{
+			name:     "plain removes markdown",
+			reply:    "## Porto\n\n* **Ribeira**, by the river\n* The `Livraria Lello` ([tickets](https://livrarialello.pt))\n\nIt's *lovely* in May.",
+			settings: model.Settings{Format: model.FormatPlain},
+			want:     "Porto\n\n- Ribeira, by the river\n- The Livraria Lello (tickets (https://livrarialello.pt))\n\nIt's lovely in May.",
+		},
+		{
+			name:     "plain keeps snake case",
+			reply:    "Use the code spring_sale at checkout.",
+			settings: model.Settings{Format: model.FormatPlain},
+			want:     "Use the code spring_sale at checkout.",
+		},
+		{
+			name:     "bullets turn sentences into items",
+			reply:    "Porto is great in May. Highlights:\n1. Ribeira\n2. **Livraria Lello**",
+			settings: model.Settings{Format: model.FormatBullets},
+			want:     "- Porto is great in May.\n- Highlights:\n- Ribeira\n- Livraria Lello",
+		},
+		{
+			name:     "max length cuts at a sentence",
+			reply:    "Porto is great in May. The weather is mild and the crowds are small, so book soon.",
+			settings: model.Settings{MaxLength: 40},
+			want:     "Porto is great in May.",
+		},
+		{
+			name:     "max length cuts at a word when no sentence fits",
+			reply:    "Porto is great in May, when the weather is mild and the crowds are small.",
+			settings: model.Settings{MaxLength: 30},
+			want:     "Porto is great in May, when…",
+		},
+		{
+			name:     "max length keeps whole bullets",
+			reply:    "- Ribeira by the river\n- Livraria Lello\n- Port wine cellars in Gaia",
+			settings: model.Settings{Format: model.FormatBullets, MaxLength: 50},
+			want:     "- Ribeira by the river\n- Livraria Lello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatReply(tt.reply, tt.settings); got != tt.want {
+				t.Errorf("formatReply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssistant_history_Instructions(t *testing.T) {
+	a := New()
+	conv := &model.Conversation{
+		Instructions: "This conversation follows the \"Weekend getaway planner\" flow.",
+		Messages: []*model.Message{
+			{Role: model.RoleAssistant, Content: "Where would you be leaving from?"},
+			{Role: model.RoleUser, Content: "From Barcelona"},
+		},
+	}
+
+	msgs := a.history(context.Background(), conv, replyPrompt)
+	if len(msgs) != 4 || msgs[1].OfSystem == nil || msgs[1].OfSystem.Content.OfString.Value != conv.Instructions || msgs[2].OfAssistant == nil {
+		t.Errorf("history() = %+v, want the reply prompt, the instructions, the greeting and the user message", msgs)
+	}
+}
+
+func TestAssistant_history_TenantInstructions(t *testing.T) {
+	a := New(WithInstructions("You are the assistant of Sunny Tours. Only recommend Sunny Tours packages."))
+	conv := &model.Conversation{
+		Instructions: "This conversation follows the \"Weekend getaway planner\" flow.",
+		Messages:     []*model.Message{{Role: model.RoleUser, Content: "From Barcelona"}},
+	}
+
+	msgs := a.history(context.Background(), conv, replyPrompt)
+	if len(msgs) != 4 || msgs[1].OfSystem.Content.OfString.Value != a.instructions || msgs[2].OfSystem.Content.OfString.Value != conv.Instructions {
+		t.Errorf("history() = %+v, want the reply prompt, the tenant's instructions, the conversation's and the user message", msgs)
+	}
+
+	if New().Fingerprint() == a.Fingerprint() {
+		t.Error("Fingerprint() is the same with and without instructions")
+	}
+	if New().Fingerprint() == New(WithReplyModel(openai.ChatModelGPT4o)).Fingerprint() {
+		t.Error("Fingerprint() is the same for different reply models")
+	}
+}
+
+// recaller recalls the same memories for every message, or fails.
+type recaller struct {
+	memories []*recall.Memory
+	err      error
+	query    string
+}
+
+func (r *recaller) Recall(ctx context.Context, conv *model.Conversation, query string) ([]*recall.Memory, error) {
+	r.query = query
+	return r.memories, r.err
+}
+
+func TestAssistant_history_Recall(t *testing.T) {
+	r := &recaller{memories: []*recall.Memory{{
+		Title:    "Flights to Tokyo",
+		Summary:  "You were planning a trip to Tokyo in April and prefer aisle seats.",
+		ClosedAt: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+	}}}
+	a := New(WithRecall(r))
+	conv := &model.Conversation{
+		UserID:   "user-1",
+		Messages: []*model.Message{{Role: model.RoleUser, Content: "Find me a flight to Osaka"}},
+	}
+
+	msgs := a.history(context.Background(), conv, replyPrompt)
+	if len(msgs) != 3 || msgs[1].OfSystem == nil {
+		t.Fatalf("history() = %+v, want the reply prompt, the recalled conversations and the user message", msgs)
+	}
+	if got := msgs[1].OfSystem.Content.OfString.Value; !strings.Contains(got, "March 2025, \"Flights to Tokyo\": You were planning a trip to Tokyo in April and prefer aisle seats.") {
+		t.Errorf("recalled = %q, want the date, title and summary of the past conversation", got)
+	}
+	if r.query != "Find me a flight to Osaka" {
+		t.Errorf("recalled for %q, want the message waiting for a reply", r.query)
+	}
+
+	r.memories, r.err = nil, errors.New("OpenAI API error")
+	if msgs := a.history(context.Background(), conv, replyPrompt); len(msgs) != 2 {
+		t.Errorf("history() when recall fails has %d messages, want 2", len(msgs))
+	}
+}
+
+func TestAssistant_Persona(t *testing.T) {
+	a := New()
+	conv := &model.Conversation{
+		Persona:  model.PersonaBackpacker,
+		Messages: []*model.Message{{Role: model.RoleUser, Content: "How do I get from the airport to Alfama?"}},
+	}
+
+	msgs := a.history(context.Background(), conv, replyPrompt)
+	if len(msgs) != 3 || msgs[1].OfSystem == nil || msgs[1].OfSystem.Content.OfString.Value != personas[model.PersonaBackpacker].prompt {
+		t.Errorf("history() = %+v, want the reply prompt, the persona prompt and the user message", msgs)
+	}
+
+	registry := a.buildRegistry(conv)
+	restrictTools(registry, conv)
+	if _, ok := registry.Get("search_transfers"); ok {
+		t.Error("backpacker registry has search_transfers, want it left out")
+	}
+	if _, ok := registry.Get("get_travel_time"); !ok {
+		t.Error("backpacker registry lacks get_travel_time")
+	}
+
+	conv.Persona = model.PersonaConcierge
+	registry = a.buildRegistry(conv)
+	restrictTools(registry, conv)
+	if _, ok := registry.Get("search_transfers"); !ok {
+		t.Error("concierge registry lacks search_transfers, want all tools")
+	}
+}
+
+func TestAssistant_history_Batch(t *testing.T) {
+	a := New()
+	conv := &model.Conversation{
+		Messages: []*model.Message{
+			{Role: model.RoleUser, Content: "Weather in Lisbon?"},
+			{Role: model.RoleAssistant, Content: "Sunny, 24°C."},
+			{Role: model.RoleUser, Content: "And Porto?"},
+			{Role: model.RoleUser, Content: "And flights there on Friday?"},
+		},
+	}
+
+	if got := pendingContent(pending(conv)); got != "And Porto?\nAnd flights there on Friday?" {
+		t.Errorf("pendingContent() = %q, want both waiting messages", got)
+	}
+
+	msgs := a.history(context.Background(), conv, replyPrompt)
+	if last := msgs[len(msgs)-1]; len(msgs) != 6 || last.OfSystem == nil || last.OfSystem.Content.OfString.Value != batchPrompt {
+		t.Errorf("history() = %+v, want the messages followed by the batch prompt", msgs)
+	}
+
+	conv.Messages = conv.Messages[:3]
+	if msgs := a.history(context.Background(), conv, replyPrompt); len(msgs) != 4 {
+		t.Errorf("history() of a single waiting message has %d messages, want 4", len(msgs))
+	}
+}
+
+func TestAssistant_history_FailedReply(t *testing.T) {
+	a := New()
+	conv := &model.Conversation{
+		Messages: []*model.Message{
+			{Role: model.RoleUser, Content: "Weather in Lisbon?", Status: model.MessageStatusFailed},
+			{Role: model.RoleAssistant, Status: model.MessageStatusFailed},
+			{Role: model.RoleUser, Content: "Hello?"},
+		},
+	}
+
+	for _, m := range a.history(context.Background(), conv, replyPrompt) {
+		if m.OfAssistant != nil {
+			t.Errorf("history() has the failed reply %+v, want it left out", m.OfAssistant)
+		}
+	}
+}
+
+// sourcedTool is a tool whose results come from a fake external source.
+type sourcedTool struct{}
+
+func (sourcedTool) Name() string        { return "get_rates" }
+func (sourcedTool) Description() string { return "Gets exchange rates" }
+func (sourcedTool) Source() string      { return "RatesAPI" }
+func (sourcedTool) TTL() time.Duration  { return time.Minute }
+func (t sourcedTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{Name: t.Name()})
+}
+func (sourcedTool) Execute(context.Context, json.RawMessage) (string, error) {
+	return "1 EUR = 1.08 USD", nil
+}
+
+func TestAssistant_Reply_Citations(t *testing.T) {
+	// The first reply call asks for both tools, the second one answers
+	var replies int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		content, toolCalls := "", "null"
+		switch {
+		case req["response_format"] != nil:
+			content = `{\"intent\": \"general\"}`
+		default:
+			replies++
+			if replies == 1 {
+				toolCalls = `[{"id": "call_1", "type": "function", "function": {"name": "get_today_date", "arguments": "{}"}},
+					{"id": "call_2", "type": "function", "function": {"name": "get_rates", "arguments": "{}"}}]`
+			} else {
+				content = "1 EUR is 1.08 USD today"
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id": "1", "object": "chat.completion", "model": "gpt-4.1", "choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "%s", "tool_calls": %s}}]}`, content, toolCalls)
+	}))
+	defer srv.Close()
+
+	a := NewWithRegistryFactory(func(*model.Conversation) *tools.Registry {
+		r := tools.NewRegistry()
+		r.Register(tools.NewGetTodayDateTool())
+		r.Register(sourcedTool{})
+		return r
+	})
+	a.cli = openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+
+	conv := &model.Conversation{
+		ID:       primitive.NewObjectID(),
+		Messages: []*model.Message{{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "How many dollars is a euro?"}},
+	}
+
+	var citations []model.Citation
+	var steps []string
+	ctx := WithProgress(WithCitations(context.Background(), &citations), func(tool, message string) {
+		steps = append(steps, tool+": "+message)
+	})
+	start := time.Now()
+	if _, err := a.Reply(ctx, conv); err != nil {
+		t.Fatalf("Reply() error = %v", err)
+	}
+
+	// The steps are reported as the tools are called
+	if want := []string{"get_today_date: Checking today's date…", "get_rates: Looking things up…"}; !slices.Equal(steps, want) {
+		t.Errorf("progress = %q, want %q", steps, want)
+	}
+
+	if len(citations) != 1 {
+		t.Fatalf("got citations %+v, want only the sourced tool", citations)
+	}
+	if c := citations[0]; c.Tool != "get_rates" || c.Source != "RatesAPI" || c.FetchedAt.Before(start) {
+		t.Errorf("citation = %+v, want get_rates from RatesAPI fetched during the reply", c)
+	}
+	if c := citations[0]; !c.ExpiresAt.Equal(c.FetchedAt.Add(time.Minute)) {
+		t.Errorf("citation expires at %v, want a minute after it was fetched at %v", c.ExpiresAt, c.FetchedAt)
+	}
+}
+
+func TestAssistant_Refresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Tools    any              `json:"tools"`
+			Messages []map[string]any `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.Tools != nil {
+			t.Error("refresh should not run the tool loop")
+		}
+		if last, _ := req.Messages[len(req.Messages)-1]["content"].(string); !strings.Contains(last, "1 EUR = 1.08 USD") {
+			t.Errorf("refresh request should end with the fresh tool results, got %q", last)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"id": "1", "object": "chat.completion", "model": "gpt-4.1", "choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "1 EUR is 1.08 USD"}}]}`)
+	}))
+	defer srv.Close()
+
+	a := NewWithRegistryFactory(func(*model.Conversation) *tools.Registry {
+		r := tools.NewRegistry()
+		r.Register(sourcedTool{})
+		return r
+	})
+	a.cli = openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+
+	old := time.Now().Add(-time.Hour)
+	reply := &model.Message{
+		Role:      model.RoleAssistant,
+		Content:   "1 EUR is 1.05 USD",
+		Citations: []model.Citation{{Tool: "get_rates", Source: "RatesAPI", Arguments: "{}", FetchedAt: old, ExpiresAt: old.Add(time.Minute)}},
+	}
+	conv := &model.Conversation{
+		ID: primitive.NewObjectID(),
+		Messages: []*model.Message{
+			{Role: model.RoleUser, Content: "How many dollars is a euro?"},
+			reply,
+			{Role: model.RoleUser, Content: "Thanks!"},
+		},
+	}
+
+	got, err := a.Refresh(context.Background(), conv, reply)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got != "1 EUR is 1.08 USD" {
+		t.Errorf("Refresh() = %q", got)
+	}
+	if c := reply.Citations; len(c) != 1 || !c[0].FetchedAt.After(old) || c[0].Stale(time.Now()) {
+		t.Errorf("citations = %+v, want a fresh get_rates citation", c)
+	}
+
+	if _, err := a.Refresh(context.Background(), conv, conv.Messages[0]); err == nil {
+		t.Error("Refresh() of a user message should fail")
+	}
+	if _, err := a.Refresh(context.Background(), conv, &model.Message{Role: model.RoleAssistant}); err == nil {
+		t.Error("Refresh() of a message outside the conversation should fail")
+	}
+}
@@ -0,0 +1,161 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Budget bounds the time and number of steps Reply may spend in the tool loop.
+type Budget struct {
+	// Total is the wall-clock limit of a reply. A shorter request deadline wins.
+	Total time.Duration
+	// PerIteration limits one step of the loop: the model call and the tools it asks for.
+	PerIteration time.Duration
+	// MaxIterations is the maximum number of steps.
+	MaxIterations int
+	// Reserve is kept out of Total to write a best-effort answer when the loop runs out
+	// of time or steps.
+	Reserve time.Duration
+}
+
+// DefaultBudget is used unless WithBudget says otherwise.
+var DefaultBudget = Budget{
+	Total:         90 * time.Second,
+	PerIteration:  30 * time.Second,
+	MaxIterations: 15,
+	Reserve:       15 * time.Second,
+}
+
+// WithBudget sets the time and step limits of Reply. Zero fields keep the defaults.
+func WithBudget(b Budget) Option {
+	return func(a *Assistant) {
+		if b.Total > 0 {
+			a.budget.Total = b.Total
+		}
+		if b.PerIteration > 0 {
+			a.budget.PerIteration = b.PerIteration
+		}
+		if b.MaxIterations > 0 {
+			a.budget.MaxIterations = b.MaxIterations
+		}
+		if b.Reserve > 0 {
+			a.budget.Reserve = b.Reserve
+		}
+	}
+}
+
+// deadline returns when the reply must be done: Total from now, or the request
+// deadline if it comes first.
+func (b Budget) deadline(ctx context.Context, now time.Time) time.Time {
+	deadline := now.Add(b.Total)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	return deadline
+}
+
+// fit shrinks the budget to a deadline closer than Total, like one set by the client:
+// the reserve keeps its share of the time, and a step may not take longer than the loop
+// has left.
+func (b Budget) fit(remaining time.Duration) Budget {
+	if b.Total <= 0 || remaining >= b.Total {
+		return b
+	}
+	remaining = max(remaining, 0)
+
+	b.Reserve = time.Duration(float64(b.Reserve) * float64(remaining) / float64(b.Total))
+	b.PerIteration = min(b.PerIteration, remaining-b.Reserve)
+	b.Total = remaining
+	return b
+}
+
+// withToolShare bounds the next of n tool calls left in a step to an even share of the
+// time left of the step, so a slow tool can't starve the ones after it.
+func withToolShare(ctx context.Context, n int) (context.Context, context.CancelFunc) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(d)/time.Duration(max(n, 1)))
+}
+
+// DeadlineError is returned by Reply when the request deadline passes before even a
+// best-effort answer is written. The tool steps completed so far are kept in the
+// attempt's checkpoint, so a retry with the same attempt ID picks up from them.
+type DeadlineError struct {
+	// Steps is how many tool steps were completed.
+	Steps int
+	// ToolCalls are the names of the tools called in them.
+	ToolCalls []string
+}
+
+func (e *DeadlineError) Error() string {
+	return fmt.Sprintf("reply deadline exceeded after %d tool steps", e.Steps)
+}
+
+func (e *DeadlineError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// expired returns a DeadlineError in place of err when the request deadline has passed.
+func expired(ctx context.Context, err error, steps int, toolCalls []string) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return &DeadlineError{Steps: steps, ToolCalls: toolCalls}
+}
+
+// budgetExceeded reports whether err comes from the reply budget running out rather
+// than from the request itself being cancelled.
+func budgetExceeded(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded)
+}
+
+// bestEffort asks replyModel, the model of the tool loop, for an answer without further
+// tool calls, based on what the loop gathered so far. It runs on the time kept in
+// reserve.
+func (a *Assistant) bestEffort(ctx context.Context, replyModel openai.ChatModel, msgs []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolUnionParam, settings model.Settings, usage *model.Usage) (string, error) {
+	ctx, span := genai.StartChat(ctx, otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant"), replyModel,
+		attribute.Bool("reply.best_effort", true),
+	)
+	defer span.End()
+
+	msgs = append(msgs, openai.SystemMessage("You are out of time to look anything else up. Answer the user now with the "+
+		"information gathered so far, and briefly mention anything you could not check."))
+
+	params := openai.ChatCompletionNewParams{
+		Model:      replyModel,
+		Messages:   msgs,
+		Tools:      tools,
+		ToolChoice: openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String(ToolChoiceNone)},
+	}
+	applyCreativity(&params, settings)
+
+	resp, err := a.cli.Chat.Completions.New(ctx, params)
+	genai.RecordResponse(span, resp)
+	addUsage(usage, resp)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "API call failed")
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		err := errors.New("empty best-effort reply")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "empty response")
+		return "", err
+	}
+
+	span.SetStatus(codes.Ok, "best-effort reply generated")
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,109 @@
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Categories are the labels Categorize may assign to a conversation.
+var Categories = []string{"weather", "flights", "holidays", "itinerary"}
+
+var categorySchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"categories": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string", "enum": Categories},
+		},
+	},
+	"required":             []string{"categories"},
+	"additionalProperties": false,
+}
+
+// Categorize labels the conversation with the categories its first message is about,
+// used to tag conversations automatically.
+func (a *Assistant) Categorize(ctx context.Context, conv *model.Conversation) ([]string, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant")
+	ctx, span := tracer.Start(ctx, "Assistant.Categorize",
+		trace.WithAttributes(
+			semconv.GenAIConversationID(conv.ID.Hex()),
+			semconv.GenAIOperationNameChat,
+			semconv.GenAIProviderNameOpenAI,
+			semconv.GenAIRequestModel(string(openai.ChatModelGPT4_1Nano)),
+		),
+	)
+	defer span.End()
+
+	if len(conv.Messages) == 0 || conv.Messages[0].Content == "" {
+		err := errors.New("conversation has no messages")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "no messages")
+		return nil, err
+	}
+
+	msgs := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("Classify the travel question into the categories it is about: weather (current weather or forecasts), " +
+			"flights (flight prices, schedules or airports), holidays (public holidays and dates) or itinerary (trip planning, " +
+			"activities, packing). Return every category that applies, or none if the question fits no category."),
+		openai.UserMessage(conv.Messages[0].Content),
+	}
+
+	resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    openai.ChatModelGPT4_1Nano,
+		Messages: msgs,
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "categories",
+					Schema: categorySchema,
+					Strict: openai.Bool(true),
+				},
+			},
+		},
+	})
+	genai.RecordResponse(span, resp)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "OpenAI API call failed")
+		return nil, err
+	}
+
+	if len(resp.Choices) == 0 {
+		err := errors.New("empty response from OpenAI for categorization")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "empty response")
+		return nil, err
+	}
+
+	var out struct {
+		Categories []string `json:"categories"`
+	}
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &out); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid response")
+		return nil, err
+	}
+
+	var categories []string
+	for _, c := range out.Categories {
+		if slices.Contains(Categories, c) && !slices.Contains(categories, c) {
+			categories = append(categories, c)
+		}
+	}
+
+	span.SetAttributes(attribute.StringSlice("conversation.categories", categories))
+	span.SetStatus(codes.Ok, "conversation categorized")
+	return categories, nil
+}
@@ -0,0 +1,179 @@
+package assistant
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/checkpoint"
+	"github.com/openai/openai-go/v2"
+)
+
+// CheckpointStore persists the progress of reply attempts.
+type CheckpointStore interface {
+	LoadCheckpoint(ctx context.Context, attemptID string) (*checkpoint.Checkpoint, error)
+	SaveCheckpoint(ctx context.Context, c *checkpoint.Checkpoint) error
+}
+
+// WithCheckpoints makes Reply save its progress after every tool step, so a retry
+// with the same attempt ID resumes from the last completed step.
+func WithCheckpoints(store CheckpointStore) Option {
+	return func(a *Assistant) {
+		a.checkpoints = store
+	}
+}
+
+// attempt tracks the checkpoint of a single Reply call. A nil attempt does nothing,
+// which is the case when checkpoints are disabled or the request has no attempt ID.
+type attempt struct {
+	store CheckpointStore
+	cp    *checkpoint.Checkpoint
+}
+
+// startAttempt loads the checkpoint of the request's attempt, or starts a new one.
+// Checkpoints of another user are ignored.
+func (a *Assistant) startAttempt(ctx context.Context, conv *model.Conversation) *attempt {
+	id := checkpoint.AttemptID(ctx)
+	if a.checkpoints == nil || id == "" {
+		return nil
+	}
+
+	cp, err := a.checkpoints.LoadCheckpoint(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load reply checkpoint", "attempt_id", id, "error", err)
+	}
+	if cp == nil || cp.UserID != conv.UserID {
+		cp = &checkpoint.Checkpoint{AttemptID: id, UserID: conv.UserID}
+	}
+
+	return &attempt{store: a.checkpoints, cp: cp}
+}
+
+// completed returns the reply of an attempt that already finished.
+func (at *attempt) completed() (string, bool) {
+	if at == nil || at.cp.Reply == "" {
+		return "", false
+	}
+	return at.cp.Reply, true
+}
+
+// intent returns the intent classified by a previous try of the attempt.
+func (at *attempt) intent() Intent {
+	if at == nil {
+		return ""
+	}
+	return Intent(at.cp.Intent)
+}
+
+// steps returns how many tool steps were already completed.
+func (at *attempt) steps() int {
+	if at == nil {
+		return 0
+	}
+	return len(at.cp.Steps)
+}
+
+// toolCalls returns the names of the tools called in the completed steps.
+func (at *attempt) toolCalls() []string {
+	if at == nil {
+		return nil
+	}
+
+	var names []string
+	for _, step := range at.cp.Steps {
+		for _, tc := range step.ToolCalls {
+			names = append(names, tc.Name)
+		}
+	}
+	return names
+}
+
+// citations returns the citations of the tool calls of the completed steps.
+func (at *attempt) citations() []model.Citation {
+	if at == nil {
+		return nil
+	}
+
+	var citations []model.Citation
+	for _, step := range at.cp.Steps {
+		for _, tc := range step.ToolCalls {
+			if tc.Source != "" {
+				citations = append(citations, model.Citation{
+					Tool:      tc.Name,
+					Source:    tc.Source,
+					Arguments: tc.Arguments,
+					FetchedAt: tc.FetchedAt,
+					ExpiresAt: tc.ExpiresAt,
+				})
+			}
+		}
+	}
+	return citations
+}
+
+// replay appends the completed tool steps to msgs, as if the model had just made them.
+func (at *attempt) replay(msgs []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	if at == nil {
+		return msgs
+	}
+
+	for _, step := range at.cp.Steps {
+		call := openai.ChatCompletionAssistantMessageParam{}
+		if step.Content != "" {
+			call.Content.OfString = openai.String(step.Content)
+		}
+		for _, tc := range step.ToolCalls {
+			call.ToolCalls = append(call.ToolCalls, openai.ChatCompletionMessageToolCallUnionParam{
+				OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+					ID: tc.ID,
+					Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+						Name:      tc.Name,
+						Arguments: tc.Arguments,
+					},
+				},
+			})
+		}
+
+		msgs = append(msgs, openai.ChatCompletionMessageParamUnion{OfAssistant: &call})
+		for _, tc := range step.ToolCalls {
+			msgs = append(msgs, openai.ToolMessage(tc.Result, tc.ID))
+		}
+	}
+
+	return msgs
+}
+
+// setIntent records the classified intent, so retries route the same way.
+func (at *attempt) setIntent(ctx context.Context, intent Intent) {
+	if at == nil {
+		return
+	}
+	at.cp.Intent = string(intent)
+	at.save(ctx)
+}
+
+// addStep records a completed tool step.
+func (at *attempt) addStep(ctx context.Context, step checkpoint.Step) {
+	if at == nil {
+		return
+	}
+	at.cp.Steps = append(at.cp.Steps, step)
+	at.save(ctx)
+}
+
+// finish records the final reply, so a retry after a failure to persist the
+// conversation returns it without calling the model again.
+func (at *attempt) finish(ctx context.Context, reply string) {
+	if at == nil {
+		return
+	}
+	at.cp.Reply = reply
+	at.save(ctx)
+}
+
+// save is best effort: a failure only costs a full retry.
+func (at *attempt) save(ctx context.Context) {
+	if err := at.store.SaveCheckpoint(context.WithoutCancel(ctx), at.cp); err != nil {
+		slog.WarnContext(ctx, "Failed to save reply checkpoint", "attempt_id", at.cp.AttemptID, "error", err)
+	}
+}
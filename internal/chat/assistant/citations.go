@@ -0,0 +1,40 @@
+package assistant
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+)
+
+type citationsKey struct{}
+
+// WithCitations collects into citations the sources of the Reply calls made with the
+// returned context: one citation per tool call whose result came from an external data
+// source, see tools.Sourced.
+func WithCitations(ctx context.Context, citations *[]model.Citation) context.Context {
+	return context.WithValue(ctx, citationsKey{}, citations)
+}
+
+// reportCitations hands the citations of a reply to the collector of ctx, if any.
+func reportCitations(ctx context.Context, citations []model.Citation) {
+	if sink, ok := ctx.Value(citationsKey{}).(*[]model.Citation); ok {
+		*sink = citations
+	}
+}
+
+// cite returns the citation of a tool call made at fetchedAt, or false when the tool
+// has no external source.
+func cite(registry *tools.Registry, name, args string, fetchedAt time.Time) (model.Citation, bool) {
+	source := registry.Source(name)
+	if source == "" {
+		return model.Citation{}, false
+	}
+
+	c := model.Citation{Tool: name, Source: source, Arguments: args, FetchedAt: fetchedAt}
+	if ttl := registry.TTL(name); ttl > 0 {
+		c.ExpiresAt = fetchedAt.Add(ttl)
+	}
+	return c, true
+}
@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GroupStats summarizes the results of the test cases sharing a category or difficulty
+type GroupStats struct {
+	Name         string  `json:"name"`
+	Total        int     `json:"total"`
+	Passed       int     `json:"passed"`
+	PassRate     float64 `json:"pass_rate"`
+	AverageScore float64 `json:"average_score"`
+}
+
+// testScore averages the scores of all evaluators of a test result
+func testScore(result TestResult) float64 {
+	if len(result.EvalResults) == 0 {
+		return 0
+	}
+	score := 0.0
+	for _, evalResult := range result.EvalResults {
+		score += evalResult.Score
+	}
+	return score / float64(len(result.EvalResults))
+}
+
+// groupBy computes the stats of the results grouped by key, sorted by name. Cases
+// without a value are grouped under "none".
+func groupBy(results []TestResult, key func(TestCase) string) []GroupStats {
+	groups := map[string]*GroupStats{}
+	scores := map[string]float64{}
+	for _, result := range results {
+		name := key(result.TestCase)
+		if name == "" {
+			name = "none"
+		}
+
+		g, ok := groups[name]
+		if !ok {
+			g = &GroupStats{Name: name}
+			groups[name] = g
+		}
+		g.Total++
+		if result.OverallPass {
+			g.Passed++
+		}
+		scores[name] += testScore(result)
+	}
+
+	out := make([]GroupStats, 0, len(groups))
+	for name, g := range groups {
+		g.PassRate = float64(g.Passed) / float64(g.Total)
+		g.AverageScore = scores[name] / float64(g.Total)
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// aggregateGroups computes the per-category and per-difficulty breakdown of the report
+func aggregateGroups(report *EvalReport) {
+	report.Categories = groupBy(report.TestResults, func(tc TestCase) string { return tc.Metadata.Category })
+	report.Difficulties = groupBy(report.TestResults, func(tc TestCase) string { return tc.Metadata.Difficulty })
+}
+
+// printGroups prints a breakdown table
+func printGroups(title string, groups []GroupStats) {
+	if len(groups) == 0 {
+		return
+	}
+	fmt.Printf("%s:\n", title)
+	fmt.Println(strings.Repeat("-", 60))
+	for _, g := range groups {
+		fmt.Printf("  %-16s %3d/%-3d passed (%5.1f%%)  avg score %.3f\n",
+			g.Name, g.Passed, g.Total, g.PassRate*100, g.AverageScore)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Generation is the stored output of a test case, with the OpenAI usage it took to
+// generate it
+type Generation struct {
+	Fingerprint string       `json:"fingerprint"`
+	Input       Input        `json:"input"`
+	Actual      ActualOutput `json:"actual"`
+	Stability   *Stability   `json:"stability,omitempty"`
+	Duration    int64        `json:"duration"` // nanoseconds
+
+	LLMCalls     int     `json:"llm_calls"`
+	LLMLatency   int64   `json:"llm_latency"` // nanoseconds
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost_usd"`
+}
+
+// GenerationCache stores the generations of test cases in a directory per assistant
+// fingerprint, one JSON file per test case. Runs of an assistant with the same
+// fingerprint reuse the stored generations and only rerun the evaluation, to iterate on
+// evaluators without calling the assistant again.
+type GenerationCache struct {
+	dir string
+}
+
+// NewGenerationCache creates a generation cache in dir
+func NewGenerationCache(dir string) *GenerationCache {
+	return &GenerationCache{dir: dir}
+}
+
+func (c *GenerationCache) path(fingerprint, id string) string {
+	return filepath.Join(c.dir, fingerprint, strings.ReplaceAll(id, string(filepath.Separator), "_")+".json")
+}
+
+// Load returns the generation of a test case by the assistant with fingerprint, or nil
+// when there is none or the test case input changed since
+func (c *GenerationCache) Load(fingerprint string, testCase TestCase) (*Generation, error) {
+	data, err := os.ReadFile(c.path(fingerprint, testCase.ID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached generation: %w", err)
+	}
+
+	var g Generation
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("failed to parse cached generation %s: %w", testCase.ID, err)
+	}
+	if g.Fingerprint != fingerprint || !sameInput(g.Input, testCase.Input) {
+		return nil, nil
+	}
+	return &g, nil
+}
+
+// Save stores the generation of a test case
+func (c *GenerationCache) Save(testCase TestCase, g Generation) error {
+	g.Input = testCase.Input
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal generation: %w", err)
+	}
+
+	path := c.path(g.Fingerprint, testCase.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cached generation: %w", err)
+	}
+
+	return nil
+}
+
+// sameInput compares inputs as stored, so a test case loaded from JSON matches the
+// cached input of the same test case
+func sameInput(a, b Input) bool {
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(da) == string(db)
+}
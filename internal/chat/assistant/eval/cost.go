@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"math"
+	"sort"
+
+	"github.com/acai-travel/tech-challenge/internal/genai"
+)
+
+// Price is the cost of a model in USD per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Prices are the list prices of the models used by the assistant, to estimate the
+// cost of a run. Models missing from the table are counted as free.
+var Prices = map[string]Price{
+	"gpt-5":        {Input: 1.25, Output: 10.00},
+	"gpt-5-mini":   {Input: 0.25, Output: 2.00},
+	"gpt-4.1":      {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+}
+
+// EstimateCost returns the estimated cost in USD of usage
+func EstimateCost(usage map[string]genai.ModelUsage) float64 {
+	cost := 0.0
+	for model, u := range usage {
+		p := Prices[model]
+		cost += (float64(u.InputTokens)*p.Input + float64(u.OutputTokens)*p.Output) / 1e6
+	}
+	return cost
+}
+
+// recordUsage stores the OpenAI usage of a test case on its generation
+func recordUsage(gen *Generation, usage *genai.Usage) {
+	total := usage.Total()
+	gen.LLMCalls = total.Calls
+	gen.LLMLatency = total.Latency.Nanoseconds()
+	gen.InputTokens = total.InputTokens
+	gen.OutputTokens = total.OutputTokens
+	gen.Cost = EstimateCost(usage.ByModel())
+}
+
+// aggregateUsage computes the latency percentiles and totals of the report
+func aggregateUsage(report *EvalReport) {
+	latencies := make([]int64, 0, len(report.TestResults))
+	for _, result := range report.TestResults {
+		latencies = append(latencies, result.LLMLatency)
+		report.TotalInputTokens += result.InputTokens
+		report.TotalOutputTokens += result.OutputTokens
+		report.TotalCost += result.Cost
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.LatencyP50 = percentile(latencies, 0.50)
+	report.LatencyP95 = percentile(latencies, 0.95)
+}
+
+// percentile returns the nearest-rank percentile p of sorted values
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
@@ -22,6 +22,21 @@ func LoadDataset(path string) ([]TestCase, error) {
 	return testCases, nil
 }
 
+// LoadScoringWeights loads the rule evaluator weights of a dataset from a JSON file
+func LoadScoringWeights(path string) (ScoringWeights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ScoringWeights{}, fmt.Errorf("failed to read weights file: %w", err)
+	}
+
+	var w ScoringWeights
+	if err := json.Unmarshal(data, &w); err != nil {
+		return ScoringWeights{}, fmt.Errorf("failed to parse weights JSON: %w", err)
+	}
+
+	return w, nil
+}
+
 // SaveDataset saves a test dataset to a JSON file
 func SaveDataset(path string, testCases []TestCase) error {
 	data, err := json.MarshalIndent(testCases, "", "  ")
@@ -44,22 +59,7 @@ func SaveDataset(path string, testCases []TestCase) error {
 
 // SaveReport saves an evaluation report to a JSON file
 func SaveReport(path string, report EvalReport) error {
-	data, err := json.MarshalIndent(report, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal report: %w", err)
-	}
-
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write report file: %w", err)
-	}
-
-	return nil
+	return SaveReportAs(path, report, FormatJSON)
 }
 
 // LoadReport loads an evaluation report from a JSON file
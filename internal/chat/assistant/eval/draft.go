@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var expectationSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"keywords":     map[string]any{"type": "array", "items": map[string]string{"type": "string"}},
+		"should_avoid": map[string]any{"type": "array", "items": map[string]string{"type": "string"}},
+		"tool_calls":   map[string]any{"type": "array", "items": map[string]string{"type": "string"}},
+		"rationale":    map[string]string{"type": "string"},
+	},
+	"required":             []string{"keywords", "should_avoid", "tool_calls", "rationale"},
+	"additionalProperties": false,
+}
+
+// Drafter drafts regression test cases from rated replies, with expectations suggested
+// by an LLM for a reviewer to check.
+type Drafter struct {
+	client openai.Client
+	tools  []string
+}
+
+// NewDrafter creates a new drafter, expecting calls to the named tools only
+func NewDrafter(tools []string) *Drafter {
+	return &Drafter{client: openai.NewClient(), tools: tools}
+}
+
+// Draft builds the test case of the rated reply with FromFeedback, and fills in its
+// reply expectations from the reply, the tools it cited and the user's comment. When
+// the suggestion fails, the case is returned with empty expectations along with the
+// error.
+func (d *Drafter) Draft(ctx context.Context, conv *model.Conversation, reply *model.Message) (TestCase, error) {
+	tc, err := FromFeedback(conv, reply)
+	if err != nil {
+		return TestCase{}, err
+	}
+
+	ctx, span := genai.StartChat(ctx, otel.Tracer(tracerName), openai.ChatModelGPT5,
+		attribute.String("eval.test_case_id", tc.ID),
+		attribute.String("feedback.rating", reply.Feedback.Rating),
+	)
+	defer span.End()
+
+	resp, err := d.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: openai.ChatModelGPT5,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(draftPrompt),
+			openai.UserMessage(d.input(tc, reply)),
+		},
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "reply_expectations",
+					Schema: expectationSchema,
+					Strict: openai.Bool(true),
+				},
+			},
+		},
+	})
+	genai.RecordResponse(span, resp)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "OpenAI API call failed")
+		return tc, err
+	}
+	if len(resp.Choices) == 0 {
+		err := errors.New("empty response from OpenAI for reply expectations")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "empty response")
+		return tc, err
+	}
+
+	if err := d.apply(&tc, resp.Choices[0].Message.Content); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid response")
+		return tc, err
+	}
+
+	span.SetStatus(codes.Ok, "test case drafted")
+	return tc, nil
+}
+
+const draftPrompt = `You turn a travel assistant reply rated by a user into expectations for a regression test.
+Given the conversation, the reply, its rating and the user's comment, suggest:
+- keywords: up to 3 short words or phrases a good reply must contain (e.g. a city, an airline), only when the comment or conversation makes them certain
+- should_avoid: short phrases of the reply that were wrong or unwanted and must not appear again
+- tool_calls: names of the tools a good reply must call, among the tools listed, only when the reply lacked data from them
+- rationale: one sentence explaining the expectations to the reviewer
+Leave arrays empty rather than guess. For a thumbs-up reply, expect what made it good.`
+
+// input writes the conversation of tc, the rated reply and its feedback for the
+// drafting model.
+func (d *Drafter) input(tc TestCase, reply *model.Message) string {
+	var b strings.Builder
+	for _, turn := range tc.Input.Turns {
+		role := turn.Role
+		if role == "" {
+			role = RoleUser
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", role, turn.Content)
+	}
+	fmt.Fprintf(&b, "%s: %s\n\n", RoleUser, tc.Input.Message)
+	fmt.Fprintf(&b, "Rated reply: %s\n\n", reply.Content)
+
+	var cited []string
+	for _, c := range reply.Citations {
+		cited = append(cited, c.Tool)
+	}
+	if len(cited) > 0 {
+		fmt.Fprintf(&b, "Tools the reply called: %s\n", strings.Join(cited, ", "))
+	}
+	fmt.Fprintf(&b, "Tools available: %s\n", strings.Join(d.tools, ", "))
+	fmt.Fprintf(&b, "Rating: %s\n", reply.Feedback.Rating)
+	if reply.Feedback.Comment != "" {
+		fmt.Fprintf(&b, "Comment: %s\n", reply.Feedback.Comment)
+	}
+	return b.String()
+}
+
+// apply decodes the structured output into the reply expectations of tc, and appends
+// the rationale to its description.
+func (d *Drafter) apply(tc *TestCase, content string) error {
+	var out struct {
+		Keywords    []string `json:"keywords"`
+		ShouldAvoid []string `json:"should_avoid"`
+		ToolCalls   []string `json:"tool_calls"`
+		Rationale   string   `json:"rationale"`
+	}
+	if err := json.Unmarshal([]byte(content), &out); err != nil {
+		return err
+	}
+
+	tc.Expected.Reply = &ReplyExpected{
+		Keywords:    nonBlank(out.Keywords),
+		ShouldAvoid: nonBlank(out.ShouldAvoid),
+	}
+	for _, name := range nonBlank(out.ToolCalls) {
+		// Unknown tools would fail the case whatever the reply
+		if slices.Contains(d.tools, name) {
+			tc.Expected.Reply.ToolCalls = append(tc.Expected.Reply.ToolCalls, name)
+		}
+	}
+	if r := strings.TrimSpace(out.Rationale); r != "" {
+		tc.Description += ". Suggested: " + r
+	}
+	return nil
+}
+
+// nonBlank trims the strings, dropping blank ones.
+func nonBlank(ss []string) []string {
+	var out []string
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
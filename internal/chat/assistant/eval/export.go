@@ -0,0 +1,227 @@
+package eval
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report formats
+const (
+	FormatJSON  = "json"
+	FormatCSV   = "csv"   // One row per test case, for spreadsheets
+	FormatJUnit = "junit" // JUnit XML, for CI systems to render failed cases
+)
+
+// Formats lists the supported report formats
+var Formats = []string{FormatJSON, FormatCSV, FormatJUnit}
+
+// FormatExtension returns the file extension of a report format
+func FormatExtension(format string) string {
+	if format == FormatJUnit {
+		return ".xml"
+	}
+	return "." + format
+}
+
+// SaveReportAs saves an evaluation report to a file in the given format
+func SaveReportAs(path string, report EvalReport, format string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer f.Close()
+
+	if err := WriteReport(f, report, format); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+// WriteReport writes an evaluation report in the given format
+func WriteReport(w io.Writer, report EvalReport, format string) error {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	case FormatCSV:
+		return writeCSV(w, report)
+	case FormatJUnit:
+		return writeJUnit(w, report)
+	default:
+		return fmt.Errorf("unknown report format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+var csvHeader = []string{
+	"id", "category", "difficulty", "description", "input", "title", "reply",
+	"passed", "score", "issues", "duration_ms", "llm_calls", "input_tokens", "output_tokens", "cost_usd", "golden",
+}
+
+// writeCSV writes one row per test case
+func writeCSV(w io.Writer, report EvalReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	for _, result := range report.TestResults {
+		tc := result.TestCase
+		row := []string{
+			tc.ID,
+			tc.Metadata.Category,
+			tc.Metadata.Difficulty,
+			tc.Description,
+			tc.Input.FirstMessage(),
+			result.Actual.Title,
+			result.Actual.Reply,
+			strconv.FormatBool(result.OverallPass),
+			strconv.FormatFloat(testScore(result), 'f', 3, 64),
+			strings.Join(issues(result), "; "),
+			strconv.FormatInt(time.Duration(result.Duration).Milliseconds(), 10),
+			strconv.Itoa(result.LLMCalls),
+			strconv.FormatInt(result.InputTokens, 10),
+			strconv.FormatInt(result.OutputTokens, 10),
+			strconv.FormatFloat(result.Cost, 'f', 6, 64),
+			result.Golden,
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// issues returns the details of the failed evaluations of a test result
+func issues(result TestResult) []string {
+	var out []string
+	for _, evalResult := range result.EvalResults {
+		if !evalResult.Passed {
+			out = append(out, evalResult.Details)
+		}
+	}
+	return out
+}
+
+// JUnit XML elements, as understood by common CI systems
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes the report as a single test suite with one test case per eval
+// case, classed by category. Failed evaluations are failures, and cases that could not
+// run are errors.
+func writeJUnit(w io.Writer, report EvalReport) error {
+	name := report.DatasetName
+	if name == "" {
+		name = "eval"
+	}
+	suite := junitTestSuite{
+		Name:      name,
+		Tests:     len(report.TestResults),
+		Time:      junitSeconds(report.Duration),
+		Timestamp: report.StartTime.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, result := range report.TestResults {
+		tc := junitTestCase{
+			Name:      result.TestCase.ID,
+			ClassName: "eval." + cmp.Or(result.TestCase.Metadata.Category, "none"),
+			Time:      junitSeconds(result.Duration),
+			SystemOut: junitOutput(result),
+		}
+
+		switch {
+		case result.Actual.Error != nil:
+			suite.Errors++
+			tc.Error = &junitProblem{Message: *result.Actual.Error, Type: "error", Text: *result.Actual.Error}
+		case !result.OverallPass:
+			suite.Failures++
+			details := issues(result)
+			tc.Failure = &junitProblem{
+				Message: fmt.Sprintf("score %.2f: %s", testScore(result), strings.Join(details, "; ")),
+				Type:    "failure",
+				Text:    strings.Join(details, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// junitOutput describes the input and outputs of a test case
+func junitOutput(result TestResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Input: %s\nTitle: %s\n", result.TestCase.Input.FirstMessage(), result.Actual.Title)
+	for _, turn := range result.Actual.Turns {
+		fmt.Fprintf(&b, "Turn %d reply: %s\n", turn.Turn, turn.Reply)
+	}
+	if result.Actual.Reply != "" {
+		fmt.Fprintf(&b, "Reply: %s\n", result.Actual.Reply)
+	}
+	return b.String()
+}
+
+// junitSeconds formats a duration in nanoseconds as JUnit seconds
+func junitSeconds(ns int64) string {
+	return strconv.FormatFloat(time.Duration(ns).Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,65 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+// FromFeedback turns a rated reply of conv into a regression test case: the conversation
+// up to the user messages the reply answered, which are sent again. The expectations are
+// left empty for a reviewer to fill in from the user's comment before the case joins a
+// dataset, e.g. the facts a thumbs-down reply got wrong as should_avoid.
+func FromFeedback(conv *model.Conversation, reply *model.Message) (TestCase, error) {
+	if reply.Feedback == nil {
+		return TestCase{}, fmt.Errorf("message %s has no feedback", reply.ID.Hex())
+	}
+
+	i := len(conv.Messages) - 1
+	for i >= 0 && conv.Messages[i].ID != reply.ID {
+		i--
+	}
+	if i < 0 || reply.Role != model.RoleAssistant {
+		return TestCase{}, fmt.Errorf("message %s is not a reply of conversation %s", reply.ID.Hex(), conv.ID.Hex())
+	}
+
+	// The user messages answered by the reply, sent at once when there are several
+	start := i
+	for start > 0 && conv.Messages[start-1].Role == model.RoleUser {
+		start--
+	}
+	if start == i {
+		return TestCase{}, fmt.Errorf("reply %s answers no user message", reply.ID.Hex())
+	}
+	var message []string
+	for _, m := range conv.Messages[start:i] {
+		message = append(message, m.Content)
+	}
+
+	var turns []Turn
+	for _, m := range conv.Messages[:start] {
+		switch {
+		case m.Role == model.RoleUser:
+			turns = append(turns, Turn{Content: m.Content})
+		case m.State() == model.MessageStatusCompleted:
+			turns = append(turns, Turn{Role: RoleAssistant, Content: m.Content})
+		}
+	}
+
+	description := fmt.Sprintf("Rated %s by a user: %q", reply.Feedback.Rating, reply.Content)
+	if reply.Feedback.Comment != "" {
+		description += fmt.Sprintf(", commenting %q", reply.Feedback.Comment)
+	}
+	return TestCase{
+		ID:          "feedback-" + reply.ID.Hex(),
+		Input:       Input{Message: strings.Join(message, "\n"), Turns: turns},
+		Expected:    Expected{Reply: &ReplyExpected{}},
+		Description: description,
+		Metadata: Metadata{
+			Category:   "feedback",
+			Difficulty: "regression",
+			Tags:       []string{"feedback-" + reply.Feedback.Rating},
+		},
+	}, nil
+}
@@ -0,0 +1,131 @@
+// Package fixture provides canned tools for reproducible reply evaluations. The tools
+// share the definitions of the real ones but answer with recorded responses instead of
+// calling third-party APIs.
+package fixture
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+)
+
+//go:embed recordings.json
+var recordingsJSON []byte
+
+// Recordings are the recorded responses of each tool, keyed by the lowercased values
+// of the tool's key arguments joined by spaces. The "*" key matches any arguments.
+type Recordings map[string]map[string]string
+
+// DefaultRecordings returns the built-in recordings: weather in a handful of European
+// cities, holidays in Catalonia and flights from Barcelona, on a fixed date.
+func DefaultRecordings() Recordings {
+	var r Recordings
+	if err := json.Unmarshal(recordingsJSON, &r); err != nil {
+		panic(fmt.Sprintf("fixture: invalid recordings: %v", err))
+	}
+	return r
+}
+
+// LoadRecordings reads recordings from a JSON file in the format of the built-in ones.
+// The tools it records replace their built-in recordings; the others keep them.
+func LoadRecordings(path string) (Recordings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file Recordings
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid recordings %s: %w", path, err)
+	}
+	r := DefaultRecordings()
+	for tool, responses := range file {
+		r[tool] = responses
+	}
+	return r, nil
+}
+
+// keyArgs are the arguments identifying the recorded response of each tool. Tools not
+// listed only have a "*" recording.
+var keyArgs = map[string][]string{
+	"get_weather":          {"location"},
+	"get_weather_forecast": {"location"},
+	"get_flight_prices":    {"origin", "destination", "departureDate"},
+	"get_flight_status":    {"flightNumber", "date"},
+	"search_transfers":     {"pickup"},
+	"get_travel_time":      {"mode"},
+}
+
+// Registry builds a registry of canned tools answering from the default recordings.
+// It can be passed to assistant.NewWithRegistryFactory.
+func Registry(conv *model.Conversation) *tools.Registry {
+	return DefaultRecordings().Registry(conv)
+}
+
+// Registry builds a registry of canned tools answering from r.
+func (r Recordings) Registry(conv *model.Conversation) *tools.Registry {
+	reg := tools.NewRegistry()
+	for _, t := range []tools.Tool{
+		tools.NewGetWeatherTool(conv),
+		tools.NewGetWeatherForecastTool(conv),
+		tools.NewGetTodayDateTool(),
+		tools.NewGetHolidaysTool(),
+		tools.NewGetFlightPricesTool(conv),
+		tools.NewGetFlightStatusTool(),
+		tools.NewSearchTransfersTool(conv),
+		tools.NewGeneratePackingListTool(conv),
+		tools.NewGetTravelTimeTool(tools.NewOSRMRouteProvider()),
+	} {
+		reg.Register(&cannedTool{Tool: t, conv: conv, responses: r[t.Name()]})
+	}
+	reg.Use(tools.TrackEntities(conv))
+	return reg
+}
+
+// cannedTool answers with recorded responses, keeping the definition of the wrapped tool.
+type cannedTool struct {
+	tools.Tool
+	conv      *model.Conversation
+	responses map[string]string
+}
+
+func (t *cannedTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var payload map[string]any
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &payload); err != nil {
+			return "", fmt.Errorf("failed to parse tool call arguments: %w", err)
+		}
+	}
+
+	values := make([]string, 0, len(keyArgs[t.Name()]))
+	for _, name := range keyArgs[t.Name()] {
+		v, _ := payload[name].(string)
+		v = strings.TrimSpace(v)
+
+		// Like the real weather tools, fall back to the location remembered from the conversation
+		if name == "location" && v == "" {
+			if v = t.conv.Entities.Location; v == "" {
+				return tools.NeedsClarification("location", "Which city or region do you mean?"), nil
+			}
+		}
+		// Like the real transfer tool, pick up at the destination airport of the flights discussed
+		if name == "pickup" && v == "" {
+			v = t.conv.Entities.Destination
+		}
+		values = append(values, strings.ToLower(v))
+	}
+	key := strings.Join(values, " ")
+
+	if response, ok := t.responses[key]; ok {
+		return response, nil
+	}
+	if response, ok := t.responses["*"]; ok {
+		return response, nil
+	}
+	return "", fmt.Errorf("no recorded response for %s with %q", t.Name(), key)
+}
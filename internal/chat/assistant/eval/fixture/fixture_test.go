@@ -0,0 +1,97 @@
+package fixture
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	conv := &model.Conversation{}
+	r := Registry(conv)
+
+	tests := []struct {
+		name       string
+		tool       string
+		args       string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{name: "recorded location", tool: "get_weather", args: `{"location":" Lisbon "}`, wantPrefix: "Lisbon: 19°C"},
+		{name: "recorded flight", tool: "get_flight_prices", args: `{"origin":"BCN","destination":"PAR","departureDate":"2025-11-14"}`, wantPrefix: "Found 3 flight options"},
+		{name: "wildcard recording", tool: "get_flight_prices", args: `{"origin":"BCN","destination":"NYC","departureDate":"2025-11-14"}`, wantPrefix: "No flights found"},
+		{name: "recorded flight status", tool: "get_flight_status", args: `{"flightNumber":"IB3402","date":"2025-11-10"}`, wantPrefix: "Flight IB3402 on 2025-11-10"},
+		{name: "recorded transfer", tool: "search_transfers", args: `{"pickup":"CDG","dropoff":"10 Rue de Rivoli","dateTime":"2025-11-14T10:30"}`, wantPrefix: "Found 3 transfer options"},
+		{name: "fixed date", tool: "get_today_date", args: `{}`, wantPrefix: "2025-11-10"},
+		{name: "no recording", tool: "get_weather", args: `{"location":"Atlantis"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Execute(ctx, tt.tool, []byte(tt.args))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Execute() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Errorf("Execute() = %q, want prefix %q", got, tt.wantPrefix)
+			}
+		})
+	}
+
+	// The location of the first call is remembered for follow-ups
+	got, err := r.Execute(ctx, "get_weather_forecast", []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.HasPrefix(got, "Lisbon forecast") {
+		t.Errorf("Execute() = %q, want the forecast for the remembered location", got)
+	}
+
+	if _, ok := tools.ParseClarification(mustExecute(t, Registry(&model.Conversation{}), "get_weather", `{}`)); !ok {
+		t.Error("Execute() without any location should ask for clarification")
+	}
+}
+
+func TestLoadRecordings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recordings.json")
+	if err := os.WriteFile(path, []byte(`{"get_weather": {"lisbon": "Lisbon: 31°C, heatwave"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadRecordings(path)
+	if err != nil {
+		t.Fatalf("LoadRecordings() error = %v", err)
+	}
+
+	reg := r.Registry(&model.Conversation{})
+	if got := mustExecute(t, reg, "get_weather", `{"location":"Lisbon"}`); got != "Lisbon: 31°C, heatwave" {
+		t.Errorf("recorded weather = %q", got)
+	}
+	// The tools the file doesn't record keep their built-in recordings
+	if got := mustExecute(t, reg, "get_today_date", `{}`); !strings.HasPrefix(got, "2025-11-10") {
+		t.Errorf("recorded date = %q", got)
+	}
+	if _, err := reg.Execute(context.Background(), "get_weather", []byte(`{"location":"Paris"}`)); err == nil {
+		t.Error("the built-in weather recordings were kept")
+	}
+}
+
+func mustExecute(t *testing.T, r *tools.Registry, name, args string) string {
+	t.Helper()
+	result, err := r.Execute(context.Background(), name, []byte(args))
+	if err != nil {
+		t.Fatalf("Execute(%s) error = %v", name, err)
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Golden snapshot statuses of a test result
+const (
+	GoldenNew       = "new"
+	GoldenUnchanged = "unchanged"
+	GoldenChanged   = "changed"
+)
+
+// Snapshot is the stored output of a test case, with the evaluation it got
+type Snapshot struct {
+	Title       string       `json:"title"`
+	Turns       []string     `json:"turns,omitempty"` // Replies to the earlier user turns
+	Reply       string       `json:"reply,omitempty"`
+	EvalResults []EvalResult `json:"eval_results"`
+}
+
+// snapshotOf returns the snapshot of an output, without evaluation
+func snapshotOf(actual ActualOutput) Snapshot {
+	s := Snapshot{Title: actual.Title, Reply: actual.Reply}
+	for _, turn := range actual.Turns {
+		s.Turns = append(s.Turns, turn.Reply)
+	}
+	return s
+}
+
+// sameOutput reports whether two snapshots have the same generated output
+func (s Snapshot) sameOutput(o Snapshot) bool {
+	return s.Title == o.Title && s.Reply == o.Reply && slices.Equal(s.Turns, o.Turns)
+}
+
+// diff describes the output changes from s to o, one "-" and "+" line pair per
+// changed field
+func (s Snapshot) diff(o Snapshot) string {
+	var b strings.Builder
+	field := func(name, before, after string) {
+		if before != after {
+			fmt.Fprintf(&b, "%s:\n- %q\n+ %q\n", name, before, after)
+		}
+	}
+
+	field("title", s.Title, o.Title)
+	for i := range max(len(s.Turns), len(o.Turns)) {
+		var before, after string
+		if i < len(s.Turns) {
+			before = s.Turns[i]
+		}
+		if i < len(o.Turns) {
+			after = o.Turns[i]
+		}
+		field(fmt.Sprintf("turn %d reply", i), before, after)
+	}
+	field("reply", s.Reply, o.Reply)
+	return b.String()
+}
+
+// printGolden prints the snapshot statuses of the report and the diffs of the changed
+// outputs, for review before updating the snapshots
+func printGolden(report *EvalReport) {
+	counts := map[string]int{}
+	for _, result := range report.TestResults {
+		counts[result.Golden]++
+	}
+	if counts[GoldenNew]+counts[GoldenUnchanged]+counts[GoldenChanged] == 0 {
+		return
+	}
+
+	fmt.Printf("Golden snapshots: %d unchanged, %d changed, %d new\n",
+		counts[GoldenUnchanged], counts[GoldenChanged], counts[GoldenNew])
+	fmt.Println(strings.Repeat("-", 60))
+	for _, result := range report.TestResults {
+		if result.Golden == GoldenChanged {
+			fmt.Printf("[%s]\n%s\n", result.TestCase.ID, result.GoldenDiff)
+		}
+	}
+	fmt.Println()
+}
+
+// Golden stores test case snapshots in a directory, one JSON file per test case.
+// Test cases whose output matches their snapshot reuse its evaluation, so unchanged
+// cases skip the evaluators (and the LLM judge). With update, the snapshots are
+// rewritten with the current outputs instead.
+type Golden struct {
+	dir    string
+	update bool
+}
+
+// NewGolden creates a snapshot store in dir
+func NewGolden(dir string, update bool) *Golden {
+	return &Golden{dir: dir, update: update}
+}
+
+func (g *Golden) path(id string) string {
+	return filepath.Join(g.dir, strings.ReplaceAll(id, string(filepath.Separator), "_")+".json")
+}
+
+// Load returns the snapshot of a test case, or nil when there is none
+func (g *Golden) Load(id string) (*Snapshot, error) {
+	data, err := os.ReadFile(g.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden snapshot: %w", err)
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse golden snapshot %s: %w", id, err)
+	}
+	return &s, nil
+}
+
+// Save stores the snapshot of a test case
+func (g *Golden) Save(id string, s Snapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal golden snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(g.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(g.path(id), data, 0644); err != nil {
+		return fmt.Errorf("failed to write golden snapshot: %w", err)
+	}
+
+	return nil
+}
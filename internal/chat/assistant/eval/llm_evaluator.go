@@ -6,9 +6,14 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/acai-travel/tech-challenge/internal/genai"
 	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+const tracerName = "github.com/acai-travel/tech-challenge/internal/chat/assistant/eval"
+
 // LLMEvaluator implements LLM-as-a-judge evaluation using GPT-4
 type LLMEvaluator struct {
 	client openai.Client
@@ -86,7 +91,7 @@ Expected criteria:
 - Ideal word count: 2-6 words
 
 Provide your evaluation in JSON format.`,
-		testCase.Input.Message,
+		testCase.Input.FirstMessage(),
 		actual.Title,
 		testCase.Expected.TitleKeywords,
 		testCase.Expected.TitleMaxLen,
@@ -97,12 +102,19 @@ Provide your evaluation in JSON format.`,
 		openai.UserMessage(userPrompt),
 	}
 
+	ctx, span := genai.StartChat(ctx, otel.Tracer(tracerName), openai.ChatModelGPT5,
+		attribute.String("eval.evaluator", e.Name()),
+		attribute.String("eval.test_case_id", testCase.ID),
+	)
+	defer span.End()
+
 	resp, err := e.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
 		Model:    openai.ChatModelGPT5,
 		Messages: msgs,
 		// Note: GPT-5 doesn't support temperature parameter
 		// Determinism is enforced through explicit prompt instructions
 	})
+	genai.RecordResponse(span, resp)
 
 	if err != nil {
 		return EvalResult{
@@ -241,11 +253,17 @@ Which title is better?`, userMessage, titleA, titleB)
 		openai.UserMessage(userPrompt),
 	}
 
+	ctx, span := genai.StartChat(ctx, otel.Tracer(tracerName), openai.ChatModelGPT5,
+		attribute.String("eval.evaluator", "pairwise"),
+	)
+	defer span.End()
+
 	resp, err := e.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
 		Model:    openai.ChatModelGPT5,
 		Messages: msgs,
 		// Note: GPT-5 doesn't support temperature parameter
 	})
+	genai.RecordResponse(span, resp)
 
 	if err != nil {
 		return "", "", fmt.Errorf("pairwise comparison failed: %w", err)
@@ -0,0 +1,80 @@
+package eval
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Policy decides whether an evaluation run passes as a quality gate, instead of failing
+// on any failed test case. Zero thresholds are not checked.
+type Policy struct {
+	MinPassRate     float64            // Share of passed test cases, 0-1
+	MinAverageScore float64            // Average score of the run, 0-1
+	MinCategoryRate map[string]float64 // Pass rate per metadata category, 0-1
+
+	// Baseline is an earlier report to compare with. A regression is a test case that
+	// passed in the baseline and fails now.
+	Baseline       *EvalReport
+	MaxRegressions int
+}
+
+// Check returns the violations of the policy by report, none when it passes
+func (p Policy) Check(report *EvalReport) []string {
+	var violations []string
+
+	if p.MinPassRate > 0 {
+		if rate := passRate(report.PassedTests, report.TotalTests); rate < p.MinPassRate {
+			violations = append(violations, fmt.Sprintf("pass rate %.1f%% < %.1f%%", rate*100, p.MinPassRate*100))
+		}
+	}
+
+	if p.MinAverageScore > 0 && report.AverageScore < p.MinAverageScore {
+		violations = append(violations, fmt.Sprintf("average score %.3f < %.3f", report.AverageScore, p.MinAverageScore))
+	}
+
+	for _, category := range slices.Sorted(maps.Keys(p.MinCategoryRate)) {
+		want := p.MinCategoryRate[category]
+		i := slices.IndexFunc(report.Categories, func(g GroupStats) bool { return g.Name == category })
+		if i < 0 {
+			violations = append(violations, fmt.Sprintf("category %s has no test cases", category))
+			continue
+		}
+		if g := report.Categories[i]; g.PassRate < want {
+			violations = append(violations, fmt.Sprintf("category %s pass rate %.1f%% < %.1f%%", category, g.PassRate*100, want*100))
+		}
+	}
+
+	if p.Baseline != nil {
+		if regressions := Regressions(p.Baseline, report); len(regressions) > p.MaxRegressions {
+			violations = append(violations, fmt.Sprintf("%d regression(s) > %d: %s",
+				len(regressions), p.MaxRegressions, strings.Join(regressions, ", ")))
+		}
+	}
+
+	return violations
+}
+
+// Regressions returns the IDs of the test cases that passed in baseline and fail in report
+func Regressions(baseline, report *EvalReport) []string {
+	passed := make(map[string]bool, len(baseline.TestResults))
+	for _, result := range baseline.TestResults {
+		passed[result.TestCase.ID] = result.OverallPass
+	}
+
+	var regressions []string
+	for _, result := range report.TestResults {
+		if passed[result.TestCase.ID] && !result.OverallPass {
+			regressions = append(regressions, result.TestCase.ID)
+		}
+	}
+	return regressions
+}
+
+func passRate(passed, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(passed) / float64(total)
+}
@@ -0,0 +1,102 @@
+package eval
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ReplyEvaluator checks the replies of multi-turn test cases against the expectations
+// of each turn. The runner applies it to every multi-turn case.
+type ReplyEvaluator struct{}
+
+// NewReplyEvaluator creates a new reply evaluator
+func NewReplyEvaluator() *ReplyEvaluator {
+	return &ReplyEvaluator{}
+}
+
+// Name returns the evaluator's name
+func (e *ReplyEvaluator) Name() string {
+	return "reply"
+}
+
+// Evaluate checks every generated reply that has expectations. The score is the share
+// of checks that passed, and any failed check fails the test case.
+func (e *ReplyEvaluator) Evaluate(testCase TestCase, actual ActualOutput) EvalResult {
+	checks, issues := 0, []string{}
+
+	for _, turn := range actual.Turns {
+		expected := testCase.Input.Turns[turn.Turn].Expected
+		if expected == nil {
+			continue
+		}
+		n, turnIssues := checkReply(fmt.Sprintf("turn %d", turn.Turn), *expected, turn.Reply, turn.ToolCalls)
+		checks += n
+		issues = append(issues, turnIssues...)
+	}
+
+	if expected := testCase.Expected.Reply; expected != nil {
+		toolCalls := make([]string, 0, len(actual.ToolCalls))
+		for _, call := range actual.ToolCalls {
+			toolCalls = append(toolCalls, call.ToolName)
+		}
+		n, replyIssues := checkReply("final reply", *expected, actual.Reply, toolCalls)
+		checks += n
+		issues = append(issues, replyIssues...)
+	}
+
+	score := 1.0
+	if checks > 0 {
+		score = float64(checks-len(issues)) / float64(checks)
+	}
+
+	details := "Replies meet all criteria"
+	if checks == 0 {
+		details = "No reply expectations"
+	}
+	if len(issues) > 0 {
+		details = strings.Join(issues, "; ")
+	}
+
+	return EvalResult{
+		TestCaseID: testCase.ID,
+		Passed:     len(issues) == 0,
+		Score:      score,
+		Details:    details,
+		Metrics: map[string]interface{}{
+			"checks": checks,
+			"failed": len(issues),
+		},
+		ActualValue: actual.Reply,
+	}
+}
+
+// checkReply runs the checks of expected on a reply. It returns the number of checks
+// and an issue for each failed one.
+func checkReply(label string, expected ReplyExpected, reply string, toolCalls []string) (int, []string) {
+	checks, issues := 0, []string{}
+	replyLower := strings.ToLower(reply)
+
+	for _, keyword := range expected.Keywords {
+		checks++
+		if !strings.Contains(replyLower, strings.ToLower(keyword)) {
+			issues = append(issues, fmt.Sprintf("%s: missing keyword %q", label, keyword))
+		}
+	}
+
+	for _, avoid := range expected.ShouldAvoid {
+		checks++
+		if strings.Contains(replyLower, strings.ToLower(avoid)) {
+			issues = append(issues, fmt.Sprintf("%s: contains avoided pattern %q", label, avoid))
+		}
+	}
+
+	for _, tool := range expected.ToolCalls {
+		checks++
+		if !slices.Contains(toolCalls, tool) {
+			issues = append(issues, fmt.Sprintf("%s: tool %s was not called (called: %v)", label, tool, toolCalls))
+		}
+	}
+
+	return checks, issues
+}
@@ -8,11 +8,67 @@ import (
 )
 
 // RuleEvaluator implements rule-based evaluation for title quality
-type RuleEvaluator struct{}
+type RuleEvaluator struct {
+	weights ScoringWeights
+}
+
+// RuleOption configures a RuleEvaluator
+type RuleOption func(*RuleEvaluator)
+
+// WithScoringWeights overrides the default weights for every test case, e.g. with the
+// weights of a dataset. Test cases can override them again with Expected.ScoringWeights.
+func WithScoringWeights(w ScoringWeights) RuleOption {
+	return func(e *RuleEvaluator) {
+		e.weights = w.over(e.weights)
+	}
+}
 
 // NewRuleEvaluator creates a new rule-based evaluator
-func NewRuleEvaluator() *RuleEvaluator {
-	return &RuleEvaluator{}
+func NewRuleEvaluator(opts ...RuleOption) *RuleEvaluator {
+	e := &RuleEvaluator{weights: DefaultScoringWeights()}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// DefaultScoringWeights returns the rule evaluator's default deductions and its 70% pass
+// threshold
+func DefaultScoringWeights() ScoringWeights {
+	return ScoringWeights{
+		MaxLen:        floatPtr(0.4),
+		WordCount:     floatPtr(0.3),
+		NoKeywords:    floatPtr(0.5),
+		FewKeywords:   floatPtr(0.3),
+		SomeKeywords:  floatPtr(0.1),
+		Emojis:        floatPtr(0.1),
+		Punctuation:   floatPtr(0.1),
+		PassThreshold: floatPtr(0.7),
+	}
+}
+
+// over returns base with the weights set in w
+func (w ScoringWeights) over(base ScoringWeights) ScoringWeights {
+	for _, f := range []struct{ dst, src **float64 }{
+		{&base.MaxLen, &w.MaxLen},
+		{&base.WordCount, &w.WordCount},
+		{&base.NoKeywords, &w.NoKeywords},
+		{&base.FewKeywords, &w.FewKeywords},
+		{&base.SomeKeywords, &w.SomeKeywords},
+		{&base.Emojis, &w.Emojis},
+		{&base.Punctuation, &w.Punctuation},
+		{&base.PassThreshold, &w.PassThreshold},
+	} {
+		if *f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	return base
+}
+
+// floatPtr returns a pointer to a float
+func floatPtr(f float64) *float64 {
+	return &f
 }
 
 // Name returns the evaluator's name
@@ -25,6 +81,11 @@ func (e *RuleEvaluator) Evaluate(testCase TestCase, actual ActualOutput) EvalRes
 	title := actual.Title
 	expected := testCase.Expected
 
+	w := e.weights
+	if expected.ScoringWeights != nil {
+		w = expected.ScoringWeights.over(w)
+	}
+
 	metrics := make(map[string]interface{})
 	issues := []string{}
 	score := 1.0 // Start with perfect score
@@ -34,7 +95,7 @@ func (e *RuleEvaluator) Evaluate(testCase TestCase, actual ActualOutput) EvalRes
 	metrics["title_length"] = titleLen
 
 	if expected.TitleMaxLen > 0 && titleLen > expected.TitleMaxLen {
-		score -= 0.4
+		score -= *w.MaxLen
 		issues = append(issues, fmt.Sprintf("Title exceeds max length: %d > %d", titleLen, expected.TitleMaxLen))
 	}
 
@@ -44,12 +105,12 @@ func (e *RuleEvaluator) Evaluate(testCase TestCase, actual ActualOutput) EvalRes
 	metrics["word_count"] = wordCount
 
 	if expected.TitleMinWords > 0 && wordCount < expected.TitleMinWords {
-		score -= 0.3
+		score -= *w.WordCount
 		issues = append(issues, fmt.Sprintf("Title has too few words: %d < %d", wordCount, expected.TitleMinWords))
 	}
 
 	if expected.TitleMaxWords > 0 && wordCount > expected.TitleMaxWords {
-		score -= 0.3
+		score -= *w.WordCount
 		issues = append(issues, fmt.Sprintf("Title has too many words: %d > %d", wordCount, expected.TitleMaxWords))
 	}
 
@@ -68,13 +129,13 @@ func (e *RuleEvaluator) Evaluate(testCase TestCase, actual ActualOutput) EvalRes
 		metrics["total_keywords"] = len(expected.TitleKeywords)
 
 		if keywordMatchRate == 0 {
-			score -= 0.5
+			score -= *w.NoKeywords
 			issues = append(issues, fmt.Sprintf("No keywords matched (0/%d)", len(expected.TitleKeywords)))
 		} else if keywordMatchRate < 0.5 {
-			score -= 0.3
+			score -= *w.FewKeywords
 			issues = append(issues, fmt.Sprintf("Low keyword match rate: %.2f", keywordMatchRate))
 		} else if keywordMatchRate < 1.0 {
-			score -= 0.1
+			score -= *w.SomeKeywords
 		}
 	}
 
@@ -102,13 +163,13 @@ func (e *RuleEvaluator) Evaluate(testCase TestCase, actual ActualOutput) EvalRes
 	// Check 6: Excessive punctuation or emojis
 	emojiPattern := regexp.MustCompile(`[\x{1F600}-\x{1F64F}\x{1F300}-\x{1F5FF}\x{1F680}-\x{1F6FF}\x{2600}-\x{26FF}\x{2700}-\x{27BF}]`)
 	if emojiPattern.MatchString(title) {
-		score -= 0.1
+		score -= *w.Emojis
 		issues = append(issues, "Title contains emojis")
 	}
 
 	punctCount := strings.Count(title, "!") + strings.Count(title, "?") + strings.Count(title, "...")
 	if punctCount > 1 {
-		score -= 0.1
+		score -= *w.Punctuation
 		issues = append(issues, "Title has excessive punctuation")
 	}
 
@@ -124,7 +185,8 @@ func (e *RuleEvaluator) Evaluate(testCase TestCase, actual ActualOutput) EvalRes
 	}
 
 	// Determine pass/fail
-	passed := score >= 0.7 // 70% threshold for passing
+	passed := score >= *w.PassThreshold
+	metrics["pass_threshold"] = *w.PassThreshold
 
 	details := "Title meets all criteria"
 	if len(issues) > 0 {
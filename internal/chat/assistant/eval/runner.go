@@ -9,6 +9,8 @@ import (
 
 	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/acai-travel/tech-challenge/internal/tools"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -16,14 +18,71 @@ import (
 type Runner struct {
 	assistant  *assistant.Assistant
 	evaluators []Evaluator
+	golden     *Golden
+	repeat     int
+	safety     *SafetyEvaluator
+	cache      *GenerationCache
+
+	// customTools is set when the assistant's tools answer differently from the default
+	// ones, with the same definitions
+	customTools bool
+}
+
+// RunnerOption configures a Runner
+type RunnerOption func(*Runner)
+
+// WithToolRegistry replaces the runner's assistant with one whose tools come from build,
+// e.g. fixture.Registry for reproducible replies. The assistant is created with
+// assistant.NewWithRegistryFactory and opts.
+func WithToolRegistry(build func(*model.Conversation) *tools.Registry, opts ...assistant.Option) RunnerOption {
+	return func(r *Runner) {
+		r.assistant = assistant.NewWithRegistryFactory(build, opts...)
+		r.customTools = true
+	}
+}
+
+// WithGolden compares the outputs of the test cases with their snapshots in g, see Golden
+func WithGolden(g *Golden) RunnerOption {
+	return func(r *Runner) {
+		r.golden = g
+	}
+}
+
+// WithRepeat generates n titles per test case to measure their stability, see
+// Stability. The first title is the one evaluated.
+func WithRepeat(n int) RunnerOption {
+	return func(r *Runner) {
+		r.repeat = n
+	}
+}
+
+// WithSafety replaces the safety evaluator gating the replies, e.g. with one using
+// WithModeration
+func WithSafety(e *SafetyEvaluator) RunnerOption {
+	return func(r *Runner) {
+		r.safety = e
+	}
+}
+
+// WithGenerationCache reuses the generations of earlier runs of the same assistant,
+// see GenerationCache
+func WithGenerationCache(c *GenerationCache) RunnerOption {
+	return func(r *Runner) {
+		r.cache = c
+	}
 }
 
 // NewRunner creates a new evaluation runner
-func NewRunner(asst *assistant.Assistant, evaluators []Evaluator) *Runner {
-	return &Runner{
+func NewRunner(asst *assistant.Assistant, evaluators []Evaluator, opts ...RunnerOption) *Runner {
+	r := &Runner{
 		assistant:  asst,
 		evaluators: evaluators,
+		safety:     NewSafetyEvaluator(),
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 // Run executes the evaluation for all test cases
@@ -77,25 +136,22 @@ func (r *Runner) Run(ctx context.Context, testCases []TestCase) (*EvalReport, er
 	// Calculate average score
 	totalScore := 0.0
 	for _, result := range report.TestResults {
-		// Average score across all evaluators for this test
-		testScore := 0.0
-		for _, evalResult := range result.EvalResults {
-			testScore += evalResult.Score
-		}
-		if len(result.EvalResults) > 0 {
-			testScore /= float64(len(result.EvalResults))
-		}
-		totalScore += testScore
+		totalScore += testScore(result)
 	}
 	if len(report.TestResults) > 0 {
 		report.AverageScore = totalScore / float64(len(report.TestResults))
 	}
+	aggregateUsage(report)
+	aggregateGroups(report)
+	aggregateStability(report)
 
 	slog.InfoContext(ctx, "Evaluation run completed",
 		"total", report.TotalTests,
 		"passed", report.PassedTests,
 		"failed", report.FailedTests,
 		"avg_score", report.AverageScore,
+		"latency_p95", time.Duration(report.LatencyP95),
+		"cost_usd", report.TotalCost,
 		"duration", report.Duration)
 
 	return report, nil
@@ -103,44 +159,46 @@ func (r *Runner) Run(ctx context.Context, testCases []TestCase) (*EvalReport, er
 
 // runTestCase executes a single test case
 func (r *Runner) runTestCase(ctx context.Context, testCase TestCase) (TestResult, error) {
-	startTime := time.Now()
-
-	// Create a conversation from the test case
-	conv := &model.Conversation{
-		ID: primitive.NewObjectID(),
-		Messages: []*model.Message{
-			{
-				ID:        primitive.NewObjectID(),
-				Role:      model.RoleUser,
-				Content:   testCase.Input.Message,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			},
-		},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	gen, cached, err := r.generation(ctx, testCase)
+	if err != nil {
+		return TestResult{}, err
 	}
+	actual := gen.Actual
 
-	// Generate title using the assistant
-	title, err := r.assistant.Title(ctx, conv)
-
-	duration := time.Since(startTime).Nanoseconds()
+	// Compare with the golden snapshot. Unchanged outputs keep their evaluation.
+	snapshot := snapshotOf(actual)
+	var golden, goldenDiff string
+	var evalResults []EvalResult
+	if r.golden != nil {
+		previous, err := r.golden.Load(testCase.ID)
+		if err != nil {
+			return TestResult{}, err
+		}
 
-	if err != nil {
-		return TestResult{}, fmt.Errorf("title generation failed: %w", err)
+		switch {
+		case previous == nil:
+			golden = GoldenNew
+		case previous.sameOutput(snapshot):
+			golden = GoldenUnchanged
+			if !r.golden.update {
+				evalResults = previous.EvalResults
+			}
+		default:
+			golden = GoldenChanged
+			goldenDiff = previous.diff(snapshot)
+		}
 	}
 
-	// Prepare actual output
-	actual := ActualOutput{
-		Title: title,
-		Error: nil,
+	// Run all evaluators
+	if evalResults == nil {
+		evalResults = r.evaluate(testCase, actual)
 	}
 
-	// Run all evaluators
-	evalResults := make([]EvalResult, 0, len(r.evaluators))
-	for _, evaluator := range r.evaluators {
-		result := evaluator.Evaluate(testCase, actual)
-		evalResults = append(evalResults, result)
+	if r.golden != nil && r.golden.update {
+		snapshot.EvalResults = evalResults
+		if err := r.golden.Save(testCase.ID, snapshot); err != nil {
+			return TestResult{}, err
+		}
 	}
 
 	// Determine overall pass/fail
@@ -152,13 +210,207 @@ func (r *Runner) runTestCase(ctx context.Context, testCase TestCase) (TestResult
 		}
 	}
 
-	return TestResult{
+	result := TestResult{
 		TestCase:    testCase,
 		Actual:      actual,
 		EvalResults: evalResults,
 		OverallPass: overallPass,
-		Duration:    duration,
-	}, nil
+		Duration:    gen.Duration,
+		Golden:      golden,
+		GoldenDiff:  goldenDiff,
+		Stability:   gen.Stability,
+		Cached:      cached,
+
+		LLMCalls:     gen.LLMCalls,
+		LLMLatency:   gen.LLMLatency,
+		InputTokens:  gen.InputTokens,
+		OutputTokens: gen.OutputTokens,
+		Cost:         gen.Cost,
+	}
+	return result, nil
+}
+
+// generation returns the output of a test case, from the cache when it has one for
+// the runner's assistant, and whether it was cached
+func (r *Runner) generation(ctx context.Context, testCase TestCase) (Generation, bool, error) {
+	if r.cache == nil {
+		gen, err := r.generate(ctx, testCase)
+		return gen, false, err
+	}
+
+	fingerprint := r.fingerprint()
+	cached, err := r.cache.Load(fingerprint, testCase)
+	if err != nil {
+		return Generation{}, false, err
+	}
+	if cached != nil && r.repeat <= 1 {
+		cached.Stability = nil
+	}
+	if cached != nil && (r.repeat <= 1 || cached.Stability != nil && len(cached.Stability.Titles) == r.repeat) {
+		slog.DebugContext(ctx, "Reusing cached generation", "id", testCase.ID, "fingerprint", fingerprint)
+		return *cached, true, nil
+	}
+
+	gen, err := r.generate(ctx, testCase)
+	if err != nil {
+		return Generation{}, false, err
+	}
+	gen.Fingerprint = fingerprint
+	if err := r.cache.Save(testCase, gen); err != nil {
+		return Generation{}, false, err
+	}
+	return gen, false, nil
+}
+
+// fingerprint identifies the generations of the runner's assistant. Custom tools
+// answer differently from the default ones with the same definitions.
+func (r *Runner) fingerprint() string {
+	fingerprint := r.assistant.Fingerprint()
+	if r.customTools {
+		fingerprint += "-custom-tools"
+	}
+	return fingerprint
+}
+
+// generate runs the assistant on a test case: the title, repeated with WithRepeat, and
+// the replies of multi-turn cases
+func (r *Runner) generate(ctx context.Context, testCase TestCase) (Generation, error) {
+	startTime := time.Now()
+
+	// Account the OpenAI calls made for this case
+	ctx, usage := genai.WithUsage(ctx)
+
+	// Create a conversation from the test case
+	conv := newConversation()
+	addMessage(conv, model.RoleUser, testCase.Input.FirstMessage())
+
+	// Generate title using the assistant
+	title, err := r.assistant.Title(ctx, conv)
+	if err != nil {
+		return Generation{}, fmt.Errorf("title generation failed: %w", err)
+	}
+
+	// Prepare actual output
+	gen := Generation{
+		Actual: ActualOutput{
+			Title: title,
+			Error: nil,
+		},
+	}
+
+	// Repeat the title generation to measure its stability
+	if r.repeat > 1 {
+		titles := []string{title}
+		for range r.repeat - 1 {
+			title, err := r.assistant.Title(ctx, conv)
+			if err != nil {
+				return Generation{}, fmt.Errorf("title generation failed: %w", err)
+			}
+			titles = append(titles, title)
+		}
+		gen.Stability = measureStability(titles)
+	}
+
+	// Multi-turn cases also generate the replies, to evaluate context carryover
+	if testCase.IsMultiTurn() {
+		if err := r.runConversation(ctx, testCase, &gen.Actual); err != nil {
+			return Generation{}, err
+		}
+	}
+
+	gen.Duration = time.Since(startTime).Nanoseconds()
+	recordUsage(&gen, usage)
+	return gen, nil
+}
+
+// evaluate runs all evaluators on the output of a test case
+func (r *Runner) evaluate(testCase TestCase, actual ActualOutput) []EvalResult {
+	evalResults := make([]EvalResult, 0, len(r.evaluators)+3)
+	for _, evaluator := range r.evaluators {
+		result := evaluator.Evaluate(testCase, actual)
+		evalResults = append(evalResults, result)
+	}
+	if testCase.IsMultiTurn() {
+		evalResults = append(evalResults, NewReplyEvaluator().Evaluate(testCase, actual))
+		evalResults = append(evalResults, r.safety.Evaluate(testCase, actual))
+	}
+	if testCase.Expected.Canary != "" {
+		evalResults = append(evalResults, NewInjectionEvaluator().Evaluate(testCase, actual))
+	}
+	return evalResults
+}
+
+// runConversation plays the turns of a multi-turn test case, generating the reply to
+// every user turn without a scripted one, then replies to the test case message.
+func (r *Runner) runConversation(ctx context.Context, testCase TestCase, actual *ActualOutput) error {
+	conv := newConversation()
+
+	turns := testCase.Input.Turns
+	for i, turn := range turns {
+		if turn.Role == RoleAssistant {
+			addMessage(conv, model.RoleAssistant, turn.Content)
+			continue
+		}
+
+		addMessage(conv, model.RoleUser, turn.Content)
+		if i+1 < len(turns) && turns[i+1].Role == RoleAssistant {
+			continue
+		}
+
+		reply, toolCalls, err := r.reply(ctx, conv)
+		if err != nil {
+			return fmt.Errorf("reply to turn %d failed: %w", i, err)
+		}
+		actual.Turns = append(actual.Turns, TurnOutput{Turn: i, Reply: reply, ToolCalls: toolCalls})
+	}
+
+	if testCase.Input.Message == "" {
+		return nil
+	}
+
+	addMessage(conv, model.RoleUser, testCase.Input.Message)
+	reply, toolCalls, err := r.reply(ctx, conv)
+	if err != nil {
+		return fmt.Errorf("reply generation failed: %w", err)
+	}
+
+	actual.Reply = reply
+	for _, name := range toolCalls {
+		actual.ToolCalls = append(actual.ToolCalls, ToolCall{ToolName: name})
+	}
+	return nil
+}
+
+// reply generates the reply to the last message of conv and appends it to the
+// conversation. It returns the reply and the tools called for it.
+func (r *Runner) reply(ctx context.Context, conv *model.Conversation) (string, []string, error) {
+	reply, err := r.assistant.Reply(ctx, conv)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The assistant records the tools it called on the user message
+	toolCalls := conv.Messages[len(conv.Messages)-1].ToolCalls
+	addMessage(conv, model.RoleAssistant, reply)
+	return reply, toolCalls, nil
+}
+
+func newConversation() *model.Conversation {
+	return &model.Conversation{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func addMessage(conv *model.Conversation, role model.Role, content string) {
+	conv.Messages = append(conv.Messages, &model.Message{
+		ID:        primitive.NewObjectID(),
+		Role:      role,
+		Content:   content,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
 }
 
 // RunSingleTest runs evaluation for a single test case (useful for debugging)
@@ -183,8 +435,17 @@ func PrintSummary(report *EvalReport) {
 		float64(report.FailedTests)/float64(report.TotalTests)*100)
 	fmt.Printf("Average score:  %.3f\n", report.AverageScore)
 	fmt.Printf("Duration:       %v\n", time.Duration(report.Duration))
+	fmt.Printf("Latency:        p50 %v, p95 %v\n",
+		time.Duration(report.LatencyP50).Round(time.Millisecond), time.Duration(report.LatencyP95).Round(time.Millisecond))
+	fmt.Printf("Tokens:         %d input, %d output\n", report.TotalInputTokens, report.TotalOutputTokens)
+	fmt.Printf("Estimated cost: $%.4f\n", report.TotalCost)
 	fmt.Println()
 
+	printGroups("By category", report.Categories)
+	printGroups("By difficulty", report.Difficulties)
+	printGolden(report)
+	printStability(report)
+
 	// Print failed tests
 	if report.FailedTests > 0 {
 		fmt.Println("Failed Tests:")
@@ -192,8 +453,16 @@ func PrintSummary(report *EvalReport) {
 		for _, result := range report.TestResults {
 			if !result.OverallPass {
 				fmt.Printf("\n[%s] %s\n", result.TestCase.ID, result.TestCase.Description)
-				fmt.Printf("  Input:    %q\n", result.TestCase.Input.Message)
+				fmt.Printf("  Input:    %q\n", result.TestCase.Input.FirstMessage())
 				fmt.Printf("  Title:    %q\n", result.Actual.Title)
+				for _, turn := range result.Actual.Turns {
+					fmt.Printf("  Turn %d:   %q\n", turn.Turn, result.TestCase.Input.Turns[turn.Turn].Content)
+					fmt.Printf("  Reply:    %q\n", turn.Reply)
+				}
+				if result.Actual.Reply != "" {
+					fmt.Printf("  Message:  %q\n", result.TestCase.Input.Message)
+					fmt.Printf("  Reply:    %q\n", result.Actual.Reply)
+				}
 				fmt.Println("  Issues:")
 				for _, evalResult := range result.EvalResults {
 					if !evalResult.Passed {
@@ -212,14 +481,8 @@ func PrintSummary(report *EvalReport) {
 		fmt.Println(strings.Repeat("-", 60))
 		for _, result := range report.TestResults {
 			if result.OverallPass {
-				avgScore := 0.0
-				for _, er := range result.EvalResults {
-					avgScore += er.Score
-				}
-				avgScore /= float64(len(result.EvalResults))
-
 				fmt.Printf("✓ [%s] %s (score: %.2f)\n",
-					result.TestCase.ID, result.TestCase.Description, avgScore)
+					result.TestCase.ID, result.TestCase.Description, testScore(result))
 			}
 		}
 	}
@@ -0,0 +1,110 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// DefaultSimilarityThreshold is the minimum cosine similarity between a message and its
+// title for text-embedding-3-small. Titles on the message's topic are usually well above
+// it, titles on another topic below.
+const DefaultSimilarityThreshold = 0.5
+
+// SimilarityEvaluator embeds the user's message and the generated title and scores their
+// cosine similarity, catching titles that pass the keyword checks but miss the topic
+type SimilarityEvaluator struct {
+	client    openai.Client
+	threshold float64
+}
+
+// NewSimilarityEvaluator creates a new embeddings-based evaluator passing titles whose
+// similarity with the message is at least threshold. opts configure the OpenAI client.
+func NewSimilarityEvaluator(threshold float64, opts ...option.RequestOption) *SimilarityEvaluator {
+	return &SimilarityEvaluator{
+		client:    openai.NewClient(opts...),
+		threshold: threshold,
+	}
+}
+
+// Name returns the evaluator's name
+func (e *SimilarityEvaluator) Name() string {
+	return "similarity"
+}
+
+// Evaluate scores the cosine similarity between the first message and the title
+func (e *SimilarityEvaluator) Evaluate(testCase TestCase, actual ActualOutput) EvalResult {
+	message := testCase.Input.FirstMessage()
+
+	resp, err := e.client.Embeddings.New(context.Background(), openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: []string{message, actual.Title}},
+		Model: openai.EmbeddingModelTextEmbedding3Small,
+	})
+	if err != nil {
+		return EvalResult{
+			TestCaseID:  testCase.ID,
+			Passed:      false,
+			Score:       0,
+			Details:     fmt.Sprintf("Embedding failed: %v", err),
+			ActualValue: actual.Title,
+		}
+	}
+	if len(resp.Data) != 2 {
+		return EvalResult{
+			TestCaseID:  testCase.ID,
+			Passed:      false,
+			Score:       0,
+			Details:     fmt.Sprintf("Expected 2 embeddings, got %d", len(resp.Data)),
+			ActualValue: actual.Title,
+		}
+	}
+
+	vectors := make([][]float64, 2)
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index > 1 {
+			continue
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	similarity := cosineSimilarity(vectors[0], vectors[1])
+	passed := similarity >= e.threshold
+
+	details := fmt.Sprintf("Title is on the message's topic (similarity %.2f)", similarity)
+	if !passed {
+		details = fmt.Sprintf("Title misses the message's topic (similarity %.2f < %.2f)", similarity, e.threshold)
+	}
+
+	return EvalResult{
+		TestCaseID: testCase.ID,
+		Passed:     passed,
+		Score:      max(0, similarity),
+		Details:    details,
+		Metrics: map[string]interface{}{
+			"similarity": similarity,
+			"threshold":  e.threshold,
+		},
+		ActualValue: actual.Title,
+	}
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 when either is
+// empty or their lengths differ
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
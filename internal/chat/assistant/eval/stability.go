@@ -0,0 +1,130 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Stability measures how much the titles generated for the same test case vary across
+// repeated runs
+type Stability struct {
+	Titles    []string `json:"titles"`
+	Distinct  int      `json:"distinct"`
+	Agreement float64  `json:"agreement"` // Share of runs generating the most common title (exact-match rate)
+
+	// Pairwise edit distance between the titles, in runes
+	EditDistanceMean float64 `json:"edit_distance_mean"`
+	EditDistanceMax  int     `json:"edit_distance_max"`
+}
+
+// Stable reports whether every run generated the same title
+func (s *Stability) Stable() bool {
+	return s.Distinct <= 1
+}
+
+// measureStability computes the stability of the titles generated for a test case
+func measureStability(titles []string) *Stability {
+	s := &Stability{Titles: titles}
+	if len(titles) == 0 {
+		return s
+	}
+
+	counts := map[string]int{}
+	top := 0
+	for _, title := range titles {
+		counts[title]++
+		top = max(top, counts[title])
+	}
+	s.Distinct = len(counts)
+	s.Agreement = float64(top) / float64(len(titles))
+
+	pairs, total := 0, 0
+	for i := range titles {
+		for j := i + 1; j < len(titles); j++ {
+			d := editDistance(titles[i], titles[j])
+			total += d
+			pairs++
+			s.EditDistanceMax = max(s.EditDistanceMax, d)
+		}
+	}
+	if pairs > 0 {
+		s.EditDistanceMean = float64(total) / float64(pairs)
+	}
+	return s
+}
+
+// editDistance returns the Levenshtein distance between a and b, in runes
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// aggregateStability computes the average title agreement of the report and the
+// number of unstable cases, when titles were repeated
+func aggregateStability(report *EvalReport) {
+	measured, agreement := 0, 0.0
+	for _, result := range report.TestResults {
+		if result.Stability == nil {
+			continue
+		}
+		measured++
+		agreement += result.Stability.Agreement
+		if !result.Stability.Stable() {
+			report.UnstableTests++
+		}
+	}
+	if measured > 0 {
+		report.TitleAgreement = agreement / float64(measured)
+	}
+}
+
+// printStability prints the unstable cases of the report, least stable first
+func printStability(report *EvalReport) {
+	var unstable []TestResult
+	measured := false
+	for _, result := range report.TestResults {
+		if result.Stability == nil {
+			continue
+		}
+		measured = true
+		if !result.Stability.Stable() {
+			unstable = append(unstable, result)
+		}
+	}
+	if !measured {
+		return
+	}
+
+	fmt.Printf("Title stability: %.1f%% agreement, %d unstable case(s)\n", report.TitleAgreement*100, report.UnstableTests)
+	fmt.Println(strings.Repeat("-", 60))
+	sort.SliceStable(unstable, func(i, j int) bool {
+		return unstable[i].Stability.Agreement < unstable[j].Stability.Agreement
+	})
+	for _, result := range unstable {
+		s := result.Stability
+		fmt.Printf("[%s] agreement %.0f%%, %d distinct, edit distance mean %.1f max %d\n",
+			result.TestCase.ID, s.Agreement*100, s.Distinct, s.EditDistanceMean, s.EditDistanceMax)
+		for _, title := range s.Titles {
+			fmt.Printf("  %q\n", title)
+		}
+	}
+	fmt.Println()
+}
@@ -14,6 +14,47 @@ type TestCase struct {
 // Input contains the input data for the test case
 type Input struct {
 	Message string `json:"message"`
+	// Turns are the earlier turns of a multi-turn conversation, Message being sent
+	// after them.
+	Turns []Turn `json:"turns,omitempty"`
+}
+
+// FirstMessage returns the message the conversation starts with, which its title
+// summarizes.
+func (in Input) FirstMessage() string {
+	for _, turn := range in.Turns {
+		if turn.Role != RoleAssistant {
+			return turn.Content
+		}
+	}
+	return in.Message
+}
+
+// IsMultiTurn reports whether the test case runs a conversation rather than a single
+// message.
+func (tc TestCase) IsMultiTurn() bool {
+	return len(tc.Input.Turns) > 0 || tc.Expected.Reply != nil
+}
+
+// Turn roles. An empty role is a user turn.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Turn is an earlier message of a multi-turn test case. The reply to a user turn is
+// generated, unless the next turn is a scripted assistant turn.
+type Turn struct {
+	Role     string         `json:"role,omitempty"`
+	Content  string         `json:"content"`
+	Expected *ReplyExpected `json:"expected,omitempty"` // Checks on the generated reply
+}
+
+// ReplyExpected contains the criteria for a generated reply
+type ReplyExpected struct {
+	Keywords    []string `json:"keywords,omitempty"`     // All must appear in the reply
+	ShouldAvoid []string `json:"should_avoid,omitempty"` // Patterns that shouldn't appear in the reply
+	ToolCalls   []string `json:"tool_calls,omitempty"`   // Tools that must be called
 }
 
 // Expected contains the expected outputs and criteria
@@ -23,6 +64,28 @@ type Expected struct {
 	TitleMinWords int      `json:"title_min_words,omitempty"`
 	TitleMaxWords int      `json:"title_max_words,omitempty"`
 	ShouldAvoid   []string `json:"should_avoid,omitempty"` // Patterns that shouldn't appear in title
+	// Reply checks the reply to Message, for multi-turn cases
+	Reply *ReplyExpected `json:"reply,omitempty"`
+	// ScoringWeights overrides the rule evaluator's deductions and pass threshold for
+	// this case
+	ScoringWeights *ScoringWeights `json:"scoring_weights,omitempty"`
+	// Canary is the token injected instructions ask for, for adversarial cases. Outputs
+	// containing it complied with them.
+	Canary string `json:"canary,omitempty"`
+}
+
+// ScoringWeights are the score deductions of the rule evaluator and its pass threshold.
+// Unset fields keep the weights of the evaluator, DefaultScoringWeights unless configured
+// with WithScoringWeights.
+type ScoringWeights struct {
+	MaxLen        *float64 `json:"max_len,omitempty"`       // Title longer than TitleMaxLen
+	WordCount     *float64 `json:"word_count,omitempty"`    // Fewer than TitleMinWords or more than TitleMaxWords
+	NoKeywords    *float64 `json:"no_keywords,omitempty"`   // No keyword matched
+	FewKeywords   *float64 `json:"few_keywords,omitempty"`  // Less than half of the keywords matched
+	SomeKeywords  *float64 `json:"some_keywords,omitempty"` // Some keywords missing
+	Emojis        *float64 `json:"emojis,omitempty"`
+	Punctuation   *float64 `json:"punctuation,omitempty"`    // More than one !, ? or ...
+	PassThreshold *float64 `json:"pass_threshold,omitempty"` // Minimum score to pass
 }
 
 // Metadata contains additional context about the test case
@@ -34,10 +97,18 @@ type Metadata struct {
 
 // ActualOutput represents the actual output from the assistant
 type ActualOutput struct {
-	Title     string     `json:"Title"`
-	Reply     string     `json:"Reply,omitempty"`
-	ToolCalls []ToolCall `json:"ToolCalls,omitempty"`
-	Error     *string    `json:"Error"`
+	Title     string       `json:"Title"`
+	Reply     string       `json:"Reply,omitempty"`
+	ToolCalls []ToolCall   `json:"ToolCalls,omitempty"`
+	Turns     []TurnOutput `json:"Turns,omitempty"` // Replies to the earlier user turns
+	Error     *string      `json:"Error"`
+}
+
+// TurnOutput is the reply generated for an earlier user turn
+type TurnOutput struct {
+	Turn      int      `json:"Turn"` // Index in Input.Turns
+	Reply     string   `json:"Reply"`
+	ToolCalls []string `json:"ToolCalls,omitempty"`
 }
 
 // ToolCall represents a tool that was called
@@ -62,7 +133,18 @@ type TestResult struct {
 	Actual      ActualOutput `json:"actual"`
 	EvalResults []EvalResult `json:"eval_results"`
 	OverallPass bool         `json:"overall_pass"`
-	Duration    int64        `json:"duration"` // nanoseconds
+	Duration    int64        `json:"duration"`              // nanoseconds
+	Golden      string       `json:"golden,omitempty"`      // Snapshot status: new, unchanged or changed
+	GoldenDiff  string       `json:"golden_diff,omitempty"` // Output changes from the snapshot
+	Stability   *Stability   `json:"stability,omitempty"`   // Titles of repeated runs, with -repeat
+	Cached      bool         `json:"cached,omitempty"`      // Output reused from an earlier run, see GenerationCache
+
+	// OpenAI usage of the assistant for this case, evaluators excluded
+	LLMCalls     int     `json:"llm_calls"`
+	LLMLatency   int64   `json:"llm_latency"` // nanoseconds spent waiting for OpenAI
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost_usd"` // Estimated from Prices
 }
 
 // EvalReport represents a complete evaluation run report
@@ -76,6 +158,21 @@ type EvalReport struct {
 	FailedTests  int          `json:"failed_tests"`
 	AverageScore float64      `json:"average_score"`
 	TestResults  []TestResult `json:"test_results"`
+
+	// Cost-performance of the assistant over all cases
+	LatencyP50        int64   `json:"latency_p50"` // nanoseconds, per-case OpenAI latency
+	LatencyP95        int64   `json:"latency_p95"` // nanoseconds, per-case OpenAI latency
+	TotalInputTokens  int64   `json:"total_input_tokens"`
+	TotalOutputTokens int64   `json:"total_output_tokens"`
+	TotalCost         float64 `json:"total_cost_usd"`
+
+	// Breakdown by test case metadata
+	Categories   []GroupStats `json:"categories,omitempty"`
+	Difficulties []GroupStats `json:"difficulties,omitempty"`
+
+	// Title stability over repeated runs
+	TitleAgreement float64 `json:"title_agreement,omitempty"` // Average agreement of the repeated cases
+	UnstableTests  int     `json:"unstable_tests,omitempty"`
 }
 
 // Evaluator is an interface for different evaluation strategies
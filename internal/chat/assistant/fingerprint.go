@@ -0,0 +1,45 @@
+package assistant
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+// PromptVersion identifies the revision of the assistant's prompts. The title and reply
+// prompts are part of Fingerprint already; bump it when changing the others (intent
+// classification, budget fallback).
+const PromptVersion = "2025-10-1"
+
+// Fingerprint identifies the outputs the assistant generates: it changes with the
+// prompt version, the title and reply prompts and models, the tool definitions, the
+// default reply options and the added instructions. Evaluations use it to reuse generations across runs.
+func (a *Assistant) Fingerprint() string {
+	registry := a.buildRegistry(&model.Conversation{})
+	names := registry.List()
+	slices.Sort(names)
+
+	tools := make([]any, 0, len(names))
+	for _, name := range names {
+		t, _ := registry.Get(name)
+		tools = append(tools, t.Definition())
+	}
+
+	data, _ := json.Marshal(struct {
+		PromptVersion string
+		TitleModel    string
+		TitlePrompt   string
+		ReplyModel    string
+		ReplyPrompt   string
+		NoToolsPrompt string
+		Tools         []any
+		ReplyOptions  ReplyOptions
+		Instructions  string `json:",omitempty"`
+	}{PromptVersion, titleModel, titlePrompt, string(a.replyModel), replyPrompt, noToolsPrompt, tools, a.replyOptions, a.instructions})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
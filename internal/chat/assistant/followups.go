@@ -0,0 +1,128 @@
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxFollowUps is the number of suggested follow-up questions returned after a reply.
+const maxFollowUps = 3
+
+// followUpContext is how many of the latest messages are used to suggest follow-ups.
+const followUpContext = 6
+
+var followUpSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"suggestions": map[string]any{
+			"type":  "array",
+			"items": map[string]string{"type": "string"},
+		},
+	},
+	"required":             []string{"suggestions"},
+	"additionalProperties": false,
+}
+
+// FollowUps suggests short questions the user may want to ask next, based on the end
+// of the conversation. It is meant to be called once the assistant has replied.
+func (a *Assistant) FollowUps(ctx context.Context, conv *model.Conversation) ([]string, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant")
+	ctx, span := tracer.Start(ctx, "Assistant.FollowUps",
+		trace.WithAttributes(
+			semconv.GenAIConversationID(conv.ID.Hex()),
+			semconv.GenAIOperationNameChat,
+			semconv.GenAIProviderNameOpenAI,
+			semconv.GenAIRequestModel(string(openai.ChatModelGPT4_1Nano)),
+		),
+	)
+	defer span.End()
+
+	var transcript strings.Builder
+	start := max(0, len(conv.Messages)-followUpContext)
+	for _, m := range conv.Messages[start:] {
+		transcript.WriteString(string(m.Role) + ": " + m.Content + "\n\n")
+	}
+
+	msgs := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("You suggest follow-up questions for a travel assistant chat. Given the end of the conversation, " +
+			"write 2 or 3 short questions (under 60 characters) the user is likely to ask next, phrased in the user's voice " +
+			"and language, e.g. \"What about the weekend forecast?\". Do not repeat questions that were already answered."),
+		openai.UserMessage(transcript.String()),
+	}
+
+	resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    openai.ChatModelGPT4_1Nano,
+		Messages: msgs,
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "follow_ups",
+					Schema: followUpSchema,
+					Strict: openai.Bool(true),
+				},
+			},
+		},
+	})
+	genai.RecordResponse(span, resp)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "OpenAI API call failed")
+		return nil, err
+	}
+
+	if len(resp.Choices) == 0 {
+		err := errors.New("empty response from OpenAI for follow-up suggestions")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "empty response")
+		return nil, err
+	}
+
+	suggestions, err := parseFollowUps(resp.Choices[0].Message.Content)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid response")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("follow_ups.count", len(suggestions)))
+	span.SetStatus(codes.Ok, "follow-ups generated")
+	return suggestions, nil
+}
+
+// parseFollowUps decodes the structured output, dropping blank and duplicate suggestions.
+func parseFollowUps(content string) ([]string, error) {
+	var out struct {
+		Suggestions []string `json:"suggestions"`
+	}
+	if err := json.Unmarshal([]byte(content), &out); err != nil {
+		return nil, err
+	}
+
+	var suggestions []string
+	seen := map[string]bool{}
+	for _, s := range out.Suggestions {
+		s = strings.TrimSpace(s)
+		if s == "" || seen[strings.ToLower(s)] {
+			continue
+		}
+		seen[strings.ToLower(s)] = true
+		suggestions = append(suggestions, s)
+		if len(suggestions) == maxFollowUps {
+			break
+		}
+	}
+
+	return suggestions, nil
+}
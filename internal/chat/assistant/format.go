@@ -0,0 +1,121 @@
+package assistant
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+var (
+	codeFence  = regexp.MustCompile("(?m)^\\s*```.*$\\n?")
+	heading    = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	bold       = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	italic     = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s](?:[^*_]*[^*_\s])?)[*_]`)
+	inlineCode = regexp.MustCompile("`([^`]*)`")
+	link       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	listItem   = regexp.MustCompile(`^\s*(?:[-*+•]|\d+[.)])\s+`)
+	sentence   = regexp.MustCompile(`[.!?](?:\s+|$)`)
+)
+
+// formatReply makes a reply follow the conversation's format and length, for the
+// times the model doesn't. Replies without a format or a length are left as they are.
+func formatReply(reply string, settings model.Settings) string {
+	switch settings.Format {
+	case model.FormatPlain:
+		reply = plainText(reply)
+	case model.FormatBullets:
+		reply = bulletSummary(reply)
+	}
+	if settings.MaxLength > 0 {
+		reply = truncate(reply, settings.MaxLength)
+	}
+	return reply
+}
+
+// plainText removes Markdown syntax from s, keeping the text it marks up. Links keep
+// their address in parentheses and list items their dashes, which read fine as text.
+func plainText(s string) string {
+	s = codeFence.ReplaceAllString(s, "")
+	s = heading.ReplaceAllString(s, "")
+	s = link.ReplaceAllString(s, "$1 ($2)")
+	s = inlineCode.ReplaceAllString(s, "$1")
+	s = bold.ReplaceAllString(s, "$1$2")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if m := listItem.FindString(line); m != "" {
+			line = "- " + line[len(m):]
+		}
+		lines[i] = line
+	}
+	s = strings.Join(lines, "\n")
+	s = italic.ReplaceAllString(s, "$1$2")
+	return strings.TrimSpace(s)
+}
+
+// bulletSummary turns s into a bullet list: list items stay, and every sentence of the
+// remaining text becomes an item of its own.
+func bulletSummary(s string) string {
+	var bullets []string
+	for _, line := range strings.Split(plainText(s), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if m := listItem.FindString(line); m != "" {
+			bullets = append(bullets, "- "+line[len(m):])
+			continue
+		}
+		for _, sent := range sentences(line) {
+			bullets = append(bullets, "- "+sent)
+		}
+	}
+	return strings.Join(bullets, "\n")
+}
+
+// sentences splits a line of text into its sentences.
+func sentences(line string) []string {
+	var out []string
+	for {
+		loc := sentence.FindStringIndex(line)
+		if loc == nil {
+			break
+		}
+		out = append(out, strings.TrimSpace(line[:loc[0]+1]))
+		line = line[loc[1]:]
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		out = append(out, line)
+	}
+	return out
+}
+
+// truncate cuts s to at most n characters, at the end of the last sentence or line that
+// fits when that keeps most of the text, or else at the end of a word, with an ellipsis.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+	cut := string(runes[:n-1])
+	if i := lastBoundary(cut); i >= len(cut)/2 {
+		return strings.TrimSpace(cut[:i])
+	}
+	if i := strings.LastIndexAny(cut, " \n"); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " \n,;:-") + "…"
+}
+
+// lastBoundary returns the index right after the last sentence end or line break in s,
+// or -1.
+func lastBoundary(s string) int {
+	end := strings.LastIndex(s, "\n")
+	for _, loc := range sentence.FindAllStringIndex(s, -1) {
+		end = max(end, loc[0]+1)
+	}
+	return end
+}
@@ -0,0 +1,122 @@
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Intent is what a user message is asking for, used to route the tool loop.
+type Intent string
+
+const (
+	IntentWeather  Intent = "weather"
+	IntentFlights  Intent = "flights"
+	IntentHolidays Intent = "holidays"
+	IntentGeneral  Intent = "general"
+)
+
+var intents = []string{string(IntentWeather), string(IntentFlights), string(IntentHolidays), string(IntentGeneral)}
+
+// intentTools lists the tools that answer each intent. General chat has none, so the
+// model decides freely.
+var intentTools = map[Intent][]string{
+	IntentWeather:  {"get_weather", "get_weather_forecast"},
+	IntentFlights:  {"get_flight_prices", "get_flight_status", "search_transfers"},
+	IntentHolidays: {"get_holidays"},
+}
+
+var intentSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"intent": map[string]any{"type": "string", "enum": intents},
+	},
+	"required":             []string{"intent"},
+	"additionalProperties": false,
+}
+
+var intentCounter metric.Int64Counter
+
+func init() {
+	var err error
+	intentCounter, err = otel.Meter("github.com/acai-travel/tech-challenge/internal/chat/assistant").Int64Counter(
+		"assistant.intent.count",
+		metric.WithDescription("Number of replies by detected intent"),
+		metric.WithUnit("{reply}"),
+	)
+	if err != nil {
+		// If metric creation fails, the counter will be nil and won't record anything
+	}
+}
+
+// classifyIntent detects the intent of the user message. Failures fall back to general
+// chat, which leaves tool use entirely to the model.
+func (a *Assistant) classifyIntent(ctx context.Context, message string) Intent {
+	ctx, span := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant").Start(ctx, "Assistant.ClassifyIntent")
+	defer span.End()
+
+	intent, err := a.requestIntent(ctx, message)
+	if err != nil {
+		span.RecordError(err)
+		intent = IntentGeneral
+	}
+
+	span.SetAttributes(attribute.String("assistant.intent", string(intent)))
+	if intentCounter != nil {
+		intentCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("intent", string(intent))))
+	}
+
+	return intent
+}
+
+func (a *Assistant) requestIntent(ctx context.Context, message string) (Intent, error) {
+	ctx, span := genai.StartChat(ctx, otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant"), openai.ChatModelGPT4_1Nano)
+	defer span.End()
+
+	resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: openai.ChatModelGPT4_1Nano,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage("Classify the intent of the travel assistant user's message: weather (current weather or forecasts), " +
+				"flights (flight prices, routes, the status of a flight or airport transfers), holidays (public or bank holidays) or general (anything else, including " +
+				"greetings and questions that mix several intents)."),
+			openai.UserMessage(message),
+		},
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "intent",
+					Schema: intentSchema,
+					Strict: openai.Bool(true),
+				},
+			},
+		},
+	})
+	genai.RecordResponse(span, resp)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", errors.New("empty response from OpenAI for intent classification")
+	}
+
+	var out struct {
+		Intent Intent `json:"intent"`
+	}
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &out); err != nil {
+		return "", err
+	}
+
+	if _, ok := intentTools[out.Intent]; !ok && out.Intent != IntentGeneral {
+		return "", errors.New("unknown intent " + string(out.Intent))
+	}
+
+	return out.Intent, nil
+}
@@ -0,0 +1,63 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// replyWithoutTools answers in a single completion without any tool definitions, for
+// requests and conversations that disabled tools. There is no intent classification
+// and no tool loop, only the reply budget's deadline applies.
+func (a *Assistant) replyWithoutTools(ctx context.Context, conv *model.Conversation) (string, error) {
+	run := a.startAttempt(ctx, conv)
+	if reply, ok := run.completed(); ok {
+		return reply, nil
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, a.budget.deadline(ctx, time.Now()))
+	defer cancel()
+
+	replyModel := a.modelFor(ctx)
+	ctx, span := genai.StartChat(ctx, otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant"), replyModel,
+		attribute.Bool("reply.tools_disabled", true),
+	)
+	defer span.End()
+
+	usage := &model.Usage{}
+	params := openai.ChatCompletionNewParams{
+		Model:    replyModel,
+		Messages: a.history(ctx, conv, noToolsPrompt),
+	}
+	applyCreativity(&params, conv.Settings)
+
+	resp, err := a.cli.Chat.Completions.New(ctx, params)
+	genai.RecordResponse(span, resp)
+	addUsage(usage, resp)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "API call failed")
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		err := errors.New("empty reply")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "empty response")
+		return "", err
+	}
+
+	reply := formatReply(resp.Choices[0].Message.Content, conv.Settings)
+	run.finish(ctx, reply)
+	recordTurn(conv.Messages[len(conv.Messages)-1], nil, usage)
+
+	span.SetStatus(codes.Ok, "reply generated")
+	return reply, nil
+}
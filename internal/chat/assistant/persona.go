@@ -0,0 +1,40 @@
+package assistant
+
+import (
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+)
+
+// persona is a character the assistant plays in a conversation: a prompt added to the
+// system prompt of its replies, and the tools it may use.
+type persona struct {
+	prompt string
+	// tools the persona may use; nil allows them all.
+	tools []string
+}
+
+var personas = map[string]persona{
+	model.PersonaBackpacker: {
+		prompt: "You are a budget backpacker advisor. Favor the cheapest options: flexible dates, budget airlines, public transport and walking over taxis, hostels and free activities. Mention prices and ways to save whenever you can.",
+		// Private transfers are beyond a backpacker's budget
+		tools: []string{
+			"get_today_date", "get_weather", "get_weather_forecast", "get_holidays", "get_flight_prices",
+			"get_flight_status", "get_travel_time", "generate_packing_list", "get_health_requirements", "set_reminder",
+			"set_trip_budget", "add_expense", "get_budget_status", "add_itinerary_item",
+			"get_calendar_trips", "add_itinerary_to_calendar", "get_booking_email_address",
+		},
+	},
+	model.PersonaConcierge: {
+		prompt: "You are a luxury travel concierge. Favor comfort and convenience over price: direct flights at good times, private transfers, premium hotels and exclusive experiences. Be courteous and anticipate what the traveler may need next.",
+	},
+	model.PersonaFamily: {
+		prompt: "You are a family trip planner. Plan around children: short travel times, kid-friendly activities, rest breaks and weather suited to little ones. Point out school and public holidays, as they drive prices and crowds, and what families should pack.",
+	},
+}
+
+// restrictTools removes the tools the conversation's persona may not use from registry.
+func restrictTools(registry *tools.Registry, conv *model.Conversation) {
+	if p, ok := personas[conv.Persona]; ok && p.tools != nil {
+		registry.Retain(p.tools...)
+	}
+}
@@ -0,0 +1,50 @@
+package assistant
+
+import "context"
+
+type progressKey struct{}
+
+// ProgressFunc is told about the steps of a reply as they start: the tool called and a
+// message for the user, like "Searching flights…".
+type ProgressFunc func(tool, message string)
+
+// WithProgress reports the steps of the Reply calls made with the returned context to
+// report, so that clients can show what the assistant is doing during long tool chains.
+func WithProgress(ctx context.Context, report ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// progressMessages are the messages of the tools' steps.
+var progressMessages = map[string]string{
+	"get_today_date":            "Checking today's date…",
+	"get_weather":               "Checking the weather…",
+	"get_weather_forecast":      "Checking the forecast…",
+	"get_holidays":              "Looking up public holidays…",
+	"get_flight_prices":         "Searching flights…",
+	"get_flight_status":         "Checking the flight status…",
+	"search_transfers":          "Searching airport transfers…",
+	"generate_packing_list":     "Putting a packing list together…",
+	"get_travel_time":           "Estimating the travel time…",
+	"get_health_requirements":   "Checking health requirements…",
+	"set_reminder":              "Setting a reminder…",
+	"set_trip_budget":           "Setting the trip budget…",
+	"add_expense":               "Adding the expense to the budget…",
+	"get_budget_status":         "Checking the budget…",
+	"add_itinerary_item":        "Adding it to the itinerary…",
+	"get_calendar_trips":        "Checking your calendar…",
+	"add_itinerary_to_calendar": "Adding the itinerary to your calendar…",
+	"get_booking_email_address": "Getting your booking forwarding address…",
+}
+
+// reportProgress tells the progress reporter of ctx, if any, that tool is being called.
+func reportProgress(ctx context.Context, tool string) {
+	report, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok {
+		return
+	}
+	message, ok := progressMessages[tool]
+	if !ok {
+		message = "Looking things up…"
+	}
+	report(tool, message)
+}
@@ -0,0 +1,56 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/recall"
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Recaller finds the past conversations of a user relevant to a message.
+type Recaller interface {
+	Recall(ctx context.Context, conv *model.Conversation, query string) ([]*recall.Memory, error)
+}
+
+// WithRecall lets replies bring up what the user told in past conversations relevant to
+// their message, like a preferred seat.
+func WithRecall(r Recaller) Option {
+	return func(a *Assistant) {
+		a.recall = r
+	}
+}
+
+// recalled returns a system message with the past conversations relevant to the messages
+// waiting for a reply. Replies go on without it when recall fails.
+func (a *Assistant) recalled(ctx context.Context, conv *model.Conversation) (openai.ChatCompletionMessageParamUnion, bool) {
+	if a.recall == nil || len(conv.Messages) == 0 {
+		return openai.ChatCompletionMessageParamUnion{}, false
+	}
+
+	memories, err := a.recall.Recall(ctx, conv, pendingContent(pending(conv)))
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to recall past conversations", "error", err)
+		return openai.ChatCompletionMessageParamUnion{}, false
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("reply.recalled", len(memories)))
+	if len(memories) == 0 {
+		return openai.ChatCompletionMessageParamUnion{}, false
+	}
+
+	var b strings.Builder
+	b.WriteString("From the user's past conversations, most relevant first. Use them only when they help, like a preference the user stated, and say where you got it from (\"you told me in March that...\"):")
+	for _, m := range memories {
+		fmt.Fprintf(&b, "\n- %s", m.ClosedAt.Format("January 2006"))
+		if m.Title != "" {
+			fmt.Fprintf(&b, ", %q", m.Title)
+		}
+		fmt.Fprintf(&b, ": %s", m.Summary)
+	}
+	return openai.SystemMessage(b.String()), true
+}
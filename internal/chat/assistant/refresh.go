@@ -0,0 +1,122 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrNothingToRefresh is returned by Refresh for replies without cited tool data.
+var ErrNothingToRefresh = errors.New("reply cites no tool data to refresh")
+
+// Refresh re-runs the tool calls cited by msg, an assistant reply of conv, and rewrites
+// the reply with their fresh results in a single completion, without re-running the
+// tool loop. It replaces the citations of msg and returns the updated reply. Calls that
+// fail keep their previous citation.
+func (a *Assistant) Refresh(ctx context.Context, conv *model.Conversation, msg *model.Message) (string, error) {
+	ctx = logging.WithConversationID(ctx, conv.ID.Hex())
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant")
+	ctx, span := tracer.Start(ctx, "Assistant.Refresh",
+		trace.WithAttributes(
+			semconv.GenAIConversationID(conv.ID.Hex()),
+			attribute.Int("refresh.citation_count", len(msg.Citations)),
+		),
+	)
+	defer span.End()
+
+	i := slices.Index(conv.Messages, msg)
+	if i < 0 || msg.Role != model.RoleAssistant {
+		err := errors.New("message is not a reply of the conversation")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid message")
+		return "", err
+	}
+	if len(msg.Citations) == 0 {
+		span.SetStatus(codes.Error, "nothing to refresh")
+		return "", ErrNothingToRefresh
+	}
+
+	registry := a.buildRegistry(conv)
+	restrictTools(registry, conv)
+
+	var results strings.Builder
+	citations := make([]model.Citation, 0, len(msg.Citations))
+	for _, old := range msg.Citations {
+		fetchedAt := time.Now()
+		result, err := registry.Execute(ctx, old.Tool, []byte(old.Arguments))
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to refresh tool data", "tool", old.Tool, "error", err)
+			citations = append(citations, old)
+			continue
+		}
+
+		c, ok := cite(registry, old.Tool, old.Arguments, fetchedAt)
+		if !ok {
+			c = old
+		}
+		citations = append(citations, c)
+		fmt.Fprintf(&results, "%s(%s):\n%s\n\n", old.Tool, old.Arguments, result)
+	}
+
+	if results.Len() == 0 {
+		err := errors.New("no tool data could be refreshed")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "refresh failed")
+		return "", err
+	}
+
+	// The reply is rewritten in the context of the conversation up to it
+	upTo := *conv
+	upTo.Messages = conv.Messages[:i+1]
+	msgs := append(a.history(ctx, &upTo, replyPrompt), openai.SystemMessage(
+		"The tools your last reply was based on were called again. Rewrite that reply with their fresh results below, "+
+			"keeping it as it was where nothing changed. Answer with the updated reply only.\n\n"+results.String()))
+
+	params := openai.ChatCompletionNewParams{
+		Model:    a.replyModel,
+		Messages: msgs,
+	}
+	applyCreativity(&params, conv.Settings)
+
+	callCtx, callSpan := genai.StartChat(ctx, tracer, a.replyModel)
+	resp, err := a.cli.Chat.Completions.New(callCtx, params)
+	genai.RecordResponse(callSpan, resp)
+	if err != nil {
+		callSpan.RecordError(err)
+		callSpan.SetStatus(codes.Error, "API call failed")
+	}
+	callSpan.End()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "OpenAI API call failed")
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		err := errors.New("empty refreshed reply")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "empty response")
+		return "", err
+	}
+
+	msg.Citations = citations
+	reply := resp.Choices[0].Message.Content
+	span.SetAttributes(attribute.String("reply.content", reply))
+	span.SetStatus(codes.Ok, "reply refreshed")
+	return reply, nil
+}
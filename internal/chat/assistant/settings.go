@@ -0,0 +1,52 @@
+package assistant
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/openai/openai-go/v2"
+)
+
+// verbosityPrompts are added to the system prompt of replies. Normal verbosity keeps the
+// reply prompt as is.
+var verbosityPrompts = map[string]string{
+	model.VerbosityBrief:    "Keep your answers brief: one to three sentences, without lists or headings unless the user asks for them.",
+	model.VerbosityDetailed: "Give detailed answers: include the relevant specifics, alternatives and caveats, and use lists where they make the answer easier to read.",
+}
+
+// formatPrompts are added to the system prompt of replies. Without a format, the
+// assistant formats replies as it sees fit.
+var formatPrompts = map[string]string{
+	model.FormatPlain:    "Write plain text only, without any Markdown: no headings, bold, tables, code blocks or link syntax.",
+	model.FormatMarkdown: "Format your answer in Markdown: short headings, bullet lists and bold for the key facts where they help.",
+	model.FormatBullets:  "Answer with a short bullet summary: one fact per line, each line starting with \"- \", without an introduction or a closing sentence.",
+}
+
+// lengthPrompt returns the system prompt of replies limited to maxLength characters.
+func lengthPrompt(maxLength int) string {
+	return fmt.Sprintf("Your answer must fit in %d characters, spaces included. Leave out details rather than getting cut off.", maxLength)
+}
+
+// creativityTemperatures are the sampling temperatures of replies. Balanced creativity
+// keeps the model's default.
+var creativityTemperatures = map[string]float64{
+	model.CreativityPrecise:  0.2,
+	model.CreativityCreative: 1.2,
+}
+
+// applyCreativity sets the sampling temperature of a reply completion for the
+// conversation's creativity. Reasoning models don't accept a temperature and keep
+// their default.
+func applyCreativity(params *openai.ChatCompletionNewParams, settings model.Settings) {
+	t, ok := creativityTemperatures[settings.Creativity]
+	if !ok || !supportsTemperature(params.Model) {
+		return
+	}
+	params.Temperature = openai.Float(t)
+}
+
+// supportsTemperature reports whether a chat model accepts a sampling temperature.
+func supportsTemperature(m openai.ChatModel) bool {
+	return !strings.HasPrefix(m, "gpt-5") && !strings.HasPrefix(m, "o")
+}
@@ -0,0 +1,66 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// summaryMessages bounds the messages a closing summary is written from: the last ones,
+// where trips are usually settled.
+const summaryMessages = 40
+
+// Summarize writes the closing summary of a conversation: what the user was planning
+// and what was found or decided, for the user to pick it up later.
+func (a *Assistant) Summarize(ctx context.Context, conv *model.Conversation) (string, error) {
+	ctx, span := genai.StartChat(ctx, otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant"), openai.ChatModelGPT4_1Mini)
+	defer span.End()
+
+	var transcript strings.Builder
+	messages := conv.Messages[max(0, len(conv.Messages)-summaryMessages):]
+	for _, m := range messages {
+		if m.Content == "" || m.State() != model.MessageStatusCompleted {
+			continue
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n\n", m.Role, m.Content)
+	}
+	if transcript.Len() == 0 {
+		err := errors.New("conversation has no messages")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "no messages")
+		return "", err
+	}
+
+	resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: openai.ChatModelGPT4_1Mini,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage("Summarize this travel conversation in three sentences at most, for the user to pick it up " +
+				"later: what they were planning (destination, dates, budget) and what was found or decided, like flights, " +
+				"weather or open questions. Write in the user's language, addressing them as \"you\"."),
+			openai.UserMessage(transcript.String()),
+		},
+	})
+	genai.RecordResponse(span, resp)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "OpenAI API call failed")
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		err := errors.New("empty summary")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "empty response")
+		return "", err
+	}
+
+	span.SetStatus(codes.Ok, "summary generated")
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,117 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"github.com/acai-travel/tech-challenge/internal/genai"
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// DefaultTitle is the title of conversations nothing better could be found for.
+const DefaultTitle = "Untitled conversation"
+
+// titleFallbackModel is tried when titleModel fails: cheaper, and served separately.
+const titleFallbackModel = openai.ChatModelGPT4_1Mini
+
+// maxHeuristicTitleWords bounds the words of titles taken from the first message.
+const maxHeuristicTitleWords = 6
+
+var titleCounter metric.Int64Counter
+
+func init() {
+	var err error
+	titleCounter, err = otel.Meter("github.com/acai-travel/tech-challenge/internal/chat/assistant").Int64Counter(
+		"assistant.title.count",
+		metric.WithDescription("Number of conversation titles by source: model, fallback_model, heuristic or default"),
+		metric.WithUnit("{title}"),
+	)
+	if err != nil {
+		// If metric creation fails, the counter will be nil and won't record anything
+	}
+}
+
+func recordTitleSource(ctx context.Context, source string) {
+	if titleCounter != nil {
+		titleCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("source", source)))
+	}
+}
+
+// requestTitle asks m for the title of a conversation starting with message.
+func (a *Assistant) requestTitle(ctx context.Context, m openai.ChatModel, message string) (string, error) {
+	ctx, span := genai.StartChat(ctx, otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/assistant"), m)
+	defer span.End()
+
+	slog.InfoContext(ctx, "API Request",
+		"model", m,
+		"system_prompt", titlePrompt,
+		"user_message", message)
+
+	resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: m,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(titlePrompt),
+			openai.UserMessage(message),
+		},
+	})
+	genai.RecordResponse(span, resp)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "API call failed")
+		return "", err
+	}
+
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		err := errors.New("empty response from OpenAI for title generation")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "empty response")
+		return "", err
+	}
+
+	slog.InfoContext(ctx, "Title API Response", "raw_title", resp.Choices[0].Message.Content)
+
+	title := resp.Choices[0].Message.Content
+	title = strings.ReplaceAll(title, "\n", " ")
+	title = strings.Trim(title, " \t\r\n-\"'")
+
+	if len(title) > 80 {
+		title = title[:80]
+	}
+	return title, nil
+}
+
+// heuristicTitle makes a title of the first words of the first sentence of message,
+// for when no model can. It is empty when message has no words.
+func heuristicTitle(message string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	if loc := sentence.FindStringIndex(first); loc != nil {
+		first = first[:loc[0]]
+	}
+
+	var words []string
+	for _, w := range strings.Fields(first) {
+		w = strings.TrimFunc(w, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		if w != "" {
+			words = append(words, w)
+		}
+		if len(words) == maxHeuristicTitleWords {
+			break
+		}
+	}
+	if len(words) == 0 {
+		return ""
+	}
+
+	title := []rune(strings.Join(words, " "))
+	title[0] = unicode.ToUpper(title[0])
+	return string(title)
+}
@@ -0,0 +1,113 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"github.com/openai/openai-go/v2"
+)
+
+// Tool choice modes. Any other value names the tool the model must call.
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceNone     = "none"
+	ToolChoiceRequired = "required"
+)
+
+// ReplyOptions control tool use in Reply.
+//
+// ToolChoice is "auto", "none", "required" or the name of a tool. "none" disables tools
+// for the whole reply; "required" and tool names only apply to the first step, so the
+// model can still answer once it has the tool results. An empty ToolChoice keeps the
+// default, where the detected intent decides.
+//
+// ParallelToolCalls allows or forbids several tool calls in one step; nil keeps the
+// model's default.
+//
+// DisableTools answers from the model alone, without sending any tool definitions:
+// faster and cheaper for chit-chat. It can only be turned on per request.
+type ReplyOptions struct {
+	ToolChoice        string
+	ParallelToolCalls *bool
+	DisableTools      bool
+}
+
+type replyOptionsKey struct{}
+
+// WithReplyOptions overrides the assistant's tool options for the Reply calls made with ctx.
+// Zero fields keep the assistant's defaults.
+func WithReplyOptions(ctx context.Context, opts ReplyOptions) context.Context {
+	return context.WithValue(ctx, replyOptionsKey{}, opts)
+}
+
+// WithToolChoice sets the default tool choice of every Reply, see ReplyOptions.
+func WithToolChoice(choice string) Option {
+	return func(a *Assistant) {
+		a.replyOptions.ToolChoice = choice
+	}
+}
+
+// WithParallelToolCalls allows or forbids parallel tool calls in every Reply.
+func WithParallelToolCalls(enabled bool) Option {
+	return func(a *Assistant) {
+		a.replyOptions.ParallelToolCalls = &enabled
+	}
+}
+
+// replyOptionsFor merges the per-request options from ctx over the assistant defaults.
+func (a *Assistant) replyOptionsFor(ctx context.Context) ReplyOptions {
+	opts := a.replyOptions
+	if o, ok := ctx.Value(replyOptionsKey{}).(ReplyOptions); ok {
+		if o.ToolChoice != "" {
+			opts.ToolChoice = o.ToolChoice
+		}
+		if o.ParallelToolCalls != nil {
+			opts.ParallelToolCalls = o.ParallelToolCalls
+		}
+		if o.DisableTools {
+			opts.DisableTools = true
+		}
+	}
+	return opts
+}
+
+// validate checks that a tool named as tool choice is available.
+func (o ReplyOptions) validate(registry *tools.Registry) error {
+	switch o.ToolChoice {
+	case "", ToolChoiceAuto, ToolChoiceNone, ToolChoiceRequired:
+		return nil
+	}
+
+	if _, ok := registry.Get(o.ToolChoice); !ok {
+		return fmt.Errorf("tool choice %q is not an available tool", o.ToolChoice)
+	}
+	return nil
+}
+
+// toolChoice returns the tool choice for an iteration of the tool loop. Without an
+// explicit choice, the first iteration is biased towards the intent: when the registry
+// has a tool for it, the model is required to call a tool instead of answering from
+// memory. Later iterations leave the choice to the model, except when tools are disabled.
+func (o ReplyOptions) toolChoice(intent Intent, registry *tools.Registry, iteration int) openai.ChatCompletionToolChoiceOptionUnionParam {
+	switch {
+	case o.ToolChoice == ToolChoiceNone:
+		return openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String(ToolChoiceNone)}
+	case iteration > 0:
+		return openai.ChatCompletionToolChoiceOptionUnionParam{}
+	case o.ToolChoice == ToolChoiceAuto:
+		return openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String(ToolChoiceAuto)}
+	case o.ToolChoice == ToolChoiceRequired:
+		return openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String(ToolChoiceRequired)}
+	case o.ToolChoice != "":
+		return openai.ToolChoiceOptionFunctionToolChoice(openai.ChatCompletionNamedToolChoiceFunctionParam{Name: o.ToolChoice})
+	}
+
+	for _, name := range intentTools[intent] {
+		if _, ok := registry.Get(name); ok {
+			return openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String(ToolChoiceRequired)}
+		}
+	}
+
+	return openai.ChatCompletionToolChoiceOptionUnionParam{}
+}
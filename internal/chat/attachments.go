@@ -0,0 +1,69 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/acai-travel/tech-challenge/internal/attachment"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+)
+
+// storeAttachments validates the uploaded files and saves them in the attachment store.
+// All uploads are validated before any is stored, and none is kept if storing one fails.
+func (s *Server) storeAttachments(ctx context.Context, uploads []*pb.AttachmentUpload) ([]*model.Attachment, error) {
+	if len(uploads) == 0 {
+		return nil, nil
+	}
+
+	if s.attachments == nil {
+		return nil, twirp.NewError(twirp.Unimplemented, "attachments are not enabled")
+	}
+
+	if len(uploads) > attachment.MaxPerMessage {
+		return nil, twirp.InvalidArgumentError("attachments", fmt.Sprintf("at most %d attachments per message", attachment.MaxPerMessage))
+	}
+
+	for i, u := range uploads {
+		if err := attachment.Validate(u.GetContentType(), len(u.GetData())); err != nil {
+			return nil, twirp.InvalidArgumentError(fmt.Sprintf("attachments[%d]", i), err.Error())
+		}
+	}
+
+	attachments := make([]*model.Attachment, 0, len(uploads))
+	for i, u := range uploads {
+		filename := path.Base(u.GetFilename())
+		if filename == "." || filename == "/" {
+			filename = fmt.Sprintf("attachment-%d", i+1)
+		}
+
+		id, err := s.attachments.Put(ctx, filename, u.GetContentType(), u.GetData())
+		if err != nil {
+			s.deleteAttachments(attachments).run(ctx)
+			return nil, twirp.InternalErrorWith(err)
+		}
+
+		attachments = append(attachments, &model.Attachment{
+			ID:          id,
+			Filename:    filename,
+			ContentType: u.GetContentType(),
+			Size:        int64(len(u.GetData())),
+		})
+	}
+
+	return attachments, nil
+}
+
+// deleteAttachments returns the compensation removing stored attachments, for requests
+// that fail after storing them.
+func (s *Server) deleteAttachments(attachments []*model.Attachment) compensations {
+	var undo compensations
+	for _, att := range attachments {
+		undo.add(func(ctx context.Context) error {
+			return s.attachments.Delete(ctx, att.ID)
+		})
+	}
+	return undo
+}
@@ -0,0 +1,21 @@
+package chat
+
+import (
+	"context"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+)
+
+// recordAudit records a change to a resource, with snapshots of it before and after
+// when given. Without an audit log, changes aren't recorded.
+func (s *Server) recordAudit(ctx context.Context, action, resourceID string, before, after any) {
+	if s.auditLog == nil {
+		return
+	}
+	audit.Record(ctx, s.auditLog, &audit.Entry{
+		Action:     action,
+		ResourceID: resourceID,
+		Before:     audit.Snapshot(before),
+		After:      audit.Snapshot(after),
+	})
+}
@@ -0,0 +1,106 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxBatchSize bounds the conversations of a single batch request.
+const maxBatchSize = 500
+
+func (s *Server) BatchDeleteConversations(ctx context.Context, req *pb.BatchDeleteConversationsRequest) (*pb.BatchDeleteConversationsResponse, error) {
+	ids, err := batchIDs(req.GetConversationIds())
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleted conversations are kept in the audit log, as they were
+	var before []*model.Conversation
+	if s.auditLog != nil {
+		if before, err = s.repo.ListOwned(ctx, auth.UserID(ctx), ids); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+	}
+
+	deleted, err := s.repo.BatchDelete(ctx, auth.UserID(ctx), ids)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	for _, c := range before {
+		s.recordAudit(ctx, audit.ConversationDelete, c.ID.Hex(), c, nil)
+	}
+	if s.memory != nil && deleted > 0 {
+		// Conversations of other users are left alone by the user filter
+		if err := s.memory.Forget(ctx, auth.UserID(ctx), ids); err != nil {
+			slog.ErrorContext(ctx, "Failed to forget deleted conversations", "error", err)
+		}
+	}
+
+	return &pb.BatchDeleteConversationsResponse{DeletedCount: int32(deleted)}, nil
+}
+
+func (s *Server) BatchArchiveConversations(ctx context.Context, req *pb.BatchArchiveConversationsRequest) (*pb.BatchArchiveConversationsResponse, error) {
+	ids, err := batchIDs(req.GetConversationIds())
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []*model.Conversation
+	if s.auditLog != nil {
+		if owned, err = s.repo.ListOwned(ctx, auth.UserID(ctx), ids); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+	}
+
+	updated, err := s.repo.BatchArchive(ctx, auth.UserID(ctx), ids, !req.GetUnarchive())
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	action := audit.ConversationArchive
+	if req.GetUnarchive() {
+		action = audit.ConversationUnarchive
+	}
+	for _, c := range owned {
+		// Conversations already in the requested state are left alone
+		if (c.ArchivedAt != nil) != req.GetUnarchive() {
+			continue
+		}
+		s.recordAudit(ctx, action, c.ID.Hex(), nil, nil)
+	}
+
+	return &pb.BatchArchiveConversationsResponse{UpdatedCount: int32(updated)}, nil
+}
+
+// batchIDs parses and deduplicates the conversation IDs of a batch request.
+func batchIDs(hexIDs []string) ([]primitive.ObjectID, error) {
+	if len(hexIDs) == 0 {
+		return nil, twirp.RequiredArgumentError("conversation_ids")
+	}
+	if len(hexIDs) > maxBatchSize {
+		return nil, twirp.InvalidArgumentError("conversation_ids", fmt.Sprintf("at most %d conversations per request", maxBatchSize))
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(hexIDs))
+	ids := make([]primitive.ObjectID, 0, len(hexIDs))
+	for i, hex := range hexIDs {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, twirp.InvalidArgumentError(fmt.Sprintf("conversation_ids[%d]", i), "must be a valid conversation ID")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,26 @@
+package chat
+
+import (
+	"context"
+	"log/slog"
+)
+
+// compensations undo the side effects of a request step by step, so a request that
+// fails midway leaves nothing behind. Mongo transactions are not an option as
+// attachments live in GridFS and the database may be a standalone server.
+type compensations []func(ctx context.Context) error
+
+func (c *compensations) add(undo func(ctx context.Context) error) {
+	*c = append(*c, undo)
+}
+
+// run undoes the recorded steps in reverse order. It runs even if the request was
+// cancelled, and failures are logged as there is nothing left to undo them.
+func (c compensations) run(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	for i := len(c) - 1; i >= 0; i-- {
+		if err := c[i](ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to undo a step of a failed request", "error", err)
+		}
+	}
+}
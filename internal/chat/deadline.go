@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
+	"github.com/twitchtv/twirp"
+)
+
+// maxTimeout bounds the time clients may ask to wait for a reply.
+const maxTimeout = 5 * time.Minute
+
+// replyTimeout validates the timeout_ms of a request. Zero means the client sets no
+// deadline of its own.
+func replyTimeout(ms int64) (time.Duration, error) {
+	timeout := time.Duration(ms) * time.Millisecond
+	switch {
+	case ms < 0:
+		return 0, twirp.InvalidArgumentError("timeout_ms", "must not be negative")
+	case timeout > maxTimeout:
+		return 0, twirp.InvalidArgumentError("timeout_ms", fmt.Sprintf("must be at most %d", maxTimeout.Milliseconds()))
+	}
+	return timeout, nil
+}
+
+// withReplyTimeout returns the context of a reply the client waits timeout for. Only
+// the reply runs under it: storing the conversation afterwards does not.
+func withReplyTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// deadlineExceeded turns a reply that ran out of time into a DeadlineExceeded error,
+// with the tool steps completed so far as metadata. A retry with the same attempt ID
+// resumes from them.
+func deadlineExceeded(err error, attemptID string) (twirp.Error, bool) {
+	var de *assistant.DeadlineError
+	if !errors.As(err, &de) {
+		return nil, false
+	}
+
+	twerr := twirp.NewError(twirp.DeadlineExceeded, "the reply did not finish before the deadline").
+		WithMeta("completed_steps", strconv.Itoa(de.Steps)).
+		WithMeta("tool_calls", strings.Join(de.ToolCalls, ","))
+	if id := strings.TrimSpace(attemptID); id != "" {
+		twerr = twerr.WithMeta("attempt_id", id)
+	}
+	return twerr, true
+}
@@ -0,0 +1,103 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// maxFeedbackComment bounds the comment of a feedback, in characters.
+const maxFeedbackComment = 2000
+
+var feedbackCounter metric.Int64Counter
+
+func init() {
+	var err error
+	feedbackCounter, err = otel.Meter("github.com/acai-travel/tech-challenge/internal/chat").Int64Counter(
+		"assistant.feedback.count",
+		metric.WithDescription("Number of ratings of assistant replies by rating and intent"),
+		metric.WithUnit("{feedback}"),
+	)
+	if err != nil {
+		// If metric creation fails, the counter will be nil and won't record anything
+	}
+}
+
+func (s *Server) SubmitFeedback(ctx context.Context, req *pb.SubmitFeedbackRequest) (*pb.SubmitFeedbackResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+	messageID, err := primitive.ObjectIDFromHex(req.GetMessageId())
+	if err != nil {
+		return nil, twirp.InvalidArgumentError("message_id", "must be a message ID")
+	}
+
+	var feedback *model.Feedback
+	switch rating := req.GetRating(); rating {
+	case model.RatingUp, model.RatingDown:
+		comment := strings.TrimSpace(req.GetComment())
+		if utf8.RuneCountInString(comment) > maxFeedbackComment {
+			return nil, twirp.InvalidArgumentError("comment", fmt.Sprintf("must be at most %d characters", maxFeedbackComment))
+		}
+		feedback = &model.Feedback{Rating: rating, Comment: comment, CreatedAt: time.Now()}
+	case "":
+		if req.GetComment() != "" {
+			return nil, twirp.InvalidArgumentError("comment", "needs a rating")
+		}
+	default:
+		return nil, twirp.InvalidArgumentError("rating", "must be up, down or empty")
+	}
+
+	conversation, err := s.ownedConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(conversation.Messages, func(m *model.Message) bool { return m.ID == messageID })
+	if i < 0 {
+		return nil, twirp.NotFoundError("message not found")
+	}
+	msg := conversation.Messages[i]
+	if msg.Role != model.RoleAssistant || msg.State() != model.MessageStatusCompleted {
+		return nil, twirp.NewError(twirp.FailedPrecondition, "only completed replies can be rated")
+	}
+
+	if err := s.repo.SetFeedback(ctx, conversation.ID, msg.ID, feedback); err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, audit.MessageFeedback, conversation.ID.Hex(), msg.Feedback, feedback)
+
+	if feedback != nil && feedbackCounter != nil {
+		feedbackCounter.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("feedback.rating", feedback.Rating),
+			attribute.Bool("feedback.comment", feedback.Comment != ""),
+			attribute.String("intent", answeredIntent(conversation, i)),
+		))
+	}
+
+	msg.Feedback = feedback
+	return &pb.SubmitFeedbackResponse{Message: msg.Proto()}, nil
+}
+
+// answeredIntent returns the intent of the user message the reply at index i answers,
+// empty when unknown.
+func answeredIntent(conv *model.Conversation, i int) string {
+	for j := i - 1; j >= 0; j-- {
+		if conv.Messages[j].Role == model.RoleUser {
+			return conv.Messages[j].Intent
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package chat
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/review"
+	"github.com/acai-travel/tech-challenge/internal/sanitize"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *Server) FlagConversation(ctx context.Context, req *pb.FlagConversationRequest) (*pb.FlagConversationResponse, error) {
+	if s.reviews == nil {
+		return nil, twirp.NewError(twirp.Unimplemented, "flagging conversations is not enabled")
+	}
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+	comment := strings.TrimSpace(req.GetComment())
+	if err := review.ValidateFlag(req.GetReason(), comment); err != nil {
+		field := "reason"
+		if slices.Contains(review.Reasons, req.GetReason()) {
+			field = "comment"
+		}
+		return nil, twirp.InvalidArgumentError(field, err.Error())
+	}
+
+	conversation, err := s.ownedConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetMessageId() != "" {
+		messageID, err := primitive.ObjectIDFromHex(req.GetMessageId())
+		if err != nil || !slices.ContainsFunc(conversation.Messages, func(m *model.Message) bool { return m.ID == messageID }) {
+			return nil, twirp.NotFoundError("message not found")
+		}
+	}
+
+	item := &review.Item{
+		ConversationID: conversation.ID.Hex(),
+		MessageID:      req.GetMessageId(),
+		UserID:         conversation.UserID,
+		Source:         review.SourceUser,
+		Reason:         req.GetReason(),
+		Comment:        comment,
+	}
+	if err := s.reviews.Flag(ctx, item); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	s.recordAudit(ctx, audit.ConversationFlag, conversation.ID.Hex(), nil, nil)
+
+	return &pb.FlagConversationResponse{}, nil
+}
+
+// flagUnsafe flags the reply for review when, before sanitization, it carried active
+// content. Flagging is best effort: the reply is sanitized either way.
+func (s *Server) flagUnsafe(ctx context.Context, conv *model.Conversation, messageID primitive.ObjectID, reply string) {
+	if s.reviews == nil || !sanitize.Unsafe(reply) {
+		return
+	}
+
+	item := &review.Item{
+		ConversationID: conv.ID.Hex(),
+		MessageID:      messageID.Hex(),
+		UserID:         conv.UserID,
+		Source:         review.SourceSafety,
+		Reason:         review.ReasonUnsafeReply,
+	}
+	if err := s.reviews.Flag(ctx, item); err != nil {
+		slog.WarnContext(ctx, "Failed to flag unsafe reply", "error", err)
+		return
+	}
+	slog.WarnContext(ctx, "Flagged unsafe reply for review", "review_id", item.ID.Hex())
+}
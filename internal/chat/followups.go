@@ -0,0 +1,29 @@
+package chat
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+// followUpTimeout bounds how long a reply waits for suggested follow-ups.
+const followUpTimeout = 5 * time.Second
+
+// suggestFollowUps attaches suggested follow-up questions to the last message of the
+// conversation, the assistant's reply. Suggestions are best effort: failures are
+// logged and the reply is returned without them.
+func (s *Server) suggestFollowUps(ctx context.Context, conv *model.Conversation) []string {
+	ctx, cancel := context.WithTimeout(ctx, followUpTimeout)
+	defer cancel()
+
+	suggestions, err := s.assist.FollowUps(ctx, conv)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to suggest follow-ups", "error", err)
+		return nil
+	}
+
+	conv.Messages[len(conv.Messages)-1].Suggestions = suggestions
+	return suggestions
+}
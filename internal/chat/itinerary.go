@@ -0,0 +1,82 @@
+package chat
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/pdf"
+	"github.com/twitchtv/twirp"
+)
+
+func (s *Server) ExportItinerary(ctx context.Context, req *pb.ExportItineraryRequest) (*pb.ExportItineraryResponse, error) {
+	if s.itineraries == nil {
+		return nil, twirp.NewError(twirp.Unimplemented, "itineraries are not enabled")
+	}
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+
+	conversation, err := s.ownedConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := s.itineraries.DescribeItinerary(ctx, conversation.ID)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	if it == nil || len(it.Items) == 0 {
+		return nil, twirp.NotFoundError("the conversation has no itinerary")
+	}
+
+	return &pb.ExportItineraryResponse{
+		Filename:    itinerary.Filename(conversation.Title),
+		ContentType: itinerary.ContentType,
+		Content:     []byte(itinerary.ICS(it, conversation.Title, time.Now())),
+	}, nil
+}
+
+func (s *Server) ExportTripPDF(ctx context.Context, req *pb.ExportTripPDFRequest) (*pb.ExportTripPDFResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+	ctx = logging.WithConversationID(ctx, req.GetConversationId())
+
+	conversation, err := s.ownedConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	trip := itinerary.Trip{Title: conversation.Title, Summary: conversation.Summary}
+	if s.itineraries != nil {
+		it, err := s.itineraries.DescribeItinerary(ctx, conversation.ID)
+		if err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+		trip.Itinerary = it
+	}
+
+	// Conversations still open have no closing summary yet; the document is printed
+	// without one if it can't be written
+	if trip.Summary == "" && s.summarizer != nil && len(conversation.Messages) > 0 {
+		summary, err := s.summarizer.Summarize(ctx, conversation)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to summarize conversation for its trip document", "error", err)
+		}
+		trip.Summary = summary
+	}
+
+	if trip.Summary == "" && (trip.Itinerary == nil || len(trip.Itinerary.Items) == 0) {
+		return nil, twirp.NotFoundError("the conversation has no summary or itinerary to print")
+	}
+
+	return &pb.ExportTripPDFResponse{
+		Filename:    itinerary.PDFFilename(conversation.Title),
+		ContentType: pdf.ContentType,
+		Content:     itinerary.PDF(trip, s.branding, time.Now()),
+	}, nil
+}
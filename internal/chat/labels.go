@@ -0,0 +1,83 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	maxTags      = 10
+	maxTagLength = 32
+	maxFolderLen = 64
+)
+
+func (s *Server) UpdateConversationLabels(ctx context.Context, req *pb.UpdateConversationLabelsRequest) (*pb.UpdateConversationLabelsResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+
+	tags, err := normalizeTags(req.GetTags())
+	if err != nil {
+		return nil, twirp.InvalidArgumentError("tags", err.Error())
+	}
+
+	folder := strings.TrimSpace(req.GetFolder())
+	if utf8.RuneCountInString(folder) > maxFolderLen {
+		return nil, twirp.InvalidArgumentError("folder", fmt.Sprintf("must be at most %d characters", maxFolderLen))
+	}
+
+	conversation, err := s.ownedConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateLabels(ctx, conversation.ID, tags, folder); err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, audit.ConversationRelabel, conversation.ID.Hex(),
+		bson.M{"tags": conversation.Tags, "folder": conversation.Folder},
+		bson.M{"tags": tags, "folder": folder})
+
+	conversation.Tags = tags
+	conversation.Folder = folder
+
+	return &pb.UpdateConversationLabelsResponse{Conversation: conversation.Proto()}, nil
+}
+
+// normalizeTag lowercases a tag and replaces inner whitespace with dashes, so
+// "Summer Trip" and "summer-trip" are the same tag.
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+// normalizeTags normalizes and deduplicates tags, keeping their order.
+func normalizeTags(tags []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = normalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+
+		if utf8.RuneCountInString(t) > maxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", t, maxTagLength)
+		}
+
+		seen[t] = true
+		out = append(out, t)
+	}
+
+	if len(out) > maxTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", maxTags)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,134 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/lockx"
+	"github.com/twitchtv/twirp"
+)
+
+const (
+	// leaseTTL is how long a conversation stays locked after the replica locking it
+	// stopped renewing its lock, e.g. because it crashed.
+	leaseTTL = time.Minute
+
+	// lockPoll is how often a call waiting for a conversation tries to lock it.
+	lockPoll = 250 * time.Millisecond
+)
+
+// ReplyLocker serializes the replies of each conversation, so that concurrent calls
+// don't answer the same history and overwrite each other's messages.
+type ReplyLocker interface {
+	// TryLock locks the conversation, reporting false when it is already locked.
+	TryLock(ctx context.Context, conversationID string) (unlock func(), ok bool, err error)
+}
+
+// ReplyLockWaitFromEnv reads how long a call waits for a locked conversation from
+// REPLY_LOCK_WAIT, a duration like "30s". Unset or zero, calls don't wait.
+func ReplyLockWaitFromEnv() (time.Duration, error) {
+	v := os.Getenv("REPLY_LOCK_WAIT")
+	if v == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("REPLY_LOCK_WAIT must be a duration like 30s, got %q", v)
+	}
+	return d, nil
+}
+
+// WithReplyLocks locks conversations with locks instead of within the process, and
+// lets calls wait up to wait for a locked conversation instead of failing at once.
+func WithReplyLocks(locks ReplyLocker, wait time.Duration) Option {
+	return func(s *Server) {
+		s.locks = locks
+		s.lockWait = wait
+	}
+}
+
+// lockConversation locks the conversation for a reply, waiting for the reply in
+// progress up to the lock wait of the server. It fails with twirp.Aborted when the
+// conversation is still locked.
+func (s *Server) lockConversation(ctx context.Context, id string) (func(), error) {
+	deadline := time.Now().Add(s.lockWait)
+	for {
+		unlock, ok, err := s.locks.TryLock(ctx, id)
+		if err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+		if ok {
+			return unlock, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, twirp.NewError(twirp.Aborted, "a reply is already being generated for the conversation")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, twirp.NewError(twirp.Aborted, "a reply is already being generated for the conversation")
+		case <-time.After(lockPoll):
+		}
+	}
+}
+
+// localLocks locks conversations within the process.
+type localLocks struct {
+	mu     sync.Mutex
+	locked map[string]bool
+}
+
+func newLocalLocks() *localLocks {
+	return &localLocks{locked: make(map[string]bool)}
+}
+
+func (l *localLocks) TryLock(_ context.Context, id string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.locked[id] {
+		return nil, false, nil
+	}
+	l.locked[id] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.locked, id)
+	}, true, nil
+}
+
+// LeaseLocks locks conversations across replicas with a lockx.Locker, renewing their
+// locks while held. Conversations are locked within the process first, so that the
+// calls of a replica don't take turns polling for its own locks.
+type LeaseLocks struct {
+	local  *localLocks
+	locker lockx.Locker
+	ttl    time.Duration
+}
+
+func NewLeaseLocks(locker lockx.Locker) *LeaseLocks {
+	return &LeaseLocks{local: newLocalLocks(), locker: locker, ttl: leaseTTL}
+}
+
+func (l *LeaseLocks) TryLock(ctx context.Context, id string) (func(), bool, error) {
+	unlockLocal, ok, _ := l.local.TryLock(ctx, id)
+	if !ok {
+		return nil, false, nil
+	}
+
+	// The lock is renewed until unlocked, as replies can outlast it
+	_, release, ok, err := lockx.Hold(ctx, l.locker, "conversation:"+id, l.ttl)
+	if err != nil || !ok {
+		unlockLocal()
+		return nil, false, err
+	}
+
+	return func() {
+		release()
+		unlockLocal()
+	}, true, nil
+}
@@ -0,0 +1,21 @@
+package model
+
+import "github.com/acai-travel/tech-challenge/internal/pb"
+
+// Attachment references a file uploaded with a message. The contents live in an
+// attachment store under ID.
+type Attachment struct {
+	ID          string `bson:"id"`
+	Filename    string `bson:"filename"`
+	ContentType string `bson:"content_type"`
+	Size        int64  `bson:"size"`
+}
+
+func (a *Attachment) Proto() *pb.Attachment {
+	return &pb.Attachment{
+		Id:          a.ID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/acai-travel/tech-challenge/internal/pb"
@@ -9,18 +10,109 @@ import (
 )
 
 type Conversation struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	Title     string             `bson:"subject"`
-	CreatedAt time.Time          `bson:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at"`
-	Messages  []*Message         `bson:"messages"`
+	ID         primitive.ObjectID `bson:"_id"`
+	UserID     string             `bson:"user_id,omitempty"`
+	Title      string             `bson:"subject"`
+	Tags       []string           `bson:"tags,omitempty"`
+	Folder     string             `bson:"folder,omitempty"`
+	ArchivedAt *time.Time         `bson:"archived_at,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+	// Messages are stored in MessageCollection, see MessageCount. Documents stored
+	// before keep them embedded until migrated.
+	Messages []*Message `bson:"messages,omitempty"`
+	// MessageCount and LastMessageAt summarize the messages, set by the repository on
+	// every write.
+	MessageCount  int        `bson:"message_count"`
+	LastMessageAt *time.Time `bson:"last_message_at,omitempty"`
+	// Entities are remembered from the tool calls of the conversation, see Entities.
+	Entities Entities `bson:"entities,omitempty"`
+	// Places are the place names of the conversation resolved to coordinates, see Place.
+	Places []Place `bson:"places,omitempty"`
+	// ToolsDisabled answers every reply without tools.
+	ToolsDisabled bool `bson:"tools_disabled,omitempty"`
+	// Settings tune how replies are generated, see Settings.
+	Settings Settings `bson:"settings,omitempty"`
+	// Persona is the character the assistant plays in the conversation, see ValidatePersona.
+	Persona string `bson:"persona,omitempty"`
+	// TemplateID is the template the conversation was started from, if any.
+	TemplateID string `bson:"template_id,omitempty"`
+	// Instructions guide the assistant through the conversation, copied from its
+	// template so that later edits of the template don't change it.
+	Instructions string `bson:"instructions,omitempty"`
+	// TitleSource is how the title was made, see TitleSourceModel.
+	TitleSource string `bson:"title_source,omitempty"`
+	// Summary is the closing summary of a conversation closed for being idle.
+	Summary string `bson:"summary,omitempty"`
+	// AutoArchived is set on conversations archived for being idle, rather than by their
+	// user.
+	AutoArchived bool `bson:"auto_archived,omitempty"`
+	// Revision counts the updates of the conversation, incremented by the repository on
+	// every write.
+	Revision int64 `bson:"revision,omitempty"`
+	// Queued is the reply waiting to be generated in the background, if any.
+	Queued *QueuedReply `bson:"queued,omitempty"`
+	// PinnedAt is when the conversation was pinned, listing it first; nil when it isn't.
+	PinnedAt *time.Time `bson:"pinned_at,omitempty"`
+	Favorite bool       `bson:"favorite,omitempty"`
+
+	// stored are the documents of the messages as last read or written, for updates to
+	// write only the messages that changed
+	stored map[primitive.ObjectID][]byte
+	// view identifies the messages read of a conversation read without all of them, see
+	// DescribeOptions; empty when they all were.
+	view string
+}
+
+// Sources of conversation titles, from best to worst.
+const (
+	// TitleSourceModel titles are generated by the title model.
+	TitleSourceModel = "model"
+	// TitleSourceFallbackModel titles are generated by a cheaper model, when the title
+	// model fails.
+	TitleSourceFallbackModel = "fallback_model"
+	// TitleSourceHeuristic titles are the first words of the first message, when no
+	// model is available.
+	TitleSourceHeuristic = "heuristic"
+	// TitleSourceDefault titles are a placeholder.
+	TitleSourceDefault = "default"
+)
+
+// ETag identifies the stored state of the conversation, changing with every update.
+// Conversations read without all their messages have their own, as they're not the same
+// representation.
+func (c *Conversation) ETag() string {
+	tag := c.ID.Hex() + "." + strconv.FormatInt(c.Revision, 10)
+	if c.view != "" {
+		tag += "." + c.view
+	}
+	return `"` + tag + `"`
 }
 
 func (c *Conversation) Proto() *pb.Conversation {
 	proto := &pb.Conversation{
-		Id:        c.ID.Hex(),
-		Title:     c.Title,
-		Timestamp: timestamppb.New(c.UpdatedAt),
+		Id:            c.ID.Hex(),
+		Title:         c.Title,
+		Timestamp:     timestamppb.New(c.UpdatedAt),
+		Tags:          c.Tags,
+		Folder:        c.Folder,
+		ToolsDisabled: c.ToolsDisabled,
+		Settings:      c.Settings.Proto(),
+		TemplateId:    c.TemplateID,
+		Persona:       c.Persona,
+		TitleSource:   c.TitleSource,
+		Summary:       c.Summary,
+		AutoArchived:  c.AutoArchived,
+		Revision:      c.Revision,
+		Favorite:      c.Favorite,
+		// Conversations not migrated yet count their embedded messages only
+		MessageCount: int32(max(c.MessageCount, len(c.Messages))),
+	}
+	if c.PinnedAt != nil {
+		proto.PinnedAt = timestamppb.New(*c.PinnedAt)
+	}
+	if c.ArchivedAt != nil {
+		proto.ArchivedAt = timestamppb.New(*c.ArchivedAt)
 	}
 
 	for _, m := range c.Messages {
@@ -29,3 +121,15 @@ func (c *Conversation) Proto() *pb.Conversation {
 
 	return proto
 }
+
+// ListFilter narrows down ListConversations. Zero fields match all active conversations.
+type ListFilter struct {
+	// UserID lists the conversations of a user, those without owner when empty.
+	UserID string
+	Tag    string
+	Folder string
+	// Archived lists archived conversations instead of active ones.
+	Archived bool
+	// Favorites lists favorite conversations only.
+	Favorites bool
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Entities are the key facts of a conversation (where, when and for how much),
+// remembered across messages so follow-up questions can leave them out.
+type Entities struct {
+	// Location is the last city or region the weather was looked up for.
+	Location string `bson:"location,omitempty"`
+	// Origin and Destination are the IATA codes of the last flight search.
+	Origin      string `bson:"origin,omitempty"`
+	Destination string `bson:"destination,omitempty"`
+	// Date is the last travel date, in YYYY-MM-DD format.
+	Date string `bson:"date,omitempty"`
+	// Budget is the last maximum price per traveler.
+	Budget int `bson:"budget,omitempty"`
+}
+
+// IsZero reports whether no entity is known. It also lets the bson encoder omit
+// empty entities.
+func (e Entities) IsZero() bool {
+	return e == Entities{}
+}
+
+// Merge overrides the entities with the ones set in o.
+func (e *Entities) Merge(o Entities) {
+	if o.Location != "" {
+		e.Location = o.Location
+	}
+	if o.Origin != "" {
+		e.Origin = o.Origin
+	}
+	if o.Destination != "" {
+		e.Destination = o.Destination
+	}
+	if o.Date != "" {
+		e.Date = o.Date
+	}
+	if o.Budget > 0 {
+		e.Budget = o.Budget
+	}
+}
+
+// String describes the known entities in a form suitable for the model's context.
+func (e Entities) String() string {
+	var parts []string
+	if e.Location != "" {
+		parts = append(parts, "location: "+e.Location)
+	}
+	if e.Origin != "" {
+		parts = append(parts, "origin airport: "+e.Origin)
+	}
+	if e.Destination != "" {
+		parts = append(parts, "destination airport: "+e.Destination)
+	}
+	if e.Date != "" {
+		parts = append(parts, "travel date: "+e.Date)
+	}
+	if e.Budget > 0 {
+		parts = append(parts, fmt.Sprintf("budget: %d", e.Budget))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Ratings of assistant replies.
+const (
+	RatingUp   = "up"
+	RatingDown = "down"
+)
+
+// Feedback is a user's rating of an assistant reply.
+type Feedback struct {
+	Rating    string    `bson:"rating"`
+	Comment   string    `bson:"comment,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func (f *Feedback) Proto() *pb.Feedback {
+	if f == nil {
+		return nil
+	}
+	return &pb.Feedback{
+		Rating:    f.Rating,
+		Comment:   f.Comment,
+		CreatedAt: timestamppb.New(f.CreatedAt),
+	}
+}
+
+// SetFeedback stores the feedback on a message of the conversation, or removes it when
+// f is nil.
+func (r *Repository) SetFeedback(ctx context.Context, id, messageID primitive.ObjectID, f *Feedback) error {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.SetFeedback")
+	span.SetAttributes(
+		attribute.String("conversation.id", id.Hex()),
+		attribute.String("message.id", messageID.Hex()),
+	)
+	defer span.End()
+
+	update := bson.M{"$unset": bson.M{"feedback": ""}}
+	if f != nil {
+		update = bson.M{"$set": bson.M{"feedback": f}}
+	}
+
+	res, err := r.conn.Collection(MessageCollection).UpdateOne(ctx,
+		bson.M{"_id": messageID, "conversation_id": id}, update)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to set feedback")
+		return err
+	}
+
+	// The message is still embedded in a conversation not migrated yet
+	if res.MatchedCount == 0 {
+		embedded := bson.M{"$unset": bson.M{"messages.$.feedback": ""}, "$inc": bson.M{"revision": 1}}
+		if f != nil {
+			embedded = bson.M{"$set": bson.M{"messages.$.feedback": f}, "$inc": bson.M{"revision": 1}}
+		}
+		res, err = r.conn.Collection(conversationCollection).UpdateOne(ctx,
+			bson.M{"_id": id, "messages._id": messageID}, embedded)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to set feedback")
+			return err
+		}
+
+		if res.MatchedCount == 0 {
+			span.SetStatus(codes.Error, "message not found")
+			return twirp.NotFoundError("message not found")
+		}
+
+		span.SetStatus(codes.Ok, "feedback set")
+		return nil
+	}
+
+	if _, err := r.conn.Collection(conversationCollection).UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"revision": 1}}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update conversation")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "feedback set")
+	return nil
+}
+
+// ListRated returns the conversations with a reply rated rating since the given time,
+// most recently created first.
+func (r *Repository) ListRated(ctx context.Context, rating string, since time.Time) ([]*Conversation, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.ListRated")
+	span.SetAttributes(attribute.String("feedback.rating", rating))
+	defer span.End()
+
+	rated := bson.M{
+		"feedback.rating":     rating,
+		"feedback.created_at": bson.M{"$gte": since},
+	}
+	ids, err := r.replica.Collection(MessageCollection).Distinct(ctx, "conversation_id", rated)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query rated messages")
+		return nil, err
+	}
+
+	// Conversations not migrated yet have their messages embedded
+	cursor, err := r.replica.Collection(conversationCollection).Find(ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"_id": bson.M{"$in": ids}},
+			bson.M{"messages": bson.M{"$elemMatch": rated}},
+		}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query conversations")
+		return nil, err
+	}
+
+	var items []*Conversation
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode conversations")
+		return nil, err
+	}
+
+	if err := r.loadMessages(ctx, r.replica, items...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read messages")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("conversations.count", len(items)))
+	span.SetStatus(codes.Ok, "rated conversations listed")
+	return items, nil
+}
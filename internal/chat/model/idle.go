@@ -0,0 +1,78 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ListIdle returns up to limit conversations not archived and not updated since before,
+// the least recently updated first.
+func (r *Repository) ListIdle(ctx context.Context, before time.Time, limit int) ([]*Conversation, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.ListIdle")
+	span.SetAttributes(attribute.String("idle.before", before.Format(time.RFC3339)))
+	defer span.End()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.conn.Collection(conversationCollection).Find(ctx,
+		bson.M{"archived_at": nil, "updated_at": bson.M{"$lt": before}}, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query idle conversations")
+		return nil, err
+	}
+
+	var items []*Conversation
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode conversations")
+		return nil, err
+	}
+
+	if err := r.loadMessages(ctx, r.conn, items...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read messages")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("conversations.count", len(items)))
+	span.SetStatus(codes.Ok, "idle conversations listed")
+	return items, nil
+}
+
+// CloseIdle archives a conversation with its closing summary and forgets what it
+// remembered from its tool calls. A conversation updated since before is left alone, as
+// it is no longer idle. It reports whether the conversation was closed.
+func (r *Repository) CloseIdle(ctx context.Context, id primitive.ObjectID, summary string, before, now time.Time) (bool, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.CloseIdle")
+	span.SetAttributes(attribute.String("conversation.id", id.Hex()))
+	defer span.End()
+
+	res, err := r.conn.Collection(conversationCollection).UpdateOne(ctx,
+		bson.M{"_id": id, "archived_at": nil, "updated_at": bson.M{"$lt": before}},
+		bson.M{
+			"$set":   bson.M{"summary": summary, "auto_archived": true, "archived_at": now},
+			"$unset": bson.M{"entities": "", "places": ""},
+			"$inc":   bson.M{"revision": 1},
+		})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to close conversation")
+		return false, err
+	}
+
+	span.SetAttributes(attribute.Bool("conversation.closed", res.ModifiedCount > 0))
+	span.SetStatus(codes.Ok, "conversation closed")
+	return res.ModifiedCount > 0, nil
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"cmp"
 	"time"
 
 	"github.com/acai-travel/tech-challenge/internal/pb"
@@ -8,19 +9,115 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// Statuses of messages. User messages are pending until answered, and failed when their
+// reply failed; assistant messages are queued until a worker picks them up, if queued,
+// then generating until the reply is ready. Messages stored without a status are
+// completed.
+const (
+	MessageStatusPending    = "pending"
+	MessageStatusQueued     = "queued"
+	MessageStatusGenerating = "generating"
+	MessageStatusCompleted  = "completed"
+	MessageStatusFailed     = "failed"
+)
+
 type Message struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	Role      Role               `bson:"role"`
-	Content   string             `bson:"content"`
-	CreatedAt time.Time          `bson:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at"`
+	ID          primitive.ObjectID `bson:"_id"`
+	Role        Role               `bson:"role"`
+	Content     string             `bson:"content"`
+	Attachments []*Attachment      `bson:"attachments,omitempty"`
+	Suggestions []string           `bson:"suggestions,omitempty"`
+	Intent      string             `bson:"intent,omitempty"`
+	ToolCalls   []string           `bson:"tool_calls,omitempty"`
+	Usage       *Usage             `bson:"usage,omitempty"`
+	Citations   []Citation         `bson:"citations,omitempty"`
+	Status      string             `bson:"status,omitempty"`
+	Feedback    *Feedback          `bson:"feedback,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+}
+
+// Usage counts the model tokens spent to answer a user message. Like ToolCalls, the
+// tools called to answer it, it is set on user messages by the assistant.
+type Usage struct {
+	InputTokens  int64 `bson:"input_tokens"`
+	OutputTokens int64 `bson:"output_tokens"`
+}
+
+// Citation is the source of facts in an assistant reply: a tool call whose result came
+// from an external data source. Its arguments let the reply be refreshed once the
+// data expires.
+type Citation struct {
+	Tool      string    `bson:"tool"`
+	Source    string    `bson:"source"`
+	Arguments string    `bson:"arguments,omitempty"`
+	FetchedAt time.Time `bson:"fetched_at"`
+	// ExpiresAt is zero when the data doesn't go stale.
+	ExpiresAt time.Time `bson:"expires_at,omitempty"`
+}
+
+// Stale reports whether the cited data has expired at now.
+func (c Citation) Stale(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && now.After(c.ExpiresAt)
+}
+
+func (c Citation) Proto() *pb.Citation {
+	proto := &pb.Citation{
+		Tool:      c.Tool,
+		Source:    c.Source,
+		FetchedAt: timestamppb.New(c.FetchedAt),
+		Stale:     c.Stale(time.Now()),
+	}
+	if !c.ExpiresAt.IsZero() {
+		proto.ExpiresAt = timestamppb.New(c.ExpiresAt)
+	}
+	return proto
+}
+
+// CitationsProto converts citations for a response.
+func CitationsProto(citations []Citation) []*pb.Citation {
+	var protos []*pb.Citation
+	for _, c := range citations {
+		protos = append(protos, c.Proto())
+	}
+	return protos
+}
+
+// DataAsOf returns when the oldest cited data was fetched, nil without citations.
+func DataAsOf(citations []Citation) *timestamppb.Timestamp {
+	if len(citations) == 0 {
+		return nil
+	}
+	oldest := citations[0].FetchedAt
+	for _, c := range citations[1:] {
+		if c.FetchedAt.Before(oldest) {
+			oldest = c.FetchedAt
+		}
+	}
+	return timestamppb.New(oldest)
+}
+
+// State returns the status of the message, completed for messages stored without one.
+func (m *Message) State() string {
+	return cmp.Or(m.Status, MessageStatusCompleted)
 }
 
 func (m *Message) Proto() *pb.Conversation_Message {
-	return &pb.Conversation_Message{
-		Id:        m.ID.Hex(),
-		Role:      m.Role.Proto(),
-		Content:   m.Content,
-		Timestamp: timestamppb.New(m.CreatedAt),
+	proto := &pb.Conversation_Message{
+		Id:          m.ID.Hex(),
+		Role:        m.Role.Proto(),
+		Content:     m.Content,
+		Timestamp:   timestamppb.New(m.CreatedAt),
+		Suggestions: m.Suggestions,
+		Intent:      m.Intent,
+		Citations:   CitationsProto(m.Citations),
+		Status:      m.State(),
+		Feedback:    m.Feedback.Proto(),
 	}
+
+	for _, a := range m.Attachments {
+		proto.Attachments = append(proto.Attachments, a.Proto())
+	}
+
+	return proto
 }
@@ -0,0 +1,278 @@
+package model
+
+import (
+	"bytes"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// MessageCollection stores the messages of conversations, a document each, so that long
+// conversations neither grow their document toward the 16MB limit of Mongo nor rewrite
+// every message on each update. Conversations stored before keep their messages
+// embedded until MigrateMessages moves them out.
+const MessageCollection = "messages"
+
+// storedMessage is a document of MessageCollection.
+type storedMessage struct {
+	Message        `bson:",inline"`
+	ConversationID primitive.ObjectID `bson:"conversation_id"`
+	// Seq is the position of the message in its conversation
+	Seq int `bson:"seq"`
+}
+
+// LookupMessages returns the aggregation stages setting the messages of each
+// conversation, with the given fields only, in order. Messages still embedded in their
+// conversation are kept whole.
+func LookupMessages(fields ...string) []bson.M {
+	project := bson.M{"_id": 0}
+	for _, f := range fields {
+		project[f] = 1
+	}
+
+	return []bson.M{
+		{"$lookup": bson.M{
+			"from":         MessageCollection,
+			"localField":   "_id",
+			"foreignField": "conversation_id",
+			"pipeline":     bson.A{bson.M{"$sort": bson.M{"seq": 1}}, bson.M{"$project": project}},
+			"as":           "stored_messages",
+		}},
+		{"$set": bson.M{"messages": bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$messages", bson.A{}}}, "$stored_messages"}}}},
+		{"$unset": "stored_messages"},
+	}
+}
+
+// EnsureMessageIndexes creates the indexes reading the messages of a conversation in
+// order, and finding rated replies.
+func (r *Repository) EnsureMessageIndexes(ctx context.Context) error {
+	_, err := r.conn.Collection(MessageCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "seq", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "feedback.rating", Value: 1}, {Key: "feedback.created_at", Value: -1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"feedback": bson.M{"$exists": true}}),
+		},
+	})
+	return err
+}
+
+// document returns c as stored in its collection: with the counters of its messages
+// rather than the messages themselves.
+func (c *Conversation) document() *Conversation {
+	c.MessageCount = len(c.Messages)
+	c.LastMessageAt = nil
+	if n := len(c.Messages); n > 0 {
+		last := c.Messages[n-1].CreatedAt
+		c.LastMessageAt = &last
+	}
+
+	doc := *c
+	doc.Messages = nil
+	return &doc
+}
+
+// encodeMessages returns the documents of the messages of c, by message ID. Messages
+// without ID get one.
+func encodeMessages(c *Conversation) (map[primitive.ObjectID][]byte, error) {
+	docs := make(map[primitive.ObjectID][]byte, len(c.Messages))
+	for i, m := range c.Messages {
+		if m.ID.IsZero() {
+			m.ID = primitive.NewObjectID()
+		}
+		doc, err := bson.Marshal(storedMessage{Message: *m, ConversationID: c.ID, Seq: i})
+		if err != nil {
+			return nil, err
+		}
+		docs[m.ID] = doc
+	}
+	return docs, nil
+}
+
+// writeMessages stores the messages of c that changed since they were read or written,
+// and deletes those it no longer has.
+func (r *Repository) writeMessages(ctx context.Context, c *Conversation) error {
+	docs, err := encodeMessages(c)
+	if err != nil {
+		return err
+	}
+
+	var models []mongo.WriteModel
+	for id, doc := range docs {
+		if prev, ok := c.stored[id]; ok && bytes.Equal(prev, doc) {
+			continue
+		}
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetReplacement(bson.Raw(doc)).
+			SetUpsert(true))
+	}
+	var removed []primitive.ObjectID
+	for id := range c.stored {
+		if _, ok := docs[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	if len(removed) > 0 {
+		models = append(models, mongo.NewDeleteManyModel().SetFilter(bson.M{"conversation_id": c.ID, "_id": bson.M{"$in": removed}}))
+	}
+
+	if len(models) > 0 {
+		if _, err := r.conn.Collection(MessageCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+	c.stored = docs
+	return nil
+}
+
+// loadMessages reads the messages of convs from db. Conversations with their messages
+// still embedded are left as they are.
+func (r *Repository) loadMessages(ctx context.Context, db *mongo.Database, convs ...*Conversation) error {
+	byID := make(map[primitive.ObjectID]*Conversation, len(convs))
+	ids := make([]primitive.ObjectID, 0, len(convs))
+	for _, c := range convs {
+		if len(c.Messages) > 0 {
+			continue
+		}
+		c.stored = make(map[primitive.ObjectID][]byte, c.MessageCount)
+		byID[c.ID] = c
+		ids = append(ids, c.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cursor, err := db.Collection(MessageCollection).Find(ctx,
+		bson.M{"conversation_id": bson.M{"$in": ids}},
+		options.Find().SetSort(bson.D{{Key: "conversation_id", Value: 1}, {Key: "seq", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	for cursor.Next(ctx) {
+		var m storedMessage
+		if err := cursor.Decode(&m); err != nil {
+			return err
+		}
+		c, ok := byID[m.ConversationID]
+		if !ok {
+			continue
+		}
+		// Encoded again rather than kept raw, to compare with the documents of updates
+		doc, err := bson.Marshal(m)
+		if err != nil {
+			return err
+		}
+		c.stored[m.ID] = doc
+		msg := m.Message
+		c.Messages = append(c.Messages, &msg)
+	}
+	return cursor.Err()
+}
+
+// loadLastMessages reads the last n messages of c, unless they're still embedded.
+func (r *Repository) loadLastMessages(ctx context.Context, c *Conversation, n int) error {
+	if len(c.Messages) > 0 {
+		return nil
+	}
+
+	cursor, err := r.conn.Collection(MessageCollection).Find(ctx,
+		bson.M{"conversation_id": c.ID},
+		options.Find().SetSort(bson.D{{Key: "seq", Value: -1}}).SetLimit(int64(n)))
+	if err != nil {
+		return err
+	}
+
+	var docs []storedMessage
+	if err := cursor.All(ctx, &docs); err != nil {
+		return err
+	}
+	c.Messages = make([]*Message, len(docs))
+	for i, m := range docs {
+		msg := m.Message
+		c.Messages[len(docs)-1-i] = &msg
+	}
+	return nil
+}
+
+// deleteMessages deletes the messages of the given conversations.
+func (r *Repository) deleteMessages(ctx context.Context, ids ...primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.conn.Collection(MessageCollection).DeleteMany(ctx, bson.M{"conversation_id": bson.M{"$in": ids}})
+	return err
+}
+
+// MigrateMessages moves the messages embedded in conversations out to
+// MessageCollection, returning how many conversations it migrated. Conversations
+// updated while they're migrated are left for another run.
+func (r *Repository) MigrateMessages(ctx context.Context) (int, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.MigrateMessages")
+	defer span.End()
+
+	cursor, err := r.conn.Collection(conversationCollection).Find(ctx, bson.M{"messages": bson.M{"$exists": true}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query conversations")
+		return 0, err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var c Conversation
+		if err := cursor.Decode(&c); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to decode conversation")
+			return migrated, err
+		}
+		if err := r.writeMessages(ctx, &c); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to write messages")
+			return migrated, err
+		}
+
+		doc := c.document()
+		revision := bson.M{"$exists": false}
+		if c.Revision != 0 {
+			revision = bson.M{"$eq": c.Revision}
+		}
+		set := bson.M{"message_count": doc.MessageCount}
+		if doc.LastMessageAt != nil {
+			set["last_message_at"] = *doc.LastMessageAt
+		}
+		res, err := r.conn.Collection(conversationCollection).UpdateOne(ctx,
+			bson.M{"_id": c.ID, "revision": revision},
+			bson.M{"$set": set, "$unset": bson.M{"messages": ""}})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to update conversation")
+			return migrated, err
+		}
+		if res.ModifiedCount > 0 {
+			migrated++
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "cursor error")
+		return migrated, err
+	}
+
+	span.SetAttributes(attribute.Int("conversations.migrated", migrated))
+	span.SetStatus(codes.Ok, "messages migrated")
+	return migrated, nil
+}
@@ -0,0 +1,114 @@
+package model_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	. "github.com/acai-travel/tech-challenge/internal/chat/testing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRepository_Messages(t *testing.T) {
+	t.Run("stores messages apart from their conversation", WithFixture(func(t *testing.T, f *Fixture) {
+		ctx := context.Background()
+		c := f.CreateConversation()
+		db := ConnectMongo()
+
+		for i := range 50 {
+			c.Messages = append(c.Messages, &model.Message{
+				ID:        primitive.NewObjectID(),
+				Role:      model.RoleAssistant,
+				Content:   fmt.Sprintf("reply %d", i),
+				CreatedAt: c.CreatedAt.Add(time.Duration(i+1) * time.Minute),
+			})
+		}
+		if err := f.UpdateConversation(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+
+		var doc bson.M
+		if err := db.Collection("conversations").FindOne(ctx, bson.M{"_id": c.ID}).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := doc["messages"]; ok || doc["message_count"] != int32(51) {
+			t.Errorf("conversation document = %v, want the message count without the messages", doc)
+		}
+		n, err := db.Collection(model.MessageCollection).CountDocuments(ctx, bson.M{"conversation_id": c.ID})
+		if err != nil || n != 51 {
+			t.Errorf("stored %d messages (error %v), want 51", n, err)
+		}
+
+		got, err := f.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Messages) != 51 || got.Messages[50].Content != "reply 49" {
+			t.Fatalf("DescribeConversation() returned %d messages, want the 51 in order", len(got.Messages))
+		}
+
+		// Updates write the messages that changed only
+		got.Messages[1].Content = "edited"
+		got.Messages = got.Messages[:10]
+		if err := f.UpdateConversation(ctx, got); err != nil {
+			t.Fatal(err)
+		}
+		again, err := f.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(again.Messages) != 10 || again.Messages[1].Content != "edited" || again.MessageCount != 10 {
+			t.Errorf("after update: %d messages, count %d, second %q", len(again.Messages), again.MessageCount, again.Messages[1].Content)
+		}
+	}))
+
+	t.Run("migrates embedded messages", WithFixture(func(t *testing.T, f *Fixture) {
+		ctx := context.Background()
+		c := f.CreateConversation()
+		db := ConnectMongo()
+
+		// Stored like conversations were before
+		legacy := []bson.M{{"_id": primitive.NewObjectID(), "role": "user", "content": "Hi", "created_at": c.CreatedAt}}
+		if _, err := db.Collection("conversations").UpdateOne(ctx, bson.M{"_id": c.ID}, bson.M{"$set": bson.M{"messages": legacy}}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Collection(model.MessageCollection).DeleteMany(ctx, bson.M{"conversation_id": c.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := f.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil || len(got.Messages) != 1 || got.Messages[0].Content != "Hi" {
+			t.Fatalf("DescribeConversation() of a legacy conversation = %v, %v", got, err)
+		}
+
+		if _, err := f.MigrateMessages(ctx); err != nil {
+			t.Fatal(err)
+		}
+		n, err := db.Collection("conversations").CountDocuments(ctx, bson.M{"_id": c.ID, "messages": bson.M{"$exists": true}})
+		if err != nil || n != 0 {
+			t.Errorf("conversation still embeds its messages after migration")
+		}
+		got, err = f.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil || len(got.Messages) != 1 || got.Messages[0].Content != "Hi" {
+			t.Errorf("DescribeConversation() of a migrated conversation = %v, %v", got, err)
+		}
+	}))
+}
+
+func TestLookupMessages(t *testing.T) {
+	stages := model.LookupMessages("role", "created_at")
+	if len(stages) != 3 {
+		t.Fatalf("got %d stages, want a lookup, a set and an unset", len(stages))
+	}
+	lookup, ok := stages[0]["$lookup"].(bson.M)
+	if !ok || lookup["from"] != model.MessageCollection || lookup["foreignField"] != "conversation_id" {
+		t.Errorf("lookup = %v", stages[0])
+	}
+	project := lookup["pipeline"].(bson.A)[1].(bson.M)["$project"].(bson.M)
+	if len(project) != 3 || project["role"] != 1 || project["created_at"] != 1 || project["_id"] != 0 {
+		t.Errorf("projection = %v, want the given fields only", project)
+	}
+}
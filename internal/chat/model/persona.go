@@ -0,0 +1,24 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Personas the assistant can play in a conversation. The empty persona is the default,
+// general travel assistant.
+const (
+	PersonaBackpacker = "budget-backpacker"
+	PersonaConcierge  = "luxury-concierge"
+	PersonaFamily     = "family-planner"
+)
+
+var personas = []string{PersonaBackpacker, PersonaConcierge, PersonaFamily}
+
+// ValidatePersona checks p is a known persona, or empty.
+func ValidatePersona(p string) error {
+	if p != "" && !slices.Contains(personas, p) {
+		return fmt.Errorf("persona must be one of %v", personas)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package model
+
+import "strings"
+
+// Place is a place name of the conversation resolved to a canonical name and
+// coordinates, so that follow-ups naming it again mean the same place without asking
+// which one again.
+type Place struct {
+	// Query is the name the place was asked for, e.g. "Springfield".
+	Query string  `bson:"query"`
+	Name  string  `bson:"name"`
+	Lat   float64 `bson:"lat"`
+	Lon   float64 `bson:"lon"`
+}
+
+// Located reports whether the coordinates of the place are known.
+func (p Place) Located() bool {
+	return p.Lat != 0 || p.Lon != 0
+}
+
+// Place returns the place name was resolved to earlier in the conversation. Both the
+// name asked for and the canonical name match, ignoring case.
+func (c *Conversation) Place(name string) (Place, bool) {
+	name = strings.TrimSpace(name)
+	for _, p := range c.Places {
+		if strings.EqualFold(p.Query, name) || strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Place{}, false
+}
+
+// RememberPlace records what a place name was resolved to, replacing an earlier
+// resolution of the same name.
+func (c *Conversation) RememberPlace(p Place) {
+	for i := range c.Places {
+		if strings.EqualFold(c.Places[i].Query, p.Query) {
+			c.Places[i] = p
+			return
+		}
+	}
+	c.Places = append(c.Places, p)
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// queueClaimTimeout is how long a claimed reply may take before another worker claims it
+// again, longer than any reply.
+const queueClaimTimeout = 10 * time.Minute
+
+// QueuedReply is a reply waiting to be generated in the background, the conversation's
+// last message, see MessageStatusQueued.
+type QueuedReply struct {
+	MessageID primitive.ObjectID `bson:"message_id"`
+	QueuedAt  time.Time          `bson:"queued_at"`
+	// ClaimedAt is when a worker last picked the reply up, if any.
+	ClaimedAt *time.Time `bson:"claimed_at,omitempty"`
+	// Attempts counts the claims of the reply.
+	Attempts int `bson:"attempts"`
+	// AttemptID is the ID clients follow the reply's progress with, if any.
+	AttemptID    string `bson:"attempt_id,omitempty"`
+	DisableTools bool   `bson:"disable_tools,omitempty"`
+}
+
+// EnsureQueueIndex creates the index workers find queued replies with.
+func (r *Repository) EnsureQueueIndex(ctx context.Context) error {
+	_, err := r.conn.Collection(conversationCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "queued.queued_at", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"queued.queued_at": bson.M{"$exists": true}}),
+	})
+	return err
+}
+
+// ClaimQueued claims the conversation whose reply has been queued the longest, for a
+// worker to generate it. Replies claimed longer than queueClaimTimeout ago are claimed
+// again, as their worker is likely gone. It returns nil when no reply is queued.
+func (r *Repository) ClaimQueued(ctx context.Context, now time.Time) (*Conversation, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.ClaimQueued")
+	defer span.End()
+
+	filter := bson.M{
+		"queued.queued_at": bson.M{"$exists": true},
+		"$or": bson.A{
+			bson.M{"queued.claimed_at": nil},
+			bson.M{"queued.claimed_at": bson.M{"$lt": now.Add(-queueClaimTimeout)}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"queued.claimed_at": now},
+		"$inc": bson.M{"queued.attempts": 1, "revision": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "queued.queued_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var c Conversation
+	err := r.conn.Collection(conversationCollection).FindOneAndUpdate(ctx, filter, update, opts).Decode(&c)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "no reply queued")
+		return nil, nil
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to claim queued reply")
+		return nil, err
+	}
+
+	if err := r.loadMessages(ctx, r.conn, &c); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read messages")
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("conversation.id", c.ID.Hex()),
+		attribute.Int("queued.attempts", c.Queued.Attempts),
+	)
+	span.SetStatus(codes.Ok, "queued reply claimed")
+	return &c, nil
+}
+
+// CountQueued returns how many replies are queued, claimed by a worker or not.
+func (r *Repository) CountQueued(ctx context.Context) (int64, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.CountQueued")
+	defer span.End()
+
+	n, err := r.conn.Collection(conversationCollection).CountDocuments(ctx, bson.M{"queued.queued_at": bson.M{"$exists": true}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count queued replies")
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Int64("queued.count", n))
+	span.SetStatus(codes.Ok, "queued replies counted")
+	return n, nil
+}
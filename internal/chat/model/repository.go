@@ -3,7 +3,10 @@ package model
 import (
 	"context"
 	"errors"
+	"strconv"
+	"time"
 
+	"github.com/acai-travel/tech-challenge/internal/mongox"
 	"github.com/twitchtv/twirp"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,6 +15,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -20,11 +24,14 @@ const (
 
 type Repository struct {
 	conn *mongo.Database
+	// replica serves the lists and aggregations, which tolerate stale data
+	replica *mongo.Database
 }
 
 func New(conn *mongo.Database) *Repository {
 	return &Repository{
-		conn: conn,
+		conn:    conn,
+		replica: mongox.Replica(conn),
 	}
 }
 
@@ -37,10 +44,23 @@ func (r *Repository) CreateConversation(ctx context.Context, c *Conversation) er
 	)
 	defer span.End()
 
-	_, err := r.conn.Collection(conversationCollection).InsertOne(ctx, c)
+	// Messages go first, so that the conversation is never read without them
+	if err := r.writeMessages(ctx, c); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to write messages")
+		return err
+	}
+
+	_, err := r.conn.Collection(conversationCollection).InsertOne(ctx, c.document())
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to create conversation")
+		// The messages of a conversation that exists already are its own
+		if !mongo.IsDuplicateKeyError(err) {
+			if err := r.deleteMessages(context.WithoutCancel(ctx), c.ID); err != nil {
+				span.RecordError(err)
+			}
+		}
 		return err
 	}
 
@@ -48,10 +68,46 @@ func (r *Repository) CreateConversation(ctx context.Context, c *Conversation) er
 	return nil
 }
 
+// ErrPartialConversation is returned updating a conversation read without all its
+// messages, which would drop or renumber those left out.
+var ErrPartialConversation = errors.New("conversation read without all its messages")
+
+// DescribeOptions narrow down the messages DescribeConversationWith reads, for views
+// that don't need the whole history. The zero value reads them all.
+type DescribeOptions struct {
+	// WithoutMessages reads none of the messages.
+	WithoutMessages bool
+	// LastMessages reads the last LastMessages messages only, when positive.
+	LastMessages int
+}
+
+// view identifies the messages read with o, empty when they all are.
+func (o DescribeOptions) view() string {
+	switch {
+	case o.WithoutMessages:
+		return "m0"
+	case o.LastMessages > 0:
+		return "m" + strconv.Itoa(o.LastMessages)
+	default:
+		return ""
+	}
+}
+
 func (r *Repository) DescribeConversation(ctx context.Context, id string) (*Conversation, error) {
+	return r.DescribeConversationWith(ctx, id, DescribeOptions{})
+}
+
+// DescribeConversationWith reads a conversation with the messages opts select.
+// Conversations read without all their messages can't be updated, see
+// ErrPartialConversation.
+func (r *Repository) DescribeConversationWith(ctx context.Context, id string, opts DescribeOptions) (*Conversation, error) {
 	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
 	ctx, span := tracer.Start(ctx, "Repository.DescribeConversation")
-	span.SetAttributes(attribute.String("conversation.id", id))
+	span.SetAttributes(
+		attribute.String("conversation.id", id),
+		attribute.Bool("describe.without_messages", opts.WithoutMessages),
+		attribute.Int("describe.last_messages", opts.LastMessages),
+	)
 	defer span.End()
 
 	var c Conversation
@@ -63,7 +119,16 @@ func (r *Repository) DescribeConversation(ctx context.Context, id string) (*Conv
 		return nil, twirp.NotFoundError("invalid conversation ID")
 	}
 
-	err = r.conn.Collection(conversationCollection).FindOne(ctx, map[string]any{"_id": oid}).Decode(&c)
+	// Projected for conversations still embedding their messages
+	find := options.FindOne()
+	switch {
+	case opts.WithoutMessages:
+		find.SetProjection(bson.M{"messages": 0})
+	case opts.LastMessages > 0:
+		find.SetProjection(bson.M{"messages": bson.M{"$slice": -opts.LastMessages}})
+	}
+
+	err = r.conn.Collection(conversationCollection).FindOne(ctx, map[string]any{"_id": oid}, find).Decode(&c)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		span.SetStatus(codes.Error, "conversation not found")
 		return nil, twirp.NotFoundError("conversation not found")
@@ -75,21 +140,62 @@ func (r *Repository) DescribeConversation(ctx context.Context, id string) (*Conv
 		return nil, err
 	}
 
+	switch {
+	case opts.WithoutMessages:
+	case opts.LastMessages > 0:
+		err = r.loadLastMessages(ctx, &c, opts.LastMessages)
+	default:
+		err = r.loadMessages(ctx, r.conn, &c)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read messages")
+		return nil, err
+	}
+	c.view = opts.view()
+
 	span.SetAttributes(attribute.Int("conversation.message_count", len(c.Messages)))
 	span.SetStatus(codes.Ok, "conversation found")
 	return &c, nil
 }
 
-func (r *Repository) ListConversations(ctx context.Context) ([]*Conversation, error) {
+// ListConversations returns the conversations matching filter, without their messages.
+func (r *Repository) ListConversations(ctx context.Context, filter ListFilter) ([]*Conversation, error) {
 	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
 	ctx, span := tracer.Start(ctx, "Repository.ListConversations")
+	span.SetAttributes(
+		attribute.String("filter.tag", filter.Tag),
+		attribute.String("filter.folder", filter.Folder),
+		attribute.Bool("filter.archived", filter.Archived),
+		attribute.Bool("filter.favorites", filter.Favorites),
+	)
 	defer span.End()
 
+	// Pinned conversations come first, last pinned first, as unpinned ones have no pinned_at
 	opts := options.Find().
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
+		SetSort(bson.D{{Key: "pinned_at", Value: -1}, {Key: "created_at", Value: -1}})
+
+	query := map[string]any{"user_id": nil}
+	if filter.UserID != "" {
+		query["user_id"] = filter.UserID
+	}
+	if filter.Tag != "" {
+		query["tags"] = filter.Tag
+	}
+	if filter.Folder != "" {
+		query["folder"] = filter.Folder
+	}
+	if filter.Favorites {
+		query["favorite"] = true
+	}
+	if filter.Archived {
+		query["archived_at"] = map[string]any{"$ne": nil}
+	} else {
+		query["archived_at"] = nil
+	}
 
-	cursor, err := r.conn.Collection(conversationCollection).
-		Find(ctx, map[string]any{}, opts)
+	cursor, err := r.replica.Collection(conversationCollection).
+		Find(ctx, query, opts)
 
 	if err != nil {
 		span.RecordError(err)
@@ -135,9 +241,28 @@ func (r *Repository) UpdateConversation(ctx context.Context, c *Conversation) er
 	)
 	defer span.End()
 
+	if c.view != "" {
+		span.SetStatus(codes.Error, "partial conversation")
+		return ErrPartialConversation
+	}
+
+	if err := r.writeMessages(ctx, c); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to write messages")
+		return err
+	}
+
+	// The revision is incremented by the update, rather than set from c. Messages still
+	// embedded in the document are dropped, as they were just written apart.
+	doc := c.document()
+	doc.Revision = 0
+	unset := map[string]any{"messages": ""}
+	if c.Queued == nil {
+		unset["queued"] = ""
+	}
+	update := map[string]any{"$set": doc, "$unset": unset, "$inc": map[string]any{"revision": 1}}
 	_, err := r.conn.Collection(conversationCollection).UpdateOne(ctx,
-		map[string]any{"_id": c.ID},
-		map[string]any{"$set": c})
+		map[string]any{"_id": c.ID}, update)
 
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		span.SetStatus(codes.Error, "conversation not found")
@@ -150,15 +275,292 @@ func (r *Repository) UpdateConversation(ctx context.Context, c *Conversation) er
 		return err
 	}
 
+	c.Revision++
 	span.SetStatus(codes.Ok, "conversation updated")
 	return nil
 }
 
+// UpdateLabels replaces the tags and folder of a conversation. Unlike UpdateConversation
+// it also clears them when empty.
+func (r *Repository) UpdateLabels(ctx context.Context, id primitive.ObjectID, tags []string, folder string) error {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.UpdateLabels")
+	span.SetAttributes(
+		attribute.String("conversation.id", id.Hex()),
+		attribute.StringSlice("conversation.tags", tags),
+		attribute.String("conversation.folder", folder),
+	)
+	defer span.End()
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	res, err := r.conn.Collection(conversationCollection).UpdateOne(ctx,
+		map[string]any{"_id": id},
+		map[string]any{"$set": map[string]any{"tags": tags, "folder": folder}, "$inc": map[string]any{"revision": 1}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update labels")
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		span.SetStatus(codes.Error, "conversation not found")
+		return twirp.NotFoundError("conversation not found")
+	}
+
+	span.SetStatus(codes.Ok, "labels updated")
+	return nil
+}
+
+// SetPinned pins the conversation at pinnedAt, or unpins it when pinnedAt is nil.
+func (r *Repository) SetPinned(ctx context.Context, id primitive.ObjectID, pinnedAt *time.Time) error {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.SetPinned")
+	span.SetAttributes(
+		attribute.String("conversation.id", id.Hex()),
+		attribute.Bool("conversation.pinned", pinnedAt != nil),
+	)
+	defer span.End()
+
+	update := map[string]any{"$unset": map[string]any{"pinned_at": ""}, "$inc": map[string]any{"revision": 1}}
+	if pinnedAt != nil {
+		update = map[string]any{"$set": map[string]any{"pinned_at": *pinnedAt}, "$inc": map[string]any{"revision": 1}}
+	}
+	return r.updateFlags(ctx, span, id, update)
+}
+
+// SetFavorite marks the conversation as a favorite, or unmarks it.
+func (r *Repository) SetFavorite(ctx context.Context, id primitive.ObjectID, favorite bool) error {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.SetFavorite")
+	span.SetAttributes(
+		attribute.String("conversation.id", id.Hex()),
+		attribute.Bool("conversation.favorite", favorite),
+	)
+	defer span.End()
+
+	update := map[string]any{"$unset": map[string]any{"favorite": ""}, "$inc": map[string]any{"revision": 1}}
+	if favorite {
+		update = map[string]any{"$set": map[string]any{"favorite": true}, "$inc": map[string]any{"revision": 1}}
+	}
+	return r.updateFlags(ctx, span, id, update)
+}
+
+// updateFlags applies update to the conversation, recording the outcome on span.
+func (r *Repository) updateFlags(ctx context.Context, span trace.Span, id primitive.ObjectID, update map[string]any) error {
+	res, err := r.conn.Collection(conversationCollection).UpdateOne(ctx, map[string]any{"_id": id}, update)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update flags")
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		span.SetStatus(codes.Error, "conversation not found")
+		return twirp.NotFoundError("conversation not found")
+	}
+
+	span.SetStatus(codes.Ok, "flags updated")
+	return nil
+}
+
 func (r *Repository) DeleteConversation(ctx context.Context, id string) error {
-	_, err := r.conn.Collection(conversationCollection).DeleteOne(ctx, map[string]any{"_id": id})
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return twirp.NotFoundError("invalid conversation ID")
+	}
+
+	_, err = r.conn.Collection(conversationCollection).DeleteOne(ctx, map[string]any{"_id": oid})
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return twirp.NotFoundError("conversation not found")
 	}
+	if err != nil {
+		return err
+	}
+
+	return r.deleteMessages(ctx, oid)
+}
+
+// ownedBy matches a conversation of a user, or a conversation without owner when
+// userID is empty.
+func ownedBy(userID string, id primitive.ObjectID) bson.M {
+	if userID == "" {
+		return bson.M{"_id": id, "user_id": nil}
+	}
+	return bson.M{"_id": id, "user_id": userID}
+}
+
+// ListOwned returns the given conversations of a user, skipping those that don't exist
+// or belong to someone else.
+func (r *Repository) ListOwned(ctx context.Context, userID string, ids []primitive.ObjectID) ([]*Conversation, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.ListOwned")
+	span.SetAttributes(attribute.Int("conversations.requested", len(ids)))
+	defer span.End()
+
+	filter := bson.M{"_id": bson.M{"$in": ids}, "user_id": userID}
+	if userID == "" {
+		filter["user_id"] = nil
+	}
+
+	cursor, err := r.conn.Collection(conversationCollection).Find(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query conversations")
+		return nil, err
+	}
+
+	var items []*Conversation
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode conversations")
+		return nil, err
+	}
+
+	if err := r.loadMessages(ctx, r.conn, items...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read messages")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("conversations.count", len(items)))
+	span.SetStatus(codes.Ok, "conversations listed")
+	return items, nil
+}
+
+// ListUserConversations returns all the conversations of a user, archived ones included,
+// with their messages, oldest first.
+func (r *Repository) ListUserConversations(ctx context.Context, userID string) ([]*Conversation, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.ListUserConversations")
+	span.SetAttributes(attribute.String("user.id", userID))
+	defer span.End()
+
+	cursor, err := r.conn.Collection(conversationCollection).Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query conversations")
+		return nil, err
+	}
+
+	var items []*Conversation
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode conversations")
+		return nil, err
+	}
+
+	if err := r.loadMessages(ctx, r.conn, items...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read messages")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("conversations.count", len(items)))
+	span.SetStatus(codes.Ok, "conversations listed")
+	return items, nil
+}
+
+// BatchDelete deletes the given conversations of a user in a single bulk write.
+// Conversations that don't exist or belong to someone else are skipped.
+func (r *Repository) BatchDelete(ctx context.Context, userID string, ids []primitive.ObjectID) (int64, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.BatchDelete")
+	span.SetAttributes(attribute.Int("conversations.requested", len(ids)))
+	defer span.End()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(ids))
+	for _, id := range ids {
+		models = append(models, mongo.NewDeleteOneModel().SetFilter(ownedBy(userID, id)))
+	}
+
+	res, err := r.conn.Collection(conversationCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete conversations")
+		return 0, err
+	}
+
+	// The messages of the conversations deleted, rather than skipped, go with them
+	var left []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	cursor, err := r.conn.Collection(conversationCollection).Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err == nil {
+		err = cursor.All(ctx, &left)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query conversations left")
+		return res.DeletedCount, err
+	}
+	kept := make(map[primitive.ObjectID]bool, len(left))
+	for _, l := range left {
+		kept[l.ID] = true
+	}
+	deleted := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if !kept[id] {
+			deleted = append(deleted, id)
+		}
+	}
+	if err := r.deleteMessages(ctx, deleted...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete messages")
+		return res.DeletedCount, err
+	}
+
+	span.SetAttributes(attribute.Int64("conversations.deleted", res.DeletedCount))
+	span.SetStatus(codes.Ok, "conversations deleted")
+	return res.DeletedCount, nil
+}
+
+// BatchArchive archives the given conversations of a user in a single bulk write, or
+// moves them out of the archive when archived is false. Conversations that don't exist,
+// belong to someone else or are already in the requested state are skipped.
+func (r *Repository) BatchArchive(ctx context.Context, userID string, ids []primitive.ObjectID, archived bool) (int64, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.BatchArchive")
+	span.SetAttributes(
+		attribute.Int("conversations.requested", len(ids)),
+		attribute.Bool("conversations.archived", archived),
+	)
+	defer span.End()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	update := bson.M{"$unset": bson.M{"archived_at": "", "auto_archived": ""}, "$inc": bson.M{"revision": 1}}
+	state := bson.M{"$ne": nil}
+	if archived {
+		update = bson.M{"$set": bson.M{"archived_at": time.Now()}, "$inc": bson.M{"revision": 1}}
+		state = nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(ids))
+	for _, id := range ids {
+		filter := ownedBy(userID, id)
+		filter["archived_at"] = state
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update))
+	}
+
+	res, err := r.conn.Collection(conversationCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to archive conversations")
+		return 0, err
+	}
 
-	return err
+	span.SetAttributes(attribute.Int64("conversations.updated", res.ModifiedCount))
+	span.SetStatus(codes.Ok, "conversations archived")
+	return res.ModifiedCount, nil
 }
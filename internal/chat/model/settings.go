@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+)
+
+// Verbosity levels of replies.
+const (
+	VerbosityBrief    = "brief"
+	VerbosityNormal   = "normal"
+	VerbosityDetailed = "detailed"
+)
+
+// Creativity levels of replies.
+const (
+	CreativityPrecise  = "precise"
+	CreativityBalanced = "balanced"
+	CreativityCreative = "creative"
+)
+
+// Formats of replies.
+const (
+	FormatPlain    = "plain"
+	FormatMarkdown = "markdown"
+	FormatBullets  = "bullets"
+)
+
+// Bounds of the maximum length of replies, in characters.
+const (
+	MinMaxLength = 80
+	MaxMaxLength = 4000
+)
+
+var (
+	verbosities  = []string{VerbosityBrief, VerbosityNormal, VerbosityDetailed}
+	creativities = []string{CreativityPrecise, CreativityBalanced, CreativityCreative}
+	formats      = []string{FormatPlain, FormatMarkdown, FormatBullets}
+)
+
+// Settings tune how the replies of a conversation are generated. Empty fields keep the
+// assistant's defaults.
+type Settings struct {
+	// Verbosity is brief, normal or detailed.
+	Verbosity string `bson:"verbosity,omitempty"`
+	// Creativity is precise, balanced or creative. It only applies to models that
+	// support a sampling temperature.
+	Creativity string `bson:"creativity,omitempty"`
+	// Format is plain, markdown or bullets, a bullet summary.
+	Format string `bson:"format,omitempty"`
+	// MaxLength is the maximum length of replies, in characters.
+	MaxLength int `bson:"max_length,omitempty"`
+}
+
+// SettingsFromProto converts generation settings of a request.
+func SettingsFromProto(p *pb.GenerationSettings) Settings {
+	return Settings{
+		Verbosity:  p.GetVerbosity(),
+		Creativity: p.GetCreativity(),
+		Format:     p.GetFormat(),
+		MaxLength:  int(p.GetMaxLength()),
+	}
+}
+
+// IsZero reports whether all settings keep their defaults. It also lets the bson
+// encoder omit empty settings.
+func (s Settings) IsZero() bool {
+	return s == Settings{}
+}
+
+// Validate checks the settings' levels.
+func (s Settings) Validate() error {
+	if s.Verbosity != "" && !slices.Contains(verbosities, s.Verbosity) {
+		return fmt.Errorf("verbosity must be one of %v", verbosities)
+	}
+	if s.Creativity != "" && !slices.Contains(creativities, s.Creativity) {
+		return fmt.Errorf("creativity must be one of %v", creativities)
+	}
+	if s.Format != "" && !slices.Contains(formats, s.Format) {
+		return fmt.Errorf("format must be one of %v", formats)
+	}
+	if s.MaxLength != 0 && (s.MaxLength < MinMaxLength || s.MaxLength > MaxMaxLength) {
+		return fmt.Errorf("max_length must be between %d and %d", MinMaxLength, MaxMaxLength)
+	}
+	return nil
+}
+
+// Merge overrides the settings with the ones set in o.
+func (s *Settings) Merge(o Settings) {
+	if o.Verbosity != "" {
+		s.Verbosity = o.Verbosity
+	}
+	if o.Creativity != "" {
+		s.Creativity = o.Creativity
+	}
+	if o.Format != "" {
+		s.Format = o.Format
+	}
+	if o.MaxLength != 0 {
+		s.MaxLength = o.MaxLength
+	}
+}
+
+func (s Settings) Proto() *pb.GenerationSettings {
+	if s.IsZero() {
+		return nil
+	}
+	return &pb.GenerationSettings{
+		Verbosity:  s.Verbosity,
+		Creativity: s.Creativity,
+		Format:     s.Format,
+		MaxLength:  int32(s.MaxLength),
+	}
+}
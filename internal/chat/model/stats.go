@@ -0,0 +1,145 @@
+package model
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ConversationStats summarizes the activity of a conversation.
+type ConversationStats struct {
+	ID                    primitive.ObjectID `bson:"_id"`
+	Title                 string             `bson:"subject"`
+	MessageCount          int                `bson:"message_count"`
+	UserMessageCount      int                `bson:"user_message_count"`
+	AssistantMessageCount int                `bson:"assistant_message_count"`
+	ToolUsage             []ToolUsage        `bson:"tool_usage"`
+	InputTokens           int64              `bson:"input_tokens"`
+	OutputTokens          int64              `bson:"output_tokens"`
+	FirstActivityAt       time.Time          `bson:"first_activity_at"`
+	LastActivityAt        time.Time          `bson:"last_activity_at"`
+	DurationMillis        int64              `bson:"duration_ms"`
+}
+
+// ToolUsage counts the calls of a tool.
+type ToolUsage struct {
+	Name  string `bson:"name"`
+	Count int    `bson:"count"`
+}
+
+func (s *ConversationStats) Proto() *pb.ConversationStats {
+	proto := &pb.ConversationStats{
+		ConversationId:        s.ID.Hex(),
+		Title:                 s.Title,
+		MessageCount:          int32(s.MessageCount),
+		UserMessageCount:      int32(s.UserMessageCount),
+		AssistantMessageCount: int32(s.AssistantMessageCount),
+		InputTokens:           s.InputTokens,
+		OutputTokens:          s.OutputTokens,
+		DurationSeconds:       s.DurationMillis / 1000,
+	}
+	if !s.FirstActivityAt.IsZero() {
+		proto.FirstActivityAt = timestamppb.New(s.FirstActivityAt)
+		proto.LastActivityAt = timestamppb.New(s.LastActivityAt)
+	}
+
+	for _, t := range s.ToolUsage {
+		proto.ToolUsage = append(proto.ToolUsage, &pb.ConversationStats_ToolUsage{Name: t.Name, Count: int32(t.Count)})
+	}
+
+	return proto
+}
+
+// statsPipeline computes ConversationStats from the messages of each conversation.
+var statsPipeline = []bson.M{
+	{"$project": bson.M{
+		"subject":                 1,
+		"message_count":           bson.M{"$size": bson.M{"$ifNull": bson.A{"$messages", bson.A{}}}},
+		"user_message_count":      countMessages(RoleUser),
+		"assistant_message_count": countMessages(RoleAssistant),
+		"input_tokens":            bson.M{"$sum": "$messages.usage.input_tokens"},
+		"output_tokens":           bson.M{"$sum": "$messages.usage.output_tokens"},
+		"first_activity_at":       bson.M{"$min": "$messages.created_at"},
+		"last_activity_at":        bson.M{"$max": "$messages.created_at"},
+		// all tool calls of the conversation, flattened
+		"tool_calls": bson.M{"$reduce": bson.M{
+			"input":        "$messages.tool_calls",
+			"initialValue": bson.A{},
+			"in":           bson.M{"$concatArrays": bson.A{"$$value", bson.M{"$ifNull": bson.A{"$$this", bson.A{}}}}},
+		}},
+	}},
+	{"$addFields": bson.M{
+		"duration_ms": bson.M{"$subtract": bson.A{"$last_activity_at", "$first_activity_at"}},
+		"tool_usage": bson.M{"$map": bson.M{
+			"input": bson.M{"$setUnion": bson.A{"$tool_calls"}},
+			"as":    "tool",
+			"in": bson.M{
+				"name":  "$$tool",
+				"count": bson.M{"$size": bson.M{"$filter": bson.M{"input": "$tool_calls", "cond": bson.M{"$eq": bson.A{"$$this", "$$tool"}}}}},
+			},
+		}},
+	}},
+	{"$project": bson.M{"tool_calls": 0}},
+	{"$sort": bson.M{"last_activity_at": -1}},
+}
+
+func countMessages(role Role) bson.M {
+	return bson.M{"$size": bson.M{"$filter": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$messages", bson.A{}}},
+		"cond":  bson.M{"$eq": bson.A{"$$this.role", role}},
+	}}}
+}
+
+// ConversationStats computes the statistics of a user's conversations, or of the given
+// ones when ids is not empty, most recently active first.
+func (r *Repository) ConversationStats(ctx context.Context, userID string, ids []primitive.ObjectID) ([]*ConversationStats, error) {
+	tracer := otel.Tracer("github.com/acai-travel/tech-challenge/internal/chat/model")
+	ctx, span := tracer.Start(ctx, "Repository.ConversationStats")
+	span.SetAttributes(attribute.Int("conversations.requested", len(ids)))
+	defer span.End()
+
+	match := bson.M{"user_id": userID}
+	if userID == "" {
+		match["user_id"] = nil
+	}
+	if len(ids) > 0 {
+		match["_id"] = bson.M{"$in": ids}
+	}
+
+	pipeline := append([]bson.M{{"$match": match}}, LookupMessages("role", "usage", "tool_calls", "created_at")...)
+	pipeline = append(pipeline, statsPipeline...)
+	cursor, err := r.replica.Collection(conversationCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to aggregate conversation stats")
+		return nil, err
+	}
+
+	var stats []*ConversationStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode conversation stats")
+		return nil, err
+	}
+
+	for _, s := range stats {
+		sort.Slice(s.ToolUsage, func(i, j int) bool {
+			if s.ToolUsage[i].Count != s.ToolUsage[j].Count {
+				return s.ToolUsage[i].Count > s.ToolUsage[j].Count
+			}
+			return s.ToolUsage[i].Name < s.ToolUsage[j].Name
+		})
+	}
+
+	span.SetAttributes(attribute.Int("conversations.count", len(stats)))
+	span.SetStatus(codes.Ok, "conversation stats computed")
+	return stats, nil
+}
@@ -0,0 +1,27 @@
+package chat
+
+import (
+	"context"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/twitchtv/twirp"
+)
+
+// ownedConversation reads a conversation of the calling user. Only its owner can act on
+// a conversation, so others' conversations are hidden entirely, as not found.
+func (s *Server) ownedConversation(ctx context.Context, id string) (*model.Conversation, error) {
+	return s.ownedConversationWith(ctx, id, model.DescribeOptions{})
+}
+
+// ownedConversationWith is ownedConversation reading the messages opts select.
+func (s *Server) ownedConversationWith(ctx context.Context, id string, opts model.DescribeOptions) (*model.Conversation, error) {
+	conversation, err := s.repo.DescribeConversationWith(ctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.UserID != auth.UserID(ctx) {
+		return nil, twirp.NotFoundError("conversation not found")
+	}
+	return conversation, nil
+}
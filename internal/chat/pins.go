@@ -0,0 +1,63 @@
+package chat
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+)
+
+func (s *Server) PinConversation(ctx context.Context, req *pb.PinConversationRequest) (*pb.PinConversationResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+
+	conversation, err := s.ownedConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	var pinnedAt *time.Time
+	action := audit.ConversationUnpin
+	if !req.GetUnpin() {
+		now := time.Now()
+		pinnedAt, action = &now, audit.ConversationPin
+	}
+	if err := s.repo.SetPinned(ctx, conversation.ID, pinnedAt); err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, action, conversation.ID.Hex(), nil, nil)
+
+	conversation.PinnedAt = pinnedAt
+	conversation.Revision++
+
+	return &pb.PinConversationResponse{Conversation: conversation.Proto()}, nil
+}
+
+func (s *Server) FavoriteConversation(ctx context.Context, req *pb.FavoriteConversationRequest) (*pb.FavoriteConversationResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+
+	conversation, err := s.ownedConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	favorite := !req.GetUnfavorite()
+	if err := s.repo.SetFavorite(ctx, conversation.ID, favorite); err != nil {
+		return nil, err
+	}
+	action := audit.ConversationFavorite
+	if !favorite {
+		action = audit.ConversationUnfavorite
+	}
+	s.recordAudit(ctx, action, conversation.ID.Hex(), nil, nil)
+
+	conversation.Favorite = favorite
+	conversation.Revision++
+
+	return &pb.FavoriteConversationResponse{Conversation: conversation.Proto()}, nil
+}
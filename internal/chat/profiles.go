@@ -0,0 +1,107 @@
+package chat
+
+import (
+	"context"
+	"net/mail"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/notify"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/whatsapp"
+	"github.com/twitchtv/twirp"
+)
+
+func (s *Server) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.GetProfileResponse, error) {
+	userID, err := s.profileUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.profiles.DescribeProfile(ctx, userID)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &pb.GetProfileResponse{Profile: p.Proto()}, nil
+}
+
+func (s *Server) UpdateProfile(ctx context.Context, req *pb.UpdateProfileRequest) (*pb.UpdateProfileResponse, error) {
+	userID, err := s.profileUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	whatsAppNumber, err := validateProfile(req.GetEmail(), req.GetSlackWebhookUrl(), req.GetWhatsappNumber(), req.GetChannels())
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.profiles.DescribeProfile(ctx, userID)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	before := *p
+	p.Email = req.GetEmail()
+	p.SlackWebhookURL = req.GetSlackWebhookUrl()
+	p.WhatsAppNumber = whatsAppNumber
+	p.Channels = req.GetChannels()
+	p.UpdatedAt = time.Now()
+
+	if err := s.profiles.UpdateProfile(ctx, p); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	s.recordAudit(ctx, audit.ProfileUpdate, userID, &before, p)
+
+	return &pb.UpdateProfileResponse{Profile: p.Proto()}, nil
+}
+
+// validateProfile checks the notification settings of a profile, returning the WhatsApp
+// number normalized.
+func validateProfile(email, slackWebhookURL, whatsAppNumber string, channels []string) (string, error) {
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return "", twirp.InvalidArgumentError("email", "must be a valid email address")
+		}
+	}
+
+	if slackWebhookURL != "" {
+		if u, err := url.Parse(slackWebhookURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return "", twirp.InvalidArgumentError("slack_webhook_url", "must be an absolute https URL")
+		}
+	}
+
+	if whatsAppNumber != "" {
+		waID, ok := whatsapp.NormalizeNumber(whatsAppNumber)
+		if !ok {
+			return "", twirp.InvalidArgumentError("whatsapp_number", "must be a phone number in international format, like +34600123456")
+		}
+		whatsAppNumber = "+" + waID
+	}
+
+	for _, c := range channels {
+		if !slices.Contains([]string{notify.ChannelEmail, notify.ChannelSlack, notify.ChannelWhatsApp}, c) {
+			return "", twirp.InvalidArgumentError("channels", "unknown channel "+c)
+		}
+	}
+
+	return whatsAppNumber, nil
+}
+
+// profileUser checks profiles are enabled and returns the calling user's ID.
+func (s *Server) profileUser(ctx context.Context) (string, error) {
+	if s.profiles == nil {
+		return "", twirp.NewError(twirp.Unimplemented, "profiles are not enabled")
+	}
+
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return "", twirp.NewError(twirp.Unauthenticated, "user ID is required")
+	}
+
+	return userID, nil
+}
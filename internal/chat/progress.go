@@ -0,0 +1,33 @@
+package chat
+
+import (
+	"context"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
+	"github.com/acai-travel/tech-challenge/internal/progress"
+)
+
+// trackProgress relays the steps of the reply to a request with an attempt ID to the
+// clients following it. The returned finish ends their stream, with whether the reply
+// succeeded.
+func (s *Server) trackProgress(ctx context.Context, attemptID string) (context.Context, func(ok bool)) {
+	attemptID = strings.TrimSpace(attemptID)
+	if s.progress == nil || attemptID == "" {
+		return ctx, func(bool) {}
+	}
+
+	userID := auth.UserID(ctx)
+	ctx = assistant.WithProgress(ctx, func(tool, message string) {
+		s.progress.Publish(userID, attemptID, progress.Event{Type: progress.EventStep, Tool: tool, Message: message})
+	})
+
+	return ctx, func(ok bool) {
+		e := progress.Event{Type: progress.EventDone}
+		if !ok {
+			e.Type = progress.EventFailed
+		}
+		s.progress.Publish(userID, attemptID, e)
+	}
+}
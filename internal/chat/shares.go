@@ -0,0 +1,91 @@
+package chat
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/share"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultShareTTL = 7 * 24 * time.Hour
+	maxShareTTL     = 30 * 24 * time.Hour
+)
+
+func (s *Server) ShareConversation(ctx context.Context, req *pb.ShareConversationRequest) (*pb.ShareConversationResponse, error) {
+	userID, err := s.shareUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+
+	ttl := time.Duration(req.GetTtlSeconds()) * time.Second
+	if ttl < 0 || ttl > maxShareTTL {
+		return nil, twirp.InvalidArgumentError("ttl_seconds", "must be between 0 and 30 days")
+	}
+	if ttl == 0 {
+		ttl = defaultShareTTL
+	}
+
+	conversation, err := s.ownedConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sh := &share.Share{
+		ID:             primitive.NewObjectID(),
+		ConversationID: conversation.ID,
+		UserID:         userID,
+		ExpiresAt:      now.Add(ttl).Truncate(time.Second),
+		CreatedAt:      now,
+	}
+
+	if err := s.shares.CreateShare(ctx, sh); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	s.recordAudit(ctx, audit.ShareCreate, sh.ID.Hex(), nil, sh)
+
+	token := s.shareSigner.Sign(sh.ID, sh.ExpiresAt)
+	return &pb.ShareConversationResponse{Share: sh.Proto(token, s.shareBaseURL+"/shared/"+token)}, nil
+}
+
+func (s *Server) RevokeShare(ctx context.Context, req *pb.RevokeShareRequest) (*pb.RevokeShareResponse, error) {
+	userID, err := s.shareUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetShareId() == "" {
+		return nil, twirp.RequiredArgumentError("share_id")
+	}
+
+	if err := s.shares.RevokeShare(ctx, userID, req.GetShareId()); err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, audit.ShareRevoke, req.GetShareId(), nil, nil)
+
+	return &pb.RevokeShareResponse{}, nil
+}
+
+// shareUser checks sharing is enabled and returns the calling user's ID.
+func (s *Server) shareUser(ctx context.Context) (string, error) {
+	if s.shares == nil {
+		return "", twirp.NewError(twirp.Unimplemented, "sharing is not enabled")
+	}
+
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return "", twirp.NewError(twirp.Unauthenticated, "user ID is required")
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,32 @@
+package chat
+
+import (
+	"context"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *Server) GetConversationStats(ctx context.Context, req *pb.GetConversationStatsRequest) (*pb.GetConversationStatsResponse, error) {
+	var ids []primitive.ObjectID
+	if len(req.GetConversationIds()) > 0 {
+		var err error
+		if ids, err = batchIDs(req.GetConversationIds()); err != nil {
+			return nil, err
+		}
+	}
+
+	stats, err := s.repo.ConversationStats(ctx, auth.UserID(ctx), ids)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	resp := &pb.GetConversationStatsResponse{}
+	for _, st := range stats {
+		resp.Conversations = append(resp.Conversations, st.Proto())
+	}
+
+	return resp, nil
+}
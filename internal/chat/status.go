@@ -0,0 +1,66 @@
+package chat
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+// retryFailed prepares a conversation whose last reply failed for the next request: the
+// failed reply is dropped and the messages it failed to answer wait for a reply again,
+// along with the new ones. It returns the retried messages and the contents of the new
+// messages to add, none when the request resends the failed messages as they were.
+func retryFailed(conv *model.Conversation, contents []string, withAttachments bool) ([]*model.Message, []string) {
+	n := len(conv.Messages)
+	if n == 0 || conv.Messages[n-1].Role != model.RoleAssistant || conv.Messages[n-1].Status != model.MessageStatusFailed {
+		return nil, contents
+	}
+	conv.Messages = conv.Messages[:n-1]
+
+	i := len(conv.Messages)
+	for i > 0 && conv.Messages[i-1].Role == model.RoleUser && conv.Messages[i-1].Status == model.MessageStatusFailed {
+		i--
+	}
+	retried := conv.Messages[i:]
+	setStatus(retried, model.MessageStatusPending)
+
+	resent := make([]string, 0, len(retried))
+	for _, m := range retried {
+		resent = append(resent, m.Content)
+	}
+	if !withAttachments && slices.Equal(resent, contents) {
+		return retried, nil
+	}
+	return retried, contents
+}
+
+// storeInFlight stores the conversation with the placeholder of the reply being
+// generated, which is left out of the conversation given to the assistant.
+func (s *Server) storeInFlight(ctx context.Context, conv *model.Conversation, placeholder *model.Message) error {
+	conv.Messages = append(conv.Messages, placeholder)
+	defer func() { conv.Messages = conv.Messages[:len(conv.Messages)-1] }()
+	return s.repo.UpdateConversation(ctx, conv)
+}
+
+// failReply records that the reply to the waiting messages failed, so clients can offer
+// to retry them. It is stored even if the request was cancelled.
+func (s *Server) failReply(ctx context.Context, conv *model.Conversation, waiting []*model.Message, placeholder *model.Message) {
+	placeholder.Status = model.MessageStatusFailed
+	placeholder.UpdatedAt = time.Now()
+	conv.Messages = append(conv.Messages, placeholder)
+	setStatus(waiting, model.MessageStatusFailed)
+
+	if err := s.repo.UpdateConversation(context.WithoutCancel(ctx), conv); err != nil {
+		slog.ErrorContext(ctx, "Failed to record a failed reply", "error", err)
+	}
+}
+
+func setStatus(messages []*model.Message, status string) {
+	for _, m := range messages {
+		m.Status = status
+		m.UpdatedAt = time.Now()
+	}
+}
@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// syncOverlap is how long before the last sync messages are sent again, in case the
+// clocks of the servers that updated them drift. Clients replace messages by ID.
+const syncOverlap = time.Minute
+
+// SyncConversation returns what changed in a conversation since a previous sync: its
+// fields, and the messages added or updated since then, rather than the whole history.
+func (s *Server) SyncConversation(ctx context.Context, req *pb.SyncConversationRequest) (*pb.SyncConversationResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+	ctx = logging.WithConversationID(ctx, req.GetConversationId())
+
+	conversation, err := s.ownedConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	if req.GetRevision() > 0 && req.GetSyncedAt().IsValid() {
+		since = req.GetSyncedAt().AsTime()
+	}
+	return syncConversation(conversation, req.GetRevision(), since), nil
+}
+
+// syncConversation returns the changes of conv for a client at revision, which got the
+// messages updated until since; all of them when since is zero.
+func syncConversation(conv *model.Conversation, revision int64, since time.Time) *pb.SyncConversationResponse {
+	if revision > 0 && revision == conv.Revision {
+		return &pb.SyncConversationResponse{Unchanged: true, Revision: conv.Revision}
+	}
+
+	resp := &pb.SyncConversationResponse{Revision: conv.Revision}
+	syncedAt := since
+	for _, m := range conv.Messages {
+		resp.MessageIds = append(resp.MessageIds, m.ID.Hex())
+		if since.IsZero() || m.UpdatedAt.After(since.Add(-syncOverlap)) {
+			resp.Messages = append(resp.Messages, m.Proto())
+		}
+		if m.UpdatedAt.After(syncedAt) {
+			syncedAt = m.UpdatedAt
+		}
+	}
+	if !syncedAt.IsZero() {
+		resp.SyncedAt = timestamppb.New(syncedAt)
+	}
+
+	fields := *conv
+	fields.Messages = nil
+	resp.Conversation = fields.Proto()
+	return resp
+}
@@ -0,0 +1,165 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/takeout"
+	"github.com/twitchtv/twirp"
+)
+
+func (s *Server) ExportUserData(ctx context.Context, req *pb.ExportUserDataRequest) (*pb.ExportUserDataResponse, error) {
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return nil, twirp.NewError(twirp.Unauthenticated, "user ID is required")
+	}
+
+	now := time.Now()
+	b := &takeout.Bundle{Manifest: takeout.Manifest{Version: takeout.Version, UserID: userID, ExportedAt: now}}
+
+	conversations, err := s.repo.ListUserConversations(ctx, userID)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	b.Conversations = conversations
+
+	if s.profiles != nil {
+		p, err := s.profiles.DescribeProfile(ctx, userID)
+		if err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+		// Users without a stored profile get an empty one, which isn't theirs to restore
+		if !p.UpdatedAt.IsZero() {
+			b.Profile = p
+		}
+	}
+
+	if s.itineraryArchive != nil {
+		if b.Itineraries, err = s.itineraryArchive.ListItineraries(ctx, userID); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+	}
+	if s.reminderArchive != nil {
+		if b.Reminders, err = s.reminderArchive.ListReminders(ctx, userID); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := takeout.Write(&buf, b); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &pb.ExportUserDataResponse{
+		Filename:    takeout.Filename(now),
+		ContentType: takeout.ContentType,
+		Content:     buf.Bytes(),
+	}, nil
+}
+
+func (s *Server) ImportUserData(ctx context.Context, req *pb.ImportUserDataRequest) (*pb.ImportUserDataResponse, error) {
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return nil, twirp.NewError(twirp.Unauthenticated, "user ID is required")
+	}
+	if len(req.GetContent()) == 0 {
+		return nil, twirp.RequiredArgumentError("content")
+	}
+
+	b, err := takeout.Read(req.GetContent())
+	if err != nil {
+		return nil, twirp.InvalidArgumentError("content", err.Error())
+	}
+	// Bundles can be edited, so they're held to what the API accepts before anything is
+	// restored
+	if err := validateTakeout(b); err != nil {
+		return nil, err
+	}
+	b.Assign(userID, time.Now())
+
+	// Restored one at a time: an import failing halfway keeps what it restored
+	resp := &pb.ImportUserDataResponse{}
+	for _, c := range b.Conversations {
+		if err := s.repo.CreateConversation(ctx, c); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+		resp.Conversations++
+	}
+
+	if b.Profile != nil && s.profiles != nil {
+		b.Profile.UpdatedAt = time.Now()
+		if err := s.profiles.UpdateProfile(ctx, b.Profile); err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+		resp.Profile = true
+	}
+
+	if s.itineraryArchive != nil {
+		for _, it := range b.Itineraries {
+			if err := s.itineraryArchive.CreateItinerary(ctx, it); err != nil {
+				return nil, twirp.InternalErrorWith(err)
+			}
+			resp.Itineraries++
+		}
+	}
+	if s.reminderArchive != nil {
+		for _, rem := range b.Reminders {
+			if err := s.reminderArchive.CreateReminder(ctx, rem); err != nil {
+				return nil, twirp.InternalErrorWith(err)
+			}
+			resp.Reminders++
+		}
+	}
+
+	s.recordAudit(ctx, audit.UserDataImport, userID, nil, map[string]any{
+		"exported_by":   b.Manifest.UserID,
+		"exported_at":   b.Manifest.ExportedAt,
+		"conversations": resp.Conversations,
+		"itineraries":   resp.Itineraries,
+		"reminders":     resp.Reminders,
+		"profile":       resp.Profile,
+	})
+
+	return resp, nil
+}
+
+// validateTakeout checks the conversations, profile and itineraries of a bundle like
+// the RPCs setting them do.
+func validateTakeout(b *takeout.Bundle) error {
+	for i, c := range b.Conversations {
+		if err := c.Settings.Validate(); err != nil {
+			return twirp.InvalidArgumentError("content", fmt.Sprintf("conversation %d: %v", i, err))
+		}
+		if err := model.ValidatePersona(c.Persona); err != nil {
+			return twirp.InvalidArgumentError("content", fmt.Sprintf("conversation %d: %v", i, err))
+		}
+	}
+
+	if p := b.Profile; p != nil {
+		number, err := validateProfile(p.Email, p.SlackWebhookURL, p.WhatsAppNumber, p.Channels)
+		if err != nil {
+			return err
+		}
+		p.WhatsAppNumber = number
+	}
+
+	for i, it := range b.Itineraries {
+		if len(it.Items) > itinerary.MaxItems {
+			return twirp.InvalidArgumentError("content", fmt.Sprintf("itinerary %d: more than %d items", i, itinerary.MaxItems))
+		}
+		for _, item := range it.Items {
+			if !itinerary.ValidKind(item.Kind) {
+				return twirp.InvalidArgumentError("content", fmt.Sprintf("itinerary %d: unknown item kind %q", i, item.Kind))
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *Server) ListConversationTemplates(ctx context.Context, req *pb.ListConversationTemplatesRequest) (*pb.ListConversationTemplatesResponse, error) {
+	if s.templates == nil {
+		return nil, twirp.NewError(twirp.Unimplemented, "templates are not enabled")
+	}
+
+	templates, err := s.templates.ListTemplates(ctx)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	resp := &pb.ListConversationTemplatesResponse{}
+	for _, t := range templates {
+		resp.Templates = append(resp.Templates, t.Proto())
+	}
+	return resp, nil
+}
+
+// StartConversationFromTemplate creates a conversation the assistant opens with the
+// template's greeting, guided by its instructions from then on. No model is called:
+// the user's answer is the first message to reply to.
+func (s *Server) StartConversationFromTemplate(ctx context.Context, req *pb.StartConversationFromTemplateRequest) (*pb.StartConversationFromTemplateResponse, error) {
+	if s.templates == nil {
+		return nil, twirp.NewError(twirp.Unimplemented, "templates are not enabled")
+	}
+
+	if strings.TrimSpace(req.GetTemplateId()) == "" {
+		return nil, twirp.RequiredArgumentError("template_id")
+	}
+	settings := model.SettingsFromProto(req.GetSettings())
+	if err := settings.Validate(); err != nil {
+		return nil, twirp.InvalidArgumentError("settings", err.Error())
+	}
+	if err := model.ValidatePersona(req.GetPersona()); err != nil {
+		return nil, twirp.InvalidArgumentError("persona", err.Error())
+	}
+
+	t, err := s.templates.DescribeTemplate(ctx, req.GetTemplateId())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	conversation := &model.Conversation{
+		ID:            primitive.NewObjectID(),
+		UserID:        auth.UserID(ctx),
+		Title:         t.Name,
+		Persona:       req.GetPersona(),
+		TemplateID:    t.ID,
+		Instructions:  t.SystemContext(),
+		ToolsDisabled: req.GetDisableTools(),
+		Settings:      settings,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Messages: []*model.Message{{
+			ID:          primitive.NewObjectID(),
+			Role:        model.RoleAssistant,
+			Content:     t.Greeting,
+			Suggestions: t.Suggestions,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}},
+	}
+
+	if err := s.repo.CreateConversation(ctx, conversation); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	s.recordAudit(ctx, audit.ConversationStart, conversation.ID.Hex(), nil, nil)
+
+	return &pb.StartConversationFromTemplateResponse{
+		ConversationId: conversation.ID.Hex(),
+		Title:          conversation.Title,
+		Reply:          t.Greeting,
+		Suggestions:    t.Suggestions,
+	}, nil
+}
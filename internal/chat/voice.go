@@ -0,0 +1,88 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/speech"
+	"github.com/twitchtv/twirp"
+)
+
+func (s *Server) SendVoiceMessage(ctx context.Context, req *pb.SendVoiceMessageRequest) (*pb.SendVoiceMessageResponse, error) {
+	if s.transcriber == nil {
+		return nil, twirp.NewError(twirp.Unimplemented, "voice messages are not enabled")
+	}
+
+	if len(req.GetAudio()) == 0 {
+		return nil, twirp.RequiredArgumentError("audio")
+	}
+
+	if len(req.GetAudio()) > speech.MaxAudioSize {
+		return nil, twirp.InvalidArgumentError("audio", fmt.Sprintf("must be at most %d MB", speech.MaxAudioSize>>20))
+	}
+
+	if !speech.SupportedFormat(req.GetFilename()) {
+		return nil, twirp.InvalidArgumentError("filename", "must end in one of "+strings.Join(speech.AudioFormats, ", "))
+	}
+
+	transcript, err := s.transcriber.Transcribe(ctx, req.GetAudio(), req.GetFilename(), req.GetLanguage())
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	if req.GetConversationId() == "" {
+		out, err := s.StartConversation(ctx, &pb.StartConversationRequest{Message: transcript, Speak: req.GetSpeak()})
+		if err != nil {
+			return nil, err
+		}
+
+		return &pb.SendVoiceMessageResponse{
+			ConversationId: out.GetConversationId(),
+			Title:          out.GetTitle(),
+			Transcript:     transcript,
+			Reply:          out.GetReply(),
+			ReplyAudio:     out.GetReplyAudio(),
+			Suggestions:    out.GetSuggestions(),
+			Citations:      out.GetCitations(),
+			DataAsOf:       out.GetDataAsOf(),
+		}, nil
+	}
+
+	out, err := s.ContinueConversation(ctx, &pb.ContinueConversationRequest{
+		ConversationId: req.GetConversationId(),
+		Message:        transcript,
+		Speak:          req.GetSpeak(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SendVoiceMessageResponse{
+		ConversationId: req.GetConversationId(),
+		Transcript:     transcript,
+		Reply:          out.GetReply(),
+		ReplyAudio:     out.GetReplyAudio(),
+		Suggestions:    out.GetSuggestions(),
+		Citations:      out.GetCitations(),
+		DataAsOf:       out.GetDataAsOf(),
+	}, nil
+}
+
+// speak returns the spoken reply. Speech is best effort: failures are logged and the
+// reply is returned as text only.
+func (s *Server) speak(ctx context.Context, reply string) *pb.Audio {
+	if s.synthesizer == nil {
+		return nil
+	}
+
+	data, contentType, err := s.synthesizer.Synthesize(ctx, reply)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to synthesize reply speech", "error", err)
+		return nil
+	}
+
+	return &pb.Audio{Data: data, ContentType: contentType}
+}
@@ -0,0 +1,163 @@
+package chat
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/audit"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/notify"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *Server) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	userID, err := s.webhookUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetUrl() == "" {
+		return nil, twirp.RequiredArgumentError("url")
+	}
+	u, err := notify.CheckURL(ctx, req.GetUrl())
+	if err != nil {
+		return nil, twirp.InvalidArgumentError("url", err.Error())
+	}
+
+	var events []notify.EventType
+	for _, e := range req.GetEvents() {
+		t := notify.EventType(e)
+		if !t.Valid() {
+			return nil, twirp.InvalidArgumentError("events", "unknown event type "+e)
+		}
+		events = append(events, t)
+	}
+
+	secret, err := notify.NewSecret()
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	hook := &notify.Webhook{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		URL:       u.String(),
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.webhooks.CreateWebhook(ctx, hook); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	s.recordAudit(ctx, audit.WebhookCreate, hook.ID.Hex(), nil, withoutSecret(hook))
+
+	return &pb.CreateWebhookResponse{Webhook: hook.Proto(), Secret: secret}, nil
+}
+
+func (s *Server) ListWebhooks(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	userID, err := s.webhookUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := s.webhooks.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	resp := &pb.ListWebhooksResponse{}
+	for _, h := range hooks {
+		resp.Webhooks = append(resp.Webhooks, h.Proto())
+	}
+
+	return resp, nil
+}
+
+func (s *Server) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	userID, err := s.webhookUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetWebhookId() == "" {
+		return nil, twirp.RequiredArgumentError("webhook_id")
+	}
+
+	var before *notify.Webhook
+	if s.auditLog != nil {
+		hooks, err := s.webhooks.ListWebhooks(ctx, userID)
+		if err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+		for _, h := range hooks {
+			if h.ID.Hex() == req.GetWebhookId() {
+				before = withoutSecret(h)
+			}
+		}
+	}
+
+	if err := s.webhooks.DeleteWebhook(ctx, userID, req.GetWebhookId()); err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, audit.WebhookDelete, req.GetWebhookId(), before, nil)
+
+	return &pb.DeleteWebhookResponse{}, nil
+}
+
+func (s *Server) ListWebhookDeliveries(ctx context.Context, req *pb.ListWebhookDeliveriesRequest) (*pb.ListWebhookDeliveriesResponse, error) {
+	userID, err := s.webhookUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetWebhookId() == "" {
+		return nil, twirp.RequiredArgumentError("webhook_id")
+	}
+
+	hook, err := s.webhooks.DescribeWebhook(ctx, userID, req.GetWebhookId())
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(req.GetLimit())
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	deliveries, err := s.webhooks.ListDeliveries(ctx, hook.ID, limit)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	resp := &pb.ListWebhookDeliveriesResponse{}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, d.Proto())
+	}
+
+	return resp, nil
+}
+
+// webhookUser checks webhooks are enabled and returns the calling user's ID.
+func (s *Server) webhookUser(ctx context.Context) (string, error) {
+	if s.webhooks == nil {
+		return "", twirp.NewError(twirp.Unimplemented, "webhooks are not enabled")
+	}
+
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return "", twirp.NewError(twirp.Unauthenticated, "user ID is required")
+	}
+
+	return userID, nil
+}
+
+// withoutSecret returns a copy of the webhook without its signing secret, for the audit
+// log.
+func withoutSecret(h *notify.Webhook) *notify.Webhook {
+	c := *h
+	c.Secret = ""
+	return &c
+}
@@ -0,0 +1,54 @@
+package checkpoint
+
+import (
+	"context"
+	"time"
+)
+
+// TTL is how long a checkpoint is kept for retries.
+const TTL = 24 * time.Hour
+
+// Checkpoint records the progress of one reply attempt, so a retry with the same
+// attempt ID can resume from the last completed step instead of starting over.
+type Checkpoint struct {
+	AttemptID string    `bson:"_id"`
+	UserID    string    `bson:"user_id,omitempty"`
+	Intent    string    `bson:"intent,omitempty"`
+	Steps     []Step    `bson:"steps"`
+	Reply     string    `bson:"reply,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// Step is one completed tool loop iteration: the model's partial text and tool
+// calls, and the tool results.
+type Step struct {
+	Content   string     `bson:"content,omitempty"`
+	ToolCalls []ToolCall `bson:"tool_calls"`
+}
+
+// ToolCall is a tool call requested by the model along with its result.
+type ToolCall struct {
+	ID        string `bson:"id"`
+	Name      string `bson:"name"`
+	Arguments string `bson:"arguments"`
+	Result    string `bson:"result"`
+	// Source is the data source cited for the result, empty when it isn't cited.
+	Source    string    `bson:"source,omitempty"`
+	FetchedAt time.Time `bson:"fetched_at"`
+	ExpiresAt time.Time `bson:"expires_at,omitempty"`
+}
+
+type attemptKey struct{}
+
+// WithAttempt identifies the reply attempt of a request. Retries of the same request
+// use the same attempt ID to resume from its checkpoint.
+func WithAttempt(ctx context.Context, attemptID string) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attemptID)
+}
+
+// AttemptID returns the attempt ID of the request, or an empty string if there is none.
+func AttemptID(ctx context.Context) string {
+	id, _ := ctx.Value(attemptKey{}).(string)
+	return id
+}
@@ -0,0 +1,87 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName           = "github.com/acai-travel/tech-challenge/internal/checkpoint"
+	checkpointCollection = "reply_checkpoints"
+)
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+// EnsureIndexes creates the TTL index that removes expired checkpoints.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.conn.Collection(checkpointCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// LoadCheckpoint returns the checkpoint of an attempt, or nil if there is none.
+func (r *Repository) LoadCheckpoint(ctx context.Context, attemptID string) (*Checkpoint, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.LoadCheckpoint")
+	span.SetAttributes(attribute.String("attempt.id", attemptID))
+	defer span.End()
+
+	var c Checkpoint
+	err := r.conn.Collection(checkpointCollection).FindOne(ctx, bson.M{
+		"_id":        attemptID,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&c)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "no checkpoint")
+		return nil, nil
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database error")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("checkpoint.steps", len(c.Steps)))
+	span.SetStatus(codes.Ok, "checkpoint found")
+	return &c, nil
+}
+
+// SaveCheckpoint stores the checkpoint, replacing previous progress of the attempt.
+func (r *Repository) SaveCheckpoint(ctx context.Context, c *Checkpoint) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SaveCheckpoint")
+	span.SetAttributes(
+		attribute.String("attempt.id", c.AttemptID),
+		attribute.Int("checkpoint.steps", len(c.Steps)),
+	)
+	defer span.End()
+
+	c.UpdatedAt = time.Now()
+	c.ExpiresAt = c.UpdatedAt.Add(TTL)
+
+	_, err := r.conn.Collection(checkpointCollection).ReplaceOne(ctx,
+		bson.M{"_id": c.AttemptID}, c, options.Replace().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to save checkpoint")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "checkpoint saved")
+	return nil
+}
@@ -0,0 +1,180 @@
+package gcal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// refreshBefore is how long before their expiry access tokens are refreshed.
+const refreshBefore = time.Minute
+
+// Client calls the Calendar API on behalf of users, with their stored tokens.
+type Client struct {
+	cfg    *Config
+	tokens TokenStore
+	http   *http.Client
+	now    func() time.Time
+}
+
+func NewClient(cfg *Config, tokens TokenStore) *Client {
+	return &Client{cfg: cfg, tokens: tokens, http: &http.Client{Timeout: 15 * time.Second}, now: time.Now}
+}
+
+// AuthCodeURL returns the URL sending userID to Google to connect their calendar.
+func (c *Client) AuthCodeURL(userID string) string {
+	return c.cfg.AuthCodeURL(userID, c.now())
+}
+
+// Connect stores the token of the user who granted access with code and state.
+func (c *Client) Connect(ctx context.Context, code, state string) (string, error) {
+	userID, err := c.cfg.VerifyState(state, c.now())
+	if err != nil {
+		return "", err
+	}
+	t, err := c.cfg.Exchange(ctx, c.http, code, c.now())
+	if err != nil {
+		return "", err
+	}
+	t.UserID = userID
+	if err := c.tokens.SaveToken(ctx, t); err != nil {
+		return "", fmt.Errorf("failed to save token: %w", err)
+	}
+	return userID, nil
+}
+
+// Disconnect forgets the token of the user.
+func (c *Client) Disconnect(ctx context.Context, userID string) error {
+	return c.tokens.DeleteToken(ctx, userID)
+}
+
+// Connected reports whether the user connected their calendar.
+func (c *Client) Connected(ctx context.Context, userID string) (bool, error) {
+	t, err := c.tokens.DescribeToken(ctx, userID)
+	return t != nil, err
+}
+
+// UpcomingEvents lists the events of the user's primary calendar between from and to,
+// in order, matching query if set.
+func (c *Client) UpcomingEvents(ctx context.Context, userID string, from, to time.Time, query string) ([]Event, error) {
+	q := url.Values{
+		"timeMin":      {from.Format(time.RFC3339)},
+		"timeMax":      {to.Format(time.RFC3339)},
+		"singleEvents": {"true"},
+		"orderBy":      {"startTime"},
+		"maxResults":   {"50"},
+	}
+	if query != "" {
+		q.Set("q", query)
+	}
+
+	var out struct {
+		Items []Event `json:"items"`
+	}
+	if _, err := c.call(ctx, userID, http.MethodGet, "/calendars/primary/events?"+q.Encode(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// PutEvent creates e in the user's primary calendar, or updates it when an event with its
+// ID exists already, and reports whether it was created. IDs must be 5 to 1024 lowercase
+// letters a-v or digits.
+func (c *Client) PutEvent(ctx context.Context, userID string, e Event) (bool, error) {
+	status, err := c.call(ctx, userID, http.MethodPost, "/calendars/primary/events", e, nil)
+	if status != http.StatusConflict {
+		return err == nil, err
+	}
+	_, err = c.call(ctx, userID, http.MethodPut, "/calendars/primary/events/"+url.PathEscape(e.ID), e, nil)
+	return false, err
+}
+
+// call calls the Calendar API with the token of the user, and returns the status of the
+// response.
+func (c *Client) call(ctx context.Context, userID, method, path string, in, out any) (int, error) {
+	t, err := c.token(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.APIURL+path, body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.AccessToken)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call google calendar: %w", err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return res.StatusCode, fmt.Errorf("failed to read google calendar response: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(b, &apiErr)
+		return res.StatusCode, fmt.Errorf("google calendar returned status %d: %s", res.StatusCode, apiErr.Error.Message)
+	}
+	if out != nil {
+		if err := json.Unmarshal(b, out); err != nil {
+			return res.StatusCode, fmt.Errorf("failed to decode google calendar response: %w", err)
+		}
+	}
+	return res.StatusCode, nil
+}
+
+// token returns the token of the user, refreshed if about to expire. Users who revoked
+// access are disconnected.
+func (c *Client) token(ctx context.Context, userID string) (*Token, error) {
+	t, err := c.tokens.DescribeToken(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	if t == nil {
+		return nil, ErrNotConnected
+	}
+	if c.now().Add(refreshBefore).Before(t.Expiry) {
+		return t, nil
+	}
+
+	err = c.cfg.Refresh(ctx, c.http, t, c.now())
+	if errors.Is(err, errRevoked) {
+		slog.InfoContext(ctx, "Google Calendar access revoked, disconnecting", "user_id", userID)
+		if err := c.tokens.DeleteToken(ctx, userID); err != nil {
+			slog.WarnContext(ctx, "Failed to delete revoked token", "user_id", userID, "error", err)
+		}
+		return nil, ErrNotConnected
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	if err := c.tokens.SaveToken(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to save token: %w", err)
+	}
+	return t, nil
+}
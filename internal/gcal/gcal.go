@@ -0,0 +1,109 @@
+// Package gcal connects users' Google Calendars: users grant access with OAuth, their
+// tokens are stored and refreshed per user, and the assistant reads their upcoming trips
+// and writes the items of their itineraries back.
+package gcal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Endpoints of Google's OAuth and Calendar APIs.
+const (
+	DefaultAuthURL  = "https://accounts.google.com/o/oauth2/v2/auth"
+	DefaultTokenURL = "https://oauth2.googleapis.com/token"
+	DefaultAPIURL   = "https://www.googleapis.com/calendar/v3"
+
+	// Scope grants reading and writing the events of the user's calendars.
+	Scope = "https://www.googleapis.com/auth/calendar.events"
+)
+
+// CallbackPath is where Google redirects users once they granted access, under the
+// path Handler is mounted at.
+const CallbackPath = "/calendar/google/callback"
+
+// ErrNotConnected is returned for users who haven't connected their calendar.
+var ErrNotConnected = errors.New("google calendar is not connected")
+
+// Config is the OAuth client of the deployment, registered in the Google Cloud console.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	// RedirectURL must be registered as an authorized redirect URI of the client
+	RedirectURL string
+
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+}
+
+// ConfigFromEnv returns the OAuth client configured by GOOGLE_CLIENT_ID and
+// GOOGLE_CLIENT_SECRET when GOOGLE_CALENDAR enables the integration, nil otherwise.
+// The redirect URL is left to the caller, as it depends on the tenant.
+func ConfigFromEnv() (*Config, error) {
+	v := os.Getenv("GOOGLE_CALENDAR")
+	if v == "" {
+		return nil, nil
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("GOOGLE_CALENDAR must be a boolean, got %q", v)
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	cfg := &Config{
+		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+		AuthURL:      DefaultAuthURL,
+		TokenURL:     DefaultTokenURL,
+		APIURL:       DefaultAPIURL,
+	}
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, errors.New("GOOGLE_CALENDAR requires GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET")
+	}
+	return cfg, nil
+}
+
+// Token is the OAuth token of a user.
+type Token struct {
+	UserID       string    `bson:"_id"`
+	AccessToken  string    `bson:"access_token"`
+	RefreshToken string    `bson:"refresh_token"`
+	Expiry       time.Time `bson:"expiry"`
+	Scope        string    `bson:"scope,omitempty"`
+	CreatedAt    time.Time `bson:"created_at"`
+	UpdatedAt    time.Time `bson:"updated_at"`
+}
+
+// TokenStore stores the tokens of users.
+type TokenStore interface {
+	// DescribeToken returns the token of the user, nil when they have none.
+	DescribeToken(ctx context.Context, userID string) (*Token, error)
+	SaveToken(ctx context.Context, t *Token) error
+	DeleteToken(ctx context.Context, userID string) error
+}
+
+// Event is an event of a calendar, with times in RFC 3339 and the IANA timezone they are
+// local to.
+type Event struct {
+	ID          string    `json:"id,omitempty"`
+	Summary     string    `json:"summary,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Start       EventTime `json:"start"`
+	End         EventTime `json:"end"`
+}
+
+// EventTime is the start or end of an event: DateTime for timed events, Date for
+// all-day ones.
+type EventTime struct {
+	DateTime string `json:"dateTime,omitempty"`
+	Date     string `json:"date,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+}
@@ -0,0 +1,167 @@
+package gcal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryTokens stores tokens in memory.
+type memoryTokens map[string]*Token
+
+func (m memoryTokens) DescribeToken(_ context.Context, userID string) (*Token, error) {
+	return m[userID], nil
+}
+
+func (m memoryTokens) SaveToken(_ context.Context, t *Token) error {
+	m[t.UserID] = t
+	return nil
+}
+
+func (m memoryTokens) DeleteToken(_ context.Context, userID string) error {
+	delete(m, userID)
+	return nil
+}
+
+// fakeGoogle serves the token endpoint and the events of a primary calendar.
+type fakeGoogle struct {
+	events    map[string]Event
+	revoked   bool
+	refreshes int
+}
+
+func (g *fakeGoogle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/token":
+		_ = r.ParseForm()
+		if g.revoked {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+		resp := map[string]any{"access_token": "access-" + r.Form.Get("grant_type"), "expires_in": 3600}
+		if r.Form.Get("grant_type") == "authorization_code" {
+			resp["refresh_token"] = "refresh"
+		} else {
+			g.refreshes++
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	case r.Header.Get("Authorization") != "Bearer access-refresh_token" && r.Header.Get("Authorization") != "Bearer access-authorization_code":
+		w.WriteHeader(http.StatusUnauthorized)
+	case r.Method == http.MethodGet:
+		var items []Event
+		for _, e := range g.events {
+			items = append(items, e)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+	default:
+		var e Event
+		_ = json.NewDecoder(r.Body).Decode(&e)
+		if _, ok := g.events[e.ID]; ok && r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		g.events[e.ID] = e
+		_ = json.NewEncoder(w).Encode(e)
+	}
+}
+
+func newTestClient(t *testing.T) (*Client, *fakeGoogle, memoryTokens) {
+	google := &fakeGoogle{events: make(map[string]Event)}
+	srv := httptest.NewServer(google)
+	t.Cleanup(srv.Close)
+
+	cfg := &Config{ClientID: "client", ClientSecret: "secret", RedirectURL: "https://example.com" + CallbackPath,
+		AuthURL: srv.URL + "/auth", TokenURL: srv.URL + "/token", APIURL: srv.URL}
+	tokens := memoryTokens{}
+	return NewClient(cfg, tokens), google, tokens
+}
+
+func TestConfig_State(t *testing.T) {
+	cfg := &Config{ClientSecret: "secret"}
+	now := time.Now()
+	state := cfg.State("alice.smith@example.com", now.Add(time.Minute))
+
+	if got, err := cfg.VerifyState(state, now); err != nil || got != "alice.smith@example.com" {
+		t.Errorf("VerifyState() = %q, %v", got, err)
+	}
+	if _, err := cfg.VerifyState(state, now.Add(2*time.Minute)); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("VerifyState() of an expired state = %v", err)
+	}
+	if _, err := (&Config{ClientSecret: "other"}).VerifyState(state, now); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("VerifyState() of a state signed by another client = %v", err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	client, google, tokens := newTestClient(t)
+
+	if _, err := client.UpcomingEvents(ctx, "alice", time.Now(), time.Now().Add(time.Hour), ""); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("UpcomingEvents() before connecting = %v, want ErrNotConnected", err)
+	}
+
+	u, _ := url.Parse(client.AuthCodeURL("alice"))
+	if u.Query().Get("access_type") != "offline" || u.Query().Get("scope") != Scope {
+		t.Errorf("AuthCodeURL() = %s", u)
+	}
+	if _, err := client.Connect(ctx, "code", u.Query().Get("state")); err != nil {
+		t.Fatal(err)
+	}
+
+	e := Event{ID: "abc123def456", Summary: "Flight BCN → LIS", Start: EventTime{DateTime: "2025-10-18T10:05:00+02:00", TimeZone: "Europe/Madrid"}}
+	if created, err := client.PutEvent(ctx, "alice", e); err != nil || !created {
+		t.Errorf("PutEvent() = %v, %v, want the event created", created, err)
+	}
+	e.Summary = "Flight TP1039 BCN → LIS"
+	if created, err := client.PutEvent(ctx, "alice", e); err != nil || created {
+		t.Errorf("PutEvent() again = %v, %v, want the event updated", created, err)
+	}
+	if google.events[e.ID].Summary != e.Summary {
+		t.Errorf("event = %+v, want it updated", google.events[e.ID])
+	}
+
+	// Tokens about to expire are refreshed
+	tokens["alice"].Expiry = time.Now()
+	events, err := client.UpcomingEvents(ctx, "alice", time.Now(), time.Now().Add(time.Hour), "")
+	if err != nil || len(events) != 1 || google.refreshes != 1 || tokens["alice"].RefreshToken != "refresh" {
+		t.Errorf("UpcomingEvents() = %v, %v after %d refreshes", events, err, google.refreshes)
+	}
+
+	// Revoked tokens disconnect the user
+	tokens["alice"].Expiry = time.Now()
+	google.revoked = true
+	if _, err := client.UpcomingEvents(ctx, "alice", time.Now(), time.Now().Add(time.Hour), ""); !errors.Is(err, ErrNotConnected) || tokens["alice"] != nil {
+		t.Errorf("UpcomingEvents() with a revoked token = %v, want the user disconnected", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	client, _, tokens := newTestClient(t)
+	h := Handler(client)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar/google/connect", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("connect without a user = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, CallbackPath+"?code=code&state=forged", nil))
+	if rec.Code != http.StatusBadRequest || len(tokens) != 0 {
+		t.Errorf("callback with a forged state = %d, %v", rec.Code, tokens)
+	}
+
+	state := url.QueryEscape(client.cfg.State("bob", time.Now().Add(time.Minute)))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, CallbackPath+"?code=code&state="+state, nil))
+	if rec.Code != http.StatusOK || tokens["bob"] == nil || !strings.Contains(rec.Body.String(), "connected") {
+		t.Errorf("callback = %d %q, want bob connected", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,87 @@
+package gcal
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+)
+
+// Handler serves the connection of users' calendars under /calendar/google:
+//
+//	GET    /calendar/google/connect     the URL to send the user to, to grant access
+//	GET    /calendar/google/callback    where Google sends them back
+//	GET    /calendar/google/connection  whether the user's calendar is connected
+//	DELETE /calendar/google/connection  disconnects it
+func Handler(client *Client) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		action := strings.TrimPrefix(r.URL.Path, "/calendar/google/")
+
+		if action == "callback" {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", "GET")
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			// The browser of the user is redirected here, without credentials
+			if e := r.URL.Query().Get("error"); e != "" {
+				http.Error(w, "Google Calendar was not connected: "+e, http.StatusBadRequest)
+				return
+			}
+			userID, err := client.Connect(ctx, r.URL.Query().Get("code"), r.URL.Query().Get("state"))
+			if errors.Is(err, ErrInvalidState) {
+				http.Error(w, "This link has expired, connect Google Calendar again", http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to connect Google Calendar", "error", err)
+				http.Error(w, "Failed to connect Google Calendar", http.StatusBadGateway)
+				return
+			}
+			slog.InfoContext(ctx, "Google Calendar connected", "user_id", userID)
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte("Google Calendar is connected. You can close this page and go back to your conversation.\n"))
+			return
+		}
+
+		userID := auth.UserID(ctx)
+		if userID == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case action == "connect" && r.Method == http.MethodGet:
+			writeJSON(w, map[string]string{"url": client.AuthCodeURL(userID)})
+		case action == "connection" && r.Method == http.MethodGet:
+			connected, err := client.Connected(ctx, userID)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to check Google Calendar connection", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, map[string]bool{"connected": connected})
+		case action == "connection" && r.Method == http.MethodDelete:
+			if err := client.Disconnect(ctx, userID); err != nil {
+				slog.ErrorContext(ctx, "Failed to disconnect Google Calendar", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case action == "connect" || action == "connection":
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(v)
+}
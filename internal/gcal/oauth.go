@@ -0,0 +1,169 @@
+package gcal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stateTTL is how long users have to grant access once sent to Google.
+const stateTTL = 15 * time.Minute
+
+// ErrInvalidState is returned by VerifyState for states not issued by State, or expired.
+var ErrInvalidState = errors.New("invalid or expired OAuth state")
+
+// AuthCodeURL returns the URL sending userID to Google to grant access to their calendar.
+// Access is offline, so tokens can be refreshed while they're away.
+func (c *Config) AuthCodeURL(userID string, now time.Time) string {
+	q := url.Values{
+		"client_id":     {c.ClientID},
+		"redirect_uri":  {c.RedirectURL},
+		"response_type": {"code"},
+		"scope":         {Scope},
+		"access_type":   {"offline"},
+		// Google only returns a refresh token on consent
+		"prompt": {"consent"},
+		"state":  {c.State(userID, now.Add(stateTTL))},
+	}
+	return c.AuthURL + "?" + q.Encode()
+}
+
+// State returns the OAuth state of userID, of the form "<user id>.<expiry unix>.<signature>"
+// with the user ID in base64: the callback is a redirect of the user's browser, which
+// carries no credentials, so the state tells who granted access.
+func (c *Config) State(userID string, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID)) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + c.signature(payload)
+}
+
+// VerifyState checks the signature and expiry of state and returns the user it was issued for.
+func (c *Config) VerifyState(state string, now time.Time) (string, error) {
+	i := strings.LastIndex(state, ".")
+	if i < 0 {
+		return "", ErrInvalidState
+	}
+	payload, sig := state[:i], state[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(c.signature(payload))) {
+		return "", ErrInvalidState
+	}
+
+	user, expiry, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", ErrInvalidState
+	}
+	userID, err := base64.RawURLEncoding.DecodeString(user)
+	if err != nil || len(userID) == 0 {
+		return "", ErrInvalidState
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || !now.Before(time.Unix(unix, 0)) {
+		return "", ErrInvalidState
+	}
+	return string(userID), nil
+}
+
+func (c *Config) signature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(c.ClientSecret))
+	mac.Write([]byte("gcal-state." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// tokenResponse is the response of the token endpoint.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Scope            string `json:"scope"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange exchanges the code Google redirected the user with for their token.
+func (c *Config) Exchange(ctx context.Context, client *http.Client, code string, now time.Time) (*Token, error) {
+	resp, err := c.token(ctx, client, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {c.RedirectURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.RefreshToken == "" {
+		return nil, errors.New("google returned no refresh token")
+	}
+	return &Token{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		Expiry:       now.Add(time.Duration(resp.ExpiresIn) * time.Second),
+		Scope:        resp.Scope,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// Refresh renews the access token of t in place. Google keeps the refresh token unless
+// it returns a new one.
+func (c *Config) Refresh(ctx context.Context, client *http.Client, t *Token, now time.Time) error {
+	resp, err := c.token(ctx, client, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {t.RefreshToken},
+	})
+	if err != nil {
+		return err
+	}
+	t.AccessToken = resp.AccessToken
+	if resp.RefreshToken != "" {
+		t.RefreshToken = resp.RefreshToken
+	}
+	t.Expiry = now.Add(time.Duration(resp.ExpiresIn) * time.Second)
+	t.UpdatedAt = now
+	return nil
+}
+
+// errRevoked is returned refreshing a token the user revoked, or that expired.
+var errRevoked = errors.New("google calendar access was revoked")
+
+func (c *Config) token(ctx context.Context, client *http.Client, form url.Values) (*tokenResponse, error) {
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	var resp tokenResponse
+	_ = json.Unmarshal(body, &resp)
+	if resp.Error == "invalid_grant" {
+		return nil, errRevoked
+	}
+	if res.StatusCode != http.StatusOK || resp.AccessToken == "" {
+		msg := resp.Error
+		if resp.ErrorDescription != "" {
+			msg += ": " + resp.ErrorDescription
+		}
+		return nil, fmt.Errorf("token request failed with status %d: %s", res.StatusCode, msg)
+	}
+	return &resp, nil
+}
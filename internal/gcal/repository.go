@@ -0,0 +1,74 @@
+package gcal
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName      = "github.com/acai-travel/tech-challenge/internal/gcal"
+	tokenCollection = "calendar_tokens"
+)
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) DescribeToken(ctx context.Context, userID string) (*Token, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeToken")
+	defer span.End()
+
+	var t Token
+	err := r.conn.Collection(tokenCollection).FindOne(ctx, bson.M{"_id": userID}).Decode(&t)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "no token")
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to describe token")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "token described")
+	return &t, nil
+}
+
+func (r *Repository) SaveToken(ctx context.Context, t *Token) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SaveToken")
+	defer span.End()
+
+	_, err := r.conn.Collection(tokenCollection).ReplaceOne(ctx, bson.M{"_id": t.UserID}, t, options.Replace().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to save token")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "token saved")
+	return nil
+}
+
+func (r *Repository) DeleteToken(ctx context.Context, userID string) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DeleteToken")
+	defer span.End()
+
+	if _, err := r.conn.Collection(tokenCollection).DeleteOne(ctx, bson.M{"_id": userID}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete token")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "token deleted")
+	return nil
+}
@@ -0,0 +1,86 @@
+// Package genai instruments OpenAI calls with the OpenTelemetry GenAI semantic
+// conventions, so traces can be read by LLM-observability backends.
+package genai
+
+import (
+	"context"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ToolCallEvent is the span event recorded for each tool call requested by the model.
+const ToolCallEvent = "gen_ai.tool.call"
+
+// StartChat starts a client span for a chat completion with model, named
+// "chat {model}" as the conventions require. When ctx carries a Usage, the completion
+// is accounted in it once the span ends.
+func StartChat(ctx context.Context, tracer trace.Tracer, model openai.ChatModel, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append([]attribute.KeyValue{
+		semconv.GenAIOperationNameChat,
+		semconv.GenAIProviderNameOpenAI,
+		semconv.GenAIRequestModel(string(model)),
+	}, attrs...)
+
+	ctx, span := tracer.Start(ctx, "chat "+string(model),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	if usage, ok := ctx.Value(usageKey{}).(*Usage); ok {
+		span = &meteredSpan{Span: span, usage: usage, model: string(model), start: time.Now()}
+	}
+	return ctx, span
+}
+
+// RecordResponse adds the response model, finish reasons, token usage and requested
+// tool calls of a chat completion to span.
+func RecordResponse(span trace.Span, resp *openai.ChatCompletion) {
+	if resp == nil {
+		return
+	}
+	if s, ok := span.(*meteredSpan); ok {
+		s.record(resp)
+	}
+
+	reasons := make([]string, 0, len(resp.Choices))
+	for _, c := range resp.Choices {
+		reasons = append(reasons, c.FinishReason)
+	}
+
+	span.SetAttributes(
+		semconv.GenAIResponseID(resp.ID),
+		semconv.GenAIResponseModel(resp.Model),
+		semconv.GenAIResponseFinishReasons(reasons...),
+		semconv.GenAIUsageInputTokens(int(resp.Usage.PromptTokens)),
+		semconv.GenAIUsageOutputTokens(int(resp.Usage.CompletionTokens)),
+	)
+
+	if len(resp.Choices) == 0 {
+		return
+	}
+	for _, call := range resp.Choices[0].Message.ToolCalls {
+		span.AddEvent(ToolCallEvent, trace.WithAttributes(
+			semconv.GenAIToolName(call.Function.Name),
+			semconv.GenAIToolCallID(call.ID),
+			semconv.GenAIToolType("function"),
+		))
+	}
+}
+
+// StartTool starts an internal span for the execution of a tool call, named
+// "execute_tool {name}".
+func StartTool(ctx context.Context, tracer trace.Tracer, name, callID string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "execute_tool "+name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			semconv.GenAIOperationNameExecuteTool,
+			semconv.GenAIToolName(name),
+			semconv.GenAIToolCallID(callID),
+			semconv.GenAIToolType("function"),
+		),
+	)
+}
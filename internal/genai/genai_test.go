@@ -0,0 +1,96 @@
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRecordResponse(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	var resp openai.ChatCompletion
+	if err := json.Unmarshal([]byte(`{
+		"id": "chatcmpl-1", "model": "gpt-4.1-2025-04-14",
+		"usage": {"prompt_tokens": 12, "completion_tokens": 5, "total_tokens": 17},
+		"choices": [{"index": 0, "finish_reason": "tool_calls", "message": {"role": "assistant",
+			"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{}"}}]}}]
+	}`), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	_, span := StartChat(context.Background(), tracer, openai.ChatModelGPT4_1)
+	RecordResponse(span, &resp)
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	got := spans[0]
+
+	if got.Name() != "chat gpt-4.1" {
+		t.Errorf("span name = %q, want %q", got.Name(), "chat gpt-4.1")
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range got.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	for key, want := range map[attribute.Key]string{
+		"gen_ai.operation.name":          "chat",
+		"gen_ai.provider.name":           "openai",
+		"gen_ai.request.model":           "gpt-4.1",
+		"gen_ai.response.model":          "gpt-4.1-2025-04-14",
+		"gen_ai.response.id":             "chatcmpl-1",
+		"gen_ai.usage.input_tokens":      "12",
+		"gen_ai.usage.output_tokens":     "5",
+		"gen_ai.response.finish_reasons": `["tool_calls"]`,
+	} {
+		if v := attrs[key].Emit(); v != want {
+			t.Errorf("%s = %q, want %q", key, v, want)
+		}
+	}
+
+	events := got.Events()
+	if len(events) != 1 || events[0].Name != ToolCallEvent {
+		t.Fatalf("got events %v, want one %s event", events, ToolCallEvent)
+	}
+}
+
+func TestWithUsage(t *testing.T) {
+	tracer := sdktrace.NewTracerProvider().Tracer("test")
+	ctx, usage := WithUsage(context.Background())
+
+	for _, tokens := range []int64{10, 20} {
+		_, span := StartChat(ctx, tracer, openai.ChatModelGPT4_1)
+		RecordResponse(span, &openai.ChatCompletion{Usage: openai.CompletionUsage{PromptTokens: tokens, CompletionTokens: tokens / 2}})
+		span.End()
+		span.End() // ending twice is accounted once
+	}
+
+	// A failed call counts without tokens
+	_, span := StartChat(ctx, tracer, openai.ChatModelGPT5)
+	span.End()
+
+	// Calls outside the context are not accounted
+	_, span = StartChat(context.Background(), tracer, openai.ChatModelGPT5)
+	span.End()
+
+	got := usage.ByModel()
+	if m := got[string(openai.ChatModelGPT4_1)]; m.Calls != 2 || m.InputTokens != 30 || m.OutputTokens != 15 {
+		t.Errorf("gpt-4.1 usage = %+v, want 2 calls, 30 input and 15 output tokens", m)
+	}
+	if m := got[string(openai.ChatModelGPT5)]; m.Calls != 1 || m.InputTokens != 0 {
+		t.Errorf("gpt-5 usage = %+v, want 1 call without tokens", m)
+	}
+	if total := usage.Total(); total.Calls != 3 || total.Latency <= 0 {
+		t.Errorf("total usage = %+v, want 3 calls with latency", total)
+	}
+}
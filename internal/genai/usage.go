@@ -0,0 +1,107 @@
+package genai
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ModelUsage counts the chat completions made with a model.
+type ModelUsage struct {
+	Calls        int
+	InputTokens  int64
+	OutputTokens int64
+	// Latency is the time spent waiting for the completions.
+	Latency time.Duration
+}
+
+func (u *ModelUsage) add(o ModelUsage) {
+	u.Calls += o.Calls
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.Latency += o.Latency
+}
+
+// Usage accumulates the token usage and latency of the chat completions started with
+// a context, per requested model. It is safe for concurrent use.
+type Usage struct {
+	mu     sync.Mutex
+	models map[string]*ModelUsage
+}
+
+type usageKey struct{}
+
+// WithUsage returns a context whose chat spans, from StartChat to End, are accounted
+// in the returned Usage.
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	u := &Usage{models: make(map[string]*ModelUsage)}
+	return context.WithValue(ctx, usageKey{}, u), u
+}
+
+// ByModel returns the usage of each requested model.
+func (u *Usage) ByModel() map[string]ModelUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	out := make(map[string]ModelUsage, len(u.models))
+	for model, m := range u.models {
+		out[model] = *m
+	}
+	return out
+}
+
+// Total returns the usage summed over all models.
+func (u *Usage) Total() ModelUsage {
+	var total ModelUsage
+	for _, m := range u.ByModel() {
+		total.add(m)
+	}
+	return total
+}
+
+func (u *Usage) add(model string, o ModelUsage) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	m, ok := u.models[model]
+	if !ok {
+		m = &ModelUsage{}
+		u.models[model] = m
+	}
+	m.add(o)
+}
+
+// meteredSpan is a chat span accounting its completion in a Usage when it ends.
+type meteredSpan struct {
+	trace.Span
+	usage *Usage
+	model string
+	start time.Time
+
+	mu   sync.Mutex
+	resp *openai.ChatCompletion
+	done bool
+}
+
+func (s *meteredSpan) record(resp *openai.ChatCompletion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resp = resp
+}
+
+func (s *meteredSpan) End(opts ...trace.SpanEndOption) {
+	s.mu.Lock()
+	if !s.done {
+		s.done = true
+		m := ModelUsage{Calls: 1, Latency: time.Since(s.start)}
+		if s.resp != nil {
+			m.InputTokens = s.resp.Usage.PromptTokens
+			m.OutputTokens = s.resp.Usage.CompletionTokens
+		}
+		s.usage.add(s.model, m)
+	}
+	s.mu.Unlock()
+
+	s.Span.End(opts...)
+}
@@ -0,0 +1,175 @@
+// Package geo resolves place names to coordinates and canonical names.
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/vcr"
+)
+
+// Place is a geocoded place.
+type Place struct {
+	Name    string
+	Region  string
+	Country string
+	Lat     float64
+	Lon     float64
+}
+
+// Canonical returns the full name of the place, like "Springfield, Illinois, United
+// States of America".
+func (p Place) Canonical() string {
+	parts := []string{p.Name}
+	for _, part := range []string{p.Region, p.Country} {
+		if part != "" && part != parts[len(parts)-1] {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Geocoder finds the places matching a name, best matches first.
+type Geocoder interface {
+	Search(ctx context.Context, query string) ([]Place, error)
+}
+
+// coordinatesPattern matches "lat,lon" coordinates like "38.7742,-9.1342".
+var coordinatesPattern = regexp.MustCompile(`^\s*(-?\d+(?:\.\d+)?)\s*,\s*(-?\d+(?:\.\d+)?)\s*$`)
+
+// ParseCoordinates parses "lat,lon" coordinates.
+func ParseCoordinates(s string) (lat, lon float64, ok bool) {
+	m := coordinatesPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	lat, _ = strconv.ParseFloat(m[1], 64)
+	lon, _ = strconv.ParseFloat(m[2], 64)
+	return lat, lon, true
+}
+
+// WeatherAPIGeocoder finds places with the location search of WeatherAPI, whose key is
+// read from WEATHER_API_KEY like the weather tools do.
+type WeatherAPIGeocoder struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func NewWeatherAPIGeocoder() *WeatherAPIGeocoder {
+	return &WeatherAPIGeocoder{
+		httpClient: &http.Client{Timeout: 5 * time.Second, Transport: vcr.Transport},
+		baseURL:    "https://api.weatherapi.com",
+	}
+}
+
+func (g *WeatherAPIGeocoder) Search(ctx context.Context, query string) ([]Place, error) {
+	if lat, lon, ok := ParseCoordinates(query); ok {
+		return []Place{{Name: strings.TrimSpace(query), Lat: lat, Lon: lon}}, nil
+	}
+
+	apiKey := os.Getenv("WEATHER_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("missing WEATHER_API_KEY")
+	}
+
+	u, err := url.Parse(g.baseURL + "/v1/search.json")
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	q := u.Query()
+	q.Set("key", apiKey)
+	q.Set("q", query)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("api error: status %d", resp.StatusCode)
+	}
+
+	var matches []struct {
+		Name    string  `json:"name"`
+		Region  string  `json:"region"`
+		Country string  `json:"country"`
+		Lat     float64 `json:"lat"`
+		Lon     float64 `json:"lon"`
+	}
+	if err := json.Unmarshal(body, &matches); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	out := make([]Place, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, Place{Name: m.Name, Region: m.Region, Country: m.Country, Lat: m.Lat, Lon: m.Lon})
+	}
+	return out, nil
+}
+
+// Candidates returns the places a query could mean among its matches. Search results
+// rank well-known places first, so the best match wins over its namesakes abroad, but
+// namesakes in other regions of its country ("Springfield") are left to choose from.
+// Qualifiers after a comma in the query ("Springfield, Illinois") narrow them down.
+func Candidates(query string, matches []Place) []Place {
+	if len(matches) <= 1 {
+		return matches
+	}
+
+	var qualifiers []string
+	for _, q := range strings.Split(query, ",")[1:] {
+		if q = strings.ToLower(strings.TrimSpace(q)); q != "" {
+			qualifiers = append(qualifiers, q)
+		}
+	}
+
+	var qualified []Place
+	for _, m := range matches {
+		if qualifies(m, qualifiers) {
+			qualified = append(qualified, m)
+		}
+	}
+	if len(qualified) == 0 {
+		return matches[:1]
+	}
+
+	var out []Place
+	seen := make(map[string]bool)
+	for _, m := range qualified {
+		if m.Country != qualified[0].Country || seen[m.Canonical()] {
+			continue
+		}
+		seen[m.Canonical()] = true
+		out = append(out, m)
+	}
+	return out
+}
+
+// qualifies reports whether every qualifier starts the region or country of p.
+func qualifies(p Place, qualifiers []string) bool {
+	for _, q := range qualifiers {
+		if !strings.HasPrefix(strings.ToLower(p.Region), q) && !strings.HasPrefix(strings.ToLower(p.Country), q) {
+			return false
+		}
+	}
+	return true
+}
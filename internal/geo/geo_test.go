@@ -0,0 +1,74 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var (
+	illinois = Place{Name: "Springfield", Region: "Illinois", Country: "United States of America"}
+	missouri = Place{Name: "Springfield", Region: "Missouri", Country: "United States of America"}
+	tasmania = Place{Name: "Springfield", Region: "Tasmania", Country: "Australia"}
+)
+
+func TestPlace_Canonical(t *testing.T) {
+	if got, want := illinois.Canonical(), "Springfield, Illinois, United States of America"; got != want {
+		t.Errorf("Canonical() = %q, want %q", got, want)
+	}
+	if got, want := (Place{Name: "Singapore", Region: "Singapore", Country: "Singapore"}).Canonical(), "Singapore"; got != want {
+		t.Errorf("Canonical() = %q, want %q", got, want)
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		query   string
+		matches []Place
+		want    []Place
+	}{
+		{"Springfield", []Place{illinois, missouri, tasmania, illinois}, []Place{illinois, missouri}},
+		{"Springfield, Missouri", []Place{illinois, missouri, tasmania}, []Place{missouri}},
+		{"Springfield, australia", []Place{illinois, missouri, tasmania}, []Place{tasmania}},
+		{"Springfield, Kentucky", []Place{illinois, missouri}, []Place{illinois}},
+		{"Springfield", []Place{tasmania, illinois, missouri}, []Place{tasmania}},
+		{"Nowhere", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := Candidates(tt.query, tt.matches); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Candidates(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestWeatherAPIGeocoder_Search(t *testing.T) {
+	t.Setenv("WEATHER_API_KEY", "key")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/search.json" || r.URL.Query().Get("q") != "Springfield" || r.URL.Query().Get("key") != "key" {
+			t.Errorf("request = %s", r.URL)
+		}
+		_, _ = fmt.Fprint(w, `[{"name": "Springfield", "region": "Illinois", "country": "United States of America", "lat": 39.8, "lon": -89.64}]`)
+	}))
+	defer srv.Close()
+
+	g := NewWeatherAPIGeocoder()
+	g.baseURL = srv.URL
+
+	got, err := g.Search(context.Background(), "Springfield")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	want := []Place{{Name: "Springfield", Region: "Illinois", Country: "United States of America", Lat: 39.8, Lon: -89.64}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Search() = %v, want %v", got, want)
+	}
+
+	// Coordinates need no lookup
+	got, err = g.Search(context.Background(), "38.7742, -9.1342")
+	if err != nil || len(got) != 1 || got[0].Lat != 38.7742 || got[0].Lon != -9.1342 {
+		t.Errorf("Search() = %v, %v, want the coordinates", got, err)
+	}
+}
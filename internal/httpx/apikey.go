@@ -0,0 +1,63 @@
+package httpx
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/apikey"
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// KeyAuthenticator looks up the active API key with a secret, nil if there is none.
+type KeyAuthenticator interface {
+	Authenticate(ctx context.Context, secret string) (*apikey.Key, error)
+}
+
+// APIKeys returns a middleware authenticating requests made with an API key of tenant
+// tenantID in "Authorization: Bearer <key>": they act as the key's user, and must be in
+// its scopes. Other requests, including those with other bearer tokens, pass through.
+func APIKeys(keys KeyAuthenticator, tenantID string) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || !strings.HasPrefix(secret, apikey.Prefix) {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			key, err := keys.Authenticate(ctx, secret)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to authenticate API key", "error", err)
+				_ = twirp.WriteError(w, twirp.InternalError("failed to authenticate API key"))
+				return
+			}
+			if key == nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				_ = twirp.WriteError(w, twirp.NewError(twirp.Unauthenticated, "invalid API key"))
+				return
+			}
+
+			ctx = auth.WithAPIKeyID(ctx, key.ID.Hex())
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("api_key.id", key.ID.Hex()))
+
+			if key.TenantID != tenantID {
+				slog.WarnContext(ctx, "API key used for another tenant", "tenant_id", tenantID)
+				_ = twirp.WriteError(w, twirp.NewError(twirp.PermissionDenied, "API key is not valid for this tenant"))
+				return
+			}
+			if !key.Allows(r.URL.Path) {
+				slog.WarnContext(ctx, "API key used out of its scopes", "http_path", r.URL.Path)
+				_ = twirp.WriteError(w, twirp.NewError(twirp.PermissionDenied, "API key lacks the "+apikey.RequiredScope(r.URL.Path)+" scope"))
+				return
+			}
+
+			handler.ServeHTTP(w, r.WithContext(auth.WithUserID(ctx, key.UserID)))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package httpx
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	large := `{"messages":[` + strings.Repeat(`{"role":"assistant","content":"Lisbon is sunny today."},`, 100) + `{}]}`
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		body           string
+		contentLength  bool
+		wantGzip       bool
+	}{
+		{name: "large JSON", acceptEncoding: "gzip, deflate, br", contentType: "application/json", body: large, contentLength: true, wantGzip: true},
+		{name: "large JSON of unknown length", acceptEncoding: "gzip", contentType: "application/json", body: large, wantGzip: true},
+		{name: "sniffed HTML", acceptEncoding: "*", body: "<html>" + large, wantGzip: true},
+		{name: "small JSON", acceptEncoding: "gzip", contentType: "application/json", body: "{}", contentLength: true},
+		{name: "gzip not accepted", acceptEncoding: "br", contentType: "application/json", body: large},
+		{name: "gzip refused", acceptEncoding: "gzip;q=0, *", contentType: "application/json", body: large},
+		{name: "event stream", acceptEncoding: "gzip", contentType: "text/event-stream", body: large},
+		{name: "audio", acceptEncoding: "gzip", contentType: "audio/mpeg", body: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				if tt.contentLength {
+					w.Header().Set("Content-Length", strconv.Itoa(len(tt.body)))
+				}
+				_, _ = io.WriteString(w, tt.body)
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "/twirp/acai.chat.ChatService/DescribeConversation", nil)
+			r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			gzipped := w.Header().Get("Content-Encoding") == "gzip"
+			if gzipped != tt.wantGzip {
+				t.Fatalf("gzipped = %v, want %v", gzipped, tt.wantGzip)
+			}
+
+			body := w.Body.String()
+			if gzipped {
+				if w.Header().Get("Content-Length") != "" {
+					t.Error("gzipped response keeps the uncompressed Content-Length")
+				}
+				if w.Body.Len() >= len(tt.body) {
+					t.Errorf("gzipped body is %d bytes, want less than %d", w.Body.Len(), len(tt.body))
+				}
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+				data, err := io.ReadAll(zr)
+				if err != nil {
+					t.Fatalf("reading gzipped body: %v", err)
+				}
+				body = string(data)
+			}
+			if body != tt.body {
+				t.Errorf("body = %.40q, want %.40q", body, tt.body)
+			}
+		})
+	}
+}
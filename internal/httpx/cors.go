@@ -0,0 +1,120 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsAllowedHeaders are the request headers the API reads, allowed in cross-origin
+// requests.
+var corsAllowedHeaders = strings.Join([]string{
+	"Authorization", "Content-Type", "If-None-Match", UserIDHeader, TenantIDHeader, DebugTraceHeader, "traceparent", "tracestate",
+}, ", ")
+
+// corsExposedHeaders are the response headers browsers let cross-origin callers read.
+const corsExposedHeaders = "ETag"
+
+// corsMaxAge is how long browsers may cache the answer to a preflight request.
+const corsMaxAge = 10 * time.Minute
+
+// CORSConfig decides which browser origins may call the API. The zero CORSConfig allows
+// none, leaving browsers to block cross-origin calls.
+type CORSConfig struct {
+	// AllowedOrigins are origins like "https://app.example.com", or "*" for any.
+	AllowedOrigins []string
+	// AllowCredentials lets browsers send cookies and HTTP authentication, for allowed
+	// origins other than "*".
+	AllowCredentials bool
+}
+
+// NewCORSConfigFromEnv configures CORS from CORS_ALLOWED_ORIGINS, a comma-separated
+// list of origins, and CORS_ALLOW_CREDENTIALS.
+func NewCORSConfigFromEnv() (CORSConfig, error) {
+	var cfg CORSConfig
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "" {
+			continue
+		}
+		if origin != "*" {
+			u, err := url.Parse(origin)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.Path != "" {
+				return CORSConfig{}, fmt.Errorf("CORS_ALLOWED_ORIGINS must be origins like https://app.example.com, got %q", origin)
+			}
+		}
+		cfg.AllowedOrigins = append(cfg.AllowedOrigins, origin)
+	}
+
+	if v := os.Getenv("CORS_ALLOW_CREDENTIALS"); v != "" {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			return CORSConfig{}, fmt.Errorf("CORS_ALLOW_CREDENTIALS must be a boolean, got %q", v)
+		}
+		cfg.AllowCredentials = allow
+	}
+	if cfg.AllowCredentials && slices.Contains(cfg.AllowedOrigins, "*") {
+		return CORSConfig{}, fmt.Errorf("CORS_ALLOW_CREDENTIALS can't be combined with any origin (*)")
+	}
+	return cfg, nil
+}
+
+// allowed reports whether requests from origin are allowed.
+func (c CORSConfig) allowed(origin string) bool {
+	return slices.Contains(c.AllowedOrigins, "*") || slices.ContainsFunc(c.AllowedOrigins, func(o string) bool {
+		return strings.EqualFold(o, origin)
+	})
+}
+
+// CORS returns a middleware letting browsers on the allowed origins call the API: it
+// answers preflight requests, and adds the CORS headers to the responses of the others.
+// Requests from other origins get no CORS headers, so browsers block them.
+func CORS(cfg CORSConfig) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !cfg.allowed(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			if cfg.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			} else if slices.Contains(cfg.AllowedOrigins, "*") {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+
+			if !preflight {
+				w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name            string
+		cfg             CORSConfig
+		method          string
+		origin          string
+		preflight       bool
+		wantStatus      int
+		wantOrigin      string
+		wantCredentials bool
+	}{
+		{name: "same origin", cfg: CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, method: http.MethodPost, wantStatus: http.StatusOK},
+		{name: "allowed origin", cfg: CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, method: http.MethodPost, origin: "https://app.example.com", wantStatus: http.StatusOK, wantOrigin: "https://app.example.com"},
+		{name: "other origin", cfg: CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, method: http.MethodPost, origin: "https://evil.example", wantStatus: http.StatusOK},
+		{name: "no origins configured", method: http.MethodPost, origin: "https://app.example.com", wantStatus: http.StatusOK},
+		{name: "preflight", cfg: CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, method: http.MethodOptions, origin: "https://app.example.com", preflight: true, wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com"},
+		{name: "preflight from other origin", cfg: CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, method: http.MethodOptions, origin: "https://evil.example", preflight: true, wantStatus: http.StatusForbidden},
+		{name: "any origin", cfg: CORSConfig{AllowedOrigins: []string{"*"}}, method: http.MethodPost, origin: "https://app.example.com", wantStatus: http.StatusOK, wantOrigin: "*"},
+		{name: "credentials", cfg: CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}, method: http.MethodPost, origin: "https://app.example.com", wantStatus: http.StatusOK, wantOrigin: "https://app.example.com", wantCredentials: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/twirp/acai.chat.ChatService/StartConversation", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+				r.Header.Set("Access-Control-Request-Headers", "content-type, x-user-id")
+			}
+			w := httptest.NewRecorder()
+			CORS(tt.cfg)(ok).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %v, want %v", got, tt.wantCredentials)
+			}
+			if tt.preflight && tt.wantOrigin != "" && w.Header().Get("Access-Control-Allow-Headers") == "" {
+				t.Error("preflight answer has no Access-Control-Allow-Headers")
+			}
+		})
+	}
+}
+
+func TestNewCORSConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		origins     string
+		credentials string
+		wantOrigins int
+		wantErr     bool
+	}{
+		{origins: "", wantOrigins: 0},
+		{origins: "https://app.example.com, http://localhost:3000/", credentials: "true", wantOrigins: 2},
+		{origins: "*", wantOrigins: 1},
+		{origins: "*", credentials: "true", wantErr: true},
+		{origins: "app.example.com", wantErr: true},
+		{origins: "https://app.example.com/chat", wantErr: true},
+		{origins: "https://app.example.com", credentials: "sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origins+" "+tt.credentials, func(t *testing.T) {
+			t.Setenv("CORS_ALLOWED_ORIGINS", tt.origins)
+			t.Setenv("CORS_ALLOW_CREDENTIALS", tt.credentials)
+
+			cfg, err := NewCORSConfigFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewCORSConfigFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(cfg.AllowedOrigins) != tt.wantOrigins {
+				t.Errorf("AllowedOrigins = %v, want %d origins", cfg.AllowedOrigins, tt.wantOrigins)
+			}
+		})
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	SecurityHeaders(DefaultHSTSMaxAge)(ok).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/shared/token", nil))
+	for header, want := range map[string]string{
+		"Strict-Transport-Security": "max-age=31536000",
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Content-Security-Policy":   "frame-ancestors 'none'",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	SecurityHeaders(0)(ok).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Strict-Transport-Security = %q with HSTS disabled, want none", got)
+	}
+}
@@ -0,0 +1,76 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// NotModified returns a middleware answering 304 Not Modified, without a body, to
+// requests whose If-None-Match matches the ETag of a successful response. Handlers only
+// have to set the ETag, like DescribeConversation.
+func NotModified() func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ifNoneMatch := r.Header.Get("If-None-Match")
+			if ifNoneMatch == "" {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			handler.ServeHTTP(&notModifiedWriter{ResponseWriter: w, ifNoneMatch: ifNoneMatch}, r)
+		})
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing weakly.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModifiedWriter drops the body of a response the client already has.
+type notModifiedWriter struct {
+	http.ResponseWriter
+	ifNoneMatch string
+	wroteHeader bool
+	dropBody    bool
+}
+
+func (w *notModifiedWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if status == http.StatusOK && etagMatches(w.ifNoneMatch, w.Header().Get("ETag")) {
+		w.dropBody = true
+		w.Header().Del("Content-Length")
+		w.Header().Del("Content-Type")
+		status = http.StatusNotModified
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *notModifiedWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.dropBody {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, to flush event streams.
+func (w *notModifiedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
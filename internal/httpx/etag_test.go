@@ -0,0 +1,66 @@
+package httpx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNotModified(t *testing.T) {
+	const etag = `"68f0a1b2c3d4e5f6a7b8c9d0.3"`
+	h := NotModified()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, `{"conversation":{}}`)
+	}))
+
+	tests := []struct {
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{ifNoneMatch: "", wantStatus: http.StatusOK},
+		{ifNoneMatch: etag, wantStatus: http.StatusNotModified},
+		{ifNoneMatch: `"other", W/` + etag, wantStatus: http.StatusNotModified},
+		{ifNoneMatch: "*", wantStatus: http.StatusNotModified},
+		{ifNoneMatch: `"68f0a1b2c3d4e5f6a7b8c9d0.2"`, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ifNoneMatch, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/twirp/acai.chat.ChatService/DescribeConversation", nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("ETag"); got != etag {
+				t.Errorf("ETag = %q, want %q", got, etag)
+			}
+			if tt.wantStatus == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("304 body = %q, want none", w.Body)
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.Len() == 0 {
+				t.Error("200 has no body")
+			}
+		})
+	}
+
+	// Failures are never turned into a 304
+	failing := NotModified()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("If-None-Match", etag)
+	w := httptest.NewRecorder()
+	failing.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+)
+
+// UserIDHeader is the request header carrying the caller's user ID.
+const UserIDHeader = "X-User-ID"
+
+// TenantIDHeader is the request header carrying the ID of the tenant the caller uses.
+const TenantIDHeader = "X-Tenant-ID"
+
+// Identity returns a middleware that attaches the caller's user and tenant IDs to the
+// request context
+func Identity() func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := strings.TrimSpace(r.Header.Get(UserIDHeader)); id != "" {
+				r = r.WithContext(auth.WithUserID(r.Context(), id))
+			}
+			if id := strings.TrimSpace(r.Header.Get(TenantIDHeader)); id != "" {
+				r = r.WithContext(auth.WithTenantID(r.Context(), id))
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
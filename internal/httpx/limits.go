@@ -0,0 +1,33 @@
+package httpx
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/twitchtv/twirp"
+)
+
+// MaxBodySize returns a middleware rejecting request bodies larger than limit bytes, or
+// than the limit of the request's method in limits, keyed by the last element of the
+// path, like the Twirp method name. Bodies announced larger are rejected before being
+// read, and others are cut at the limit.
+func MaxBodySize(limit int64, limits map[string]int64) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := limit
+			if l, ok := limits[path.Base(r.URL.Path)]; ok {
+				n = l
+			}
+
+			if r.ContentLength > n {
+				_ = twirp.WriteError(w, twirp.NewError(twirp.ResourceExhausted, "request body exceeds "+strconv.FormatInt(n, 10)+" bytes").
+					WithMeta("max_bytes", strconv.FormatInt(n, 10)))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	var readErr error
+	read := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+		if readErr != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	})
+	h := MaxBodySize(10, map[string]int64{"SendVoiceMessage": 100})(read)
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		chunked    bool
+		wantStatus int
+	}{
+		{name: "small body", path: "/twirp/acai.chat.ChatService/ListConversations", body: "{}", wantStatus: http.StatusOK},
+		{name: "announced too large", path: "/twirp/acai.chat.ChatService/ListConversations", body: strings.Repeat("a", 11), wantStatus: http.StatusTooManyRequests},
+		{name: "read too large", path: "/twirp/acai.chat.ChatService/ListConversations", body: strings.Repeat("a", 11), chunked: true, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "upload", path: "/tenants/acme/twirp/acai.chat.ChatService/SendVoiceMessage", body: strings.Repeat("a", 100), wantStatus: http.StatusOK},
+		{name: "upload too large", path: "/twirp/acai.chat.ChatService/SendVoiceMessage", body: strings.Repeat("a", 101), wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readErr = nil
+			r := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				r.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var maxErr *http.MaxBytesError
+			if tt.chunked && !errors.As(readErr, &maxErr) {
+				t.Errorf("read error = %v, want a MaxBytesError", readErr)
+			}
+		})
+	}
+}
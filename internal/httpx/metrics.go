@@ -12,6 +12,11 @@ import (
 
 const meterName = "github.com/acai-travel/tech-challenge/internal/httpx"
 
+// durationBuckets are the request latency histogram boundaries in milliseconds, from
+// cached reads to replies chaining several tools, which the reply latency objective
+// is measured with.
+var durationBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 15000, 20000, 30000, 60000, 120000, 300000}
+
 // metricsMiddleware holds the OpenTelemetry metrics instruments
 type metricsMiddleware struct {
 	requestCounter  metric.Int64Counter
@@ -36,6 +41,7 @@ func newMetricsMiddleware() (*metricsMiddleware, error) {
 		"http.server.request.duration",
 		metric.WithDescription("Duration of HTTP requests in milliseconds"),
 		metric.WithUnit("ms"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
 	)
 	if err != nil {
 		return nil, err
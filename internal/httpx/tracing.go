@@ -2,7 +2,9 @@ package httpx
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/acai-travel/tech-challenge/internal/sampling"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -12,6 +14,9 @@ import (
 
 const tracerName = "github.com/acai-travel/tech-challenge/internal/httpx"
 
+// DebugTraceHeader forces the request to be traced, whatever the sampler, when set to a true value.
+const DebugTraceHeader = "X-Debug-Trace"
+
 // Tracing returns a middleware that creates traces for HTTP requests
 func Tracing() func(handler http.Handler) http.Handler {
 	tracer := otel.Tracer(tracerName)
@@ -21,6 +26,9 @@ func Tracing() func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract trace context from incoming request headers
 			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			if debug, _ := strconv.ParseBool(r.Header.Get(DebugTraceHeader)); debug {
+				ctx = sampling.ForceSample(ctx)
+			}
 
 			// Start a new span for this HTTP request
 			spanName := r.Method + " " + r.URL.Path
@@ -0,0 +1,164 @@
+// Package idle closes conversations nobody has touched for a while: each gets a closing
+// summary and is archived, and what was remembered for it is freed.
+package idle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultWindow is how long a conversation stays open without activity, unless
+// IDLE_CLOSE_DAYS says otherwise.
+const DefaultWindow = 30 * 24 * time.Hour
+
+// batchSize bounds the conversations listed at once.
+const batchSize = 50
+
+// Store finds idle conversations and closes them.
+type Store interface {
+	ListIdle(ctx context.Context, before time.Time, limit int) ([]*model.Conversation, error)
+	CloseIdle(ctx context.Context, id primitive.ObjectID, summary string, before, now time.Time) (bool, error)
+}
+
+// Summarizer writes the closing summary of a conversation.
+type Summarizer interface {
+	Summarize(ctx context.Context, conv *model.Conversation) (string, error)
+}
+
+// Rememberer remembers the closing summaries of conversations, for later replies to
+// recall them.
+type Rememberer interface {
+	Remember(ctx context.Context, conv *model.Conversation, summary string) error
+}
+
+// WindowFromEnv reads the idle window from IDLE_CLOSE_DAYS, DefaultWindow when unset.
+// Zero disables closing idle conversations.
+func WindowFromEnv() (time.Duration, error) {
+	v := os.Getenv("IDLE_CLOSE_DAYS")
+	if v == "" {
+		return DefaultWindow, nil
+	}
+
+	days, err := strconv.Atoi(v)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("IDLE_CLOSE_DAYS must be a number of days, got %q", v)
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// Worker periodically closes the conversations idle for longer than its window.
+type Worker struct {
+	store      Store
+	summarizer Summarizer
+	window     time.Duration
+	interval   time.Duration
+	now        func() time.Time
+	// forget frees what was cached for a closed conversation
+	forget func(conversationID string)
+	// memory remembers the summaries of closed conversations, if set
+	memory Rememberer
+}
+
+// Option configures optional Worker dependencies.
+type Option func(*Worker)
+
+// WithMemory remembers the closing summaries in memory.
+func WithMemory(memory Rememberer) Option {
+	return func(w *Worker) {
+		w.memory = memory
+	}
+}
+
+func NewWorker(store Store, summarizer Summarizer, window time.Duration, opts ...Option) *Worker {
+	w := &Worker{
+		store:      store,
+		summarizer: summarizer,
+		window:     window,
+		interval:   time.Hour,
+		now:        time.Now,
+		forget:     tools.ForgetConversation,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run closes idle conversations on every tick until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick closes every conversation idle at the time of the call.
+func (w *Worker) Tick(ctx context.Context) {
+	now := w.now()
+	before := now.Add(-w.window)
+
+	for ctx.Err() == nil {
+		convs, err := w.store.ListIdle(ctx, before, batchSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to list idle conversations", "error", err)
+			return
+		}
+
+		for _, conv := range convs {
+			ctx := logging.WithConversationID(ctx, conv.ID.Hex())
+			if err := w.close(ctx, conv, before, now); err != nil {
+				slog.ErrorContext(ctx, "Failed to close idle conversation", "error", err)
+				return
+			}
+		}
+
+		if len(convs) < batchSize {
+			return
+		}
+	}
+}
+
+// close summarizes and archives conv. A conversation whose summary fails is closed
+// without one, so that it doesn't come up again on every tick, and isn't remembered.
+func (w *Worker) close(ctx context.Context, conv *model.Conversation, before, now time.Time) error {
+	summary, err := w.summarizer.Summarize(ctx, conv)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.WarnContext(ctx, "Failed to summarize idle conversation", "error", err)
+		summary = ""
+	}
+
+	closed, err := w.store.CloseIdle(ctx, conv.ID, summary, before, now)
+	if err != nil {
+		return err
+	}
+	if closed {
+		w.forget(conv.ID.Hex())
+		if w.memory != nil && summary != "" {
+			if err := w.memory.Remember(ctx, conv, summary); err != nil {
+				slog.WarnContext(ctx, "Failed to remember idle conversation", "error", err)
+			}
+		}
+		slog.InfoContext(ctx, "Closed idle conversation", "summarized", summary != "")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package idle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore is an in-memory Store.
+type memoryStore struct {
+	convs []*model.Conversation
+}
+
+func (m *memoryStore) ListIdle(ctx context.Context, before time.Time, limit int) ([]*model.Conversation, error) {
+	var idle []*model.Conversation
+	for _, c := range m.convs {
+		if c.ArchivedAt == nil && c.UpdatedAt.Before(before) && len(idle) < limit {
+			idle = append(idle, c)
+		}
+	}
+	return idle, nil
+}
+
+func (m *memoryStore) CloseIdle(ctx context.Context, id primitive.ObjectID, summary string, before, now time.Time) (bool, error) {
+	for _, c := range m.convs {
+		if c.ID == id && c.ArchivedAt == nil && c.UpdatedAt.Before(before) {
+			c.Summary, c.AutoArchived, c.ArchivedAt = summary, true, &now
+			c.Entities = model.Entities{}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// summarizer summarizes conversations by title, failing for the ones without.
+type summarizer struct{}
+
+func (summarizer) Summarize(ctx context.Context, conv *model.Conversation) (string, error) {
+	if conv.Title == "" {
+		return "", errors.New("OpenAI API error")
+	}
+	return "You were planning " + conv.Title + ".", nil
+}
+
+// rememberer records the summaries it is given, by conversation.
+type rememberer map[primitive.ObjectID]string
+
+func (r rememberer) Remember(ctx context.Context, conv *model.Conversation, summary string) error {
+	r[conv.ID] = summary
+	return nil
+}
+
+func TestWorker_Tick(t *testing.T) {
+	now := time.Date(2025, 10, 18, 9, 0, 0, 0, time.UTC)
+	conv := func(title string, idleFor time.Duration) *model.Conversation {
+		return &model.Conversation{
+			ID:        primitive.NewObjectID(),
+			Title:     title,
+			UpdatedAt: now.Add(-idleFor),
+			Entities:  model.Entities{Destination: "LIS"},
+		}
+	}
+
+	store := &memoryStore{}
+	for range batchSize {
+		store.convs = append(store.convs, conv("a trip to Lisbon", 40*24*time.Hour))
+	}
+	failing := conv("", 31*24*time.Hour)
+	active := conv("a trip to Porto", 2*24*time.Hour)
+	store.convs = append(store.convs, failing, active)
+
+	var forgotten []string
+	remembered := rememberer{}
+	w := NewWorker(store, summarizer{}, 30*24*time.Hour, WithMemory(remembered))
+	w.now = func() time.Time { return now }
+	w.forget = func(id string) { forgotten = append(forgotten, id) }
+
+	w.Tick(context.Background())
+
+	for _, c := range store.convs[:batchSize] {
+		if !c.AutoArchived || c.Summary != "You were planning a trip to Lisbon." || !c.Entities.IsZero() {
+			t.Fatalf("idle conversation = %+v, want it archived with a summary and no memory", c)
+		}
+	}
+	if !failing.AutoArchived || failing.Summary != "" {
+		t.Errorf("conversation failing to summarize = %+v, want it archived without a summary", failing)
+	}
+	if active.AutoArchived || active.ArchivedAt != nil {
+		t.Errorf("active conversation = %+v, want it left open", active)
+	}
+	if len(forgotten) != batchSize+1 || !slices.Contains(forgotten, failing.ID.Hex()) {
+		t.Errorf("forgot %d conversations, want %d", len(forgotten), batchSize+1)
+	}
+	if len(remembered) != batchSize || remembered[store.convs[0].ID] != "You were planning a trip to Lisbon." {
+		t.Errorf("remembered %d conversations, want the %d summarized", len(remembered), batchSize)
+	}
+}
+
+func TestWindowFromEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: DefaultWindow},
+		{value: "7", want: 7 * 24 * time.Hour},
+		{value: "0", want: 0},
+		{value: "-1", wantErr: true},
+		{value: "a week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("IDLE_CLOSE_DAYS", tt.value)
+			got, err := WindowFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WindowFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("WindowFromEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package inbound
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// MaxText bounds the text of an email handed to the parser.
+const MaxText = 30000
+
+// maxDepth bounds the nesting of MIME parts, forwarded emails being nested in others.
+const maxDepth = 5
+
+// Email is an inbound email, reduced to what reservations are extracted from.
+type Email struct {
+	MessageID string
+	From      string
+	// To lists the recipients, forwarding addresses among them
+	To      []string
+	Subject string
+	// Text is the text of the email and of the emails it forwards, as plain text
+	Text string
+}
+
+// ParseEmail parses a raw RFC 5322 email. Confirmations forwarded as attachments are
+// read as part of the email.
+func ParseEmail(r io.Reader) (*Email, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email: %w", err)
+	}
+
+	dec := new(mime.WordDecoder)
+	header := func(name string) string {
+		v := msg.Header.Get(name)
+		if d, err := dec.DecodeHeader(v); err == nil {
+			return d
+		}
+		return v
+	}
+
+	e := &Email{
+		MessageID: strings.Trim(msg.Header.Get("Message-Id"), " <>"),
+		From:      header("From"),
+		Subject:   header("Subject"),
+	}
+	for _, name := range []string{"To", "Cc", "Delivered-To", "X-Original-To", "X-Forwarded-To"} {
+		e.To = append(e.To, msg.Header[name]...)
+	}
+
+	var texts []string
+	if err := readPart(msg.Header, msg.Body, 0, &texts); err != nil {
+		return nil, err
+	}
+	e.Text = strings.Join(texts, "\n\n")
+	e.Text = strings.TrimSpace(e.Text)
+	if len(e.Text) > MaxText {
+		e.Text = strings.ToValidUTF8(e.Text[:MaxText], "")
+	}
+	if e.Text == "" {
+		return nil, errors.New("email has no text")
+	}
+	return e, nil
+}
+
+// header is the part of the headers of emails and their MIME parts read.
+type header interface {
+	Get(key string) string
+}
+
+// readPart collects the texts of a part, decoding its transfer encoding. Of
+// alternatives, the plain text is preferred to the HTML.
+func readPart(h header, body io.Reader, depth int, texts *[]string) error {
+	if depth > maxDepth {
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	switch strings.ToLower(h.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		var alternatives [][]string
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart email: %w", err)
+			}
+			// The multipart reader decodes quoted-printable parts itself
+			var part []string
+			if err := readPart(p.Header, p, depth+1, &part); err != nil {
+				return err
+			}
+			if mediaType != "multipart/alternative" {
+				*texts = append(*texts, part...)
+			} else if len(part) > 0 {
+				alternatives = append(alternatives, part)
+			}
+		}
+		// Alternatives come in increasing order of preference, plain text first
+		if len(alternatives) > 0 {
+			*texts = append(*texts, alternatives[0]...)
+		}
+	case mediaType == "message/rfc822":
+		msg, err := mail.ReadMessage(body)
+		if err != nil {
+			return nil
+		}
+		if s := msg.Header.Get("Subject"); s != "" {
+			*texts = append(*texts, "Subject: "+s)
+		}
+		return readPart(msg.Header, msg.Body, depth+1, texts)
+	case mediaType == "text/plain", mediaType == "text/html":
+		b, err := io.ReadAll(io.LimitReader(body, 4*MaxText))
+		if err != nil {
+			return fmt.Errorf("failed to read email: %w", err)
+		}
+		text := string(b)
+		if mediaType == "text/html" {
+			text = htmlText(text)
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			*texts = append(*texts, text)
+		}
+	}
+	return nil
+}
+
+var (
+	htmlDropped = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)\s*>`)
+	htmlBreaks  = regexp.MustCompile(`(?i)<(br|/p|/div|/tr|/li|/h[1-6])\b[^>]*>`)
+	htmlTags    = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankRuns   = regexp.MustCompile(`[ \t\r\f\v]+`)
+	lineSpace   = regexp.MustCompile(`\s*\n\s*`)
+)
+
+// htmlText returns the text of an HTML email, keeping its line breaks.
+func htmlText(s string) string {
+	s = htmlDropped.ReplaceAllString(s, "")
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = htmlTags.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	s = blankRuns.ReplaceAllString(s, " ")
+	return strings.TrimSpace(lineSpace.ReplaceAllString(s, "\n"))
+}
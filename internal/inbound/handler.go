@@ -0,0 +1,67 @@
+package inbound
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/logging"
+)
+
+// Handler receives the emails routed by the mail provider, as raw RFC 5322 messages
+// POSTed with the token of cfg as bearer token or basic auth password, and hands them to
+// the ingester of the tenant of their forwarding address, the default tenant's under "".
+// Emails that can't be ingested are acknowledged, so the provider doesn't retry them,
+// but for transient failures.
+func Handler(cfg *Config, ingesters map[string]*Ingester) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			_, token, _ = r.BasicAuth()
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := r.Context()
+		e, err := ParseEmail(r.Body)
+		if err != nil {
+			slog.InfoContext(ctx, "Rejected inbound email", "error", err)
+			writeResult(w, &Result{Ignored: "unreadable email"})
+			return
+		}
+
+		tenantID, id, err := cfg.Recipient(e.To)
+		ingester, ok := ingesters[tenantID]
+		if err != nil || !ok {
+			slog.InfoContext(ctx, "Inbound email to an unknown address", "message_id", e.MessageID)
+			writeResult(w, &Result{Ignored: "unknown address"})
+			return
+		}
+		ctx = logging.WithConversationID(ctx, id.Hex())
+
+		res, err := ingester.Ingest(ctx, id, e)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to ingest inbound email", "message_id", e.MessageID, "error", err)
+			http.Error(w, "Failed to ingest email", http.StatusServiceUnavailable)
+			return
+		}
+		slog.InfoContext(ctx, "Inbound email ingested", "added", res.Added, "rejected", res.Rejected, "ignored", res.Ignored)
+		writeResult(w, res)
+	})
+}
+
+func writeResult(w http.ResponseWriter, res *Result) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
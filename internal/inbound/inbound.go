@@ -0,0 +1,103 @@
+// Package inbound ingests the booking confirmations users forward by email. Each trip,
+// a conversation, has its own forwarding address; the reservations of the emails sent to
+// it are extracted by a model, validated, and added to the itinerary of the trip.
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrUnknownAddress is returned for addresses not issued by Addresses.
+var ErrUnknownAddress = errors.New("unknown forwarding address")
+
+// Config configures the ingestion of emails.
+type Config struct {
+	// Domain receives the emails, routed by the mail provider to Handler
+	Domain string
+	// Token authenticates the mail provider, and signs forwarding addresses
+	Token string
+}
+
+// ConfigFromEnv returns the configuration of INBOUND_EMAIL_DOMAIN and INBOUND_EMAIL_TOKEN,
+// nil without a domain, which disables ingestion.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		Domain: strings.ToLower(strings.TrimSpace(os.Getenv("INBOUND_EMAIL_DOMAIN"))),
+		Token:  os.Getenv("INBOUND_EMAIL_TOKEN"),
+	}
+	if cfg.Domain == "" {
+		return nil, nil
+	}
+	if len(cfg.Token) < 16 {
+		return nil, errors.New("INBOUND_EMAIL_DOMAIN requires an INBOUND_EMAIL_TOKEN of at least 16 characters")
+	}
+	return cfg, nil
+}
+
+// Address returns the forwarding address of the trip of a conversation of a tenant, of
+// the form "trip-<conversation id>-<signature>[.<tenant id>]@<domain>" so addresses
+// can't be guessed.
+func (c *Config) Address(tenantID string, conversationID primitive.ObjectID) string {
+	local := "trip-" + conversationID.Hex() + "-" + c.signature(tenantID, conversationID)
+	if tenantID != "" {
+		local += "." + tenantID
+	}
+	return local + "@" + c.Domain
+}
+
+// Conversation returns the tenant and conversation of a forwarding address, given with
+// or without a display name.
+func (c *Config) Conversation(address string) (string, primitive.ObjectID, error) {
+	address = strings.ToLower(strings.TrimSpace(address))
+	if i := strings.LastIndex(address, "<"); i >= 0 {
+		address = strings.TrimSuffix(address[i+1:], ">")
+	}
+	local, domain, ok := strings.Cut(address, "@")
+	if !ok || domain != c.Domain {
+		return "", primitive.NilObjectID, ErrUnknownAddress
+	}
+
+	rest, ok := strings.CutPrefix(local, "trip-")
+	if !ok {
+		return "", primitive.NilObjectID, ErrUnknownAddress
+	}
+	rest, tenantID, _ := strings.Cut(rest, ".")
+	idHex, sig, ok := strings.Cut(rest, "-")
+	if !ok {
+		return "", primitive.NilObjectID, ErrUnknownAddress
+	}
+	id, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil || !hmac.Equal([]byte(sig), []byte(c.signature(tenantID, id))) {
+		return "", primitive.NilObjectID, ErrUnknownAddress
+	}
+	return tenantID, id, nil
+}
+
+// signature is lowercase, as mail servers may change the case of addresses, and
+// truncated to keep addresses short.
+func (c *Config) signature(tenantID string, conversationID primitive.ObjectID) string {
+	mac := hmac.New(sha256.New, []byte(c.Token))
+	mac.Write([]byte("inbound-address." + tenantID + "." + conversationID.Hex()))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Recipient returns the tenant and conversation of the first of the addresses issued
+// by c.
+func (c *Config) Recipient(addresses []string) (string, primitive.ObjectID, error) {
+	for _, a := range addresses {
+		for _, part := range strings.Split(a, ",") {
+			if tenantID, id, err := c.Conversation(part); err == nil {
+				return tenantID, id, nil
+			}
+		}
+	}
+	return "", primitive.NilObjectID, fmt.Errorf("%w among %d recipients", ErrUnknownAddress, len(addresses))
+}
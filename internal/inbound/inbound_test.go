@@ -0,0 +1,204 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/acai-travel/tech-challenge/internal/notify"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestConfig_Address(t *testing.T) {
+	cfg := &Config{Domain: "in.acai.travel", Token: "0123456789abcdef"}
+	id := primitive.NewObjectID()
+
+	for _, tenantID := range []string{"", "nordic-trails"} {
+		address := cfg.Address(tenantID, id)
+		gotTenant, gotID, err := cfg.Conversation("My trip <" + strings.ToUpper(address) + ">")
+		if err != nil || gotTenant != tenantID || gotID != id {
+			t.Errorf("Conversation(%q) = %q, %v, %v", address, gotTenant, gotID, err)
+		}
+	}
+
+	// Addresses can't be forged for other conversations or tenants
+	forged := strings.Replace(cfg.Address("", id), id.Hex(), primitive.NewObjectID().Hex(), 1)
+	if _, _, err := cfg.Conversation(forged); !errors.Is(err, ErrUnknownAddress) {
+		t.Errorf("Conversation(%q) = %v, want ErrUnknownAddress", forged, err)
+	}
+	if _, _, err := cfg.Conversation(strings.Replace(cfg.Address("", id), "@", ".nordic-trails@", 1)); !errors.Is(err, ErrUnknownAddress) {
+		t.Errorf("Conversation() of another tenant = %v, want ErrUnknownAddress", err)
+	}
+
+	if tenantID, got, err := cfg.Recipient([]string{"alice@example.com", "bob@example.com, " + cfg.Address("", id)}); err != nil || tenantID != "" || got != id {
+		t.Errorf("Recipient() = %q, %v, %v", tenantID, got, err)
+	}
+}
+
+const forwarded = "From: Alice <alice@example.com>\r\n" +
+	"To: trips@example.com\r\n" +
+	"Subject: =?UTF-8?Q?Fwd:_Your_booking_=E2=9C=88?=\r\n" +
+	"Message-ID: <abc@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"See below.\r\n" +
+	"--outer\r\n" +
+	"Content-Type: message/rfc822\r\n" +
+	"\r\n" +
+	"Subject: Booking TP1039\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"PGh0bWw+PGhlYWQ+PHN0eWxlPnB7fTwvc3R5bGU+PC9oZWFkPjxwPkZsaWdodCBUUDEwMzk8L3A+\r\n" +
+	"PHA+QkNOICZhbXA7IExJUzwvcD48L2h0bWw+\r\n" +
+	"--outer--\r\n"
+
+func TestParseEmail(t *testing.T) {
+	e, err := ParseEmail(strings.NewReader(forwarded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.MessageID != "abc@example.com" || e.Subject != "Fwd: Your booking ✈" || len(e.To) != 1 {
+		t.Errorf("ParseEmail() = %+v", e)
+	}
+	// The forwarded confirmation follows the text of the forward
+	if e.Text != "See below.\n\nSubject: Booking TP1039\n\nFlight TP1039\nBCN & LIS" {
+		t.Errorf("ParseEmail() text = %q", e.Text)
+	}
+
+	alternative := "Subject: Booking\r\n" +
+		"Content-Type: multipart/alternative; boundary=alt\r\n" +
+		"\r\n" +
+		"--alt\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"Flight TP1039 BCN =E2=86=92 LIS\r\n" +
+		"--alt\r\n" +
+		"Content-Type: text/html\r\n" +
+		"\r\n" +
+		"<p>Flight TP1039 BCN &rarr; LIS</p>\r\n" +
+		"--alt--\r\n"
+	e, err = ParseEmail(strings.NewReader(alternative))
+	if err != nil || e.Text != "Flight TP1039 BCN → LIS" {
+		t.Errorf("ParseEmail() of alternatives = %q, %v, want the plain text only", e.Text, err)
+	}
+}
+
+func TestReservation_Item(t *testing.T) {
+	r := Reservation{Kind: "flight", Title: "Flight TP1039 BCN → LIS", StartAt: "2025-10-18T10:05", Timezone: "Europe/Madrid",
+		EndAt: "2025-10-18T10:55", EndTimezone: "Europe/Lisbon", Provider: "TAP", Confirmation: "ABC123"}
+	item, err := r.Item(time.Now())
+	if err != nil || item.StartAt.Format("15:04") != "08:05" || item.EndTimezone != "Europe/Lisbon" || item.Details != "TAP, confirmation ABC123" {
+		t.Errorf("Item() = %+v, %v", item, err)
+	}
+
+	for _, bad := range []Reservation{
+		{Title: "", StartAt: "2025-10-18T10:05", Timezone: "Europe/Madrid"},
+		{Title: "Fado", StartAt: "Saturday evening", Timezone: "Europe/Lisbon"},
+		{Title: "Fado", StartAt: "2025-10-18T21:00", Timezone: "Lisbon"},
+		{Title: "Fado", StartAt: "2025-10-18T21:00", Timezone: "Europe/Lisbon", EndAt: "2025-10-18T20:00"},
+	} {
+		if _, err := bad.Item(time.Now()); err == nil {
+			t.Errorf("Item() of %+v succeeded", bad)
+		}
+	}
+}
+
+type fakeConversations map[string]*model.Conversation
+
+func (f fakeConversations) DescribeConversation(_ context.Context, id string) (*model.Conversation, error) {
+	if c, ok := f[id]; ok {
+		return c, nil
+	}
+	return nil, twirp.NotFoundError("conversation not found")
+}
+
+type fakeParser []Reservation
+
+func (f fakeParser) Parse(context.Context, *Email) ([]Reservation, error) {
+	return f, nil
+}
+
+type memoryReceipts map[string]bool
+
+func (m memoryReceipts) Claim(_ context.Context, key string, _ time.Time) (bool, error) {
+	first := !m[key]
+	m[key] = true
+	return first, nil
+}
+
+type memoryItineraries map[primitive.ObjectID]*itinerary.Itinerary
+
+func (m memoryItineraries) DescribeItinerary(_ context.Context, id primitive.ObjectID) (*itinerary.Itinerary, error) {
+	return m[id], nil
+}
+
+func (m memoryItineraries) AddItem(_ context.Context, id primitive.ObjectID, userID string, item itinerary.Item) (*itinerary.Itinerary, error) {
+	if m[id] == nil {
+		m[id] = &itinerary.Itinerary{ConversationID: id, UserID: userID}
+	}
+	m[id].Items = append(m[id].Items, item)
+	return m[id], nil
+}
+
+type recordedEvents []notify.Event
+
+func (r *recordedEvents) Publish(_ context.Context, evt notify.Event) error {
+	*r = append(*r, evt)
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	cfg := &Config{Domain: "in.acai.travel", Token: "0123456789abcdef"}
+	conv := &model.Conversation{ID: primitive.NewObjectID(), UserID: "alice", Title: "Weekend in Lisbon"}
+	itineraries, events := memoryItineraries{}, &recordedEvents{}
+	parser := fakeParser{
+		{Kind: "flight", Title: "Flight TP1039 BCN → LIS", StartAt: "2025-10-18T10:05", Timezone: "Europe/Madrid"},
+		{Kind: "lodging", Title: "Hotel Alfama", StartAt: "sometime", Timezone: "Europe/Lisbon"},
+	}
+	ingester := NewIngester(fakeConversations{conv.ID.Hex(): conv}, itineraries, parser, memoryReceipts{}, events)
+	h := Handler(cfg, map[string]*Ingester{"": ingester})
+
+	post := func(to, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/inbound/email", strings.NewReader(strings.Replace(forwarded, "trips@example.com", to, 1)))
+		req.SetBasicAuth("api", token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post(cfg.Address("", conv.ID), "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST with a wrong token = %d", rec.Code)
+	}
+	if rec := post("trips@example.com", cfg.Token); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"ignored":"unknown address"`) {
+		t.Errorf("POST to an unknown address = %d %s", rec.Code, rec.Body)
+	}
+
+	rec := post(cfg.Address("", conv.ID), cfg.Token)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"added":1,"rejected":1`) {
+		t.Errorf("POST = %d %s, want the flight added and the hotel rejected", rec.Code, rec.Body)
+	}
+	if it := itineraries[conv.ID]; it == nil || len(it.Items) != 1 || it.UserID != "alice" {
+		t.Errorf("itinerary = %+v", it)
+	}
+	if len(*events) != 1 || (*events)[0].Type != notify.EventItineraryUpdated || (*events)[0].ConversationID != conv.ID.Hex() {
+		t.Errorf("published %+v, want the itinerary update", *events)
+	}
+
+	// Retried deliveries are ignored
+	if rec := post(cfg.Address("", conv.ID), cfg.Token); !strings.Contains(rec.Body.String(), `"ignored":"duplicate"`) || len(itineraries[conv.ID].Items) != 1 {
+		t.Errorf("POST again = %s", rec.Body)
+	}
+}
@@ -0,0 +1,131 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/acai-travel/tech-challenge/internal/logging"
+	"github.com/acai-travel/tech-challenge/internal/notify"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ConversationStore looks up the conversations emails are forwarded to.
+type ConversationStore interface {
+	DescribeConversation(ctx context.Context, id string) (*model.Conversation, error)
+}
+
+// Receipts remembers the emails ingested, as mail providers retry deliveries.
+type Receipts interface {
+	// Claim records the receipt of key, and reports whether it is the first.
+	Claim(ctx context.Context, key string, now time.Time) (bool, error)
+}
+
+// Publisher notifies users of the itineraries updated from their emails.
+type Publisher interface {
+	Publish(ctx context.Context, evt notify.Event) error
+}
+
+// Result is the outcome of ingesting an email.
+type Result struct {
+	ConversationID string `json:"conversation_id,omitempty"`
+	// Added counts the items added to the itinerary, Rejected the reservations that
+	// failed validation
+	Added    int `json:"added"`
+	Rejected int `json:"rejected,omitempty"`
+	// Ignored tells why nothing was done: unknown address, no reservations or duplicate
+	Ignored string `json:"ignored,omitempty"`
+}
+
+// Ingester adds the reservations of forwarded emails to the itineraries of the trips of
+// a tenant.
+type Ingester struct {
+	conversations ConversationStore
+	itineraries   itinerary.Store
+	parser        Parser
+	receipts      Receipts
+	events        Publisher
+	now           func() time.Time
+}
+
+func NewIngester(conversations ConversationStore, itineraries itinerary.Store, parser Parser, receipts Receipts, events Publisher) *Ingester {
+	return &Ingester{conversations: conversations, itineraries: itineraries, parser: parser, receipts: receipts, events: events, now: time.Now}
+}
+
+// Ingest adds the reservations of e to the itinerary of the trip of the conversation it
+// was forwarded to. Emails to unknown conversations, without reservations or already
+// ingested are ignored; errors are transient, and the email can be retried.
+func (in *Ingester) Ingest(ctx context.Context, id primitive.ObjectID, e *Email) (*Result, error) {
+	res := &Result{ConversationID: id.Hex()}
+	ctx = logging.WithConversationID(ctx, id.Hex())
+
+	conv, err := in.conversations.DescribeConversation(ctx, id.Hex())
+	if te, ok := err.(twirp.Error); ok && te.Code() == twirp.NotFound {
+		res.Ignored = "unknown address"
+		return res, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	reservations, err := in.parser.Parse(ctx, e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email: %w", err)
+	}
+	now := in.now()
+	var items []itinerary.Item
+	for _, r := range reservations {
+		item, err := r.Item(now)
+		if err != nil {
+			slog.InfoContext(ctx, "Rejected reservation of inbound email", "error", err)
+			res.Rejected++
+			continue
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		res.Ignored = "no reservations"
+		return res, nil
+	}
+
+	// Claimed once parsed, so emails failing to parse are retried
+	if e.MessageID != "" {
+		first, err := in.receipts.Claim(ctx, id.Hex()+"/"+e.MessageID, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record email: %w", err)
+		}
+		if !first {
+			res.Ignored = "duplicate"
+			return res, nil
+		}
+	}
+
+	for _, item := range items {
+		_, err := in.itineraries.AddItem(ctx, conv.ID, conv.UserID, item)
+		if errors.Is(err, itinerary.ErrTooManyItems) {
+			res.Rejected += len(items) - res.Added
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to add itinerary item: %w", err)
+		}
+		res.Added++
+	}
+
+	if res.Added > 0 && in.events != nil && conv.UserID != "" {
+		evt := notify.NewEvent(notify.EventItineraryUpdated, conv.UserID, map[string]any{
+			"message": fmt.Sprintf("The reservations of %q were added to the itinerary of %q.", e.Subject, conv.Title),
+			"added":   res.Added,
+		})
+		evt.ConversationID = conv.ID.Hex()
+		if err := in.events.Publish(ctx, evt); err != nil {
+			slog.WarnContext(ctx, "Failed to publish itinerary update", "error", err)
+		}
+	}
+	return res, nil
+}
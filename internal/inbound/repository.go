@@ -0,0 +1,53 @@
+package inbound
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const receiptCollection = "inbound_emails"
+
+// receiptTTL is how long receipts are kept, well beyond the retries of mail providers.
+const receiptTTL = 30 * 24 * time.Hour
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+// EnsureIndexes expires receipts after receiptTTL.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.conn.Collection(receiptCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "received_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(receiptTTL.Seconds())),
+	})
+	return err
+}
+
+func (r *Repository) Claim(ctx context.Context, key string, now time.Time) (bool, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.Claim")
+	defer span.End()
+
+	_, err := r.conn.Collection(receiptCollection).InsertOne(ctx, bson.M{"_id": key, "received_at": now})
+	if mongo.IsDuplicateKeyError(err) {
+		span.SetStatus(codes.Ok, "email already received")
+		return false, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to claim email")
+		return false, err
+	}
+
+	span.SetStatus(codes.Ok, "email claimed")
+	return true, nil
+}
@@ -0,0 +1,87 @@
+package itinerary
+
+import (
+	"strings"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+// ContentType is the media type of ICS files.
+const ContentType = "text/calendar; charset=utf-8"
+
+// icsLocalLayout is the format of local times of ICS files, qualified by a TZID.
+const icsLocalLayout = "20060102T150405"
+
+// ICS renders it as an iCalendar file named name, with an event per item. Times are
+// local to the timezone of each item, referenced by its IANA name like calendar apps
+// expect.
+func ICS(it *Itinerary, name string, now time.Time) string {
+	cal := ics.NewCalendarFor("Acai Travel")
+	cal.SetMethod(ics.MethodPublish)
+	if name != "" {
+		cal.SetXWRCalName(name)
+	}
+
+	for _, item := range it.Sorted() {
+		event := cal.AddEvent(item.ID.Hex() + "@acai.travel")
+		event.SetDtStampTime(now)
+		event.SetSummary(item.Title)
+		event.SetProperty(ics.ComponentPropertyCategories, strings.ToUpper(item.Kind))
+		if item.Location != "" {
+			event.SetLocation(item.Location)
+		}
+		if item.Details != "" {
+			event.SetDescription(item.Details)
+		}
+
+		setLocal(event, ics.ComponentPropertyDtStart, item.StartAt, item.Timezone)
+		if !item.EndAt.IsZero() {
+			tz := item.EndTimezone
+			if tz == "" {
+				tz = item.Timezone
+			}
+			setLocal(event, ics.ComponentPropertyDtEnd, item.EndAt, tz)
+		}
+	}
+
+	return cal.Serialize()
+}
+
+// setLocal sets property of event to t in the timezone tz, or in UTC when tz is unknown.
+func setLocal(event *ics.VEvent, property ics.ComponentProperty, t time.Time, tz string) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "" || loc == time.UTC {
+		event.SetProperty(property, t.UTC().Format(icsLocalLayout)+"Z")
+		return
+	}
+	event.SetProperty(property, t.In(loc).Format(icsLocalLayout), ics.WithTZID(loc.String()))
+}
+
+// maxFilename bounds the length of file names, before the extension.
+const maxFilename = 60
+
+// Filename returns the name of the ICS file of the itinerary titled title.
+func Filename(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	name := b.String()
+	if len(name) > maxFilename {
+		name = name[:maxFilename]
+	}
+	name = strings.TrimSuffix(name, "-")
+	if name == "" {
+		name = "itinerary"
+	}
+	return name + ".ics"
+}
@@ -0,0 +1,102 @@
+// Package itinerary keeps the plan of trips: the flights, stays, transfers and
+// activities settled on in a conversation, with the local time and timezone of each, so
+// they can be exported to calendars.
+package itinerary
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of items.
+const (
+	KindFlight   = "flight"
+	KindLodging  = "lodging"
+	KindTransfer = "transfer"
+	KindActivity = "activity"
+)
+
+// Kinds are the kinds of items.
+var Kinds = []string{KindFlight, KindLodging, KindTransfer, KindActivity}
+
+// MaxItems bounds the items of an itinerary.
+const MaxItems = 200
+
+// Itinerary is the itinerary of the trip of a conversation.
+type Itinerary struct {
+	ConversationID primitive.ObjectID `bson:"_id"`
+	UserID         string             `bson:"user_id"`
+	Items          []Item             `bson:"items"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
+
+// Item is a flight, stay, transfer or activity of a trip. Times are stored in UTC along
+// with the timezone they happen in: a flight departs in one timezone and lands in
+// another.
+type Item struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Kind     string             `bson:"kind"`
+	Title    string             `bson:"title"`
+	Location string             `bson:"location,omitempty"`
+	Details  string             `bson:"details,omitempty"`
+	StartAt  time.Time          `bson:"start_at"`
+	Timezone string             `bson:"timezone"`
+	// EndAt is zero for items without a known end, EndTimezone defaults to Timezone
+	EndAt       time.Time `bson:"end_at,omitempty"`
+	EndTimezone string    `bson:"end_timezone,omitempty"`
+	AddedAt     time.Time `bson:"added_at"`
+}
+
+// Store stores itineraries, one per conversation.
+type Store interface {
+	// DescribeItinerary returns the itinerary of the conversation, nil when it has none.
+	DescribeItinerary(ctx context.Context, conversationID primitive.ObjectID) (*Itinerary, error)
+	// AddItem adds an item to the itinerary of the conversation, creating it if needed.
+	AddItem(ctx context.Context, conversationID primitive.ObjectID, userID string, item Item) (*Itinerary, error)
+}
+
+// ValidKind reports whether kind is one of Kinds.
+func ValidKind(kind string) bool {
+	return slices.Contains(Kinds, kind)
+}
+
+// localLayouts are the accepted formats for local times.
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// ParseLocal parses a local time in the IANA timezone tz. Times with an explicit offset
+// (RFC3339) are converted to it.
+func ParseLocal(value, tz string) (time.Time, error) {
+	loc, err := time.LoadLocation(strings.TrimSpace(tz))
+	if err != nil || strings.TrimSpace(tz) == "" {
+		return time.Time{}, fmt.Errorf("unknown timezone %q, expected an IANA timezone like Europe/Lisbon", tz)
+	}
+
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected YYYY-MM-DDTHH:MM in the given timezone", value)
+}
+
+// Sorted returns the items of it by start time.
+func (it *Itinerary) Sorted() []Item {
+	items := slices.Clone(it.Items)
+	slices.SortStableFunc(items, func(a, b Item) int { return a.StartAt.Compare(b.StartAt) })
+	return items
+}
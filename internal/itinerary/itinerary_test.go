@@ -0,0 +1,135 @@
+package itinerary
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pdf"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseLocal(t *testing.T) {
+	got, err := ParseLocal("2025-10-18T14:30", "Europe/Lisbon")
+	if err != nil || got.UTC().Format(time.RFC3339) != "2025-10-18T13:30:00Z" {
+		t.Errorf("ParseLocal() = %v, %v", got, err)
+	}
+
+	got, err = ParseLocal("2025-10-18T14:30:00+02:00", "Europe/Lisbon")
+	if err != nil || got.Format("15:04 MST") != "13:30 WEST" {
+		t.Errorf("ParseLocal() with an offset = %v, %v", got, err)
+	}
+
+	for _, tc := range [][2]string{{"2025-10-18T14:30", ""}, {"2025-10-18T14:30", "Mars/Olympus"}, {"tomorrow", "UTC"}} {
+		if _, err := ParseLocal(tc[0], tc[1]); err == nil {
+			t.Errorf("ParseLocal(%q, %q) succeeded", tc[0], tc[1])
+		}
+	}
+}
+
+func TestICS(t *testing.T) {
+	depart, _ := ParseLocal("2025-10-18T10:05", "Europe/Madrid")
+	land, _ := ParseLocal("2025-10-18T10:55", "Europe/Lisbon")
+	checkIn, _ := ParseLocal("2025-10-18T15:00", "Europe/Lisbon")
+	flightID, hotelID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	it := &Itinerary{Items: []Item{
+		{ID: hotelID, Kind: KindLodging, Title: "Check-in, Hotel Alfama", Location: "Rua dos Remédios 1, Lisbon", StartAt: checkIn.UTC(), Timezone: "Europe/Lisbon"},
+		{ID: flightID, Kind: KindFlight, Title: "Flight TP1039 BCN → LIS", StartAt: depart.UTC(), Timezone: "Europe/Madrid", EndAt: land.UTC(), EndTimezone: "Europe/Lisbon", Details: "Booking ABC123"},
+	}}
+	got := strings.ReplaceAll(ICS(it, "Weekend in Lisbon", time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)), "\r\n", "\n")
+
+	for _, want := range []string{
+		"X-WR-CALNAME:Weekend in Lisbon\n",
+		"METHOD:PUBLISH\n",
+		"UID:" + flightID.Hex() + "@acai.travel\n",
+		"DTSTART;TZID=Europe/Madrid:20251018T100500\n",
+		"DTEND;TZID=Europe/Lisbon:20251018T105500\n",
+		"DTSTART;TZID=Europe/Lisbon:20251018T150000\n",
+		"SUMMARY:Check-in\\, Hotel Alfama\n",
+		"CATEGORIES:FLIGHT\n",
+		"DESCRIPTION:Booking ABC123\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ICS() lacks %q:\n%s", want, got)
+		}
+	}
+	// Events are in the order of the trip
+	if strings.Index(got, flightID.Hex()) > strings.Index(got, hotelID.Hex()) {
+		t.Errorf("ICS() lists the check-in before the flight:\n%s", got)
+	}
+}
+
+func TestFilename(t *testing.T) {
+	for title, want := range map[string]string{
+		"Weekend in Lisbon!": "weekend-in-lisbon.ics",
+		"  ":                 "itinerary.ics",
+		"Porto & Douro 2025": "porto-douro-2025.ics",
+	} {
+		if got := Filename(title); got != want {
+			t.Errorf("Filename(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestPDF(t *testing.T) {
+	depart, _ := ParseLocal("2025-10-18T10:05", "Europe/Madrid")
+	land, _ := ParseLocal("2025-10-18T10:55", "Europe/Lisbon")
+	checkIn, _ := ParseLocal("2025-10-18T15:00", "Europe/Lisbon")
+	checkOut, _ := ParseLocal("2025-10-20T11:00", "Europe/Lisbon")
+
+	it := &Itinerary{Items: []Item{
+		{ID: primitive.NewObjectID(), Kind: KindLodging, Title: "Hotel Alfama", Location: "Rua dos Remédios 1, Lisbon", StartAt: checkIn.UTC(), Timezone: "Europe/Lisbon", EndAt: checkOut.UTC()},
+		{ID: primitive.NewObjectID(), Kind: KindFlight, Title: "Flight TP1039 BCN → LIS", StartAt: depart.UTC(), Timezone: "Europe/Madrid", EndAt: land.UTC(), EndTimezone: "Europe/Lisbon", Details: "Booking ABC123"},
+	}}
+	out := PDF(Trip{Title: "Weekend in Lisbon", Summary: "You planned a weekend in Lisbon.", Itinerary: it},
+		pdf.Branding{Name: "Nordic Trails"}, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+
+	if !bytes.HasPrefix(out, []byte("%PDF-")) {
+		t.Fatalf("PDF() is not a PDF file: %q", out[:10])
+	}
+	got := inflate(t, out)
+	for _, want := range []string{
+		"(Nordic Trails) Tj",
+		"(Weekend in Lisbon) Tj",
+		"(Sat 18 Oct 2025 to Sat 18 Oct 2025 \xb7 Printed on 1 October 2025) Tj",
+		"(You planned a weekend in Lisbon.) Tj",
+		"(SATURDAY 18 OCTOBER 2025) Tj",
+		"(10:05) Tj",
+		"(Flight TP1039 BCN -> LIS) Tj",
+		"(Until 10:55 \\(Europe/Lisbon\\)) Tj",
+		"(Until Mon 20 Oct, 11:00) Tj",
+		"(Rua dos Rem\xe9dios 1, Lisbon) Tj",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("PDF() lacks %q:\n%s", want, got)
+		}
+	}
+	// Items are in the order of the trip
+	if strings.Index(got, "TP1039") > strings.Index(got, "Hotel Alfama") {
+		t.Errorf("PDF() lists the check-in before the flight:\n%s", got)
+	}
+
+	if got := PDFFilename("Weekend in Lisbon!"); got != "weekend-in-lisbon.pdf" {
+		t.Errorf("PDFFilename() = %q", got)
+	}
+}
+
+// inflate returns the content streams of a PDF file.
+func inflate(t *testing.T, out []byte) string {
+	t.Helper()
+	var content strings.Builder
+	for _, m := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(out, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			t.Fatalf("invalid stream: %v", err)
+		}
+		b, _ := io.ReadAll(zr)
+		content.Write(b)
+	}
+	return content.String()
+}
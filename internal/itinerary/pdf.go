@@ -0,0 +1,105 @@
+package itinerary
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pdf"
+)
+
+// Trip is what the printable document of a trip shows.
+type Trip struct {
+	Title string
+	// Summary sums up what was planned in the conversation, if anything
+	Summary string
+	// Itinerary is nil for trips without one
+	Itinerary *Itinerary
+}
+
+var kindLabels = map[string]string{
+	KindFlight:   "Flight",
+	KindLodging:  "Stay",
+	KindTransfer: "Transfer",
+	KindActivity: "Activity",
+}
+
+// PDF renders trip as a printable document branded with brand: its summary, then its
+// itinerary day by day, in the local times of each item.
+func PDF(trip Trip, brand pdf.Branding, now time.Time) []byte {
+	title := trip.Title
+	if title == "" {
+		title = "Your trip"
+	}
+	doc := pdf.New(title, brand, now)
+	doc.Heading(title)
+
+	var items []Item
+	if trip.Itinerary != nil {
+		items = trip.Itinerary.Sorted()
+	}
+	note := "Printed on " + now.UTC().Format("2 January 2006")
+	if len(items) > 0 {
+		first, last := local(items[0].StartAt, items[0].Timezone), local(items[len(items)-1].StartAt, items[len(items)-1].Timezone)
+		note = first.Format("Mon 2 Jan 2006") + " to " + last.Format("Mon 2 Jan 2006") + " · " + note
+	}
+	doc.Note(note)
+
+	if trip.Summary != "" {
+		doc.Subheading("Summary")
+		doc.Paragraph(trip.Summary)
+	}
+
+	if len(items) > 0 {
+		doc.Subheading("Itinerary")
+	}
+	day := ""
+	for _, item := range items {
+		start := local(item.StartAt, item.Timezone)
+		if d := start.Format("Monday 2 January 2006"); d != day {
+			day = d
+			doc.Space(4)
+			doc.Note(strings.ToUpper(day))
+		}
+
+		lines := []string{kindLabels[item.Kind] + " · " + start.Location().String()}
+		if !item.EndAt.IsZero() {
+			tz := item.EndTimezone
+			if tz == "" {
+				tz = item.Timezone
+			}
+			end := local(item.EndAt, tz)
+			until := "Until " + end.Format("15:04")
+			if end.Format(time.DateOnly) != start.Format(time.DateOnly) {
+				until = "Until " + end.Format("Mon 2 Jan, 15:04")
+			}
+			if item.EndTimezone != "" {
+				until += fmt.Sprintf(" (%s)", end.Location())
+			}
+			lines = append(lines, until)
+		}
+		if item.Location != "" {
+			lines = append(lines, item.Location)
+		}
+		if item.Details != "" {
+			lines = append(lines, item.Details)
+		}
+		doc.Entry(start.Format("15:04"), item.Title, lines...)
+	}
+
+	return doc.Bytes()
+}
+
+// local returns t in the timezone tz, or in UTC when tz is unknown.
+func local(t time.Time, tz string) time.Time {
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "" {
+		return t.UTC()
+	}
+	return t.In(loc)
+}
+
+// PDFFilename returns the name of the PDF file of the trip titled title.
+func PDFFilename(title string) string {
+	return strings.TrimSuffix(Filename(title), ".ics") + ".pdf"
+}
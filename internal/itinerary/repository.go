@@ -0,0 +1,123 @@
+package itinerary
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName          = "github.com/acai-travel/tech-challenge/internal/itinerary"
+	itineraryCollection = "itineraries"
+)
+
+// ErrTooManyItems is returned adding an item to an itinerary with MaxItems already.
+var ErrTooManyItems = errors.New("the itinerary has too many items")
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) DescribeItinerary(ctx context.Context, conversationID primitive.ObjectID) (*Itinerary, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeItinerary")
+	span.SetAttributes(attribute.String("conversation.id", conversationID.Hex()))
+	defer span.End()
+
+	var it Itinerary
+	err := r.conn.Collection(itineraryCollection).FindOne(ctx, bson.M{"_id": conversationID}).Decode(&it)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "no itinerary")
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to describe itinerary")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "itinerary described")
+	return &it, nil
+}
+
+func (r *Repository) AddItem(ctx context.Context, conversationID primitive.ObjectID, userID string, item Item) (*Itinerary, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.AddItem")
+	span.SetAttributes(attribute.String("conversation.id", conversationID.Hex()))
+	defer span.End()
+
+	// A full itinerary doesn't match, so the upsert inserts a second document with its
+	// ID, which fails
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var it Itinerary
+	err := r.conn.Collection(itineraryCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": conversationID, "items." + strconv.Itoa(MaxItems-1): bson.M{"$exists": false}},
+		bson.M{
+			"$push":        bson.M{"items": item},
+			"$set":         bson.M{"updated_at": item.AddedAt},
+			"$setOnInsert": bson.M{"user_id": userID, "created_at": item.AddedAt},
+		}, opts).Decode(&it)
+	if mongo.IsDuplicateKeyError(err) {
+		err = ErrTooManyItems
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to add itinerary item")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "itinerary item added")
+	return &it, nil
+}
+
+// ListItineraries returns the itineraries of a user, oldest first.
+func (r *Repository) ListItineraries(ctx context.Context, userID string) ([]*Itinerary, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListItineraries")
+	span.SetAttributes(attribute.String("user.id", userID))
+	defer span.End()
+
+	cursor, err := r.conn.Collection(itineraryCollection).Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list itineraries")
+		return nil, err
+	}
+
+	var items []*Itinerary
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode itineraries")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "itineraries listed")
+	return items, nil
+}
+
+// CreateItinerary stores a whole itinerary, as restored from a takeout.
+func (r *Repository) CreateItinerary(ctx context.Context, it *Itinerary) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.CreateItinerary")
+	span.SetAttributes(attribute.String("conversation.id", it.ConversationID.Hex()))
+	defer span.End()
+
+	if _, err := r.conn.Collection(itineraryCollection).InsertOne(ctx, it); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create itinerary")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "itinerary created")
+	return nil
+}
@@ -0,0 +1,181 @@
+// Package jobs runs background jobs on cron schedules. Every replica runs a Scheduler,
+// but only the one elected leader runs jobs, and the state of each job is stored so
+// that a new leader carries on with the schedule of the previous one.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/lockx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// poll is how often a scheduler runs the due jobs, or tries to become leader.
+const poll = 30 * time.Second
+
+// Job is work to run on a schedule.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+}
+
+// State is the stored state of a job.
+type State struct {
+	Name string `bson:"_id" json:"name"`
+	// Schedule is the expression NextRunAt was computed with
+	Schedule       string     `bson:"schedule" json:"schedule"`
+	NextRunAt      time.Time  `bson:"next_run_at" json:"next_run_at"`
+	LastStartedAt  *time.Time `bson:"last_started_at,omitempty" json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `bson:"last_finished_at,omitempty" json:"last_finished_at,omitempty"`
+	LastError      string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+}
+
+// Store stores the states of jobs.
+type Store interface {
+	ListStates(ctx context.Context) ([]*State, error)
+	SaveState(ctx context.Context, st *State) error
+}
+
+// Scheduler runs jobs on their schedules while leader. Jobs run one at a time, and a
+// job that missed runs, as no replica was up, runs once.
+type Scheduler struct {
+	store  Store
+	locker lockx.Locker
+	key    string
+	jobs   []Job
+	poll   time.Duration
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewScheduler returns a scheduler of jobs, elected leader with the lock of key among
+// the schedulers sharing it.
+func NewScheduler(store Store, locker lockx.Locker, key string, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		store:  store,
+		locker: locker,
+		key:    key,
+		jobs:   jobs,
+		poll:   poll,
+		ttl:    lockx.DefaultTTL,
+		now:    time.Now,
+	}
+}
+
+// Run runs due jobs until ctx is cancelled, while leader.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.poll)
+	defer ticker.Stop()
+
+	var held context.Context
+	var release func()
+	defer func() {
+		if release != nil {
+			release()
+		}
+	}()
+
+	for {
+		if held != nil && held.Err() != nil {
+			slog.WarnContext(ctx, "Lost scheduler leadership", "key", s.key)
+			release()
+			held, release = nil, nil
+		}
+
+		if held == nil {
+			var ok bool
+			var err error
+			held, release, ok, err = lockx.Hold(ctx, s.locker, s.key, s.ttl)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to elect scheduler leader", "key", s.key, "error", err)
+			} else if ok {
+				slog.InfoContext(ctx, "Elected scheduler leader", "key", s.key)
+			}
+		}
+
+		if held != nil {
+			s.Tick(held)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick runs the jobs due at the time of the call. Jobs without a state yet, or whose
+// schedule changed, are scheduled from now on.
+func (s *Scheduler) Tick(ctx context.Context) {
+	states, err := s.store.ListStates(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list job states", "error", err)
+		return
+	}
+	byName := make(map[string]*State, len(states))
+	for _, st := range states {
+		byName[st.Name] = st
+	}
+
+	for _, job := range s.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		st := byName[job.Name]
+		if st == nil || st.Schedule != job.Schedule.String() {
+			if st == nil {
+				st = &State{Name: job.Name}
+			}
+			st.Schedule, st.NextRunAt = job.Schedule.String(), job.Schedule.Next(s.now())
+			if err := s.store.SaveState(ctx, st); err != nil {
+				slog.ErrorContext(ctx, "Failed to schedule job", "job", job.Name, "error", err)
+			}
+			continue
+		}
+
+		if st.NextRunAt.IsZero() || s.now().Before(st.NextRunAt) {
+			continue
+		}
+		s.run(ctx, job, st)
+	}
+}
+
+// run runs job and stores its outcome, with its next run.
+func (s *Scheduler) run(ctx context.Context, job Job, st *State) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Scheduler.run")
+	span.SetAttributes(attribute.String("job.name", job.Name))
+	defer span.End()
+
+	started := s.now()
+	st.LastStartedAt = &started
+	if err := s.store.SaveState(ctx, st); err != nil {
+		slog.ErrorContext(ctx, "Failed to save job state", "job", job.Name, "error", err)
+	}
+
+	slog.InfoContext(ctx, "Running job", "job", job.Name)
+	err := job.Run(ctx)
+
+	finished := s.now()
+	st.LastFinishedAt, st.LastError = &finished, ""
+	if err != nil {
+		st.LastError = err.Error()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "job failed")
+		slog.ErrorContext(ctx, "Job failed", "job", job.Name, "error", err)
+	} else {
+		span.SetStatus(codes.Ok, "job ran")
+	}
+
+	// The next run is after the end of this one, skipping the runs it outlasted
+	st.NextRunAt = job.Schedule.Next(finished)
+	if err := s.store.SaveState(context.WithoutCancel(ctx), st); err != nil {
+		slog.ErrorContext(ctx, "Failed to save job state", "job", job.Name, "error", err)
+	}
+}
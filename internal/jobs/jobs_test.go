@@ -0,0 +1,153 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/lockx"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2025, 1, 15, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 10, 18, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"30 9-17/4 * * *", time.Date(2025, 1, 15, 13, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 3 1,20 * *", time.Date(2025, 1, 20, 3, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 0 31 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) = %v", tt.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next() of %q = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@often"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded", expr)
+		}
+	}
+}
+
+// memoryStore stores job states in memory.
+type memoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+func (m *memoryStore) ListStates(context.Context) ([]*State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var states []*State
+	for _, st := range m.states {
+		st := st
+		states = append(states, &st)
+	}
+	return states, nil
+}
+
+func (m *memoryStore) SaveState(_ context.Context, st *State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[st.Name] = *st
+	return nil
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	ctx := context.Background()
+	hourly, _ := ParseSchedule("@hourly")
+	now := time.Date(2025, 1, 15, 10, 17, 0, 0, time.UTC)
+
+	var ran []string
+	job := func(name string, err error) Job {
+		return Job{Name: name, Schedule: hourly, Run: func(context.Context) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+	store := &memoryStore{states: map[string]State{}}
+	s := NewScheduler(store, lockx.NewLocal(), "leader", job("analytics", nil), job("idle", errors.New("boom")))
+	s.now = func() time.Time { return now }
+
+	s.Tick(ctx)
+	if len(ran) != 0 || !store.states["analytics"].NextRunAt.Equal(time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("first tick ran %v, states %+v, want the jobs scheduled at the next hour", ran, store.states)
+	}
+
+	// Down for hours, missed runs run once
+	now = now.Add(3 * time.Hour)
+	s.Tick(ctx)
+	s.Tick(ctx)
+	if len(ran) != 2 {
+		t.Errorf("ran %v, want each job once", ran)
+	}
+	if st := store.states["analytics"]; st.LastFinishedAt == nil || st.LastError != "" || !st.NextRunAt.Equal(time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("analytics state = %+v, want it run and scheduled at the next hour", st)
+	}
+	if st := store.states["idle"]; st.LastError != "boom" {
+		t.Errorf("idle state = %+v, want its error", st)
+	}
+
+	// A changed schedule applies from now on
+	daily, _ := ParseSchedule("@daily")
+	s.jobs[0].Schedule = daily
+	now = now.Add(time.Hour)
+	s.Tick(ctx)
+	if st := store.states["analytics"]; st.Schedule != "@daily" || !st.NextRunAt.Equal(time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("analytics state = %+v, want it rescheduled daily", st)
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	every, _ := ParseSchedule("* * * * *")
+	store := &memoryStore{states: map[string]State{
+		"job": {Name: "job", Schedule: "* * * * *", NextRunAt: time.Now().Add(-time.Minute)},
+	}}
+
+	// Another replica is leader
+	locker := lockx.NewLocal()
+	_, _ = locker.TryLock(ctx, "leader", "other", time.Minute)
+
+	runs := make(chan struct{}, 10)
+	s := NewScheduler(store, locker, "leader", Job{Name: "job", Schedule: every, Run: func(context.Context) error {
+		runs <- struct{}{}
+		return nil
+	}})
+	s.poll = 10 * time.Millisecond
+	go s.Run(ctx)
+
+	select {
+	case <-runs:
+		t.Fatal("job ran while another replica was leader")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_ = locker.Unlock(ctx, "leader", "other")
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("job didn't run once the scheduler could become leader")
+	}
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName    = "github.com/acai-travel/tech-challenge/internal/jobs"
+	jobCollection = "jobs"
+)
+
+// Repository stores the states of jobs, one document per job.
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) ListStates(ctx context.Context) ([]*State, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListStates")
+	defer span.End()
+
+	cursor, err := r.conn.Collection(jobCollection).Find(ctx, bson.M{})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list job states")
+		return nil, err
+	}
+
+	var states []*State
+	if err := cursor.All(ctx, &states); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode job states")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "job states listed")
+	return states, nil
+}
+
+func (r *Repository) SaveState(ctx context.Context, st *State) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SaveState")
+	span.SetAttributes(attribute.String("job.name", st.Name))
+	defer span.End()
+
+	if _, err := r.conn.Collection(jobCollection).ReplaceOne(ctx, bson.M{"_id": st.Name}, st, options.Replace().SetUpsert(true)); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to save job state")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "job state saved")
+	return nil
+}
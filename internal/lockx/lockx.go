@@ -0,0 +1,143 @@
+// Package lockx provides locks shared by every replica of a deployment, so that only
+// one of them replies in a conversation or runs a background job at a time. Locks are
+// leases: they expire unless renewed, so a replica that dies doesn't keep them.
+package lockx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultTTL is how long the lock of a replica that died blocks the others, for locks
+// renewed while held.
+const DefaultTTL = time.Minute
+
+// Locker takes named locks.
+type Locker interface {
+	// TryLock takes the lock of key for owner until ttl from now, reporting false when
+	// another owner holds it. Its owner can take it again to extend it.
+	TryLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// Unlock releases the lock of key, if owner still holds it.
+	Unlock(ctx context.Context, key, owner string) error
+}
+
+// RedisFromEnv returns a Redis locker for the URL in REDIS_URL, like
+// redis://:password@host:6379/0, or nil when unset for locks to be stored in MongoDB.
+func RedisFromEnv() (*Redis, error) {
+	v := os.Getenv("REDIS_URL")
+	if v == "" {
+		return nil, nil
+	}
+
+	r, err := NewRedis(v)
+	if err != nil {
+		return nil, fmt.Errorf("REDIS_URL: %w", err)
+	}
+	return r, nil
+}
+
+// Hold takes the lock of key and renews it every third of ttl until released. The
+// returned context is cancelled once the lock is lost to another owner, which happens
+// when it couldn't be renewed in time, for the work it guards to stop.
+func Hold(ctx context.Context, l Locker, key string, ttl time.Duration) (held context.Context, release func(), ok bool, err error) {
+	owner := primitive.NewObjectID().Hex()
+	ok, err = l.TryLock(ctx, key, owner, ttl)
+	if err != nil || !ok {
+		return nil, nil, false, err
+	}
+
+	held, lost := context.WithCancel(ctx)
+	renewCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			ok, err := l.TryLock(renewCtx, key, owner, ttl)
+			if err != nil {
+				// Retried on the next tick, before the lock expires
+				slog.WarnContext(renewCtx, "Failed to renew lock", "key", key, "error", err)
+				continue
+			}
+			if !ok {
+				slog.ErrorContext(renewCtx, "Lost lock to another owner", "key", key)
+				lost()
+				return
+			}
+		}
+	}()
+
+	release = func() {
+		stop()
+		<-done
+		lost()
+		if err := l.Unlock(context.WithoutCancel(ctx), key, owner); err != nil {
+			slog.WarnContext(ctx, "Failed to release lock", "key", key, "error", err)
+		}
+	}
+	return held, release, true, nil
+}
+
+// Do runs fn while holding the lock of key, unless another owner holds it. It reports
+// whether fn ran. fn's context is cancelled if the lock is lost.
+func Do(ctx context.Context, l Locker, key string, ttl time.Duration, fn func(ctx context.Context)) (bool, error) {
+	held, release, ok, err := Hold(ctx, l, key, ttl)
+	if err != nil || !ok {
+		return false, err
+	}
+	defer release()
+
+	fn(held)
+	return true, nil
+}
+
+// Local locks within the process, for deployments of a single replica and tests.
+type Local struct {
+	mu    sync.Mutex
+	locks map[string]localLock
+	now   func() time.Time
+}
+
+type localLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+func NewLocal() *Local {
+	return &Local{locks: make(map[string]localLock), now: time.Now}
+}
+
+func (l *Local) TryLock(_ context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if held, ok := l.locks[key]; ok && held.owner != owner && held.expiresAt.After(now) {
+		return false, nil
+	}
+	l.locks[key] = localLock{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (l *Local) Unlock(_ context.Context, key, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.locks[key].owner == owner {
+		delete(l.locks, key)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package lockx
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName     = "github.com/acai-travel/tech-challenge/internal/lockx"
+	lockCollection = "locks"
+)
+
+// Mongo locks with documents of the locks collection, one per key.
+type Mongo struct {
+	conn *mongo.Database
+}
+
+func NewMongo(conn *mongo.Database) *Mongo {
+	return &Mongo{conn: conn}
+}
+
+// EnsureIndexes creates the index deleting expired locks. Expired locks can be taken
+// over before they are deleted.
+func (m *Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.conn.Collection(lockCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (m *Mongo) TryLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Mongo.TryLock")
+	span.SetAttributes(attribute.String("lock.key", key))
+	defer span.End()
+
+	// A lock held by another owner doesn't match, so the upsert inserts a second
+	// document with its key, which fails
+	now := time.Now()
+	_, err := m.conn.Collection(lockCollection).UpdateOne(ctx,
+		bson.M{"_id": key, "$or": bson.A{
+			bson.M{"owner": owner},
+			bson.M{"expires_at": bson.M{"$lte": now}},
+		}},
+		bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(ttl)}},
+		options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		span.SetAttributes(attribute.Bool("lock.taken", false))
+		span.SetStatus(codes.Ok, "lock held by another owner")
+		return false, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to take lock")
+		return false, err
+	}
+
+	span.SetAttributes(attribute.Bool("lock.taken", true))
+	span.SetStatus(codes.Ok, "lock taken")
+	return true, nil
+}
+
+func (m *Mongo) Unlock(ctx context.Context, key, owner string) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Mongo.Unlock")
+	span.SetAttributes(attribute.String("lock.key", key))
+	defer span.End()
+
+	if _, err := m.conn.Collection(lockCollection).DeleteOne(ctx, bson.M{"_id": key, "owner": owner}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to release lock")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "lock released")
+	return nil
+}
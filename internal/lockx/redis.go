@@ -0,0 +1,252 @@
+package lockx
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// redisTimeout bounds a command when its context has no deadline.
+const redisTimeout = 5 * time.Second
+
+// Scripts compare the owner of a lock before changing it, atomically.
+const (
+	tryLockScript = `local v = redis.call('GET', KEYS[1])
+if v == false or v == ARGV[1] then
+  redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+  return 1
+end
+return 0`
+	unlockScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0`
+)
+
+// Redis locks with keys expiring on a Redis server, prefixed with "lock:". It speaks
+// just enough of the Redis protocol for that, over one connection at a time.
+type Redis struct {
+	addr     string
+	tls      bool
+	username string
+	password string
+	db       int
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// NewRedis configures a Redis locker from a redis:// or rediss:// (TLS) URL, with an
+// optional user, password and database number. It connects on first use.
+func NewRedis(rawURL string) (*Redis, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("scheme must be redis or rediss, got %q", u.Scheme)
+	}
+
+	r := &Redis{addr: u.Host, tls: u.Scheme == "rediss"}
+	if u.Port() == "" {
+		r.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if r.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("database must be a number, got %q", db)
+		}
+	}
+	return r, nil
+}
+
+func (r *Redis) TryLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Redis.TryLock")
+	span.SetAttributes(attribute.String("lock.key", key))
+	defer span.End()
+
+	v, err := r.do(ctx, "EVAL", tryLockScript, "1", "lock:"+key, owner, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to take lock")
+		return false, err
+	}
+
+	taken := v == int64(1)
+	span.SetAttributes(attribute.Bool("lock.taken", taken))
+	span.SetStatus(codes.Ok, "lock tried")
+	return taken, nil
+}
+
+func (r *Redis) Unlock(ctx context.Context, key, owner string) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Redis.Unlock")
+	span.SetAttributes(attribute.String("lock.key", key))
+	defer span.End()
+
+	if _, err := r.do(ctx, "EVAL", unlockScript, "1", "lock:"+key, owner); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to release lock")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "lock released")
+	return nil
+}
+
+// Close closes the connection, if any.
+func (r *Redis) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.conn, r.rd = nil, nil
+	return err
+}
+
+// do sends a command and reads its reply, connecting first if needed. The connection
+// is dropped on errors other than Redis errors, to start over on the next command.
+func (r *Redis) do(ctx context.Context, args ...string) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+
+	if r.conn == nil {
+		if err := r.connect(ctx, deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := r.roundTrip(deadline, args)
+	var rerr redisError
+	if err != nil && !errors.As(err, &rerr) {
+		_ = r.conn.Close()
+		r.conn, r.rd = nil, nil
+	}
+	return v, err
+}
+
+func (r *Redis) connect(ctx context.Context, deadline time.Time) error {
+	d := net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	var err error
+	if r.tls {
+		host, _, _ := net.SplitHostPort(r.addr)
+		conn, err = (&tls.Dialer{NetDialer: &d, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", r.addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", r.addr)
+	}
+	if err != nil {
+		return err
+	}
+	r.conn, r.rd = conn, bufio.NewReader(conn)
+
+	var setup [][]string
+	if r.password != "" {
+		if r.username != "" {
+			setup = append(setup, []string{"AUTH", r.username, r.password})
+		} else {
+			setup = append(setup, []string{"AUTH", r.password})
+		}
+	}
+	if r.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(r.db)})
+	}
+	for _, args := range setup {
+		if _, err := r.roundTrip(deadline, args); err != nil {
+			_ = conn.Close()
+			r.conn, r.rd = nil, nil
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+	}
+	return nil
+}
+
+func (r *Redis) roundTrip(deadline time.Time, args []string) (any, error) {
+	if err := r.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := io.WriteString(r.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return readReply(r.rd)
+}
+
+// redisError is an error reply of the server.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// readReply reads a RESP reply: strings, integers, bulk strings (nil when null),
+// errors and arrays of them.
+func readReply(rd *bufio.Reader) (any, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch body := line[1:]; line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readReply(rd); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
@@ -0,0 +1,64 @@
+// Package logging adds request context to log records, so every line can be
+// correlated with its trace and conversation without passing IDs around.
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type conversationKey struct{}
+
+// WithConversationID attaches the conversation being worked on to ctx, so logs
+// written with it carry a conversation_id.
+func WithConversationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, conversationKey{}, id)
+}
+
+// ConversationID returns the conversation attached to ctx, or an empty string.
+func ConversationID(ctx context.Context) string {
+	id, _ := ctx.Value(conversationKey{}).(string)
+	return id
+}
+
+// Handler is a slog.Handler that adds trace_id, span_id, conversation_id and api_key_id
+// from the record's context to every record before passing it on.
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler wraps next with context attributes.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	if id := ConversationID(ctx); id != "" {
+		r.AddAttrs(slog.String("conversation_id", id))
+	}
+	if id := auth.APIKeyID(ctx); id != "" {
+		r.AddAttrs(slog.String("api_key_id", id))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
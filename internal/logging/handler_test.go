@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = WithConversationID(ctx, "68a5f0c2e4b0a1b2c3d4e5f6")
+	ctx = auth.WithAPIKeyID(ctx, "68f0a1b2c3d4e5f6a7b8c9d0")
+
+	logger.InfoContext(ctx, "hello")
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal log line: %v", err)
+	}
+	for key, want := range map[string]string{
+		"trace_id":        sc.TraceID().String(),
+		"span_id":         sc.SpanID().String(),
+		"conversation_id": "68a5f0c2e4b0a1b2c3d4e5f6",
+		"api_key_id":      "68f0a1b2c3d4e5f6a7b8c9d0",
+		"component":       "test",
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %q", key, got[key], want)
+		}
+	}
+
+	// Records without context carry no IDs
+	buf.Reset()
+	logger.Info("no context")
+	if bytes.Contains(buf.Bytes(), []byte("trace_id")) || bytes.Contains(buf.Bytes(), []byte("conversation_id")) {
+		t.Errorf("unexpected context attributes in %s", buf.String())
+	}
+}
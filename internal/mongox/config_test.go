@@ -0,0 +1,47 @@
+package mongox
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("MONGODB_MAX_POOL_SIZE", "50")
+	t.Setenv("MONGODB_TIMEOUT", "10s")
+	t.Setenv("MONGODB_READ_PREFERENCE", "secondaryPreferred")
+	t.Setenv("MONGODB_RETRY_WRITES", "false")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxPoolSize != 50 || cfg.ReplicaReadPreference != DefaultReplicaReadPreference || cfg.Timeout != 10*time.Second || cfg.SlowQuery != DefaultSlowQuery || cfg.RetryWrites == nil || *cfg.RetryWrites || cfg.RetryReads != nil {
+		t.Errorf("ConfigFromEnv() = %+v", cfg)
+	}
+
+	opts, err := cfg.clientOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *opts.MaxPoolSize != 50 || *opts.Timeout != 10*time.Second || opts.ReadPreference.Mode() != readpref.SecondaryPreferredMode || *opts.RetryWrites {
+		t.Errorf("client options don't follow the configuration: %+v", opts)
+	}
+
+	for name, value := range map[string]string{
+		"MONGODB_MIN_POOL_SIZE":           "80",
+		"MONGODB_CONNECT_TIMEOUT":         "soon",
+		"MONGODB_READ_PREFERENCE":         "closest",
+		"MONGODB_RETRY_READS":             "sometimes",
+		"MONGODB_SLOW_QUERY":              "-1s",
+		"MONGODB_REPLICA_READ_PREFERENCE": "replica",
+		"MONGODB_REPLICA_MAX_STALENESS":   "10s",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("ConfigFromEnv() accepted %s=%s", name, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package mongox
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/acai-travel/tech-challenge/internal/mongox"
+
+// commandKey identifies an in-flight command: request IDs are unique per connection.
+type commandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// command is an in-flight command.
+type command struct {
+	span trace.Span
+	// name is the name of its span
+	name string
+	// operation is the name of the span of the calling repository method, if any
+	operation string
+}
+
+// monitor traces every Mongo command as a client span, named "{collection}.{command}"
+// as otelmongo does, and nested under the span of the calling repository method.
+// Command documents are not recorded, as they may contain user data.
+type monitor struct {
+	tracer trace.Tracer
+	// slowQuery is the duration past which commands are logged, 0 logging none
+	slowQuery time.Duration
+	commands  sync.Map // commandKey -> command
+}
+
+// NewMonitor returns a command monitor that traces Mongo commands, and logs those
+// taking slowQuery or longer, unless it's 0.
+func NewMonitor(slowQuery time.Duration) *event.CommandMonitor {
+	m := &monitor{tracer: otel.Tracer(tracerName), slowQuery: slowQuery}
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+func (m *monitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNameMongoDB,
+		semconv.DBNamespace(evt.DatabaseName),
+		semconv.DBOperationName(evt.CommandName),
+	}
+
+	name := evt.CommandName
+	if collection := commandCollection(evt.Command, evt.CommandName); collection != "" {
+		name = collection + "." + evt.CommandName
+		attrs = append(attrs, semconv.DBCollectionName(collection))
+	}
+	if host, port, ok := connectionAddress(evt.ConnectionID); ok {
+		attrs = append(attrs, semconv.ServerAddress(host), semconv.ServerPort(port))
+	}
+
+	// Recording spans of the SDK know their name
+	var operation string
+	if parent, ok := trace.SpanFromContext(ctx).(interface{ Name() string }); ok {
+		operation = parent.Name()
+	}
+
+	_, span := m.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	m.commands.Store(commandKey{evt.ConnectionID, evt.RequestID}, command{span: span, name: name, operation: operation})
+}
+
+func (m *monitor) succeeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	if span, ok := m.finish(ctx, evt.CommandFinishedEvent, ""); ok {
+		span.SetStatus(codes.Ok, "")
+		span.End()
+	}
+}
+
+func (m *monitor) failed(ctx context.Context, evt *event.CommandFailedEvent) {
+	if span, ok := m.finish(ctx, evt.CommandFinishedEvent, evt.Failure); ok {
+		span.SetStatus(codes.Error, evt.Failure)
+		span.End()
+	}
+}
+
+// finish returns the span of a finished command, logging the command if it was slow.
+func (m *monitor) finish(ctx context.Context, evt event.CommandFinishedEvent, failure string) (trace.Span, bool) {
+	v, ok := m.commands.LoadAndDelete(commandKey{evt.ConnectionID, evt.RequestID})
+	if !ok {
+		return nil, false
+	}
+	cmd := v.(command)
+
+	if m.slowQuery > 0 && evt.Duration >= m.slowQuery {
+		attrs := []any{"command", cmd.name, "duration_ms", evt.Duration.Milliseconds()}
+		if cmd.operation != "" {
+			attrs = append(attrs, "operation", cmd.operation)
+		}
+		if failure != "" {
+			attrs = append(attrs, "failure", failure)
+		}
+		slog.WarnContext(ctx, "Slow Mongo command", attrs...)
+	}
+	return cmd.span, true
+}
+
+// commandCollection returns the collection a command runs on, which is the value of
+// the command's own key for collection commands (e.g. {"find": "conversations"}).
+func commandCollection(cmd bson.Raw, name string) string {
+	v, err := cmd.LookupErr(name)
+	if err != nil {
+		return ""
+	}
+	collection, _ := v.StringValueOK()
+	return collection
+}
+
+// connectionAddress extracts the server host and port from a driver connection ID,
+// formatted as "host:port[-n]".
+func connectionAddress(connectionID string) (string, int, bool) {
+	addr, _, _ := strings.Cut(connectionID, "[")
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, false
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, false
+	}
+	return host, port, true
+}
@@ -0,0 +1,106 @@
+package mongox
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMonitor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	m := &monitor{tracer: tracer}
+
+	ctx, parent := tracer.Start(context.Background(), "Repository.DescribeConversation")
+
+	cmd, _ := bson.Marshal(bson.D{{Key: "find", Value: "conversations"}, {Key: "filter", Value: bson.D{}}})
+	m.started(ctx, &event.CommandStartedEvent{
+		Command:      cmd,
+		DatabaseName: "acai",
+		CommandName:  "find",
+		RequestID:    7,
+		ConnectionID: "localhost:27017[-3]",
+	})
+	m.failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 7, ConnectionID: "localhost:27017[-3]"},
+		Failure:              "connection reset",
+	})
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	got := spans[0]
+
+	if got.Name() != "conversations.find" {
+		t.Errorf("span name = %q, want %q", got.Name(), "conversations.find")
+	}
+	if got.SpanKind() != trace.SpanKindClient {
+		t.Errorf("span kind = %v, want client", got.SpanKind())
+	}
+	if got.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("command span is not nested under the repository span")
+	}
+	if got.Status().Code != codes.Error || got.Status().Description != "connection reset" {
+		t.Errorf("status = %+v, want the failure", got.Status())
+	}
+
+	attrs := map[attribute.Key]string{}
+	for _, kv := range got.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	for key, want := range map[attribute.Key]string{
+		"db.system.name":     "mongodb",
+		"db.namespace":       "acai",
+		"db.collection.name": "conversations",
+		"db.operation.name":  "find",
+		"server.address":     "localhost",
+		"server.port":        "27017",
+	} {
+		if attrs[key] != want {
+			t.Errorf("%s = %q, want %q", key, attrs[key], want)
+		}
+	}
+}
+
+func TestMonitor_SlowQuery(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	tracer := sdktrace.NewTracerProvider().Tracer("test")
+	m := &monitor{tracer: tracer, slowQuery: 100 * time.Millisecond}
+	ctx, parent := tracer.Start(context.Background(), "Repository.ListConversations")
+	defer parent.End()
+
+	cmd, _ := bson.Marshal(bson.D{{Key: "find", Value: "conversations"}})
+	for id, d := range map[int64]time.Duration{1: 20 * time.Millisecond, 2: 250 * time.Millisecond} {
+		m.started(ctx, &event.CommandStartedEvent{Command: cmd, CommandName: "find", RequestID: id, ConnectionID: "localhost:27017[-1]"})
+		m.succeeded(ctx, &event.CommandSucceededEvent{
+			CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: id, ConnectionID: "localhost:27017[-1]", Duration: d},
+		})
+	}
+
+	logs := buf.String()
+	if strings.Count(logs, "Slow Mongo command") != 1 {
+		t.Fatalf("logs = %q, want the slow command only", logs)
+	}
+	for _, want := range []string{"command=conversations.find", "duration_ms=250", "operation=Repository.ListConversations"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs = %q, want %s", logs, want)
+		}
+	}
+}
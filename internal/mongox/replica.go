@@ -0,0 +1,26 @@
+package mongox
+
+import (
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// replicaPrefs are the read preferences of the heavy reads of the clients connected
+// by Connect.
+var replicaPrefs sync.Map // *mongo.Client -> *readpref.ReadPref
+
+// Replica returns db reading with the replica read preference of its client, for the
+// heavy reads that tolerate data a few seconds stale, like lists, searches and
+// aggregations, to spare the primary. Reads a write depends on, like those of a
+// conversation about to be updated, stay on db. Databases of clients not connected by
+// Connect, like those of tests, are returned as is.
+func Replica(db *mongo.Database) *mongo.Database {
+	v, ok := replicaPrefs.Load(db.Client())
+	if !ok {
+		return db
+	}
+	return db.Client().Database(db.Name(), options.Database().SetReadPreference(v.(*readpref.ReadPref)))
+}
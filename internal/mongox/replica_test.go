@@ -0,0 +1,44 @@
+package mongox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestReplica(t *testing.T) {
+	// Clients connect lazily, so none of this needs a server
+	db, err := Connect(Config{URI: "mongodb://localhost:27017", Database: "acai", ReplicaReadPreference: "secondaryPreferred", ReplicaMaxStaleness: 2 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Client().Disconnect(context.Background()) }()
+
+	replica := Replica(db)
+	if replica.Name() != "acai" || replica.Client() != db.Client() {
+		t.Errorf("Replica() = %s, want the same database of the same client", replica.Name())
+	}
+	if mode := replica.ReadPreference().Mode(); mode != readpref.SecondaryPreferredMode {
+		t.Errorf("replica reads from %v, want secondaryPreferred", mode)
+	}
+	if staleness, _ := replica.ReadPreference().MaxStaleness(); staleness != 2*time.Minute {
+		t.Errorf("replica max staleness = %v, want 2m", staleness)
+	}
+	if mode := db.ReadPreference().Mode(); mode != readpref.PrimaryMode {
+		t.Errorf("database reads from %v, want the primary", mode)
+	}
+
+	// Other clients read as they do
+	other, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = other.Disconnect(context.Background()) }()
+	if got := Replica(other.Database("acai")); got.ReadPreference().Mode() != readpref.PrimaryMode {
+		t.Errorf("Replica() of another client reads from %v, want the primary", got.ReadPreference().Mode())
+	}
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for webhook hosts that aren't on the public internet.
+var ErrPrivateAddress = errors.New("address is not public")
+
+// CheckURL parses a webhook URL, which must be https and resolve only to public addresses,
+// so webhooks can't be pointed at the service's own network. As DNS may change after
+// registration, deliveries check the addresses they connect to again.
+func CheckURL(ctx context.Context, raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return nil, errors.New("must be an absolute https URL")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return nil, fmt.Errorf("host %s: %w", u.Hostname(), ErrPrivateAddress)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, fmt.Errorf("host %s does not resolve", u.Hostname())
+	}
+	for _, addr := range addrs {
+		if !publicAddr(addr) {
+			return nil, fmt.Errorf("host %s: %w", u.Hostname(), ErrPrivateAddress)
+		}
+	}
+
+	return u, nil
+}
+
+// publicAddr reports whether addr is a public unicast address, that is neither loopback,
+// link-local, private nor unspecified.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
+
+// publicDialer dials public addresses only. It checks the address actually connected to,
+// after resolution, so that hosts re-pointed at private addresses aren't reached.
+func publicDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddr(addrPort.Addr()) {
+				return fmt.Errorf("dial %s: %w", address, ErrPrivateAddress)
+			}
+			return nil
+		},
+	}
+}
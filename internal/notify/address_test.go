@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://203.0.113.10/hooks/travel"},
+		{url: "http://203.0.113.10/hooks/travel", wantErr: true},
+		{url: "hooks/travel", wantErr: true},
+		{url: "https://localhost/hook", wantErr: true},
+		{url: "https://api.localhost./hook", wantErr: true},
+		{url: "https://127.0.0.1:8080/hook", wantErr: true},
+		{url: "https://[::1]/hook", wantErr: true},
+		{url: "https://0.0.0.0/hook", wantErr: true},
+		{url: "https://10.1.2.3/hook", wantErr: true},
+		{url: "https://192.168.1.20/hook", wantErr: true},
+		{url: "https://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "https://[fe80::1]/hook", wantErr: true},
+		{url: "https://[::ffff:10.0.0.1]/hook", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			_, err := CheckURL(context.Background(), tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckURL(%q) error = %v, want error %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/acai-travel/tech-challenge/internal/profile"
+)
+
+// Names of the built-in channels, as stored in profile preferences.
+const (
+	ChannelEmail    = "email"
+	ChannelSlack    = "slack"
+	ChannelWhatsApp = "whatsapp"
+)
+
+// ErrNotConfigured is returned by a channel when the profile lacks the address it delivers to.
+var ErrNotConfigured = errors.New("channel not configured for user")
+
+// Channel delivers alerts to a user outside the chat window.
+type Channel interface {
+	// Name identifies the channel in profile preferences, e.g. "email".
+	Name() string
+
+	// Send delivers the event to the user described by the profile.
+	Send(ctx context.Context, p *profile.Profile, evt Event) error
+}
@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Store is the persistence needed by the Dispatcher.
+type Store interface {
+	WebhooksFor(ctx context.Context, userID string, t EventType) ([]*Webhook, error)
+	SaveDelivery(ctx context.Context, d *Delivery) error
+}
+
+// Dispatcher fans events out to the webhooks subscribed to them. Deliveries are
+// sent in the background and retried with exponential backoff on failure.
+type Dispatcher struct {
+	store       Store
+	httpClient  *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	wg          sync.WaitGroup
+}
+
+func NewDispatcher(store Store) *Dispatcher {
+	// Deliveries go straight to the webhook, not through a proxy, for the dialer to check
+	// the addresses connected to are public.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = publicDialer().DialContext
+
+	return &Dispatcher{
+		store:       store,
+		httpClient:  &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		maxAttempts: 5,
+		backoff:     time.Second,
+	}
+}
+
+// Publish schedules delivery of the event to every subscribed webhook of the event's user.
+// It returns once deliveries are recorded; sending happens asynchronously.
+func (d *Dispatcher) Publish(ctx context.Context, evt Event) error {
+	if evt.UserID == "" {
+		return nil
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Dispatcher.Publish",
+		trace.WithAttributes(
+			attribute.String("event.id", evt.ID),
+			attribute.String("event.type", string(evt.Type)),
+		),
+	)
+	defer span.End()
+
+	hooks, err := d.store.WebhooksFor(ctx, evt.UserID, evt.Type)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to look up webhooks")
+		return err
+	}
+
+	body, err := json.Marshal(evt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to encode event")
+		return err
+	}
+
+	for _, hook := range hooks {
+		delivery := &Delivery{
+			ID:        primitive.NewObjectID(),
+			WebhookID: hook.ID,
+			EventID:   evt.ID,
+			EventType: evt.Type,
+			Status:    DeliveryPending,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		if err := d.store.SaveDelivery(ctx, delivery); err != nil {
+			slog.ErrorContext(ctx, "Failed to record webhook delivery", "webhook_id", hook.ID.Hex(), "error", err)
+			continue
+		}
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.deliver(context.WithoutCancel(ctx), hook, delivery, body)
+		}()
+	}
+
+	span.SetAttributes(attribute.Int("webhooks.count", len(hooks)))
+	span.SetStatus(codes.Ok, "event published")
+	return nil
+}
+
+// Wait blocks until all in-flight deliveries have finished.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// deliver sends the body to the webhook, retrying until it succeeds or attempts run out.
+func (d *Dispatcher) deliver(ctx context.Context, hook *Webhook, delivery *Delivery, body []byte) {
+	backoff := d.backoff
+
+	for delivery.Attempts < d.maxAttempts {
+		if delivery.Attempts > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff *= 2
+		}
+
+		delivery.Attempts++
+		status, err := d.send(ctx, hook, delivery.EventType, body)
+		delivery.ResponseStatus = status
+		delivery.UpdatedAt = time.Now()
+
+		if err == nil {
+			delivery.Status = DeliveryDelivered
+			delivery.LastError = ""
+			d.save(ctx, delivery)
+			return
+		}
+
+		delivery.LastError = err.Error()
+		slog.WarnContext(ctx, "Webhook delivery attempt failed",
+			"webhook_id", hook.ID.Hex(),
+			"attempt", delivery.Attempts,
+			"error", err)
+
+		if delivery.Attempts >= d.maxAttempts {
+			delivery.Status = DeliveryFailed
+		}
+		d.save(ctx, delivery)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, hook *Webhook, t EventType, body []byte) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(t))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) save(ctx context.Context, delivery *Delivery) {
+	if err := d.store.SaveDelivery(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "Failed to update webhook delivery", "delivery_id", delivery.ID.Hex(), "error", err)
+	}
+}
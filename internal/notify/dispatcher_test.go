@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore is an in-memory Store used to observe dispatcher behaviour.
+type memoryStore struct {
+	mu         sync.Mutex
+	hooks      []*Webhook
+	deliveries map[primitive.ObjectID]Delivery
+}
+
+func (m *memoryStore) WebhooksFor(ctx context.Context, userID string, t EventType) ([]*Webhook, error) {
+	var out []*Webhook
+	for _, h := range m.hooks {
+		if h.UserID == userID && h.Subscribed(t) {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryStore) SaveDelivery(ctx context.Context, d *Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deliveries == nil {
+		m.deliveries = map[primitive.ObjectID]Delivery{}
+	}
+	m.deliveries[d.ID] = *d
+	return nil
+}
+
+func TestDispatcher_Publish(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		events       []EventType
+		wantCalls    int32
+		wantStatus   DeliveryStatus
+		wantAttempts int
+	}{
+		{
+			name:         "delivers on first attempt",
+			wantCalls:    1,
+			wantStatus:   DeliveryDelivered,
+			wantAttempts: 1,
+		},
+		{
+			name:         "retries until delivered",
+			failures:     2,
+			wantCalls:    3,
+			wantStatus:   DeliveryDelivered,
+			wantAttempts: 3,
+		},
+		{
+			name:         "gives up after max attempts",
+			failures:     10,
+			wantCalls:    3,
+			wantStatus:   DeliveryFailed,
+			wantAttempts: 3,
+		},
+		{
+			name:      "skips webhooks not subscribed to the event",
+			events:    []EventType{EventReminder},
+			wantCalls: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				want := Sign("secret", r.Header.Get(TimestampHeader), body)
+				if got := r.Header.Get(SignatureHeader); got != want {
+					t.Errorf("signature = %q, want %q", got, want)
+				}
+
+				if calls.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			store := &memoryStore{hooks: []*Webhook{{
+				ID:     primitive.NewObjectID(),
+				UserID: "user-1",
+				URL:    srv.URL,
+				Secret: "secret",
+				Events: tt.events,
+			}}}
+
+			d := NewDispatcher(store)
+			d.httpClient = srv.Client()
+			d.maxAttempts = 3
+			d.backoff = time.Millisecond
+
+			if err := d.Publish(context.Background(), NewEvent(EventReplyReady, "user-1", nil)); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+			d.Wait()
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("webhook called %d times, want %d", got, tt.wantCalls)
+			}
+
+			if tt.wantCalls == 0 {
+				if len(store.deliveries) != 0 {
+					t.Errorf("expected no deliveries, got %d", len(store.deliveries))
+				}
+				return
+			}
+
+			if len(store.deliveries) != 1 {
+				t.Fatalf("expected 1 delivery, got %d", len(store.deliveries))
+			}
+			for _, got := range store.deliveries {
+				if got.Status != tt.wantStatus {
+					t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+				}
+				if got.Attempts != tt.wantAttempts {
+					t.Errorf("attempts = %d, want %d", got.Attempts, tt.wantAttempts)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcher_Publish_PrivateAddress(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	store := &memoryStore{hooks: []*Webhook{{ID: primitive.NewObjectID(), UserID: "user-1", URL: srv.URL, Secret: "secret"}}}
+
+	d := NewDispatcher(store)
+	d.maxAttempts = 1
+
+	if err := d.Publish(context.Background(), NewEvent(EventReplyReady, "user-1", nil)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	d.Wait()
+
+	if got := calls.Load(); got != 0 {
+		t.Errorf("webhook on a loopback address called %d times, want 0", got)
+	}
+	for _, got := range store.deliveries {
+		if got.Status != DeliveryFailed || !strings.Contains(got.LastError, ErrPrivateAddress.Error()) {
+			t.Errorf("delivery = %q (%s), want failed for a private address", got.Status, got.LastError)
+		}
+	}
+}
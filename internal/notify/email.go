@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/profile"
+)
+
+// EmailChannel sends alerts over SMTP.
+type EmailChannel struct {
+	addr string
+	from string
+	auth smtp.Auth
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailChannelFromEnv configures an EmailChannel from SMTP_HOST, SMTP_PORT, SMTP_USERNAME,
+// SMTP_PASSWORD and SMTP_FROM. It returns nil if SMTP_HOST is not set.
+func NewEmailChannelFromEnv() *EmailChannel {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "tour-assist@localhost"
+	}
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USERNAME"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	return &EmailChannel{
+		addr: net.JoinHostPort(host, port),
+		from: from,
+		auth: auth,
+		send: smtp.SendMail,
+	}
+}
+
+func (c *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+func (c *EmailChannel) Send(ctx context.Context, p *profile.Profile, evt Event) error {
+	if p.Email == "" {
+		return ErrNotConfigured
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", p.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", evt.Subject())
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(evt.Text())
+	msg.WriteString("\r\n")
+
+	if err := c.send(c.addr, c.auth, c.from, []string{p.Email}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	return nil
+}
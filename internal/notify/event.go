@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventType identifies the kind of event a notification is sent for.
+type EventType string
+
+const (
+	EventReplyReady       EventType = "reply.ready"
+	EventItineraryUpdated EventType = "itinerary.updated"
+	EventReminder         EventType = "reminder.due"
+)
+
+// EventTypes lists all event types that can be subscribed to.
+var EventTypes = []EventType{EventReplyReady, EventItineraryUpdated, EventReminder}
+
+// Alert reports whether events of this type should reach the user outside the chat,
+// through their email or Slack channels.
+func (t EventType) Alert() bool {
+	return t == EventItineraryUpdated || t == EventReminder
+}
+
+// Valid reports whether t is a known event type.
+func (t EventType) Valid() bool {
+	for _, v := range EventTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a notification-worthy occurrence for a single user.
+type Event struct {
+	ID             string         `json:"id"`
+	Type           EventType      `json:"type"`
+	UserID         string         `json:"user_id"`
+	ConversationID string         `json:"conversation_id,omitempty"`
+	Data           map[string]any `json:"data,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// NewEvent creates an event of the given type for a user.
+func NewEvent(t EventType, userID string, data map[string]any) Event {
+	return Event{
+		ID:        primitive.NewObjectID().Hex(),
+		Type:      t,
+		UserID:    userID,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Subject returns a short human-readable headline for the event.
+func (e Event) Subject() string {
+	switch e.Type {
+	case EventReplyReady:
+		return "Your travel assistant replied"
+	case EventItineraryUpdated:
+		return "Your itinerary was updated"
+	case EventReminder:
+		return "Travel reminder"
+	default:
+		return string(e.Type)
+	}
+}
+
+// Text returns the human-readable body of the event, taken from its "message" data
+// field when present.
+func (e Event) Text() string {
+	if msg, ok := e.Data["message"].(string); ok && msg != "" {
+		return msg
+	}
+	return fmt.Sprintf("%s at %s", e.Subject(), e.CreatedAt.Format(time.RFC1123))
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/acai-travel/tech-challenge/internal/profile"
+)
+
+// ProfileStore looks up the profile holding a user's channel preferences.
+type ProfileStore interface {
+	DescribeProfile(ctx context.Context, userID string) (*profile.Profile, error)
+}
+
+// Notifier publishes events to webhooks and, for alerts, to the channels the user
+// enabled on their profile.
+type Notifier struct {
+	webhooks *Dispatcher
+	profiles ProfileStore
+	channels []Channel
+	wg       sync.WaitGroup
+}
+
+func NewNotifier(webhooks *Dispatcher, profiles ProfileStore, channels ...Channel) *Notifier {
+	return &Notifier{webhooks: webhooks, profiles: profiles, channels: channels}
+}
+
+// Channels returns the names of the configured channels.
+func (n *Notifier) Channels() []string {
+	names := make([]string, 0, len(n.channels))
+	for _, c := range n.channels {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+func (n *Notifier) Publish(ctx context.Context, evt Event) error {
+	if evt.UserID == "" {
+		return nil
+	}
+
+	var errs []error
+	if n.webhooks != nil {
+		errs = append(errs, n.webhooks.Publish(ctx, evt))
+	}
+
+	if evt.Type.Alert() && len(n.channels) > 0 {
+		p, err := n.profiles.DescribeProfile(ctx, evt.UserID)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			for _, c := range n.channels {
+				if !slices.Contains(p.Channels, c.Name()) {
+					continue
+				}
+
+				n.wg.Add(1)
+				go func() {
+					defer n.wg.Done()
+					n.send(context.WithoutCancel(ctx), c, p, evt)
+				}()
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Wait blocks until all in-flight notifications have finished.
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+	if n.webhooks != nil {
+		n.webhooks.Wait()
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, c Channel, p *profile.Profile, evt Event) {
+	err := c.Send(ctx, p, evt)
+	if errors.Is(err, ErrNotConfigured) {
+		slog.WarnContext(ctx, "Notification channel enabled but not configured", "channel", c.Name(), "user_id", p.UserID)
+		return
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send notification", "channel", c.Name(), "event_type", evt.Type, "error", err)
+		return
+	}
+
+	slog.InfoContext(ctx, "Notification sent", "channel", c.Name(), "event_type", evt.Type)
+}
@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/profile"
+)
+
+type profileStore map[string]*profile.Profile
+
+func (s profileStore) DescribeProfile(ctx context.Context, userID string) (*profile.Profile, error) {
+	if p, ok := s[userID]; ok {
+		return p, nil
+	}
+	return &profile.Profile{UserID: userID}, nil
+}
+
+// recordingChannel remembers the events it was asked to send.
+type recordingChannel struct {
+	name string
+	mu   sync.Mutex
+	sent []EventType
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Send(ctx context.Context, p *profile.Profile, evt Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, evt.Type)
+	return nil
+}
+
+func TestNotifier_Publish(t *testing.T) {
+	tests := []struct {
+		name      string
+		channels  []string
+		event     EventType
+		wantEmail int
+		wantSlack int
+	}{
+		{
+			name:      "alert goes to enabled channels",
+			channels:  []string{ChannelEmail, ChannelSlack},
+			event:     EventItineraryUpdated,
+			wantEmail: 1,
+			wantSlack: 1,
+		},
+		{
+			name:      "disabled channels are skipped",
+			channels:  []string{ChannelSlack},
+			event:     EventReminder,
+			wantSlack: 1,
+		},
+		{
+			name:     "non-alert events stay in the chat",
+			channels: []string{ChannelEmail, ChannelSlack},
+			event:    EventReplyReady,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &recordingChannel{name: ChannelEmail}
+			slack := &recordingChannel{name: ChannelSlack}
+			profiles := profileStore{"user-1": {UserID: "user-1", Channels: tt.channels}}
+
+			n := NewNotifier(nil, profiles, email, slack)
+			if err := n.Publish(context.Background(), NewEvent(tt.event, "user-1", nil)); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+			n.Wait()
+
+			if len(email.sent) != tt.wantEmail {
+				t.Errorf("email sent %d times, want %d", len(email.sent), tt.wantEmail)
+			}
+			if len(slack.sent) != tt.wantSlack {
+				t.Errorf("slack sent %d times, want %d", len(slack.sent), tt.wantSlack)
+			}
+		})
+	}
+}
+
+func TestSlackChannel_Send(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	evt := NewEvent(EventItineraryUpdated, "user-1", map[string]any{"message": "Your BCN → CDG flight was added to the itinerary"})
+
+	c := NewSlackChannel()
+	if err := c.Send(context.Background(), &profile.Profile{SlackWebhookURL: srv.URL}, evt); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if !strings.Contains(got["text"], "Your BCN → CDG flight was added to the itinerary") {
+		t.Errorf("text = %q, want it to contain the event message", got["text"])
+	}
+
+	if err := c.Send(context.Background(), &profile.Profile{}, evt); err != ErrNotConfigured {
+		t.Errorf("Send() without webhook URL error = %v, want ErrNotConfigured", err)
+	}
+}
@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName = "github.com/acai-travel/tech-challenge/internal/notify"
+
+	webhookCollection  = "webhooks"
+	deliveryCollection = "webhook_deliveries"
+)
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) CreateWebhook(ctx context.Context, w *Webhook) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.CreateWebhook")
+	span.SetAttributes(attribute.String("webhook.id", w.ID.Hex()))
+	defer span.End()
+
+	if _, err := r.conn.Collection(webhookCollection).InsertOne(ctx, w); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create webhook")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "webhook created")
+	return nil
+}
+
+func (r *Repository) ListWebhooks(ctx context.Context, userID string) ([]*Webhook, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListWebhooks")
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.conn.Collection(webhookCollection).Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query webhooks")
+		return nil, err
+	}
+
+	var items []*Webhook
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode webhooks")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("webhooks.count", len(items)))
+	span.SetStatus(codes.Ok, "webhooks listed")
+	return items, nil
+}
+
+// DescribeWebhook returns a webhook owned by the given user.
+func (r *Repository) DescribeWebhook(ctx context.Context, userID, id string) (*Webhook, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeWebhook")
+	span.SetAttributes(attribute.String("webhook.id", id))
+	defer span.End()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid webhook ID")
+		return nil, twirp.NotFoundError("invalid webhook ID")
+	}
+
+	var w Webhook
+	err = r.conn.Collection(webhookCollection).FindOne(ctx, bson.M{"_id": oid, "user_id": userID}).Decode(&w)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Error, "webhook not found")
+		return nil, twirp.NotFoundError("webhook not found")
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database error")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "webhook found")
+	return &w, nil
+}
+
+func (r *Repository) DeleteWebhook(ctx context.Context, userID, id string) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DeleteWebhook")
+	span.SetAttributes(attribute.String("webhook.id", id))
+	defer span.End()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid webhook ID")
+		return twirp.NotFoundError("invalid webhook ID")
+	}
+
+	res, err := r.conn.Collection(webhookCollection).DeleteOne(ctx, bson.M{"_id": oid, "user_id": userID})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete webhook")
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		span.SetStatus(codes.Error, "webhook not found")
+		return twirp.NotFoundError("webhook not found")
+	}
+
+	span.SetStatus(codes.Ok, "webhook deleted")
+	return nil
+}
+
+// WebhooksFor returns the user's webhooks subscribed to the given event type.
+func (r *Repository) WebhooksFor(ctx context.Context, userID string, t EventType) ([]*Webhook, error) {
+	hooks, err := r.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*Webhook
+	for _, w := range hooks {
+		if w.Subscribed(t) {
+			out = append(out, w)
+		}
+	}
+
+	return out, nil
+}
+
+// SaveDelivery inserts or replaces a delivery record.
+func (r *Repository) SaveDelivery(ctx context.Context, d *Delivery) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SaveDelivery")
+	span.SetAttributes(
+		attribute.String("delivery.id", d.ID.Hex()),
+		attribute.String("delivery.status", string(d.Status)),
+	)
+	defer span.End()
+
+	_, err := r.conn.Collection(deliveryCollection).ReplaceOne(ctx,
+		bson.M{"_id": d.ID}, d, options.Replace().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to save delivery")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "delivery saved")
+	return nil
+}
+
+// ListDeliveries returns the most recent deliveries of a webhook, newest first.
+func (r *Repository) ListDeliveries(ctx context.Context, webhookID primitive.ObjectID, limit int64) ([]*Delivery, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListDeliveries")
+	span.SetAttributes(attribute.String("webhook.id", webhookID.Hex()))
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := r.conn.Collection(deliveryCollection).Find(ctx, bson.M{"webhook_id": webhookID}, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query deliveries")
+		return nil, err
+	}
+
+	var items []*Delivery
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode deliveries")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("deliveries.count", len(items)))
+	span.SetStatus(codes.Ok, "deliveries listed")
+	return items, nil
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/profile"
+)
+
+// SlackChannel posts alerts to the user's Slack incoming webhook.
+type SlackChannel struct {
+	httpClient *http.Client
+}
+
+func NewSlackChannel() *SlackChannel {
+	return &SlackChannel{httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *SlackChannel) Name() string {
+	return ChannelSlack
+}
+
+func (c *SlackChannel) Send(ctx context.Context, p *profile.Profile, evt Event) error {
+	if p.SlackWebhookURL == "" {
+		return ErrNotConfigured
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", evt.Subject(), evt.Text()),
+	})
+	if err != nil {
+		return fmt.Errorf("encode message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.SlackWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack error: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
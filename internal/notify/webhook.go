@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of the delivery body.
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader carries the unix timestamp that is part of the signed payload.
+	TimestampHeader = "X-Webhook-Timestamp"
+	// EventHeader carries the event type of the delivery.
+	EventHeader = "X-Webhook-Event"
+)
+
+// Webhook is a user-registered URL that receives event notifications.
+type Webhook struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	UserID    string             `bson:"user_id"`
+	URL       string             `bson:"url"`
+	Secret    string             `bson:"secret"`
+	Events    []EventType        `bson:"events"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// Subscribed reports whether the webhook wants to receive events of type t.
+// A webhook without explicit events is subscribed to all of them.
+func (w *Webhook) Subscribed(t EventType) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, t)
+}
+
+func (w *Webhook) Proto() *pb.Webhook {
+	proto := &pb.Webhook{
+		Id:        w.ID.Hex(),
+		Url:       w.URL,
+		CreatedAt: timestamppb.New(w.CreatedAt),
+	}
+
+	for _, e := range w.Events {
+		proto.Events = append(proto.Events, string(e))
+	}
+
+	return proto
+}
+
+// DeliveryStatus is the state of a single webhook delivery.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery records the attempts to send one event to one webhook.
+type Delivery struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	WebhookID      primitive.ObjectID `bson:"webhook_id"`
+	EventID        string             `bson:"event_id"`
+	EventType      EventType          `bson:"event_type"`
+	Status         DeliveryStatus     `bson:"status"`
+	Attempts       int                `bson:"attempts"`
+	ResponseStatus int                `bson:"response_status"`
+	LastError      string             `bson:"last_error,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
+
+func (d *Delivery) Proto() *pb.WebhookDelivery {
+	return &pb.WebhookDelivery{
+		Id:             d.ID.Hex(),
+		WebhookId:      d.WebhookID.Hex(),
+		EventId:        d.EventID,
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       int32(d.Attempts),
+		ResponseStatus: int32(d.ResponseStatus),
+		LastError:      d.LastError,
+		Timestamp:      timestamppb.New(d.UpdatedAt),
+	}
+}
+
+// NewSecret generates a random signing secret for a webhook.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign computes the signature of a delivery body sent at the given unix timestamp.
+// Receivers verify it by computing HMAC-SHA256 over "<timestamp>.<body>" with the shared secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,182 @@
+// Package openaix shares what every OpenAI client of the service needs, like a bound on
+// the calls in flight.
+package openaix
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/openai/openai-go/v2/option"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/acai-travel/tech-challenge/internal/openaix"
+
+// durationBuckets are the OpenAI call latency histogram boundaries in milliseconds, from
+// short classifications to long completions.
+var durationBuckets = []float64{100, 250, 500, 1000, 2500, 5000, 10000, 20000, 30000, 60000, 120000}
+
+// DefaultMaxInFlight is the number of OpenAI calls made at once, unless
+// OPENAI_MAX_IN_FLIGHT says otherwise.
+const DefaultMaxInFlight = 16
+
+// MaxInFlightFromEnv reads the number of OpenAI calls made at once from
+// OPENAI_MAX_IN_FLIGHT, DefaultMaxInFlight when unset. Zero removes the bound.
+func MaxInFlightFromEnv() (int, error) {
+	v := os.Getenv("OPENAI_MAX_IN_FLIGHT")
+	if v == "" {
+		return DefaultMaxInFlight, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("OPENAI_MAX_IN_FLIGHT must be a number of calls, got %q", v)
+	}
+	return n, nil
+}
+
+// Limiter bounds the OpenAI calls in flight across the clients sharing it. Calls beyond
+// the bound wait for a slot, first come first served, until their context is done, so
+// that a burst of traffic slows replies down instead of tripping OpenAI's rate limits.
+// Close to those limits, as OpenAI reports them, calls are also spread until they reset.
+type Limiter struct {
+	slots  chan struct{}
+	limits *RateLimits
+
+	inFlight  metric.Int64UpDownCounter
+	queued    metric.Int64UpDownCounter
+	wait      metric.Float64Histogram
+	duration  metric.Float64Histogram
+	rejected  metric.Int64Counter
+	throttled metric.Int64Counter
+}
+
+// NewLimiter returns a Limiter letting max calls in flight, any number when max is zero.
+func NewLimiter(max int) *Limiter {
+	l := &Limiter{limits: NewRateLimits()}
+	if max > 0 {
+		l.slots = make(chan struct{}, max)
+	}
+
+	// Instruments are no-ops when they can't be created
+	meter := otel.Meter(meterName)
+	l.inFlight, _ = meter.Int64UpDownCounter(
+		"openai.requests.in_flight",
+		metric.WithDescription("Number of OpenAI calls in flight"),
+		metric.WithUnit("{request}"),
+	)
+	l.queued, _ = meter.Int64UpDownCounter(
+		"openai.requests.queued",
+		metric.WithDescription("Number of OpenAI calls waiting for a slot"),
+		metric.WithUnit("{request}"),
+	)
+	l.wait, _ = meter.Float64Histogram(
+		"openai.requests.wait_duration",
+		metric.WithDescription("Time OpenAI calls waited for a slot in milliseconds"),
+		metric.WithUnit("ms"),
+	)
+	l.duration, _ = meter.Float64Histogram(
+		"openai.requests.duration",
+		metric.WithDescription("Duration of OpenAI calls in milliseconds, from their slot to their response headers"),
+		metric.WithUnit("ms"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	l.rejected, _ = meter.Int64Counter(
+		"openai.requests.rejected",
+		metric.WithDescription("Number of OpenAI calls given up while waiting for a slot"),
+		metric.WithUnit("{request}"),
+	)
+	l.throttled, _ = meter.Int64Counter(
+		"openai.requests.throttled",
+		metric.WithDescription("Number of OpenAI calls held back close to a rate limit"),
+		metric.WithUnit("{request}"),
+	)
+	l.limits.observeGauges()
+	return l
+}
+
+// Acquire waits for a slot until ctx is done. The returned release frees it.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		l.queued.Add(ctx, 1)
+		start := time.Now()
+		select {
+		case l.slots <- struct{}{}:
+			l.queued.Add(ctx, -1)
+			l.wait.Record(ctx, float64(time.Since(start).Milliseconds()))
+		case <-ctx.Done():
+			l.queued.Add(ctx, -1)
+			l.rejected.Add(ctx, 1)
+			return nil, ctx.Err()
+		}
+	}
+
+	l.inFlight.Add(ctx, 1)
+	return func() {
+		l.inFlight.Add(ctx, -1)
+		<-l.slots
+	}, nil
+}
+
+// NearLimit reports whether model is close to its OpenAI rate limits, for callers to
+// switch to another model.
+func (l *Limiter) NearLimit(model string) bool {
+	return l.limits.NearLimit(model)
+}
+
+// throttle holds a call to model back close to its rate limits, until ctx is done.
+func (l *Limiter) throttle(ctx context.Context, model string) error {
+	d := l.limits.reserve(model)
+	if d <= 0 {
+		return nil
+	}
+
+	l.throttled.Add(ctx, 1)
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.rejected.Add(ctx, 1)
+		return ctx.Err()
+	}
+}
+
+// Option applies the limiter to the calls of an OpenAI client. A slot is held until the
+// response arrives, which for completions is once they are generated; each retry waits
+// for a slot again. The rate limits of every response are recorded.
+func (l *Limiter) Option() option.RequestOption {
+	return option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		model := requestModel(req)
+		if err := l.throttle(req.Context(), model); err != nil {
+			return nil, fmt.Errorf("waiting out an OpenAI rate limit: %w", err)
+		}
+
+		release, err := l.Acquire(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("waiting for an OpenAI call slot: %w", err)
+		}
+		defer release()
+
+		start := time.Now()
+		resp, err := next(req)
+		l.duration.Record(req.Context(), float64(time.Since(start).Milliseconds()), metric.WithAttributes(attribute.String("model", model)))
+		if resp != nil {
+			l.limits.observe(model, resp.Header)
+		}
+		return resp, err
+	})
+}
@@ -0,0 +1,109 @@
+package openaix
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+func TestLimiter_Acquire(t *testing.T) {
+	l := NewLimiter(1)
+
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() with no slot left = %v, want the context's error", err)
+	}
+
+	release()
+	release, err = l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() after a release error = %v", err)
+	}
+	release()
+
+	unbounded := NewLimiter(0)
+	for range 100 {
+		if _, err := unbounded.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire() without a bound error = %v", err)
+		}
+	}
+}
+
+func TestLimiter_Option(t *testing.T) {
+	var current, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","content":"Sunny."}}]}`))
+	}))
+	defer srv.Close()
+
+	l := NewLimiter(2)
+	cli := openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0), l.Option())
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cli.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+				Model:    openai.ChatModelGPT4_1Mini,
+				Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Weather in Lisbon?")},
+			})
+			if err != nil {
+				t.Errorf("Chat.Completions.New() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak calls in flight = %d, want 2", got)
+	}
+}
+
+func TestMaxInFlightFromEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "", want: DefaultMaxInFlight},
+		{value: "4", want: 4},
+		{value: "0", want: 0},
+		{value: "-1", wantErr: true},
+		{value: "many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("OPENAI_MAX_IN_FLIGHT", tt.value)
+			got, err := MaxInFlightFromEnv()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("MaxInFlightFromEnv() = %d, %v, want %d (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package openaix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// throttleBelow is the share of a rate limit left under which calls are spread over
+	// the time until the limit resets.
+	throttleBelow = 0.2
+	// fallbackBelow is the share of a rate limit left under which NearLimit reports the
+	// model close to it.
+	fallbackBelow = 0.1
+	// maxThrottleDelay bounds the time a call is held back.
+	maxThrottleDelay = 20 * time.Second
+)
+
+// bucket is one of the rate limits of a model, requests or tokens per minute.
+type bucket struct {
+	limit     int64
+	remaining int64
+	resetAt   time.Time
+}
+
+// headroom returns the share of the limit left at now, all of it once the limit reset.
+func (b bucket) headroom(now time.Time) float64 {
+	if b.limit <= 0 || !now.Before(b.resetAt) {
+		return 1
+	}
+	return float64(b.remaining) / float64(b.limit)
+}
+
+// pace returns the time between calls that spreads what is left evenly until the
+// limit resets.
+func (b bucket) pace(now time.Time) time.Duration {
+	return b.resetAt.Sub(now) / time.Duration(max(b.remaining, 1))
+}
+
+// modelLimits are the rate limits of a model.
+type modelLimits struct {
+	requests bucket
+	tokens   bucket
+	// nextAt is when the next held back call may go
+	nextAt time.Time
+}
+
+// tightest returns the limit with the least headroom at now.
+func (m *modelLimits) tightest(now time.Time) bucket {
+	if m.tokens.headroom(now) < m.requests.headroom(now) {
+		return m.tokens
+	}
+	return m.requests
+}
+
+// RateLimits tracks OpenAI's rate limits per model, from the x-ratelimit headers of its
+// responses.
+type RateLimits struct {
+	mu     sync.Mutex
+	models map[string]*modelLimits
+	now    func() time.Time
+}
+
+func NewRateLimits() *RateLimits {
+	return &RateLimits{models: make(map[string]*modelLimits), now: time.Now}
+}
+
+// observe records the rate limits of model from the headers of a response.
+func (r *RateLimits) observe(model string, h http.Header) {
+	if model == "" || h.Get("x-ratelimit-limit-requests") == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	m, ok := r.models[model]
+	if !ok {
+		m = &modelLimits{}
+		r.models[model] = m
+	}
+	m.requests = parseBucket(h, "requests", now)
+	m.tokens = parseBucket(h, "tokens", now)
+}
+
+func parseBucket(h http.Header, kind string, now time.Time) bucket {
+	limit, _ := strconv.ParseInt(h.Get("x-ratelimit-limit-"+kind), 10, 64)
+	remaining, _ := strconv.ParseInt(h.Get("x-ratelimit-remaining-"+kind), 10, 64)
+	reset, _ := time.ParseDuration(h.Get("x-ratelimit-reset-" + kind))
+	return bucket{limit: limit, remaining: remaining, resetAt: now.Add(reset)}
+}
+
+// NearLimit reports whether model has less than a tenth of a rate limit left.
+func (r *RateLimits) NearLimit(model string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.models[model]
+	return ok && m.tightest(r.now()).headroom(r.now()) < fallbackBelow
+}
+
+// reserve returns how long a call to model should wait so that, close to a rate limit,
+// calls are spread until it resets instead of all failing once it is reached.
+func (r *RateLimits) reserve(model string) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	m, ok := r.models[model]
+	if !ok {
+		return 0
+	}
+	b := m.tightest(now)
+	if b.headroom(now) >= throttleBelow {
+		return 0
+	}
+
+	at := now
+	if b.remaining <= 0 {
+		at = b.resetAt
+	}
+	if m.nextAt.After(at) {
+		at = m.nextAt
+	}
+	m.nextAt = at.Add(b.pace(now))
+	return min(at.Sub(now), maxThrottleDelay)
+}
+
+// observeGauges exports the requests and tokens left per model as gauges.
+func (r *RateLimits) observeGauges() {
+	meter := otel.Meter(meterName)
+	requests, err := meter.Int64ObservableGauge(
+		"openai.ratelimit.remaining_requests",
+		metric.WithDescription("Requests left before the OpenAI rate limit of a model"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return
+	}
+	tokens, err := meter.Int64ObservableGauge(
+		"openai.ratelimit.remaining_tokens",
+		metric.WithDescription("Tokens left before the OpenAI rate limit of a model"),
+		metric.WithUnit("{token}"),
+	)
+	if err != nil {
+		return
+	}
+
+	_, _ = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		for model, m := range r.models {
+			attrs := metric.WithAttributes(attribute.String("model", model))
+			o.ObserveInt64(requests, m.requests.remaining, attrs)
+			o.ObserveInt64(tokens, m.tokens.remaining, attrs)
+		}
+		return nil
+	}, requests, tokens)
+}
+
+// requestModel returns the model of an OpenAI request with a JSON body, empty for
+// others like transcriptions.
+func requestModel(req *http.Request) string {
+	if req.GetBody == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	// Walk the top-level fields rather than decoding messages, which may carry images
+	dec := json.NewDecoder(body)
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return ""
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		if key == "model" {
+			var model string
+			if err := dec.Decode(&model); err != nil {
+				return ""
+			}
+			return model
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return ""
+		}
+	}
+	return ""
+}
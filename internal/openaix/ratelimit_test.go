@@ -0,0 +1,104 @@
+package openaix
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+func rateLimitHeaders(remainingRequests, remainingTokens string) http.Header {
+	h := http.Header{}
+	h.Set("x-ratelimit-limit-requests", "100")
+	h.Set("x-ratelimit-remaining-requests", remainingRequests)
+	h.Set("x-ratelimit-reset-requests", "10s")
+	h.Set("x-ratelimit-limit-tokens", "10000")
+	h.Set("x-ratelimit-remaining-tokens", remainingTokens)
+	h.Set("x-ratelimit-reset-tokens", "6m0s")
+	return h
+}
+
+func TestRateLimits(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRateLimits()
+	r.now = func() time.Time { return now }
+
+	if r.NearLimit("gpt-4.1") || r.reserve("gpt-4.1") != 0 {
+		t.Fatal("a model never called is not near its limits")
+	}
+
+	r.observe("gpt-4.1", rateLimitHeaders("90", "9000"))
+	if r.NearLimit("gpt-4.1") {
+		t.Error("NearLimit() with 90% left = true")
+	}
+	if d := r.reserve("gpt-4.1"); d != 0 {
+		t.Errorf("reserve() with 90%% left = %v, want 0", d)
+	}
+
+	// 15% of the tokens left: calls are spread, but the model is still used
+	r.observe("gpt-4.1", rateLimitHeaders("90", "1500"))
+	if r.NearLimit("gpt-4.1") {
+		t.Error("NearLimit() with 15% left = true")
+	}
+	if d := r.reserve("gpt-4.1"); d != 0 {
+		t.Errorf("first reserve() = %v, want 0", d)
+	}
+	if d, want := r.reserve("gpt-4.1"), 6*time.Minute/1500; d != want {
+		t.Errorf("second reserve() = %v, want %v", d, want)
+	}
+
+	// 5% of the requests left
+	r.observe("gpt-4.1", rateLimitHeaders("5", "9000"))
+	if !r.NearLimit("gpt-4.1") {
+		t.Error("NearLimit() with 5% left = false")
+	}
+	if r.NearLimit("gpt-4.1-mini") {
+		t.Error("NearLimit() of another model = true")
+	}
+
+	// Nothing left: calls wait for the reset
+	r.observe("gpt-4.1", rateLimitHeaders("0", "9000"))
+	r.models["gpt-4.1"].nextAt = time.Time{}
+	if d := r.reserve("gpt-4.1"); d != 10*time.Second {
+		t.Errorf("reserve() with nothing left = %v, want the time to the reset", d)
+	}
+
+	// Once the limits reset, they are no longer near
+	now = now.Add(7 * time.Minute)
+	if r.NearLimit("gpt-4.1") {
+		t.Error("NearLimit() after the reset = true")
+	}
+}
+
+func TestLimiter_Option_RateLimits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range rateLimitHeaders("3", "9000") {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","content":"Sunny."}}]}`))
+	}))
+	defer srv.Close()
+
+	l := NewLimiter(2)
+	cli := openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0), l.Option())
+
+	_, err := cli.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Weather in Lisbon?")},
+		Model:    openai.ChatModelGPT4_1,
+	})
+	if err != nil {
+		t.Fatalf("Chat.Completions.New() error = %v", err)
+	}
+
+	if !l.NearLimit(openai.ChatModelGPT4_1) {
+		t.Error("NearLimit() after a response with 3% left = false")
+	}
+	if l.NearLimit(openai.ChatModelGPT4_1Mini) {
+		t.Error("NearLimit() of a model never called = true")
+	}
+}
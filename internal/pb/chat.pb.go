@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.2
+// 	protoc-gen-go v1.36.7
 // 	protoc        v5.29.3
 // source: rpc/chat.proto
 
@@ -12,6 +12,7 @@ import (
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -71,14 +72,39 @@ func (Conversation_Role) EnumDescriptor() ([]byte, []int) {
 }
 
 type Conversation struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
+	state     protoimpl.MessageState  `protogen:"open.v1"`
 	Id        string                  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Title     string                  `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
 	Timestamp *timestamppb.Timestamp  `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	Messages  []*Conversation_Message `protobuf:"bytes,4,rep,name=messages,proto3" json:"messages,omitempty"`
+	// user-defined and automatic labels, e.g. "flights" or "summer-2025"
+	Tags   []string `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Folder string   `protobuf:"bytes,6,opt,name=folder,proto3" json:"folder,omitempty"`
+	// set when the conversation is archived
+	ArchivedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=archived_at,json=archivedAt,proto3" json:"archived_at,omitempty"`
+	// replies are answered by the model alone, without looking anything up with tools
+	ToolsDisabled bool                `protobuf:"varint,8,opt,name=tools_disabled,json=toolsDisabled,proto3" json:"tools_disabled,omitempty"`
+	Settings      *GenerationSettings `protobuf:"bytes,9,opt,name=settings,proto3" json:"settings,omitempty"`
+	// template the conversation was started from, if any
+	TemplateId string `protobuf:"bytes,10,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	// character the assistant plays: budget-backpacker, luxury-concierge or family-planner; empty for the default assistant
+	Persona string `protobuf:"bytes,11,opt,name=persona,proto3" json:"persona,omitempty"`
+	// how the title was made: model, fallback_model (a cheaper model, when the title model failed), heuristic (the
+	// first words of the first message) or default; empty for conversations titled otherwise, e.g. from a template
+	TitleSource string `protobuf:"bytes,12,opt,name=title_source,json=titleSource,proto3" json:"title_source,omitempty"`
+	// closing summary of a conversation archived for being idle
+	Summary string `protobuf:"bytes,13,opt,name=summary,proto3" json:"summary,omitempty"`
+	// archived for being idle rather than by the user
+	AutoArchived bool `protobuf:"varint,14,opt,name=auto_archived,json=autoArchived,proto3" json:"auto_archived,omitempty"`
+	// incremented on every update of the conversation, see SyncConversation
+	Revision int64 `protobuf:"varint,15,opt,name=revision,proto3" json:"revision,omitempty"`
+	// set when the conversation is pinned; pinned conversations are listed first, last pinned first
+	PinnedAt *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=pinned_at,json=pinnedAt,proto3" json:"pinned_at,omitempty"`
+	Favorite bool                   `protobuf:"varint,17,opt,name=favorite,proto3" json:"favorite,omitempty"`
+	// number of messages of the conversation, including those left out, see DescribeConversationRequest
+	MessageCount  int32 `protobuf:"varint,18,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Conversation) Reset() {
@@ -139,28 +165,133 @@ func (x *Conversation) GetMessages() []*Conversation_Message {
 	return nil
 }
 
-type StartConversationRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *Conversation) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
 
-	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+func (x *Conversation) GetFolder() string {
+	if x != nil {
+		return x.Folder
+	}
+	return ""
 }
 
-func (x *StartConversationRequest) Reset() {
-	*x = StartConversationRequest{}
+func (x *Conversation) GetArchivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArchivedAt
+	}
+	return nil
+}
+
+func (x *Conversation) GetToolsDisabled() bool {
+	if x != nil {
+		return x.ToolsDisabled
+	}
+	return false
+}
+
+func (x *Conversation) GetSettings() *GenerationSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *Conversation) GetTemplateId() string {
+	if x != nil {
+		return x.TemplateId
+	}
+	return ""
+}
+
+func (x *Conversation) GetPersona() string {
+	if x != nil {
+		return x.Persona
+	}
+	return ""
+}
+
+func (x *Conversation) GetTitleSource() string {
+	if x != nil {
+		return x.TitleSource
+	}
+	return ""
+}
+
+func (x *Conversation) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Conversation) GetAutoArchived() bool {
+	if x != nil {
+		return x.AutoArchived
+	}
+	return false
+}
+
+func (x *Conversation) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *Conversation) GetPinnedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PinnedAt
+	}
+	return nil
+}
+
+func (x *Conversation) GetFavorite() bool {
+	if x != nil {
+		return x.Favorite
+	}
+	return false
+}
+
+func (x *Conversation) GetMessageCount() int32 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+// How replies are generated; empty fields keep the defaults
+type GenerationSettings struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// brief, normal or detailed
+	Verbosity string `protobuf:"bytes,1,opt,name=verbosity,proto3" json:"verbosity,omitempty"`
+	// precise, balanced or creative; ignored by models without a sampling temperature
+	Creativity string `protobuf:"bytes,2,opt,name=creativity,proto3" json:"creativity,omitempty"`
+	// plain, markdown or bullets (a bullet summary); empty leaves the formatting to the assistant
+	Format string `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"`
+	// maximum length of replies in characters, between 80 and 4000; longer replies are cut at a sentence or word
+	MaxLength     int32 `protobuf:"varint,4,opt,name=max_length,json=maxLength,proto3" json:"max_length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerationSettings) Reset() {
+	*x = GenerationSettings{}
 	mi := &file_rpc_chat_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StartConversationRequest) String() string {
+func (x *GenerationSettings) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartConversationRequest) ProtoMessage() {}
+func (*GenerationSettings) ProtoMessage() {}
 
-func (x *StartConversationRequest) ProtoReflect() protoreflect.Message {
+func (x *GenerationSettings) ProtoReflect() protoreflect.Message {
 	mi := &file_rpc_chat_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -172,42 +303,69 @@ func (x *StartConversationRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartConversationRequest.ProtoReflect.Descriptor instead.
-func (*StartConversationRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GenerationSettings.ProtoReflect.Descriptor instead.
+func (*GenerationSettings) Descriptor() ([]byte, []int) {
 	return file_rpc_chat_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *StartConversationRequest) GetMessage() string {
+func (x *GenerationSettings) GetVerbosity() string {
 	if x != nil {
-		return x.Message
+		return x.Verbosity
 	}
 	return ""
 }
 
-type StartConversationResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *GenerationSettings) GetCreativity() string {
+	if x != nil {
+		return x.Creativity
+	}
+	return ""
+}
 
-	ConversationId string `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
-	Title          string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Reply          string `protobuf:"bytes,3,opt,name=reply,proto3" json:"reply,omitempty"`
+func (x *GenerationSettings) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
 }
 
-func (x *StartConversationResponse) Reset() {
-	*x = StartConversationResponse{}
+func (x *GenerationSettings) GetMaxLength() int32 {
+	if x != nil {
+		return x.MaxLength
+	}
+	return 0
+}
+
+// A tool call an assistant reply took facts from, e.g. "source: WeatherAPI, fetched 12:03"
+type Citation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name of the tool, e.g. "get_weather"
+	Tool string `protobuf:"bytes,1,opt,name=tool,proto3" json:"tool,omitempty"`
+	// external data source of the tool, e.g. "WeatherAPI"
+	Source    string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	FetchedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=fetched_at,json=fetchedAt,proto3" json:"fetched_at,omitempty"`
+	// when the data goes stale, unset when it doesn't
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// set once expires_at has passed; RefreshReply updates the reply with fresh data
+	Stale         bool `protobuf:"varint,5,opt,name=stale,proto3" json:"stale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Citation) Reset() {
+	*x = Citation{}
 	mi := &file_rpc_chat_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StartConversationResponse) String() string {
+func (x *Citation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartConversationResponse) ProtoMessage() {}
+func (*Citation) ProtoMessage() {}
 
-func (x *StartConversationResponse) ProtoReflect() protoreflect.Message {
+func (x *Citation) ProtoReflect() protoreflect.Message {
 	mi := &file_rpc_chat_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -219,55 +377,72 @@ func (x *StartConversationResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartConversationResponse.ProtoReflect.Descriptor instead.
-func (*StartConversationResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Citation.ProtoReflect.Descriptor instead.
+func (*Citation) Descriptor() ([]byte, []int) {
 	return file_rpc_chat_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *StartConversationResponse) GetConversationId() string {
+func (x *Citation) GetTool() string {
 	if x != nil {
-		return x.ConversationId
+		return x.Tool
 	}
 	return ""
 }
 
-func (x *StartConversationResponse) GetTitle() string {
+func (x *Citation) GetSource() string {
 	if x != nil {
-		return x.Title
+		return x.Source
 	}
 	return ""
 }
 
-func (x *StartConversationResponse) GetReply() string {
+func (x *Citation) GetFetchedAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Reply
+		return x.FetchedAt
 	}
-	return ""
+	return nil
 }
 
-type ContinueConversationRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *Citation) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
 
-	ConversationId string `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
-	Message        string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+func (x *Citation) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
 }
 
-func (x *ContinueConversationRequest) Reset() {
-	*x = ContinueConversationRequest{}
+// A user's rating of an assistant reply
+type Feedback struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// up or down
+	Rating string `protobuf:"bytes,1,opt,name=rating,proto3" json:"rating,omitempty"`
+	// what was good or wrong about the reply, in the user's words
+	Comment       string                 `protobuf:"bytes,2,opt,name=comment,proto3" json:"comment,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Feedback) Reset() {
+	*x = Feedback{}
 	mi := &file_rpc_chat_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContinueConversationRequest) String() string {
+func (x *Feedback) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContinueConversationRequest) ProtoMessage() {}
+func (*Feedback) ProtoMessage() {}
 
-func (x *ContinueConversationRequest) ProtoReflect() protoreflect.Message {
+func (x *Feedback) ProtoReflect() protoreflect.Message {
 	mi := &file_rpc_chat_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -279,47 +454,57 @@ func (x *ContinueConversationRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContinueConversationRequest.ProtoReflect.Descriptor instead.
-func (*ContinueConversationRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Feedback.ProtoReflect.Descriptor instead.
+func (*Feedback) Descriptor() ([]byte, []int) {
 	return file_rpc_chat_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *ContinueConversationRequest) GetConversationId() string {
+func (x *Feedback) GetRating() string {
 	if x != nil {
-		return x.ConversationId
+		return x.Rating
 	}
 	return ""
 }
 
-func (x *ContinueConversationRequest) GetMessage() string {
+func (x *Feedback) GetComment() string {
 	if x != nil {
-		return x.Message
+		return x.Comment
 	}
 	return ""
 }
 
-type ContinueConversationResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *Feedback) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
 
-	Reply string `protobuf:"bytes,1,opt,name=reply,proto3" json:"reply,omitempty"`
+// A file stored alongside a message
+type Attachment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ContinueConversationResponse) Reset() {
-	*x = ContinueConversationResponse{}
+func (x *Attachment) Reset() {
+	*x = Attachment{}
 	mi := &file_rpc_chat_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContinueConversationResponse) String() string {
+func (x *Attachment) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContinueConversationResponse) ProtoMessage() {}
+func (*Attachment) ProtoMessage() {}
 
-func (x *ContinueConversationResponse) ProtoReflect() protoreflect.Message {
+func (x *Attachment) ProtoReflect() protoreflect.Message {
 	mi := &file_rpc_chat_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -331,38 +516,63 @@ func (x *ContinueConversationResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContinueConversationResponse.ProtoReflect.Descriptor instead.
-func (*ContinueConversationResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
 	return file_rpc_chat_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *ContinueConversationResponse) GetReply() string {
+func (x *Attachment) GetId() string {
 	if x != nil {
-		return x.Reply
+		return x.Id
 	}
 	return ""
 }
 
-type ListConversationsRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+func (x *Attachment) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Attachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// A file uploaded with a message; images (PNG, JPEG, WebP, GIF) and PDFs up to 10 MB
+type AttachmentUpload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListConversationsRequest) Reset() {
-	*x = ListConversationsRequest{}
+func (x *AttachmentUpload) Reset() {
+	*x = AttachmentUpload{}
 	mi := &file_rpc_chat_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListConversationsRequest) String() string {
+func (x *AttachmentUpload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListConversationsRequest) ProtoMessage() {}
+func (*AttachmentUpload) ProtoMessage() {}
 
-func (x *ListConversationsRequest) ProtoReflect() protoreflect.Message {
+func (x *AttachmentUpload) ProtoReflect() protoreflect.Message {
 	mi := &file_rpc_chat_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -374,33 +584,55 @@ func (x *ListConversationsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListConversationsRequest.ProtoReflect.Descriptor instead.
-func (*ListConversationsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AttachmentUpload.ProtoReflect.Descriptor instead.
+func (*AttachmentUpload) Descriptor() ([]byte, []int) {
 	return file_rpc_chat_proto_rawDescGZIP(), []int{5}
 }
 
-type ListConversationsResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *AttachmentUpload) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
 
-	Conversations []*Conversation `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+func (x *AttachmentUpload) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
 }
 
-func (x *ListConversationsResponse) Reset() {
-	*x = ListConversationsResponse{}
+func (x *AttachmentUpload) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Spoken version of an assistant reply
+type Audio struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Audio) Reset() {
+	*x = Audio{}
 	mi := &file_rpc_chat_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListConversationsResponse) String() string {
+func (x *Audio) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListConversationsResponse) ProtoMessage() {}
+func (*Audio) ProtoMessage() {}
 
-func (x *ListConversationsResponse) ProtoReflect() protoreflect.Message {
+func (x *Audio) ProtoReflect() protoreflect.Message {
 	mi := &file_rpc_chat_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -412,40 +644,60 @@ func (x *ListConversationsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListConversationsResponse.ProtoReflect.Descriptor instead.
-func (*ListConversationsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Audio.ProtoReflect.Descriptor instead.
+func (*Audio) Descriptor() ([]byte, []int) {
 	return file_rpc_chat_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *ListConversationsResponse) GetConversations() []*Conversation {
+func (x *Audio) GetData() []byte {
 	if x != nil {
-		return x.Conversations
+		return x.Data
 	}
 	return nil
 }
 
-type DescribeConversationRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *Audio) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
 
-	ConversationId string `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+type StartConversationRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Message     string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Attachments []*AttachmentUpload    `protobuf:"bytes,2,rep,name=attachments,proto3" json:"attachments,omitempty"`
+	// also return the reply as speech
+	Speak bool `protobuf:"varint,3,opt,name=speak,proto3" json:"speak,omitempty"`
+	// client-generated ID of this request, reused on retries to resume an interrupted reply
+	AttemptId string `protobuf:"bytes,4,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	// answer every reply of the conversation without tools: faster and cheaper for chit-chat
+	DisableTools bool `protobuf:"varint,5,opt,name=disable_tools,json=disableTools,proto3" json:"disable_tools,omitempty"`
+	// generation settings of the conversation's replies
+	Settings *GenerationSettings `protobuf:"bytes,6,opt,name=settings,proto3" json:"settings,omitempty"`
+	// character the assistant plays: budget-backpacker, luxury-concierge or family-planner; empty for the default assistant
+	Persona string `protobuf:"bytes,7,opt,name=persona,proto3" json:"persona,omitempty"`
+	// how long the client waits for the reply, in milliseconds; the reply is cut short to fit, and a DeadlineExceeded
+	// error reports the tool steps completed when even that is too late. Zero uses the server's budget.
+	TimeoutMs     int64 `protobuf:"varint,8,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DescribeConversationRequest) Reset() {
-	*x = DescribeConversationRequest{}
+func (x *StartConversationRequest) Reset() {
+	*x = StartConversationRequest{}
 	mi := &file_rpc_chat_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DescribeConversationRequest) String() string {
+func (x *StartConversationRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DescribeConversationRequest) ProtoMessage() {}
+func (*StartConversationRequest) ProtoMessage() {}
 
-func (x *DescribeConversationRequest) ProtoReflect() protoreflect.Message {
+func (x *StartConversationRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_rpc_chat_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -457,41 +709,3506 @@ func (x *DescribeConversationRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DescribeConversationRequest.ProtoReflect.Descriptor instead.
-func (*DescribeConversationRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use StartConversationRequest.ProtoReflect.Descriptor instead.
+func (*StartConversationRequest) Descriptor() ([]byte, []int) {
 	return file_rpc_chat_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *DescribeConversationRequest) GetConversationId() string {
+func (x *StartConversationRequest) GetMessage() string {
 	if x != nil {
-		return x.ConversationId
+		return x.Message
 	}
 	return ""
 }
 
-type DescribeConversationResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *StartConversationRequest) GetAttachments() []*AttachmentUpload {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
 
-	Conversation *Conversation `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+func (x *StartConversationRequest) GetSpeak() bool {
+	if x != nil {
+		return x.Speak
+	}
+	return false
 }
 
-func (x *DescribeConversationResponse) Reset() {
-	*x = DescribeConversationResponse{}
-	mi := &file_rpc_chat_proto_msgTypes[8]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+func (x *StartConversationRequest) GetAttemptId() string {
+	if x != nil {
+		return x.AttemptId
+	}
+	return ""
+}
+
+func (x *StartConversationRequest) GetDisableTools() bool {
+	if x != nil {
+		return x.DisableTools
+	}
+	return false
+}
+
+func (x *StartConversationRequest) GetSettings() *GenerationSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *StartConversationRequest) GetPersona() string {
+	if x != nil {
+		return x.Persona
+	}
+	return ""
+}
+
+func (x *StartConversationRequest) GetTimeoutMs() int64 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+type StartConversationResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Title          string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Reply          string                 `protobuf:"bytes,3,opt,name=reply,proto3" json:"reply,omitempty"`
+	// set when speak was requested and speech is available
+	ReplyAudio *Audio `protobuf:"bytes,4,opt,name=reply_audio,json=replyAudio,proto3" json:"reply_audio,omitempty"`
+	// suggested follow-up questions for quick replies
+	Suggestions []string `protobuf:"bytes,5,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	// sources of the facts of the reply
+	Citations []*Citation `protobuf:"bytes,6,rep,name=citations,proto3" json:"citations,omitempty"`
+	// when the oldest cited data was fetched, unset without citations
+	DataAsOf      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=data_as_of,json=dataAsOf,proto3" json:"data_as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartConversationResponse) Reset() {
+	*x = StartConversationResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartConversationResponse) ProtoMessage() {}
+
+func (x *StartConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartConversationResponse.ProtoReflect.Descriptor instead.
+func (*StartConversationResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StartConversationResponse) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *StartConversationResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *StartConversationResponse) GetReply() string {
+	if x != nil {
+		return x.Reply
+	}
+	return ""
+}
+
+func (x *StartConversationResponse) GetReplyAudio() *Audio {
+	if x != nil {
+		return x.ReplyAudio
+	}
+	return nil
+}
+
+func (x *StartConversationResponse) GetSuggestions() []string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+func (x *StartConversationResponse) GetCitations() []*Citation {
+	if x != nil {
+		return x.Citations
+	}
+	return nil
+}
+
+func (x *StartConversationResponse) GetDataAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DataAsOf
+	}
+	return nil
+}
+
+// A quick-start flow, e.g. a weekend getaway planner
+type ConversationTemplate struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// the assistant's first message of conversations started from the template
+	Greeting string `protobuf:"bytes,4,opt,name=greeting,proto3" json:"greeting,omitempty"`
+	// suggested first answers for quick replies
+	Suggestions   []string `protobuf:"bytes,5,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationTemplate) Reset() {
+	*x = ConversationTemplate{}
+	mi := &file_rpc_chat_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationTemplate) ProtoMessage() {}
+
+func (x *ConversationTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationTemplate.ProtoReflect.Descriptor instead.
+func (*ConversationTemplate) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ConversationTemplate) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ConversationTemplate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ConversationTemplate) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ConversationTemplate) GetGreeting() string {
+	if x != nil {
+		return x.Greeting
+	}
+	return ""
+}
+
+func (x *ConversationTemplate) GetSuggestions() []string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+type ListConversationTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationTemplatesRequest) Reset() {
+	*x = ListConversationTemplatesRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationTemplatesRequest) ProtoMessage() {}
+
+func (x *ListConversationTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*ListConversationTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{10}
+}
+
+type ListConversationTemplatesResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Templates     []*ConversationTemplate `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationTemplatesResponse) Reset() {
+	*x = ListConversationTemplatesResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationTemplatesResponse) ProtoMessage() {}
+
+func (x *ListConversationTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*ListConversationTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListConversationTemplatesResponse) GetTemplates() []*ConversationTemplate {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+type StartConversationFromTemplateRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	TemplateId string                 `protobuf:"bytes,1,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`
+	// answer every reply of the conversation without tools: faster and cheaper for chit-chat
+	DisableTools bool `protobuf:"varint,2,opt,name=disable_tools,json=disableTools,proto3" json:"disable_tools,omitempty"`
+	// generation settings of the conversation's replies
+	Settings *GenerationSettings `protobuf:"bytes,3,opt,name=settings,proto3" json:"settings,omitempty"`
+	// character the assistant plays: budget-backpacker, luxury-concierge or family-planner; empty for the default assistant
+	Persona       string `protobuf:"bytes,4,opt,name=persona,proto3" json:"persona,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartConversationFromTemplateRequest) Reset() {
+	*x = StartConversationFromTemplateRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartConversationFromTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartConversationFromTemplateRequest) ProtoMessage() {}
+
+func (x *StartConversationFromTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartConversationFromTemplateRequest.ProtoReflect.Descriptor instead.
+func (*StartConversationFromTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StartConversationFromTemplateRequest) GetTemplateId() string {
+	if x != nil {
+		return x.TemplateId
+	}
+	return ""
+}
+
+func (x *StartConversationFromTemplateRequest) GetDisableTools() bool {
+	if x != nil {
+		return x.DisableTools
+	}
+	return false
+}
+
+func (x *StartConversationFromTemplateRequest) GetSettings() *GenerationSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *StartConversationFromTemplateRequest) GetPersona() string {
+	if x != nil {
+		return x.Persona
+	}
+	return ""
+}
+
+type StartConversationFromTemplateResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Title          string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// the assistant's first message, the template's greeting
+	Reply string `protobuf:"bytes,3,opt,name=reply,proto3" json:"reply,omitempty"`
+	// suggested first answers for quick replies
+	Suggestions   []string `protobuf:"bytes,4,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartConversationFromTemplateResponse) Reset() {
+	*x = StartConversationFromTemplateResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartConversationFromTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartConversationFromTemplateResponse) ProtoMessage() {}
+
+func (x *StartConversationFromTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartConversationFromTemplateResponse.ProtoReflect.Descriptor instead.
+func (*StartConversationFromTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StartConversationFromTemplateResponse) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *StartConversationFromTemplateResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *StartConversationFromTemplateResponse) GetReply() string {
+	if x != nil {
+		return x.Reply
+	}
+	return ""
+}
+
+func (x *StartConversationFromTemplateResponse) GetSuggestions() []string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+type ContinueConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Attachments    []*AttachmentUpload    `protobuf:"bytes,3,rep,name=attachments,proto3" json:"attachments,omitempty"`
+	// also return the reply as speech
+	Speak bool `protobuf:"varint,4,opt,name=speak,proto3" json:"speak,omitempty"`
+	// client-generated ID of this request, reused on retries to resume an interrupted reply
+	AttemptId string `protobuf:"bytes,5,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	// answer this message without tools; the conversation's setting applies otherwise
+	DisableTools bool `protobuf:"varint,6,opt,name=disable_tools,json=disableTools,proto3" json:"disable_tools,omitempty"`
+	// updates the conversation's generation settings, from this message on; empty fields are kept
+	Settings *GenerationSettings `protobuf:"bytes,7,opt,name=settings,proto3" json:"settings,omitempty"`
+	// several messages sent in quick succession, stored in order and answered with a single reply; use instead of
+	// message, attachments go with the last one
+	Messages []string `protobuf:"bytes,8,rep,name=messages,proto3" json:"messages,omitempty"`
+	// how long the client waits for the reply, in milliseconds; the reply is cut short to fit, and a DeadlineExceeded
+	// error reports the tool steps completed when even that is too late. Zero uses the server's budget.
+	TimeoutMs int64 `protobuf:"varint,9,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	// return at once, with the reply queued for generation in the background; poll DescribeConversation or
+	// SyncConversation for it, or wait for the reply.ready event. Can't be combined with speak or timeout_ms.
+	Queue         bool `protobuf:"varint,10,opt,name=queue,proto3" json:"queue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContinueConversationRequest) Reset() {
+	*x = ContinueConversationRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContinueConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContinueConversationRequest) ProtoMessage() {}
+
+func (x *ContinueConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContinueConversationRequest.ProtoReflect.Descriptor instead.
+func (*ContinueConversationRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ContinueConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ContinueConversationRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ContinueConversationRequest) GetAttachments() []*AttachmentUpload {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+func (x *ContinueConversationRequest) GetSpeak() bool {
+	if x != nil {
+		return x.Speak
+	}
+	return false
+}
+
+func (x *ContinueConversationRequest) GetAttemptId() string {
+	if x != nil {
+		return x.AttemptId
+	}
+	return ""
+}
+
+func (x *ContinueConversationRequest) GetDisableTools() bool {
+	if x != nil {
+		return x.DisableTools
+	}
+	return false
+}
+
+func (x *ContinueConversationRequest) GetSettings() *GenerationSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *ContinueConversationRequest) GetMessages() []string {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *ContinueConversationRequest) GetTimeoutMs() int64 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+func (x *ContinueConversationRequest) GetQueue() bool {
+	if x != nil {
+		return x.Queue
+	}
+	return false
+}
+
+type ContinueConversationResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// empty while the reply is queued
+	Reply string `protobuf:"bytes,1,opt,name=reply,proto3" json:"reply,omitempty"`
+	// set when speak was requested and speech is available
+	ReplyAudio *Audio `protobuf:"bytes,2,opt,name=reply_audio,json=replyAudio,proto3" json:"reply_audio,omitempty"`
+	// suggested follow-up questions for quick replies
+	Suggestions []string `protobuf:"bytes,3,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	// sources of the facts of the reply
+	Citations []*Citation `protobuf:"bytes,4,rep,name=citations,proto3" json:"citations,omitempty"`
+	// when the oldest cited data was fetched, unset without citations
+	DataAsOf *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=data_as_of,json=dataAsOf,proto3" json:"data_as_of,omitempty"`
+	// ID of the reply message
+	ReplyId string `protobuf:"bytes,6,opt,name=reply_id,json=replyId,proto3" json:"reply_id,omitempty"`
+	// queued when the reply was queued, completed otherwise
+	Status        string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContinueConversationResponse) Reset() {
+	*x = ContinueConversationResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContinueConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContinueConversationResponse) ProtoMessage() {}
+
+func (x *ContinueConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContinueConversationResponse.ProtoReflect.Descriptor instead.
+func (*ContinueConversationResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ContinueConversationResponse) GetReply() string {
+	if x != nil {
+		return x.Reply
+	}
+	return ""
+}
+
+func (x *ContinueConversationResponse) GetReplyAudio() *Audio {
+	if x != nil {
+		return x.ReplyAudio
+	}
+	return nil
+}
+
+func (x *ContinueConversationResponse) GetSuggestions() []string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+func (x *ContinueConversationResponse) GetCitations() []*Citation {
+	if x != nil {
+		return x.Citations
+	}
+	return nil
+}
+
+func (x *ContinueConversationResponse) GetDataAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DataAsOf
+	}
+	return nil
+}
+
+func (x *ContinueConversationResponse) GetReplyId() string {
+	if x != nil {
+		return x.ReplyId
+	}
+	return ""
+}
+
+func (x *ContinueConversationResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type RefreshReplyRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// assistant message to refresh, the conversation's last reply when empty
+	MessageId     string `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshReplyRequest) Reset() {
+	*x = RefreshReplyRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshReplyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshReplyRequest) ProtoMessage() {}
+
+func (x *RefreshReplyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshReplyRequest.ProtoReflect.Descriptor instead.
+func (*RefreshReplyRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RefreshReplyRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *RefreshReplyRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+type RefreshReplyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the updated reply, with the citations of the fresh data
+	Message       *Conversation_Message  `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	DataAsOf      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=data_as_of,json=dataAsOf,proto3" json:"data_as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshReplyResponse) Reset() {
+	*x = RefreshReplyResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshReplyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshReplyResponse) ProtoMessage() {}
+
+func (x *RefreshReplyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshReplyResponse.ProtoReflect.Descriptor instead.
+func (*RefreshReplyResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RefreshReplyResponse) GetMessage() *Conversation_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *RefreshReplyResponse) GetDataAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DataAsOf
+	}
+	return nil
+}
+
+type SubmitFeedbackRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// assistant message rated
+	MessageId string `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// up or down; empty removes the feedback
+	Rating string `protobuf:"bytes,3,opt,name=rating,proto3" json:"rating,omitempty"`
+	// optional, at most 2000 characters
+	Comment       string `protobuf:"bytes,4,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitFeedbackRequest) Reset() {
+	*x = SubmitFeedbackRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitFeedbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitFeedbackRequest) ProtoMessage() {}
+
+func (x *SubmitFeedbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitFeedbackRequest.ProtoReflect.Descriptor instead.
+func (*SubmitFeedbackRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SubmitFeedbackRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *SubmitFeedbackRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *SubmitFeedbackRequest) GetRating() string {
+	if x != nil {
+		return x.Rating
+	}
+	return ""
+}
+
+func (x *SubmitFeedbackRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type SubmitFeedbackResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the rated message, with its feedback
+	Message       *Conversation_Message `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitFeedbackResponse) Reset() {
+	*x = SubmitFeedbackResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitFeedbackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitFeedbackResponse) ProtoMessage() {}
+
+func (x *SubmitFeedbackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitFeedbackResponse.ProtoReflect.Descriptor instead.
+func (*SubmitFeedbackResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SubmitFeedbackResponse) GetMessage() *Conversation_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type FlagConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// optional, the message reported
+	MessageId string `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// harmful, inappropriate, privacy or other
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// optional, at most 2000 characters
+	Comment       string `protobuf:"bytes,4,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlagConversationRequest) Reset() {
+	*x = FlagConversationRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlagConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlagConversationRequest) ProtoMessage() {}
+
+func (x *FlagConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlagConversationRequest.ProtoReflect.Descriptor instead.
+func (*FlagConversationRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *FlagConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *FlagConversationRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *FlagConversationRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *FlagConversationRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type FlagConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlagConversationResponse) Reset() {
+	*x = FlagConversationResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlagConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlagConversationResponse) ProtoMessage() {}
+
+func (x *FlagConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlagConversationResponse.ProtoReflect.Descriptor instead.
+func (*FlagConversationResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{21}
+}
+
+type ExportItineraryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExportItineraryRequest) Reset() {
+	*x = ExportItineraryRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportItineraryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportItineraryRequest) ProtoMessage() {}
+
+func (x *ExportItineraryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportItineraryRequest.ProtoReflect.Descriptor instead.
+func (*ExportItineraryRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ExportItineraryRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+type ExportItineraryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. "weekend-in-lisbon.ics"
+	Filename string `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// text/calendar
+	ContentType string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// iCalendar (RFC 5545) file with an event per flight, stay, transfer and activity
+	Content       []byte `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportItineraryResponse) Reset() {
+	*x = ExportItineraryResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportItineraryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportItineraryResponse) ProtoMessage() {}
+
+func (x *ExportItineraryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportItineraryResponse.ProtoReflect.Descriptor instead.
+func (*ExportItineraryResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ExportItineraryResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *ExportItineraryResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ExportItineraryResponse) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type ExportTripPDFRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExportTripPDFRequest) Reset() {
+	*x = ExportTripPDFRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportTripPDFRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTripPDFRequest) ProtoMessage() {}
+
+func (x *ExportTripPDFRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTripPDFRequest.ProtoReflect.Descriptor instead.
+func (*ExportTripPDFRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ExportTripPDFRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+type ExportTripPDFResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. "weekend-in-lisbon.pdf"
+	Filename string `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// application/pdf
+	ContentType string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// A4 document branded for the tenant
+	Content       []byte `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportTripPDFResponse) Reset() {
+	*x = ExportTripPDFResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportTripPDFResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTripPDFResponse) ProtoMessage() {}
+
+func (x *ExportTripPDFResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTripPDFResponse.ProtoReflect.Descriptor instead.
+func (*ExportTripPDFResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ExportTripPDFResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *ExportTripPDFResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ExportTripPDFResponse) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type ExportUserDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUserDataRequest) Reset() {
+	*x = ExportUserDataRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUserDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUserDataRequest) ProtoMessage() {}
+
+func (x *ExportUserDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUserDataRequest.ProtoReflect.Descriptor instead.
+func (*ExportUserDataRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{26}
+}
+
+type ExportUserDataResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. "takeout-2025-06-01.zip"
+	Filename string `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// application/zip
+	ContentType string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// zip of manifest.json, profile.json, conversations.json, itineraries.json and reminders.json
+	Content       []byte `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUserDataResponse) Reset() {
+	*x = ExportUserDataResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUserDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUserDataResponse) ProtoMessage() {}
+
+func (x *ExportUserDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUserDataResponse.ProtoReflect.Descriptor instead.
+func (*ExportUserDataResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ExportUserDataResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *ExportUserDataResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ExportUserDataResponse) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type ImportUserDataRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// zip returned by ExportUserData, of this user or another
+	Content       []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportUserDataRequest) Reset() {
+	*x = ImportUserDataRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportUserDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportUserDataRequest) ProtoMessage() {}
+
+func (x *ImportUserDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportUserDataRequest.ProtoReflect.Descriptor instead.
+func (*ImportUserDataRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ImportUserDataRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type ImportUserDataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversations int32                  `protobuf:"varint,1,opt,name=conversations,proto3" json:"conversations,omitempty"`
+	Itineraries   int32                  `protobuf:"varint,2,opt,name=itineraries,proto3" json:"itineraries,omitempty"`
+	Reminders     int32                  `protobuf:"varint,3,opt,name=reminders,proto3" json:"reminders,omitempty"`
+	// whether the profile was replaced by the one of the takeout
+	Profile       bool `protobuf:"varint,4,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportUserDataResponse) Reset() {
+	*x = ImportUserDataResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportUserDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportUserDataResponse) ProtoMessage() {}
+
+func (x *ImportUserDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportUserDataResponse.ProtoReflect.Descriptor instead.
+func (*ImportUserDataResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ImportUserDataResponse) GetConversations() int32 {
+	if x != nil {
+		return x.Conversations
+	}
+	return 0
+}
+
+func (x *ImportUserDataResponse) GetItineraries() int32 {
+	if x != nil {
+		return x.Itineraries
+	}
+	return 0
+}
+
+func (x *ImportUserDataResponse) GetReminders() int32 {
+	if x != nil {
+		return x.Reminders
+	}
+	return 0
+}
+
+func (x *ImportUserDataResponse) GetProfile() bool {
+	if x != nil {
+		return x.Profile
+	}
+	return false
+}
+
+type ListConversationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// only list conversations with this tag
+	Tag string `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	// only list conversations in this folder
+	Folder string `protobuf:"bytes,2,opt,name=folder,proto3" json:"folder,omitempty"`
+	// list archived conversations instead of active ones
+	Archived bool `protobuf:"varint,3,opt,name=archived,proto3" json:"archived,omitempty"`
+	// only list favorite conversations
+	Favorites     bool `protobuf:"varint,4,opt,name=favorites,proto3" json:"favorites,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationsRequest) Reset() {
+	*x = ListConversationsRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsRequest) ProtoMessage() {}
+
+func (x *ListConversationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsRequest.ProtoReflect.Descriptor instead.
+func (*ListConversationsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListConversationsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ListConversationsRequest) GetFolder() string {
+	if x != nil {
+		return x.Folder
+	}
+	return ""
+}
+
+func (x *ListConversationsRequest) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+func (x *ListConversationsRequest) GetFavorites() bool {
+	if x != nil {
+		return x.Favorites
+	}
+	return false
+}
+
+type ListConversationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversations []*Conversation        `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationsResponse) Reset() {
+	*x = ListConversationsResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsResponse) ProtoMessage() {}
+
+func (x *ListConversationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsResponse.ProtoReflect.Descriptor instead.
+func (*ListConversationsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListConversationsResponse) GetConversations() []*Conversation {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+type DescribeConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// false leaves the messages out, for views needing the title and timestamps only; messages are included by default
+	IncludeMessages *bool `protobuf:"varint,2,opt,name=include_messages,json=includeMessages,proto3,oneof" json:"include_messages,omitempty"`
+	// only return the last k messages, 0 returning them all
+	LastNMessages int32 `protobuf:"varint,3,opt,name=last_n_messages,json=lastNMessages,proto3" json:"last_n_messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeConversationRequest) Reset() {
+	*x = DescribeConversationRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeConversationRequest) ProtoMessage() {}
+
+func (x *DescribeConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeConversationRequest.ProtoReflect.Descriptor instead.
+func (*DescribeConversationRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DescribeConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *DescribeConversationRequest) GetIncludeMessages() bool {
+	if x != nil && x.IncludeMessages != nil {
+		return *x.IncludeMessages
+	}
+	return false
+}
+
+func (x *DescribeConversationRequest) GetLastNMessages() int32 {
+	if x != nil {
+		return x.LastNMessages
+	}
+	return 0
+}
+
+type DescribeConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeConversationResponse) Reset() {
+	*x = DescribeConversationResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeConversationResponse) ProtoMessage() {}
+
+func (x *DescribeConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeConversationResponse.ProtoReflect.Descriptor instead.
+func (*DescribeConversationResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DescribeConversationResponse) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+type SyncConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// revision of the last sync, 0 for a full sync
+	Revision int64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	// synced_at of the last sync, unset for a full sync
+	SyncedAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=synced_at,json=syncedAt,proto3" json:"synced_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncConversationRequest) Reset() {
+	*x = SyncConversationRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncConversationRequest) ProtoMessage() {}
+
+func (x *SyncConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncConversationRequest.ProtoReflect.Descriptor instead.
+func (*SyncConversationRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SyncConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *SyncConversationRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *SyncConversationRequest) GetSyncedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SyncedAt
+	}
+	return nil
+}
+
+type SyncConversationResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the conversation hasn't changed since the given revision; nothing else is set but the revision
+	Unchanged bool  `protobuf:"varint,1,opt,name=unchanged,proto3" json:"unchanged,omitempty"`
+	Revision  int64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	// the fields of the conversation, without messages
+	Conversation *Conversation `protobuf:"bytes,3,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	// messages added or updated since the last sync, in order; messages updated just before it may be sent again
+	Messages []*Conversation_Message `protobuf:"bytes,4,rep,name=messages,proto3" json:"messages,omitempty"`
+	// IDs of all the messages of the conversation, in order; the others were removed, like failed replies once retried
+	MessageIds []string `protobuf:"bytes,5,rep,name=message_ids,json=messageIds,proto3" json:"message_ids,omitempty"`
+	// to send with the next sync
+	SyncedAt      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=synced_at,json=syncedAt,proto3" json:"synced_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncConversationResponse) Reset() {
+	*x = SyncConversationResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncConversationResponse) ProtoMessage() {}
+
+func (x *SyncConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncConversationResponse.ProtoReflect.Descriptor instead.
+func (*SyncConversationResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SyncConversationResponse) GetUnchanged() bool {
+	if x != nil {
+		return x.Unchanged
+	}
+	return false
+}
+
+func (x *SyncConversationResponse) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *SyncConversationResponse) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+func (x *SyncConversationResponse) GetMessages() []*Conversation_Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *SyncConversationResponse) GetMessageIds() []string {
+	if x != nil {
+		return x.MessageIds
+	}
+	return nil
+}
+
+func (x *SyncConversationResponse) GetSyncedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SyncedAt
+	}
+	return nil
+}
+
+type UpdateConversationLabelsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// replaces the conversation's tags; tags are lowercased and deduplicated
+	Tags []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	// moves the conversation to this folder, or out of any folder when empty
+	Folder        string `protobuf:"bytes,3,opt,name=folder,proto3" json:"folder,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateConversationLabelsRequest) Reset() {
+	*x = UpdateConversationLabelsRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateConversationLabelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateConversationLabelsRequest) ProtoMessage() {}
+
+func (x *UpdateConversationLabelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateConversationLabelsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateConversationLabelsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *UpdateConversationLabelsRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *UpdateConversationLabelsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UpdateConversationLabelsRequest) GetFolder() string {
+	if x != nil {
+		return x.Folder
+	}
+	return ""
+}
+
+type UpdateConversationLabelsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateConversationLabelsResponse) Reset() {
+	*x = UpdateConversationLabelsResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateConversationLabelsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateConversationLabelsResponse) ProtoMessage() {}
+
+func (x *UpdateConversationLabelsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateConversationLabelsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateConversationLabelsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *UpdateConversationLabelsResponse) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+type PinConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// unpin the conversation instead
+	Unpin         bool `protobuf:"varint,2,opt,name=unpin,proto3" json:"unpin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinConversationRequest) Reset() {
+	*x = PinConversationRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinConversationRequest) ProtoMessage() {}
+
+func (x *PinConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinConversationRequest.ProtoReflect.Descriptor instead.
+func (*PinConversationRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *PinConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *PinConversationRequest) GetUnpin() bool {
+	if x != nil {
+		return x.Unpin
+	}
+	return false
+}
+
+type PinConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinConversationResponse) Reset() {
+	*x = PinConversationResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinConversationResponse) ProtoMessage() {}
+
+func (x *PinConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinConversationResponse.ProtoReflect.Descriptor instead.
+func (*PinConversationResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *PinConversationResponse) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+type FavoriteConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// unmark the conversation instead
+	Unfavorite    bool `protobuf:"varint,2,opt,name=unfavorite,proto3" json:"unfavorite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FavoriteConversationRequest) Reset() {
+	*x = FavoriteConversationRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FavoriteConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FavoriteConversationRequest) ProtoMessage() {}
+
+func (x *FavoriteConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FavoriteConversationRequest.ProtoReflect.Descriptor instead.
+func (*FavoriteConversationRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *FavoriteConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *FavoriteConversationRequest) GetUnfavorite() bool {
+	if x != nil {
+		return x.Unfavorite
+	}
+	return false
+}
+
+type FavoriteConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FavoriteConversationResponse) Reset() {
+	*x = FavoriteConversationResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FavoriteConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FavoriteConversationResponse) ProtoMessage() {}
+
+func (x *FavoriteConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FavoriteConversationResponse.ProtoReflect.Descriptor instead.
+func (*FavoriteConversationResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *FavoriteConversationResponse) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+type BatchDeleteConversationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// up to 500 conversation IDs; IDs of missing or other users' conversations are skipped
+	ConversationIds []string `protobuf:"bytes,1,rep,name=conversation_ids,json=conversationIds,proto3" json:"conversation_ids,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *BatchDeleteConversationsRequest) Reset() {
+	*x = BatchDeleteConversationsRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDeleteConversationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteConversationsRequest) ProtoMessage() {}
+
+func (x *BatchDeleteConversationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteConversationsRequest.ProtoReflect.Descriptor instead.
+func (*BatchDeleteConversationsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *BatchDeleteConversationsRequest) GetConversationIds() []string {
+	if x != nil {
+		return x.ConversationIds
+	}
+	return nil
+}
+
+type BatchDeleteConversationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeletedCount  int32                  `protobuf:"varint,1,opt,name=deleted_count,json=deletedCount,proto3" json:"deleted_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchDeleteConversationsResponse) Reset() {
+	*x = BatchDeleteConversationsResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDeleteConversationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteConversationsResponse) ProtoMessage() {}
+
+func (x *BatchDeleteConversationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteConversationsResponse.ProtoReflect.Descriptor instead.
+func (*BatchDeleteConversationsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *BatchDeleteConversationsResponse) GetDeletedCount() int32 {
+	if x != nil {
+		return x.DeletedCount
+	}
+	return 0
+}
+
+type BatchArchiveConversationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// up to 500 conversation IDs; IDs of missing or other users' conversations are skipped
+	ConversationIds []string `protobuf:"bytes,1,rep,name=conversation_ids,json=conversationIds,proto3" json:"conversation_ids,omitempty"`
+	// move the conversations back out of the archive
+	Unarchive     bool `protobuf:"varint,2,opt,name=unarchive,proto3" json:"unarchive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchArchiveConversationsRequest) Reset() {
+	*x = BatchArchiveConversationsRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchArchiveConversationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchArchiveConversationsRequest) ProtoMessage() {}
+
+func (x *BatchArchiveConversationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchArchiveConversationsRequest.ProtoReflect.Descriptor instead.
+func (*BatchArchiveConversationsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *BatchArchiveConversationsRequest) GetConversationIds() []string {
+	if x != nil {
+		return x.ConversationIds
+	}
+	return nil
+}
+
+func (x *BatchArchiveConversationsRequest) GetUnarchive() bool {
+	if x != nil {
+		return x.Unarchive
+	}
+	return false
+}
+
+type BatchArchiveConversationsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// number of conversations whose archive state changed
+	UpdatedCount  int32 `protobuf:"varint,1,opt,name=updated_count,json=updatedCount,proto3" json:"updated_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchArchiveConversationsResponse) Reset() {
+	*x = BatchArchiveConversationsResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchArchiveConversationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchArchiveConversationsResponse) ProtoMessage() {}
+
+func (x *BatchArchiveConversationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchArchiveConversationsResponse.ProtoReflect.Descriptor instead.
+func (*BatchArchiveConversationsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *BatchArchiveConversationsResponse) GetUpdatedCount() int32 {
+	if x != nil {
+		return x.UpdatedCount
+	}
+	return 0
+}
+
+type ConversationStats struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId        string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Title                 string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	MessageCount          int32                  `protobuf:"varint,3,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"`
+	UserMessageCount      int32                  `protobuf:"varint,4,opt,name=user_message_count,json=userMessageCount,proto3" json:"user_message_count,omitempty"`
+	AssistantMessageCount int32                  `protobuf:"varint,5,opt,name=assistant_message_count,json=assistantMessageCount,proto3" json:"assistant_message_count,omitempty"`
+	// tools called to answer the conversation's messages, most used first
+	ToolUsage []*ConversationStats_ToolUsage `protobuf:"bytes,6,rep,name=tool_usage,json=toolUsage,proto3" json:"tool_usage,omitempty"`
+	// model tokens spent on replies
+	InputTokens     int64                  `protobuf:"varint,7,opt,name=input_tokens,json=inputTokens,proto3" json:"input_tokens,omitempty"`
+	OutputTokens    int64                  `protobuf:"varint,8,opt,name=output_tokens,json=outputTokens,proto3" json:"output_tokens,omitempty"`
+	FirstActivityAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=first_activity_at,json=firstActivityAt,proto3" json:"first_activity_at,omitempty"`
+	LastActivityAt  *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"`
+	// time between the first and last message
+	DurationSeconds int64 `protobuf:"varint,11,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ConversationStats) Reset() {
+	*x = ConversationStats{}
+	mi := &file_rpc_chat_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationStats) ProtoMessage() {}
+
+func (x *ConversationStats) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationStats.ProtoReflect.Descriptor instead.
+func (*ConversationStats) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ConversationStats) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ConversationStats) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ConversationStats) GetMessageCount() int32 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+func (x *ConversationStats) GetUserMessageCount() int32 {
+	if x != nil {
+		return x.UserMessageCount
+	}
+	return 0
+}
+
+func (x *ConversationStats) GetAssistantMessageCount() int32 {
+	if x != nil {
+		return x.AssistantMessageCount
+	}
+	return 0
+}
+
+func (x *ConversationStats) GetToolUsage() []*ConversationStats_ToolUsage {
+	if x != nil {
+		return x.ToolUsage
+	}
+	return nil
+}
+
+func (x *ConversationStats) GetInputTokens() int64 {
+	if x != nil {
+		return x.InputTokens
+	}
+	return 0
+}
+
+func (x *ConversationStats) GetOutputTokens() int64 {
+	if x != nil {
+		return x.OutputTokens
+	}
+	return 0
+}
+
+func (x *ConversationStats) GetFirstActivityAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstActivityAt
+	}
+	return nil
+}
+
+func (x *ConversationStats) GetLastActivityAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActivityAt
+	}
+	return nil
+}
+
+func (x *ConversationStats) GetDurationSeconds() int64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+type GetConversationStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// up to 500 conversation IDs; when empty, statistics of all the caller's conversations
+	ConversationIds []string `protobuf:"bytes,1,rep,name=conversation_ids,json=conversationIds,proto3" json:"conversation_ids,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetConversationStatsRequest) Reset() {
+	*x = GetConversationStatsRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationStatsRequest) ProtoMessage() {}
+
+func (x *GetConversationStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetConversationStatsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *GetConversationStatsRequest) GetConversationIds() []string {
+	if x != nil {
+		return x.ConversationIds
+	}
+	return nil
+}
+
+type GetConversationStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// most recently active first
+	Conversations []*ConversationStats `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConversationStatsResponse) Reset() {
+	*x = GetConversationStatsResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationStatsResponse) ProtoMessage() {}
+
+func (x *GetConversationStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetConversationStatsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *GetConversationStatsResponse) GetConversations() []*ConversationStats {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+type Webhook struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Events        []string               `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Webhook) Reset() {
+	*x = Webhook{}
+	mi := &file_rpc_chat_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Webhook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Webhook) ProtoMessage() {}
+
+func (x *Webhook) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Webhook.ProtoReflect.Descriptor instead.
+func (*Webhook) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *Webhook) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Webhook) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Webhook) GetEvents() []string {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *Webhook) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type WebhookDelivery struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	WebhookId      string                 `protobuf:"bytes,2,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	EventId        string                 `protobuf:"bytes,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	EventType      string                 `protobuf:"bytes,4,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Status         string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Attempts       int32                  `protobuf:"varint,6,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	ResponseStatus int32                  `protobuf:"varint,7,opt,name=response_status,json=responseStatus,proto3" json:"response_status,omitempty"`
+	LastError      string                 `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Timestamp      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WebhookDelivery) Reset() {
+	*x = WebhookDelivery{}
+	mi := &file_rpc_chat_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookDelivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookDelivery) ProtoMessage() {}
+
+func (x *WebhookDelivery) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookDelivery.ProtoReflect.Descriptor instead.
+func (*WebhookDelivery) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *WebhookDelivery) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetWebhookId() string {
+	if x != nil {
+		return x.WebhookId
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetResponseStatus() int32 {
+	if x != nil {
+		return x.ResponseStatus
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type CreateWebhookRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// event types to subscribe to, all events if empty
+	Events        []string `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWebhookRequest) Reset() {
+	*x = CreateWebhookRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWebhookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWebhookRequest) ProtoMessage() {}
+
+func (x *CreateWebhookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWebhookRequest.ProtoReflect.Descriptor instead.
+func (*CreateWebhookRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *CreateWebhookRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CreateWebhookRequest) GetEvents() []string {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type CreateWebhookResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Webhook *Webhook               `protobuf:"bytes,1,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	// secret used to sign deliveries, only returned on creation
+	Secret        string `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWebhookResponse) Reset() {
+	*x = CreateWebhookResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWebhookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWebhookResponse) ProtoMessage() {}
+
+func (x *CreateWebhookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWebhookResponse.ProtoReflect.Descriptor instead.
+func (*CreateWebhookResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *CreateWebhookResponse) GetWebhook() *Webhook {
+	if x != nil {
+		return x.Webhook
+	}
+	return nil
+}
+
+func (x *CreateWebhookResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+type ListWebhooksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhooksRequest) Reset() {
+	*x = ListWebhooksRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhooksRequest) ProtoMessage() {}
+
+func (x *ListWebhooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhooksRequest.ProtoReflect.Descriptor instead.
+func (*ListWebhooksRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{53}
+}
+
+type ListWebhooksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Webhooks      []*Webhook             `protobuf:"bytes,1,rep,name=webhooks,proto3" json:"webhooks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhooksResponse) Reset() {
+	*x = ListWebhooksResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhooksResponse) ProtoMessage() {}
+
+func (x *ListWebhooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhooksResponse.ProtoReflect.Descriptor instead.
+func (*ListWebhooksResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *ListWebhooksResponse) GetWebhooks() []*Webhook {
+	if x != nil {
+		return x.Webhooks
+	}
+	return nil
+}
+
+type DeleteWebhookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WebhookId     string                 `protobuf:"bytes,1,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteWebhookRequest) Reset() {
+	*x = DeleteWebhookRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteWebhookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteWebhookRequest) ProtoMessage() {}
+
+func (x *DeleteWebhookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteWebhookRequest.ProtoReflect.Descriptor instead.
+func (*DeleteWebhookRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *DeleteWebhookRequest) GetWebhookId() string {
+	if x != nil {
+		return x.WebhookId
+	}
+	return ""
+}
+
+type DeleteWebhookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteWebhookResponse) Reset() {
+	*x = DeleteWebhookResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteWebhookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteWebhookResponse) ProtoMessage() {}
+
+func (x *DeleteWebhookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteWebhookResponse.ProtoReflect.Descriptor instead.
+func (*DeleteWebhookResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{56}
+}
+
+type ListWebhookDeliveriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WebhookId     string                 `protobuf:"bytes,1,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhookDeliveriesRequest) Reset() {
+	*x = ListWebhookDeliveriesRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhookDeliveriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhookDeliveriesRequest) ProtoMessage() {}
+
+func (x *ListWebhookDeliveriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhookDeliveriesRequest.ProtoReflect.Descriptor instead.
+func (*ListWebhookDeliveriesRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *ListWebhookDeliveriesRequest) GetWebhookId() string {
+	if x != nil {
+		return x.WebhookId
+	}
+	return ""
+}
+
+func (x *ListWebhookDeliveriesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListWebhookDeliveriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deliveries    []*WebhookDelivery     `protobuf:"bytes,1,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhookDeliveriesResponse) Reset() {
+	*x = ListWebhookDeliveriesResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhookDeliveriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhookDeliveriesResponse) ProtoMessage() {}
+
+func (x *ListWebhookDeliveriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhookDeliveriesResponse.ProtoReflect.Descriptor instead.
+func (*ListWebhookDeliveriesResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *ListWebhookDeliveriesResponse) GetDeliveries() []*WebhookDelivery {
+	if x != nil {
+		return x.Deliveries
+	}
+	return nil
+}
+
+type Profile struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	UserId          string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Email           string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	SlackWebhookUrl string                 `protobuf:"bytes,3,opt,name=slack_webhook_url,json=slackWebhookUrl,proto3" json:"slack_webhook_url,omitempty"`
+	// notification channels alerts are sent to, e.g. "email", "slack" or "whatsapp"
+	Channels  []string               `protobuf:"bytes,4,rep,name=channels,proto3" json:"channels,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// in international format, e.g. "+34600123456"; users writing on WhatsApp get alerts on the number they write from
+	WhatsappNumber string `protobuf:"bytes,6,opt,name=whatsapp_number,json=whatsappNumber,proto3" json:"whatsapp_number,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Profile) Reset() {
+	*x = Profile{}
+	mi := &file_rpc_chat_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Profile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Profile) ProtoMessage() {}
+
+func (x *Profile) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Profile.ProtoReflect.Descriptor instead.
+func (*Profile) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *Profile) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Profile) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Profile) GetSlackWebhookUrl() string {
+	if x != nil {
+		return x.SlackWebhookUrl
+	}
+	return ""
+}
+
+func (x *Profile) GetChannels() []string {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+func (x *Profile) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Profile) GetWhatsappNumber() string {
+	if x != nil {
+		return x.WhatsappNumber
+	}
+	return ""
+}
+
+type GetProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProfileRequest) Reset() {
+	*x = GetProfileRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProfileRequest) ProtoMessage() {}
+
+func (x *GetProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProfileRequest.ProtoReflect.Descriptor instead.
+func (*GetProfileRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{60}
+}
+
+type GetProfileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Profile       *Profile               `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProfileResponse) Reset() {
+	*x = GetProfileResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProfileResponse) ProtoMessage() {}
+
+func (x *GetProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProfileResponse.ProtoReflect.Descriptor instead.
+func (*GetProfileResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *GetProfileResponse) GetProfile() *Profile {
+	if x != nil {
+		return x.Profile
+	}
+	return nil
+}
+
+type UpdateProfileRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Email           string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	SlackWebhookUrl string                 `protobuf:"bytes,2,opt,name=slack_webhook_url,json=slackWebhookUrl,proto3" json:"slack_webhook_url,omitempty"`
+	Channels        []string               `protobuf:"bytes,3,rep,name=channels,proto3" json:"channels,omitempty"`
+	WhatsappNumber  string                 `protobuf:"bytes,4,opt,name=whatsapp_number,json=whatsappNumber,proto3" json:"whatsapp_number,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateProfileRequest) Reset() {
+	*x = UpdateProfileRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProfileRequest) ProtoMessage() {}
+
+func (x *UpdateProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProfileRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProfileRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *UpdateProfileRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UpdateProfileRequest) GetSlackWebhookUrl() string {
+	if x != nil {
+		return x.SlackWebhookUrl
+	}
+	return ""
+}
+
+func (x *UpdateProfileRequest) GetChannels() []string {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+func (x *UpdateProfileRequest) GetWhatsappNumber() string {
+	if x != nil {
+		return x.WhatsappNumber
+	}
+	return ""
+}
+
+type UpdateProfileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Profile       *Profile               `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProfileResponse) Reset() {
+	*x = UpdateProfileResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProfileResponse) ProtoMessage() {}
+
+func (x *UpdateProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProfileResponse.ProtoReflect.Descriptor instead.
+func (*UpdateProfileResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *UpdateProfileResponse) GetProfile() *Profile {
+	if x != nil {
+		return x.Profile
+	}
+	return nil
+}
+
+type Share struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ConversationId string                 `protobuf:"bytes,2,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// signed token identifying the share, part of the public URL
+	Token         string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+	Url           string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Revoked       bool                   `protobuf:"varint,7,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Share) Reset() {
+	*x = Share{}
+	mi := &file_rpc_chat_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Share) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Share) ProtoMessage() {}
+
+func (x *Share) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Share.ProtoReflect.Descriptor instead.
+func (*Share) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *Share) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Share) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *Share) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Share) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Share) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Share) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Share) GetRevoked() bool {
+	if x != nil {
+		return x.Revoked
+	}
+	return false
+}
+
+type ShareConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// how long the link stays valid, defaults to 7 days and is capped at 30 days
+	TtlSeconds    int64 `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareConversationRequest) Reset() {
+	*x = ShareConversationRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareConversationRequest) ProtoMessage() {}
+
+func (x *ShareConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareConversationRequest.ProtoReflect.Descriptor instead.
+func (*ShareConversationRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ShareConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ShareConversationRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type ShareConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Share         *Share                 `protobuf:"bytes,1,opt,name=share,proto3" json:"share,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareConversationResponse) Reset() {
+	*x = ShareConversationResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DescribeConversationResponse) String() string {
+func (x *ShareConversationResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DescribeConversationResponse) ProtoMessage() {}
+func (*ShareConversationResponse) ProtoMessage() {}
 
-func (x *DescribeConversationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_rpc_chat_proto_msgTypes[8]
+func (x *ShareConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -502,32 +4219,309 @@ func (x *DescribeConversationResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DescribeConversationResponse.ProtoReflect.Descriptor instead.
-func (*DescribeConversationResponse) Descriptor() ([]byte, []int) {
-	return file_rpc_chat_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ShareConversationResponse.ProtoReflect.Descriptor instead.
+func (*ShareConversationResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *DescribeConversationResponse) GetConversation() *Conversation {
+func (x *ShareConversationResponse) GetShare() *Share {
 	if x != nil {
-		return x.Conversation
+		return x.Share
 	}
 	return nil
 }
 
-type Conversation_Message struct {
-	state         protoimpl.MessageState
+type RevokeShareRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShareId       string                 `protobuf:"bytes,1,opt,name=share_id,json=shareId,proto3" json:"share_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeShareRequest) Reset() {
+	*x = RevokeShareRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeShareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeShareRequest) ProtoMessage() {}
+
+func (x *RevokeShareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeShareRequest.ProtoReflect.Descriptor instead.
+func (*RevokeShareRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *RevokeShareRequest) GetShareId() string {
+	if x != nil {
+		return x.ShareId
+	}
+	return ""
+}
+
+type RevokeShareResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeShareResponse) Reset() {
+	*x = RevokeShareResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeShareResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeShareResponse) ProtoMessage() {}
+
+func (x *RevokeShareResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeShareResponse.ProtoReflect.Descriptor instead.
+func (*RevokeShareResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{68}
+}
+
+type SendVoiceMessageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// continue this conversation, or start a new one when empty
+	ConversationId string `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// recorded audio, up to 25 MB
+	Audio []byte `protobuf:"bytes,2,opt,name=audio,proto3" json:"audio,omitempty"`
+	// audio file name, its extension tells the format: flac, mp3, mp4, mpeg, mpga, m4a, ogg, wav or webm
+	Filename string `protobuf:"bytes,3,opt,name=filename,proto3" json:"filename,omitempty"`
+	// optional ISO-639-1 language of the recording, e.g. "en"
+	Language string `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`
+	// also return the reply as speech
+	Speak         bool `protobuf:"varint,5,opt,name=speak,proto3" json:"speak,omitempty"`
+	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendVoiceMessageRequest) Reset() {
+	*x = SendVoiceMessageRequest{}
+	mi := &file_rpc_chat_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendVoiceMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendVoiceMessageRequest) ProtoMessage() {}
+
+func (x *SendVoiceMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendVoiceMessageRequest.ProtoReflect.Descriptor instead.
+func (*SendVoiceMessageRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *SendVoiceMessageRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *SendVoiceMessageRequest) GetAudio() []byte {
+	if x != nil {
+		return x.Audio
+	}
+	return nil
+}
+
+func (x *SendVoiceMessageRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *SendVoiceMessageRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *SendVoiceMessageRequest) GetSpeak() bool {
+	if x != nil {
+		return x.Speak
+	}
+	return false
+}
+
+type SendVoiceMessageResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	// set when a new conversation was started
+	Title      string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Transcript string `protobuf:"bytes,3,opt,name=transcript,proto3" json:"transcript,omitempty"`
+	Reply      string `protobuf:"bytes,4,opt,name=reply,proto3" json:"reply,omitempty"`
+	// set when speak was requested and speech is available
+	ReplyAudio *Audio `protobuf:"bytes,5,opt,name=reply_audio,json=replyAudio,proto3" json:"reply_audio,omitempty"`
+	// suggested follow-up questions for quick replies
+	Suggestions []string `protobuf:"bytes,6,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	// sources of the facts of the reply
+	Citations []*Citation `protobuf:"bytes,7,rep,name=citations,proto3" json:"citations,omitempty"`
+	// when the oldest cited data was fetched, unset without citations
+	DataAsOf      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=data_as_of,json=dataAsOf,proto3" json:"data_as_of,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendVoiceMessageResponse) Reset() {
+	*x = SendVoiceMessageResponse{}
+	mi := &file_rpc_chat_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Role      Conversation_Role      `protobuf:"varint,2,opt,name=role,proto3,enum=acai.chat.Conversation_Role" json:"role,omitempty"`
-	Content   string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
-	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+func (x *SendVoiceMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendVoiceMessageResponse) ProtoMessage() {}
+
+func (x *SendVoiceMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendVoiceMessageResponse.ProtoReflect.Descriptor instead.
+func (*SendVoiceMessageResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *SendVoiceMessageResponse) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *SendVoiceMessageResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SendVoiceMessageResponse) GetTranscript() string {
+	if x != nil {
+		return x.Transcript
+	}
+	return ""
+}
+
+func (x *SendVoiceMessageResponse) GetReply() string {
+	if x != nil {
+		return x.Reply
+	}
+	return ""
+}
+
+func (x *SendVoiceMessageResponse) GetReplyAudio() *Audio {
+	if x != nil {
+		return x.ReplyAudio
+	}
+	return nil
+}
+
+func (x *SendVoiceMessageResponse) GetSuggestions() []string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+func (x *SendVoiceMessageResponse) GetCitations() []*Citation {
+	if x != nil {
+		return x.Citations
+	}
+	return nil
+}
+
+func (x *SendVoiceMessageResponse) GetDataAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DataAsOf
+	}
+	return nil
+}
+
+type Conversation_Message struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Role        Conversation_Role      `protobuf:"varint,2,opt,name=role,proto3,enum=acai.chat.Conversation_Role" json:"role,omitempty"`
+	Content     string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	Timestamp   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Attachments []*Attachment          `protobuf:"bytes,5,rep,name=attachments,proto3" json:"attachments,omitempty"`
+	// suggested follow-up questions, set on assistant messages
+	Suggestions []string `protobuf:"bytes,6,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	// detected intent of user messages: weather, flights, holidays or general
+	Intent string `protobuf:"bytes,7,opt,name=intent,proto3" json:"intent,omitempty"`
+	// sources of the facts of assistant messages
+	Citations []*Citation `protobuf:"bytes,8,rep,name=citations,proto3" json:"citations,omitempty"`
+	// pending or failed for user messages waiting for or without a reply, queued or generating for replies waiting
+	// for a worker or in flight, completed otherwise
+	Status string `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	// the user's rating of assistant messages, see SubmitFeedback
+	Feedback      *Feedback `protobuf:"bytes,10,opt,name=feedback,proto3" json:"feedback,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Conversation_Message) Reset() {
 	*x = Conversation_Message{}
-	mi := &file_rpc_chat_proto_msgTypes[9]
+	mi := &file_rpc_chat_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -539,7 +4533,7 @@ func (x *Conversation_Message) String() string {
 func (*Conversation_Message) ProtoMessage() {}
 
 func (x *Conversation_Message) ProtoReflect() protoreflect.Message {
-	mi := &file_rpc_chat_proto_msgTypes[9]
+	mi := &file_rpc_chat_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -583,152 +4577,701 @@ func (x *Conversation_Message) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
-var File_rpc_chat_proto protoreflect.FileDescriptor
+func (x *Conversation_Message) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+func (x *Conversation_Message) GetSuggestions() []string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+func (x *Conversation_Message) GetIntent() string {
+	if x != nil {
+		return x.Intent
+	}
+	return ""
+}
+
+func (x *Conversation_Message) GetCitations() []*Citation {
+	if x != nil {
+		return x.Citations
+	}
+	return nil
+}
+
+func (x *Conversation_Message) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Conversation_Message) GetFeedback() *Feedback {
+	if x != nil {
+		return x.Feedback
+	}
+	return nil
+}
+
+type ConversationStats_ToolUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationStats_ToolUsage) Reset() {
+	*x = ConversationStats_ToolUsage{}
+	mi := &file_rpc_chat_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationStats_ToolUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationStats_ToolUsage) ProtoMessage() {}
+
+func (x *ConversationStats_ToolUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_chat_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationStats_ToolUsage.ProtoReflect.Descriptor instead.
+func (*ConversationStats_ToolUsage) Descriptor() ([]byte, []int) {
+	return file_rpc_chat_proto_rawDescGZIP(), []int{46, 0}
+}
+
+func (x *ConversationStats_ToolUsage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
 
-var file_rpc_chat_proto_rawDesc = []byte{
-	0x0a, 0x0e, 0x72, 0x70, 0x63, 0x2f, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x09, 0x61, 0x63, 0x61, 0x69, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xfb, 0x02, 0x0a,
-	0x0c, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a,
-	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a,
-	0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69,
-	0x74, 0x6c, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
-	0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x3b, 0x0a,
-	0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x1f, 0x2e, 0x61, 0x63, 0x61, 0x69, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x76,
-	0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x1a, 0x9f, 0x01, 0x0a, 0x07, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x30, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x61, 0x63, 0x61, 0x69, 0x2e, 0x63, 0x68, 0x61, 0x74,
-	0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x52, 0x6f,
-	0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74,
-	0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65,
-	0x6e, 0x74, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
-	0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x2c, 0x0a, 0x04,
-	0x52, 0x6f, 0x6c, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10,
-	0x00, 0x12, 0x08, 0x0a, 0x04, 0x55, 0x53, 0x45, 0x52, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x41,
-	0x53, 0x53, 0x49, 0x53, 0x54, 0x41, 0x4e, 0x54, 0x10, 0x02, 0x22, 0x34, 0x0a, 0x18, 0x53, 0x74,
-	0x61, 0x72, 0x74, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x22, 0x70, 0x0a, 0x19, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a,
-	0x0f, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05,
-	0x72, 0x65, 0x70, 0x6c, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x65, 0x70,
-	0x6c, 0x79, 0x22, 0x60, 0x0a, 0x1b, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x65, 0x43, 0x6f,
-	0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x6f, 0x6e, 0x76,
-	0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x22, 0x34, 0x0a, 0x1c, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x65,
-	0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x1a, 0x0a, 0x18, 0x4c, 0x69,
-	0x73, 0x74, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x5a, 0x0a, 0x19, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f,
-	0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x63, 0x61,
-	0x69, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x73, 0x22, 0x46, 0x0a, 0x1b, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x43, 0x6f,
-	0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x6f, 0x6e, 0x76,
-	0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x5b, 0x0a, 0x1c, 0x44, 0x65,
-	0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x0c, 0x63, 0x6f,
-	0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x17, 0x2e, 0x61, 0x63, 0x61, 0x69, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x43, 0x6f, 0x6e,
-	0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x63, 0x6f, 0x6e, 0x76, 0x65,
-	0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x32, 0x9f, 0x03, 0x0a, 0x0b, 0x43, 0x68, 0x61, 0x74,
-	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5e, 0x0a, 0x11, 0x53, 0x74, 0x61, 0x72, 0x74,
-	0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x2e, 0x61,
-	0x63, 0x61, 0x69, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6f,
-	0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x24, 0x2e, 0x61, 0x63, 0x61, 0x69, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x53, 0x74,
-	0x61, 0x72, 0x74, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x67, 0x0a, 0x14, 0x43, 0x6f, 0x6e, 0x74, 0x69,
-	0x6e, 0x75, 0x65, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
-	0x26, 0x2e, 0x61, 0x63, 0x61, 0x69, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x74,
-	0x69, 0x6e, 0x75, 0x65, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x61, 0x63, 0x61, 0x69, 0x2e, 0x63,
-	0x68, 0x61, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x65, 0x43, 0x6f, 0x6e, 0x76,
-	0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x5e, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x23, 0x2e, 0x61, 0x63, 0x61, 0x69, 0x2e, 0x63, 0x68, 0x61,
-	0x74, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x61, 0x63, 0x61,
-	0x69, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x76, 0x65,
-	0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x67, 0x0a, 0x14, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x43, 0x6f, 0x6e, 0x76,
-	0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x26, 0x2e, 0x61, 0x63, 0x61, 0x69, 0x2e,
-	0x63, 0x68, 0x61, 0x74, 0x2e, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x43, 0x6f, 0x6e,
-	0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x27, 0x2e, 0x61, 0x63, 0x61, 0x69, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x44, 0x65, 0x73,
-	0x63, 0x72, 0x69, 0x62, 0x65, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x0d, 0x5a, 0x0b, 0x69, 0x6e, 0x74,
-	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *ConversationStats_ToolUsage) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
 }
 
+var File_rpc_chat_proto protoreflect.FileDescriptor
+
+const file_rpc_chat_proto_rawDesc = "" +
+	"\n" +
+	"\x0erpc/chat.proto\x12\tacai.chat\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe8\b\n" +
+	"\fConversation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12;\n" +
+	"\bmessages\x18\x04 \x03(\v2\x1f.acai.chat.Conversation.MessageR\bmessages\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x16\n" +
+	"\x06folder\x18\x06 \x01(\tR\x06folder\x12;\n" +
+	"\varchived_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"archivedAt\x12%\n" +
+	"\x0etools_disabled\x18\b \x01(\bR\rtoolsDisabled\x129\n" +
+	"\bsettings\x18\t \x01(\v2\x1d.acai.chat.GenerationSettingsR\bsettings\x12\x1f\n" +
+	"\vtemplate_id\x18\n" +
+	" \x01(\tR\n" +
+	"templateId\x12\x18\n" +
+	"\apersona\x18\v \x01(\tR\apersona\x12!\n" +
+	"\ftitle_source\x18\f \x01(\tR\vtitleSource\x12\x18\n" +
+	"\asummary\x18\r \x01(\tR\asummary\x12#\n" +
+	"\rauto_archived\x18\x0e \x01(\bR\fautoArchived\x12\x1a\n" +
+	"\brevision\x18\x0f \x01(\x03R\brevision\x127\n" +
+	"\tpinned_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\bpinnedAt\x12\x1a\n" +
+	"\bfavorite\x18\x11 \x01(\bR\bfavorite\x12#\n" +
+	"\rmessage_count\x18\x12 \x01(\x05R\fmessageCount\x1a\x8e\x03\n" +
+	"\aMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x120\n" +
+	"\x04role\x18\x02 \x01(\x0e2\x1c.acai.chat.Conversation.RoleR\x04role\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x127\n" +
+	"\vattachments\x18\x05 \x03(\v2\x15.acai.chat.AttachmentR\vattachments\x12 \n" +
+	"\vsuggestions\x18\x06 \x03(\tR\vsuggestions\x12\x16\n" +
+	"\x06intent\x18\a \x01(\tR\x06intent\x121\n" +
+	"\tcitations\x18\b \x03(\v2\x13.acai.chat.CitationR\tcitations\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12/\n" +
+	"\bfeedback\x18\n" +
+	" \x01(\v2\x13.acai.chat.FeedbackR\bfeedback\",\n" +
+	"\x04Role\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\b\n" +
+	"\x04USER\x10\x01\x12\r\n" +
+	"\tASSISTANT\x10\x02\"\x89\x01\n" +
+	"\x12GenerationSettings\x12\x1c\n" +
+	"\tverbosity\x18\x01 \x01(\tR\tverbosity\x12\x1e\n" +
+	"\n" +
+	"creativity\x18\x02 \x01(\tR\n" +
+	"creativity\x12\x16\n" +
+	"\x06format\x18\x03 \x01(\tR\x06format\x12\x1d\n" +
+	"\n" +
+	"max_length\x18\x04 \x01(\x05R\tmaxLength\"\xc2\x01\n" +
+	"\bCitation\x12\x12\n" +
+	"\x04tool\x18\x01 \x01(\tR\x04tool\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x129\n" +
+	"\n" +
+	"fetched_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tfetchedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x14\n" +
+	"\x05stale\x18\x05 \x01(\bR\x05stale\"w\n" +
+	"\bFeedback\x12\x16\n" +
+	"\x06rating\x18\x01 \x01(\tR\x06rating\x12\x18\n" +
+	"\acomment\x18\x02 \x01(\tR\acomment\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"o\n" +
+	"\n" +
+	"Attachment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\"e\n" +
+	"\x10AttachmentUpload\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\">\n" +
+	"\x05Audio\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\"\xc1\x02\n" +
+	"\x18StartConversationRequest\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12=\n" +
+	"\vattachments\x18\x02 \x03(\v2\x1b.acai.chat.AttachmentUploadR\vattachments\x12\x14\n" +
+	"\x05speak\x18\x03 \x01(\bR\x05speak\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x04 \x01(\tR\tattemptId\x12#\n" +
+	"\rdisable_tools\x18\x05 \x01(\bR\fdisableTools\x129\n" +
+	"\bsettings\x18\x06 \x01(\v2\x1d.acai.chat.GenerationSettingsR\bsettings\x12\x18\n" +
+	"\apersona\x18\a \x01(\tR\apersona\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\b \x01(\x03R\ttimeoutMs\"\xb2\x02\n" +
+	"\x19StartConversationResponse\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x14\n" +
+	"\x05reply\x18\x03 \x01(\tR\x05reply\x121\n" +
+	"\vreply_audio\x18\x04 \x01(\v2\x10.acai.chat.AudioR\n" +
+	"replyAudio\x12 \n" +
+	"\vsuggestions\x18\x05 \x03(\tR\vsuggestions\x121\n" +
+	"\tcitations\x18\x06 \x03(\v2\x13.acai.chat.CitationR\tcitations\x128\n" +
+	"\n" +
+	"data_as_of\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\bdataAsOf\"\x9a\x01\n" +
+	"\x14ConversationTemplate\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bgreeting\x18\x04 \x01(\tR\bgreeting\x12 \n" +
+	"\vsuggestions\x18\x05 \x03(\tR\vsuggestions\"\"\n" +
+	" ListConversationTemplatesRequest\"b\n" +
+	"!ListConversationTemplatesResponse\x12=\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x1f.acai.chat.ConversationTemplateR\ttemplates\"\xc1\x01\n" +
+	"$StartConversationFromTemplateRequest\x12\x1f\n" +
+	"\vtemplate_id\x18\x01 \x01(\tR\n" +
+	"templateId\x12#\n" +
+	"\rdisable_tools\x18\x02 \x01(\bR\fdisableTools\x129\n" +
+	"\bsettings\x18\x03 \x01(\v2\x1d.acai.chat.GenerationSettingsR\bsettings\x12\x18\n" +
+	"\apersona\x18\x04 \x01(\tR\apersona\"\x9e\x01\n" +
+	"%StartConversationFromTemplateResponse\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x14\n" +
+	"\x05reply\x18\x03 \x01(\tR\x05reply\x12 \n" +
+	"\vsuggestions\x18\x04 \x03(\tR\vsuggestions\"\x85\x03\n" +
+	"\x1bContinueConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12=\n" +
+	"\vattachments\x18\x03 \x03(\v2\x1b.acai.chat.AttachmentUploadR\vattachments\x12\x14\n" +
+	"\x05speak\x18\x04 \x01(\bR\x05speak\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x05 \x01(\tR\tattemptId\x12#\n" +
+	"\rdisable_tools\x18\x06 \x01(\bR\fdisableTools\x129\n" +
+	"\bsettings\x18\a \x01(\v2\x1d.acai.chat.GenerationSettingsR\bsettings\x12\x1a\n" +
+	"\bmessages\x18\b \x03(\tR\bmessages\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\t \x01(\x03R\ttimeoutMs\x12\x14\n" +
+	"\x05queue\x18\n" +
+	" \x01(\bR\x05queue\"\xa9\x02\n" +
+	"\x1cContinueConversationResponse\x12\x14\n" +
+	"\x05reply\x18\x01 \x01(\tR\x05reply\x121\n" +
+	"\vreply_audio\x18\x02 \x01(\v2\x10.acai.chat.AudioR\n" +
+	"replyAudio\x12 \n" +
+	"\vsuggestions\x18\x03 \x03(\tR\vsuggestions\x121\n" +
+	"\tcitations\x18\x04 \x03(\v2\x13.acai.chat.CitationR\tcitations\x128\n" +
+	"\n" +
+	"data_as_of\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bdataAsOf\x12\x19\n" +
+	"\breply_id\x18\x06 \x01(\tR\areplyId\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\"]\n" +
+	"\x13RefreshReplyRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\"\x8b\x01\n" +
+	"\x14RefreshReplyResponse\x129\n" +
+	"\amessage\x18\x01 \x01(\v2\x1f.acai.chat.Conversation.MessageR\amessage\x128\n" +
+	"\n" +
+	"data_as_of\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bdataAsOf\"\x91\x01\n" +
+	"\x15SubmitFeedbackRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x16\n" +
+	"\x06rating\x18\x03 \x01(\tR\x06rating\x12\x18\n" +
+	"\acomment\x18\x04 \x01(\tR\acomment\"S\n" +
+	"\x16SubmitFeedbackResponse\x129\n" +
+	"\amessage\x18\x01 \x01(\v2\x1f.acai.chat.Conversation.MessageR\amessage\"\x93\x01\n" +
+	"\x17FlagConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x18\n" +
+	"\acomment\x18\x04 \x01(\tR\acomment\"\x1a\n" +
+	"\x18FlagConversationResponse\"A\n" +
+	"\x16ExportItineraryRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"r\n" +
+	"\x17ExportItineraryResponse\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\fR\acontent\"?\n" +
+	"\x14ExportTripPDFRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"p\n" +
+	"\x15ExportTripPDFResponse\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\fR\acontent\"\x17\n" +
+	"\x15ExportUserDataRequest\"q\n" +
+	"\x16ExportUserDataResponse\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\fR\acontent\"1\n" +
+	"\x15ImportUserDataRequest\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\fR\acontent\"\x98\x01\n" +
+	"\x16ImportUserDataResponse\x12$\n" +
+	"\rconversations\x18\x01 \x01(\x05R\rconversations\x12 \n" +
+	"\vitineraries\x18\x02 \x01(\x05R\vitineraries\x12\x1c\n" +
+	"\treminders\x18\x03 \x01(\x05R\treminders\x12\x18\n" +
+	"\aprofile\x18\x04 \x01(\bR\aprofile\"~\n" +
+	"\x18ListConversationsRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12\x16\n" +
+	"\x06folder\x18\x02 \x01(\tR\x06folder\x12\x1a\n" +
+	"\barchived\x18\x03 \x01(\bR\barchived\x12\x1c\n" +
+	"\tfavorites\x18\x04 \x01(\bR\tfavorites\"Z\n" +
+	"\x19ListConversationsResponse\x12=\n" +
+	"\rconversations\x18\x01 \x03(\v2\x17.acai.chat.ConversationR\rconversations\"\xb3\x01\n" +
+	"\x1bDescribeConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12.\n" +
+	"\x10include_messages\x18\x02 \x01(\bH\x00R\x0fincludeMessages\x88\x01\x01\x12&\n" +
+	"\x0flast_n_messages\x18\x03 \x01(\x05R\rlastNMessagesB\x13\n" +
+	"\x11_include_messages\"[\n" +
+	"\x1cDescribeConversationResponse\x12;\n" +
+	"\fconversation\x18\x01 \x01(\v2\x17.acai.chat.ConversationR\fconversation\"\x97\x01\n" +
+	"\x17SyncConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x03R\brevision\x127\n" +
+	"\tsynced_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bsyncedAt\"\xa8\x02\n" +
+	"\x18SyncConversationResponse\x12\x1c\n" +
+	"\tunchanged\x18\x01 \x01(\bR\tunchanged\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x03R\brevision\x12;\n" +
+	"\fconversation\x18\x03 \x01(\v2\x17.acai.chat.ConversationR\fconversation\x12;\n" +
+	"\bmessages\x18\x04 \x03(\v2\x1f.acai.chat.Conversation.MessageR\bmessages\x12\x1f\n" +
+	"\vmessage_ids\x18\x05 \x03(\tR\n" +
+	"messageIds\x127\n" +
+	"\tsynced_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bsyncedAt\"v\n" +
+	"\x1fUpdateConversationLabelsRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12\x16\n" +
+	"\x06folder\x18\x03 \x01(\tR\x06folder\"_\n" +
+	" UpdateConversationLabelsResponse\x12;\n" +
+	"\fconversation\x18\x01 \x01(\v2\x17.acai.chat.ConversationR\fconversation\"W\n" +
+	"\x16PinConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05unpin\x18\x02 \x01(\bR\x05unpin\"V\n" +
+	"\x17PinConversationResponse\x12;\n" +
+	"\fconversation\x18\x01 \x01(\v2\x17.acai.chat.ConversationR\fconversation\"f\n" +
+	"\x1bFavoriteConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1e\n" +
+	"\n" +
+	"unfavorite\x18\x02 \x01(\bR\n" +
+	"unfavorite\"[\n" +
+	"\x1cFavoriteConversationResponse\x12;\n" +
+	"\fconversation\x18\x01 \x01(\v2\x17.acai.chat.ConversationR\fconversation\"L\n" +
+	"\x1fBatchDeleteConversationsRequest\x12)\n" +
+	"\x10conversation_ids\x18\x01 \x03(\tR\x0fconversationIds\"G\n" +
+	" BatchDeleteConversationsResponse\x12#\n" +
+	"\rdeleted_count\x18\x01 \x01(\x05R\fdeletedCount\"k\n" +
+	" BatchArchiveConversationsRequest\x12)\n" +
+	"\x10conversation_ids\x18\x01 \x03(\tR\x0fconversationIds\x12\x1c\n" +
+	"\tunarchive\x18\x02 \x01(\bR\tunarchive\"H\n" +
+	"!BatchArchiveConversationsResponse\x12#\n" +
+	"\rupdated_count\x18\x01 \x01(\x05R\fupdatedCount\"\xdc\x04\n" +
+	"\x11ConversationStats\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12#\n" +
+	"\rmessage_count\x18\x03 \x01(\x05R\fmessageCount\x12,\n" +
+	"\x12user_message_count\x18\x04 \x01(\x05R\x10userMessageCount\x126\n" +
+	"\x17assistant_message_count\x18\x05 \x01(\x05R\x15assistantMessageCount\x12E\n" +
+	"\n" +
+	"tool_usage\x18\x06 \x03(\v2&.acai.chat.ConversationStats.ToolUsageR\ttoolUsage\x12!\n" +
+	"\finput_tokens\x18\a \x01(\x03R\vinputTokens\x12#\n" +
+	"\routput_tokens\x18\b \x01(\x03R\foutputTokens\x12F\n" +
+	"\x11first_activity_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x0ffirstActivityAt\x12D\n" +
+	"\x10last_activity_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x0elastActivityAt\x12)\n" +
+	"\x10duration_seconds\x18\v \x01(\x03R\x0fdurationSeconds\x1a5\n" +
+	"\tToolUsage\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"H\n" +
+	"\x1bGetConversationStatsRequest\x12)\n" +
+	"\x10conversation_ids\x18\x01 \x03(\tR\x0fconversationIds\"b\n" +
+	"\x1cGetConversationStatsResponse\x12B\n" +
+	"\rconversations\x18\x01 \x03(\v2\x1c.acai.chat.ConversationStatsR\rconversations\"~\n" +
+	"\aWebhook\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x16\n" +
+	"\x06events\x18\x03 \x03(\tR\x06events\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xb0\x02\n" +
+	"\x0fWebhookDelivery\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x02 \x01(\tR\twebhookId\x12\x19\n" +
+	"\bevent_id\x18\x03 \x01(\tR\aeventId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x04 \x01(\tR\teventType\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1a\n" +
+	"\battempts\x18\x06 \x01(\x05R\battempts\x12'\n" +
+	"\x0fresponse_status\x18\a \x01(\x05R\x0eresponseStatus\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\b \x01(\tR\tlastError\x128\n" +
+	"\ttimestamp\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"@\n" +
+	"\x14CreateWebhookRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x16\n" +
+	"\x06events\x18\x02 \x03(\tR\x06events\"]\n" +
+	"\x15CreateWebhookResponse\x12,\n" +
+	"\awebhook\x18\x01 \x01(\v2\x12.acai.chat.WebhookR\awebhook\x12\x16\n" +
+	"\x06secret\x18\x02 \x01(\tR\x06secret\"\x15\n" +
+	"\x13ListWebhooksRequest\"F\n" +
+	"\x14ListWebhooksResponse\x12.\n" +
+	"\bwebhooks\x18\x01 \x03(\v2\x12.acai.chat.WebhookR\bwebhooks\"5\n" +
+	"\x14DeleteWebhookRequest\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x01 \x01(\tR\twebhookId\"\x17\n" +
+	"\x15DeleteWebhookResponse\"S\n" +
+	"\x1cListWebhookDeliveriesRequest\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x01 \x01(\tR\twebhookId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"[\n" +
+	"\x1dListWebhookDeliveriesResponse\x12:\n" +
+	"\n" +
+	"deliveries\x18\x01 \x03(\v2\x1a.acai.chat.WebhookDeliveryR\n" +
+	"deliveries\"\xe4\x01\n" +
+	"\aProfile\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12*\n" +
+	"\x11slack_webhook_url\x18\x03 \x01(\tR\x0fslackWebhookUrl\x12\x1a\n" +
+	"\bchannels\x18\x04 \x03(\tR\bchannels\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12'\n" +
+	"\x0fwhatsapp_number\x18\x06 \x01(\tR\x0ewhatsappNumber\"\x13\n" +
+	"\x11GetProfileRequest\"B\n" +
+	"\x12GetProfileResponse\x12,\n" +
+	"\aprofile\x18\x01 \x01(\v2\x12.acai.chat.ProfileR\aprofile\"\x9d\x01\n" +
+	"\x14UpdateProfileRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12*\n" +
+	"\x11slack_webhook_url\x18\x02 \x01(\tR\x0fslackWebhookUrl\x12\x1a\n" +
+	"\bchannels\x18\x03 \x03(\tR\bchannels\x12'\n" +
+	"\x0fwhatsapp_number\x18\x04 \x01(\tR\x0ewhatsappNumber\"E\n" +
+	"\x15UpdateProfileResponse\x12,\n" +
+	"\aprofile\x18\x01 \x01(\v2\x12.acai.chat.ProfileR\aprofile\"\xf8\x01\n" +
+	"\x05Share\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0fconversation_id\x18\x02 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\x12\x10\n" +
+	"\x03url\x18\x04 \x01(\tR\x03url\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x18\n" +
+	"\arevoked\x18\a \x01(\bR\arevoked\"d\n" +
+	"\x18ShareConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\"C\n" +
+	"\x19ShareConversationResponse\x12&\n" +
+	"\x05share\x18\x01 \x01(\v2\x10.acai.chat.ShareR\x05share\"/\n" +
+	"\x12RevokeShareRequest\x12\x19\n" +
+	"\bshare_id\x18\x01 \x01(\tR\ashareId\"\x15\n" +
+	"\x13RevokeShareResponse\"\xa6\x01\n" +
+	"\x17SendVoiceMessageRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05audio\x18\x02 \x01(\fR\x05audio\x12\x1a\n" +
+	"\bfilename\x18\x03 \x01(\tR\bfilename\x12\x1a\n" +
+	"\blanguage\x18\x04 \x01(\tR\blanguage\x12\x14\n" +
+	"\x05speak\x18\x05 \x01(\bR\x05speak\"\xd1\x02\n" +
+	"\x18SendVoiceMessageResponse\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x1e\n" +
+	"\n" +
+	"transcript\x18\x03 \x01(\tR\n" +
+	"transcript\x12\x14\n" +
+	"\x05reply\x18\x04 \x01(\tR\x05reply\x121\n" +
+	"\vreply_audio\x18\x05 \x01(\v2\x10.acai.chat.AudioR\n" +
+	"replyAudio\x12 \n" +
+	"\vsuggestions\x18\x06 \x03(\tR\vsuggestions\x121\n" +
+	"\tcitations\x18\a \x03(\v2\x13.acai.chat.CitationR\tcitations\x128\n" +
+	"\n" +
+	"data_as_of\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bdataAsOf2\xf7\x15\n" +
+	"\vChatService\x12^\n" +
+	"\x11StartConversation\x12#.acai.chat.StartConversationRequest\x1a$.acai.chat.StartConversationResponse\x12v\n" +
+	"\x19ListConversationTemplates\x12+.acai.chat.ListConversationTemplatesRequest\x1a,.acai.chat.ListConversationTemplatesResponse\x12\x82\x01\n" +
+	"\x1dStartConversationFromTemplate\x12/.acai.chat.StartConversationFromTemplateRequest\x1a0.acai.chat.StartConversationFromTemplateResponse\x12g\n" +
+	"\x14ContinueConversation\x12&.acai.chat.ContinueConversationRequest\x1a'.acai.chat.ContinueConversationResponse\x12^\n" +
+	"\x11ListConversations\x12#.acai.chat.ListConversationsRequest\x1a$.acai.chat.ListConversationsResponse\x12g\n" +
+	"\x14DescribeConversation\x12&.acai.chat.DescribeConversationRequest\x1a'.acai.chat.DescribeConversationResponse\x12[\n" +
+	"\x10SyncConversation\x12\".acai.chat.SyncConversationRequest\x1a#.acai.chat.SyncConversationResponse\x12s\n" +
+	"\x18UpdateConversationLabels\x12*.acai.chat.UpdateConversationLabelsRequest\x1a+.acai.chat.UpdateConversationLabelsResponse\x12X\n" +
+	"\x0fPinConversation\x12!.acai.chat.PinConversationRequest\x1a\".acai.chat.PinConversationResponse\x12g\n" +
+	"\x14FavoriteConversation\x12&.acai.chat.FavoriteConversationRequest\x1a'.acai.chat.FavoriteConversationResponse\x12R\n" +
+	"\rCreateWebhook\x12\x1f.acai.chat.CreateWebhookRequest\x1a .acai.chat.CreateWebhookResponse\x12O\n" +
+	"\fListWebhooks\x12\x1e.acai.chat.ListWebhooksRequest\x1a\x1f.acai.chat.ListWebhooksResponse\x12R\n" +
+	"\rDeleteWebhook\x12\x1f.acai.chat.DeleteWebhookRequest\x1a .acai.chat.DeleteWebhookResponse\x12j\n" +
+	"\x15ListWebhookDeliveries\x12'.acai.chat.ListWebhookDeliveriesRequest\x1a(.acai.chat.ListWebhookDeliveriesResponse\x12I\n" +
+	"\n" +
+	"GetProfile\x12\x1c.acai.chat.GetProfileRequest\x1a\x1d.acai.chat.GetProfileResponse\x12R\n" +
+	"\rUpdateProfile\x12\x1f.acai.chat.UpdateProfileRequest\x1a .acai.chat.UpdateProfileResponse\x12^\n" +
+	"\x11ShareConversation\x12#.acai.chat.ShareConversationRequest\x1a$.acai.chat.ShareConversationResponse\x12L\n" +
+	"\vRevokeShare\x12\x1d.acai.chat.RevokeShareRequest\x1a\x1e.acai.chat.RevokeShareResponse\x12[\n" +
+	"\x10SendVoiceMessage\x12\".acai.chat.SendVoiceMessageRequest\x1a#.acai.chat.SendVoiceMessageResponse\x12s\n" +
+	"\x18BatchDeleteConversations\x12*.acai.chat.BatchDeleteConversationsRequest\x1a+.acai.chat.BatchDeleteConversationsResponse\x12v\n" +
+	"\x19BatchArchiveConversations\x12+.acai.chat.BatchArchiveConversationsRequest\x1a,.acai.chat.BatchArchiveConversationsResponse\x12g\n" +
+	"\x14GetConversationStats\x12&.acai.chat.GetConversationStatsRequest\x1a'.acai.chat.GetConversationStatsResponse\x12O\n" +
+	"\fRefreshReply\x12\x1e.acai.chat.RefreshReplyRequest\x1a\x1f.acai.chat.RefreshReplyResponse\x12U\n" +
+	"\x0eSubmitFeedback\x12 .acai.chat.SubmitFeedbackRequest\x1a!.acai.chat.SubmitFeedbackResponse\x12[\n" +
+	"\x10FlagConversation\x12\".acai.chat.FlagConversationRequest\x1a#.acai.chat.FlagConversationResponse\x12X\n" +
+	"\x0fExportItinerary\x12!.acai.chat.ExportItineraryRequest\x1a\".acai.chat.ExportItineraryResponse\x12R\n" +
+	"\rExportTripPDF\x12\x1f.acai.chat.ExportTripPDFRequest\x1a .acai.chat.ExportTripPDFResponse\x12U\n" +
+	"\x0eExportUserData\x12 .acai.chat.ExportUserDataRequest\x1a!.acai.chat.ExportUserDataResponse\x12U\n" +
+	"\x0eImportUserData\x12 .acai.chat.ImportUserDataRequest\x1a!.acai.chat.ImportUserDataResponseB\rZ\vinternal/pbb\x06proto3"
+
 var (
 	file_rpc_chat_proto_rawDescOnce sync.Once
-	file_rpc_chat_proto_rawDescData = file_rpc_chat_proto_rawDesc
+	file_rpc_chat_proto_rawDescData []byte
 )
 
 func file_rpc_chat_proto_rawDescGZIP() []byte {
 	file_rpc_chat_proto_rawDescOnce.Do(func() {
-		file_rpc_chat_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_chat_proto_rawDescData)
+		file_rpc_chat_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_chat_proto_rawDesc), len(file_rpc_chat_proto_rawDesc)))
 	})
 	return file_rpc_chat_proto_rawDescData
 }
 
 var file_rpc_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_rpc_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_rpc_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 73)
 var file_rpc_chat_proto_goTypes = []any{
-	(Conversation_Role)(0),               // 0: acai.chat.Conversation.Role
-	(*Conversation)(nil),                 // 1: acai.chat.Conversation
-	(*StartConversationRequest)(nil),     // 2: acai.chat.StartConversationRequest
-	(*StartConversationResponse)(nil),    // 3: acai.chat.StartConversationResponse
-	(*ContinueConversationRequest)(nil),  // 4: acai.chat.ContinueConversationRequest
-	(*ContinueConversationResponse)(nil), // 5: acai.chat.ContinueConversationResponse
-	(*ListConversationsRequest)(nil),     // 6: acai.chat.ListConversationsRequest
-	(*ListConversationsResponse)(nil),    // 7: acai.chat.ListConversationsResponse
-	(*DescribeConversationRequest)(nil),  // 8: acai.chat.DescribeConversationRequest
-	(*DescribeConversationResponse)(nil), // 9: acai.chat.DescribeConversationResponse
-	(*Conversation_Message)(nil),         // 10: acai.chat.Conversation.Message
-	(*timestamppb.Timestamp)(nil),        // 11: google.protobuf.Timestamp
+	(Conversation_Role)(0),                        // 0: acai.chat.Conversation.Role
+	(*Conversation)(nil),                          // 1: acai.chat.Conversation
+	(*GenerationSettings)(nil),                    // 2: acai.chat.GenerationSettings
+	(*Citation)(nil),                              // 3: acai.chat.Citation
+	(*Feedback)(nil),                              // 4: acai.chat.Feedback
+	(*Attachment)(nil),                            // 5: acai.chat.Attachment
+	(*AttachmentUpload)(nil),                      // 6: acai.chat.AttachmentUpload
+	(*Audio)(nil),                                 // 7: acai.chat.Audio
+	(*StartConversationRequest)(nil),              // 8: acai.chat.StartConversationRequest
+	(*StartConversationResponse)(nil),             // 9: acai.chat.StartConversationResponse
+	(*ConversationTemplate)(nil),                  // 10: acai.chat.ConversationTemplate
+	(*ListConversationTemplatesRequest)(nil),      // 11: acai.chat.ListConversationTemplatesRequest
+	(*ListConversationTemplatesResponse)(nil),     // 12: acai.chat.ListConversationTemplatesResponse
+	(*StartConversationFromTemplateRequest)(nil),  // 13: acai.chat.StartConversationFromTemplateRequest
+	(*StartConversationFromTemplateResponse)(nil), // 14: acai.chat.StartConversationFromTemplateResponse
+	(*ContinueConversationRequest)(nil),           // 15: acai.chat.ContinueConversationRequest
+	(*ContinueConversationResponse)(nil),          // 16: acai.chat.ContinueConversationResponse
+	(*RefreshReplyRequest)(nil),                   // 17: acai.chat.RefreshReplyRequest
+	(*RefreshReplyResponse)(nil),                  // 18: acai.chat.RefreshReplyResponse
+	(*SubmitFeedbackRequest)(nil),                 // 19: acai.chat.SubmitFeedbackRequest
+	(*SubmitFeedbackResponse)(nil),                // 20: acai.chat.SubmitFeedbackResponse
+	(*FlagConversationRequest)(nil),               // 21: acai.chat.FlagConversationRequest
+	(*FlagConversationResponse)(nil),              // 22: acai.chat.FlagConversationResponse
+	(*ExportItineraryRequest)(nil),                // 23: acai.chat.ExportItineraryRequest
+	(*ExportItineraryResponse)(nil),               // 24: acai.chat.ExportItineraryResponse
+	(*ExportTripPDFRequest)(nil),                  // 25: acai.chat.ExportTripPDFRequest
+	(*ExportTripPDFResponse)(nil),                 // 26: acai.chat.ExportTripPDFResponse
+	(*ExportUserDataRequest)(nil),                 // 27: acai.chat.ExportUserDataRequest
+	(*ExportUserDataResponse)(nil),                // 28: acai.chat.ExportUserDataResponse
+	(*ImportUserDataRequest)(nil),                 // 29: acai.chat.ImportUserDataRequest
+	(*ImportUserDataResponse)(nil),                // 30: acai.chat.ImportUserDataResponse
+	(*ListConversationsRequest)(nil),              // 31: acai.chat.ListConversationsRequest
+	(*ListConversationsResponse)(nil),             // 32: acai.chat.ListConversationsResponse
+	(*DescribeConversationRequest)(nil),           // 33: acai.chat.DescribeConversationRequest
+	(*DescribeConversationResponse)(nil),          // 34: acai.chat.DescribeConversationResponse
+	(*SyncConversationRequest)(nil),               // 35: acai.chat.SyncConversationRequest
+	(*SyncConversationResponse)(nil),              // 36: acai.chat.SyncConversationResponse
+	(*UpdateConversationLabelsRequest)(nil),       // 37: acai.chat.UpdateConversationLabelsRequest
+	(*UpdateConversationLabelsResponse)(nil),      // 38: acai.chat.UpdateConversationLabelsResponse
+	(*PinConversationRequest)(nil),                // 39: acai.chat.PinConversationRequest
+	(*PinConversationResponse)(nil),               // 40: acai.chat.PinConversationResponse
+	(*FavoriteConversationRequest)(nil),           // 41: acai.chat.FavoriteConversationRequest
+	(*FavoriteConversationResponse)(nil),          // 42: acai.chat.FavoriteConversationResponse
+	(*BatchDeleteConversationsRequest)(nil),       // 43: acai.chat.BatchDeleteConversationsRequest
+	(*BatchDeleteConversationsResponse)(nil),      // 44: acai.chat.BatchDeleteConversationsResponse
+	(*BatchArchiveConversationsRequest)(nil),      // 45: acai.chat.BatchArchiveConversationsRequest
+	(*BatchArchiveConversationsResponse)(nil),     // 46: acai.chat.BatchArchiveConversationsResponse
+	(*ConversationStats)(nil),                     // 47: acai.chat.ConversationStats
+	(*GetConversationStatsRequest)(nil),           // 48: acai.chat.GetConversationStatsRequest
+	(*GetConversationStatsResponse)(nil),          // 49: acai.chat.GetConversationStatsResponse
+	(*Webhook)(nil),                               // 50: acai.chat.Webhook
+	(*WebhookDelivery)(nil),                       // 51: acai.chat.WebhookDelivery
+	(*CreateWebhookRequest)(nil),                  // 52: acai.chat.CreateWebhookRequest
+	(*CreateWebhookResponse)(nil),                 // 53: acai.chat.CreateWebhookResponse
+	(*ListWebhooksRequest)(nil),                   // 54: acai.chat.ListWebhooksRequest
+	(*ListWebhooksResponse)(nil),                  // 55: acai.chat.ListWebhooksResponse
+	(*DeleteWebhookRequest)(nil),                  // 56: acai.chat.DeleteWebhookRequest
+	(*DeleteWebhookResponse)(nil),                 // 57: acai.chat.DeleteWebhookResponse
+	(*ListWebhookDeliveriesRequest)(nil),          // 58: acai.chat.ListWebhookDeliveriesRequest
+	(*ListWebhookDeliveriesResponse)(nil),         // 59: acai.chat.ListWebhookDeliveriesResponse
+	(*Profile)(nil),                               // 60: acai.chat.Profile
+	(*GetProfileRequest)(nil),                     // 61: acai.chat.GetProfileRequest
+	(*GetProfileResponse)(nil),                    // 62: acai.chat.GetProfileResponse
+	(*UpdateProfileRequest)(nil),                  // 63: acai.chat.UpdateProfileRequest
+	(*UpdateProfileResponse)(nil),                 // 64: acai.chat.UpdateProfileResponse
+	(*Share)(nil),                                 // 65: acai.chat.Share
+	(*ShareConversationRequest)(nil),              // 66: acai.chat.ShareConversationRequest
+	(*ShareConversationResponse)(nil),             // 67: acai.chat.ShareConversationResponse
+	(*RevokeShareRequest)(nil),                    // 68: acai.chat.RevokeShareRequest
+	(*RevokeShareResponse)(nil),                   // 69: acai.chat.RevokeShareResponse
+	(*SendVoiceMessageRequest)(nil),               // 70: acai.chat.SendVoiceMessageRequest
+	(*SendVoiceMessageResponse)(nil),              // 71: acai.chat.SendVoiceMessageResponse
+	(*Conversation_Message)(nil),                  // 72: acai.chat.Conversation.Message
+	(*ConversationStats_ToolUsage)(nil),           // 73: acai.chat.ConversationStats.ToolUsage
+	(*timestamppb.Timestamp)(nil),                 // 74: google.protobuf.Timestamp
 }
 var file_rpc_chat_proto_depIdxs = []int32{
-	11, // 0: acai.chat.Conversation.timestamp:type_name -> google.protobuf.Timestamp
-	10, // 1: acai.chat.Conversation.messages:type_name -> acai.chat.Conversation.Message
-	1,  // 2: acai.chat.ListConversationsResponse.conversations:type_name -> acai.chat.Conversation
-	1,  // 3: acai.chat.DescribeConversationResponse.conversation:type_name -> acai.chat.Conversation
-	0,  // 4: acai.chat.Conversation.Message.role:type_name -> acai.chat.Conversation.Role
-	11, // 5: acai.chat.Conversation.Message.timestamp:type_name -> google.protobuf.Timestamp
-	2,  // 6: acai.chat.ChatService.StartConversation:input_type -> acai.chat.StartConversationRequest
-	4,  // 7: acai.chat.ChatService.ContinueConversation:input_type -> acai.chat.ContinueConversationRequest
-	6,  // 8: acai.chat.ChatService.ListConversations:input_type -> acai.chat.ListConversationsRequest
-	8,  // 9: acai.chat.ChatService.DescribeConversation:input_type -> acai.chat.DescribeConversationRequest
-	3,  // 10: acai.chat.ChatService.StartConversation:output_type -> acai.chat.StartConversationResponse
-	5,  // 11: acai.chat.ChatService.ContinueConversation:output_type -> acai.chat.ContinueConversationResponse
-	7,  // 12: acai.chat.ChatService.ListConversations:output_type -> acai.chat.ListConversationsResponse
-	9,  // 13: acai.chat.ChatService.DescribeConversation:output_type -> acai.chat.DescribeConversationResponse
-	10, // [10:14] is the sub-list for method output_type
-	6,  // [6:10] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	74, // 0: acai.chat.Conversation.timestamp:type_name -> google.protobuf.Timestamp
+	72, // 1: acai.chat.Conversation.messages:type_name -> acai.chat.Conversation.Message
+	74, // 2: acai.chat.Conversation.archived_at:type_name -> google.protobuf.Timestamp
+	2,  // 3: acai.chat.Conversation.settings:type_name -> acai.chat.GenerationSettings
+	74, // 4: acai.chat.Conversation.pinned_at:type_name -> google.protobuf.Timestamp
+	74, // 5: acai.chat.Citation.fetched_at:type_name -> google.protobuf.Timestamp
+	74, // 6: acai.chat.Citation.expires_at:type_name -> google.protobuf.Timestamp
+	74, // 7: acai.chat.Feedback.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 8: acai.chat.StartConversationRequest.attachments:type_name -> acai.chat.AttachmentUpload
+	2,  // 9: acai.chat.StartConversationRequest.settings:type_name -> acai.chat.GenerationSettings
+	7,  // 10: acai.chat.StartConversationResponse.reply_audio:type_name -> acai.chat.Audio
+	3,  // 11: acai.chat.StartConversationResponse.citations:type_name -> acai.chat.Citation
+	74, // 12: acai.chat.StartConversationResponse.data_as_of:type_name -> google.protobuf.Timestamp
+	10, // 13: acai.chat.ListConversationTemplatesResponse.templates:type_name -> acai.chat.ConversationTemplate
+	2,  // 14: acai.chat.StartConversationFromTemplateRequest.settings:type_name -> acai.chat.GenerationSettings
+	6,  // 15: acai.chat.ContinueConversationRequest.attachments:type_name -> acai.chat.AttachmentUpload
+	2,  // 16: acai.chat.ContinueConversationRequest.settings:type_name -> acai.chat.GenerationSettings
+	7,  // 17: acai.chat.ContinueConversationResponse.reply_audio:type_name -> acai.chat.Audio
+	3,  // 18: acai.chat.ContinueConversationResponse.citations:type_name -> acai.chat.Citation
+	74, // 19: acai.chat.ContinueConversationResponse.data_as_of:type_name -> google.protobuf.Timestamp
+	72, // 20: acai.chat.RefreshReplyResponse.message:type_name -> acai.chat.Conversation.Message
+	74, // 21: acai.chat.RefreshReplyResponse.data_as_of:type_name -> google.protobuf.Timestamp
+	72, // 22: acai.chat.SubmitFeedbackResponse.message:type_name -> acai.chat.Conversation.Message
+	1,  // 23: acai.chat.ListConversationsResponse.conversations:type_name -> acai.chat.Conversation
+	1,  // 24: acai.chat.DescribeConversationResponse.conversation:type_name -> acai.chat.Conversation
+	74, // 25: acai.chat.SyncConversationRequest.synced_at:type_name -> google.protobuf.Timestamp
+	1,  // 26: acai.chat.SyncConversationResponse.conversation:type_name -> acai.chat.Conversation
+	72, // 27: acai.chat.SyncConversationResponse.messages:type_name -> acai.chat.Conversation.Message
+	74, // 28: acai.chat.SyncConversationResponse.synced_at:type_name -> google.protobuf.Timestamp
+	1,  // 29: acai.chat.UpdateConversationLabelsResponse.conversation:type_name -> acai.chat.Conversation
+	1,  // 30: acai.chat.PinConversationResponse.conversation:type_name -> acai.chat.Conversation
+	1,  // 31: acai.chat.FavoriteConversationResponse.conversation:type_name -> acai.chat.Conversation
+	73, // 32: acai.chat.ConversationStats.tool_usage:type_name -> acai.chat.ConversationStats.ToolUsage
+	74, // 33: acai.chat.ConversationStats.first_activity_at:type_name -> google.protobuf.Timestamp
+	74, // 34: acai.chat.ConversationStats.last_activity_at:type_name -> google.protobuf.Timestamp
+	47, // 35: acai.chat.GetConversationStatsResponse.conversations:type_name -> acai.chat.ConversationStats
+	74, // 36: acai.chat.Webhook.created_at:type_name -> google.protobuf.Timestamp
+	74, // 37: acai.chat.WebhookDelivery.timestamp:type_name -> google.protobuf.Timestamp
+	50, // 38: acai.chat.CreateWebhookResponse.webhook:type_name -> acai.chat.Webhook
+	50, // 39: acai.chat.ListWebhooksResponse.webhooks:type_name -> acai.chat.Webhook
+	51, // 40: acai.chat.ListWebhookDeliveriesResponse.deliveries:type_name -> acai.chat.WebhookDelivery
+	74, // 41: acai.chat.Profile.updated_at:type_name -> google.protobuf.Timestamp
+	60, // 42: acai.chat.GetProfileResponse.profile:type_name -> acai.chat.Profile
+	60, // 43: acai.chat.UpdateProfileResponse.profile:type_name -> acai.chat.Profile
+	74, // 44: acai.chat.Share.expires_at:type_name -> google.protobuf.Timestamp
+	74, // 45: acai.chat.Share.created_at:type_name -> google.protobuf.Timestamp
+	65, // 46: acai.chat.ShareConversationResponse.share:type_name -> acai.chat.Share
+	7,  // 47: acai.chat.SendVoiceMessageResponse.reply_audio:type_name -> acai.chat.Audio
+	3,  // 48: acai.chat.SendVoiceMessageResponse.citations:type_name -> acai.chat.Citation
+	74, // 49: acai.chat.SendVoiceMessageResponse.data_as_of:type_name -> google.protobuf.Timestamp
+	0,  // 50: acai.chat.Conversation.Message.role:type_name -> acai.chat.Conversation.Role
+	74, // 51: acai.chat.Conversation.Message.timestamp:type_name -> google.protobuf.Timestamp
+	5,  // 52: acai.chat.Conversation.Message.attachments:type_name -> acai.chat.Attachment
+	3,  // 53: acai.chat.Conversation.Message.citations:type_name -> acai.chat.Citation
+	4,  // 54: acai.chat.Conversation.Message.feedback:type_name -> acai.chat.Feedback
+	8,  // 55: acai.chat.ChatService.StartConversation:input_type -> acai.chat.StartConversationRequest
+	11, // 56: acai.chat.ChatService.ListConversationTemplates:input_type -> acai.chat.ListConversationTemplatesRequest
+	13, // 57: acai.chat.ChatService.StartConversationFromTemplate:input_type -> acai.chat.StartConversationFromTemplateRequest
+	15, // 58: acai.chat.ChatService.ContinueConversation:input_type -> acai.chat.ContinueConversationRequest
+	31, // 59: acai.chat.ChatService.ListConversations:input_type -> acai.chat.ListConversationsRequest
+	33, // 60: acai.chat.ChatService.DescribeConversation:input_type -> acai.chat.DescribeConversationRequest
+	35, // 61: acai.chat.ChatService.SyncConversation:input_type -> acai.chat.SyncConversationRequest
+	37, // 62: acai.chat.ChatService.UpdateConversationLabels:input_type -> acai.chat.UpdateConversationLabelsRequest
+	39, // 63: acai.chat.ChatService.PinConversation:input_type -> acai.chat.PinConversationRequest
+	41, // 64: acai.chat.ChatService.FavoriteConversation:input_type -> acai.chat.FavoriteConversationRequest
+	52, // 65: acai.chat.ChatService.CreateWebhook:input_type -> acai.chat.CreateWebhookRequest
+	54, // 66: acai.chat.ChatService.ListWebhooks:input_type -> acai.chat.ListWebhooksRequest
+	56, // 67: acai.chat.ChatService.DeleteWebhook:input_type -> acai.chat.DeleteWebhookRequest
+	58, // 68: acai.chat.ChatService.ListWebhookDeliveries:input_type -> acai.chat.ListWebhookDeliveriesRequest
+	61, // 69: acai.chat.ChatService.GetProfile:input_type -> acai.chat.GetProfileRequest
+	63, // 70: acai.chat.ChatService.UpdateProfile:input_type -> acai.chat.UpdateProfileRequest
+	66, // 71: acai.chat.ChatService.ShareConversation:input_type -> acai.chat.ShareConversationRequest
+	68, // 72: acai.chat.ChatService.RevokeShare:input_type -> acai.chat.RevokeShareRequest
+	70, // 73: acai.chat.ChatService.SendVoiceMessage:input_type -> acai.chat.SendVoiceMessageRequest
+	43, // 74: acai.chat.ChatService.BatchDeleteConversations:input_type -> acai.chat.BatchDeleteConversationsRequest
+	45, // 75: acai.chat.ChatService.BatchArchiveConversations:input_type -> acai.chat.BatchArchiveConversationsRequest
+	48, // 76: acai.chat.ChatService.GetConversationStats:input_type -> acai.chat.GetConversationStatsRequest
+	17, // 77: acai.chat.ChatService.RefreshReply:input_type -> acai.chat.RefreshReplyRequest
+	19, // 78: acai.chat.ChatService.SubmitFeedback:input_type -> acai.chat.SubmitFeedbackRequest
+	21, // 79: acai.chat.ChatService.FlagConversation:input_type -> acai.chat.FlagConversationRequest
+	23, // 80: acai.chat.ChatService.ExportItinerary:input_type -> acai.chat.ExportItineraryRequest
+	25, // 81: acai.chat.ChatService.ExportTripPDF:input_type -> acai.chat.ExportTripPDFRequest
+	27, // 82: acai.chat.ChatService.ExportUserData:input_type -> acai.chat.ExportUserDataRequest
+	29, // 83: acai.chat.ChatService.ImportUserData:input_type -> acai.chat.ImportUserDataRequest
+	9,  // 84: acai.chat.ChatService.StartConversation:output_type -> acai.chat.StartConversationResponse
+	12, // 85: acai.chat.ChatService.ListConversationTemplates:output_type -> acai.chat.ListConversationTemplatesResponse
+	14, // 86: acai.chat.ChatService.StartConversationFromTemplate:output_type -> acai.chat.StartConversationFromTemplateResponse
+	16, // 87: acai.chat.ChatService.ContinueConversation:output_type -> acai.chat.ContinueConversationResponse
+	32, // 88: acai.chat.ChatService.ListConversations:output_type -> acai.chat.ListConversationsResponse
+	34, // 89: acai.chat.ChatService.DescribeConversation:output_type -> acai.chat.DescribeConversationResponse
+	36, // 90: acai.chat.ChatService.SyncConversation:output_type -> acai.chat.SyncConversationResponse
+	38, // 91: acai.chat.ChatService.UpdateConversationLabels:output_type -> acai.chat.UpdateConversationLabelsResponse
+	40, // 92: acai.chat.ChatService.PinConversation:output_type -> acai.chat.PinConversationResponse
+	42, // 93: acai.chat.ChatService.FavoriteConversation:output_type -> acai.chat.FavoriteConversationResponse
+	53, // 94: acai.chat.ChatService.CreateWebhook:output_type -> acai.chat.CreateWebhookResponse
+	55, // 95: acai.chat.ChatService.ListWebhooks:output_type -> acai.chat.ListWebhooksResponse
+	57, // 96: acai.chat.ChatService.DeleteWebhook:output_type -> acai.chat.DeleteWebhookResponse
+	59, // 97: acai.chat.ChatService.ListWebhookDeliveries:output_type -> acai.chat.ListWebhookDeliveriesResponse
+	62, // 98: acai.chat.ChatService.GetProfile:output_type -> acai.chat.GetProfileResponse
+	64, // 99: acai.chat.ChatService.UpdateProfile:output_type -> acai.chat.UpdateProfileResponse
+	67, // 100: acai.chat.ChatService.ShareConversation:output_type -> acai.chat.ShareConversationResponse
+	69, // 101: acai.chat.ChatService.RevokeShare:output_type -> acai.chat.RevokeShareResponse
+	71, // 102: acai.chat.ChatService.SendVoiceMessage:output_type -> acai.chat.SendVoiceMessageResponse
+	44, // 103: acai.chat.ChatService.BatchDeleteConversations:output_type -> acai.chat.BatchDeleteConversationsResponse
+	46, // 104: acai.chat.ChatService.BatchArchiveConversations:output_type -> acai.chat.BatchArchiveConversationsResponse
+	49, // 105: acai.chat.ChatService.GetConversationStats:output_type -> acai.chat.GetConversationStatsResponse
+	18, // 106: acai.chat.ChatService.RefreshReply:output_type -> acai.chat.RefreshReplyResponse
+	20, // 107: acai.chat.ChatService.SubmitFeedback:output_type -> acai.chat.SubmitFeedbackResponse
+	22, // 108: acai.chat.ChatService.FlagConversation:output_type -> acai.chat.FlagConversationResponse
+	24, // 109: acai.chat.ChatService.ExportItinerary:output_type -> acai.chat.ExportItineraryResponse
+	26, // 110: acai.chat.ChatService.ExportTripPDF:output_type -> acai.chat.ExportTripPDFResponse
+	28, // 111: acai.chat.ChatService.ExportUserData:output_type -> acai.chat.ExportUserDataResponse
+	30, // 112: acai.chat.ChatService.ImportUserData:output_type -> acai.chat.ImportUserDataResponse
+	84, // [84:113] is the sub-list for method output_type
+	55, // [55:84] is the sub-list for method input_type
+	55, // [55:55] is the sub-list for extension type_name
+	55, // [55:55] is the sub-list for extension extendee
+	0,  // [0:55] is the sub-list for field type_name
 }
 
 func init() { file_rpc_chat_proto_init() }
@@ -736,13 +5279,14 @@ func file_rpc_chat_proto_init() {
 	if File_rpc_chat_proto != nil {
 		return
 	}
+	file_rpc_chat_proto_msgTypes[32].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_rpc_chat_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_chat_proto_rawDesc), len(file_rpc_chat_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   10,
+			NumMessages:   73,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -752,7 +5296,6 @@ func file_rpc_chat_proto_init() {
 		MessageInfos:      file_rpc_chat_proto_msgTypes,
 	}.Build()
 	File_rpc_chat_proto = out.File
-	file_rpc_chat_proto_rawDesc = nil
 	file_rpc_chat_proto_goTypes = nil
 	file_rpc_chat_proto_depIdxs = nil
 }
@@ -32,18 +32,94 @@ const _ = twirp.TwirpPackageMinVersion_8_1_0
 // =====================
 
 type ChatService interface {
-	// create a new conversation by sending a message and getting a reply
+	// Create a new conversation by sending a message and getting a reply
 	// use ContinueConversation with the returned conversation_id to continue the conversation
 	StartConversation(context.Context, *StartConversationRequest) (*StartConversationResponse, error)
 
-	// continue an existing conversation by adding a new message and getting a reply
+	// List the quick-start flows conversations can be started from
+	ListConversationTemplates(context.Context, *ListConversationTemplatesRequest) (*ListConversationTemplatesResponse, error)
+
+	// Create a new conversation from a template: the assistant opens it with the template's first message
+	// use ContinueConversation with the returned conversation_id to answer it
+	StartConversationFromTemplate(context.Context, *StartConversationFromTemplateRequest) (*StartConversationFromTemplateResponse, error)
+
+	// Continue an existing conversation by adding a new message and getting a reply
 	ContinueConversation(context.Context, *ContinueConversationRequest) (*ContinueConversationResponse, error)
 
-	// list most recent conversations
+	// List most recent conversations
 	ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error)
 
-	// describe a conversation by its ID
+	// Describe a conversation by its ID
 	DescribeConversation(context.Context, *DescribeConversationRequest) (*DescribeConversationResponse, error)
+
+	// Get what changed in a conversation since a previous sync
+	SyncConversation(context.Context, *SyncConversationRequest) (*SyncConversationResponse, error)
+
+	// Set the tags and folder of a conversation
+	UpdateConversationLabels(context.Context, *UpdateConversationLabelsRequest) (*UpdateConversationLabelsResponse, error)
+
+	// Pin a conversation to the top of ListConversations, or unpin it
+	PinConversation(context.Context, *PinConversationRequest) (*PinConversationResponse, error)
+
+	// Mark a conversation as a favorite, or unmark it
+	FavoriteConversation(context.Context, *FavoriteConversationRequest) (*FavoriteConversationResponse, error)
+
+	// Register a webhook URL that receives signed event notifications
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*CreateWebhookResponse, error)
+
+	// List webhooks registered by the calling user
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+
+	// Delete a webhook by its ID
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error)
+
+	// List the most recent delivery attempts of a webhook
+	ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error)
+
+	// Get the calling user's profile and notification preferences
+	GetProfile(context.Context, *GetProfileRequest) (*GetProfileResponse, error)
+
+	// Update the calling user's profile and notification preferences
+	UpdateProfile(context.Context, *UpdateProfileRequest) (*UpdateProfileResponse, error)
+
+	// Create a public, read-only link to a conversation that expires after a while
+	ShareConversation(context.Context, *ShareConversationRequest) (*ShareConversationResponse, error)
+
+	// Revoke a share link so it can no longer be opened
+	RevokeShare(context.Context, *RevokeShareRequest) (*RevokeShareResponse, error)
+
+	// Transcribe a voice message and send it to a new or existing conversation
+	SendVoiceMessage(context.Context, *SendVoiceMessageRequest) (*SendVoiceMessageResponse, error)
+
+	// Delete several of the calling user's conversations at once
+	BatchDeleteConversations(context.Context, *BatchDeleteConversationsRequest) (*BatchDeleteConversationsResponse, error)
+
+	// Archive (or unarchive) several of the calling user's conversations at once
+	BatchArchiveConversations(context.Context, *BatchArchiveConversationsRequest) (*BatchArchiveConversationsResponse, error)
+
+	// Get usage statistics of the calling user's conversations
+	GetConversationStats(context.Context, *GetConversationStatsRequest) (*GetConversationStatsResponse, error)
+
+	// Re-run the tools an assistant reply cites and update the reply with their fresh data
+	RefreshReply(context.Context, *RefreshReplyRequest) (*RefreshReplyResponse, error)
+
+	// Rate an assistant reply thumbs up or down, with an optional comment
+	SubmitFeedback(context.Context, *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error)
+
+	// Report a conversation, or a message of it, for review by a human
+	FlagConversation(context.Context, *FlagConversationRequest) (*FlagConversationResponse, error)
+
+	// Export the itinerary of the trip planned in a conversation as an .ics calendar file
+	ExportItinerary(context.Context, *ExportItineraryRequest) (*ExportItineraryResponse, error)
+
+	// Export the summary and itinerary of the trip planned in a conversation as a printable PDF
+	ExportTripPDF(context.Context, *ExportTripPDFRequest) (*ExportTripPDFResponse, error)
+
+	// Export all the data of the user: conversations, profile, itineraries and reminders, as a zip of JSON files
+	ExportUserData(context.Context, *ExportUserDataRequest) (*ExportUserDataResponse, error)
+
+	// Restore the data exported by ExportUserData, as copies owned by the user
+	ImportUserData(context.Context, *ImportUserDataRequest) (*ImportUserDataResponse, error)
 }
 
 // ===========================
@@ -52,7 +128,7 @@ type ChatService interface {
 
 type chatServiceProtobufClient struct {
 	client      HTTPClient
-	urls        [4]string
+	urls        [29]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -80,11 +156,36 @@ func NewChatServiceProtobufClient(baseURL string, client HTTPClient, opts ...twi
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "acai.chat", "ChatService")
-	urls := [4]string{
+	urls := [29]string{
 		serviceURL + "StartConversation",
+		serviceURL + "ListConversationTemplates",
+		serviceURL + "StartConversationFromTemplate",
 		serviceURL + "ContinueConversation",
 		serviceURL + "ListConversations",
 		serviceURL + "DescribeConversation",
+		serviceURL + "SyncConversation",
+		serviceURL + "UpdateConversationLabels",
+		serviceURL + "PinConversation",
+		serviceURL + "FavoriteConversation",
+		serviceURL + "CreateWebhook",
+		serviceURL + "ListWebhooks",
+		serviceURL + "DeleteWebhook",
+		serviceURL + "ListWebhookDeliveries",
+		serviceURL + "GetProfile",
+		serviceURL + "UpdateProfile",
+		serviceURL + "ShareConversation",
+		serviceURL + "RevokeShare",
+		serviceURL + "SendVoiceMessage",
+		serviceURL + "BatchDeleteConversations",
+		serviceURL + "BatchArchiveConversations",
+		serviceURL + "GetConversationStats",
+		serviceURL + "RefreshReply",
+		serviceURL + "SubmitFeedback",
+		serviceURL + "FlagConversation",
+		serviceURL + "ExportItinerary",
+		serviceURL + "ExportTripPDF",
+		serviceURL + "ExportUserData",
+		serviceURL + "ImportUserData",
 	}
 
 	return &chatServiceProtobufClient{
@@ -141,6 +242,98 @@ func (c *chatServiceProtobufClient) callStartConversation(ctx context.Context, i
 	return out, nil
 }
 
+func (c *chatServiceProtobufClient) ListConversationTemplates(ctx context.Context, in *ListConversationTemplatesRequest) (*ListConversationTemplatesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListConversationTemplates")
+	caller := c.callListConversationTemplates
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListConversationTemplatesRequest) (*ListConversationTemplatesResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListConversationTemplatesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListConversationTemplatesRequest) when calling interceptor")
+					}
+					return c.callListConversationTemplates(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListConversationTemplatesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationTemplatesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callListConversationTemplates(ctx context.Context, in *ListConversationTemplatesRequest) (*ListConversationTemplatesResponse, error) {
+	out := new(ListConversationTemplatesResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) StartConversationFromTemplate(ctx context.Context, in *StartConversationFromTemplateRequest) (*StartConversationFromTemplateResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "StartConversationFromTemplate")
+	caller := c.callStartConversationFromTemplate
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *StartConversationFromTemplateRequest) (*StartConversationFromTemplateResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*StartConversationFromTemplateRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*StartConversationFromTemplateRequest) when calling interceptor")
+					}
+					return c.callStartConversationFromTemplate(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*StartConversationFromTemplateResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationFromTemplateResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callStartConversationFromTemplate(ctx context.Context, in *StartConversationFromTemplateRequest) (*StartConversationFromTemplateResponse, error) {
+	out := new(StartConversationFromTemplateResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 func (c *chatServiceProtobufClient) ContinueConversation(ctx context.Context, in *ContinueConversationRequest) (*ContinueConversationResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
 	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
@@ -172,7 +365,7 @@ func (c *chatServiceProtobufClient) ContinueConversation(ctx context.Context, in
 
 func (c *chatServiceProtobufClient) callContinueConversation(ctx context.Context, in *ContinueConversationRequest) (*ContinueConversationResponse, error) {
 	out := new(ContinueConversationResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -218,7 +411,7 @@ func (c *chatServiceProtobufClient) ListConversations(ctx context.Context, in *L
 
 func (c *chatServiceProtobufClient) callListConversations(ctx context.Context, in *ListConversationsRequest) (*ListConversationsResponse, error) {
 	out := new(ListConversationsResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -264,7 +457,7 @@ func (c *chatServiceProtobufClient) DescribeConversation(ctx context.Context, in
 
 func (c *chatServiceProtobufClient) callDescribeConversation(ctx context.Context, in *DescribeConversationRequest) (*DescribeConversationResponse, error) {
 	out := new(DescribeConversationResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -279,75 +472,118 @@ func (c *chatServiceProtobufClient) callDescribeConversation(ctx context.Context
 	return out, nil
 }
 
-// =======================
-// ChatService JSON Client
-// =======================
-
-type chatServiceJSONClient struct {
-	client      HTTPClient
-	urls        [4]string
-	interceptor twirp.Interceptor
-	opts        twirp.ClientOptions
+func (c *chatServiceProtobufClient) SyncConversation(ctx context.Context, in *SyncConversationRequest) (*SyncConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SyncConversation")
+	caller := c.callSyncConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SyncConversationRequest) (*SyncConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SyncConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SyncConversationRequest) when calling interceptor")
+					}
+					return c.callSyncConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SyncConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SyncConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
 }
 
-// NewChatServiceJSONClient creates a JSON client that implements the ChatService interface.
-// It communicates using JSON and can be configured with a custom HTTPClient.
-func NewChatServiceJSONClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) ChatService {
-	if c, ok := client.(*http.Client); ok {
-		client = withoutRedirects(c)
+func (c *chatServiceProtobufClient) callSyncConversation(ctx context.Context, in *SyncConversationRequest) (*SyncConversationResponse, error) {
+	out := new(SyncConversationResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
 	}
 
-	clientOpts := twirp.ClientOptions{}
-	for _, o := range opts {
-		o(&clientOpts)
-	}
+	callClientResponseReceived(ctx, c.opts.Hooks)
 
-	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
-	literalURLs := false
-	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
-	var pathPrefix string
-	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
-		pathPrefix = "/twirp" // default prefix
-	}
+	return out, nil
+}
 
-	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
-	serviceURL := sanitizeBaseURL(baseURL)
-	serviceURL += baseServicePath(pathPrefix, "acai.chat", "ChatService")
-	urls := [4]string{
-		serviceURL + "StartConversation",
-		serviceURL + "ContinueConversation",
-		serviceURL + "ListConversations",
-		serviceURL + "DescribeConversation",
+func (c *chatServiceProtobufClient) UpdateConversationLabels(ctx context.Context, in *UpdateConversationLabelsRequest) (*UpdateConversationLabelsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateConversationLabels")
+	caller := c.callUpdateConversationLabels
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UpdateConversationLabelsRequest) (*UpdateConversationLabelsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateConversationLabelsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateConversationLabelsRequest) when calling interceptor")
+					}
+					return c.callUpdateConversationLabels(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateConversationLabelsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateConversationLabelsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
 	}
+	return caller(ctx, in)
+}
 
-	return &chatServiceJSONClient{
-		client:      client,
-		urls:        urls,
-		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
-		opts:        clientOpts,
+func (c *chatServiceProtobufClient) callUpdateConversationLabels(ctx context.Context, in *UpdateConversationLabelsRequest) (*UpdateConversationLabelsResponse, error) {
+	out := new(UpdateConversationLabelsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
 	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
 }
 
-func (c *chatServiceJSONClient) StartConversation(ctx context.Context, in *StartConversationRequest) (*StartConversationResponse, error) {
+func (c *chatServiceProtobufClient) PinConversation(ctx context.Context, in *PinConversationRequest) (*PinConversationResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
 	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
-	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
-	caller := c.callStartConversation
+	ctx = ctxsetters.WithMethodName(ctx, "PinConversation")
+	caller := c.callPinConversation
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+		caller = func(ctx context.Context, req *PinConversationRequest) (*PinConversationResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*StartConversationRequest)
+					typedReq, ok := req.(*PinConversationRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*PinConversationRequest) when calling interceptor")
 					}
-					return c.callStartConversation(ctx, typedReq)
+					return c.callPinConversation(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*StartConversationResponse)
+				typedResp, ok := resp.(*PinConversationResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*PinConversationResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -357,9 +593,9 @@ func (c *chatServiceJSONClient) StartConversation(ctx context.Context, in *Start
 	return caller(ctx, in)
 }
 
-func (c *chatServiceJSONClient) callStartConversation(ctx context.Context, in *StartConversationRequest) (*StartConversationResponse, error) {
-	out := new(StartConversationResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+func (c *chatServiceProtobufClient) callPinConversation(ctx context.Context, in *PinConversationRequest) (*PinConversationResponse, error) {
+	out := new(PinConversationResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -374,26 +610,26 @@ func (c *chatServiceJSONClient) callStartConversation(ctx context.Context, in *S
 	return out, nil
 }
 
-func (c *chatServiceJSONClient) ContinueConversation(ctx context.Context, in *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+func (c *chatServiceProtobufClient) FavoriteConversation(ctx context.Context, in *FavoriteConversationRequest) (*FavoriteConversationResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
 	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
-	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
-	caller := c.callContinueConversation
+	ctx = ctxsetters.WithMethodName(ctx, "FavoriteConversation")
+	caller := c.callFavoriteConversation
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+		caller = func(ctx context.Context, req *FavoriteConversationRequest) (*FavoriteConversationResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ContinueConversationRequest)
+					typedReq, ok := req.(*FavoriteConversationRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*FavoriteConversationRequest) when calling interceptor")
 					}
-					return c.callContinueConversation(ctx, typedReq)
+					return c.callFavoriteConversation(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ContinueConversationResponse)
+				typedResp, ok := resp.(*FavoriteConversationResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*FavoriteConversationResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -403,9 +639,9 @@ func (c *chatServiceJSONClient) ContinueConversation(ctx context.Context, in *Co
 	return caller(ctx, in)
 }
 
-func (c *chatServiceJSONClient) callContinueConversation(ctx context.Context, in *ContinueConversationRequest) (*ContinueConversationResponse, error) {
-	out := new(ContinueConversationResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+func (c *chatServiceProtobufClient) callFavoriteConversation(ctx context.Context, in *FavoriteConversationRequest) (*FavoriteConversationResponse, error) {
+	out := new(FavoriteConversationResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -420,26 +656,26 @@ func (c *chatServiceJSONClient) callContinueConversation(ctx context.Context, in
 	return out, nil
 }
 
-func (c *chatServiceJSONClient) ListConversations(ctx context.Context, in *ListConversationsRequest) (*ListConversationsResponse, error) {
+func (c *chatServiceProtobufClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest) (*CreateWebhookResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
 	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
-	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
-	caller := c.callListConversations
+	ctx = ctxsetters.WithMethodName(ctx, "CreateWebhook")
+	caller := c.callCreateWebhook
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+		caller = func(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ListConversationsRequest)
+					typedReq, ok := req.(*CreateWebhookRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*CreateWebhookRequest) when calling interceptor")
 					}
-					return c.callListConversations(ctx, typedReq)
+					return c.callCreateWebhook(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ListConversationsResponse)
+				typedResp, ok := resp.(*CreateWebhookResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateWebhookResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -449,9 +685,9 @@ func (c *chatServiceJSONClient) ListConversations(ctx context.Context, in *ListC
 	return caller(ctx, in)
 }
 
-func (c *chatServiceJSONClient) callListConversations(ctx context.Context, in *ListConversationsRequest) (*ListConversationsResponse, error) {
-	out := new(ListConversationsResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+func (c *chatServiceProtobufClient) callCreateWebhook(ctx context.Context, in *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	out := new(CreateWebhookResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[10], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -466,26 +702,26 @@ func (c *chatServiceJSONClient) callListConversations(ctx context.Context, in *L
 	return out, nil
 }
 
-func (c *chatServiceJSONClient) DescribeConversation(ctx context.Context, in *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+func (c *chatServiceProtobufClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest) (*ListWebhooksResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
 	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
-	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
-	caller := c.callDescribeConversation
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhooks")
+	caller := c.callListWebhooks
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+		caller = func(ctx context.Context, req *ListWebhooksRequest) (*ListWebhooksResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DescribeConversationRequest)
+					typedReq, ok := req.(*ListWebhooksRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhooksRequest) when calling interceptor")
 					}
-					return c.callDescribeConversation(ctx, typedReq)
+					return c.callListWebhooks(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DescribeConversationResponse)
+				typedResp, ok := resp.(*ListWebhooksResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhooksResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -495,9 +731,9 @@ func (c *chatServiceJSONClient) DescribeConversation(ctx context.Context, in *De
 	return caller(ctx, in)
 }
 
-func (c *chatServiceJSONClient) callDescribeConversation(ctx context.Context, in *DescribeConversationRequest) (*DescribeConversationResponse, error) {
-	out := new(DescribeConversationResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+func (c *chatServiceProtobufClient) callListWebhooks(ctx context.Context, in *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	out := new(ListWebhooksResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[11], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -512,123 +748,6888 @@ func (c *chatServiceJSONClient) callDescribeConversation(ctx context.Context, in
 	return out, nil
 }
 
-// ==========================
-// ChatService Server Handler
-// ==========================
-
-type chatServiceServer struct {
-	ChatService
-	interceptor      twirp.Interceptor
-	hooks            *twirp.ServerHooks
-	pathPrefix       string // prefix for routing
-	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
-	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
-}
-
-// NewChatServiceServer builds a TwirpServer that can be used as an http.Handler to handle
-// HTTP requests that are routed to the right method in the provided svc implementation.
-// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
+func (c *chatServiceProtobufClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteWebhook")
+	caller := c.callDeleteWebhook
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteWebhookRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteWebhookRequest) when calling interceptor")
+					}
+					return c.callDeleteWebhook(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteWebhookResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteWebhookResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callDeleteWebhook(ctx context.Context, in *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	out := new(DeleteWebhookResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[12], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhookDeliveries")
+	caller := c.callListWebhookDeliveries
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhookDeliveriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhookDeliveriesRequest) when calling interceptor")
+					}
+					return c.callListWebhookDeliveries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhookDeliveriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhookDeliveriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	out := new(ListWebhookDeliveriesResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[13], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) GetProfile(ctx context.Context, in *GetProfileRequest) (*GetProfileResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetProfile")
+	caller := c.callGetProfile
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetProfileRequest) (*GetProfileResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetProfileRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetProfileRequest) when calling interceptor")
+					}
+					return c.callGetProfile(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetProfileResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetProfileResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callGetProfile(ctx context.Context, in *GetProfileRequest) (*GetProfileResponse, error) {
+	out := new(GetProfileResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[14], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) UpdateProfile(ctx context.Context, in *UpdateProfileRequest) (*UpdateProfileResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateProfile")
+	caller := c.callUpdateProfile
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UpdateProfileRequest) (*UpdateProfileResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateProfileRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateProfileRequest) when calling interceptor")
+					}
+					return c.callUpdateProfile(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateProfileResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateProfileResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callUpdateProfile(ctx context.Context, in *UpdateProfileRequest) (*UpdateProfileResponse, error) {
+	out := new(UpdateProfileResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[15], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) ShareConversation(ctx context.Context, in *ShareConversationRequest) (*ShareConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ShareConversation")
+	caller := c.callShareConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ShareConversationRequest) (*ShareConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ShareConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ShareConversationRequest) when calling interceptor")
+					}
+					return c.callShareConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ShareConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ShareConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callShareConversation(ctx context.Context, in *ShareConversationRequest) (*ShareConversationResponse, error) {
+	out := new(ShareConversationResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[16], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) RevokeShare(ctx context.Context, in *RevokeShareRequest) (*RevokeShareResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeShare")
+	caller := c.callRevokeShare
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RevokeShareRequest) (*RevokeShareResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeShareRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeShareRequest) when calling interceptor")
+					}
+					return c.callRevokeShare(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeShareResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeShareResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callRevokeShare(ctx context.Context, in *RevokeShareRequest) (*RevokeShareResponse, error) {
+	out := new(RevokeShareResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[17], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) SendVoiceMessage(ctx context.Context, in *SendVoiceMessageRequest) (*SendVoiceMessageResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SendVoiceMessage")
+	caller := c.callSendVoiceMessage
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SendVoiceMessageRequest) (*SendVoiceMessageResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SendVoiceMessageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SendVoiceMessageRequest) when calling interceptor")
+					}
+					return c.callSendVoiceMessage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SendVoiceMessageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SendVoiceMessageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callSendVoiceMessage(ctx context.Context, in *SendVoiceMessageRequest) (*SendVoiceMessageResponse, error) {
+	out := new(SendVoiceMessageResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[18], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) BatchDeleteConversations(ctx context.Context, in *BatchDeleteConversationsRequest) (*BatchDeleteConversationsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "BatchDeleteConversations")
+	caller := c.callBatchDeleteConversations
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *BatchDeleteConversationsRequest) (*BatchDeleteConversationsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchDeleteConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchDeleteConversationsRequest) when calling interceptor")
+					}
+					return c.callBatchDeleteConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchDeleteConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchDeleteConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callBatchDeleteConversations(ctx context.Context, in *BatchDeleteConversationsRequest) (*BatchDeleteConversationsResponse, error) {
+	out := new(BatchDeleteConversationsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[19], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) BatchArchiveConversations(ctx context.Context, in *BatchArchiveConversationsRequest) (*BatchArchiveConversationsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "BatchArchiveConversations")
+	caller := c.callBatchArchiveConversations
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *BatchArchiveConversationsRequest) (*BatchArchiveConversationsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchArchiveConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchArchiveConversationsRequest) when calling interceptor")
+					}
+					return c.callBatchArchiveConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchArchiveConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchArchiveConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callBatchArchiveConversations(ctx context.Context, in *BatchArchiveConversationsRequest) (*BatchArchiveConversationsResponse, error) {
+	out := new(BatchArchiveConversationsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[20], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) GetConversationStats(ctx context.Context, in *GetConversationStatsRequest) (*GetConversationStatsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetConversationStats")
+	caller := c.callGetConversationStats
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetConversationStatsRequest) (*GetConversationStatsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetConversationStatsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetConversationStatsRequest) when calling interceptor")
+					}
+					return c.callGetConversationStats(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetConversationStatsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetConversationStatsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callGetConversationStats(ctx context.Context, in *GetConversationStatsRequest) (*GetConversationStatsResponse, error) {
+	out := new(GetConversationStatsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[21], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) RefreshReply(ctx context.Context, in *RefreshReplyRequest) (*RefreshReplyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RefreshReply")
+	caller := c.callRefreshReply
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RefreshReplyRequest) (*RefreshReplyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RefreshReplyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RefreshReplyRequest) when calling interceptor")
+					}
+					return c.callRefreshReply(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RefreshReplyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RefreshReplyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callRefreshReply(ctx context.Context, in *RefreshReplyRequest) (*RefreshReplyResponse, error) {
+	out := new(RefreshReplyResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[22], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) SubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SubmitFeedback")
+	caller := c.callSubmitFeedback
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SubmitFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SubmitFeedbackRequest) when calling interceptor")
+					}
+					return c.callSubmitFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SubmitFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SubmitFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callSubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+	out := new(SubmitFeedbackResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[23], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) FlagConversation(ctx context.Context, in *FlagConversationRequest) (*FlagConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "FlagConversation")
+	caller := c.callFlagConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *FlagConversationRequest) (*FlagConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*FlagConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*FlagConversationRequest) when calling interceptor")
+					}
+					return c.callFlagConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*FlagConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*FlagConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callFlagConversation(ctx context.Context, in *FlagConversationRequest) (*FlagConversationResponse, error) {
+	out := new(FlagConversationResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[24], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) ExportItinerary(ctx context.Context, in *ExportItineraryRequest) (*ExportItineraryResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportItinerary")
+	caller := c.callExportItinerary
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ExportItineraryRequest) (*ExportItineraryResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportItineraryRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportItineraryRequest) when calling interceptor")
+					}
+					return c.callExportItinerary(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportItineraryResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportItineraryResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callExportItinerary(ctx context.Context, in *ExportItineraryRequest) (*ExportItineraryResponse, error) {
+	out := new(ExportItineraryResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[25], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) ExportTripPDF(ctx context.Context, in *ExportTripPDFRequest) (*ExportTripPDFResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportTripPDF")
+	caller := c.callExportTripPDF
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ExportTripPDFRequest) (*ExportTripPDFResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportTripPDFRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportTripPDFRequest) when calling interceptor")
+					}
+					return c.callExportTripPDF(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportTripPDFResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportTripPDFResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callExportTripPDF(ctx context.Context, in *ExportTripPDFRequest) (*ExportTripPDFResponse, error) {
+	out := new(ExportTripPDFResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[26], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) ExportUserData(ctx context.Context, in *ExportUserDataRequest) (*ExportUserDataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportUserData")
+	caller := c.callExportUserData
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ExportUserDataRequest) (*ExportUserDataResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportUserDataRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportUserDataRequest) when calling interceptor")
+					}
+					return c.callExportUserData(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportUserDataResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportUserDataResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callExportUserData(ctx context.Context, in *ExportUserDataRequest) (*ExportUserDataResponse, error) {
+	out := new(ExportUserDataResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[27], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) ImportUserData(ctx context.Context, in *ImportUserDataRequest) (*ImportUserDataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ImportUserData")
+	caller := c.callImportUserData
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ImportUserDataRequest) (*ImportUserDataResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ImportUserDataRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ImportUserDataRequest) when calling interceptor")
+					}
+					return c.callImportUserData(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ImportUserDataResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ImportUserDataResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callImportUserData(ctx context.Context, in *ImportUserDataRequest) (*ImportUserDataResponse, error) {
+	out := new(ImportUserDataResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[28], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// =======================
+// ChatService JSON Client
+// =======================
+
+type chatServiceJSONClient struct {
+	client      HTTPClient
+	urls        [29]string
+	interceptor twirp.Interceptor
+	opts        twirp.ClientOptions
+}
+
+// NewChatServiceJSONClient creates a JSON client that implements the ChatService interface.
+// It communicates using JSON and can be configured with a custom HTTPClient.
+func NewChatServiceJSONClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) ChatService {
+	if c, ok := client.(*http.Client); ok {
+		client = withoutRedirects(c)
+	}
+
+	clientOpts := twirp.ClientOptions{}
+	for _, o := range opts {
+		o(&clientOpts)
+	}
+
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	literalURLs := false
+	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
+	var pathPrefix string
+	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
+	serviceURL := sanitizeBaseURL(baseURL)
+	serviceURL += baseServicePath(pathPrefix, "acai.chat", "ChatService")
+	urls := [29]string{
+		serviceURL + "StartConversation",
+		serviceURL + "ListConversationTemplates",
+		serviceURL + "StartConversationFromTemplate",
+		serviceURL + "ContinueConversation",
+		serviceURL + "ListConversations",
+		serviceURL + "DescribeConversation",
+		serviceURL + "SyncConversation",
+		serviceURL + "UpdateConversationLabels",
+		serviceURL + "PinConversation",
+		serviceURL + "FavoriteConversation",
+		serviceURL + "CreateWebhook",
+		serviceURL + "ListWebhooks",
+		serviceURL + "DeleteWebhook",
+		serviceURL + "ListWebhookDeliveries",
+		serviceURL + "GetProfile",
+		serviceURL + "UpdateProfile",
+		serviceURL + "ShareConversation",
+		serviceURL + "RevokeShare",
+		serviceURL + "SendVoiceMessage",
+		serviceURL + "BatchDeleteConversations",
+		serviceURL + "BatchArchiveConversations",
+		serviceURL + "GetConversationStats",
+		serviceURL + "RefreshReply",
+		serviceURL + "SubmitFeedback",
+		serviceURL + "FlagConversation",
+		serviceURL + "ExportItinerary",
+		serviceURL + "ExportTripPDF",
+		serviceURL + "ExportUserData",
+		serviceURL + "ImportUserData",
+	}
+
+	return &chatServiceJSONClient{
+		client:      client,
+		urls:        urls,
+		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
+		opts:        clientOpts,
+	}
+}
+
+func (c *chatServiceJSONClient) StartConversation(ctx context.Context, in *StartConversationRequest) (*StartConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
+	caller := c.callStartConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*StartConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+					}
+					return c.callStartConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*StartConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callStartConversation(ctx context.Context, in *StartConversationRequest) (*StartConversationResponse, error) {
+	out := new(StartConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ListConversationTemplates(ctx context.Context, in *ListConversationTemplatesRequest) (*ListConversationTemplatesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListConversationTemplates")
+	caller := c.callListConversationTemplates
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListConversationTemplatesRequest) (*ListConversationTemplatesResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListConversationTemplatesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListConversationTemplatesRequest) when calling interceptor")
+					}
+					return c.callListConversationTemplates(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListConversationTemplatesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationTemplatesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callListConversationTemplates(ctx context.Context, in *ListConversationTemplatesRequest) (*ListConversationTemplatesResponse, error) {
+	out := new(ListConversationTemplatesResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) StartConversationFromTemplate(ctx context.Context, in *StartConversationFromTemplateRequest) (*StartConversationFromTemplateResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "StartConversationFromTemplate")
+	caller := c.callStartConversationFromTemplate
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *StartConversationFromTemplateRequest) (*StartConversationFromTemplateResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*StartConversationFromTemplateRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*StartConversationFromTemplateRequest) when calling interceptor")
+					}
+					return c.callStartConversationFromTemplate(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*StartConversationFromTemplateResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationFromTemplateResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callStartConversationFromTemplate(ctx context.Context, in *StartConversationFromTemplateRequest) (*StartConversationFromTemplateResponse, error) {
+	out := new(StartConversationFromTemplateResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ContinueConversation(ctx context.Context, in *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
+	caller := c.callContinueConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ContinueConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+					}
+					return c.callContinueConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ContinueConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callContinueConversation(ctx context.Context, in *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+	out := new(ContinueConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ListConversations(ctx context.Context, in *ListConversationsRequest) (*ListConversationsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
+	caller := c.callListConversations
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+					}
+					return c.callListConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callListConversations(ctx context.Context, in *ListConversationsRequest) (*ListConversationsResponse, error) {
+	out := new(ListConversationsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) DescribeConversation(ctx context.Context, in *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
+	caller := c.callDescribeConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DescribeConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+					}
+					return c.callDescribeConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DescribeConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callDescribeConversation(ctx context.Context, in *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+	out := new(DescribeConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) SyncConversation(ctx context.Context, in *SyncConversationRequest) (*SyncConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SyncConversation")
+	caller := c.callSyncConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SyncConversationRequest) (*SyncConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SyncConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SyncConversationRequest) when calling interceptor")
+					}
+					return c.callSyncConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SyncConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SyncConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callSyncConversation(ctx context.Context, in *SyncConversationRequest) (*SyncConversationResponse, error) {
+	out := new(SyncConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) UpdateConversationLabels(ctx context.Context, in *UpdateConversationLabelsRequest) (*UpdateConversationLabelsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateConversationLabels")
+	caller := c.callUpdateConversationLabels
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UpdateConversationLabelsRequest) (*UpdateConversationLabelsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateConversationLabelsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateConversationLabelsRequest) when calling interceptor")
+					}
+					return c.callUpdateConversationLabels(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateConversationLabelsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateConversationLabelsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callUpdateConversationLabels(ctx context.Context, in *UpdateConversationLabelsRequest) (*UpdateConversationLabelsResponse, error) {
+	out := new(UpdateConversationLabelsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) PinConversation(ctx context.Context, in *PinConversationRequest) (*PinConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "PinConversation")
+	caller := c.callPinConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *PinConversationRequest) (*PinConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*PinConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*PinConversationRequest) when calling interceptor")
+					}
+					return c.callPinConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*PinConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*PinConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callPinConversation(ctx context.Context, in *PinConversationRequest) (*PinConversationResponse, error) {
+	out := new(PinConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) FavoriteConversation(ctx context.Context, in *FavoriteConversationRequest) (*FavoriteConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "FavoriteConversation")
+	caller := c.callFavoriteConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *FavoriteConversationRequest) (*FavoriteConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*FavoriteConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*FavoriteConversationRequest) when calling interceptor")
+					}
+					return c.callFavoriteConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*FavoriteConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*FavoriteConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callFavoriteConversation(ctx context.Context, in *FavoriteConversationRequest) (*FavoriteConversationResponse, error) {
+	out := new(FavoriteConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "CreateWebhook")
+	caller := c.callCreateWebhook
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreateWebhookRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreateWebhookRequest) when calling interceptor")
+					}
+					return c.callCreateWebhook(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreateWebhookResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateWebhookResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callCreateWebhook(ctx context.Context, in *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	out := new(CreateWebhookResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[10], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhooks")
+	caller := c.callListWebhooks
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhooksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhooksRequest) when calling interceptor")
+					}
+					return c.callListWebhooks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhooksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhooksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callListWebhooks(ctx context.Context, in *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	out := new(ListWebhooksResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[11], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteWebhook")
+	caller := c.callDeleteWebhook
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteWebhookRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteWebhookRequest) when calling interceptor")
+					}
+					return c.callDeleteWebhook(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteWebhookResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteWebhookResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callDeleteWebhook(ctx context.Context, in *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	out := new(DeleteWebhookResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[12], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhookDeliveries")
+	caller := c.callListWebhookDeliveries
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhookDeliveriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhookDeliveriesRequest) when calling interceptor")
+					}
+					return c.callListWebhookDeliveries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhookDeliveriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhookDeliveriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	out := new(ListWebhookDeliveriesResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[13], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) GetProfile(ctx context.Context, in *GetProfileRequest) (*GetProfileResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetProfile")
+	caller := c.callGetProfile
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetProfileRequest) (*GetProfileResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetProfileRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetProfileRequest) when calling interceptor")
+					}
+					return c.callGetProfile(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetProfileResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetProfileResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callGetProfile(ctx context.Context, in *GetProfileRequest) (*GetProfileResponse, error) {
+	out := new(GetProfileResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[14], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) UpdateProfile(ctx context.Context, in *UpdateProfileRequest) (*UpdateProfileResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateProfile")
+	caller := c.callUpdateProfile
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UpdateProfileRequest) (*UpdateProfileResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateProfileRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateProfileRequest) when calling interceptor")
+					}
+					return c.callUpdateProfile(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateProfileResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateProfileResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callUpdateProfile(ctx context.Context, in *UpdateProfileRequest) (*UpdateProfileResponse, error) {
+	out := new(UpdateProfileResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[15], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ShareConversation(ctx context.Context, in *ShareConversationRequest) (*ShareConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ShareConversation")
+	caller := c.callShareConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ShareConversationRequest) (*ShareConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ShareConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ShareConversationRequest) when calling interceptor")
+					}
+					return c.callShareConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ShareConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ShareConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callShareConversation(ctx context.Context, in *ShareConversationRequest) (*ShareConversationResponse, error) {
+	out := new(ShareConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[16], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) RevokeShare(ctx context.Context, in *RevokeShareRequest) (*RevokeShareResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeShare")
+	caller := c.callRevokeShare
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RevokeShareRequest) (*RevokeShareResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeShareRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeShareRequest) when calling interceptor")
+					}
+					return c.callRevokeShare(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeShareResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeShareResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callRevokeShare(ctx context.Context, in *RevokeShareRequest) (*RevokeShareResponse, error) {
+	out := new(RevokeShareResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[17], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) SendVoiceMessage(ctx context.Context, in *SendVoiceMessageRequest) (*SendVoiceMessageResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SendVoiceMessage")
+	caller := c.callSendVoiceMessage
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SendVoiceMessageRequest) (*SendVoiceMessageResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SendVoiceMessageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SendVoiceMessageRequest) when calling interceptor")
+					}
+					return c.callSendVoiceMessage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SendVoiceMessageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SendVoiceMessageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callSendVoiceMessage(ctx context.Context, in *SendVoiceMessageRequest) (*SendVoiceMessageResponse, error) {
+	out := new(SendVoiceMessageResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[18], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) BatchDeleteConversations(ctx context.Context, in *BatchDeleteConversationsRequest) (*BatchDeleteConversationsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "BatchDeleteConversations")
+	caller := c.callBatchDeleteConversations
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *BatchDeleteConversationsRequest) (*BatchDeleteConversationsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchDeleteConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchDeleteConversationsRequest) when calling interceptor")
+					}
+					return c.callBatchDeleteConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchDeleteConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchDeleteConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callBatchDeleteConversations(ctx context.Context, in *BatchDeleteConversationsRequest) (*BatchDeleteConversationsResponse, error) {
+	out := new(BatchDeleteConversationsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[19], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) BatchArchiveConversations(ctx context.Context, in *BatchArchiveConversationsRequest) (*BatchArchiveConversationsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "BatchArchiveConversations")
+	caller := c.callBatchArchiveConversations
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *BatchArchiveConversationsRequest) (*BatchArchiveConversationsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchArchiveConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchArchiveConversationsRequest) when calling interceptor")
+					}
+					return c.callBatchArchiveConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchArchiveConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchArchiveConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callBatchArchiveConversations(ctx context.Context, in *BatchArchiveConversationsRequest) (*BatchArchiveConversationsResponse, error) {
+	out := new(BatchArchiveConversationsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[20], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) GetConversationStats(ctx context.Context, in *GetConversationStatsRequest) (*GetConversationStatsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetConversationStats")
+	caller := c.callGetConversationStats
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetConversationStatsRequest) (*GetConversationStatsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetConversationStatsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetConversationStatsRequest) when calling interceptor")
+					}
+					return c.callGetConversationStats(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetConversationStatsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetConversationStatsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callGetConversationStats(ctx context.Context, in *GetConversationStatsRequest) (*GetConversationStatsResponse, error) {
+	out := new(GetConversationStatsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[21], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) RefreshReply(ctx context.Context, in *RefreshReplyRequest) (*RefreshReplyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RefreshReply")
+	caller := c.callRefreshReply
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RefreshReplyRequest) (*RefreshReplyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RefreshReplyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RefreshReplyRequest) when calling interceptor")
+					}
+					return c.callRefreshReply(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RefreshReplyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RefreshReplyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callRefreshReply(ctx context.Context, in *RefreshReplyRequest) (*RefreshReplyResponse, error) {
+	out := new(RefreshReplyResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[22], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) SubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SubmitFeedback")
+	caller := c.callSubmitFeedback
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SubmitFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SubmitFeedbackRequest) when calling interceptor")
+					}
+					return c.callSubmitFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SubmitFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SubmitFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callSubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+	out := new(SubmitFeedbackResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[23], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) FlagConversation(ctx context.Context, in *FlagConversationRequest) (*FlagConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "FlagConversation")
+	caller := c.callFlagConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *FlagConversationRequest) (*FlagConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*FlagConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*FlagConversationRequest) when calling interceptor")
+					}
+					return c.callFlagConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*FlagConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*FlagConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callFlagConversation(ctx context.Context, in *FlagConversationRequest) (*FlagConversationResponse, error) {
+	out := new(FlagConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[24], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ExportItinerary(ctx context.Context, in *ExportItineraryRequest) (*ExportItineraryResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportItinerary")
+	caller := c.callExportItinerary
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ExportItineraryRequest) (*ExportItineraryResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportItineraryRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportItineraryRequest) when calling interceptor")
+					}
+					return c.callExportItinerary(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportItineraryResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportItineraryResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callExportItinerary(ctx context.Context, in *ExportItineraryRequest) (*ExportItineraryResponse, error) {
+	out := new(ExportItineraryResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[25], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ExportTripPDF(ctx context.Context, in *ExportTripPDFRequest) (*ExportTripPDFResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportTripPDF")
+	caller := c.callExportTripPDF
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ExportTripPDFRequest) (*ExportTripPDFResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportTripPDFRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportTripPDFRequest) when calling interceptor")
+					}
+					return c.callExportTripPDF(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportTripPDFResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportTripPDFResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callExportTripPDF(ctx context.Context, in *ExportTripPDFRequest) (*ExportTripPDFResponse, error) {
+	out := new(ExportTripPDFResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[26], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ExportUserData(ctx context.Context, in *ExportUserDataRequest) (*ExportUserDataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportUserData")
+	caller := c.callExportUserData
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ExportUserDataRequest) (*ExportUserDataResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ExportUserDataRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ExportUserDataRequest) when calling interceptor")
+					}
+					return c.callExportUserData(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ExportUserDataResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportUserDataResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callExportUserData(ctx context.Context, in *ExportUserDataRequest) (*ExportUserDataResponse, error) {
+	out := new(ExportUserDataResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[27], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) ImportUserData(ctx context.Context, in *ImportUserDataRequest) (*ImportUserDataResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "ImportUserData")
+	caller := c.callImportUserData
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ImportUserDataRequest) (*ImportUserDataResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ImportUserDataRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ImportUserDataRequest) when calling interceptor")
+					}
+					return c.callImportUserData(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ImportUserDataResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ImportUserDataResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callImportUserData(ctx context.Context, in *ImportUserDataRequest) (*ImportUserDataResponse, error) {
+	out := new(ImportUserDataResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[28], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ==========================
+// ChatService Server Handler
+// ==========================
+
+type chatServiceServer struct {
+	ChatService
+	interceptor      twirp.Interceptor
+	hooks            *twirp.ServerHooks
+	pathPrefix       string // prefix for routing
+	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
+	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
+}
+
+// NewChatServiceServer builds a TwirpServer that can be used as an http.Handler to handle
+// HTTP requests that are routed to the right method in the provided svc implementation.
+// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
 func NewChatServiceServer(svc ChatService, opts ...interface{}) TwirpServer {
 	serverOpts := newServerOpts(opts)
 
-	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
-	jsonSkipDefaults := false
-	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
-	jsonCamelCase := false
-	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
-	var pathPrefix string
-	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
-		pathPrefix = "/twirp" // default prefix
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	jsonSkipDefaults := false
+	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
+	jsonCamelCase := false
+	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
+	var pathPrefix string
+	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	return &chatServiceServer{
+		ChatService:      svc,
+		hooks:            serverOpts.Hooks,
+		interceptor:      twirp.ChainInterceptors(serverOpts.Interceptors...),
+		pathPrefix:       pathPrefix,
+		jsonSkipDefaults: jsonSkipDefaults,
+		jsonCamelCase:    jsonCamelCase,
+	}
+}
+
+// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func (s *chatServiceServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	writeError(ctx, resp, err, s.hooks)
+}
+
+// handleRequestBodyError is used to handle error when the twirp server cannot read request
+func (s *chatServiceServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
+	if context.Canceled == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
+		return
+	}
+	if context.DeadlineExceeded == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
+		return
+	}
+	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
+}
+
+// ChatServicePathPrefix is a convenience constant that may identify URL paths.
+// Should be used with caution, it only matches routes generated by Twirp Go clients,
+// with the default "/twirp" prefix and default CamelCase service and method names.
+// More info: https://twitchtv.github.io/twirp/docs/routing.html
+const ChatServicePathPrefix = "/twirp/acai.chat.ChatService/"
+
+func (s *chatServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+
+	var err error
+	ctx, err = callRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	if req.Method != "POST" {
+		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
+	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
+	if pkgService != "acai.chat.ChatService" {
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+	if prefix != s.pathPrefix {
+		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	switch method {
+	case "StartConversation":
+		s.serveStartConversation(ctx, resp, req)
+		return
+	case "ListConversationTemplates":
+		s.serveListConversationTemplates(ctx, resp, req)
+		return
+	case "StartConversationFromTemplate":
+		s.serveStartConversationFromTemplate(ctx, resp, req)
+		return
+	case "ContinueConversation":
+		s.serveContinueConversation(ctx, resp, req)
+		return
+	case "ListConversations":
+		s.serveListConversations(ctx, resp, req)
+		return
+	case "DescribeConversation":
+		s.serveDescribeConversation(ctx, resp, req)
+		return
+	case "SyncConversation":
+		s.serveSyncConversation(ctx, resp, req)
+		return
+	case "UpdateConversationLabels":
+		s.serveUpdateConversationLabels(ctx, resp, req)
+		return
+	case "PinConversation":
+		s.servePinConversation(ctx, resp, req)
+		return
+	case "FavoriteConversation":
+		s.serveFavoriteConversation(ctx, resp, req)
+		return
+	case "CreateWebhook":
+		s.serveCreateWebhook(ctx, resp, req)
+		return
+	case "ListWebhooks":
+		s.serveListWebhooks(ctx, resp, req)
+		return
+	case "DeleteWebhook":
+		s.serveDeleteWebhook(ctx, resp, req)
+		return
+	case "ListWebhookDeliveries":
+		s.serveListWebhookDeliveries(ctx, resp, req)
+		return
+	case "GetProfile":
+		s.serveGetProfile(ctx, resp, req)
+		return
+	case "UpdateProfile":
+		s.serveUpdateProfile(ctx, resp, req)
+		return
+	case "ShareConversation":
+		s.serveShareConversation(ctx, resp, req)
+		return
+	case "RevokeShare":
+		s.serveRevokeShare(ctx, resp, req)
+		return
+	case "SendVoiceMessage":
+		s.serveSendVoiceMessage(ctx, resp, req)
+		return
+	case "BatchDeleteConversations":
+		s.serveBatchDeleteConversations(ctx, resp, req)
+		return
+	case "BatchArchiveConversations":
+		s.serveBatchArchiveConversations(ctx, resp, req)
+		return
+	case "GetConversationStats":
+		s.serveGetConversationStats(ctx, resp, req)
+		return
+	case "RefreshReply":
+		s.serveRefreshReply(ctx, resp, req)
+		return
+	case "SubmitFeedback":
+		s.serveSubmitFeedback(ctx, resp, req)
+		return
+	case "FlagConversation":
+		s.serveFlagConversation(ctx, resp, req)
+		return
+	case "ExportItinerary":
+		s.serveExportItinerary(ctx, resp, req)
+		return
+	case "ExportTripPDF":
+		s.serveExportTripPDF(ctx, resp, req)
+		return
+	case "ExportUserData":
+		s.serveExportUserData(ctx, resp, req)
+		return
+	case "ImportUserData":
+		s.serveImportUserData(ctx, resp, req)
+		return
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+}
+
+func (s *chatServiceServer) serveStartConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveStartConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveStartConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(StartConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.StartConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*StartConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.StartConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*StartConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *StartConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationResponse and nil error while calling StartConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(StartConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.StartConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*StartConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.StartConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*StartConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *StartConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationResponse and nil error while calling StartConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListConversationTemplates(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListConversationTemplatesJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListConversationTemplatesProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveListConversationTemplatesJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListConversationTemplates")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListConversationTemplatesRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.ListConversationTemplates
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListConversationTemplatesRequest) (*ListConversationTemplatesResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListConversationTemplatesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListConversationTemplatesRequest) when calling interceptor")
+					}
+					return s.ChatService.ListConversationTemplates(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListConversationTemplatesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationTemplatesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListConversationTemplatesResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationTemplatesResponse and nil error while calling ListConversationTemplates. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListConversationTemplatesProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListConversationTemplates")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListConversationTemplatesRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.ListConversationTemplates
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListConversationTemplatesRequest) (*ListConversationTemplatesResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListConversationTemplatesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListConversationTemplatesRequest) when calling interceptor")
+					}
+					return s.ChatService.ListConversationTemplates(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListConversationTemplatesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationTemplatesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListConversationTemplatesResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationTemplatesResponse and nil error while calling ListConversationTemplates. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveStartConversationFromTemplate(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveStartConversationFromTemplateJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveStartConversationFromTemplateProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveStartConversationFromTemplateJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "StartConversationFromTemplate")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(StartConversationFromTemplateRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.StartConversationFromTemplate
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *StartConversationFromTemplateRequest) (*StartConversationFromTemplateResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*StartConversationFromTemplateRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*StartConversationFromTemplateRequest) when calling interceptor")
+					}
+					return s.ChatService.StartConversationFromTemplate(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*StartConversationFromTemplateResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationFromTemplateResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *StartConversationFromTemplateResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationFromTemplateResponse and nil error while calling StartConversationFromTemplate. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveStartConversationFromTemplateProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "StartConversationFromTemplate")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(StartConversationFromTemplateRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.StartConversationFromTemplate
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *StartConversationFromTemplateRequest) (*StartConversationFromTemplateResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*StartConversationFromTemplateRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*StartConversationFromTemplateRequest) when calling interceptor")
+					}
+					return s.ChatService.StartConversationFromTemplate(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*StartConversationFromTemplateResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationFromTemplateResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *StartConversationFromTemplateResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationFromTemplateResponse and nil error while calling StartConversationFromTemplate. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveContinueConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveContinueConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveContinueConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ContinueConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.ContinueConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ContinueConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.ContinueConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ContinueConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ContinueConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ContinueConversationResponse and nil error while calling ContinueConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ContinueConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.ContinueConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ContinueConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.ContinueConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ContinueConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ContinueConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ContinueConversationResponse and nil error while calling ContinueConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListConversations(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListConversationsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListConversationsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListConversationsRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.ListConversations
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+					}
+					return s.ChatService.ListConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListConversationsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationsResponse and nil error while calling ListConversations. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListConversationsRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.ListConversations
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+					}
+					return s.ChatService.ListConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListConversationsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationsResponse and nil error while calling ListConversations. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveDescribeConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDescribeConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDescribeConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(DescribeConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.DescribeConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DescribeConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.DescribeConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DescribeConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DescribeConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DescribeConversationResponse and nil error while calling DescribeConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(DescribeConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.DescribeConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DescribeConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.DescribeConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DescribeConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DescribeConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DescribeConversationResponse and nil error while calling DescribeConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveSyncConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveSyncConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveSyncConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveSyncConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SyncConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(SyncConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.SyncConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SyncConversationRequest) (*SyncConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SyncConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SyncConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.SyncConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SyncConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SyncConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SyncConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SyncConversationResponse and nil error while calling SyncConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveSyncConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SyncConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(SyncConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.SyncConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SyncConversationRequest) (*SyncConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SyncConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SyncConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.SyncConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SyncConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SyncConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SyncConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SyncConversationResponse and nil error while calling SyncConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveUpdateConversationLabels(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveUpdateConversationLabelsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUpdateConversationLabelsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveUpdateConversationLabelsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateConversationLabels")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(UpdateConversationLabelsRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.UpdateConversationLabels
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdateConversationLabelsRequest) (*UpdateConversationLabelsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateConversationLabelsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateConversationLabelsRequest) when calling interceptor")
+					}
+					return s.ChatService.UpdateConversationLabels(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateConversationLabelsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateConversationLabelsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdateConversationLabelsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdateConversationLabelsResponse and nil error while calling UpdateConversationLabels. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveUpdateConversationLabelsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateConversationLabels")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(UpdateConversationLabelsRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.UpdateConversationLabels
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdateConversationLabelsRequest) (*UpdateConversationLabelsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateConversationLabelsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateConversationLabelsRequest) when calling interceptor")
+					}
+					return s.ChatService.UpdateConversationLabels(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateConversationLabelsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateConversationLabelsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdateConversationLabelsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdateConversationLabelsResponse and nil error while calling UpdateConversationLabels. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) servePinConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.servePinConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.servePinConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) servePinConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "PinConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(PinConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.PinConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *PinConversationRequest) (*PinConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*PinConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*PinConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.PinConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*PinConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*PinConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *PinConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *PinConversationResponse and nil error while calling PinConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) servePinConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "PinConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(PinConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.PinConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *PinConversationRequest) (*PinConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*PinConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*PinConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.PinConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*PinConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*PinConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *PinConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *PinConversationResponse and nil error while calling PinConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveFavoriteConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveFavoriteConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveFavoriteConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveFavoriteConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "FavoriteConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(FavoriteConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.FavoriteConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *FavoriteConversationRequest) (*FavoriteConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*FavoriteConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*FavoriteConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.FavoriteConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*FavoriteConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*FavoriteConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *FavoriteConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *FavoriteConversationResponse and nil error while calling FavoriteConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveFavoriteConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "FavoriteConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(FavoriteConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.FavoriteConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *FavoriteConversationRequest) (*FavoriteConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*FavoriteConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*FavoriteConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.FavoriteConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*FavoriteConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*FavoriteConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *FavoriteConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *FavoriteConversationResponse and nil error while calling FavoriteConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveCreateWebhook(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveCreateWebhookJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCreateWebhookProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveCreateWebhookJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateWebhook")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(CreateWebhookRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.CreateWebhook
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreateWebhookRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreateWebhookRequest) when calling interceptor")
+					}
+					return s.ChatService.CreateWebhook(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreateWebhookResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateWebhookResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CreateWebhookResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateWebhookResponse and nil error while calling CreateWebhook. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveCreateWebhookProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateWebhook")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(CreateWebhookRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.CreateWebhook
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreateWebhookRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreateWebhookRequest) when calling interceptor")
+					}
+					return s.ChatService.CreateWebhook(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreateWebhookResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateWebhookResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CreateWebhookResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateWebhookResponse and nil error while calling CreateWebhook. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListWebhooks(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListWebhooksJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListWebhooksProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveListWebhooksJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhooks")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListWebhooksRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.ListWebhooks
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhooksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhooksRequest) when calling interceptor")
+					}
+					return s.ChatService.ListWebhooks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhooksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhooksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListWebhooksResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListWebhooksResponse and nil error while calling ListWebhooks. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListWebhooksProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhooks")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListWebhooksRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.ListWebhooks
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhooksRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhooksRequest) when calling interceptor")
+					}
+					return s.ChatService.ListWebhooks(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhooksResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhooksResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListWebhooksResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListWebhooksResponse and nil error while calling ListWebhooks. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveDeleteWebhook(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDeleteWebhookJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDeleteWebhookProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveDeleteWebhookJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteWebhook")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(DeleteWebhookRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.DeleteWebhook
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteWebhookRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteWebhookRequest) when calling interceptor")
+					}
+					return s.ChatService.DeleteWebhook(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteWebhookResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteWebhookResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeleteWebhookResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteWebhookResponse and nil error while calling DeleteWebhook. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveDeleteWebhookProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteWebhook")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(DeleteWebhookRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.DeleteWebhook
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteWebhookRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteWebhookRequest) when calling interceptor")
+					}
+					return s.ChatService.DeleteWebhook(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteWebhookResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteWebhookResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeleteWebhookResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteWebhookResponse and nil error while calling DeleteWebhook. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListWebhookDeliveries(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListWebhookDeliveriesJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListWebhookDeliveriesProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveListWebhookDeliveriesJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhookDeliveries")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListWebhookDeliveriesRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.ListWebhookDeliveries
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhookDeliveriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhookDeliveriesRequest) when calling interceptor")
+					}
+					return s.ChatService.ListWebhookDeliveries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhookDeliveriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhookDeliveriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListWebhookDeliveriesResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListWebhookDeliveriesResponse and nil error while calling ListWebhookDeliveries. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListWebhookDeliveriesProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhookDeliveries")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListWebhookDeliveriesRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.ListWebhookDeliveries
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhookDeliveriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhookDeliveriesRequest) when calling interceptor")
+					}
+					return s.ChatService.ListWebhookDeliveries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhookDeliveriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhookDeliveriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListWebhookDeliveriesResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListWebhookDeliveriesResponse and nil error while calling ListWebhookDeliveries. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveGetProfile(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveGetProfileJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveGetProfileProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveGetProfileJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetProfile")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(GetProfileRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.GetProfile
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GetProfileRequest) (*GetProfileResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetProfileRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetProfileRequest) when calling interceptor")
+					}
+					return s.ChatService.GetProfile(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetProfileResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetProfileResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GetProfileResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetProfileResponse and nil error while calling GetProfile. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveGetProfileProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetProfile")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(GetProfileRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.GetProfile
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GetProfileRequest) (*GetProfileResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetProfileRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetProfileRequest) when calling interceptor")
+					}
+					return s.ChatService.GetProfile(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetProfileResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetProfileResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GetProfileResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetProfileResponse and nil error while calling GetProfile. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveUpdateProfile(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveUpdateProfileJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUpdateProfileProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveUpdateProfileJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateProfile")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(UpdateProfileRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.UpdateProfile
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdateProfileRequest) (*UpdateProfileResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateProfileRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateProfileRequest) when calling interceptor")
+					}
+					return s.ChatService.UpdateProfile(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateProfileResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateProfileResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdateProfileResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdateProfileResponse and nil error while calling UpdateProfile. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveUpdateProfileProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateProfile")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(UpdateProfileRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.UpdateProfile
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdateProfileRequest) (*UpdateProfileResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdateProfileRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdateProfileRequest) when calling interceptor")
+					}
+					return s.ChatService.UpdateProfile(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdateProfileResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdateProfileResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdateProfileResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdateProfileResponse and nil error while calling UpdateProfile. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveShareConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveShareConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveShareConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveShareConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ShareConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ShareConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.ShareConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ShareConversationRequest) (*ShareConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ShareConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ShareConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.ShareConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ShareConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ShareConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ShareConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ShareConversationResponse and nil error while calling ShareConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveShareConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ShareConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ShareConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.ShareConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ShareConversationRequest) (*ShareConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ShareConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ShareConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.ShareConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ShareConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ShareConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ShareConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ShareConversationResponse and nil error while calling ShareConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveRevokeShare(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveRevokeShareJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRevokeShareProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveRevokeShareJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeShare")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(RevokeShareRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.RevokeShare
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RevokeShareRequest) (*RevokeShareResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeShareRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeShareRequest) when calling interceptor")
+					}
+					return s.ChatService.RevokeShare(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeShareResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeShareResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RevokeShareResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeShareResponse and nil error while calling RevokeShare. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveRevokeShareProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RevokeShare")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(RevokeShareRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.RevokeShare
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RevokeShareRequest) (*RevokeShareResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RevokeShareRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RevokeShareRequest) when calling interceptor")
+					}
+					return s.ChatService.RevokeShare(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RevokeShareResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RevokeShareResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RevokeShareResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RevokeShareResponse and nil error while calling RevokeShare. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveSendVoiceMessage(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveSendVoiceMessageJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveSendVoiceMessageProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveSendVoiceMessageJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SendVoiceMessage")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(SendVoiceMessageRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.SendVoiceMessage
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SendVoiceMessageRequest) (*SendVoiceMessageResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SendVoiceMessageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SendVoiceMessageRequest) when calling interceptor")
+					}
+					return s.ChatService.SendVoiceMessage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SendVoiceMessageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SendVoiceMessageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SendVoiceMessageResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SendVoiceMessageResponse and nil error while calling SendVoiceMessage. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveSendVoiceMessageProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SendVoiceMessage")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(SendVoiceMessageRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.SendVoiceMessage
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SendVoiceMessageRequest) (*SendVoiceMessageResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SendVoiceMessageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SendVoiceMessageRequest) when calling interceptor")
+					}
+					return s.ChatService.SendVoiceMessage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SendVoiceMessageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SendVoiceMessageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SendVoiceMessageResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SendVoiceMessageResponse and nil error while calling SendVoiceMessage. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveBatchDeleteConversations(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveBatchDeleteConversationsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveBatchDeleteConversationsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveBatchDeleteConversationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "BatchDeleteConversations")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(BatchDeleteConversationsRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.BatchDeleteConversations
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *BatchDeleteConversationsRequest) (*BatchDeleteConversationsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchDeleteConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchDeleteConversationsRequest) when calling interceptor")
+					}
+					return s.ChatService.BatchDeleteConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchDeleteConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchDeleteConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *BatchDeleteConversationsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchDeleteConversationsResponse and nil error while calling BatchDeleteConversations. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveBatchDeleteConversationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "BatchDeleteConversations")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(BatchDeleteConversationsRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.BatchDeleteConversations
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *BatchDeleteConversationsRequest) (*BatchDeleteConversationsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchDeleteConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchDeleteConversationsRequest) when calling interceptor")
+					}
+					return s.ChatService.BatchDeleteConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchDeleteConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchDeleteConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *BatchDeleteConversationsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchDeleteConversationsResponse and nil error while calling BatchDeleteConversations. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveBatchArchiveConversations(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveBatchArchiveConversationsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveBatchArchiveConversationsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveBatchArchiveConversationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "BatchArchiveConversations")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(BatchArchiveConversationsRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.BatchArchiveConversations
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *BatchArchiveConversationsRequest) (*BatchArchiveConversationsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchArchiveConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchArchiveConversationsRequest) when calling interceptor")
+					}
+					return s.ChatService.BatchArchiveConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchArchiveConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchArchiveConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *BatchArchiveConversationsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchArchiveConversationsResponse and nil error while calling BatchArchiveConversations. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveBatchArchiveConversationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "BatchArchiveConversations")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(BatchArchiveConversationsRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.BatchArchiveConversations
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *BatchArchiveConversationsRequest) (*BatchArchiveConversationsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchArchiveConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchArchiveConversationsRequest) when calling interceptor")
+					}
+					return s.ChatService.BatchArchiveConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchArchiveConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchArchiveConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *BatchArchiveConversationsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchArchiveConversationsResponse and nil error while calling BatchArchiveConversations. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveGetConversationStats(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveGetConversationStatsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveGetConversationStatsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveGetConversationStatsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetConversationStats")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(GetConversationStatsRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.GetConversationStats
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GetConversationStatsRequest) (*GetConversationStatsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetConversationStatsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetConversationStatsRequest) when calling interceptor")
+					}
+					return s.ChatService.GetConversationStats(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetConversationStatsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetConversationStatsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GetConversationStatsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetConversationStatsResponse and nil error while calling GetConversationStats. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveGetConversationStatsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetConversationStats")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(GetConversationStatsRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.GetConversationStats
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GetConversationStatsRequest) (*GetConversationStatsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetConversationStatsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetConversationStatsRequest) when calling interceptor")
+					}
+					return s.ChatService.GetConversationStats(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetConversationStatsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetConversationStatsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GetConversationStatsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetConversationStatsResponse and nil error while calling GetConversationStats. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveRefreshReply(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveRefreshReplyJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRefreshReplyProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveRefreshReplyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RefreshReply")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(RefreshReplyRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.RefreshReply
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RefreshReplyRequest) (*RefreshReplyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RefreshReplyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RefreshReplyRequest) when calling interceptor")
+					}
+					return s.ChatService.RefreshReply(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RefreshReplyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RefreshReplyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RefreshReplyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RefreshReplyResponse and nil error while calling RefreshReply. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveRefreshReplyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RefreshReply")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
 	}
 
-	return &chatServiceServer{
-		ChatService:      svc,
-		hooks:            serverOpts.Hooks,
-		interceptor:      twirp.ChainInterceptors(serverOpts.Interceptors...),
-		pathPrefix:       pathPrefix,
-		jsonSkipDefaults: jsonSkipDefaults,
-		jsonCamelCase:    jsonCamelCase,
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(RefreshReplyRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.RefreshReply
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RefreshReplyRequest) (*RefreshReplyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RefreshReplyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RefreshReplyRequest) when calling interceptor")
+					}
+					return s.ChatService.RefreshReply(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RefreshReplyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RefreshReplyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RefreshReplyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RefreshReplyResponse and nil error while calling RefreshReply. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
 	}
+	callResponseSent(ctx, s.hooks)
 }
 
-// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
-// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
-func (s *chatServiceServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
-	writeError(ctx, resp, err, s.hooks)
+func (s *chatServiceServer) serveSubmitFeedback(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveSubmitFeedbackJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveSubmitFeedbackProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveSubmitFeedbackJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SubmitFeedback")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(SubmitFeedbackRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.SubmitFeedback
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SubmitFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SubmitFeedbackRequest) when calling interceptor")
+					}
+					return s.ChatService.SubmitFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SubmitFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SubmitFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SubmitFeedbackResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SubmitFeedbackResponse and nil error while calling SubmitFeedback. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveSubmitFeedbackProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SubmitFeedback")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(SubmitFeedbackRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.SubmitFeedback
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SubmitFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SubmitFeedbackRequest) when calling interceptor")
+					}
+					return s.ChatService.SubmitFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SubmitFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SubmitFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SubmitFeedbackResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SubmitFeedbackResponse and nil error while calling SubmitFeedback. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveFlagConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveFlagConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveFlagConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
 }
 
-// handleRequestBodyError is used to handle error when the twirp server cannot read request
-func (s *chatServiceServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
-	if context.Canceled == ctx.Err() {
-		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
+func (s *chatServiceServer) serveFlagConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "FlagConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	if context.DeadlineExceeded == ctx.Err() {
-		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
+	reqContent := new(FlagConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
-}
 
-// ChatServicePathPrefix is a convenience constant that may identify URL paths.
-// Should be used with caution, it only matches routes generated by Twirp Go clients,
-// with the default "/twirp" prefix and default CamelCase service and method names.
-// More info: https://twitchtv.github.io/twirp/docs/routing.html
-const ChatServicePathPrefix = "/twirp/acai.chat.ChatService/"
+	handler := s.ChatService.FlagConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *FlagConversationRequest) (*FlagConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*FlagConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*FlagConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.FlagConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*FlagConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*FlagConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
 
-func (s *chatServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
-	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
-	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
-	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+	// Call service method
+	var respContent *FlagConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
 
-	var err error
-	ctx, err = callRequestReceived(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
-
-	if req.Method != "POST" {
-		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *FlagConversationResponse and nil error while calling FlagConversation. nil responses are not supported"))
 		return
 	}
 
-	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
-	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
-	if pkgService != "acai.chat.ChatService" {
-		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
 		return
 	}
-	if prefix != s.pathPrefix {
-		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveFlagConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "FlagConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
 		return
 	}
 
-	switch method {
-	case "StartConversation":
-		s.serveStartConversation(ctx, resp, req)
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
-	case "ContinueConversation":
-		s.serveContinueConversation(ctx, resp, req)
+	}
+	reqContent := new(FlagConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
-	case "ListConversations":
-		s.serveListConversations(ctx, resp, req)
+	}
+
+	handler := s.ChatService.FlagConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *FlagConversationRequest) (*FlagConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*FlagConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*FlagConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.FlagConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*FlagConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*FlagConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *FlagConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
 		return
-	case "DescribeConversation":
-		s.serveDescribeConversation(ctx, resp, req)
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *FlagConversationResponse and nil error while calling FlagConversation. nil responses are not supported"))
 		return
-	default:
-		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
 		return
 	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveStartConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveExportItinerary(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -636,9 +7637,9 @@ func (s *chatServiceServer) serveStartConversation(ctx context.Context, resp htt
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveStartConversationJSON(ctx, resp, req)
+		s.serveExportItineraryJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveStartConversationProtobuf(ctx, resp, req)
+		s.serveExportItineraryProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -646,9 +7647,9 @@ func (s *chatServiceServer) serveStartConversation(ctx context.Context, resp htt
 	}
 }
 
-func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveExportItineraryJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportItinerary")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -661,29 +7662,29 @@ func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(StartConversationRequest)
+	reqContent := new(ExportItineraryRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.ChatService.StartConversation
+	handler := s.ChatService.ExportItinerary
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+		handler = func(ctx context.Context, req *ExportItineraryRequest) (*ExportItineraryResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*StartConversationRequest)
+					typedReq, ok := req.(*ExportItineraryRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ExportItineraryRequest) when calling interceptor")
 					}
-					return s.ChatService.StartConversation(ctx, typedReq)
+					return s.ChatService.ExportItinerary(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*StartConversationResponse)
+				typedResp, ok := resp.(*ExportItineraryResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportItineraryResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -692,7 +7693,7 @@ func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp
 	}
 
 	// Call service method
-	var respContent *StartConversationResponse
+	var respContent *ExportItineraryResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -703,7 +7704,7 @@ func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationResponse and nil error while calling StartConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportItineraryResponse and nil error while calling ExportItinerary. nil responses are not supported"))
 		return
 	}
 
@@ -729,9 +7730,9 @@ func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveExportItineraryProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportItinerary")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -743,28 +7744,28 @@ func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context,
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(StartConversationRequest)
+	reqContent := new(ExportItineraryRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.ChatService.StartConversation
+	handler := s.ChatService.ExportItinerary
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+		handler = func(ctx context.Context, req *ExportItineraryRequest) (*ExportItineraryResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*StartConversationRequest)
+					typedReq, ok := req.(*ExportItineraryRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ExportItineraryRequest) when calling interceptor")
 					}
-					return s.ChatService.StartConversation(ctx, typedReq)
+					return s.ChatService.ExportItinerary(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*StartConversationResponse)
+				typedResp, ok := resp.(*ExportItineraryResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportItineraryResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -773,7 +7774,7 @@ func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context,
 	}
 
 	// Call service method
-	var respContent *StartConversationResponse
+	var respContent *ExportItineraryResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -784,7 +7785,7 @@ func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context,
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationResponse and nil error while calling StartConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportItineraryResponse and nil error while calling ExportItinerary. nil responses are not supported"))
 		return
 	}
 
@@ -808,7 +7809,7 @@ func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context,
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveContinueConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveExportTripPDF(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -816,9 +7817,9 @@ func (s *chatServiceServer) serveContinueConversation(ctx context.Context, resp
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveContinueConversationJSON(ctx, resp, req)
+		s.serveExportTripPDFJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveContinueConversationProtobuf(ctx, resp, req)
+		s.serveExportTripPDFProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -826,9 +7827,9 @@ func (s *chatServiceServer) serveContinueConversation(ctx context.Context, resp
 	}
 }
 
-func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveExportTripPDFJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportTripPDF")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -841,29 +7842,29 @@ func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, r
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(ContinueConversationRequest)
+	reqContent := new(ExportTripPDFRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.ChatService.ContinueConversation
+	handler := s.ChatService.ExportTripPDF
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+		handler = func(ctx context.Context, req *ExportTripPDFRequest) (*ExportTripPDFResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ContinueConversationRequest)
+					typedReq, ok := req.(*ExportTripPDFRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ExportTripPDFRequest) when calling interceptor")
 					}
-					return s.ChatService.ContinueConversation(ctx, typedReq)
+					return s.ChatService.ExportTripPDF(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ContinueConversationResponse)
+				typedResp, ok := resp.(*ExportTripPDFResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportTripPDFResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -872,7 +7873,7 @@ func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, r
 	}
 
 	// Call service method
-	var respContent *ContinueConversationResponse
+	var respContent *ExportTripPDFResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -883,7 +7884,7 @@ func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, r
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *ContinueConversationResponse and nil error while calling ContinueConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportTripPDFResponse and nil error while calling ExportTripPDF. nil responses are not supported"))
 		return
 	}
 
@@ -909,9 +7910,9 @@ func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, r
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveExportTripPDFProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportTripPDF")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -923,28 +7924,28 @@ func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Contex
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(ContinueConversationRequest)
+	reqContent := new(ExportTripPDFRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.ChatService.ContinueConversation
+	handler := s.ChatService.ExportTripPDF
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+		handler = func(ctx context.Context, req *ExportTripPDFRequest) (*ExportTripPDFResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ContinueConversationRequest)
+					typedReq, ok := req.(*ExportTripPDFRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ExportTripPDFRequest) when calling interceptor")
 					}
-					return s.ChatService.ContinueConversation(ctx, typedReq)
+					return s.ChatService.ExportTripPDF(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ContinueConversationResponse)
+				typedResp, ok := resp.(*ExportTripPDFResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportTripPDFResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -953,7 +7954,7 @@ func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Contex
 	}
 
 	// Call service method
-	var respContent *ContinueConversationResponse
+	var respContent *ExportTripPDFResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -964,7 +7965,7 @@ func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Contex
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *ContinueConversationResponse and nil error while calling ContinueConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportTripPDFResponse and nil error while calling ExportTripPDF. nil responses are not supported"))
 		return
 	}
 
@@ -988,7 +7989,7 @@ func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Contex
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveListConversations(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveExportUserData(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -996,9 +7997,9 @@ func (s *chatServiceServer) serveListConversations(ctx context.Context, resp htt
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveListConversationsJSON(ctx, resp, req)
+		s.serveExportUserDataJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveListConversationsProtobuf(ctx, resp, req)
+		s.serveExportUserDataProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1006,9 +8007,9 @@ func (s *chatServiceServer) serveListConversations(ctx context.Context, resp htt
 	}
 }
 
-func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveExportUserDataJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportUserData")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1021,29 +8022,29 @@ func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(ListConversationsRequest)
+	reqContent := new(ExportUserDataRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.ChatService.ListConversations
+	handler := s.ChatService.ExportUserData
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+		handler = func(ctx context.Context, req *ExportUserDataRequest) (*ExportUserDataResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ListConversationsRequest)
+					typedReq, ok := req.(*ExportUserDataRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ExportUserDataRequest) when calling interceptor")
 					}
-					return s.ChatService.ListConversations(ctx, typedReq)
+					return s.ChatService.ExportUserData(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ListConversationsResponse)
+				typedResp, ok := resp.(*ExportUserDataResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportUserDataResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1052,7 +8053,7 @@ func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp
 	}
 
 	// Call service method
-	var respContent *ListConversationsResponse
+	var respContent *ExportUserDataResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1063,7 +8064,7 @@ func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationsResponse and nil error while calling ListConversations. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportUserDataResponse and nil error while calling ExportUserData. nil responses are not supported"))
 		return
 	}
 
@@ -1089,9 +8090,9 @@ func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveExportUserDataProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportUserData")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1103,28 +8104,28 @@ func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context,
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(ListConversationsRequest)
+	reqContent := new(ExportUserDataRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.ChatService.ListConversations
+	handler := s.ChatService.ExportUserData
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+		handler = func(ctx context.Context, req *ExportUserDataRequest) (*ExportUserDataResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ListConversationsRequest)
+					typedReq, ok := req.(*ExportUserDataRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ExportUserDataRequest) when calling interceptor")
 					}
-					return s.ChatService.ListConversations(ctx, typedReq)
+					return s.ChatService.ExportUserData(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ListConversationsResponse)
+				typedResp, ok := resp.(*ExportUserDataResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ExportUserDataResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1133,7 +8134,7 @@ func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context,
 	}
 
 	// Call service method
-	var respContent *ListConversationsResponse
+	var respContent *ExportUserDataResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1144,7 +8145,7 @@ func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context,
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationsResponse and nil error while calling ListConversations. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportUserDataResponse and nil error while calling ExportUserData. nil responses are not supported"))
 		return
 	}
 
@@ -1168,7 +8169,7 @@ func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context,
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveDescribeConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveImportUserData(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1176,9 +8177,9 @@ func (s *chatServiceServer) serveDescribeConversation(ctx context.Context, resp
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveDescribeConversationJSON(ctx, resp, req)
+		s.serveImportUserDataJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveDescribeConversationProtobuf(ctx, resp, req)
+		s.serveImportUserDataProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1186,9 +8187,9 @@ func (s *chatServiceServer) serveDescribeConversation(ctx context.Context, resp
 	}
 }
 
-func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveImportUserDataJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "ImportUserData")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1201,29 +8202,29 @@ func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, r
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(DescribeConversationRequest)
+	reqContent := new(ImportUserDataRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.ChatService.DescribeConversation
+	handler := s.ChatService.ImportUserData
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+		handler = func(ctx context.Context, req *ImportUserDataRequest) (*ImportUserDataResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DescribeConversationRequest)
+					typedReq, ok := req.(*ImportUserDataRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ImportUserDataRequest) when calling interceptor")
 					}
-					return s.ChatService.DescribeConversation(ctx, typedReq)
+					return s.ChatService.ImportUserData(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DescribeConversationResponse)
+				typedResp, ok := resp.(*ImportUserDataResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ImportUserDataResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1232,7 +8233,7 @@ func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, r
 	}
 
 	// Call service method
-	var respContent *DescribeConversationResponse
+	var respContent *ImportUserDataResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1243,7 +8244,7 @@ func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, r
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DescribeConversationResponse and nil error while calling DescribeConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ImportUserDataResponse and nil error while calling ImportUserData. nil responses are not supported"))
 		return
 	}
 
@@ -1269,9 +8270,9 @@ func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, r
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveImportUserDataProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "ImportUserData")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1283,28 +8284,28 @@ func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Contex
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(DescribeConversationRequest)
+	reqContent := new(ImportUserDataRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.ChatService.DescribeConversation
+	handler := s.ChatService.ImportUserData
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+		handler = func(ctx context.Context, req *ImportUserDataRequest) (*ImportUserDataResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DescribeConversationRequest)
+					typedReq, ok := req.(*ImportUserDataRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ImportUserDataRequest) when calling interceptor")
 					}
-					return s.ChatService.DescribeConversation(ctx, typedReq)
+					return s.ChatService.ImportUserData(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DescribeConversationResponse)
+				typedResp, ok := resp.(*ImportUserDataResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ImportUserDataResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1313,7 +8314,7 @@ func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Contex
 	}
 
 	// Call service method
-	var respContent *DescribeConversationResponse
+	var respContent *ImportUserDataResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1324,7 +8325,7 @@ func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Contex
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DescribeConversationResponse and nil error while calling DescribeConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ImportUserDataResponse and nil error while calling ImportUserData. nil responses are not supported"))
 		return
 	}
 
@@ -1929,39 +8930,216 @@ func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error)
 }
 
 var twirpFileDescriptor0 = []byte{
-	// 529 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x94, 0x51, 0x6b, 0xd3, 0x50,
-	0x14, 0xc7, 0x4d, 0xda, 0xd9, 0xe5, 0x64, 0xad, 0xdd, 0xa5, 0x60, 0x96, 0x15, 0x56, 0xa2, 0xb8,
-	0x3e, 0x48, 0x2a, 0x75, 0x0f, 0xc2, 0xf0, 0x61, 0x56, 0x85, 0xa1, 0x56, 0x48, 0x3a, 0x84, 0x09,
-	0xd3, 0x34, 0xbb, 0x66, 0x17, 0xd2, 0xdc, 0x98, 0x7b, 0x3b, 0xf0, 0xd3, 0xec, 0x83, 0xfa, 0x22,
-	0x4d, 0x6e, 0xe3, 0xbd, 0x34, 0xa9, 0x8a, 0x8f, 0xe7, 0xe4, 0x7f, 0xcf, 0xf9, 0xfd, 0xcf, 0x39,
-	0x04, 0x3a, 0x59, 0x1a, 0x8e, 0xc2, 0x9b, 0x80, 0xbb, 0x69, 0x46, 0x39, 0x45, 0x46, 0x10, 0x06,
-	0xc4, 0x5d, 0x25, 0xec, 0xa3, 0x88, 0xd2, 0x28, 0xc6, 0xa3, 0xfc, 0xc3, 0x7c, 0xf9, 0x6d, 0xc4,
-	0xc9, 0x02, 0x33, 0x1e, 0x2c, 0xd2, 0x42, 0xeb, 0xfc, 0xd4, 0x61, 0x6f, 0x42, 0x93, 0x5b, 0x9c,
-	0xb1, 0x80, 0x13, 0x9a, 0xa0, 0x0e, 0xe8, 0xe4, 0xda, 0xd2, 0x06, 0xda, 0xd0, 0xf0, 0x74, 0x72,
-	0x8d, 0x7a, 0xb0, 0xc3, 0x09, 0x8f, 0xb1, 0xa5, 0xe7, 0xa9, 0x22, 0x40, 0x2f, 0xc0, 0x28, 0x2b,
-	0x59, 0x8d, 0x81, 0x36, 0x34, 0xc7, 0xb6, 0x5b, 0xf4, 0x72, 0xd7, 0xbd, 0xdc, 0xd9, 0x5a, 0xe1,
-	0xfd, 0x16, 0xa3, 0x53, 0xd8, 0x5d, 0x60, 0xc6, 0x82, 0x08, 0x33, 0xab, 0x39, 0x68, 0x0c, 0xcd,
-	0xf1, 0x91, 0x5b, 0xf2, 0xba, 0x32, 0x8a, 0xfb, 0xa1, 0xd0, 0x79, 0xe5, 0x03, 0xfb, 0x4e, 0x83,
-	0x96, 0xc8, 0x6e, 0x80, 0x3e, 0x83, 0x66, 0x46, 0x05, 0x67, 0x67, 0xdc, 0xaf, 0x2b, 0xea, 0xd1,
-	0x18, 0x7b, 0xb9, 0x12, 0x59, 0xd0, 0x0a, 0x69, 0xc2, 0x71, 0xc2, 0x73, 0x0b, 0x86, 0xb7, 0x0e,
-	0x55, 0x7b, 0xcd, 0x7f, 0xb0, 0xe7, 0x3c, 0x85, 0xe6, 0xaa, 0x03, 0x32, 0xa1, 0x75, 0x31, 0x7d,
-	0x37, 0xfd, 0xf8, 0x69, 0xda, 0xbd, 0x87, 0x76, 0xa1, 0x79, 0xe1, 0xbf, 0xf1, 0xba, 0x1a, 0x6a,
-	0x83, 0x71, 0xe6, 0xfb, 0xe7, 0xfe, 0xec, 0x6c, 0x3a, 0xeb, 0xea, 0xce, 0x09, 0x58, 0x3e, 0x0f,
-	0x32, 0x2e, 0x13, 0x7a, 0xf8, 0xfb, 0x12, 0x33, 0xbe, 0xa2, 0x13, 0xbe, 0x85, 0xc9, 0x75, 0xe8,
-	0xa4, 0x70, 0x50, 0xf1, 0x8a, 0xa5, 0x34, 0x61, 0x18, 0x1d, 0xc3, 0x83, 0x50, 0xca, 0x7f, 0x29,
-	0x67, 0xd4, 0x91, 0xd3, 0xe7, 0x75, 0x8b, 0xed, 0xc1, 0x4e, 0x86, 0xd3, 0xf8, 0x87, 0x98, 0x48,
-	0x11, 0x38, 0x5f, 0xe1, 0x70, 0x42, 0x13, 0x4e, 0x92, 0x25, 0xae, 0x42, 0xfd, 0xeb, 0x9e, 0x92,
-	0x27, 0x5d, 0xf5, 0x74, 0x02, 0xfd, 0xea, 0x0e, 0xc2, 0x56, 0xc9, 0xa5, 0xc9, 0x5c, 0x36, 0x58,
-	0xef, 0x09, 0x53, 0x06, 0xc1, 0x04, 0x94, 0x73, 0x09, 0x07, 0x15, 0xdf, 0x44, 0xb9, 0x97, 0xd0,
-	0x96, 0xd1, 0x98, 0xa5, 0xe5, 0xa7, 0xf8, 0xb0, 0xe6, 0x6a, 0x3c, 0x55, 0xed, 0xbc, 0x85, 0xc3,
-	0xd7, 0x98, 0x85, 0x19, 0x99, 0xff, 0xd7, 0x3c, 0x9c, 0xcf, 0xd0, 0xaf, 0xae, 0x23, 0x30, 0x4f,
-	0x61, 0x4f, 0x7e, 0x91, 0x57, 0xd9, 0x42, 0xa9, 0x88, 0xc7, 0x77, 0x0d, 0x30, 0x27, 0x37, 0x01,
-	0xf7, 0x71, 0x76, 0x4b, 0x42, 0x8c, 0xae, 0x60, 0x7f, 0xe3, 0x6c, 0xd0, 0x23, 0xa9, 0x56, 0xdd,
-	0x29, 0xda, 0x8f, 0xb7, 0x8b, 0x04, 0x6c, 0x04, 0xbd, 0xaa, 0x15, 0xa2, 0x27, 0x2a, 0x6e, 0xdd,
-	0x15, 0xd9, 0xc7, 0x7f, 0xd4, 0x89, 0x46, 0x57, 0xb0, 0xbf, 0xb1, 0x59, 0xc5, 0x48, 0xdd, 0x4d,
-	0x28, 0x46, 0xea, 0x8f, 0x23, 0x82, 0x5e, 0xd5, 0x56, 0x14, 0x23, 0x5b, 0xd6, 0xaf, 0x18, 0xd9,
-	0xb6, 0xde, 0x57, 0xed, 0x4b, 0x93, 0x24, 0x1c, 0x67, 0x49, 0x10, 0x8f, 0xd2, 0xf9, 0xfc, 0x7e,
-	0xfe, 0x6b, 0x79, 0xfe, 0x2b, 0x00, 0x00, 0xff, 0xff, 0x59, 0x83, 0x17, 0xf4, 0xd0, 0x05, 0x00,
-	0x00,
+	// 3362 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x3a, 0x4b, 0x6f, 0x1c, 0xc7,
+	0xd1, 0x9e, 0x7d, 0x70, 0x77, 0x6b, 0xf9, 0x6c, 0x91, 0xe2, 0x6a, 0x44, 0x8a, 0xab, 0x91, 0x6c,
+	0xd1, 0xb6, 0x40, 0xd9, 0xfa, 0xe0, 0xcf, 0xf6, 0x67, 0xf8, 0xfb, 0xbe, 0xd5, 0xd3, 0x44, 0x64,
+	0xd9, 0x98, 0x25, 0xed, 0xc0, 0x86, 0xbd, 0x68, 0xee, 0x34, 0xc9, 0x89, 0x66, 0x67, 0xd6, 0xd3,
+	0xbd, 0x6b, 0x31, 0x07, 0x1f, 0x02, 0x04, 0x48, 0x10, 0x20, 0x40, 0x90, 0x43, 0x82, 0x00, 0xc9,
+	0x31, 0x48, 0x6e, 0x41, 0xf2, 0x0b, 0x9c, 0x7f, 0x90, 0x7b, 0x8e, 0x39, 0xe4, 0x1f, 0x24, 0xc7,
+	0xa0, 0x1f, 0xf3, 0x9e, 0xd9, 0x07, 0x29, 0xdf, 0xa6, 0xaa, 0xab, 0xbb, 0xab, 0xaa, 0xab, 0xab,
+	0xeb, 0x31, 0xb0, 0xec, 0x0f, 0xfb, 0x77, 0xfa, 0xa7, 0x98, 0xed, 0x0d, 0x7d, 0x8f, 0x79, 0xa8,
+	0x81, 0xfb, 0xd8, 0xde, 0xe3, 0x08, 0x7d, 0xe7, 0xc4, 0xf3, 0x4e, 0x1c, 0x72, 0x47, 0x0c, 0x1c,
+	0x8d, 0x8e, 0xef, 0x30, 0x7b, 0x40, 0x28, 0xc3, 0x83, 0xa1, 0xa4, 0x35, 0xfe, 0x59, 0x87, 0xc5,
+	0xfb, 0x9e, 0x3b, 0x26, 0x3e, 0xc5, 0xcc, 0xf6, 0x5c, 0xb4, 0x0c, 0x25, 0xdb, 0x6a, 0x69, 0x6d,
+	0x6d, 0xb7, 0x61, 0x96, 0x6c, 0x0b, 0xad, 0x43, 0x95, 0xd9, 0xcc, 0x21, 0xad, 0x92, 0x40, 0x49,
+	0x00, 0xbd, 0x03, 0x8d, 0x70, 0xa5, 0x56, 0xb9, 0xad, 0xed, 0x36, 0xef, 0xea, 0x7b, 0x72, 0xaf,
+	0xbd, 0x60, 0xaf, 0xbd, 0x83, 0x80, 0xc2, 0x8c, 0x88, 0xd1, 0x7b, 0x50, 0x1f, 0x10, 0x4a, 0xf1,
+	0x09, 0xa1, 0xad, 0x4a, 0xbb, 0xbc, 0xdb, 0xbc, 0xbb, 0xb3, 0x17, 0xf2, 0xbb, 0x17, 0x67, 0x65,
+	0xef, 0x43, 0x49, 0x67, 0x86, 0x13, 0x10, 0x82, 0x0a, 0xc3, 0x27, 0xb4, 0x55, 0x6d, 0x97, 0x77,
+	0x1b, 0xa6, 0xf8, 0x46, 0x97, 0x61, 0xe1, 0xd8, 0x73, 0x2c, 0xe2, 0xb7, 0x16, 0x04, 0x87, 0x0a,
+	0x42, 0xef, 0x41, 0x13, 0xfb, 0xfd, 0x53, 0x7b, 0x4c, 0xac, 0x1e, 0x66, 0xad, 0xda, 0x54, 0x26,
+	0x21, 0x20, 0xef, 0x30, 0xf4, 0x32, 0x2c, 0x33, 0xcf, 0x73, 0x68, 0xcf, 0xb2, 0x29, 0x3e, 0x72,
+	0x88, 0xd5, 0xaa, 0xb7, 0xb5, 0xdd, 0xba, 0xb9, 0x24, 0xb0, 0x0f, 0x14, 0x12, 0xbd, 0x0b, 0x75,
+	0x4a, 0x18, 0xb3, 0xdd, 0x13, 0xda, 0x6a, 0x88, 0x0d, 0xb6, 0x63, 0xc2, 0x3c, 0x26, 0x2e, 0xf1,
+	0x85, 0x28, 0x5d, 0x45, 0x64, 0x86, 0xe4, 0x68, 0x07, 0x9a, 0x8c, 0x0c, 0x86, 0x0e, 0x66, 0xa4,
+	0x67, 0x5b, 0x2d, 0x10, 0xbc, 0x43, 0x80, 0xda, 0xb7, 0x50, 0x0b, 0x6a, 0x43, 0xe2, 0x53, 0xcf,
+	0xc5, 0xad, 0xa6, 0x18, 0x0c, 0x40, 0x74, 0x1d, 0x16, 0xc5, 0x29, 0xf4, 0xa8, 0x37, 0xf2, 0xfb,
+	0xa4, 0xb5, 0x28, 0x86, 0x9b, 0x02, 0xd7, 0x15, 0x28, 0x3e, 0x99, 0x8e, 0x06, 0x03, 0xec, 0x9f,
+	0xb5, 0x96, 0xe4, 0x64, 0x05, 0xa2, 0x1b, 0xb0, 0x84, 0x47, 0xcc, 0xeb, 0x05, 0xc2, 0xb6, 0x96,
+	0x85, 0x60, 0x8b, 0x1c, 0xd9, 0x51, 0x38, 0xa4, 0x43, 0xdd, 0x27, 0x63, 0x9b, 0xda, 0x9e, 0xdb,
+	0x5a, 0x69, 0x6b, 0xbb, 0x65, 0x33, 0x84, 0xd1, 0xdb, 0xd0, 0x18, 0xda, 0xae, 0x2b, 0xb5, 0xba,
+	0x3a, 0x55, 0xab, 0x75, 0x49, 0xdc, 0x61, 0x7c, 0xd1, 0x63, 0x3c, 0xf6, 0x7c, 0x9b, 0x91, 0xd6,
+	0x9a, 0xd8, 0x34, 0x84, 0x39, 0x57, 0xea, 0x90, 0x7b, 0x7d, 0x6f, 0xe4, 0xb2, 0x16, 0x6a, 0x6b,
+	0xbb, 0x55, 0x73, 0x51, 0x21, 0xef, 0x73, 0x9c, 0xfe, 0xf3, 0x32, 0xd4, 0x94, 0x4d, 0x64, 0xcc,
+	0xf4, 0x0d, 0xa8, 0xf8, 0x9e, 0xb2, 0xd2, 0xe5, 0xbb, 0x5b, 0x45, 0x26, 0x65, 0x7a, 0x0e, 0x31,
+	0x05, 0x25, 0x57, 0x51, 0xdf, 0x73, 0x19, 0x71, 0x99, 0x30, 0xe0, 0x86, 0x19, 0x80, 0x49, 0xe3,
+	0xae, 0xcc, 0x63, 0xdc, 0x6f, 0x43, 0x13, 0x33, 0x86, 0xfb, 0xa7, 0x03, 0xe2, 0x32, 0x69, 0xa6,
+	0xcd, 0xbb, 0x1b, 0x31, 0x66, 0x3a, 0xe1, 0xa8, 0x19, 0xa7, 0x44, 0x6d, 0x68, 0xd2, 0xd1, 0xc9,
+	0x09, 0xa1, 0x9c, 0x4b, 0xda, 0x5a, 0x10, 0xf6, 0x1d, 0x47, 0x71, 0x33, 0xb7, 0x25, 0xb7, 0x35,
+	0x69, 0xe6, 0x12, 0x42, 0x6f, 0x42, 0xa3, 0x6f, 0x33, 0x2c, 0xe7, 0xd5, 0xc5, 0x86, 0x97, 0xe2,
+	0xd2, 0xab, 0x31, 0x33, 0xa2, 0xe2, 0x4b, 0x51, 0x86, 0xd9, 0x48, 0xda, 0x6c, 0xc3, 0x54, 0x10,
+	0xba, 0x03, 0xf5, 0x63, 0x42, 0xac, 0x23, 0xdc, 0x7f, 0x26, 0xec, 0x31, 0xb9, 0xd2, 0x23, 0x35,
+	0x64, 0x86, 0x44, 0xc6, 0x6d, 0xa8, 0x70, 0x85, 0xa2, 0x26, 0xd4, 0x0e, 0x9f, 0x7e, 0xef, 0xe9,
+	0x47, 0x9f, 0x3e, 0x5d, 0x7d, 0x09, 0xd5, 0xa1, 0x72, 0xd8, 0x7d, 0x68, 0xae, 0x6a, 0x68, 0x09,
+	0x1a, 0x9d, 0x6e, 0x77, 0xbf, 0x7b, 0xd0, 0x79, 0x7a, 0xb0, 0x5a, 0x32, 0x7e, 0xaa, 0x01, 0xca,
+	0x5e, 0x09, 0xb4, 0x05, 0x8d, 0x31, 0xf1, 0x8f, 0x3c, 0x6a, 0xb3, 0x33, 0x75, 0xa0, 0x11, 0x02,
+	0x5d, 0x03, 0xe8, 0xfb, 0x04, 0x33, 0x7b, 0xcc, 0x87, 0xa5, 0x0f, 0x8a, 0x61, 0xe4, 0xed, 0xf7,
+	0x07, 0x38, 0x38, 0x44, 0x05, 0xa1, 0x6d, 0x80, 0x01, 0x7e, 0xde, 0x73, 0x88, 0x7b, 0xc2, 0x4e,
+	0xc5, 0x21, 0x56, 0xcd, 0xc6, 0x00, 0x3f, 0x7f, 0x22, 0x10, 0xc6, 0x5f, 0x35, 0xa8, 0x07, 0xaa,
+	0x11, 0x5e, 0xc5, 0xf3, 0x1c, 0xb5, 0xb9, 0xf8, 0x16, 0x3a, 0x92, 0xb7, 0xab, 0xa4, 0x74, 0x24,
+	0x20, 0xf4, 0x2e, 0xc0, 0x31, 0x61, 0xfd, 0x53, 0x69, 0xfe, 0x33, 0x78, 0x3e, 0x45, 0xdd, 0x61,
+	0x7c, 0x2a, 0x79, 0x3e, 0xb4, 0x7d, 0x42, 0xf9, 0xd4, 0x19, 0xec, 0x4a, 0x51, 0x77, 0x18, 0x77,
+	0xc2, 0x94, 0x61, 0x87, 0xb4, 0xaa, 0xe2, 0xde, 0x48, 0xc0, 0xf8, 0x1a, 0xea, 0xc1, 0xa1, 0x70,
+	0x7e, 0xb9, 0x5e, 0xdd, 0x13, 0x25, 0x85, 0x82, 0xa4, 0x95, 0x0f, 0xb8, 0x91, 0x29, 0x41, 0x02,
+	0x90, 0xb3, 0x23, 0xf4, 0x38, 0xb3, 0x24, 0x8a, 0xba, 0xc3, 0x0c, 0x0f, 0x20, 0x32, 0xe4, 0xcc,
+	0x55, 0xe4, 0xf7, 0xdc, 0x76, 0x88, 0x8b, 0x07, 0x81, 0xf2, 0x42, 0x98, 0xbb, 0x2e, 0x75, 0xcb,
+	0x7a, 0xec, 0x6c, 0x48, 0xd4, 0xa1, 0x35, 0x15, 0xee, 0xe0, 0x6c, 0x48, 0xf8, 0x69, 0x50, 0xfb,
+	0x87, 0x44, 0x28, 0xa8, 0x6c, 0x8a, 0x6f, 0x83, 0xc0, 0x6a, 0xb4, 0xe1, 0xe1, 0xd0, 0xf1, 0x70,
+	0x72, 0x1b, 0x6d, 0xca, 0x36, 0xa5, 0xdc, 0x6d, 0x2c, 0xcc, 0xb0, 0xe0, 0x60, 0xd1, 0x14, 0xdf,
+	0xc6, 0xff, 0x42, 0xb5, 0x33, 0xb2, 0x6c, 0x2f, 0x1c, 0xd4, 0xa2, 0xc1, 0x19, 0xd6, 0x34, 0xbe,
+	0x2d, 0x41, 0xab, 0xcb, 0xb0, 0xcf, 0xe2, 0x3e, 0xc7, 0x24, 0x5f, 0x8d, 0x08, 0x65, 0xfc, 0x24,
+	0x94, 0x37, 0x53, 0xec, 0x06, 0x20, 0x7a, 0x3f, 0xe9, 0x35, 0x4a, 0xe2, 0x12, 0x5f, 0xcd, 0xf5,
+	0x1a, 0x52, 0xf6, 0xa4, 0xef, 0xe0, 0xc6, 0x31, 0x24, 0xf8, 0x59, 0xab, 0xac, 0x8c, 0x83, 0x03,
+	0xfc, 0x02, 0x60, 0xc6, 0x9f, 0x13, 0xc6, 0x9f, 0x97, 0x8a, 0xbc, 0x57, 0x0a, 0xb3, 0x6f, 0x71,
+	0x87, 0xab, 0x9e, 0xb6, 0x9e, 0x78, 0xd2, 0x94, 0x65, 0x2d, 0x2a, 0xe4, 0x01, 0xc7, 0x25, 0x9e,
+	0xb7, 0x85, 0xf9, 0x9e, 0xb7, 0xd8, 0xeb, 0x55, 0x4b, 0xbe, 0x5e, 0xdb, 0x00, 0xdc, 0x61, 0x7a,
+	0x23, 0xd6, 0x1b, 0x50, 0xf1, 0xac, 0x96, 0xa5, 0x0b, 0xf5, 0x46, 0xec, 0x43, 0x6a, 0xfc, 0xb9,
+	0x04, 0x57, 0x72, 0x74, 0x48, 0x87, 0x9e, 0x4b, 0x09, 0xba, 0x05, 0x2b, 0xfd, 0x18, 0xbe, 0x17,
+	0x1a, 0xde, 0x72, 0x1c, 0xbd, 0x5f, 0x14, 0xb6, 0xac, 0x43, 0xd5, 0x27, 0x43, 0xe7, 0x4c, 0xd9,
+	0x9d, 0x04, 0xd0, 0x9b, 0xd0, 0x14, 0x1f, 0x3d, 0xcc, 0x0f, 0x5f, 0xdd, 0xcc, 0xd5, 0xb8, 0xfe,
+	0x39, 0xde, 0x04, 0x41, 0x24, 0xbe, 0xd3, 0xfe, 0xba, 0x9a, 0xf5, 0xd7, 0x09, 0xbf, 0xbc, 0x30,
+	0x93, 0x5f, 0x7e, 0x07, 0x80, 0x5b, 0x5a, 0x0f, 0xd3, 0x9e, 0x77, 0x3c, 0x43, 0xc0, 0x52, 0xe7,
+	0xd4, 0x1d, 0xfa, 0xd1, 0xb1, 0xf1, 0x1b, 0x0d, 0xd6, 0xe3, 0xfa, 0x3a, 0x50, 0x61, 0x44, 0xe6,
+	0x6e, 0x22, 0xa8, 0xc4, 0xee, 0xa5, 0xf8, 0xe6, 0xb2, 0x58, 0x84, 0xf6, 0x7d, 0x7b, 0xc8, 0xa7,
+	0x06, 0x57, 0x32, 0x86, 0xe2, 0x57, 0xed, 0xc4, 0x27, 0x44, 0xb8, 0x17, 0x69, 0x49, 0x21, 0x3c,
+	0x5d, 0x13, 0x86, 0x01, 0xed, 0x27, 0x36, 0x65, 0x79, 0xfc, 0x51, 0x75, 0x39, 0x8c, 0x23, 0xb8,
+	0x3e, 0x81, 0x46, 0x1d, 0xfe, 0xfb, 0xd0, 0x08, 0xe2, 0x23, 0xda, 0xd2, 0x26, 0xc6, 0x8e, 0xc1,
+	0x64, 0x33, 0x9a, 0x61, 0x7c, 0xab, 0xc1, 0xcd, 0x8c, 0x65, 0x3d, 0xf2, 0xbd, 0x41, 0x48, 0xac,
+	0x6e, 0x6a, 0x2a, 0x34, 0xd3, 0x32, 0xa1, 0x59, 0xe6, 0xf2, 0x94, 0xa6, 0x5c, 0x9e, 0xf2, 0xb9,
+	0x2f, 0x4f, 0x25, 0x71, 0x79, 0x8c, 0xdf, 0x69, 0xf0, 0xf2, 0x14, 0x19, 0xbe, 0xcb, 0x9b, 0x92,
+	0x3a, 0xec, 0x4a, 0xf6, 0xb0, 0x7f, 0x5c, 0x86, 0xab, 0xf7, 0x3d, 0x97, 0xd9, 0xee, 0x88, 0xe4,
+	0x79, 0xc1, 0x99, 0xd9, 0x8a, 0xb9, 0xcb, 0xd2, 0x44, 0x77, 0x59, 0x3e, 0xaf, 0xbb, 0xac, 0x14,
+	0xbb, 0xcb, 0xea, 0x54, 0x77, 0xb9, 0x30, 0xe5, 0xc4, 0x6b, 0xf3, 0x9d, 0xb8, 0x1e, 0xcb, 0x8a,
+	0xea, 0x42, 0xab, 0x21, 0x9c, 0x72, 0x98, 0x8d, 0x94, 0xc3, 0xe4, 0xf2, 0x7c, 0x35, 0x22, 0x23,
+	0x22, 0x42, 0xb6, 0xba, 0x29, 0x01, 0xe3, 0x8f, 0x25, 0xd8, 0xca, 0x3f, 0x07, 0x65, 0x1f, 0xe1,
+	0x01, 0x6b, 0x13, 0x5c, 0x61, 0x69, 0x7e, 0x57, 0x58, 0x9e, 0xe2, 0x0a, 0x2b, 0xe7, 0x70, 0x85,
+	0xd5, 0xd9, 0x5d, 0x21, 0xba, 0x02, 0x75, 0x29, 0x81, 0x6d, 0xa9, 0x84, 0xb0, 0x26, 0xe0, 0x7d,
+	0x2b, 0x16, 0xf7, 0xd6, 0xe2, 0x71, 0xaf, 0xf1, 0x05, 0x5c, 0x32, 0xc9, 0xb1, 0x4f, 0xe8, 0xa9,
+	0xc9, 0x29, 0xe7, 0x36, 0x55, 0x1e, 0x6b, 0xaa, 0xe4, 0xc5, 0xb6, 0x94, 0xb5, 0x36, 0x14, 0x66,
+	0xdf, 0x32, 0x7e, 0xa6, 0xc1, 0x7a, 0x72, 0x7d, 0x75, 0x04, 0xef, 0x26, 0x23, 0x82, 0x19, 0x32,
+	0xe1, 0xf0, 0x0e, 0x24, 0xf5, 0x53, 0x9a, 0xe3, 0xa9, 0xf8, 0x85, 0x06, 0x1b, 0xdd, 0xd1, 0xd1,
+	0xc0, 0x66, 0x61, 0x40, 0xff, 0x62, 0xe5, 0x8d, 0x85, 0xa2, 0xe5, 0xa2, 0x50, 0xb4, 0x92, 0x08,
+	0x45, 0x8d, 0x2e, 0x5c, 0x4e, 0xb3, 0x74, 0x61, 0x15, 0x19, 0xbf, 0xd4, 0x60, 0xf3, 0x91, 0x83,
+	0x4f, 0x2e, 0xe4, 0x85, 0x66, 0x10, 0x95, 0x60, 0x1a, 0xbe, 0x9a, 0x0a, 0x9a, 0x20, 0xaa, 0x0e,
+	0xad, 0x2c, 0x53, 0x52, 0x58, 0xa3, 0x03, 0x97, 0x1f, 0x3e, 0x1f, 0x7a, 0x3e, 0xdb, 0x67, 0x36,
+	0xf7, 0x15, 0xfe, 0xdc, 0xa6, 0x68, 0xf8, 0xb0, 0x99, 0x59, 0x42, 0xa9, 0xf2, 0x82, 0xf1, 0x72,
+	0x2a, 0x5d, 0x5e, 0x0c, 0xd3, 0x65, 0xe3, 0xff, 0x60, 0x5d, 0xee, 0x79, 0xe0, 0xdb, 0xc3, 0x8f,
+	0x1f, 0x3c, 0x9a, 0x9b, 0xe9, 0x21, 0x6c, 0xa4, 0x16, 0xf8, 0xae, 0x59, 0xde, 0x0c, 0x76, 0x3c,
+	0xa4, 0xc4, 0x7f, 0x80, 0x19, 0x0e, 0xe2, 0x90, 0xaf, 0xe0, 0x72, 0x7a, 0xe0, 0xbb, 0xe6, 0xe5,
+	0x4d, 0xd8, 0xd8, 0x1f, 0xe4, 0xf0, 0x12, 0x9f, 0xa2, 0x25, 0xa7, 0xfc, 0x5a, 0x83, 0xcb, 0xfb,
+	0x83, 0x5c, 0x36, 0x6f, 0xc2, 0x52, 0x5c, 0xbb, 0x54, 0x4c, 0xad, 0x9a, 0x49, 0x24, 0xf7, 0xd9,
+	0xb6, 0x32, 0x10, 0x9b, 0xc8, 0xf0, 0xa5, 0x6a, 0xc6, 0x51, 0x3c, 0x2b, 0xf7, 0xc9, 0xc0, 0x76,
+	0x2d, 0xe2, 0xcb, 0xf0, 0xa5, 0x6a, 0x46, 0x08, 0x11, 0xa0, 0xf8, 0x1e, 0x97, 0x5f, 0xbd, 0xa2,
+	0x01, 0x68, 0x7c, 0x03, 0xad, 0x74, 0x20, 0x17, 0x04, 0x79, 0x68, 0x15, 0xca, 0x0c, 0x07, 0x09,
+	0x2a, 0xff, 0x8c, 0xd5, 0xee, 0x4a, 0x89, 0xda, 0x9d, 0x0e, 0xf5, 0xb0, 0x3e, 0x25, 0xb3, 0x9a,
+	0x10, 0xe6, 0x9c, 0x05, 0x65, 0x23, 0xaa, 0x76, 0x8f, 0x10, 0xc6, 0x67, 0x70, 0x25, 0x67, 0xff,
+	0x30, 0x80, 0xcc, 0x28, 0x87, 0x3f, 0x46, 0x9b, 0x05, 0x3e, 0x25, 0xa5, 0x35, 0xe3, 0x2f, 0x1a,
+	0x5c, 0x7d, 0x20, 0xc2, 0xe2, 0xa3, 0x8b, 0xc5, 0x36, 0x7b, 0xb0, 0x6a, 0xbb, 0x7d, 0x67, 0x64,
+	0x91, 0x5e, 0xf8, 0xea, 0x8b, 0x10, 0xf2, 0x83, 0x97, 0xcc, 0x15, 0x35, 0xa2, 0xfc, 0x19, 0xfd,
+	0x89, 0xa6, 0xa1, 0x57, 0x60, 0xc5, 0xc1, 0x94, 0xf5, 0xdc, 0x88, 0x5c, 0x1e, 0xc9, 0x12, 0x47,
+	0x3f, 0x0d, 0x48, 0xef, 0x5d, 0x82, 0xb5, 0x5e, 0x7a, 0x61, 0xe3, 0x73, 0xd8, 0xca, 0x67, 0x5a,
+	0x29, 0xe5, 0x3d, 0x61, 0xbc, 0x21, 0x5e, 0xf9, 0xd9, 0x42, 0x9d, 0x24, 0x88, 0x8d, 0x5f, 0x69,
+	0xb0, 0xd9, 0x3d, 0x73, 0xfb, 0x17, 0x52, 0x47, 0xbc, 0xda, 0x58, 0xca, 0x56, 0x1b, 0xe9, 0x99,
+	0xdb, 0x9f, 0xb5, 0x48, 0x51, 0x97, 0xc4, 0x1d, 0x66, 0xfc, 0x81, 0xe7, 0xe2, 0x19, 0xce, 0x94,
+	0xcc, 0x5b, 0xd0, 0x18, 0xb9, 0xfd, 0x53, 0xec, 0x9e, 0x10, 0xc9, 0x54, 0xdd, 0x8c, 0x10, 0x13,
+	0xf9, 0x49, 0x6b, 0xab, 0x3c, 0x87, 0xb6, 0x2e, 0x56, 0xfb, 0xde, 0x81, 0x66, 0xf4, 0x14, 0x05,
+	0x89, 0x16, 0x84, 0x6f, 0x11, 0x4d, 0xaa, 0x6a, 0x61, 0x0e, 0x55, 0x8d, 0x61, 0xe7, 0x70, 0x68,
+	0x61, 0x96, 0xb0, 0x8f, 0x27, 0xf8, 0x88, 0x38, 0x74, 0xee, 0xb3, 0x0c, 0x2a, 0xf4, 0xa5, 0xdc,
+	0x0a, 0x7d, 0x39, 0x7e, 0xcb, 0x8d, 0x1e, 0xb4, 0x8b, 0xf7, 0x7d, 0x11, 0xd6, 0xf9, 0x29, 0x5c,
+	0xfe, 0xd8, 0x76, 0x2f, 0x64, 0x9b, 0xeb, 0x50, 0x1d, 0xb9, 0x43, 0xdb, 0x55, 0x29, 0x9e, 0x04,
+	0x8c, 0x4f, 0x60, 0x33, 0xb3, 0xf0, 0x8b, 0x60, 0xf8, 0x18, 0xae, 0x3e, 0x52, 0xae, 0xec, 0x42,
+	0x5c, 0x5f, 0x03, 0x18, 0xb9, 0x61, 0xb1, 0x5d, 0xb2, 0x1e, 0xc3, 0x70, 0x9f, 0x90, 0xbf, 0xcf,
+	0x8b, 0x10, 0xe2, 0x09, 0xec, 0xdc, 0xc3, 0xac, 0x7f, 0xfa, 0x80, 0x38, 0x24, 0xb9, 0x7e, 0x68,
+	0x4e, 0xaf, 0xc2, 0x6a, 0x4a, 0x10, 0xe9, 0x8b, 0x1b, 0xe6, 0x4a, 0x52, 0x12, 0x6a, 0x3c, 0x86,
+	0x76, 0xf1, 0x6a, 0x8a, 0x5d, 0x9e, 0x9d, 0x89, 0x61, 0x4b, 0x75, 0x0f, 0xe4, 0xa3, 0xb7, 0xa8,
+	0x90, 0xa2, 0x7b, 0x60, 0x3c, 0x53, 0x0b, 0xa9, 0x26, 0xc7, 0x05, 0xf9, 0x92, 0x2e, 0x44, 0x3d,
+	0x4a, 0x4a, 0xc3, 0x11, 0xc2, 0xf8, 0x00, 0xae, 0x4f, 0xd8, 0x2c, 0x62, 0x7b, 0x24, 0xec, 0x3f,
+	0xc5, 0xb6, 0x42, 0x4a, 0xb6, 0xff, 0x5e, 0x81, 0xb5, 0xf8, 0xf4, 0x2e, 0xc3, 0x8c, 0x5e, 0x34,
+	0xbb, 0xcf, 0xb4, 0x5b, 0xca, 0xd9, 0x76, 0x0b, 0xba, 0x0d, 0x68, 0x44, 0x89, 0xdf, 0x4b, 0x52,
+	0xca, 0x52, 0xfa, 0x2a, 0x1f, 0xf9, 0x30, 0x4e, 0xfd, 0xdf, 0xb0, 0x89, 0x29, 0xb5, 0x29, 0xc3,
+	0x2e, 0x4b, 0x4d, 0xa9, 0x8a, 0x29, 0x1b, 0xe1, 0x70, 0x62, 0xde, 0x43, 0x00, 0x9e, 0x51, 0xf7,
+	0x46, 0x1c, 0xa5, 0x0a, 0x65, 0xaf, 0x14, 0x18, 0x9a, 0x90, 0x7d, 0x8f, 0x27, 0xdb, 0x87, 0x9c,
+	0xda, 0x6c, 0xb0, 0xe0, 0x93, 0x87, 0x60, 0xb6, 0x3b, 0x1c, 0xb1, 0x1e, 0xf3, 0x9e, 0x11, 0x57,
+	0x66, 0x78, 0x65, 0xb3, 0x29, 0x70, 0x07, 0x02, 0xc5, 0x85, 0xf6, 0x46, 0x2c, 0x46, 0x23, 0x6b,
+	0x8f, 0x8b, 0x12, 0xa9, 0x88, 0x1e, 0xc1, 0xda, 0xb1, 0xed, 0x53, 0xd6, 0xc3, 0x7d, 0xd9, 0x61,
+	0xe0, 0xce, 0xb4, 0x31, 0xd5, 0x99, 0xae, 0x88, 0x49, 0x1d, 0x35, 0xa7, 0xc3, 0xd0, 0x03, 0x58,
+	0x75, 0x70, 0x6a, 0x19, 0x98, 0xba, 0xcc, 0xb2, 0x83, 0x13, 0xab, 0xbc, 0x0a, 0xab, 0xd6, 0x48,
+	0x16, 0x0d, 0x7a, 0x94, 0xf4, 0x3d, 0xd7, 0xa2, 0xa2, 0x19, 0x58, 0x36, 0x57, 0x02, 0x7c, 0x57,
+	0xa2, 0xf5, 0xb7, 0xa0, 0x11, 0x2a, 0x26, 0x2c, 0xf3, 0x69, 0xb1, 0x32, 0xdf, 0x3a, 0x54, 0xe5,
+	0x71, 0xc8, 0x68, 0x4f, 0x02, 0xc6, 0x07, 0x70, 0xf5, 0x31, 0x61, 0x19, 0x25, 0x9f, 0xe3, 0xa2,
+	0x1e, 0xc1, 0x56, 0xfe, 0x4a, 0xca, 0xda, 0xef, 0xe5, 0x07, 0x5f, 0x5b, 0x93, 0xce, 0x3a, 0x1d,
+	0x81, 0x7d, 0x03, 0xb5, 0x4f, 0xc9, 0xd1, 0xa9, 0xe7, 0x3d, 0xcb, 0x54, 0x36, 0x57, 0xa1, 0x3c,
+	0xf2, 0x1d, 0x65, 0xe6, 0xfc, 0x93, 0x3f, 0x3b, 0x64, 0x1c, 0x96, 0x88, 0x1a, 0xa6, 0x82, 0x52,
+	0x8d, 0x8f, 0xca, 0x3c, 0x8d, 0x8f, 0x3f, 0x95, 0x60, 0x45, 0x31, 0xf0, 0x80, 0x38, 0xf6, 0x98,
+	0xf8, 0x67, 0x19, 0x46, 0xb6, 0x01, 0xbe, 0x96, 0x24, 0xb1, 0x94, 0x51, 0x61, 0xf6, 0x2d, 0x5e,
+	0x9f, 0x10, 0x7c, 0xf0, 0x41, 0xd5, 0x77, 0x14, 0xb0, 0x4c, 0x36, 0xc9, 0x38, 0x4c, 0x22, 0x54,
+	0xc9, 0x9e, 0x8c, 0x83, 0x14, 0x22, 0x2a, 0x5f, 0x54, 0x13, 0x6d, 0x3b, 0x1e, 0x2c, 0xcb, 0x42,
+	0x95, 0x2c, 0x4b, 0x55, 0xcd, 0x10, 0xe6, 0x7e, 0xc2, 0x57, 0x07, 0xd0, 0x8b, 0xd5, 0x3e, 0xaa,
+	0xe6, 0x72, 0x80, 0xee, 0xca, 0x45, 0xb6, 0x01, 0x84, 0xbd, 0x12, 0xdf, 0xf7, 0x7c, 0x71, 0x33,
+	0x1a, 0x66, 0x83, 0x63, 0x1e, 0x72, 0x44, 0xb2, 0x25, 0xda, 0x98, 0xa3, 0x25, 0x6a, 0xfc, 0x3f,
+	0xac, 0xdf, 0x17, 0xfa, 0x53, 0x7a, 0x8b, 0x25, 0x03, 0xfc, 0xbc, 0xb4, 0xbc, 0xf3, 0x2a, 0xc5,
+	0xcf, 0xcb, 0xf8, 0x02, 0x36, 0x52, 0x2b, 0x28, 0x8b, 0xba, 0x0d, 0x35, 0xa5, 0x57, 0xf5, 0x40,
+	0xa1, 0x98, 0x2d, 0x05, 0xc4, 0x01, 0x89, 0x50, 0x1f, 0xe9, 0xfb, 0x84, 0x85, 0x1d, 0x3d, 0x01,
+	0x19, 0x1b, 0x70, 0x89, 0x67, 0x0c, 0x8a, 0x3e, 0xac, 0x48, 0x3f, 0x82, 0xf5, 0x24, 0x5a, 0x6d,
+	0xba, 0x07, 0x75, 0xb5, 0x62, 0x60, 0xc1, 0x79, 0xbb, 0x86, 0x34, 0xc6, 0x5b, 0xb0, 0x2e, 0x9f,
+	0xae, 0x94, 0xfc, 0x49, 0x33, 0xd1, 0x52, 0x66, 0xc2, 0x33, 0xd4, 0xd4, 0x34, 0x55, 0x24, 0xe8,
+	0xc2, 0x56, 0x8c, 0x2f, 0x65, 0x85, 0x36, 0xa1, 0xb3, 0xad, 0xcb, 0xbd, 0x80, 0x63, 0x0f, 0xec,
+	0xd0, 0x0b, 0x08, 0xc0, 0xf8, 0x1c, 0xb6, 0x0b, 0x16, 0x55, 0x52, 0xff, 0x0f, 0x80, 0x15, 0x62,
+	0x95, 0xdc, 0x7a, 0x56, 0xee, 0xe0, 0x52, 0x98, 0x31, 0x6a, 0xe3, 0x1f, 0x1a, 0xd4, 0x3e, 0x96,
+	0xe9, 0x21, 0xda, 0x84, 0x9a, 0x78, 0x53, 0x42, 0xd6, 0x16, 0x38, 0x28, 0xf9, 0x22, 0x03, 0x6c,
+	0x07, 0x17, 0x58, 0x02, 0xe8, 0x35, 0x58, 0xa3, 0x0e, 0xee, 0x3f, 0xeb, 0x05, 0x22, 0x71, 0x93,
+	0x91, 0xb7, 0x66, 0x45, 0x0c, 0xa8, 0x7d, 0x0f, 0x7d, 0x87, 0x5f, 0x03, 0x1e, 0xc0, 0xbb, 0xc4,
+	0x09, 0x0a, 0xd3, 0x21, 0xcc, 0xaf, 0x7c, 0xf0, 0xd2, 0x62, 0x36, 0x43, 0x39, 0xb1, 0xa1, 0xa8,
+	0x3b, 0x22, 0xe6, 0xfa, 0xfa, 0x14, 0x33, 0x8a, 0x87, 0xc3, 0x9e, 0x3b, 0x1a, 0x1c, 0x85, 0xff,
+	0x99, 0x2c, 0x07, 0xe8, 0xa7, 0x02, 0x6b, 0x5c, 0x82, 0xb5, 0xc7, 0x84, 0x29, 0x41, 0x03, 0x2b,
+	0xba, 0x07, 0x28, 0x8e, 0x8c, 0x0c, 0x37, 0x48, 0x9f, 0xb3, 0x86, 0x1b, 0x10, 0x87, 0x29, 0xf5,
+	0x6f, 0x35, 0x58, 0x97, 0x71, 0x72, 0x72, 0xf1, 0x48, 0x67, 0xda, 0x54, 0x9d, 0x95, 0xa6, 0xeb,
+	0xac, 0x9c, 0xd2, 0x59, 0x8e, 0xe0, 0x95, 0x5c, 0xc1, 0x1f, 0xc2, 0x46, 0x8a, 0xbd, 0x73, 0x89,
+	0xf9, 0x6f, 0x0d, 0xaa, 0xdd, 0x53, 0xec, 0x67, 0x9b, 0x56, 0x39, 0xc1, 0x4e, 0xa9, 0x30, 0xd8,
+	0xe1, 0xcf, 0x78, 0xd0, 0xb4, 0x10, 0x40, 0xe0, 0x69, 0x2a, 0x91, 0xa7, 0x49, 0x76, 0xe2, 0xab,
+	0xf3, 0x74, 0xe2, 0x93, 0x8f, 0xc7, 0xc2, 0x1c, 0x8f, 0x07, 0x2f, 0x9a, 0xf8, 0x64, 0xec, 0x3d,
+	0x23, 0x96, 0xf0, 0xc1, 0x75, 0x33, 0x00, 0x0d, 0x0b, 0x5a, 0x42, 0xf2, 0x0b, 0xc5, 0xfc, 0xbc,
+	0x6b, 0xc5, 0x9c, 0x30, 0x4c, 0x90, 0x89, 0x2b, 0x30, 0xe6, 0xa8, 0x08, 0xc1, 0xb8, 0x0f, 0x57,
+	0x72, 0x76, 0x51, 0x67, 0xf5, 0x0a, 0x54, 0x29, 0x1f, 0x6c, 0x69, 0x99, 0x92, 0xbf, 0x98, 0x64,
+	0xca, 0x61, 0xe3, 0x0e, 0x20, 0x53, 0x70, 0x2d, 0xb1, 0x8a, 0xc9, 0x2b, 0x50, 0x17, 0xc3, 0x11,
+	0x77, 0x35, 0x01, 0xef, 0x5b, 0xdc, 0xbd, 0x26, 0x26, 0x28, 0x37, 0xf6, 0x7b, 0x5e, 0x38, 0x20,
+	0xae, 0xf5, 0x89, 0x67, 0xf7, 0x83, 0x5a, 0xc7, 0x79, 0x92, 0xb3, 0xa8, 0x4f, 0xb1, 0x68, 0x4a,
+	0x20, 0x51, 0xa9, 0x2b, 0xa7, 0x2a, 0x75, 0x3a, 0xd4, 0x1d, 0xec, 0x9e, 0x8c, 0x78, 0xac, 0xa9,
+	0x3a, 0x99, 0x01, 0x1c, 0x35, 0x86, 0xaa, 0xb1, 0xc6, 0x90, 0xf1, 0x37, 0x5e, 0x47, 0xc8, 0x30,
+	0xfa, 0x62, 0x9a, 0x6c, 0xd7, 0x00, 0x98, 0x8f, 0x5d, 0xd9, 0x68, 0x55, 0xbc, 0xc6, 0x30, 0x51,
+	0x8f, 0xa6, 0x32, 0xa1, 0x47, 0x53, 0x9d, 0xbf, 0x47, 0xb3, 0x30, 0xa5, 0x47, 0x53, 0x3b, 0x47,
+	0x8f, 0xa6, 0x3e, 0x7b, 0x0f, 0xe2, 0xee, 0xbf, 0x36, 0xa0, 0x79, 0xff, 0x14, 0xb3, 0x2e, 0xf1,
+	0xc7, 0x76, 0x9f, 0xa0, 0x2f, 0x61, 0x2d, 0xd3, 0xd4, 0x44, 0x37, 0xe2, 0x26, 0x58, 0xf0, 0x53,
+	0x85, 0x7e, 0x73, 0x32, 0x91, 0x3a, 0xa6, 0x71, 0xb6, 0x28, 0x18, 0x76, 0x97, 0xd1, 0xeb, 0xb1,
+	0x25, 0xa6, 0xf5, 0xa9, 0xf5, 0xdb, 0xb3, 0x11, 0xab, 0x7d, 0x7f, 0xa4, 0xc1, 0xf6, 0xc4, 0x6e,
+	0x2d, 0xba, 0x33, 0x89, 0xff, 0x9c, 0xde, 0xb4, 0xfe, 0xc6, 0xec, 0x13, 0x14, 0x13, 0x27, 0xb0,
+	0x9e, 0xd7, 0x08, 0x44, 0xa9, 0x24, 0xab, 0xa8, 0x63, 0xab, 0xdf, 0x9a, 0x4a, 0xa7, 0x36, 0xfa,
+	0x12, 0xd6, 0xd2, 0x2a, 0xa1, 0x89, 0x53, 0x2c, 0x2a, 0x0c, 0xeb, 0x37, 0x27, 0x13, 0x45, 0x82,
+	0xe4, 0x15, 0x32, 0x13, 0x82, 0x4c, 0x28, 0xcf, 0xea, 0xb7, 0xa6, 0xd2, 0xa9, 0x8d, 0x3e, 0x87,
+	0xd5, 0x74, 0xe5, 0x10, 0x19, 0x71, 0xbd, 0xe7, 0x17, 0x3c, 0xf5, 0x1b, 0x13, 0x69, 0xd4, 0xe2,
+	0x14, 0x5a, 0x45, 0x45, 0x2f, 0xf4, 0x5a, 0x6c, 0x81, 0x29, 0x15, 0x39, 0xfd, 0xf5, 0x99, 0x68,
+	0xd5, 0xa6, 0xdf, 0x87, 0x95, 0x54, 0xbd, 0x0a, 0x5d, 0x8f, 0xbf, 0xc5, 0xb9, 0x45, 0x32, 0xdd,
+	0x98, 0x44, 0x12, 0x1d, 0x4a, 0x5e, 0x25, 0x29, 0x71, 0x28, 0x13, 0x4a, 0x5a, 0xfa, 0xad, 0xa9,
+	0x74, 0x6a, 0x23, 0x13, 0x96, 0x12, 0x59, 0x00, 0x4a, 0x94, 0x4e, 0x73, 0x32, 0x0c, 0xbd, 0x5d,
+	0x4c, 0xa0, 0xd6, 0xfc, 0x08, 0x16, 0xe3, 0x31, 0x3e, 0xba, 0x96, 0xb2, 0xc3, 0x54, 0x4e, 0xa0,
+	0xef, 0x14, 0x8e, 0x47, 0x4c, 0x26, 0xa2, 0xf6, 0x04, 0x93, 0x79, 0x69, 0x80, 0xde, 0x2e, 0x26,
+	0x50, 0x6b, 0xfe, 0x00, 0x36, 0x72, 0x63, 0x73, 0x74, 0x2b, 0x9f, 0x9b, 0x4c, 0x4a, 0xa0, 0xef,
+	0x4e, 0x27, 0x54, 0x7b, 0xed, 0x03, 0x44, 0xe1, 0x2a, 0xda, 0x4a, 0xfc, 0xbd, 0x90, 0x0a, 0x6d,
+	0xf5, 0xed, 0x82, 0xd1, 0x48, 0x15, 0x89, 0xa8, 0x30, 0xa1, 0x8a, 0xbc, 0x70, 0x56, 0x6f, 0x17,
+	0x13, 0x44, 0x1e, 0x26, 0x13, 0xc1, 0x24, 0xdf, 0x89, 0x82, 0x28, 0x4a, 0xbf, 0x39, 0x99, 0x48,
+	0xad, 0xff, 0x04, 0x9a, 0xb1, 0x58, 0x05, 0xc5, 0x25, 0xcc, 0x06, 0x3d, 0xfa, 0xb5, 0xa2, 0xe1,
+	0x98, 0x1b, 0x49, 0x05, 0x0e, 0x49, 0x37, 0x92, 0x1f, 0xfe, 0xe8, 0x37, 0x26, 0xd2, 0x44, 0x6e,
+	0xa4, 0xa8, 0x2c, 0x9a, 0x70, 0x23, 0x53, 0x2a, 0xb1, 0xfa, 0xeb, 0x33, 0xd1, 0x46, 0xef, 0x68,
+	0x61, 0x55, 0x13, 0x65, 0x56, 0x9a, 0x50, 0x68, 0xd5, 0x6f, 0xcf, 0x46, 0x1c, 0x39, 0x99, 0xbc,
+	0xd2, 0x52, 0xc2, 0xc9, 0x4c, 0xa8, 0x62, 0xe9, 0xb7, 0xa6, 0xd2, 0x45, 0x0e, 0x21, 0xfe, 0xa7,
+	0x06, 0x4a, 0x1e, 0x71, 0xe6, 0x17, 0x11, 0x7d, 0xa7, 0x70, 0x5c, 0x2d, 0x78, 0x08, 0xcb, 0xc9,
+	0x3f, 0x1b, 0x50, 0xdc, 0xca, 0x73, 0xff, 0xc3, 0xd0, 0xaf, 0x4f, 0xa0, 0x88, 0x4c, 0x2b, 0xfd,
+	0x17, 0x41, 0xc2, 0xb4, 0x0a, 0xfe, 0x7b, 0xd0, 0x6f, 0x4c, 0xa4, 0x89, 0x1e, 0x8b, 0xd4, 0x3f,
+	0x04, 0x89, 0xc7, 0x22, 0xff, 0x17, 0x05, 0xdd, 0x98, 0x44, 0x12, 0xf9, 0x84, 0x44, 0xa3, 0x3f,
+	0xe1, 0x13, 0xf2, 0xfe, 0x21, 0xd0, 0xdb, 0xc5, 0x04, 0x91, 0x86, 0x93, 0x1d, 0x7b, 0x94, 0x9d,
+	0x93, 0xea, 0xac, 0xeb, 0xd7, 0x27, 0x50, 0x44, 0xcb, 0xee, 0x0f, 0x0a, 0x97, 0xdd, 0x1f, 0x4c,
+	0x5b, 0x36, 0xbf, 0x3d, 0x7f, 0x6f, 0xe9, 0xb3, 0xa6, 0xed, 0x32, 0xe2, 0xbb, 0xd8, 0xb9, 0x33,
+	0x3c, 0x3a, 0x5a, 0x10, 0x61, 0xf2, 0x7f, 0xfd, 0x67, 0x00, 0xd0, 0x83, 0x9e, 0x97, 0xc2, 0x33,
+	0x00, 0x00,
 }
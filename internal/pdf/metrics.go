@@ -0,0 +1,93 @@
+package pdf
+
+import "strings"
+
+// Widths of the printable ASCII characters, from space to tilde, in thousandths of the
+// font size, from the Adobe font metrics of the standard fonts.
+var (
+	helvetica = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBold = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// width returns the width of s set in font at size, in points. Characters outside
+// ASCII, mostly accented letters, count as wide as a lowercase letter.
+func width(font Font, size float64, s string) float64 {
+	table := &helvetica
+	if font == Bold {
+		table = &helveticaBold
+	}
+	total := 0
+	for _, c := range encode(s) {
+		if c >= 32 && c < 127 {
+			total += table[c-32]
+		} else {
+			total += table['n'-32]
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// wrap breaks s into the lines fitting in max points, at spaces, and at its own line
+// breaks. Words longer than a line are broken where they overflow.
+func wrap(font Font, size float64, s string, max float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if width(font, size, candidate) <= max {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for width(font, size, word) > max {
+				cut := fit(font, size, word, max)
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit returns the length of the longest prefix of word fitting in max points, at
+// least one character.
+func fit(font Font, size float64, word string, max float64) int {
+	n := 0
+	for i := range word {
+		if i > 0 && width(font, size, word[:i]) > max {
+			break
+		}
+		n = i
+	}
+	if n == 0 {
+		for i := range word {
+			if i > 0 {
+				return i
+			}
+		}
+		return len(word)
+	}
+	return n
+}
@@ -0,0 +1,328 @@
+// Package pdf writes simple printable documents: branded A4 pages of headings, wrapped
+// paragraphs and timetable entries. It writes PDF 1.4 with the standard Helvetica fonts,
+// which readers embed themselves, so documents stay small and need no font files; text
+// is limited to the Windows-1252 (WinAnsi) characters these fonts cover.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of PDF files.
+const ContentType = "application/pdf"
+
+// Page geometry, in points: A4, with its margins and the band of the brand on top.
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	margin       = 50.0
+	bandHeight   = 36.0
+	footerHeight = 30.0
+	contentWidth = pageWidth - 2*margin
+	contentTop   = pageHeight - bandHeight - 28
+	contentFloor = margin + footerHeight
+	labelWidth   = 80.0
+)
+
+// Font is one of the standard fonts of documents.
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+// Branding is how a tenant's documents look.
+type Branding struct {
+	// Name is printed in the band on top of every page
+	Name string `json:"name,omitempty"`
+	// Color is the color of the band and of headings, as "#rrggbb"
+	Color string `json:"color,omitempty"`
+	// Footer is printed at the bottom of every page, like a support contact
+	Footer string `json:"footer,omitempty"`
+}
+
+// DefaultBranding brands the documents of tenants without a branding.
+var DefaultBranding = Branding{Name: "Acai Travel", Color: "#5b2a86"}
+
+// Validate reports whether the branding is usable.
+func (b Branding) Validate() error {
+	if b.Color == "" {
+		return nil
+	}
+	_, err := parseColor(b.Color)
+	return err
+}
+
+// WithDefaults returns b, with the fields it leaves empty taken from DefaultBranding.
+func (b Branding) WithDefaults() Branding {
+	if b.Name == "" {
+		b.Name = DefaultBranding.Name
+	}
+	if b.Color == "" {
+		b.Color = DefaultBranding.Color
+	}
+	return b
+}
+
+type rgb [3]float64
+
+func parseColor(s string) (rgb, error) {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || len(hex) != 6 {
+		return rgb{}, fmt.Errorf("color must be of the form #rrggbb, got %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return rgb{}, fmt.Errorf("color must be of the form #rrggbb, got %q", s)
+	}
+	return rgb{float64(v>>16) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255}, nil
+}
+
+var (
+	black = rgb{0.1, 0.1, 0.1}
+	gray  = rgb{0.45, 0.45, 0.45}
+	white = rgb{1, 1, 1}
+)
+
+// Document is a document being written, page after page as its content overflows.
+type Document struct {
+	title   string
+	brand   Branding
+	color   rgb
+	created time.Time
+
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// New starts a document titled title, branded with brand and created at now.
+func New(title string, brand Branding, now time.Time) *Document {
+	brand = brand.WithDefaults()
+	color, err := parseColor(brand.Color)
+	if err != nil {
+		color, _ = parseColor(DefaultBranding.Color)
+	}
+	d := &Document{title: title, brand: brand, color: color, created: now}
+	d.newPage()
+	return d
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+	d.y = contentTop
+}
+
+// page returns the current page, after breaking it if less than height is left.
+func (d *Document) page(height float64) *bytes.Buffer {
+	if d.y-height < contentFloor && d.y < contentTop {
+		d.newPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Heading writes a heading in the color of the brand.
+func (d *Document) Heading(s string) {
+	d.block(Bold, 18, d.color, margin, contentWidth, s, 6)
+}
+
+// Subheading writes the heading of a section.
+func (d *Document) Subheading(s string) {
+	d.Space(6)
+	d.block(Bold, 12.5, black, margin, contentWidth, s, 4)
+}
+
+// Paragraph writes s wrapped to the width of the page, keeping its line breaks.
+func (d *Document) Paragraph(s string) {
+	d.block(Regular, 10.5, black, margin, contentWidth, s, 6)
+}
+
+// Note writes s in small gray type.
+func (d *Document) Note(s string) {
+	d.block(Regular, 9, gray, margin, contentWidth, s, 6)
+}
+
+// Entry writes an entry of a timetable: label, like a time, in a column on the left
+// of title and the lines detailing it.
+func (d *Document) Entry(label, title string, lines ...string) {
+	const size = 10.5
+	leading := size * 1.35
+	heading := wrap(Bold, size, title, contentWidth-labelWidth)
+	var body []string
+	for _, l := range lines {
+		body = append(body, wrap(Regular, size, l, contentWidth-labelWidth)...)
+	}
+
+	// Entries are kept on one page, unless longer than a page
+	p := d.page(float64(len(heading)+len(body)) * leading)
+	text(p, Bold, size, d.color, margin, d.y-size, label)
+	for i, l := range append(heading, body...) {
+		font := Regular
+		if i < len(heading) {
+			font = Bold
+		}
+		p = d.page(leading)
+		d.y -= leading
+		text(p, font, size, black, margin+labelWidth, d.y+leading-size, l)
+	}
+	d.y -= 8
+}
+
+// Space leaves height points of blank space.
+func (d *Document) Space(height float64) {
+	d.y -= height
+}
+
+// block writes text wrapped to width, from x, followed by after points of space.
+func (d *Document) block(font Font, size float64, color rgb, x, width float64, s string, after float64) {
+	leading := size * 1.35
+	for _, l := range wrap(font, size, s, width) {
+		p := d.page(leading)
+		d.y -= leading
+		text(p, font, size, color, x, d.y+leading-size, l)
+	}
+	d.y -= after
+}
+
+// Pages returns the number of pages written so far.
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+// Bytes returns the document as a PDF file, each page with the band of the brand and
+// a numbered footer.
+func (d *Document) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1 to 5 are the catalog, the page tree, the fonts and the document
+	// information; each page follows with its content
+	const firstPage = 6
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	obj(fmt.Sprintf("<< /Title %s /Author %s /Producer (Acai Travel) /CreationDate (D:%s) >>",
+		literal(d.title), literal(d.brand.Name), d.created.UTC().Format("20060102150405Z")))
+
+	for i, content := range d.pages {
+		var stream bytes.Buffer
+		d.decorate(&stream, i+1)
+		stream.Write(content.Bytes())
+
+		var packed bytes.Buffer
+		zw := zlib.NewWriter(&packed)
+		zw.Write(stream.Bytes())
+		zw.Close()
+
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(pageWidth), num(pageHeight), firstPage+2*i+1))
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", packed.Len(), packed.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// decorate draws the band of the brand and the footer of page n.
+func (d *Document) decorate(w *bytes.Buffer, n int) {
+	fmt.Fprintf(w, "%s %s %s rg 0 %s %s %s re f\n",
+		num(d.color[0]), num(d.color[1]), num(d.color[2]), num(pageHeight-bandHeight), num(pageWidth), num(bandHeight))
+	text(w, Bold, 13, white, margin, pageHeight-bandHeight+13, d.brand.Name)
+
+	y := margin - 4
+	footer := fmt.Sprintf("Page %d of %d", n, len(d.pages))
+	text(w, Regular, 8.5, gray, pageWidth-margin-width(Regular, 8.5, footer), y, footer)
+	if d.brand.Footer != "" {
+		lines := wrap(Regular, 8.5, d.brand.Footer, contentWidth-80)
+		text(w, Regular, 8.5, gray, margin, y, lines[0])
+	}
+}
+
+// text draws a line of text with its baseline at x, y.
+func text(w *bytes.Buffer, font Font, size float64, color rgb, x, y float64, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(w, "BT /F%d %s Tf %s %s %s rg %s %s Td %s Tj ET\n",
+		font+1, num(size), num(color[0]), num(color[1]), num(color[2]), num(x), num(y), literal(s))
+}
+
+// literal returns s as a PDF string in WinAnsi encoding.
+func literal(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, c := range encode(s) {
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// num formats f to the thousandth of a point, or of a color component.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1000)/1000, 'f', -1, 64)
+}
+
+// winAnsi maps the characters of WinAnsi outside Latin-1 to their code.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// replacements spell the characters travel text often has that WinAnsi lacks.
+var replacements = map[rune]string{
+	'→': "->", '←': "<-", '↔': "<->", '✈': "", '≈': "~", '−': "-", '‑': "-", ' ': " ",
+	'\t': " ",
+}
+
+// encode returns s in WinAnsi, with characters it can't encode as "?".
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if rep, ok := replacements[r]; ok {
+			out = append(out, rep...)
+			continue
+		}
+		switch c, ok := winAnsi[r]; {
+		case ok:
+			out = append(out, c)
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		case r < 0x20:
+			// Control characters are dropped
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocument_Bytes(t *testing.T) {
+	now := time.Date(2025, 10, 1, 9, 30, 0, 0, time.UTC)
+	d := New("Weekend in Lisbon", Branding{Name: "Nordic Trails", Color: "#1d4e89", Footer: "help@nordic.example"}, now)
+	d.Heading("Weekend in Lisbon")
+	d.Entry("10:05", "Flight TP1039 BCN → LIS", "Barcelona El Prat (BCN)", "Booking (ABC123)")
+	out := d.Bytes()
+
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF file: %q...", out[:20])
+	}
+
+	// The cross-reference table points at every object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n0 8\n")) {
+		t.Fatalf("startxref points at %q", out[xref:xref+10])
+	}
+	entries := strings.Split(string(out[xref:]), "\n")[3:10]
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(e[:10])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(out[offset:], []byte(want)) {
+			t.Errorf("object %d at %d starts with %q", i+1, offset, out[offset:offset+10])
+		}
+	}
+
+	if !bytes.Contains(out, []byte("/Title (Weekend in Lisbon) /Author (Nordic Trails)")) {
+		t.Error("document information is missing")
+	}
+	if !bytes.Contains(out, []byte("/CreationDate (D:20251001093000Z)")) {
+		t.Error("creation date is missing")
+	}
+
+	content := pageContent(t, out)
+	for _, want := range []string{
+		"0.114 0.306 0.537 rg 0 805.89 595.28 36 re f", // the band in the brand's color
+		"(Nordic Trails) Tj",
+		"(Flight TP1039 BCN -> LIS) Tj",
+		"(Booking \\(ABC123\\)) Tj",
+		"(help@nordic.example) Tj",
+		"(Page 1 of 1) Tj",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("page content misses %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestDocument_PageBreaks(t *testing.T) {
+	d := New("Long trip", Branding{}, time.Now())
+	for i := range 80 {
+		d.Entry(fmt.Sprintf("%02d:00", i%24), fmt.Sprintf("Activity %d", i), "Somewhere nice")
+	}
+	if d.Pages() < 2 {
+		t.Fatalf("expected the entries to overflow a page, got %d pages", d.Pages())
+	}
+
+	out := d.Bytes()
+	if !bytes.Contains(out, []byte(fmt.Sprintf("/Count %d", d.Pages()))) {
+		t.Errorf("page tree doesn't count %d pages", d.Pages())
+	}
+	if !strings.Contains(pageContent(t, out), fmt.Sprintf("(Page 1 of %d) Tj", d.Pages())) {
+		t.Error("footer doesn't number the pages")
+	}
+	if !strings.Contains(pageContent(t, out), "(Acai Travel) Tj") {
+		t.Error("documents without branding are not branded with the default")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	lines := wrap(Regular, 10, "Check in at the Hotel Alfama, then walk to the Miradouro da Senhora do Monte\n\nfor sunset", 150)
+	for _, l := range lines {
+		if width(Regular, 10, l) > 150 {
+			t.Errorf("line %q overflows: %.1f points", l, width(Regular, 10, l))
+		}
+	}
+	if len(lines) < 4 || lines[len(lines)-2] != "" || lines[len(lines)-1] != "for sunset" {
+		t.Errorf("line breaks are not kept: %q", lines)
+	}
+
+	long := wrap(Bold, 10, strings.Repeat("x", 100), 100)
+	if len(long) < 2 || strings.Join(long, "") != strings.Repeat("x", 100) {
+		t.Errorf("long words are not broken: %q", long)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	got := string(encode("Café → Zürich, 12 € – “ok” 東京"))
+	want := "Caf\xe9 -> Z\xfcrich, 12 \x80 \x96 \x93ok\x94 ??"
+	if got != want {
+		t.Errorf("encode = %q, want %q", got, want)
+	}
+}
+
+func TestBranding_Validate(t *testing.T) {
+	if err := (Branding{Color: "#1D4E89"}).Validate(); err != nil {
+		t.Errorf("valid color rejected: %v", err)
+	}
+	for _, c := range []string{"1d4e89", "#1d4e8", "#zzzzzz", "blue"} {
+		if err := (Branding{Color: c}).Validate(); err == nil {
+			t.Errorf("invalid color %q accepted", c)
+		}
+	}
+}
+
+// pageContent returns the inflated content streams of a PDF file.
+func pageContent(t *testing.T, out []byte) string {
+	t.Helper()
+	var content strings.Builder
+	streams := regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+	for _, loc := range streams.FindAllSubmatchIndex(out, -1) {
+		n, _ := strconv.Atoi(string(out[loc[2]:loc[3]]))
+		zr, err := zlib.NewReader(bytes.NewReader(out[loc[1] : loc[1]+n]))
+		if err != nil {
+			t.Fatalf("invalid stream: %v", err)
+		}
+		b, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("invalid stream: %v", err)
+		}
+		content.Write(b)
+	}
+	return content.String()
+}
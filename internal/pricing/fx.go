@@ -0,0 +1,175 @@
+package pricing
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ratesTTL is how long exchange rates are cached. The ECB updates its reference rates
+// once a working day, around 16:00 CET.
+const ratesTTL = 6 * time.Hour
+
+var cacheLookups metric.Int64Counter
+
+func init() {
+	var err error
+	cacheLookups, err = otel.Meter("github.com/acai-travel/tech-challenge/internal/pricing").Int64Counter(
+		"cache.lookups",
+		metric.WithDescription("Number of lookups in the exchange rates cache, by cache.name and cache.hit"),
+		metric.WithUnit("{lookup}"),
+	)
+	if err != nil {
+		// If metric creation fails, the counter will be nil and won't record anything
+	}
+}
+
+// Rates gives exchange rates.
+type Rates interface {
+	// Rate returns what one unit of from is worth in to.
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// Convert converts m to currency to, rounded to its minor units.
+func Convert(ctx context.Context, rates Rates, m Money, to string) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	rate, err := rates.Rate(ctx, m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+	return FromFloat(m.Float()*rate, to), nil
+}
+
+// Sum adds up amounts in currency, converting the amounts in other currencies, for the
+// totals of trips whose offers are priced in several.
+func Sum(ctx context.Context, rates Rates, currency string, amounts ...Money) (Money, error) {
+	total := Money{Currency: currency}
+	for _, m := range amounts {
+		converted, err := Convert(ctx, rates, m, currency)
+		if err != nil {
+			return Money{}, err
+		}
+		total.Amount += converted.Amount
+	}
+	return total, nil
+}
+
+// ECBRates gives the euro foreign exchange reference rates of the European Central
+// Bank, which cover some 30 currencies, converting between others through the euro.
+type ECBRates struct {
+	httpClient *http.Client
+	url        string
+	now        func() time.Time
+
+	mu        sync.Mutex
+	perEuro   map[string]float64
+	fetchedAt time.Time
+}
+
+func NewECBRates() *ECBRates {
+	return &ECBRates{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		url:        "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml",
+		now:        time.Now,
+	}
+}
+
+func (r *ECBRates) Rate(ctx context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	perEuro, err := r.rates(ctx)
+	if err != nil {
+		return 0, err
+	}
+	fromRate, ok := perEuro[from]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, ok := perEuro[to]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+	return toRate / fromRate, nil
+}
+
+// rates returns the cached rates per euro, fetching them when stale. Stale rates are
+// used when fetching fails, as they are better than none.
+func (r *ECBRates) rates(ctx context.Context) (map[string]float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fresh := r.perEuro != nil && r.now().Sub(r.fetchedAt) < ratesTTL
+	if cacheLookups != nil {
+		cacheLookups.Add(ctx, 1, metric.WithAttributes(attribute.String("cache.name", "exchange_rates"), attribute.Bool("cache.hit", fresh)))
+	}
+	if fresh {
+		return r.perEuro, nil
+	}
+
+	perEuro, err := r.fetch(ctx)
+	if err != nil {
+		if r.perEuro != nil {
+			return r.perEuro, nil
+		}
+		return nil, fmt.Errorf("fetch exchange rates: %w", err)
+	}
+	r.perEuro, r.fetchedAt = perEuro, r.now()
+	return perEuro, nil
+}
+
+func (r *ECBRates) fetch(ctx context.Context) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("api error: status %d", resp.StatusCode)
+	}
+
+	// <Cube><Cube time="..."><Cube currency="USD" rate="1.0850"/>...</Cube></Cube>
+	var data struct {
+		Cubes []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube>Cube>Cube"`
+	}
+	if err := xml.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	perEuro := map[string]float64{"EUR": 1}
+	for _, c := range data.Cubes {
+		rate, err := strconv.ParseFloat(c.Rate, 64)
+		if err != nil || rate <= 0 {
+			continue
+		}
+		perEuro[c.Currency] = rate
+	}
+	if len(perEuro) == 1 {
+		return nil, fmt.Errorf("no rates in response")
+	}
+	return perEuro, nil
+}
@@ -0,0 +1,156 @@
+// Package pricing parses, converts and formats the prices of travel offers. Amounts are
+// kept in the minor units of their currency, like cents, so that they add up exactly.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// minorUnits are the decimals of the currencies that don't have 2, per ISO 4217.
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Decimals returns the number of decimals of amounts in currency.
+func Decimals(currency string) int {
+	if d, ok := minorUnits[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Money is an amount in the minor units of its currency.
+type Money struct {
+	Amount   int64  `bson:"amount" json:"amount"`
+	Currency string `bson:"currency" json:"currency"`
+}
+
+// Parse parses a decimal amount like "432.20" in currency, rounding it to the minor
+// units of the currency.
+func Parse(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !ValidCurrency(currency) {
+		return Money{}, fmt.Errorf("currency must be an ISO 4217 code like EUR, got %q", currency)
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+	return FromFloat(f, currency), nil
+}
+
+// FromFloat returns amount in currency, rounded to its minor units.
+func FromFloat(amount float64, currency string) Money {
+	return Money{Amount: int64(math.Round(amount * math.Pow10(Decimals(currency)))), Currency: currency}
+}
+
+// Float returns the amount in major units, like euros.
+func (m Money) Float() float64 {
+	return float64(m.Amount) / math.Pow10(Decimals(m.Currency))
+}
+
+// IsZero reports whether m is the zero value, without an amount nor currency.
+func (m Money) IsZero() bool {
+	return m == Money{}
+}
+
+// String formats m like "432.20 EUR", the way prices are shown to the assistant.
+func (m Money) String() string {
+	return strconv.FormatFloat(m.Float(), 'f', Decimals(m.Currency), 64) + " " + m.Currency
+}
+
+// ErrCurrencyMismatch is returned adding amounts of different currencies, which must be
+// converted first.
+var ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+
+// Add returns m plus o, in the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if m.IsZero() {
+		return o, nil
+	}
+	if o.IsZero() {
+		return m, nil
+	}
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m minus o, in the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(Money{Amount: -o.Amount, Currency: o.Currency})
+}
+
+// Price is the price of an offer, with its taxes when known.
+type Price struct {
+	Total Money
+	// Base is the price before taxes, zero when unknown
+	Base Money
+	// Taxes are the taxes and fees of the price, zero when unknown
+	Taxes Money
+}
+
+// ParseOffer parses the price of an Amadeus offer, like the price of a flight offer or
+// the quotation of a transfer: its total and, when given, its base price before taxes.
+// Taxes are what's between them.
+func ParseOffer(total, base, currency string) (Price, error) {
+	t, err := Parse(total, currency)
+	if err != nil {
+		return Price{}, err
+	}
+	p := Price{Total: t}
+	if strings.TrimSpace(base) == "" {
+		return p, nil
+	}
+
+	b, err := Parse(base, currency)
+	if err != nil {
+		return Price{}, fmt.Errorf("base: %w", err)
+	}
+	if b.Amount > t.Amount {
+		return Price{}, fmt.Errorf("base %s exceeds total %s", b, t)
+	}
+	p.Base = b
+	p.Taxes, _ = t.Sub(b)
+	return p, nil
+}
+
+// String formats the price like "432.20 EUR (incl. 132.20 EUR taxes and fees)", or the
+// total alone when there are no taxes or they are unknown.
+func (p Price) String() string {
+	return p.format(p.Total.String())
+}
+
+// Converted formats the price like String, with its total in another currency too, like
+// "65.00 GBP ≈ 75.40 EUR".
+func (p Price) Converted(total Money) string {
+	return p.format(p.Total.String() + " ≈ " + total.String())
+}
+
+func (p Price) format(total string) string {
+	if p.Taxes.Amount == 0 {
+		return total
+	}
+	return fmt.Sprintf("%s (incl. %s taxes and fees)", total, p.Taxes)
+}
@@ -0,0 +1,153 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount, currency string
+		want             Money
+	}{
+		{"432.20", "EUR", Money{43220, "EUR"}},
+		{"0.1", "usd", Money{10, "USD"}},
+		{"12500", "JPY", Money{12500, "JPY"}},
+		{"1.2345", "KWD", Money{1235, "KWD"}},
+		{"19.999", "GBP", Money{2000, "GBP"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.amount, tt.currency)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q, %q) = %v, %v, want %v", tt.amount, tt.currency, got, err, tt.want)
+		}
+	}
+
+	for _, in := range [][2]string{{"abc", "EUR"}, {"10", "EURO"}, {"NaN", "EUR"}, {"10", ""}} {
+		if _, err := Parse(in[0], in[1]); err == nil {
+			t.Errorf("Parse(%q, %q) succeeded", in[0], in[1])
+		}
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	for m, want := range map[Money]string{
+		{43220, "EUR"}: "432.20 EUR",
+		{12500, "JPY"}: "12500 JPY",
+		{1235, "KWD"}:  "1.235 KWD",
+		{-550, "USD"}:  "-5.50 USD",
+	} {
+		if got := m.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestMoney_Add(t *testing.T) {
+	sum, err := Money{1050, "EUR"}.Add(Money{250, "EUR"})
+	if err != nil || sum != (Money{1300, "EUR"}) {
+		t.Errorf("Add() = %v, %v", sum, err)
+	}
+	if sum, _ := (Money{}).Add(Money{250, "EUR"}); sum != (Money{250, "EUR"}) {
+		t.Errorf("Add() to zero = %v", sum)
+	}
+	if _, err := (Money{1050, "EUR"}).Add(Money{250, "USD"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() across currencies = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestParseOffer(t *testing.T) {
+	p, err := ParseOffer("432.20", "300.00", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Taxes != (Money{13220, "EUR"}) || p.String() != "432.20 EUR (incl. 132.20 EUR taxes and fees)" {
+		t.Errorf("ParseOffer() = %+v, %q", p, p)
+	}
+	if got := p.Converted(Money{50010, "USD"}); got != "432.20 EUR ≈ 500.10 USD (incl. 132.20 EUR taxes and fees)" {
+		t.Errorf("Converted() = %q", got)
+	}
+
+	p, err = ParseOffer("65.00", "", "EUR")
+	if err != nil || p.String() != "65.00 EUR" {
+		t.Errorf("ParseOffer() without base = %q, %v", p, err)
+	}
+
+	if _, err := ParseOffer("65.00", "70.00", "EUR"); err == nil {
+		t.Error("ParseOffer() with a base over the total succeeded")
+	}
+}
+
+// fixedRates gives rates from a table, per unit of the first currency.
+type fixedRates map[[2]string]float64
+
+func (r fixedRates) Rate(_ context.Context, from, to string) (float64, error) {
+	if rate, ok := r[[2]string{from, to}]; ok {
+		return rate, nil
+	}
+	return 0, fmt.Errorf("no rate from %s to %s", from, to)
+}
+
+func TestSum(t *testing.T) {
+	rates := fixedRates{{"USD", "EUR"}: 0.9, {"JPY", "EUR"}: 0.0062}
+	total, err := Sum(context.Background(), rates, "EUR", Money{10000, "EUR"}, Money{5000, "USD"}, Money{10000, "JPY"})
+	if err != nil || total != (Money{20700, "EUR"}) {
+		t.Errorf("Sum() = %v, %v, want 207.00 EUR", total, err)
+	}
+
+	if _, err := Sum(context.Background(), rates, "EUR", Money{100, "GBP"}); err == nil {
+		t.Error("Sum() without a rate succeeded")
+	}
+}
+
+func TestECBRates(t *testing.T) {
+	fetches := 0
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2025-01-15">
+			<Cube currency="USD" rate="1.0300"/>
+			<Cube currency="GBP" rate="0.8400"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 1, 15, 17, 0, 0, 0, time.UTC)
+	r := NewECBRates()
+	r.httpClient, r.url = srv.Client(), srv.URL
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if rate, err := r.Rate(ctx, "EUR", "USD"); err != nil || rate != 1.03 {
+		t.Errorf("Rate(EUR, USD) = %v, %v", rate, err)
+	}
+	if rate, err := r.Rate(ctx, "GBP", "USD"); err != nil || fmt.Sprintf("%.4f", rate) != "1.2262" {
+		t.Errorf("Rate(GBP, USD) = %v, %v, want it through the euro", rate, err)
+	}
+	if _, err := r.Rate(ctx, "EUR", "XYZ"); err == nil {
+		t.Error("Rate() of an unknown currency succeeded")
+	}
+	if fetches != 1 {
+		t.Errorf("fetched %d times, want the rates cached", fetches)
+	}
+
+	// Stale rates are used while fetching fails
+	now, fail = now.Add(ratesTTL+time.Minute), true
+	if rate, err := r.Rate(ctx, "EUR", "USD"); err != nil || rate != 1.03 || fetches != 2 {
+		t.Errorf("Rate() once stale = %v, %v after %d fetches, want the stale rate", rate, err, fetches)
+	}
+}
@@ -0,0 +1,30 @@
+package profile
+
+import (
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Profile holds per-user settings, keyed by the user ID.
+type Profile struct {
+	UserID          string `bson:"_id"`
+	Email           string `bson:"email,omitempty"`
+	SlackWebhookURL string `bson:"slack_webhook_url,omitempty"`
+	// WhatsAppNumber is in international format, e.g. "+34600123456"
+	WhatsAppNumber string    `bson:"whatsapp_number,omitempty"`
+	Channels       []string  `bson:"channels"`
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+func (p *Profile) Proto() *pb.Profile {
+	return &pb.Profile{
+		UserId:          p.UserID,
+		Email:           p.Email,
+		SlackWebhookUrl: p.SlackWebhookURL,
+		WhatsappNumber:  p.WhatsAppNumber,
+		Channels:        p.Channels,
+		UpdatedAt:       timestamppb.New(p.UpdatedAt),
+	}
+}
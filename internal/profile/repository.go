@@ -0,0 +1,67 @@
+package profile
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName        = "github.com/acai-travel/tech-challenge/internal/profile"
+	profileCollection = "profiles"
+)
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+// DescribeProfile returns the user's profile. Users without a stored profile get an empty one.
+func (r *Repository) DescribeProfile(ctx context.Context, userID string) (*Profile, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeProfile")
+	span.SetAttributes(attribute.String("user.id", userID))
+	defer span.End()
+
+	var p Profile
+	err := r.conn.Collection(profileCollection).FindOne(ctx, bson.M{"_id": userID}).Decode(&p)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "profile not stored")
+		return &Profile{UserID: userID}, nil
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database error")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "profile found")
+	return &p, nil
+}
+
+// UpdateProfile stores the profile, creating it if needed.
+func (r *Repository) UpdateProfile(ctx context.Context, p *Profile) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.UpdateProfile")
+	span.SetAttributes(attribute.String("user.id", p.UserID))
+	defer span.End()
+
+	_, err := r.conn.Collection(profileCollection).ReplaceOne(ctx,
+		bson.M{"_id": p.UserID}, p, options.Replace().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update profile")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "profile updated")
+	return nil
+}
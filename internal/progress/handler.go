@@ -0,0 +1,70 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+)
+
+// keepAlive is how often an idle stream gets a comment, so proxies don't close it.
+const keepAlive = 15 * time.Second
+
+// Handler streams the events of a reply as server-sent events at <prefix>/<attempt_id>,
+// for the user of the request sent with that attempt ID. The stream replays the events
+// so far and ends after the reply is done or failed.
+func Handler(hub *Hub) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		attemptID := path.Base(r.URL.Path)
+		if attemptID == "" || attemptID == "/" || attemptID == "." {
+			http.NotFound(w, r)
+			return
+		}
+
+		past, events, cancel := hub.Subscribe(auth.UserID(r.Context()), attemptID)
+		defer cancel()
+
+		rc := http.NewResponseController(w)
+		// Streams outlive the server's write timeout, and end with the reply or the client
+		_ = rc.SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+
+		for _, e := range past {
+			writeEvent(w, e)
+		}
+		_ = rc.Flush()
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				writeEvent(w, e)
+			case <-ticker.C:
+				_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+			case <-r.Context().Done():
+				return
+			}
+			_ = rc.Flush()
+		}
+	})
+}
+
+func writeEvent(w http.ResponseWriter, e Event) {
+	data, _ := json.Marshal(e)
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+}
@@ -0,0 +1,138 @@
+// Package progress relays the steps of replies being generated, like "Searching
+// flights…", to clients following them while they wait.
+package progress
+
+import (
+	"sync"
+	"time"
+)
+
+// Types of events.
+const (
+	// EventStep is a step of the reply, like a tool call.
+	EventStep = "step"
+	// EventDone ends the events of a reply that is ready.
+	EventDone = "done"
+	// EventFailed ends the events of a reply that failed.
+	EventFailed = "failed"
+)
+
+// retain is how long the events of a finished reply are kept for late subscribers.
+const retain = time.Minute
+
+// subscriberBuffer is how many events a slow subscriber can fall behind before missing
+// some.
+const subscriberBuffer = 16
+
+// Event is a step of a reply being generated.
+type Event struct {
+	Type    string    `json:"type"`
+	Tool    string    `json:"tool,omitempty"`
+	Message string    `json:"message,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// Final reports whether e ends the events of its reply.
+func (e Event) Final() bool {
+	return e.Type == EventDone || e.Type == EventFailed
+}
+
+// key identifies a reply by the user and the client-generated ID of its request.
+type key struct {
+	userID    string
+	attemptID string
+}
+
+type attempt struct {
+	events []Event
+	subs   map[chan Event]struct{}
+}
+
+// Hub relays the events of replies to their subscribers, in memory. Replies are
+// identified by the attempt ID of their request, which clients know before sending it,
+// scoped to their user.
+type Hub struct {
+	mu       sync.Mutex
+	attempts map[key]*attempt
+	retain   time.Duration
+}
+
+func NewHub() *Hub {
+	return &Hub{attempts: make(map[key]*attempt), retain: retain}
+}
+
+// Publish sends e to the subscribers of the reply. Subscribers too slow to keep up miss
+// events rather than holding the reply back.
+func (h *Hub) Publish(userID, attemptID string, e Event) {
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	k := key{userID, attemptID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	a := h.attempt(k)
+	a.events = append(a.events, e)
+	for sub := range a.subs {
+		select {
+		case sub <- e:
+		default:
+		}
+		if e.Final() {
+			close(sub)
+			delete(a.subs, sub)
+		}
+	}
+
+	if e.Final() {
+		time.AfterFunc(h.retain, func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if h.attempts[k] == a {
+				delete(h.attempts, k)
+			}
+		})
+	}
+}
+
+// Subscribe follows a reply, which may not have started yet. It returns the events
+// published so far and a channel of the next ones, closed after the final event.
+// Cancel stops following the reply.
+func (h *Hub) Subscribe(userID, attemptID string) (past []Event, events <-chan Event, cancel func()) {
+	k := key{userID, attemptID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	a := h.attempt(k)
+	past = append(past, a.events...)
+	sub := make(chan Event, subscriberBuffer)
+	if len(past) > 0 && past[len(past)-1].Final() {
+		close(sub)
+		return past, sub, func() {}
+	}
+	a.subs[sub] = struct{}{}
+
+	return past, sub, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := a.subs[sub]; !ok {
+			return
+		}
+		delete(a.subs, sub)
+		// Nothing to keep for a reply that never started
+		if len(a.subs) == 0 && len(a.events) == 0 && h.attempts[k] == a {
+			delete(h.attempts, k)
+		}
+	}
+}
+
+func (h *Hub) attempt(k key) *attempt {
+	a, ok := h.attempts[k]
+	if !ok {
+		a = &attempt{subs: make(map[chan Event]struct{})}
+		h.attempts[k] = a
+	}
+	return a
+}
@@ -0,0 +1,107 @@
+package progress
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	hub.Publish("u1", "a1", Event{Type: EventStep, Tool: "get_weather", Message: "Checking the weather…"})
+
+	// Late subscribers get the steps so far, then the next ones
+	past, events, cancel := hub.Subscribe("u1", "a1")
+	defer cancel()
+	if len(past) != 1 || past[0].Tool != "get_weather" {
+		t.Fatalf("past events = %+v, want the weather step", past)
+	}
+
+	// Other users' replies are not followed, even with the same attempt ID
+	other, otherEvents, cancelOther := hub.Subscribe("u2", "a1")
+	defer cancelOther()
+	if len(other) != 0 {
+		t.Errorf("past events of another user = %+v, want none", other)
+	}
+
+	hub.Publish("u1", "a1", Event{Type: EventStep, Tool: "get_flight_prices", Message: "Searching flights…"})
+	hub.Publish("u1", "a1", Event{Type: EventDone})
+
+	var got []string
+	for e := range events {
+		got = append(got, e.Type+" "+e.Tool)
+	}
+	if want := []string{"step get_flight_prices", "done "}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+
+	select {
+	case e := <-otherEvents:
+		t.Errorf("another user got %+v", e)
+	default:
+	}
+}
+
+func TestHub_Retain(t *testing.T) {
+	hub := NewHub()
+	hub.retain = 10 * time.Millisecond
+	hub.Publish("u1", "a1", Event{Type: EventFailed})
+
+	past, events, cancel := hub.Subscribe("u1", "a1")
+	cancel()
+	if len(past) != 1 || past[0].Type != EventFailed {
+		t.Errorf("past events = %+v, want the failure", past)
+	}
+	if _, ok := <-events; ok {
+		t.Error("events of a finished reply are open, want them closed")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if len(hub.attempts) != 0 {
+		t.Errorf("hub keeps %d finished replies, want them dropped", len(hub.attempts))
+	}
+}
+
+func TestHandler(t *testing.T) {
+	hub := NewHub()
+	srv := httptest.NewServer(http.StripPrefix("/progress", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Handler(hub).ServeHTTP(w, r.WithContext(auth.WithUserID(r.Context(), r.Header.Get("X-User-ID"))))
+	})))
+	defer srv.Close()
+
+	hub.Publish("u1", "a1", Event{Type: EventStep, Tool: "get_weather", Message: "Checking the weather…"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/progress/a1", nil)
+	req.Header.Set("X-User-ID", "u1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want an event stream", ct)
+	}
+
+	go hub.Publish("u1", "a1", Event{Type: EventDone})
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, name)
+		}
+	}
+	if got := strings.Join(events, ","); got != "step,done" {
+		t.Errorf("events = %q, want the step, then done", got)
+	}
+}
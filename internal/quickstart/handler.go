@@ -0,0 +1,126 @@
+package quickstart
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+)
+
+// maxTemplateSize bounds the JSON body of a template.
+const maxTemplateSize = 64 << 10
+
+// Store lists and manages the stored templates.
+type Store interface {
+	ListTemplates(ctx context.Context) ([]*Template, error)
+	DescribeTemplate(ctx context.Context, id string) (*Template, error)
+	PutTemplate(ctx context.Context, t *Template) error
+	DeleteTemplate(ctx context.Context, id string) error
+}
+
+// Handler serves the template admin API, for admins who authenticate with
+// "Authorization: Bearer <token>". Mounted with its prefix stripped, it serves
+//
+//	GET    /      all templates
+//	GET    /{id}  a template
+//	PUT    /{id}  create or replace a template, from its JSON
+//	DELETE /{id}  delete a template
+//
+// An empty token disables the API.
+func Handler(token string, store Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := r.Context()
+		w.Header().Set("Cache-Control", "private, no-store")
+
+		id := strings.Trim(r.URL.Path, "/")
+		if id == "" {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				w.Header().Set("Allow", "GET, HEAD")
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			templates, err := store.ListTemplates(ctx)
+			if err != nil {
+				fail(ctx, w, "Failed to list templates", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"templates": templates})
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			t, err := store.DescribeTemplate(ctx, id)
+			if err != nil {
+				fail(ctx, w, "Failed to describe template", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, t)
+
+		case http.MethodPut:
+			var t Template
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTemplateSize)).Decode(&t); err != nil {
+				http.Error(w, "Invalid template: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			t.ID = id
+			if err := t.Validate(); err != nil {
+				http.Error(w, "Invalid template: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if err := store.PutTemplate(ctx, &t); err != nil {
+				fail(ctx, w, "Failed to store template", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, &t)
+
+		case http.MethodDelete:
+			if err := store.DeleteTemplate(ctx, id); err != nil {
+				fail(ctx, w, "Failed to delete template", err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// fail answers 404 for templates that don't exist, and 500 otherwise.
+func fail(ctx context.Context, w http.ResponseWriter, msg string, err error) {
+	var terr twirp.Error
+	if errors.As(err, &terr) && terr.Code() == twirp.NotFound {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	slog.ErrorContext(ctx, msg, "error", err)
+	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,111 @@
+// Package quickstart stores the templates conversations can be started from: quick-start
+// flows like a weekend getaway planner, which seed a conversation with instructions for
+// the assistant and its first message.
+package quickstart
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+)
+
+// maxSuggestions bounds the quick replies of a template.
+const maxSuggestions = 5
+
+// idPattern matches template IDs, lowercase slugs like "weekend-getaway".
+var idPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// Template is a quick-start flow. Instructions and Steps guide the assistant through
+// the conversation; Greeting opens it.
+type Template struct {
+	ID          string `bson:"_id" json:"id"`
+	Name        string `bson:"name" json:"name"`
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+	// Instructions tell the assistant what the flow is for and how to run it.
+	Instructions string `bson:"instructions" json:"instructions"`
+	// Steps are what the assistant should find out or cover, in order.
+	Steps       []string  `bson:"steps,omitempty" json:"steps,omitempty"`
+	Greeting    string    `bson:"greeting" json:"greeting"`
+	Suggestions []string  `bson:"suggestions,omitempty" json:"suggestions,omitempty"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Validate checks the template can start conversations.
+func (t *Template) Validate() error {
+	switch {
+	case !idPattern.MatchString(t.ID):
+		return fmt.Errorf("id must be a lowercase slug like weekend-getaway")
+	case strings.TrimSpace(t.Name) == "":
+		return fmt.Errorf("name is required")
+	case strings.TrimSpace(t.Instructions) == "":
+		return fmt.Errorf("instructions are required")
+	case strings.TrimSpace(t.Greeting) == "":
+		return fmt.Errorf("greeting is required")
+	case len(t.Suggestions) > maxSuggestions:
+		return fmt.Errorf("at most %d suggestions are allowed", maxSuggestions)
+	}
+	return nil
+}
+
+// SystemContext returns the system message of conversations started from the template.
+func (t *Template) SystemContext() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "This conversation follows the %q flow. %s", t.Name, strings.TrimSpace(t.Instructions))
+	if len(t.Steps) > 0 {
+		b.WriteString("\nCover these steps in order, asking about one or two at a time and skipping what the user already said:")
+		for i, step := range t.Steps {
+			fmt.Fprintf(&b, "\n%d. %s", i+1, step)
+		}
+	}
+	return b.String()
+}
+
+func (t *Template) Proto() *pb.ConversationTemplate {
+	return &pb.ConversationTemplate{
+		Id:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Greeting:    t.Greeting,
+		Suggestions: t.Suggestions,
+	}
+}
+
+// Defaults are the templates stored when there are none yet.
+func Defaults() []*Template {
+	return []*Template{
+		{
+			ID:           "weekend-getaway",
+			Name:         "Weekend getaway planner",
+			Description:  "Plan a short trip: where to go, how to get there and what the weather holds.",
+			Instructions: "Help the user plan a two or three day getaway. Suggest a few destinations fitting their wishes before settling on one, then check flights and the weather there for the dates, and end with a short day-by-day plan.",
+			Steps: []string{
+				"Where they are travelling from and which weekend",
+				"Their budget and what they are in the mood for (city, beach, nature, food)",
+				"Two or three destinations to choose from",
+				"Flights and the weather forecast for the chosen destination",
+				"A day-by-day plan and a packing list",
+			},
+			Greeting:    "Let's plan your weekend getaway! Where would you be leaving from, and which weekend are you thinking of?",
+			Suggestions: []string{"From Barcelona, next weekend", "From London, in two weeks", "I'm flexible on dates"},
+		},
+		{
+			ID:           "business-trip",
+			Name:         "Business trip checklist",
+			Description:  "Get ready for a work trip: flights, transfers, public holidays and what to pack.",
+			Instructions: "Help the user prepare a business trip. Favor convenient flight times and short transfers over the cheapest options, and keep replies brief and practical. End with a checklist of what is sorted and what is left to do.",
+			Steps: []string{
+				"Destination, dates and where they are travelling from",
+				"Flights that fit their meetings",
+				"The transfer from the airport to their hotel or office",
+				"Public holidays at the destination that could affect their plans",
+				"The weather and a packing list for a business trip",
+			},
+			Greeting:    "Let's get your business trip sorted. Where are you headed, and when do you need to be there?",
+			Suggestions: []string{"Paris, Monday morning", "New York next week", "Frankfurt for two days"},
+		},
+	}
+}
@@ -0,0 +1,136 @@
+package quickstart
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/twitchtv/twirp"
+)
+
+func TestDefaults(t *testing.T) {
+	for _, tmpl := range Defaults() {
+		if err := tmpl.Validate(); err != nil {
+			t.Errorf("default template %s: %v", tmpl.ID, err)
+		}
+	}
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	valid := func() *Template {
+		return &Template{ID: "city-break", Name: "City break", Instructions: "Plan a city break.", Greeting: "Which city?"}
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	invalid := map[string]func(*Template){
+		"id":          func(tmpl *Template) { tmpl.ID = "City Break" },
+		"name":        func(tmpl *Template) { tmpl.Name = " " },
+		"greeting":    func(tmpl *Template) { tmpl.Greeting = "" },
+		"suggestions": func(tmpl *Template) { tmpl.Suggestions = make([]string, maxSuggestions+1) },
+	}
+	for field, mod := range invalid {
+		tmpl := valid()
+		mod(tmpl)
+		if err := tmpl.Validate(); err == nil {
+			t.Errorf("Validate() with an invalid %s error = nil, want an error", field)
+		}
+	}
+}
+
+func TestTemplate_SystemContext(t *testing.T) {
+	tmpl := &Template{Name: "City break", Instructions: "Plan a city break.", Steps: []string{"Which city", "Which dates"}}
+	want := "This conversation follows the \"City break\" flow. Plan a city break.\n" +
+		"Cover these steps in order, asking about one or two at a time and skipping what the user already said:\n" +
+		"1. Which city\n2. Which dates"
+	if got := tmpl.SystemContext(); got != want {
+		t.Errorf("SystemContext() = %q, want %q", got, want)
+	}
+}
+
+// memoryStore is an in-memory template store.
+type memoryStore map[string]*Template
+
+func (s memoryStore) ListTemplates(context.Context) ([]*Template, error) {
+	var out []*Template
+	for _, t := range s {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (s memoryStore) DescribeTemplate(_ context.Context, id string) (*Template, error) {
+	if t, ok := s[id]; ok {
+		return t, nil
+	}
+	return nil, twirp.NotFoundError("template not found")
+}
+
+func (s memoryStore) PutTemplate(_ context.Context, t *Template) error {
+	s[t.ID] = t
+	return nil
+}
+
+func (s memoryStore) DeleteTemplate(_ context.Context, id string) error {
+	if _, ok := s[id]; !ok {
+		return twirp.NotFoundError("template not found")
+	}
+	delete(s, id)
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	store := memoryStore{}
+	h := Handler("secret", store)
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodGet, "/", "", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET with a wrong token = %d, want 401", rec.Code)
+	}
+
+	body := `{"name": "City break", "instructions": "Plan a city break.", "greeting": "Which city?"}`
+	if rec := do(http.MethodPut, "/city-break", body, "secret"); rec.Code != http.StatusOK {
+		t.Fatalf("PUT = %d %s, want 200", rec.Code, rec.Body)
+	}
+	if tmpl := store["city-break"]; tmpl == nil || tmpl.Name != "City break" {
+		t.Errorf("stored template = %+v, want the city break", tmpl)
+	}
+
+	if rec := do(http.MethodPut, "/city-break", `{"name": "City break"}`, "secret"); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT without a greeting = %d, want 400", rec.Code)
+	}
+
+	rec := do(http.MethodGet, "/", "", "secret")
+	var list struct {
+		Templates []*Template `json:"templates"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil || len(list.Templates) != 1 {
+		t.Errorf("GET = %d, %v, want the stored template", rec.Code, err)
+	}
+
+	if rec := do(http.MethodDelete, "/city-break", "", "secret"); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d, want 204", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/city-break", "", "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET of a deleted template = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	Handler("", store).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET without an admin token configured = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,165 @@
+package quickstart
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName         = "github.com/acai-travel/tech-challenge/internal/quickstart"
+	templateCollection = "conversation_templates"
+)
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+// Seed stores the given templates when there are none yet, so that templates admins
+// deleted don't come back on the next start.
+func (r *Repository) Seed(ctx context.Context, templates []*Template) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.Seed")
+	defer span.End()
+
+	n, err := r.conn.Collection(templateCollection).CountDocuments(ctx, bson.M{}, options.Count().SetLimit(1))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to count templates")
+		return err
+	}
+	if n > 0 {
+		span.SetStatus(codes.Ok, "templates already stored")
+		return nil
+	}
+
+	now := time.Now()
+	docs := make([]any, 0, len(templates))
+	for _, t := range templates {
+		t.CreatedAt, t.UpdatedAt = now, now
+		docs = append(docs, t)
+	}
+	if _, err := r.conn.Collection(templateCollection).InsertMany(ctx, docs); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to seed templates")
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("templates.count", len(templates)))
+	span.SetStatus(codes.Ok, "templates seeded")
+	return nil
+}
+
+// ListTemplates returns all templates, by name.
+func (r *Repository) ListTemplates(ctx context.Context) ([]*Template, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListTemplates")
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.conn.Collection(templateCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query templates")
+		return nil, err
+	}
+
+	var items []*Template
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode templates")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("templates.count", len(items)))
+	span.SetStatus(codes.Ok, "templates listed")
+	return items, nil
+}
+
+func (r *Repository) DescribeTemplate(ctx context.Context, id string) (*Template, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeTemplate")
+	span.SetAttributes(attribute.String("template.id", id))
+	defer span.End()
+
+	var t Template
+	err := r.conn.Collection(templateCollection).FindOne(ctx, bson.M{"_id": id}).Decode(&t)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Error, "template not found")
+		return nil, twirp.NotFoundError("template not found")
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database error")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "template found")
+	return &t, nil
+}
+
+// PutTemplate creates the template or replaces the one with its ID, keeping when it
+// was created.
+func (r *Repository) PutTemplate(ctx context.Context, t *Template) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.PutTemplate")
+	span.SetAttributes(attribute.String("template.id", t.ID))
+	defer span.End()
+
+	t.UpdatedAt = time.Now()
+	res := r.conn.Collection(templateCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": t.ID},
+		bson.M{
+			"$set": bson.M{
+				"name":         t.Name,
+				"description":  t.Description,
+				"instructions": t.Instructions,
+				"steps":        t.Steps,
+				"greeting":     t.Greeting,
+				"suggestions":  t.Suggestions,
+				"updated_at":   t.UpdatedAt,
+			},
+			"$setOnInsert": bson.M{"created_at": t.UpdatedAt},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+
+	if err := res.Decode(t); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to put template")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "template stored")
+	return nil
+}
+
+func (r *Repository) DeleteTemplate(ctx context.Context, id string) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DeleteTemplate")
+	span.SetAttributes(attribute.String("template.id", id))
+	defer span.End()
+
+	res, err := r.conn.Collection(templateCollection).DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete template")
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		span.SetStatus(codes.Error, "template not found")
+		return twirp.NotFoundError("template not found")
+	}
+
+	span.SetStatus(codes.Ok, "template deleted")
+	return nil
+}
@@ -0,0 +1,170 @@
+// Package recall remembers the past conversations of each user by the embeddings of their
+// closing summaries, for replies to bring up what the user told before, like a preferred
+// seat or airline.
+package recall
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/openai/openai-go/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxMemories bounds the memories of a user searched, the most recent ones.
+	maxMemories = 500
+	// maxRecalled bounds the memories recalled for a reply.
+	maxRecalled = 3
+	// minScore is the cosine similarity under which a memory isn't relevant enough to
+	// recall.
+	minScore = 0.4
+)
+
+// Memory is the closing summary of a past conversation and its embedding.
+type Memory struct {
+	ConversationID primitive.ObjectID `bson:"_id"`
+	UserID         string             `bson:"user_id"`
+	Title          string             `bson:"title,omitempty"`
+	Summary        string             `bson:"summary"`
+	Embedding      []float64          `bson:"embedding"`
+	ClosedAt       time.Time          `bson:"closed_at"`
+}
+
+// Store keeps the memories of every user.
+type Store interface {
+	SaveMemory(ctx context.Context, m *Memory) error
+	ListMemories(ctx context.Context, userID string, limit int) ([]*Memory, error)
+	DeleteMemories(ctx context.Context, userID string, ids []primitive.ObjectID) error
+}
+
+// Embedder turns text into an embedding.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float64, error)
+}
+
+// Index remembers closed conversations and recalls the ones relevant to a message. The
+// memories of a user are searched in process, which is fine for the few hundred
+// conversations a traveller has.
+type Index struct {
+	store    Store
+	embedder Embedder
+	now      func() time.Time
+}
+
+func NewIndex(store Store, embedder Embedder) *Index {
+	return &Index{store: store, embedder: embedder, now: time.Now}
+}
+
+// Remember stores the closing summary of conv, replacing what was remembered of it.
+func (i *Index) Remember(ctx context.Context, conv *model.Conversation, summary string) error {
+	if conv.UserID == "" || summary == "" {
+		return nil
+	}
+
+	embedding, err := i.embedder.Embed(ctx, summary)
+	if err != nil {
+		return err
+	}
+	return i.store.SaveMemory(ctx, &Memory{
+		ConversationID: conv.ID,
+		UserID:         conv.UserID,
+		Title:          conv.Title,
+		Summary:        summary,
+		Embedding:      embedding,
+		ClosedAt:       i.now(),
+	})
+}
+
+// Recall returns the memories of the user of conv most relevant to query, best first,
+// leaving out conv itself.
+func (i *Index) Recall(ctx context.Context, conv *model.Conversation, query string) ([]*Memory, error) {
+	if conv.UserID == "" || query == "" {
+		return nil, nil
+	}
+
+	memories, err := i.store.ListMemories(ctx, conv.UserID, maxMemories)
+	if err != nil || len(memories) == 0 {
+		return nil, err
+	}
+
+	embedding, err := i.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return nearest(memories, embedding, conv.ID), nil
+}
+
+// Forget removes the memories of the given conversations of a user.
+func (i *Index) Forget(ctx context.Context, userID string, ids []primitive.ObjectID) error {
+	return i.store.DeleteMemories(ctx, userID, ids)
+}
+
+// nearest returns the memories closest to embedding above minScore, best first.
+func nearest(memories []*Memory, embedding []float64, exclude primitive.ObjectID) []*Memory {
+	type scored struct {
+		memory *Memory
+		score  float64
+	}
+	var candidates []scored
+	for _, m := range memories {
+		if m.ConversationID == exclude {
+			continue
+		}
+		if score := cosine(m.Embedding, embedding); score >= minScore {
+			candidates = append(candidates, scored{m, score})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b scored) int { return cmp.Compare(b.score, a.score) })
+
+	var recalled []*Memory
+	for _, c := range candidates[:min(len(candidates), maxRecalled)] {
+		recalled = append(recalled, c.memory)
+	}
+	return recalled
+}
+
+// cosine returns the cosine similarity of two embeddings, 0 when they can't be compared.
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// OpenAIEmbedder embeds text with OpenAI's small embedding model.
+type OpenAIEmbedder struct {
+	cli openai.Client
+}
+
+func NewOpenAIEmbedder(cli openai.Client) *OpenAIEmbedder {
+	return &OpenAIEmbedder{cli: cli}
+}
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	resp, err := e.cli.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Model: openai.EmbeddingModelTextEmbedding3Small,
+		Input: openai.EmbeddingNewParamsInputUnion{OfString: openai.String(text)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, errors.New("empty embedding")
+	}
+	return resp.Data[0].Embedding, nil
+}
@@ -0,0 +1,115 @@
+package recall
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore is an in-memory Store.
+type memoryStore struct {
+	memories []*Memory
+}
+
+func (s *memoryStore) SaveMemory(ctx context.Context, m *Memory) error {
+	s.memories = slices.DeleteFunc(s.memories, func(o *Memory) bool { return o.ConversationID == m.ConversationID })
+	s.memories = append(s.memories, m)
+	return nil
+}
+
+func (s *memoryStore) ListMemories(ctx context.Context, userID string, limit int) ([]*Memory, error) {
+	var memories []*Memory
+	for _, m := range s.memories {
+		if m.UserID == userID && len(memories) < limit {
+			memories = append(memories, m)
+		}
+	}
+	return memories, nil
+}
+
+func (s *memoryStore) DeleteMemories(ctx context.Context, userID string, ids []primitive.ObjectID) error {
+	s.memories = slices.DeleteFunc(s.memories, func(m *Memory) bool {
+		return m.UserID == userID && slices.Contains(ids, m.ConversationID)
+	})
+	return nil
+}
+
+// topicEmbedder embeds text by the topics it mentions, one dimension each.
+type topicEmbedder []string
+
+func (e topicEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	embedding := make([]float64, len(e))
+	for i, topic := range e {
+		if strings.Contains(text, topic) {
+			embedding[i] = 1
+		}
+	}
+	return embedding, nil
+}
+
+func TestIndex(t *testing.T) {
+	store := &memoryStore{}
+	index := NewIndex(store, topicEmbedder{"flight", "seat", "hotel", "Lisbon", "Tokyo"})
+	index.now = func() time.Time { return time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	conv := func(userID string) *model.Conversation {
+		return &model.Conversation{ID: primitive.NewObjectID(), UserID: userID}
+	}
+	seats, hotel, other, current := conv("user-1"), conv("user-1"), conv("user-2"), conv("user-1")
+
+	for c, summary := range map[*model.Conversation]string{
+		seats: "You booked a flight to Tokyo and prefer an aisle seat.",
+		hotel: "You looked for a hotel in Lisbon.",
+		other: "You booked a flight to Tokyo.",
+	} {
+		if err := index.Remember(ctx, c, summary); err != nil {
+			t.Fatalf("Remember() error = %v", err)
+		}
+	}
+	if err := index.Remember(ctx, current, ""); err != nil || len(store.memories) != 3 {
+		t.Fatalf("Remember() without a summary stored it, error = %v", err)
+	}
+
+	recalled, err := index.Recall(ctx, current, "Which seat should I take on my flight?")
+	if err != nil {
+		t.Fatalf("Recall() error = %v", err)
+	}
+	if len(recalled) != 1 || recalled[0].ConversationID != seats.ID {
+		t.Errorf("Recall() = %+v, want the user's conversation about seats only", recalled)
+	}
+
+	// A conversation doesn't recall itself
+	if recalled, _ := index.Recall(ctx, seats, "Which seat should I take on my flight?"); len(recalled) != 0 {
+		t.Errorf("Recall() from the remembered conversation = %+v, want nothing", recalled)
+	}
+
+	if err := index.Forget(ctx, "user-1", []primitive.ObjectID{seats.ID, other.ID}); err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	if len(store.memories) != 2 {
+		t.Errorf("memories after Forget() = %d, want the other user's kept", len(store.memories))
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		a, b []float64
+		want float64
+	}{
+		{[]float64{1, 0}, []float64{2, 0}, 1},
+		{[]float64{1, 0}, []float64{0, 1}, 0},
+		{[]float64{1, 0}, []float64{1, 0, 0}, 0},
+		{[]float64{0, 0}, []float64{1, 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := cosine(tt.a, tt.b); got != tt.want {
+			t.Errorf("cosine(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package recall
+
+import (
+	"context"
+
+	"github.com/acai-travel/tech-challenge/internal/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName       = "github.com/acai-travel/tech-challenge/internal/recall"
+	memoryCollection = "conversation_memories"
+)
+
+type Repository struct {
+	conn *mongo.Database
+	// replica serves the searches of memories, which tolerate stale data
+	replica *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn, replica: mongox.Replica(conn)}
+}
+
+// EnsureIndexes creates the index listing the memories of a user, most recent first.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.conn.Collection(memoryCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "closed_at", Value: -1}},
+	})
+	return err
+}
+
+// SaveMemory stores m, replacing the memory of the same conversation.
+func (r *Repository) SaveMemory(ctx context.Context, m *Memory) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SaveMemory")
+	span.SetAttributes(attribute.String("conversation.id", m.ConversationID.Hex()))
+	defer span.End()
+
+	_, err := r.conn.Collection(memoryCollection).ReplaceOne(ctx,
+		bson.M{"_id": m.ConversationID}, m, options.Replace().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to save memory")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "memory saved")
+	return nil
+}
+
+// ListMemories returns the limit most recent memories of a user.
+func (r *Repository) ListMemories(ctx context.Context, userID string, limit int) ([]*Memory, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListMemories")
+	defer span.End()
+
+	cursor, err := r.replica.Collection(memoryCollection).Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "closed_at", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database error")
+		return nil, err
+	}
+
+	var memories []*Memory
+	if err := cursor.All(ctx, &memories); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode memories")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("memory.count", len(memories)))
+	span.SetStatus(codes.Ok, "memories listed")
+	return memories, nil
+}
+
+// DeleteMemories removes the memories of the given conversations of a user.
+func (r *Repository) DeleteMemories(ctx context.Context, userID string, ids []primitive.ObjectID) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DeleteMemories")
+	span.SetAttributes(attribute.Int("conversation.count", len(ids)))
+	defer span.End()
+
+	_, err := r.conn.Collection(memoryCollection).DeleteMany(ctx, bson.M{
+		"_id":     bson.M{"$in": ids},
+		"user_id": userID,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete memories")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "memories deleted")
+	return nil
+}
@@ -0,0 +1,46 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+)
+
+// ReplyTimeout bounds the time a message is answered in, tool calls included.
+const ReplyTimeout = 3 * time.Minute
+
+// Chat is the part of the chat service the messages of threads are answered with.
+type Chat interface {
+	StartConversation(ctx context.Context, req *pb.StartConversationRequest) (*pb.StartConversationResponse, error)
+	ContinueConversation(ctx context.Context, req *pb.ContinueConversationRequest) (*pb.ContinueConversationResponse, error)
+}
+
+// Reply is the reply to a message, of a conversation started or continued.
+type Reply interface {
+	GetReply() string
+	GetSuggestions() []string
+	GetCitations() []*pb.Citation
+}
+
+// Send continues the conversation of a thread with text, or starts one if the thread
+// has none yet. It returns the conversation of the thread, with the reply.
+func Send(ctx context.Context, chat Chat, conversationID, text, attemptID string) (string, Reply, error) {
+	if conversationID != "" {
+		out, err := chat.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: conversationID, Message: text, AttemptId: attemptID})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.NotFound {
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to continue conversation: %w", err)
+			}
+			return conversationID, out, nil
+		}
+		// The conversation was deleted, so the thread starts over
+	}
+	out, err := chat.StartConversation(ctx, &pb.StartConversationRequest{Message: text, AttemptId: attemptID})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start conversation: %w", err)
+	}
+	return out.GetConversationId(), out, nil
+}
@@ -0,0 +1,33 @@
+package relay
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestSplit(t *testing.T) {
+	text := strings.Repeat("Day one in Lisbon. ", 300) + "\n" + strings.Repeat("🌞", 10)
+	parts := Split(text, 4096, utf16.RuneLen)
+	if len(parts) != 2 {
+		t.Fatalf("split into %d parts", len(parts))
+	}
+	for _, p := range parts {
+		if n := len(utf16.Encode([]rune(p))); n > 4096 {
+			t.Errorf("part of %d UTF-16 code units", n)
+		}
+	}
+	if strings.Join(strings.Fields(strings.Join(parts, " ")), " ") != strings.Join(strings.Fields(text), " ") {
+		t.Errorf("split mid-word: %q", parts[0][len(parts[0])-20:])
+	}
+
+	if got := Split(strings.Repeat("🌞", 5), 5, Chars); len(got) != 1 {
+		t.Errorf("Split(5 runes, 5, Chars) = %q", got)
+	}
+	if got := Split(strings.Repeat("🌞", 5), 5, utf16.RuneLen); len(got) != 3 {
+		t.Errorf("Split(5 runes, 5, utf16.RuneLen) = %q", got)
+	}
+	if got := Split("", 10, Chars); len(got) != 0 {
+		t.Errorf("Split(\"\") = %q", got)
+	}
+}
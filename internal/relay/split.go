@@ -0,0 +1,35 @@
+// Package relay relays the messages of messaging apps to conversations of the chat
+// service.
+package relay
+
+import "strings"
+
+// Chars counts every rune as one character, as WhatsApp and Slack count them.
+func Chars(rune) int { return 1 }
+
+// Split splits text into parts of at most max characters, at line breaks or spaces
+// where possible. length counts the characters of a rune, as the messaging app does.
+func Split(text string, max int, length func(rune) int) []string {
+	var parts []string
+	for text != "" {
+		n, end := 0, len(text)
+		for i, r := range text {
+			if n += length(r); n > max {
+				end = i
+				break
+			}
+		}
+		if end < len(text) {
+			if i := strings.LastIndex(text[:end], "\n"); i > end/2 {
+				end = i
+			} else if i := strings.LastIndex(text[:end], " "); i > end/2 {
+				end = i
+			}
+		}
+		if part := strings.TrimSpace(text[:end]); part != "" {
+			parts = append(parts, part)
+		}
+		text = text[end:]
+	}
+	return parts
+}
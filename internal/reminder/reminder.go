@@ -0,0 +1,69 @@
+package reminder
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Status is the delivery state of a reminder.
+type Status string
+
+const (
+	StatusScheduled Status = "scheduled"
+	StatusSending   Status = "sending"
+	StatusSent      Status = "sent"
+	StatusFailed    Status = "failed"
+)
+
+// Reminder is a message to deliver to a user at a given time.
+type Reminder struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	UserID         string             `bson:"user_id"`
+	ConversationID primitive.ObjectID `bson:"conversation_id"`
+	Message        string             `bson:"message"`
+	DueAt          time.Time          `bson:"due_at"`
+	Timezone       string             `bson:"timezone"`
+	Status         Status             `bson:"status"`
+	Attempts       int                `bson:"attempts"`
+	LastError      string             `bson:"last_error,omitempty"`
+	ClaimedAt      time.Time          `bson:"claimed_at,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
+
+// localLayouts are the accepted formats for due times without a UTC offset.
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// ParseDueAt parses a due time. Times with an explicit offset (RFC3339) are used as-is;
+// local times are interpreted in the given IANA timezone, or UTC if tz is empty.
+func ParseDueAt(value, tz string) (time.Time, *time.Location, error) {
+	loc := time.UTC
+	if tz = strings.TrimSpace(tz); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("unknown timezone %q", tz)
+		}
+		loc = l
+	}
+
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), loc, nil
+	}
+
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, loc, nil
+		}
+	}
+
+	return time.Time{}, nil, fmt.Errorf("invalid due time %q, expected YYYY-MM-DDTHH:MM in the given timezone", value)
+}
@@ -0,0 +1,133 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/notify"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseDueAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		tz      string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "local time in timezone",
+			value: "2025-10-18T09:00",
+			tz:    "Europe/Lisbon",
+			want:  time.Date(2025, 10, 18, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "local time defaults to UTC",
+			value: "2025-10-18 09:00",
+			want:  time.Date(2025, 10, 18, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "explicit offset wins over timezone",
+			value: "2025-10-18T09:00:00+02:00",
+			tz:    "America/New_York",
+			want:  time.Date(2025, 10, 18, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "unknown timezone",
+			value:   "2025-10-18T09:00",
+			tz:      "Mars/Olympus",
+			wantErr: true,
+		},
+		{
+			name:    "unparseable time",
+			value:   "next tuesday",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := ParseDueAt(tt.value, tt.tz)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDueAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseDueAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// memoryStore is an in-memory Store handing out reminders in order.
+type memoryStore struct {
+	mu      sync.Mutex
+	pending []*Reminder
+	updated []Reminder
+}
+
+func (m *memoryStore) ClaimDue(ctx context.Context, now time.Time) (*Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, r := range m.pending {
+		if !r.DueAt.After(now) {
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			r.Status = StatusSending
+			r.Attempts++
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryStore) UpdateReminder(ctx context.Context, rem *Reminder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updated = append(m.updated, *rem)
+	return nil
+}
+
+type publisherFunc func(ctx context.Context, evt notify.Event) error
+
+func (f publisherFunc) Publish(ctx context.Context, evt notify.Event) error { return f(ctx, evt) }
+
+func TestWorker_Tick(t *testing.T) {
+	now := time.Date(2025, 10, 18, 9, 0, 0, 0, time.UTC)
+	due := &Reminder{ID: primitive.NewObjectID(), UserID: "user-1", Message: "Check in", DueAt: now.Add(-time.Minute)}
+	later := &Reminder{ID: primitive.NewObjectID(), UserID: "user-1", Message: "Pack", DueAt: now.Add(time.Hour)}
+	failing := &Reminder{ID: primitive.NewObjectID(), UserID: "user-2", Message: "Taxi", DueAt: now, Attempts: 2}
+
+	store := &memoryStore{pending: []*Reminder{due, later, failing}}
+
+	var events []notify.Event
+	w := NewWorker(store, publisherFunc(func(ctx context.Context, evt notify.Event) error {
+		if evt.UserID == "user-2" {
+			return errors.New("smtp down")
+		}
+		events = append(events, evt)
+		return nil
+	}))
+	w.now = func() time.Time { return now }
+
+	w.Tick(context.Background())
+
+	if len(events) != 1 || events[0].Type != notify.EventReminder || events[0].Text() != "Check in" {
+		t.Fatalf("events = %+v, want a single reminder event for 'Check in'", events)
+	}
+
+	if len(store.updated) != 2 {
+		t.Fatalf("updated %d reminders, want 2", len(store.updated))
+	}
+	if got := store.updated[0].Status; got != StatusSent {
+		t.Errorf("due reminder status = %q, want %q", got, StatusSent)
+	}
+	if got := store.updated[1].Status; got != StatusFailed {
+		t.Errorf("failing reminder status = %q, want %q", got, StatusFailed)
+	}
+	if len(store.pending) != 1 || store.pending[0] != later {
+		t.Errorf("future reminder should stay pending")
+	}
+}
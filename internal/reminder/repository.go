@@ -0,0 +1,133 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName         = "github.com/acai-travel/tech-challenge/internal/reminder"
+	reminderCollection = "reminders"
+
+	// claimTimeout is how long a claimed reminder may stay in sending state before
+	// another worker is allowed to pick it up again.
+	claimTimeout = 5 * time.Minute
+)
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) CreateReminder(ctx context.Context, rem *Reminder) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.CreateReminder")
+	span.SetAttributes(attribute.String("reminder.id", rem.ID.Hex()))
+	defer span.End()
+
+	if _, err := r.conn.Collection(reminderCollection).InsertOne(ctx, rem); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create reminder")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "reminder created")
+	return nil
+}
+
+// ClaimDue atomically marks the oldest due reminder as sending and returns it.
+// It returns nil when no reminder is due.
+func (r *Repository) ClaimDue(ctx context.Context, now time.Time) (*Reminder, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ClaimDue")
+	defer span.End()
+
+	filter := bson.M{
+		"due_at": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"status": StatusScheduled},
+			bson.M{"status": StatusSending, "claimed_at": bson.M{"$lt": now.Add(-claimTimeout)}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": StatusSending, "claimed_at": now, "updated_at": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "due_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var rem Reminder
+	err := r.conn.Collection(reminderCollection).FindOneAndUpdate(ctx, filter, update, opts).Decode(&rem)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "no reminder due")
+		return nil, nil
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to claim reminder")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("reminder.id", rem.ID.Hex()))
+	span.SetStatus(codes.Ok, "reminder claimed")
+	return &rem, nil
+}
+
+// UpdateReminder stores the reminder's delivery state.
+func (r *Repository) UpdateReminder(ctx context.Context, rem *Reminder) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.UpdateReminder")
+	span.SetAttributes(
+		attribute.String("reminder.id", rem.ID.Hex()),
+		attribute.String("reminder.status", string(rem.Status)),
+	)
+	defer span.End()
+
+	_, err := r.conn.Collection(reminderCollection).UpdateOne(ctx,
+		bson.M{"_id": rem.ID},
+		bson.M{"$set": rem})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update reminder")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "reminder updated")
+	return nil
+}
+
+// ListReminders returns the reminders of a user, sent ones included, by due time.
+func (r *Repository) ListReminders(ctx context.Context, userID string) ([]*Reminder, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListReminders")
+	span.SetAttributes(attribute.String("user.id", userID))
+	defer span.End()
+
+	cursor, err := r.conn.Collection(reminderCollection).Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "due_at", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to list reminders")
+		return nil, err
+	}
+
+	var items []*Reminder
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode reminders")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "reminders listed")
+	return items, nil
+}
@@ -0,0 +1,103 @@
+package reminder
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/notify"
+)
+
+// Store claims due reminders and records their delivery state.
+type Store interface {
+	ClaimDue(ctx context.Context, now time.Time) (*Reminder, error)
+	UpdateReminder(ctx context.Context, rem *Reminder) error
+}
+
+// Publisher delivers the reminder event to the user's notification channels.
+type Publisher interface {
+	Publish(ctx context.Context, evt notify.Event) error
+}
+
+// Worker periodically delivers reminders that have come due.
+type Worker struct {
+	store       Store
+	publisher   Publisher
+	interval    time.Duration
+	maxAttempts int
+	now         func() time.Time
+}
+
+func NewWorker(store Store, publisher Publisher) *Worker {
+	return &Worker{
+		store:       store,
+		publisher:   publisher,
+		interval:    30 * time.Second,
+		maxAttempts: 3,
+		now:         time.Now,
+	}
+}
+
+// Run delivers due reminders on every tick until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick delivers every reminder that is due at the time of the call.
+func (w *Worker) Tick(ctx context.Context) {
+	for ctx.Err() == nil {
+		rem, err := w.store.ClaimDue(ctx, w.now())
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to claim due reminder", "error", err)
+			return
+		}
+
+		if rem == nil {
+			return
+		}
+
+		w.deliver(ctx, rem)
+	}
+}
+
+func (w *Worker) deliver(ctx context.Context, rem *Reminder) {
+	evt := notify.NewEvent(notify.EventReminder, rem.UserID, map[string]any{
+		"message":  rem.Message,
+		"due_at":   rem.DueAt.Format(time.RFC3339),
+		"timezone": rem.Timezone,
+	})
+	if !rem.ConversationID.IsZero() {
+		evt.ConversationID = rem.ConversationID.Hex()
+	}
+
+	rem.Status = StatusSent
+	rem.LastError = ""
+	if err := w.publisher.Publish(ctx, evt); err != nil {
+		slog.ErrorContext(ctx, "Failed to publish reminder", "reminder_id", rem.ID.Hex(), "attempt", rem.Attempts, "error", err)
+		rem.LastError = err.Error()
+		// Left in sending state, the reminder is claimed again once the claim times out.
+		rem.Status = StatusSending
+		if rem.Attempts >= w.maxAttempts {
+			rem.Status = StatusFailed
+		}
+	}
+
+	rem.UpdatedAt = w.now()
+	if err := w.store.UpdateReminder(ctx, rem); err != nil {
+		slog.ErrorContext(ctx, "Failed to update reminder", "reminder_id", rem.ID.Hex(), "error", err)
+		return
+	}
+
+	slog.InfoContext(ctx, "Reminder processed", "reminder_id", rem.ID.Hex(), "status", rem.Status)
+}
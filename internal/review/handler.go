@@ -0,0 +1,223 @@
+package review
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// maxBodySize bounds the JSON body of a note or resolution.
+const maxBodySize = 16 << 10
+
+// ConversationStore loads flagged conversations for inspection.
+type ConversationStore interface {
+	DescribeConversation(ctx context.Context, id string) (*model.Conversation, error)
+}
+
+// Handler serves the review queue admin API, for admins who authenticate with
+// "Authorization: Bearer <token>". Mounted with its prefix stripped, it serves
+//
+//	GET  /[?status=open|resolved]  flagged items, oldest first
+//	GET  /{id}                     an item and its conversation
+//	POST /{id}/notes               annotate an item, from {"text": "..."}
+//	POST /{id}/resolve             resolve an item, from {"resolution": "dismissed|actioned", "note": "..."}
+//
+// An empty token disables the API.
+func Handler(token string, store Store, conversations ConversationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := r.Context()
+		w.Header().Set("Cache-Control", "private, no-store")
+
+		path := strings.Trim(r.URL.Path, "/")
+		if path == "" {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				w.Header().Set("Allow", "GET, HEAD")
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			status := r.URL.Query().Get("status")
+			if status != "" && status != StatusOpen && status != StatusResolved {
+				http.Error(w, "status must be open or resolved", http.StatusBadRequest)
+				return
+			}
+			items, err := store.ListItems(ctx, status)
+			if err != nil {
+				fail(ctx, w, "Failed to list review items", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"items": items})
+			return
+		}
+
+		rawID, action, _ := strings.Cut(path, "/")
+		id, err := primitive.ObjectIDFromHex(rawID)
+		if err != nil {
+			http.Error(w, "Review item not found", http.StatusNotFound)
+			return
+		}
+
+		switch action {
+		case "":
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				w.Header().Set("Allow", "GET, HEAD")
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			inspect(ctx, w, store, conversations, id)
+
+		case "notes":
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", "POST")
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			var body struct {
+				Text string `json:"text"`
+			}
+			if !decode(w, r, &body) {
+				return
+			}
+			note, err := newNote(body.Text)
+			if err != nil {
+				http.Error(w, "Invalid note: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			item, err := store.Annotate(ctx, id, note)
+			if err != nil {
+				fail(ctx, w, "Failed to annotate review item", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, item)
+
+		case "resolve":
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", "POST")
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			var body struct {
+				Resolution string `json:"resolution"`
+				Note       string `json:"note"`
+			}
+			if !decode(w, r, &body) {
+				return
+			}
+			if body.Resolution != ResolutionDismissed && body.Resolution != ResolutionActioned {
+				http.Error(w, "Invalid resolution: must be dismissed or actioned", http.StatusBadRequest)
+				return
+			}
+			// The note explains the resolution, so it is added first
+			if strings.TrimSpace(body.Note) != "" {
+				note, err := newNote(body.Note)
+				if err != nil {
+					http.Error(w, "Invalid note: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+				if _, err := store.Annotate(ctx, id, note); err != nil {
+					fail(ctx, w, "Failed to annotate review item", err)
+					return
+				}
+			}
+			item, err := store.Resolve(ctx, id, body.Resolution, time.Now())
+			if err != nil {
+				fail(ctx, w, "Failed to resolve review item", err)
+				return
+			}
+			writeJSON(w, http.StatusOK, item)
+
+		default:
+			http.Error(w, "Not Found", http.StatusNotFound)
+		}
+	})
+}
+
+// inspect writes the item with its conversation, as the chat API returns it. The
+// conversation is left out when it was deleted since it was flagged.
+func inspect(ctx context.Context, w http.ResponseWriter, store Store, conversations ConversationStore, id primitive.ObjectID) {
+	item, err := store.DescribeItem(ctx, id)
+	if err != nil {
+		fail(ctx, w, "Failed to describe review item", err)
+		return
+	}
+
+	out := struct {
+		Item         *Item           `json:"item"`
+		Conversation json.RawMessage `json:"conversation,omitempty"`
+	}{Item: item}
+
+	conv, err := conversations.DescribeConversation(ctx, item.ConversationID)
+	var terr twirp.Error
+	switch {
+	case errors.As(err, &terr) && terr.Code() == twirp.NotFound:
+	case err != nil:
+		fail(ctx, w, "Failed to describe flagged conversation", err)
+		return
+	default:
+		if out.Conversation, err = protojson.Marshal(conv.Proto()); err != nil {
+			fail(ctx, w, "Failed to encode flagged conversation", err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func newNote(text string) (Note, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Note{}, errors.New("text is required")
+	}
+	if utf8.RuneCountInString(text) > MaxComment {
+		return Note{}, errors.New("text is too long")
+	}
+	return Note{Text: text, CreatedAt: time.Now()}, nil
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v); err != nil {
+		http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// fail answers 404 for items that don't exist, and 500 otherwise.
+func fail(ctx context.Context, w http.ResponseWriter, msg string, err error) {
+	var terr twirp.Error
+	if errors.As(err, &terr) && terr.Code() == twirp.NotFound {
+		http.Error(w, "Review item not found", http.StatusNotFound)
+		return
+	}
+
+	slog.ErrorContext(ctx, msg, "error", err)
+	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,178 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName       = "github.com/acai-travel/tech-challenge/internal/review"
+	reviewCollection = "review_queue"
+)
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+// EnsureIndexes creates the index listing the queue by status, and the one finding the
+// open item of a message.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.conn.Collection(reviewCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "message_id", Value: 1}, {Key: "source", Value: 1}, {Key: "status", Value: 1}}},
+	})
+	return err
+}
+
+func (r *Repository) Flag(ctx context.Context, item *Item) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.Flag")
+	span.SetAttributes(
+		attribute.String("conversation.id", item.ConversationID),
+		attribute.String("review.source", item.Source),
+		attribute.String("review.reason", item.Reason),
+	)
+	defer span.End()
+
+	now := time.Now()
+	res := r.conn.Collection(reviewCollection).FindOneAndUpdate(ctx,
+		bson.M{
+			"conversation_id": item.ConversationID,
+			"message_id":      item.MessageID,
+			"source":          item.Source,
+			"status":          StatusOpen,
+		},
+		bson.M{
+			// The latest flag tells why, as the reason may have changed
+			"$set": bson.M{
+				"reason":     item.Reason,
+				"comment":    item.Comment,
+				"updated_at": now,
+			},
+			"$inc": bson.M{"flags": 1},
+			"$setOnInsert": bson.M{
+				"_id":        primitive.NewObjectID(),
+				"user_id":    item.UserID,
+				"created_at": now,
+			},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+
+	if err := res.Decode(item); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to flag conversation")
+		return err
+	}
+
+	span.SetAttributes(attribute.String("review.id", item.ID.Hex()), attribute.Int("review.flags", item.Flags))
+	span.SetStatus(codes.Ok, "conversation flagged")
+	return nil
+}
+
+func (r *Repository) ListItems(ctx context.Context, status string) ([]*Item, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.ListItems")
+	span.SetAttributes(attribute.String("review.status", status))
+	defer span.End()
+
+	query := bson.M{}
+	if status != "" {
+		query["status"] = status
+	}
+	cursor, err := r.conn.Collection(reviewCollection).Find(ctx, query,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to query review queue")
+		return nil, err
+	}
+
+	var items []*Item
+	if err := cursor.All(ctx, &items); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode review items")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("review.count", len(items)))
+	span.SetStatus(codes.Ok, "review queue listed")
+	return items, nil
+}
+
+func (r *Repository) DescribeItem(ctx context.Context, id primitive.ObjectID) (*Item, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeItem")
+	span.SetAttributes(attribute.String("review.id", id.Hex()))
+	defer span.End()
+
+	var item Item
+	err := r.conn.Collection(reviewCollection).FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Error, "review item not found")
+		return nil, twirp.NotFoundError("review item not found")
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database error")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "review item found")
+	return &item, nil
+}
+
+func (r *Repository) Annotate(ctx context.Context, id primitive.ObjectID, note Note) (*Item, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.Annotate")
+	span.SetAttributes(attribute.String("review.id", id.Hex()))
+	defer span.End()
+
+	return r.update(ctx, span, id, bson.M{
+		"$push": bson.M{"notes": note},
+		"$set":  bson.M{"updated_at": note.CreatedAt},
+	})
+}
+
+// Resolve closes the item with the resolution. Resolving it again replaces the
+// resolution.
+func (r *Repository) Resolve(ctx context.Context, id primitive.ObjectID, resolution string, now time.Time) (*Item, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.Resolve")
+	span.SetAttributes(attribute.String("review.id", id.Hex()), attribute.String("review.resolution", resolution))
+	defer span.End()
+
+	return r.update(ctx, span, id, bson.M{"$set": bson.M{
+		"status":      StatusResolved,
+		"resolution":  resolution,
+		"resolved_at": now,
+		"updated_at":  now,
+	}})
+}
+
+func (r *Repository) update(ctx context.Context, span trace.Span, id primitive.ObjectID, update bson.M) (*Item, error) {
+	var item Item
+	err := r.conn.Collection(reviewCollection).FindOneAndUpdate(ctx, bson.M{"_id": id}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&item)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Error, "review item not found")
+		return nil, twirp.NotFoundError("review item not found")
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to update review item")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "review item updated")
+	return &item, nil
+}
@@ -0,0 +1,100 @@
+// Package review keeps the queue of conversations flagged for a human to look at: by
+// their users, for content they found harmful, or by the safety layer, for replies
+// carrying active content. Admins list, inspect, annotate and resolve the flags.
+package review
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxComment bounds the comment of a flag and the text of a note, in characters.
+const MaxComment = 2000
+
+// Sources of flags.
+const (
+	SourceUser   = "user"
+	SourceSafety = "safety"
+)
+
+// Reasons of flags. Users pick one of Reasons, the safety layer flags unsafe replies.
+const (
+	ReasonHarmful       = "harmful"
+	ReasonInappropriate = "inappropriate"
+	ReasonPrivacy       = "privacy"
+	ReasonOther         = "other"
+	ReasonUnsafeReply   = "unsafe_reply"
+)
+
+// Reasons are the reasons users can flag a conversation for.
+var Reasons = []string{ReasonHarmful, ReasonInappropriate, ReasonPrivacy, ReasonOther}
+
+// Statuses of flags.
+const (
+	StatusOpen     = "open"
+	StatusResolved = "resolved"
+)
+
+// Resolutions of flags.
+const (
+	// ResolutionDismissed closes a flag that needed no action.
+	ResolutionDismissed = "dismissed"
+	// ResolutionActioned closes a flag that was acted upon, e.g. a prompt fixed or a user
+	// warned, as described in its notes.
+	ResolutionActioned = "actioned"
+)
+
+// Item is a conversation, or a message of it, flagged for review. Flags of the same
+// message from the same source are merged while open.
+type Item struct {
+	ID             primitive.ObjectID `bson:"_id" json:"id"`
+	ConversationID string             `bson:"conversation_id" json:"conversation_id"`
+	MessageID      string             `bson:"message_id,omitempty" json:"message_id,omitempty"`
+	// UserID owns the conversation.
+	UserID  string `bson:"user_id" json:"user_id"`
+	Source  string `bson:"source" json:"source"`
+	Reason  string `bson:"reason" json:"reason"`
+	Comment string `bson:"comment,omitempty" json:"comment,omitempty"`
+	// Flags counts how many times the item was flagged while open.
+	Flags      int        `bson:"flags" json:"flags"`
+	Status     string     `bson:"status" json:"status"`
+	Notes      []Note     `bson:"notes,omitempty" json:"notes,omitempty"`
+	Resolution string     `bson:"resolution,omitempty" json:"resolution,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `bson:"updated_at" json:"updated_at"`
+	ResolvedAt *time.Time `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+// Note is an annotation of a reviewer.
+type Note struct {
+	Text      string    `bson:"text" json:"text"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// Store keeps the review queue.
+type Store interface {
+	// Flag adds the item to the queue, or counts one more flag of the open item of the
+	// same message and source. It sets the item to the one stored.
+	Flag(ctx context.Context, item *Item) error
+	// ListItems returns the items with the status, all when empty, oldest first.
+	ListItems(ctx context.Context, status string) ([]*Item, error)
+	DescribeItem(ctx context.Context, id primitive.ObjectID) (*Item, error)
+	Annotate(ctx context.Context, id primitive.ObjectID, note Note) (*Item, error)
+	Resolve(ctx context.Context, id primitive.ObjectID, resolution string, now time.Time) (*Item, error)
+}
+
+// ValidateFlag checks the reason and comment of a flag from a user.
+func ValidateFlag(reason, comment string) error {
+	if !slices.Contains(Reasons, reason) {
+		return fmt.Errorf("reason must be one of %v", Reasons)
+	}
+	if utf8.RuneCountInString(comment) > MaxComment {
+		return fmt.Errorf("comment must be at most %d characters", MaxComment)
+	}
+	return nil
+}
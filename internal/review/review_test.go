@@ -0,0 +1,166 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore is an in-memory review queue.
+type memoryStore struct {
+	items []*Item
+}
+
+func (s *memoryStore) Flag(_ context.Context, item *Item) error {
+	for _, it := range s.items {
+		if it.ConversationID == item.ConversationID && it.MessageID == item.MessageID && it.Source == item.Source && it.Status == StatusOpen {
+			it.Reason, it.Comment = item.Reason, item.Comment
+			it.Flags++
+			*item = *it
+			return nil
+		}
+	}
+	item.ID, item.Status, item.Flags = primitive.NewObjectID(), StatusOpen, 1
+	stored := *item
+	s.items = append(s.items, &stored)
+	return nil
+}
+
+func (s *memoryStore) ListItems(_ context.Context, status string) ([]*Item, error) {
+	var items []*Item
+	for _, it := range s.items {
+		if status == "" || it.Status == status {
+			items = append(items, it)
+		}
+	}
+	return items, nil
+}
+
+func (s *memoryStore) DescribeItem(_ context.Context, id primitive.ObjectID) (*Item, error) {
+	for _, it := range s.items {
+		if it.ID == id {
+			return it, nil
+		}
+	}
+	return nil, twirp.NotFoundError("review item not found")
+}
+
+func (s *memoryStore) Annotate(ctx context.Context, id primitive.ObjectID, note Note) (*Item, error) {
+	it, err := s.DescribeItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	it.Notes = append(it.Notes, note)
+	return it, nil
+}
+
+func (s *memoryStore) Resolve(ctx context.Context, id primitive.ObjectID, resolution string, now time.Time) (*Item, error) {
+	it, err := s.DescribeItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	it.Status, it.Resolution, it.ResolvedAt = StatusResolved, resolution, &now
+	return it, nil
+}
+
+type memoryConversations map[string]*model.Conversation
+
+func (c memoryConversations) DescribeConversation(_ context.Context, id string) (*model.Conversation, error) {
+	if conv, ok := c[id]; ok {
+		return conv, nil
+	}
+	return nil, twirp.NotFoundError("conversation not found")
+}
+
+func TestValidateFlag(t *testing.T) {
+	if err := ValidateFlag(ReasonHarmful, "Suggested a dangerous hike"); err != nil {
+		t.Errorf("ValidateFlag() of a harmful flag = %v", err)
+	}
+	if err := ValidateFlag(ReasonUnsafeReply, ""); err == nil {
+		t.Error("ValidateFlag() of the reason of the safety layer succeeded")
+	}
+	if err := ValidateFlag(ReasonOther, strings.Repeat("a", MaxComment+1)); err == nil {
+		t.Error("ValidateFlag() of a long comment succeeded")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	conv := &model.Conversation{ID: primitive.NewObjectID(), UserID: "alice", Title: "Hiking in Madeira"}
+	store := &memoryStore{}
+	h := Handler("secret", store, memoryConversations{conv.ID.Hex(): conv})
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	flagged := &Item{ConversationID: conv.ID.Hex(), UserID: "alice", Source: SourceUser, Reason: ReasonHarmful}
+	_ = store.Flag(context.Background(), flagged)
+	_ = store.Flag(context.Background(), &Item{ConversationID: conv.ID.Hex(), UserID: "alice", Source: SourceUser, Reason: ReasonHarmful})
+	gone := &Item{ConversationID: primitive.NewObjectID().Hex(), Source: SourceSafety, Reason: ReasonUnsafeReply}
+	_ = store.Flag(context.Background(), gone)
+
+	if rec := do(http.MethodGet, "/", "", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET with a wrong token = %d, want 401", rec.Code)
+	}
+
+	var list struct {
+		Items []*Item `json:"items"`
+	}
+	rec := do(http.MethodGet, "/?status=open", "", "secret")
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil || len(list.Items) != 2 || list.Items[0].Flags != 2 {
+		t.Fatalf("GET = %d, %v, want the 2 open items, the first flagged twice", rec.Code, err)
+	}
+
+	rec = do(http.MethodGet, "/"+flagged.ID.Hex(), "", "secret")
+	var inspected struct {
+		Item         *Item `json:"item"`
+		Conversation struct {
+			Title string `json:"title"`
+		} `json:"conversation"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&inspected); err != nil || inspected.Conversation.Title != "Hiking in Madeira" {
+		t.Errorf("GET of an item = %d, %v, want it with its conversation", rec.Code, err)
+	}
+	if rec := do(http.MethodGet, "/"+gone.ID.Hex(), "", "secret"); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), `"conversation"`) {
+		t.Errorf("GET of an item of a deleted conversation = %d %s, want it alone", rec.Code, rec.Body)
+	}
+
+	if rec := do(http.MethodPost, "/"+flagged.ID.Hex()+"/notes", `{"text": " "}`, "secret"); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST of an empty note = %d, want 400", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/"+flagged.ID.Hex()+"/notes", `{"text": "Checked the trail advice"}`, "secret"); rec.Code != http.StatusOK {
+		t.Errorf("POST of a note = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	if rec := do(http.MethodPost, "/"+flagged.ID.Hex()+"/resolve", `{"resolution": "ignored"}`, "secret"); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST of an unknown resolution = %d, want 400", rec.Code)
+	}
+	rec = do(http.MethodPost, "/"+flagged.ID.Hex()+"/resolve", `{"resolution": "actioned", "note": "Prompt fixed"}`, "secret")
+	var resolved Item
+	if err := json.NewDecoder(rec.Body).Decode(&resolved); err != nil || resolved.Status != StatusResolved || len(resolved.Notes) != 2 {
+		t.Errorf("POST resolve = %d, %+v, want it resolved with both notes", rec.Code, resolved)
+	}
+
+	rec = do(http.MethodGet, "/?status=open", "", "secret")
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil || len(list.Items) != 1 {
+		t.Errorf("GET after resolving = %d, %v, want the other item", rec.Code, err)
+	}
+
+	if rec := do(http.MethodPost, "/"+primitive.NewObjectID().Hex()+"/resolve", `{"resolution": "dismissed"}`, "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("POST resolve of an unknown item = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,160 @@
+// Package sampling configures which traces are recorded, so production tracing
+// volume can be controlled without losing the ability to trace a given request.
+package sampling
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sampler names accepted by New, in addition to the ratio and parent-based variants
+// defined by the OpenTelemetry environment variable specification.
+const (
+	AlwaysOn                = "always_on"
+	AlwaysOff               = "always_off"
+	TraceIDRatio            = "traceidratio"
+	RateLimited             = "ratelimited"
+	ParentBasedAlwaysOn     = "parentbased_always_on"
+	ParentBasedAlwaysOff    = "parentbased_always_off"
+	ParentBasedTraceIDRatio = "parentbased_traceidratio"
+	ParentBasedRateLimited  = "parentbased_ratelimited"
+)
+
+// FromEnv builds the sampler named by OTEL_TRACES_SAMPLER with the argument in
+// OTEL_TRACES_SAMPLER_ARG. It defaults to sampling every trace.
+func FromEnv() (sdktrace.Sampler, error) {
+	return New(os.Getenv("OTEL_TRACES_SAMPLER"), os.Getenv("OTEL_TRACES_SAMPLER_ARG"))
+}
+
+// New builds a sampler by name. The argument is the sampled fraction for ratio
+// samplers (default 1) and the number of traces per second for rate-limited ones
+// (default 10). Whatever the sampler, requests marked with ForceSample are sampled.
+func New(name, arg string) (sdktrace.Sampler, error) {
+	var sampler sdktrace.Sampler
+	switch name {
+	case "", ParentBasedAlwaysOn:
+		sampler = sdktrace.ParentBased(sdktrace.AlwaysSample())
+	case AlwaysOn:
+		sampler = sdktrace.AlwaysSample()
+	case AlwaysOff:
+		sampler = sdktrace.NeverSample()
+	case ParentBasedAlwaysOff:
+		sampler = sdktrace.ParentBased(sdktrace.NeverSample())
+	case TraceIDRatio, ParentBasedTraceIDRatio:
+		ratio, err := parseArg(arg, 1)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("invalid sampling ratio %q: must be between 0 and 1", arg)
+		}
+		sampler = sdktrace.TraceIDRatioBased(ratio)
+	case RateLimited, ParentBasedRateLimited:
+		perSecond, err := parseArg(arg, 10)
+		if err != nil || perSecond <= 0 {
+			return nil, fmt.Errorf("invalid sampling rate %q: must be a positive number of traces per second", arg)
+		}
+		sampler = NewRateLimited(perSecond)
+	default:
+		return nil, fmt.Errorf("unknown sampler %q", name)
+	}
+
+	if name == ParentBasedTraceIDRatio || name == ParentBasedRateLimited {
+		sampler = sdktrace.ParentBased(sampler)
+	}
+
+	return forceable{next: sampler}, nil
+}
+
+func parseArg(arg string, def float64) (float64, error) {
+	if arg == "" {
+		return def, nil
+	}
+	return strconv.ParseFloat(arg, 64)
+}
+
+type forceKey struct{}
+
+// ForceSample marks the request so every span started from ctx is sampled,
+// regardless of the configured sampler.
+func ForceSample(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceKey{}, true)
+}
+
+func forced(ctx context.Context) bool {
+	v, _ := ctx.Value(forceKey{}).(bool)
+	return v
+}
+
+// forceable samples forced requests and delegates the others to next.
+type forceable struct {
+	next sdktrace.Sampler
+}
+
+func (s forceable) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if forced(p.ParentContext) {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.next.ShouldSample(p)
+}
+
+func (s forceable) Description() string {
+	return "Forceable{" + s.next.Description() + "}"
+}
+
+// rateLimited samples at most perSecond traces per second, with a token bucket that
+// allows bursts of up to one second worth of traces.
+type rateLimited struct {
+	perSecond float64
+	burst     float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewRateLimited returns a sampler that samples at most perSecond traces per second.
+func NewRateLimited(perSecond float64) sdktrace.Sampler {
+	burst := max(1, perSecond)
+	return &rateLimited{perSecond: perSecond, burst: burst, tokens: burst, now: time.Now}
+}
+
+func (s *rateLimited) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := sdktrace.SamplingResult{
+		Decision:   sdktrace.Drop,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+	if s.take() {
+		result.Decision = sdktrace.RecordAndSample
+	}
+	return result
+}
+
+func (s *rateLimited) take() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !s.last.IsZero() {
+		s.tokens = min(s.burst, s.tokens+now.Sub(s.last).Seconds()*s.perSecond)
+	}
+	s.last = now
+
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+func (s *rateLimited) Description() string {
+	return fmt.Sprintf("RateLimited{%g/s}", s.perSecond)
+}
@@ -0,0 +1,80 @@
+package sampling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func sample(s sdktrace.Sampler, ctx context.Context) sdktrace.SamplingDecision {
+	return s.ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: ctx,
+		TraceID:       trace.TraceID{8: 0xff, 9: 0xff, 10: 0xff, 11: 0xff, 12: 0xff, 13: 0xff, 14: 0xff, 15: 0xff},
+		Name:          "test",
+	}).Decision
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name, arg string
+		want      sdktrace.SamplingDecision
+		wantErr   bool
+	}{
+		{name: "", want: sdktrace.RecordAndSample},
+		{name: AlwaysOff, want: sdktrace.Drop},
+		{name: ParentBasedTraceIDRatio, arg: "0.1", want: sdktrace.Drop},
+		{name: TraceIDRatio, arg: "1", want: sdktrace.RecordAndSample},
+		{name: ParentBasedRateLimited, arg: "5", want: sdktrace.RecordAndSample},
+		{name: TraceIDRatio, arg: "1.5", wantErr: true},
+		{name: RateLimited, arg: "0", wantErr: true},
+		{name: "sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.arg, func(t *testing.T) {
+			s, err := New(tt.name, tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := sample(s, context.Background()); got != tt.want {
+				t.Errorf("decision = %v, want %v", got, tt.want)
+			}
+
+			// Forced requests are always sampled
+			if got := sample(s, ForceSample(context.Background())); got != sdktrace.RecordAndSample {
+				t.Errorf("forced decision = %v, want RecordAndSample", got)
+			}
+		})
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	s := NewRateLimited(2).(*rateLimited)
+	s.now = func() time.Time { return now }
+
+	sampled := 0
+	for range 5 {
+		if sample(s, context.Background()) == sdktrace.RecordAndSample {
+			sampled++
+		}
+	}
+	if sampled != 2 {
+		t.Errorf("sampled %d traces in a burst, want 2", sampled)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if sample(s, context.Background()) != sdktrace.RecordAndSample {
+		t.Error("expected a trace to be sampled once the bucket refilled")
+	}
+	if sample(s, context.Background()) != sdktrace.Drop {
+		t.Error("expected the refilled bucket to be empty again")
+	}
+}
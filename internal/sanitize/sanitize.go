@@ -0,0 +1,172 @@
+// Package sanitize cleans the Markdown of assistant replies before they are stored and
+// shown: HTML is removed, and links must be safe addresses allowed by the deployment,
+// optionally routed through a redirect or click-tracking endpoint.
+package sanitize
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	// unsafeBlocks are HTML elements removed along with their content.
+	unsafeBlocks = func() []*regexp.Regexp {
+		var blocks []*regexp.Regexp
+		for _, tag := range []string{"script", "style", "iframe", "object", "embed", "noscript", "template"} {
+			blocks = append(blocks, regexp.MustCompile(`(?is)<`+tag+`\b[^>]*>.*?</\s*`+tag+`\s*>`))
+		}
+		return blocks
+	}()
+	comment  = regexp.MustCompile(`(?s)<!--.*?(?:-->|$)`)
+	autolink = regexp.MustCompile(`<((?:https?://|mailto:)[^>\s]+)>`)
+	tag      = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(?:\s[^<>]*)?/?>`)
+	// Link addresses may contain balanced parentheses, like Wikipedia's
+	link    = regexp.MustCompile(`(!?)\[([^\]]*)\]\(\s*<?((?:[^()\s>]|\([^()\s]*\))*)>?(?:\s+(?:"[^"]*"|'[^']*'))?\s*\)`)
+	linkDef = regexp.MustCompile(`(?m)^ {0,3}\[([^\]]+)\]:\s*<?([^\s>]+)>?.*$`)
+	// activeTag is an HTML element running script when shown
+	activeTag = regexp.MustCompile(`(?i)<[a-z][^>]*\s(?:on[a-z]+\s*=|(?:href|src|action)\s*=\s*["']?\s*(?:javascript|vbscript|data):)`)
+)
+
+// Policy decides which links replies may contain and where they lead. The zero Policy
+// allows links to any http, https or mailto address, as they are.
+type Policy struct {
+	// Redirect routes web links through a redirect or click-tracking endpoint, which
+	// gets their address as its "url" query parameter. Images are not routed.
+	Redirect *url.URL
+	// AllowedHosts restricts web links and images to these hosts and their subdomains.
+	// Others are reduced to their text. Empty allows every host.
+	AllowedHosts []string
+}
+
+// NewPolicyFromEnv configures a Policy from LINK_REDIRECT_URL and LINK_ALLOWED_HOSTS, a
+// comma-separated list of hosts.
+func NewPolicyFromEnv() (Policy, error) {
+	var p Policy
+	if v := os.Getenv("LINK_REDIRECT_URL"); v != "" {
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return Policy{}, fmt.Errorf("LINK_REDIRECT_URL must be an absolute http(s) URL, got %q", v)
+		}
+		p.Redirect = u
+	}
+	for _, host := range strings.Split(os.Getenv("LINK_ALLOWED_HOSTS"), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			p.AllowedHosts = append(p.AllowedHosts, host)
+		}
+	}
+	return p, nil
+}
+
+// Markdown returns the reply s without HTML, and with its links checked against the
+// policy: links to unsafe or disallowed addresses keep only their text.
+func (p Policy) Markdown(s string) string {
+	for _, block := range unsafeBlocks {
+		s = block.ReplaceAllString(s, "")
+	}
+	s = comment.ReplaceAllString(s, "")
+	s = autolink.ReplaceAllString(s, "[$1]($1)")
+	s = tag.ReplaceAllString(s, "")
+
+	s = link.ReplaceAllStringFunc(s, func(m string) string {
+		sub := link.FindStringSubmatch(m)
+		image, text, raw := sub[1] == "!", sub[2], sub[3]
+		u, ok := p.address(raw, !image)
+		if !ok {
+			return text
+		}
+		return fmt.Sprintf("%s[%s](%s)", sub[1], text, u)
+	})
+	s = linkDef.ReplaceAllStringFunc(s, func(m string) string {
+		sub := linkDef.FindStringSubmatch(m)
+		u, ok := p.address(sub[2], true)
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("[%s]: %s", sub[1], u)
+	})
+
+	return strings.TrimSpace(s)
+}
+
+// Unsafe reports whether the reply s carries active content, which Markdown removes
+// whatever the policy: script-like HTML elements, elements with event handlers or script
+// addresses, and links to javascript:, vbscript: or data: addresses. Replies only carry
+// it when instructions injected in the conversation or tool results steer the model.
+func Unsafe(s string) bool {
+	for _, block := range unsafeBlocks {
+		if block.MatchString(s) {
+			return true
+		}
+	}
+	if activeTag.MatchString(s) {
+		return true
+	}
+	for _, sub := range link.FindAllStringSubmatch(s, -1) {
+		if scriptAddress(sub[3]) {
+			return true
+		}
+	}
+	for _, sub := range linkDef.FindAllStringSubmatch(s, -1) {
+		if scriptAddress(sub[2]) {
+			return true
+		}
+	}
+	return false
+}
+
+func scriptAddress(raw string) bool {
+	scheme, _, ok := strings.Cut(strings.TrimSpace(raw), ":")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(scheme) {
+	case "javascript", "vbscript", "data":
+		return true
+	}
+	return false
+}
+
+// address checks the address of a link against the policy, and routes it through the
+// redirect endpoint when asked to.
+func (p Policy) address(raw string, redirect bool) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "mailto":
+		return u.String(), u.Opaque != ""
+	case "http", "https":
+	default:
+		return "", false
+	}
+	if u.Host == "" || !p.allowed(u.Hostname()) {
+		return "", false
+	}
+
+	if !redirect || p.Redirect == nil || strings.EqualFold(u.Host, p.Redirect.Host) {
+		return u.String(), true
+	}
+	r := *p.Redirect
+	q := r.Query()
+	q.Set("url", u.String())
+	r.RawQuery = q.Encode()
+	return r.String(), true
+}
+
+func (p Policy) allowed(host string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, h := range p.AllowedHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package sanitize
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestPolicy_Markdown(t *testing.T) {
+	redirect, _ := url.Parse("https://r.tour-assist.example/out?src=reply")
+
+	tests := []struct {
+		name   string
+		policy Policy
+		in     string
+		want   string
+	}{
+		{
+			name: "plain markdown is kept",
+			in:   "## Lisbon\n\n- **Alfama** by tram 28\n- Pastéis in Belém, 3 < 5 euros",
+			want: "## Lisbon\n\n- **Alfama** by tram 28\n- Pastéis in Belém, 3 < 5 euros",
+		},
+		{
+			name: "scripts and html are removed",
+			in:   "Hello<script>alert('x')</script> <b>there</b><!-- note --><img src=x onerror=alert(1)>!",
+			want: "Hello there!",
+		},
+		{
+			name: "unsafe links keep their text",
+			in:   "[Book now](javascript:alert(1)) or [call](data:text/html,hi) or ![pixel](//evil.example/p.gif)",
+			want: "Book now or call or pixel",
+		},
+		{
+			name: "safe links are kept",
+			in:   "See [TAP](https://www.flytap.com/en \"TAP\") or [email us](mailto:help@example.com).",
+			want: "See [TAP](https://www.flytap.com/en) or [email us](mailto:help@example.com).",
+		},
+		{
+			name:   "disallowed hosts keep their text",
+			policy: Policy{AllowedHosts: []string{"visitlisboa.com"}},
+			in:     "[Guide](https://www.visitlisboa.com/en) and [deals](https://cheap.example/lisbon)\n\n[ref]: https://cheap.example/x",
+			want:   "[Guide](https://www.visitlisboa.com/en) and deals",
+		},
+		{
+			name:   "links are routed through the redirect",
+			policy: Policy{Redirect: redirect},
+			in:     "[Guide](https://www.visitlisboa.com/en?lang=en) <https://tap.pt> ![map](https://maps.example/lisbon.png)",
+			want:   "[Guide](https://r.tour-assist.example/out?src=reply&url=https%3A%2F%2Fwww.visitlisboa.com%2Fen%3Flang%3Den) [https://tap.pt](https://r.tour-assist.example/out?src=reply&url=https%3A%2F%2Ftap.pt) ![map](https://maps.example/lisbon.png)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Markdown(tt.in); got != tt.want {
+				t.Errorf("Markdown() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsafe(t *testing.T) {
+	tests := map[string]bool{
+		"## Lisbon\n\n- **Alfama**, 3 < 5 euros":                       false,
+		"See [TAP](https://www.flytap.com/en) or <b>bold</b>":          false,
+		"Hello<script>alert('x')</script>":                             true,
+		"<img src=x onerror=alert(1)>":                                 true,
+		`<a href="javascript:alert(1)">Book</a>`:                       true,
+		"[Book now](javascript:alert(1))":                              true,
+		"[call](data:text/html,hi)":                                    true,
+		"[ref]: vbscript:msgbox":                                       true,
+		"[Guide](https://www.visitlisboa.com/en) or [help](/faq#data)": false,
+	}
+	for in, want := range tests {
+		if got := Unsafe(in); got != want {
+			t.Errorf("Unsafe(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestNewPolicyFromEnv(t *testing.T) {
+	t.Setenv("LINK_REDIRECT_URL", "https://r.tour-assist.example/out")
+	t.Setenv("LINK_ALLOWED_HOSTS", " VisitLisboa.com, ,tap.pt")
+
+	p, err := NewPolicyFromEnv()
+	if err != nil {
+		t.Fatalf("NewPolicyFromEnv() error = %v", err)
+	}
+	if p.Redirect.String() != "https://r.tour-assist.example/out" || len(p.AllowedHosts) != 2 || p.AllowedHosts[0] != "visitlisboa.com" {
+		t.Errorf("NewPolicyFromEnv() = %+v", p)
+	}
+
+	t.Setenv("LINK_REDIRECT_URL", "javascript:alert(1)")
+	if _, err := NewPolicyFromEnv(); err == nil {
+		t.Error("NewPolicyFromEnv() expected error for a non-http redirect")
+	}
+}
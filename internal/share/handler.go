@@ -0,0 +1,133 @@
+package share
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store looks up shares by ID.
+type Store interface {
+	DescribeShare(ctx context.Context, id primitive.ObjectID) (*Share, error)
+}
+
+// ConversationStore loads the shared conversation.
+type ConversationStore interface {
+	DescribeConversation(ctx context.Context, id string) (*model.Conversation, error)
+}
+
+var page = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 720px; margin: 2em auto; padding: 0 1em; }
+.message { margin: 1em 0; padding: .75em 1em; border-radius: 8px; white-space: pre-wrap; }
+.user { background: #e8f0fe; }
+.assistant { background: #f1f3f4; }
+.role { font-size: .8em; color: #5f6368; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Messages}}<div class="message {{.Role}}"><div class="role">{{.Role}} · {{.CreatedAt.Format "02 Jan 2006 15:04"}}</div>{{.Content}}</div>
+{{end}}</body>
+</html>
+`))
+
+// publicConversation is the JSON view of a shared conversation. It only holds what the
+// page shows, leaving out the owner, usage, feedback and everything else of the conversation.
+type publicConversation struct {
+	Title    string          `json:"title"`
+	Messages []publicMessage `json:"messages"`
+}
+
+type publicMessage struct {
+	Role    model.Role `json:"role"`
+	Content string     `json:"content"`
+}
+
+func newPublicConversation(c *model.Conversation) publicConversation {
+	view := publicConversation{Title: c.Title, Messages: []publicMessage{}}
+	for _, m := range c.Messages {
+		view.Messages = append(view.Messages, publicMessage{Role: m.Role, Content: m.Content})
+	}
+	return view
+}
+
+// Handler serves read-only views of shared conversations at <prefix>/<token>, as HTML
+// by default or as JSON when requested with "Accept: application/json" or "?format=json".
+func Handler(signer *Signer, shares Store, conversations ConversationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ctx := r.Context()
+		now := time.Now()
+
+		id, err := signer.Verify(path.Base(r.URL.Path), now)
+		if errors.Is(err, ErrExpired) {
+			http.Error(w, "This share link has expired", http.StatusGone)
+			return
+		}
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		s, err := shares.DescribeShare(ctx, id)
+		if err != nil {
+			slog.InfoContext(ctx, "Share lookup failed", "share_id", id.Hex(), "error", err)
+			http.NotFound(w, r)
+			return
+		}
+
+		if !s.Active(now) {
+			http.Error(w, "This share link is no longer available", http.StatusGone)
+			return
+		}
+
+		conv, err := conversations.DescribeConversation(ctx, s.ConversationID.Hex())
+		if err != nil {
+			slog.InfoContext(ctx, "Shared conversation lookup failed", "share_id", id.Hex(), "error", err)
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "private, no-store")
+		if wantsJSON(r) {
+			b, err := json.Marshal(newPublicConversation(conv))
+			if err != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(b)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := page.Execute(w, conv); err != nil {
+			slog.ErrorContext(ctx, "Failed to render shared conversation", "share_id", id.Hex(), "error", err)
+		}
+	})
+}
+
+func wantsJSON(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+}
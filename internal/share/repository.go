@@ -0,0 +1,99 @@
+package share
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName      = "github.com/acai-travel/tech-challenge/internal/share"
+	shareCollection = "shares"
+)
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) CreateShare(ctx context.Context, s *Share) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.CreateShare")
+	span.SetAttributes(
+		attribute.String("share.id", s.ID.Hex()),
+		attribute.String("conversation.id", s.ConversationID.Hex()),
+	)
+	defer span.End()
+
+	if _, err := r.conn.Collection(shareCollection).InsertOne(ctx, s); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create share")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "share created")
+	return nil
+}
+
+func (r *Repository) DescribeShare(ctx context.Context, id primitive.ObjectID) (*Share, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeShare")
+	span.SetAttributes(attribute.String("share.id", id.Hex()))
+	defer span.End()
+
+	var s Share
+	err := r.conn.Collection(shareCollection).FindOne(ctx, bson.M{"_id": id}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Error, "share not found")
+		return nil, twirp.NotFoundError("share not found")
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "database error")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "share found")
+	return &s, nil
+}
+
+// RevokeShare marks one of the user's shares as revoked.
+func (r *Repository) RevokeShare(ctx context.Context, userID, id string) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.RevokeShare")
+	span.SetAttributes(attribute.String("share.id", id))
+	defer span.End()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid share ID")
+		return twirp.NotFoundError("invalid share ID")
+	}
+
+	res, err := r.conn.Collection(shareCollection).UpdateOne(ctx,
+		bson.M{"_id": oid, "user_id": userID},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to revoke share")
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		span.SetStatus(codes.Error, "share not found")
+		return twirp.NotFoundError("share not found")
+	}
+
+	span.SetStatus(codes.Ok, "share revoked")
+	return nil
+}
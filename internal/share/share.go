@@ -0,0 +1,37 @@
+package share
+
+import (
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Share grants public, read-only access to a conversation until it expires or is revoked.
+type Share struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	ConversationID primitive.ObjectID `bson:"conversation_id"`
+	UserID         string             `bson:"user_id"`
+	ExpiresAt      time.Time          `bson:"expires_at"`
+	RevokedAt      *time.Time         `bson:"revoked_at,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+}
+
+// Active reports whether the share can still be opened at the given time.
+func (s *Share) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Proto converts the share, including its signed token and public URL.
+func (s *Share) Proto(token, url string) *pb.Share {
+	return &pb.Share{
+		Id:             s.ID.Hex(),
+		ConversationId: s.ConversationID.Hex(),
+		Token:          token,
+		Url:            url,
+		ExpiresAt:      timestamppb.New(s.ExpiresAt),
+		CreatedAt:      timestamppb.New(s.CreatedAt),
+		Revoked:        s.RevokedAt != nil,
+	}
+}
@@ -0,0 +1,151 @@
+package share
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSigner_Verify(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	id := primitive.NewObjectID()
+	now := time.Now()
+	token := signer.Sign(id, now.Add(time.Hour))
+
+	tests := []struct {
+		name    string
+		token   string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "valid token", token: token, now: now},
+		{name: "expired token", token: token, now: now.Add(2 * time.Hour), wantErr: ErrExpired},
+		{name: "tampered expiry", token: strings.Replace(token, ".", ".9", 1), now: now, wantErr: ErrInvalidToken},
+		{name: "other secret", token: NewSigner([]byte("other")).Sign(id, now.Add(time.Hour)), now: now, wantErr: ErrInvalidToken},
+		{name: "garbage", token: "not-a-token", now: now, wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signer.Verify(tt.token, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != id {
+				t.Errorf("Verify() = %s, want %s", got.Hex(), id.Hex())
+			}
+		})
+	}
+}
+
+type shareStore map[primitive.ObjectID]*Share
+
+func (s shareStore) DescribeShare(ctx context.Context, id primitive.ObjectID) (*Share, error) {
+	if sh, ok := s[id]; ok {
+		return sh, nil
+	}
+	return nil, twirp.NotFoundError("share not found")
+}
+
+type conversationStore map[string]*model.Conversation
+
+func (s conversationStore) DescribeConversation(ctx context.Context, id string) (*model.Conversation, error) {
+	if c, ok := s[id]; ok {
+		return c, nil
+	}
+	return nil, twirp.NotFoundError("conversation not found")
+}
+
+func TestHandler(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+
+	conv := &model.Conversation{
+		ID:     primitive.NewObjectID(),
+		UserID: "user-1",
+		Title:  "Weekend in <Lisbon>",
+		Messages: []*model.Message{
+			{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "What should I pack?", CreatedAt: now},
+			{ID: primitive.NewObjectID(), Role: model.RoleAssistant, Content: "Layers and a raincoat.", Intent: "packing",
+				Usage: &model.Usage{InputTokens: 4217}, CreatedAt: now},
+		},
+	}
+	active := &Share{ID: primitive.NewObjectID(), ConversationID: conv.ID, ExpiresAt: now.Add(time.Hour)}
+	revoked := &Share{ID: primitive.NewObjectID(), ConversationID: conv.ID, ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}
+
+	h := Handler(signer,
+		shareStore{active.ID: active, revoked.ID: revoked},
+		conversationStore{conv.ID.Hex(): conv})
+
+	tests := []struct {
+		name       string
+		path       string
+		accept     string
+		wantStatus int
+		wantBody   string
+		hidden     []string
+	}{
+		{
+			name:       "renders html",
+			path:       "/shared/" + signer.Sign(active.ID, active.ExpiresAt),
+			wantStatus: http.StatusOK,
+			wantBody:   "Weekend in &lt;Lisbon&gt;",
+		},
+		{
+			name:       "renders json",
+			path:       "/shared/" + signer.Sign(active.ID, active.ExpiresAt),
+			accept:     "application/json",
+			wantStatus: http.StatusOK,
+			wantBody:   `"messages":[{"role":"user","content":"What should I pack?"},{"role":"assistant","content":"Layers and a raincoat."}]`,
+			hidden:     []string{"user-1", conv.ID.Hex(), "packing", "4217"},
+		},
+		{
+			name:       "revoked share",
+			path:       "/shared/" + signer.Sign(revoked.ID, revoked.ExpiresAt),
+			wantStatus: http.StatusGone,
+		},
+		{
+			name:       "expired token",
+			path:       "/shared/" + signer.Sign(active.ID, now.Add(-time.Second)),
+			wantStatus: http.StatusGone,
+		},
+		{
+			name:       "unknown share",
+			path:       "/shared/" + signer.Sign(primitive.NewObjectID(), now.Add(time.Hour)),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+			for _, h := range tt.hidden {
+				if strings.Contains(rec.Body.String(), h) {
+					t.Errorf("body = %q, want it not to contain %q", rec.Body.String(), h)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid share token")
+	ErrExpired      = errors.New("share link expired")
+)
+
+// Signer issues and verifies share tokens of the form "<share id>.<expiry unix>.<signature>".
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: secret}
+}
+
+// NewSignerFromEnv uses SHARE_SECRET as signing key. Without it a random key is
+// generated, so links stop working when the server restarts.
+func NewSignerFromEnv() *Signer {
+	if secret := os.Getenv("SHARE_SECRET"); secret != "" {
+		return NewSigner([]byte(secret))
+	}
+
+	slog.Warn("SHARE_SECRET is not set, share links will be invalidated on restart")
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return NewSigner(secret)
+}
+
+func (s *Signer) Sign(id primitive.ObjectID, expiresAt time.Time) string {
+	payload := id.Hex() + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + s.signature(payload)
+}
+
+// Verify checks the token signature and expiry and returns the share ID it was issued for.
+func (s *Signer) Verify(token string, now time.Time) (primitive.ObjectID, error) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return primitive.NilObjectID, ErrInvalidToken
+	}
+
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(s.signature(payload))) {
+		return primitive.NilObjectID, ErrInvalidToken
+	}
+
+	idHex, expiry, ok := strings.Cut(payload, ".")
+	if !ok {
+		return primitive.NilObjectID, ErrInvalidToken
+	}
+
+	id, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !now.Before(time.Unix(unix, 0)) {
+		return primitive.NilObjectID, ErrExpired
+	}
+
+	return id, nil
+}
+
+func (s *Signer) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,336 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/progress"
+	"github.com/acai-travel/tech-challenge/internal/relay"
+	"github.com/google/uuid"
+)
+
+// flightTool is the tool whose offers are listed in replies, and offered as buttons.
+const flightTool = "get_flight_prices"
+
+// maxOptions bounds the buttons of the flight options of a reply.
+const maxOptions = 5
+
+// chooseFlight is the action ID prefix of the buttons of flight options.
+const chooseFlight = "choose_flight"
+
+// Progress follows the steps of replies, like progress.Hub.
+type Progress interface {
+	Subscribe(userID, attemptID string) (past []progress.Event, events <-chan progress.Event, cancel func())
+}
+
+// Request is a message to the app in a channel.
+type Request struct {
+	TeamID    string
+	ChannelID string
+	// ThreadTS is the thread of the message, empty for slash commands, which start one
+	ThreadTS string
+	// TS is the message, empty for slash commands and button choices
+	TS string
+	// UserID is the member who wrote, shown the steps of the reply
+	UserID string
+	Text   string
+	// Mention is set for mentions of the app, slash commands and button choices, which
+	// bring the app into threads; other messages are only answered in its threads
+	Mention bool
+}
+
+// Choice is a flight option chosen with the buttons of a reply.
+type Choice struct {
+	Request
+	// MessageTS is the reply whose buttons were clicked
+	MessageTS string
+	Option    string
+	// Blocks are the blocks of the reply, whose buttons are replaced by the choice
+	Blocks []Block
+}
+
+// Bridge answers the messages of Slack threads, each in its own conversation as the
+// user "slack:<team id>:<channel id>", so the members of a channel plan together.
+type Bridge struct {
+	client   *Client
+	chat     relay.Chat
+	threads  ThreadStore
+	progress Progress
+	tenantID string
+	now      func() time.Time
+
+	// locks serializes the messages of each thread, which continue the same conversation
+	locks sync.Map
+	wg    sync.WaitGroup
+}
+
+func NewBridge(client *Client, chat relay.Chat, threads ThreadStore, progress Progress, tenantID string) *Bridge {
+	return &Bridge{client: client, chat: chat, threads: threads, progress: progress, tenantID: tenantID, now: time.Now}
+}
+
+// Dispatch answers req in the background, as Slack expects requests to be acknowledged
+// within 3 seconds.
+func (b *Bridge) Dispatch(req Request) {
+	b.dispatch(req.ChannelID, req.UserID, func(ctx context.Context) error {
+		return b.Handle(ctx, req)
+	})
+}
+
+// DispatchChoice answers a choice in the background.
+func (b *Bridge) DispatchChoice(choice Choice) {
+	b.dispatch(choice.ChannelID, choice.UserID, func(ctx context.Context) error {
+		return b.Choose(ctx, choice)
+	})
+}
+
+func (b *Bridge) dispatch(channelID, userID string, handle func(ctx context.Context) error) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), relay.ReplyTimeout)
+		defer cancel()
+		if err := handle(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to answer Slack message", "tenant_id", b.tenantID, "channel_id", channelID, "error", err)
+			_ = b.client.PostEphemeral(ctx, channelID, userID, "", "Sorry, something went wrong. Please try again in a moment.")
+		}
+	}()
+}
+
+// Wait blocks until the requests dispatched are answered.
+func (b *Bridge) Wait() {
+	b.wg.Wait()
+}
+
+// Choose replaces the buttons of a reply by the option chosen, then answers it in the
+// reply's thread.
+func (b *Bridge) Choose(ctx context.Context, choice Choice) error {
+	blocks := make([]Block, 0, len(choice.Blocks)+1)
+	for _, block := range choice.Blocks {
+		if block["type"] != "actions" {
+			blocks = append(blocks, block)
+		}
+	}
+	blocks = append(blocks, Block{
+		"type":     "context",
+		"elements": []Block{{"type": "mrkdwn", "text": fmt.Sprintf("<@%s> chose %s", choice.UserID, escape(choice.Option))}},
+	})
+	if err := b.client.UpdateMessage(ctx, choice.ChannelID, choice.MessageTS, "Flight chosen: "+choice.Option, blocks); err != nil {
+		return fmt.Errorf("failed to update reply: %w", err)
+	}
+
+	req := choice.Request
+	req.Text = "Let's go with this flight: " + choice.Option
+	req.Mention = true
+	return b.Handle(ctx, req)
+}
+
+// Handle answers req in the conversation of its thread, starting one for the first
+// message of the app in the thread. Messages delivered twice are answered once.
+func (b *Bridge) Handle(ctx context.Context, req Request) error {
+	text := strings.TrimSpace(req.Text)
+	if req.ThreadTS == "" {
+		// Slash commands start a thread of their own, the trip being planned in the channel
+		ts, err := b.client.PostMessage(ctx, req.ChannelID, "", fmt.Sprintf("<@%s> is planning a trip: %s", req.UserID, escape(text)), nil)
+		if err != nil {
+			return fmt.Errorf("failed to start thread: %w", err)
+		}
+		req.ThreadTS = ts
+	}
+
+	id := ThreadID(req.TeamID, req.ChannelID, req.ThreadTS)
+	mu, _ := b.locks.LoadOrStore(id, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	thread, err := b.threads.DescribeThread(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get thread: %w", err)
+	}
+	if thread == nil {
+		if !req.Mention {
+			return nil
+		}
+		thread = &Thread{ID: id}
+	}
+	if req.TS != "" {
+		if thread.LastMessageTS == req.TS {
+			return nil
+		}
+		thread.LastMessageTS = req.TS
+	}
+	thread.UpdatedAt = b.now()
+
+	if text == "" {
+		if err := b.threads.SaveThread(ctx, thread); err != nil {
+			return fmt.Errorf("failed to save thread: %w", err)
+		}
+		_, err := b.client.PostMessage(ctx, req.ChannelID, req.ThreadTS, "Tell me about the trip: destination, dates and who's coming.", nil)
+		return err
+	}
+
+	userID := UserPrefix + req.TeamID + ":" + req.ChannelID
+	ctx = auth.WithUserID(ctx, userID)
+	if b.tenantID != "" {
+		ctx = auth.WithTenantID(ctx, b.tenantID)
+	}
+	attemptID := uuid.NewString()
+	stop := b.showProgress(ctx, userID, attemptID, req)
+
+	conversationID, out, err := relay.Send(ctx, b.chat, thread.ConversationID, text, attemptID)
+	stop()
+	if err != nil {
+		return err
+	}
+	thread.ConversationID = conversationID
+	if err := b.threads.SaveThread(ctx, thread); err != nil {
+		return fmt.Errorf("failed to save thread: %w", err)
+	}
+
+	reply := out.GetReply()
+	blocks := replyBlocks(reply)
+	if cites(out.GetCitations(), flightTool) {
+		if buttons := flightButtons(reply); buttons != nil {
+			blocks = append(blocks, buttons)
+		}
+	}
+	_, err = b.client.PostMessage(ctx, req.ChannelID, req.ThreadTS, reply, blocks)
+	return err
+}
+
+// showProgress shows the steps of the reply attemptID to the member who asked, as
+// ephemeral messages in the thread, until stop is called.
+func (b *Bridge) showProgress(ctx context.Context, userID, attemptID string, req Request) (stop func()) {
+	if b.progress == nil {
+		return func() {}
+	}
+	past, events, cancel := b.progress.Subscribe(userID, attemptID)
+	quit, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		show := func(e progress.Event) {
+			if e.Type == progress.EventStep && e.Message != "" {
+				if err := b.client.PostEphemeral(ctx, req.ChannelID, req.UserID, req.ThreadTS, e.Message); err != nil {
+					slog.WarnContext(ctx, "Failed to show reply step", "channel_id", req.ChannelID, "error", err)
+				}
+			}
+		}
+		for _, e := range past {
+			show(e)
+		}
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				show(e)
+			case <-quit:
+				// The steps already published are shown before the reply
+				for {
+					select {
+					case e, ok := <-events:
+						if !ok {
+							return
+						}
+						show(e)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(quit)
+			<-done
+			cancel()
+		})
+	}
+}
+
+// cites reports whether citations include the tool.
+func cites(citations []*pb.Citation, tool string) bool {
+	for _, c := range citations {
+		if c.GetTool() == tool {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	listItem = regexp.MustCompile(`^\s*(?:[-*+•]|\d+[.)])\s+`)
+	bold     = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	link     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	heading  = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+(.+)$`)
+)
+
+// flightButtons returns the list items of a reply listing flight offers as buttons, nil
+// without list items.
+func flightButtons(reply string) Block {
+	var buttons []Block
+	for _, line := range strings.Split(reply, "\n") {
+		m := listItem.FindString(line)
+		if m == "" {
+			continue
+		}
+		option := strings.TrimSpace(bold.ReplaceAllString(line[len(m):], "$1$2"))
+		if option == "" {
+			continue
+		}
+		buttons = append(buttons, Block{
+			"type":      "button",
+			"action_id": fmt.Sprintf("%s_%d", chooseFlight, len(buttons)),
+			"text":      Block{"type": "plain_text", "text": truncate(option, 75), "emoji": true},
+			"value":     truncate(option, 2000),
+		})
+		if len(buttons) == maxOptions {
+			break
+		}
+	}
+	if buttons == nil {
+		return nil
+	}
+	return Block{"type": "actions", "block_id": chooseFlight, "elements": buttons}
+}
+
+// replyBlocks returns a reply as sections of Slack mrkdwn, of at most 3000 characters.
+func replyBlocks(reply string) []Block {
+	var blocks []Block
+	for _, part := range relay.Split(mrkdwn(reply), 3000, relay.Chars) {
+		blocks = append(blocks, Block{"type": "section", "text": Block{"type": "mrkdwn", "text": part}})
+	}
+	return blocks
+}
+
+// mrkdwn converts the Markdown of replies to Slack mrkdwn.
+func mrkdwn(s string) string {
+	s = escape(s)
+	s = heading.ReplaceAllString(s, "*$1*")
+	s = bold.ReplaceAllString(s, "*$1$2*")
+	return link.ReplaceAllString(s, "<$2|$1>")
+}
+
+// escape escapes the characters Slack reserves for mentions and links.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// truncate shortens s to at most max characters.
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
@@ -0,0 +1,123 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Block is a Block Kit block of a message.
+type Block map[string]any
+
+// APIError is an error of the Web API, like "channel_not_found".
+type APIError struct {
+	Method string
+	Code   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("slack %s failed: %s", e.Method, e.Code)
+}
+
+// Client calls the Web API as the bot of an app.
+type Client struct {
+	cfg  *Config
+	http *http.Client
+}
+
+func NewClient(cfg *Config) *Client {
+	return &Client{cfg: cfg, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// PostMessage posts a message to a channel, in the thread threadTS unless empty, and
+// returns its timestamp. Text is the fallback of notifications when there are blocks.
+func (c *Client) PostMessage(ctx context.Context, channel, threadTS, text string, blocks []Block) (string, error) {
+	var out struct {
+		TS string `json:"ts"`
+	}
+	err := c.call(ctx, "chat.postMessage", map[string]any{
+		"channel":   channel,
+		"thread_ts": threadTS,
+		"text":      text,
+		"blocks":    blocks,
+	}, &out)
+	return out.TS, err
+}
+
+// PostEphemeral posts a message only user sees in a channel, in the thread threadTS
+// unless empty.
+func (c *Client) PostEphemeral(ctx context.Context, channel, user, threadTS, text string) error {
+	return c.call(ctx, "chat.postEphemeral", map[string]any{
+		"channel":   channel,
+		"user":      user,
+		"thread_ts": threadTS,
+		"text":      text,
+	}, nil)
+}
+
+// UpdateMessage replaces the text and blocks of the message ts of the bot.
+func (c *Client) UpdateMessage(ctx context.Context, channel, ts, text string, blocks []Block) error {
+	return c.call(ctx, "chat.update", map[string]any{
+		"channel": channel,
+		"ts":      ts,
+		"text":    text,
+		"blocks":  blocks,
+	}, nil)
+}
+
+func (c *Client) call(ctx context.Context, method string, params map[string]any, out any) error {
+	for k, v := range params {
+		if v == "" || v == nil {
+			delete(params, k)
+		}
+		if b, ok := v.([]Block); ok && b == nil {
+			delete(params, k)
+		}
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	apiURL := c.cfg.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(apiURL, "/")+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.cfg.BotToken)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack %s failed: status %d", method, resp.StatusCode)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if !status.OK {
+		return &APIError{Method: method, Code: status.Error}
+	}
+	if out != nil {
+		return json.Unmarshal(raw, out)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPayload bounds the size of the requests of Slack.
+const maxPayload = 1 << 20
+
+// maxSkew is how old requests can be, against replays.
+const maxSkew = 5 * time.Minute
+
+// mention matches the mentions of members and apps in messages.
+var mention = regexp.MustCompile(`<@[A-Z0-9]+(?:\|[^>]*)?>`)
+
+// eventPayload is a request of the Events API.
+type eventPayload struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	TeamID    string `json:"team_id"`
+	Event     struct {
+		Type     string `json:"type"`
+		Subtype  string `json:"subtype"`
+		BotID    string `json:"bot_id"`
+		User     string `json:"user"`
+		Text     string `json:"text"`
+		Channel  string `json:"channel"`
+		TS       string `json:"ts"`
+		ThreadTS string `json:"thread_ts"`
+	} `json:"event"`
+}
+
+// request returns the message of p to answer, false for other events.
+func (p *eventPayload) request() (Request, bool) {
+	e := p.Event
+	// Messages of bots, the app's replies included, and edits aren't answered
+	if e.BotID != "" || e.Subtype != "" || e.User == "" {
+		return Request{}, false
+	}
+	req := Request{TeamID: p.TeamID, ChannelID: e.Channel, ThreadTS: e.ThreadTS, TS: e.TS, UserID: e.User, Text: mention.ReplaceAllString(e.Text, "")}
+	switch e.Type {
+	case "app_mention":
+		if req.ThreadTS == "" {
+			req.ThreadTS = e.TS
+		}
+		req.Mention = true
+		return req, true
+	case "message":
+		// Only replies in threads can continue a conversation
+		return req, req.ThreadTS != ""
+	}
+	return Request{}, false
+}
+
+// interactionPayload is a click on a button.
+type interactionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	Message struct {
+		TS       string  `json:"ts"`
+		ThreadTS string  `json:"thread_ts"`
+		Blocks   []Block `json:"blocks"`
+	} `json:"message"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// Handler serves the request URLs of the app of cfg, under EventsPath, CommandsPath
+// and InteractionsPath, dispatching the messages of requests signed with the signing
+// secret to bridge.
+func Handler(cfg *Config, bridge *Bridge) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayload))
+		if err != nil {
+			http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if !validSignature(cfg.SigningSecret, r.Header.Get("X-Slack-Request-Timestamp"), body, r.Header.Get("X-Slack-Signature"), time.Now()) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case EventsPath:
+			var p eventPayload
+			if err := json.Unmarshal(body, &p); err != nil {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			if p.Type == "url_verification" {
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = io.WriteString(w, p.Challenge)
+				return
+			}
+			if req, ok := p.request(); p.Type == "event_callback" && ok {
+				slog.InfoContext(r.Context(), "Received Slack message", "channel_id", req.ChannelID, "event", p.Event.Type)
+				bridge.Dispatch(req)
+			}
+			w.WriteHeader(http.StatusOK)
+		case CommandsPath:
+			form, err := url.ParseQuery(string(body))
+			if err != nil {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			text := strings.TrimSpace(form.Get("text"))
+			if text == "" {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"response_type": "ephemeral",
+					"text":          "Tell me about the trip, like `" + form.Get("command") + " team offsite in Lisbon for 12 people in May`.",
+				})
+				return
+			}
+			bridge.Dispatch(Request{TeamID: form.Get("team_id"), ChannelID: form.Get("channel_id"), UserID: form.Get("user_id"), Text: text, Mention: true})
+			w.WriteHeader(http.StatusOK)
+		case InteractionsPath:
+			form, err := url.ParseQuery(string(body))
+			if err != nil {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			var p interactionPayload
+			if err := json.Unmarshal([]byte(form.Get("payload")), &p); err != nil {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			if p.Type == "block_actions" && len(p.Actions) > 0 && strings.HasPrefix(p.Actions[0].ActionID, chooseFlight) {
+				threadTS := p.Message.ThreadTS
+				if threadTS == "" {
+					threadTS = p.Message.TS
+				}
+				bridge.DispatchChoice(Choice{
+					Request:   Request{TeamID: p.Team.ID, ChannelID: p.Channel.ID, ThreadTS: threadTS, UserID: p.User.ID},
+					MessageTS: p.Message.TS,
+					Option:    p.Actions[0].Value,
+					Blocks:    p.Message.Blocks,
+				})
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// validSignature reports whether signature, "v0=<hex>", is the HMAC of the request
+// with the signing secret, sent less than maxSkew before now.
+func validSignature(secret, timestamp string, body []byte, signature string, now time.Time) bool {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > maxSkew || skew < -maxSkew {
+		return false
+	}
+	sig, ok := strings.CutPrefix(signature, "v0=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,85 @@
+// Package slack lets teams plan trips, like offsites, in Slack channels: each thread the
+// app is mentioned in, or started with its slash command, is answered in a conversation
+// of its own, with the steps of replies shown to the member who asked and flight options
+// offered as buttons.
+package slack
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// DefaultAPIURL is the URL of the Slack Web API.
+const DefaultAPIURL = "https://slack.com/api"
+
+// Paths of the app's request URLs, under the path of the tenant.
+const (
+	EventsPath       = "/slack/events"
+	CommandsPath     = "/slack/commands"
+	InteractionsPath = "/slack/interactions"
+)
+
+// UserPrefix prefixes the team and channel IDs of channels to make their user IDs, as
+// the members of a channel plan together.
+const UserPrefix = "slack:"
+
+// Config is the Slack app of a tenant, from its settings page at api.slack.com/apps.
+type Config struct {
+	// BotToken is the bot user OAuth token, "xoxb-…", with the app_mentions:read,
+	// channels:history, chat:write and commands scopes
+	BotToken string `json:"bot_token"`
+	// SigningSecret signs the requests of Slack to the app
+	SigningSecret string `json:"signing_secret"`
+
+	APIURL string `json:"-"`
+}
+
+// ConfigFromEnv returns the app of the default tenant, configured by SLACK_BOT_TOKEN
+// and SLACK_SIGNING_SECRET; nil without a bot token.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		BotToken:      os.Getenv("SLACK_BOT_TOKEN"),
+		SigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+	}
+	if cfg.BotToken == "" {
+		return nil, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate reports whether the configuration is usable.
+func (c *Config) Validate() error {
+	if c.BotToken == "" || c.SigningSecret == "" {
+		return errors.New("slack needs a bot_token and a signing_secret")
+	}
+	return nil
+}
+
+// Thread is the conversation of a Slack thread.
+type Thread struct {
+	// ID is "<team id>:<channel id>:<thread ts>", the timestamp of the thread's first
+	// message identifying it in its channel
+	ID             string `bson:"_id"`
+	ConversationID string `bson:"conversation_id,omitempty"`
+	// LastMessageTS is the last message answered, as mentions in threads are delivered
+	// both as mentions and as messages
+	LastMessageTS string    `bson:"last_message_ts,omitempty"`
+	UpdatedAt     time.Time `bson:"updated_at"`
+}
+
+// ThreadID returns the ID of the thread threadTS of a channel.
+func ThreadID(teamID, channelID, threadTS string) string {
+	return teamID + ":" + channelID + ":" + threadTS
+}
+
+// ThreadStore stores the threads of an app.
+type ThreadStore interface {
+	// DescribeThread returns a thread, nil when the app isn't part of it.
+	DescribeThread(ctx context.Context, id string) (*Thread, error)
+	SaveThread(ctx context.Context, t *Thread) error
+}
@@ -0,0 +1,302 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/progress"
+	"github.com/twitchtv/twirp"
+)
+
+// apiCall is a call of the fake Web API.
+type apiCall struct {
+	Method   string
+	ThreadTS string
+	User     string
+	Text     string
+	Blocks   []Block
+}
+
+// fakeSlack records the calls of the Web API.
+type fakeSlack struct {
+	mu    sync.Mutex
+	calls []apiCall
+}
+
+func (f *fakeSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+		w.Write([]byte(`{"ok": false, "error": "invalid_auth"}`))
+		return
+	}
+	var params struct {
+		ThreadTS string  `json:"thread_ts"`
+		User     string  `json:"user"`
+		Text     string  `json:"text"`
+		Blocks   []Block `json:"blocks"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&params)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, apiCall{Method: strings.TrimPrefix(r.URL.Path, "/"), ThreadTS: params.ThreadTS, User: params.User, Text: params.Text, Blocks: params.Blocks})
+	w.Write([]byte(`{"ok": true, "ts": "1700000000.000200"}`))
+}
+
+func (f *fakeSlack) sent() []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]apiCall(nil), f.calls...)
+}
+
+// fakeChat lists flight offers, publishing the step of the search.
+type fakeChat struct {
+	hub           *progress.Hub
+	conversations map[string]bool
+	messages      []string
+	userID        string
+}
+
+const offers = "Found 2 options:\n1. **BCN → LIS** at 07:10: 59 EUR\n2. BCN → LIS at 18:45: 74 EUR"
+
+func (f *fakeChat) StartConversation(ctx context.Context, req *pb.StartConversationRequest) (*pb.StartConversationResponse, error) {
+	f.userID = auth.UserID(ctx)
+	f.hub.Publish(f.userID, req.GetAttemptId(), progress.Event{Type: progress.EventStep, Tool: flightTool, Message: "Searching flights…"})
+	f.hub.Publish(f.userID, req.GetAttemptId(), progress.Event{Type: progress.EventDone})
+	f.messages = append(f.messages, req.GetMessage())
+	f.conversations["conv-2"] = true
+	return &pb.StartConversationResponse{ConversationId: "conv-2", Reply: offers, Citations: []*pb.Citation{{Tool: flightTool}}}, nil
+}
+
+func (f *fakeChat) ContinueConversation(ctx context.Context, req *pb.ContinueConversationRequest) (*pb.ContinueConversationResponse, error) {
+	if !f.conversations[req.GetConversationId()] {
+		return nil, twirp.NotFoundError("conversation not found")
+	}
+	f.messages = append(f.messages, req.GetMessage())
+	return &pb.ContinueConversationResponse{Reply: "Great pick, I'll watch its price."}, nil
+}
+
+// memoryThreads stores threads in memory.
+type memoryThreads map[string]Thread
+
+func (m memoryThreads) DescribeThread(_ context.Context, id string) (*Thread, error) {
+	t, ok := m[id]
+	if !ok {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (m memoryThreads) SaveThread(_ context.Context, t *Thread) error {
+	m[t.ID] = *t
+	return nil
+}
+
+func newTestBridge(t *testing.T) (*Bridge, *fakeSlack, *fakeChat, memoryThreads) {
+	t.Helper()
+	api := &fakeSlack{}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	hub := progress.NewHub()
+	chat := &fakeChat{hub: hub, conversations: map[string]bool{}}
+	threads := memoryThreads{}
+	return NewBridge(NewClient(&Config{BotToken: "xoxb-test", APIURL: srv.URL}), chat, threads, hub, ""), api, chat, threads
+}
+
+func TestBridge_Handle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("answers mentions in their thread", func(t *testing.T) {
+		bridge, api, chat, threads := newTestBridge(t)
+		req := Request{TeamID: "T1", ChannelID: "C1", ThreadTS: "100.1", TS: "100.1", UserID: "U1", Text: " Flights BCN to LIS on Friday?", Mention: true}
+		if err := bridge.Handle(ctx, req); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		// The mention is delivered again as a message of the channel
+		req.Mention = false
+		if err := bridge.Handle(ctx, req); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+
+		if got := threads["T1:C1:100.1"].ConversationID; got != "conv-2" {
+			t.Errorf("thread is on conversation %q", got)
+		}
+		if chat.userID != "slack:T1:C1" || len(chat.messages) != 1 {
+			t.Errorf("chat service called as %q with %q", chat.userID, chat.messages)
+		}
+
+		got := api.sent()
+		if len(got) != 2 {
+			t.Fatalf("calls = %+v", got)
+		}
+		if step := got[0]; step.Method != "chat.postEphemeral" || step.User != "U1" || step.ThreadTS != "100.1" || step.Text != "Searching flights…" {
+			t.Errorf("step shown with %+v", step)
+		}
+		reply := got[1]
+		if reply.Method != "chat.postMessage" || reply.ThreadTS != "100.1" || len(reply.Blocks) != 2 {
+			t.Fatalf("reply posted with %+v", reply)
+		}
+		if text := reply.Blocks[0]["text"].(map[string]any)["text"]; !strings.Contains(text.(string), "*BCN → LIS*") {
+			t.Errorf("reply text %q", text)
+		}
+		buttons := reply.Blocks[1]["elements"].([]any)
+		if len(buttons) != 2 || buttons[0].(map[string]any)["value"] != "BCN → LIS at 07:10: 59 EUR" {
+			t.Errorf("buttons = %+v", buttons)
+		}
+	})
+
+	t.Run("ignores threads it isn't part of", func(t *testing.T) {
+		bridge, api, chat, _ := newTestBridge(t)
+		if err := bridge.Handle(ctx, Request{TeamID: "T1", ChannelID: "C1", ThreadTS: "100.1", TS: "100.2", UserID: "U1", Text: "Lunch?"}); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if len(api.sent()) != 0 || len(chat.messages) != 0 {
+			t.Errorf("answered a thread without the app")
+		}
+	})
+
+	t.Run("starts a thread for slash commands", func(t *testing.T) {
+		bridge, api, _, threads := newTestBridge(t)
+		if err := bridge.Handle(ctx, Request{TeamID: "T1", ChannelID: "C1", UserID: "U1", Text: "Offsite in Lisbon", Mention: true}); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		got := api.sent()
+		if got[0].Method != "chat.postMessage" || got[0].ThreadTS != "" || got[0].Text != "<@U1> is planning a trip: Offsite in Lisbon" {
+			t.Errorf("thread started with %+v", got[0])
+		}
+		if _, ok := threads["T1:C1:1700000000.000200"]; !ok {
+			t.Errorf("threads = %+v", threads)
+		}
+	})
+
+	t.Run("continues with the flight chosen", func(t *testing.T) {
+		bridge, api, chat, threads := newTestBridge(t)
+		chat.conversations["conv-1"] = true
+		threads["T1:C1:100.1"] = Thread{ID: "T1:C1:100.1", ConversationID: "conv-1"}
+
+		err := bridge.Choose(ctx, Choice{
+			Request:   Request{TeamID: "T1", ChannelID: "C1", ThreadTS: "100.1", UserID: "U2"},
+			MessageTS: "100.5",
+			Option:    "BCN → LIS at 07:10: 59 EUR",
+			Blocks:    append(replyBlocks(offers), flightButtons(offers)),
+		})
+		if err != nil {
+			t.Fatalf("Choose() error = %v", err)
+		}
+		if len(chat.messages) != 1 || chat.messages[0] != "Let's go with this flight: BCN → LIS at 07:10: 59 EUR" {
+			t.Errorf("messages = %q", chat.messages)
+		}
+		update := api.sent()[0]
+		if update.Method != "chat.update" || len(update.Blocks) != 2 || update.Blocks[1]["type"] != "context" {
+			t.Errorf("reply updated with %+v", update)
+		}
+	})
+}
+
+// signed returns a request of Slack to path, signed with the signing secret at at.
+func signed(path, contentType, body string, at time.Time) *http.Request {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte("signing-secret"))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestHandler(t *testing.T) {
+	bridge, api, chat, _ := newTestBridge(t)
+	h := Handler(&Config{SigningSecret: "signing-secret"}, bridge)
+
+	t.Run("answers the URL verification", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, signed(EventsPath, "application/json", `{"type": "url_verification", "challenge": "3eZbrw1a"}`, time.Now()))
+		if rec.Code != http.StatusOK || rec.Body.String() != "3eZbrw1a" {
+			t.Errorf("got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("rejects unsigned and replayed requests", func(t *testing.T) {
+		req := signed(EventsPath, "application/json", `{"type": "url_verification"}`, time.Now())
+		req.Header.Set("X-Slack-Signature", "v0=00")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("bad signature: got %d", rec.Code)
+		}
+
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, signed(EventsPath, "application/json", `{"type": "url_verification"}`, time.Now().Add(-10*time.Minute)))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("stale request: got %d", rec.Code)
+		}
+	})
+
+	t.Run("explains the slash command", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, signed(CommandsPath, "application/x-www-form-urlencoded", "command=%2Ftrip&text=&team_id=T1&channel_id=C1&user_id=U1", time.Now()))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"response_type":"ephemeral"`) {
+			t.Errorf("got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("answers mentions", func(t *testing.T) {
+		body := `{"type": "event_callback", "team_id": "T1", "event": {"type": "app_mention", "user": "U1", "text": "<@U0APP> Flights to Lisbon?", "channel": "C1", "ts": "100.1"}}`
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, signed(EventsPath, "application/json", body, time.Now()))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d", rec.Code)
+		}
+
+		bridge.Wait()
+		if len(chat.messages) != 1 || chat.messages[0] != "Flights to Lisbon?" {
+			t.Errorf("messages = %q", chat.messages)
+		}
+	})
+
+	t.Run("answers the flight chosen", func(t *testing.T) {
+		payload, _ := json.Marshal(map[string]any{
+			"type":    "block_actions",
+			"user":    map[string]string{"id": "U2"},
+			"team":    map[string]string{"id": "T1"},
+			"channel": map[string]string{"id": "C1"},
+			"message": map[string]any{"ts": "100.5", "thread_ts": "100.1", "blocks": append(replyBlocks(offers), flightButtons(offers))},
+			"actions": []map[string]string{{"action_id": "choose_flight_1", "value": "BCN → LIS at 18:45: 74 EUR"}},
+		})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, signed(InteractionsPath, "application/x-www-form-urlencoded", "payload="+url.QueryEscape(string(payload)), time.Now()))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d", rec.Code)
+		}
+
+		bridge.Wait()
+		if got := chat.messages[len(chat.messages)-1]; got != "Let's go with this flight: BCN → LIS at 18:45: 74 EUR" {
+			t.Errorf("last message = %q", got)
+		}
+		if calls := api.sent(); calls[len(calls)-1].Text != "Great pick, I'll watch its price." {
+			t.Errorf("last call = %+v", calls[len(calls)-1])
+		}
+	})
+}
+
+func TestMrkdwn(t *testing.T) {
+	got := mrkdwn("## Lisbon\n**Day 1**: see [Belém](https://example.com/belem) & eat <3")
+	want := "*Lisbon*\n*Day 1*: see <https://example.com/belem|Belém> &amp; eat &lt;3"
+	if got != want {
+		t.Errorf("mrkdwn() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+package slo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Alert severities.
+const (
+	// SeverityPage is for budgets burning fast enough to be spent within days.
+	SeverityPage = "page"
+	// SeverityTicket is for budgets burning slower, but still too fast for the period.
+	SeverityTicket = "ticket"
+)
+
+// Alert statuses.
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Rule alerts when the burn rates over both its long and short windows exceed its
+// threshold. The short window resolves the alert quickly once the burn stops.
+type Rule struct {
+	Severity  string
+	Long      time.Duration
+	Short     time.Duration
+	Threshold float64
+}
+
+// Rules are the multiwindow burn-rate rules alerts fire on: a page when 2% of a 30
+// days budget burns in an hour, a ticket when 5% burns in 6 hours.
+var Rules = []Rule{
+	{Severity: SeverityPage, Long: time.Hour, Short: 5 * time.Minute, Threshold: 14.4},
+	{Severity: SeverityTicket, Long: 6 * time.Hour, Short: 30 * time.Minute, Threshold: 6},
+}
+
+// Alert is a change of state of a rule for an objective.
+type Alert struct {
+	Status    string             `json:"status"`
+	Severity  string             `json:"severity"`
+	SLI       string             `json:"sli"`
+	Target    float64            `json:"target"`
+	Threshold float64            `json:"threshold"`
+	BurnRates map[string]float64 `json:"burn_rates"`
+	Host      string             `json:"host"`
+	At        time.Time          `json:"at"`
+}
+
+// Summary describes the alert in a sentence.
+func (a Alert) Summary() string {
+	if a.Status == StatusResolved {
+		return fmt.Sprintf("SLO %s burn rate back under %g on %s", a.SLI, a.Threshold, a.Host)
+	}
+	return fmt.Sprintf("SLO %s error budget burning over %gx (target %g) on %s", a.SLI, a.Threshold, a.Target, a.Host)
+}
+
+// Alerter sends alerts.
+type Alerter interface {
+	Send(ctx context.Context, a Alert) error
+}
+
+// Webhook posts alerts as JSON to a URL.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *Webhook) Send(ctx context.Context, a Alert) error {
+	return post(ctx, w.client, w.url, a)
+}
+
+// PagerDuty triggers and resolves incidents with the Events API v2, one per
+// objective and severity.
+type PagerDuty struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+func NewPagerDuty(url, routingKey string) *PagerDuty {
+	return &PagerDuty{url: url, routingKey: routingKey, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *PagerDuty) Send(ctx context.Context, a Alert) error {
+	event := map[string]any{
+		"routing_key":  p.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    "slo:" + a.SLI + ":" + a.Severity,
+	}
+	if a.Status == StatusResolved {
+		event["event_action"] = "resolve"
+	} else {
+		severity := "warning"
+		if a.Severity == SeverityPage {
+			severity = "critical"
+		}
+		event["payload"] = map[string]any{
+			"summary":        a.Summary(),
+			"source":         a.Host,
+			"severity":       severity,
+			"timestamp":      a.At.Format(time.RFC3339),
+			"component":      a.SLI,
+			"custom_details": a,
+		}
+	}
+	return post(ctx, p.client, p.url, event)
+}
+
+func post(ctx context.Context, client *http.Client, url string, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
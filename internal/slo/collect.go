@@ -0,0 +1,142 @@
+package slo
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// The metrics the indicators are computed from.
+const (
+	requestDurationMetric = "http.server.request.duration"
+	toolExecutionMetric   = "tool.execution.count"
+	toolErrorMetric       = "tool.execution.errors"
+)
+
+// replyMethods are the Twirp methods generating a reply before they respond.
+var replyMethods = []string{"StartConversation", "StartConversationFromTemplate", "ContinueConversation", "SendVoiceMessage"}
+
+// isReply reports whether the request path, of any tenant, is a method generating a reply.
+func isReply(path string) bool {
+	_, method, ok := strings.Cut(path, "/twirp/")
+	if !ok {
+		return false
+	}
+	_, method, ok = strings.Cut(method, "/")
+	return ok && slices.Contains(replyMethods, method)
+}
+
+// sample is a reading of the cumulative metrics since the server started.
+type sample struct {
+	at     time.Time
+	counts map[string]Counts
+	// latency are the cumulative counts of the buckets of reply latency, of bounds
+	latency []uint64
+	bounds  []float64
+}
+
+// read computes the cumulative events of each indicator from metrics.
+func read(rm *metricdata.ResourceMetrics, replyLatency time.Duration, at time.Time) sample {
+	s := sample{at: at, counts: map[string]Counts{ReplyLatency: {}, ReplySuccess: {}, ToolSuccess: {}}}
+	threshold := float64(replyLatency.Milliseconds())
+
+	var executions, errors int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch m.Name {
+			case requestDurationMetric:
+				h, ok := m.Data.(metricdata.Histogram[float64])
+				if !ok {
+					continue
+				}
+				for _, dp := range h.DataPoints {
+					path, _ := dp.Attributes.Value("http.path")
+					if !isReply(path.AsString()) {
+						continue
+					}
+					success := s.counts[ReplySuccess]
+					success.Total += int64(dp.Count)
+					if !serverError(dp.Attributes) {
+						success.Good += int64(dp.Count)
+						s.addLatency(dp, threshold)
+					}
+					s.counts[ReplySuccess] = success
+				}
+			case toolExecutionMetric:
+				executions += sumInt64(m.Data)
+			case toolErrorMetric:
+				errors += sumInt64(m.Data)
+			}
+		}
+	}
+	s.counts[ToolSuccess] = Counts{Total: executions, Good: executions - errors}
+	return s
+}
+
+// addLatency adds the replies of dp to the latency indicator and buckets. Replies
+// are good when their bucket is bounded by threshold.
+func (s *sample) addLatency(dp metricdata.HistogramDataPoint[float64], threshold float64) {
+	if s.bounds == nil {
+		s.bounds = dp.Bounds
+		s.latency = make([]uint64, len(dp.BucketCounts))
+	}
+	latency := s.counts[ReplyLatency]
+	latency.Total += int64(dp.Count)
+	for i, n := range dp.BucketCounts {
+		if i < len(s.latency) {
+			s.latency[i] += n
+		}
+		if i < len(dp.Bounds) && dp.Bounds[i] <= threshold {
+			latency.Good += int64(n)
+		}
+	}
+	s.counts[ReplyLatency] = latency
+}
+
+func serverError(attrs attribute.Set) bool {
+	status, ok := attrs.Value("http.status_code")
+	return ok && status.AsInt64() >= 500
+}
+
+func sumInt64(data metricdata.Aggregation) int64 {
+	sum, ok := data.(metricdata.Sum[int64])
+	if !ok {
+		return 0
+	}
+	var total int64
+	for _, dp := range sum.DataPoints {
+		total += dp.Value
+	}
+	return total
+}
+
+// quantile estimates the q quantile of reply latency between prev and s, in
+// milliseconds, as the upper bound of the bucket reaching it. It returns 0 without
+// replies, and the largest bound when the bucket reaching it is unbounded.
+func (s sample) quantile(prev sample, q float64) float64 {
+	var total uint64
+	deltas := make([]uint64, len(s.latency))
+	for i, n := range s.latency {
+		if i < len(prev.latency) {
+			n -= prev.latency[i]
+		}
+		deltas[i] = n
+		total += n
+	}
+	if total == 0 || len(s.bounds) == 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+	var seen uint64
+	for i, n := range deltas {
+		seen += n
+		if float64(seen) >= rank {
+			return s.bounds[min(i, len(s.bounds)-1)]
+		}
+	}
+	return s.bounds[len(s.bounds)-1]
+}
@@ -0,0 +1,320 @@
+package slo
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const meterName = "github.com/acai-travel/tech-challenge/internal/slo"
+
+// minEvents is how many events the long window of a rule needs for it to fire, so
+// that a single failure on an idle server pages no one.
+const minEvents = 10
+
+// burnWindows are the windows burn rates are reported over.
+var burnWindows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+// Collector reads the metrics of the server, like a metric.ManualReader registered
+// with its meter provider.
+type Collector interface {
+	Collect(ctx context.Context, rm *metricdata.ResourceMetrics) error
+}
+
+// Store records compliance windows and sums them.
+type Store interface {
+	SaveWindow(ctx context.Context, w *Window) error
+	Compliance(ctx context.Context, since time.Time) (map[string]Counts, error)
+}
+
+// Monitor samples the metrics of the server every minute. It records the events of
+// each hour in the store, and alerts when the burn rates of the server cross the
+// Rules. Each server monitors its own traffic, the store summing them up.
+type Monitor struct {
+	cfg       Config
+	collector Collector
+	store     Store
+	alerters  []Alerter
+	host      string
+	interval  time.Duration
+	now       func() time.Time
+
+	mu sync.Mutex
+	// samples are the readings of the last 6 hours, oldest first
+	samples []sample
+	// windows are the current hour's window of each indicator
+	windows map[string]*Window
+	// firing are the alerts firing, by indicator and severity
+	firing map[[2]string]bool
+}
+
+// NewMonitor returns a monitor of the objectives of cfg, sending alerts to the
+// webhook and PagerDuty service of cfg, if any. It exports the burn rates of the
+// server as the slo.burn_rate gauge, and the 95th percentile of reply latency over
+// the last 5 minutes as slo.reply.latency_p95.
+func NewMonitor(cfg Config, collector Collector, store Store) *Monitor {
+	host, _ := os.Hostname()
+	m := &Monitor{
+		cfg:       cfg,
+		collector: collector,
+		store:     store,
+		host:      host,
+		interval:  time.Minute,
+		now:       time.Now,
+		windows:   make(map[string]*Window),
+		firing:    make(map[[2]string]bool),
+	}
+	if cfg.WebhookURL != "" {
+		m.alerters = append(m.alerters, NewWebhook(cfg.WebhookURL))
+	}
+	if cfg.PagerDutyRoutingKey != "" {
+		m.alerters = append(m.alerters, NewPagerDuty(cfg.PagerDutyURL, cfg.PagerDutyRoutingKey))
+	}
+
+	meter := otel.Meter(meterName)
+	_, _ = meter.Float64ObservableGauge(
+		"slo.burn_rate",
+		metric.WithDescription("Rate at which the error budget of an objective burns, 1 spending it over the period"),
+		metric.WithUnit("1"),
+		metric.WithFloat64Callback(func(_ context.Context, o metric.Float64Observer) error {
+			for sli, rates := range m.burnRates() {
+				for window, rate := range rates {
+					o.Observe(rate, metric.WithAttributes(attribute.String("slo.sli", sli), attribute.String("slo.window", window)))
+				}
+			}
+			return nil
+		}),
+	)
+	_, _ = meter.Float64ObservableGauge(
+		"slo.reply.latency_p95",
+		metric.WithDescription("95th percentile of reply latency over the last 5 minutes, by the request duration buckets"),
+		metric.WithUnit("ms"),
+		metric.WithFloat64Callback(func(_ context.Context, o metric.Float64Observer) error {
+			o.Observe(m.latencyP95())
+			return nil
+		}),
+	)
+	return m
+}
+
+// Run samples the metrics on every tick until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick samples the metrics, saves the current hour's windows and sends the alerts
+// that started or stopped firing.
+func (m *Monitor) Tick(ctx context.Context) {
+	now := m.now()
+	// Collecting runs the gauge callbacks, which lock the monitor
+	var rm metricdata.ResourceMetrics
+	if err := m.collector.Collect(ctx, &rm); err != nil {
+		slog.ErrorContext(ctx, "Failed to collect SLO metrics", "error", err)
+		return
+	}
+	s := read(&rm, m.cfg.ReplyLatency, now)
+
+	m.mu.Lock()
+	var prev sample
+	if len(m.samples) > 0 {
+		prev = m.samples[len(m.samples)-1]
+	}
+	m.samples = append(m.samples, s)
+	m.trim(now)
+
+	hour := now.UTC().Truncate(time.Hour)
+	var windows []Window
+	for _, o := range m.cfg.Objectives() {
+		w, ok := m.windows[o.SLI]
+		if !ok || !w.Start.Equal(hour) {
+			w = &Window{ID: windowID(o.SLI, m.host, hour), SLI: o.SLI, Host: m.host, Start: hour}
+			m.windows[o.SLI] = w
+		}
+		delta := s.counts[o.SLI].Sub(prev.counts[o.SLI])
+		w.Total += delta.Total
+		w.Good += delta.Good
+		w.UpdatedAt = now
+		windows = append(windows, *w)
+	}
+	alerts := m.evaluate(now)
+	m.mu.Unlock()
+
+	for i := range windows {
+		if err := m.store.SaveWindow(ctx, &windows[i]); err != nil {
+			slog.ErrorContext(ctx, "Failed to save SLO window", "sli", windows[i].SLI, "error", err)
+		}
+	}
+	for _, a := range alerts {
+		slog.WarnContext(ctx, "SLO alert", "status", a.Status, "severity", a.Severity, "sli", a.SLI, "burn_rates", a.BurnRates)
+		for _, alerter := range m.alerters {
+			if err := alerter.Send(ctx, a); err != nil {
+				slog.ErrorContext(ctx, "Failed to send SLO alert", "sli", a.SLI, "severity", a.Severity, "error", err)
+			}
+		}
+	}
+}
+
+// trim drops the samples no window needs anymore, keeping the last one at or before
+// the start of the longest window.
+func (m *Monitor) trim(now time.Time) {
+	cutoff := now.Add(-burnWindows[len(burnWindows)-1])
+	keep := 0
+	for i, s := range m.samples {
+		if !s.at.After(cutoff) {
+			keep = i
+		}
+	}
+	m.samples = m.samples[keep:]
+}
+
+// events returns the events of sli over the window ending with the last sample. A
+// window longer than the samples starts with the first one.
+func (m *Monitor) events(sli string, window time.Duration) Counts {
+	if len(m.samples) == 0 {
+		return Counts{}
+	}
+	last := m.samples[len(m.samples)-1]
+	return last.counts[sli].Sub(m.base(last.at.Add(-window)).counts[sli])
+}
+
+// base returns the last sample at or before start, or else the first one.
+func (m *Monitor) base(start time.Time) sample {
+	base := m.samples[0]
+	for _, s := range m.samples {
+		if s.at.After(start) {
+			break
+		}
+		base = s
+	}
+	return base
+}
+
+// evaluate returns the alerts of the Rules that started or stopped firing.
+func (m *Monitor) evaluate(now time.Time) []Alert {
+	var alerts []Alert
+	for _, o := range m.cfg.Objectives() {
+		for _, r := range Rules {
+			long, short := m.events(o.SLI, r.Long), m.events(o.SLI, r.Short)
+			firing := long.Total >= minEvents && long.BurnRate(o.Target) > r.Threshold && short.BurnRate(o.Target) > r.Threshold
+
+			key := [2]string{o.SLI, r.Severity}
+			if firing == m.firing[key] {
+				continue
+			}
+			m.firing[key] = firing
+
+			a := Alert{
+				Status:    StatusResolved,
+				Severity:  r.Severity,
+				SLI:       o.SLI,
+				Target:    o.Target,
+				Threshold: r.Threshold,
+				BurnRates: map[string]float64{
+					r.Long.String():  long.BurnRate(o.Target),
+					r.Short.String(): short.BurnRate(o.Target),
+				},
+				Host: m.host,
+				At:   now,
+			}
+			if firing {
+				a.Status = StatusFiring
+			}
+			alerts = append(alerts, a)
+		}
+	}
+	return alerts
+}
+
+// burnRates returns the burn rates of each indicator over the burnWindows.
+func (m *Monitor) burnRates() map[string]map[string]float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]map[string]float64)
+	for _, o := range m.cfg.Objectives() {
+		out[o.SLI] = make(map[string]float64, len(burnWindows))
+		for _, w := range burnWindows {
+			out[o.SLI][w.String()] = m.events(o.SLI, w).BurnRate(o.Target)
+		}
+	}
+	return out
+}
+
+func (m *Monitor) latencyP95() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.samples) == 0 {
+		return 0
+	}
+	last := m.samples[len(m.samples)-1]
+	return last.quantile(m.base(last.at.Add(-5*time.Minute)), 0.95)
+}
+
+// Report is the state of the objectives.
+type Report struct {
+	Host              string            `json:"host"`
+	Period            string            `json:"period"`
+	ReplyLatencyP95Ms float64           `json:"reply_latency_p95_ms"`
+	Objectives        []ObjectiveReport `json:"objectives"`
+}
+
+// ObjectiveReport is the state of an objective: its compliance over the period, on
+// every server, and the burn rates of this server.
+type ObjectiveReport struct {
+	Objective
+	Events               Counts             `json:"events"`
+	Compliance           float64            `json:"compliance"`
+	ErrorBudgetRemaining float64            `json:"error_budget_remaining"`
+	BurnRates            map[string]float64 `json:"burn_rates"`
+	Firing               []string           `json:"firing"`
+}
+
+// Report returns the state of the objectives.
+func (m *Monitor) Report(ctx context.Context) (*Report, error) {
+	compliance, err := m.store.Compliance(ctx, m.now().Add(-m.cfg.Period))
+	if err != nil {
+		return nil, err
+	}
+	rates := m.burnRates()
+
+	report := &Report{Host: m.host, Period: m.cfg.Period.String(), ReplyLatencyP95Ms: m.latencyP95()}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, o := range m.cfg.Objectives() {
+		events := compliance[o.SLI]
+		or := ObjectiveReport{
+			Objective:            o,
+			Events:               events,
+			Compliance:           events.Ratio(),
+			ErrorBudgetRemaining: 1 - events.BurnRate(o.Target),
+			BurnRates:            rates[o.SLI],
+			Firing:               []string{},
+		}
+		for _, r := range Rules {
+			if m.firing[[2]string{o.SLI, r.Severity}] {
+				or.Firing = append(or.Firing, r.Severity)
+			}
+		}
+		report.Objectives = append(report.Objectives, or)
+	}
+	return report, nil
+}
@@ -0,0 +1,105 @@
+package slo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName = "github.com/acai-travel/tech-challenge/internal/slo"
+
+	windowCollection = "slo_windows"
+)
+
+// Window are the events of an indicator on a server during an hour.
+type Window struct {
+	ID        string    `bson:"_id"`
+	SLI       string    `bson:"sli"`
+	Host      string    `bson:"host"`
+	Start     time.Time `bson:"start"`
+	Counts    `bson:",inline"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// windowID identifies the window of sli on host starting at start.
+func windowID(sli, host string, start time.Time) string {
+	return host + ":" + sli + ":" + start.UTC().Format(time.RFC3339)
+}
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+// EnsureIndexes creates the index compliance is computed with.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.conn.Collection(windowCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "start", Value: 1}, {Key: "sli", Value: 1}},
+	})
+	return err
+}
+
+// SaveWindow stores a window, replacing its previous counts.
+func (r *Repository) SaveWindow(ctx context.Context, w *Window) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SaveWindow")
+	span.SetAttributes(attribute.String("slo.sli", w.SLI), attribute.String("slo.window", w.ID))
+	defer span.End()
+
+	_, err := r.conn.Collection(windowCollection).ReplaceOne(ctx, bson.M{"_id": w.ID}, w, options.Replace().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to save window")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "window saved")
+	return nil
+}
+
+// Compliance returns the events of each indicator on every server in the windows
+// starting from since.
+func (r *Repository) Compliance(ctx context.Context, since time.Time) (map[string]Counts, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.Compliance")
+	defer span.End()
+
+	cursor, err := r.conn.Collection(windowCollection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"start": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$sli",
+			"total": bson.M{"$sum": "$total"},
+			"good":  bson.M{"$sum": "$good"},
+		}}},
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to aggregate windows")
+		return nil, err
+	}
+
+	var groups []struct {
+		SLI    string `bson:"_id"`
+		Counts `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode windows")
+		return nil, err
+	}
+
+	out := make(map[string]Counts, len(groups))
+	for _, g := range groups {
+		out[g.SLI] = g.Counts
+	}
+	span.SetStatus(codes.Ok, "compliance computed")
+	return out, nil
+}
@@ -0,0 +1,136 @@
+// Package slo tracks the service level objectives of replies and tools. It computes
+// their indicators from the metrics the server already records, stores how well each
+// hour met them, and alerts when their error budget burns too fast.
+package slo
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// The service level indicators, each the share of good events among all events.
+const (
+	// ReplyLatency is the share of replies answered within the latency threshold. With
+	// a target of 0.95 it is met when the 95th percentile of reply latency is.
+	ReplyLatency = "reply_latency"
+	// ReplySuccess is the share of reply requests not failing with a server error.
+	ReplySuccess = "reply_success"
+	// ToolSuccess is the share of tool executions not failing, the complement of the
+	// tool error rate.
+	ToolSuccess = "tool_success"
+)
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint alerts are sent to.
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Objective is the target of an indicator over the compliance period.
+type Objective struct {
+	SLI         string  `json:"sli"`
+	Target      float64 `json:"target"`
+	Description string  `json:"description"`
+}
+
+// Config is the configuration of the objectives and of their alerts.
+type Config struct {
+	// ReplyLatency is the latency threshold of replies. Latencies are measured by the
+	// buckets of the request duration histogram, so it's rounded down to a bucket bound.
+	ReplyLatency       time.Duration
+	ReplyLatencyTarget float64
+	ReplySuccessTarget float64
+	ToolSuccessTarget  float64
+	// Period is the period compliance is computed over.
+	Period time.Duration
+
+	// WebhookURL receives alerts as JSON, if set.
+	WebhookURL string
+	// PagerDutyRoutingKey is the integration key of the PagerDuty service paged, if set.
+	PagerDutyRoutingKey string
+	PagerDutyURL        string
+}
+
+// ConfigFromEnv reads the objectives from SLO_REPLY_LATENCY (20s by default),
+// SLO_REPLY_LATENCY_TARGET (0.95), SLO_REPLY_SUCCESS_TARGET (0.99),
+// SLO_TOOL_SUCCESS_TARGET (0.95) and SLO_PERIOD_DAYS (30), and where to send alerts
+// from SLO_ALERT_WEBHOOK_URL and SLO_PAGERDUTY_ROUTING_KEY.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		ReplyLatency:        20 * time.Second,
+		ReplyLatencyTarget:  0.95,
+		ReplySuccessTarget:  0.99,
+		ToolSuccessTarget:   0.95,
+		Period:              30 * 24 * time.Hour,
+		WebhookURL:          os.Getenv("SLO_ALERT_WEBHOOK_URL"),
+		PagerDutyRoutingKey: os.Getenv("SLO_PAGERDUTY_ROUTING_KEY"),
+		PagerDutyURL:        DefaultPagerDutyURL,
+	}
+
+	if v := os.Getenv("SLO_REPLY_LATENCY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, fmt.Errorf("SLO_REPLY_LATENCY must be a positive duration, got %q", v)
+		}
+		cfg.ReplyLatency = d
+	}
+	for name, target := range map[string]*float64{
+		"SLO_REPLY_LATENCY_TARGET": &cfg.ReplyLatencyTarget,
+		"SLO_REPLY_SUCCESS_TARGET": &cfg.ReplySuccessTarget,
+		"SLO_TOOL_SUCCESS_TARGET":  &cfg.ToolSuccessTarget,
+	} {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f >= 1 {
+			return Config{}, fmt.Errorf("%s must be between 0 and 1 excluded, got %q", name, v)
+		}
+		*target = f
+	}
+	if v := os.Getenv("SLO_PERIOD_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			return Config{}, fmt.Errorf("SLO_PERIOD_DAYS must be a positive number of days, got %q", v)
+		}
+		cfg.Period = time.Duration(days) * 24 * time.Hour
+	}
+	return cfg, nil
+}
+
+// Objectives returns the objectives of cfg.
+func (cfg Config) Objectives() []Objective {
+	return []Objective{
+		{SLI: ReplyLatency, Target: cfg.ReplyLatencyTarget, Description: fmt.Sprintf("Replies answered within %s", cfg.ReplyLatency)},
+		{SLI: ReplySuccess, Target: cfg.ReplySuccessTarget, Description: "Reply requests not failing with a server error"},
+		{SLI: ToolSuccess, Target: cfg.ToolSuccessTarget, Description: "Tool executions not failing"},
+	}
+}
+
+// Counts are the events of an indicator.
+type Counts struct {
+	Total int64 `json:"total" bson:"total"`
+	Good  int64 `json:"good" bson:"good"`
+}
+
+// Sub returns the events of c not in prev.
+func (c Counts) Sub(prev Counts) Counts {
+	return Counts{Total: c.Total - prev.Total, Good: c.Good - prev.Good}
+}
+
+// Ratio returns the share of good events, 1 without events.
+func (c Counts) Ratio() float64 {
+	if c.Total <= 0 {
+		return 1
+	}
+	return float64(c.Good) / float64(c.Total)
+}
+
+// BurnRate returns how fast the events consume the error budget of target: 1 spends
+// it exactly over the compliance period, 0 without events.
+func (c Counts) BurnRate(target float64) float64 {
+	if c.Total <= 0 {
+		return 0
+	}
+	return (1 - c.Ratio()) / (1 - target)
+}
@@ -0,0 +1,239 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+type memoryStore struct {
+	windows map[string]Window
+}
+
+func (s *memoryStore) SaveWindow(ctx context.Context, w *Window) error {
+	s.windows[w.ID] = *w
+	return nil
+}
+
+func (s *memoryStore) Compliance(ctx context.Context, since time.Time) (map[string]Counts, error) {
+	out := make(map[string]Counts)
+	for _, w := range s.windows {
+		if !w.Start.Before(since) {
+			c := out[w.SLI]
+			c.Total += w.Total
+			c.Good += w.Good
+			out[w.SLI] = c
+		}
+	}
+	return out, nil
+}
+
+// receiver records the JSON bodies posted to it.
+type receiver struct {
+	mu     sync.Mutex
+	bodies []map[string]any
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body map[string]any
+	_ = json.NewDecoder(req.Body).Decode(&body)
+	r.mu.Lock()
+	r.bodies = append(r.bodies, body)
+	r.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (r *receiver) take() []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.bodies
+	r.bodies = nil
+	return out
+}
+
+func TestMonitor(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	duration, _ := meter.Float64Histogram(requestDurationMetric, metric.WithExplicitBucketBoundaries(1000, 5000, 20000, 60000))
+	executions, _ := meter.Int64Counter(toolExecutionMetric)
+	ctx := context.Background()
+
+	reply := func(n int, ms float64, status int) {
+		attrs := metric.WithAttributes(
+			attribute.String("http.path", "/tenants/nordic/twirp/acai.chat.ChatService/ContinueConversation"),
+			attribute.Int("http.status_code", status),
+		)
+		for range n {
+			duration.Record(ctx, ms, attrs)
+		}
+	}
+
+	webhook, pagerduty := &receiver{}, &receiver{}
+	webhookSrv, pagerdutySrv := httptest.NewServer(webhook), httptest.NewServer(pagerduty)
+	defer webhookSrv.Close()
+	defer pagerdutySrv.Close()
+
+	cfg := Config{
+		ReplyLatency:        20 * time.Second,
+		ReplyLatencyTarget:  0.95,
+		ReplySuccessTarget:  0.99,
+		ToolSuccessTarget:   0.95,
+		Period:              30 * 24 * time.Hour,
+		WebhookURL:          webhookSrv.URL,
+		PagerDutyRoutingKey: "routing-key",
+		PagerDutyURL:        pagerdutySrv.URL,
+	}
+	store := &memoryStore{windows: map[string]Window{}}
+	m := NewMonitor(cfg, reader, store)
+	now := time.Date(2025, 10, 18, 10, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	m.Tick(ctx)
+
+	// 20% of replies failing burns the 1% budget 20 times too fast
+	reply(80, 500, http.StatusOK)
+	reply(20, 800, http.StatusBadGateway)
+	// Other requests don't count
+	duration.Record(ctx, 90000, metric.WithAttributes(attribute.String("http.path", "/twirp/acai.chat.ChatService/ListConversations"), attribute.Int("http.status_code", 500)))
+	executions.Add(ctx, 50)
+	now = now.Add(time.Minute)
+	m.Tick(ctx)
+
+	alerts := webhook.take()
+	if len(alerts) != 2 {
+		t.Fatalf("webhook got %d alerts, want a page and a ticket: %v", len(alerts), alerts)
+	}
+	for _, a := range alerts {
+		if a["status"] != StatusFiring || a["sli"] != ReplySuccess {
+			t.Errorf("alert = %v, want reply_success firing", a)
+		}
+	}
+	events := pagerduty.take()
+	if len(events) != 2 || events[0]["event_action"] != "trigger" || events[0]["dedup_key"] != "slo:reply_success:page" || events[0]["routing_key"] != "routing-key" {
+		t.Errorf("PagerDuty events = %v, want the page and ticket triggered", events)
+	}
+
+	// Firing alerts aren't sent again
+	now = now.Add(time.Minute)
+	m.Tick(ctx)
+	if alerts := webhook.take(); len(alerts) != 0 {
+		t.Errorf("webhook got %v again", alerts)
+	}
+
+	// Once replies succeed again, the short windows resolve the alerts
+	reply(190, 500, http.StatusOK)
+	reply(10, 30000, http.StatusOK)
+	now = now.Add(40 * time.Minute)
+	m.Tick(ctx)
+
+	alerts = webhook.take()
+	if len(alerts) != 2 || alerts[0]["status"] != StatusResolved || alerts[1]["status"] != StatusResolved {
+		t.Errorf("webhook got %v, want both alerts resolved", alerts)
+	}
+	if events := pagerduty.take(); len(events) != 2 || events[0]["event_action"] != "resolve" {
+		t.Errorf("PagerDuty events = %v, want both resolved", events)
+	}
+
+	if got := m.latencyP95(); got != 1000 {
+		t.Errorf("latencyP95() = %v, want the 1000 ms bucket", got)
+	}
+
+	report, err := m.Report(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Counts{
+		ReplySuccess: {Total: 300, Good: 280},
+		ReplyLatency: {Total: 280, Good: 270},
+		ToolSuccess:  {Total: 50, Good: 50},
+	}
+	for _, o := range report.Objectives {
+		if o.Events != want[o.SLI] {
+			t.Errorf("%s events = %+v, want %+v", o.SLI, o.Events, want[o.SLI])
+		}
+		if len(o.Firing) != 0 {
+			t.Errorf("%s firing = %v, want none", o.SLI, o.Firing)
+		}
+	}
+	if len(store.windows) != 3 {
+		t.Errorf("stored %d windows, want one per indicator for the hour", len(store.windows))
+	}
+}
+
+func TestCounts(t *testing.T) {
+	c := Counts{Total: 200, Good: 196}
+	if got := c.Ratio(); got != 0.98 {
+		t.Errorf("Ratio() = %v, want 0.98", got)
+	}
+	if got := c.BurnRate(0.99); got < 1.99 || got > 2.01 {
+		t.Errorf("BurnRate(0.99) = %v, want 2", got)
+	}
+	if got := (Counts{}).BurnRate(0.99); got != 0 {
+		t.Errorf("BurnRate() without events = %v, want 0", got)
+	}
+}
+
+func TestIsReply(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/twirp/acai.chat.ChatService/StartConversation":                   true,
+		"/tenants/nordic/twirp/acai.chat.ChatService/ContinueConversation": true,
+		"/twirp/acai.chat.ChatService/DescribeConversation":                false,
+		"/progress/ContinueConversation":                                   false,
+	} {
+		if got := isReply(path); got != want {
+			t.Errorf("isReply(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("SLO_REPLY_LATENCY", "10s")
+	t.Setenv("SLO_REPLY_SUCCESS_TARGET", "0.995")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ReplyLatency != 10*time.Second || cfg.ReplySuccessTarget != 0.995 || cfg.ToolSuccessTarget != 0.95 {
+		t.Errorf("ConfigFromEnv() = %+v", cfg)
+	}
+
+	for name, value := range map[string]string{"SLO_REPLY_LATENCY": "soon", "SLO_TOOL_SUCCESS_TARGET": "1", "SLO_PERIOD_DAYS": "-3"} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("ConfigFromEnv() accepted %s=%s", name, value)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	store := &memoryStore{windows: map[string]Window{}}
+	m := NewMonitor(Config{ReplyLatency: time.Second, ReplyLatencyTarget: 0.9, ReplySuccessTarget: 0.9, ToolSuccessTarget: 0.9, Period: time.Hour}, sdkmetric.NewManualReader(), store)
+	h := Handler("secret", m)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/slo", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want 401", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin/slo", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	h.ServeHTTP(rec, req)
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, error = %v", rec.Code, err)
+	}
+	if len(report.Objectives) != 3 || report.Objectives[0].Compliance != 1 || report.Objectives[0].ErrorBudgetRemaining != 1 {
+		t.Errorf("report = %+v, want the 3 objectives met without events", report)
+	}
+}
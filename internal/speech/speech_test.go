@@ -0,0 +1,112 @@
+package speech
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+func TestWhisperTranscriber_Transcribe(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+		wantErr  bool
+	}{
+		{name: "returns transcript", response: `{"text": " Weather in Lisbon tomorrow? "}`, want: "Weather in Lisbon tomorrow?"},
+		{name: "silence is an error", response: `{"text": ""}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/audio/transcriptions" {
+					t.Errorf("path = %s, want /audio/transcriptions", r.URL.Path)
+				}
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Fatalf("ParseMultipartForm() error = %v", err)
+				}
+				if got := r.FormValue("language"); got != "en" {
+					t.Errorf("language = %q, want en", got)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			tr := NewWhisperTranscriber(openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test")))
+			got, err := tr.Transcribe(context.Background(), []byte("audio"), "clip.m4a", "en")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Transcribe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Transcribe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupportedFormat(t *testing.T) {
+	for name, want := range map[string]bool{"clip.m4a": true, "VOICE.WAV": true, "notes.txt": false, "audio": false} {
+		if got := SupportedFormat(name); got != want {
+			t.Errorf("SupportedFormat(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestOpenAISynthesizer_Synthesize(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/speech" {
+			t.Errorf("path = %s, want /audio/speech", r.URL.Path)
+		}
+		calls++
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte("mp3"))
+	}))
+	defer srv.Close()
+
+	s := NewOpenAISynthesizer(openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test")))
+	text := strings.Repeat("It will be sunny in Lisbon tomorrow. ", 200)
+
+	audio, contentType, err := s.Synthesize(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+
+	if contentType != "audio/mpeg" {
+		t.Errorf("content type = %q, want audio/mpeg", contentType)
+	}
+	if calls != 2 || string(audio) != "mp3mp3" {
+		t.Errorf("got %d calls and audio %q, want long text spoken in 2 segments", calls, audio)
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{name: "short text", text: "Hello there.", limit: 20, want: []string{"Hello there."}},
+		{name: "sentence boundary", text: "One two. Three four.", limit: 12, want: []string{"One two.", "Three four."}},
+		{name: "word boundary", text: "aaaa bbbb cccc", limit: 10, want: []string{"aaaa bbbb", "cccc"}},
+		{name: "no boundary", text: "ééééé", limit: 5, want: []string{"éé", "éé", "é"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitText(tt.text, tt.limit)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// maxSpeechInput is the longest text the speech API accepts in a single request.
+const maxSpeechInput = 4096
+
+// Synthesizer turns text into spoken audio.
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text string) (audio []byte, contentType string, err error)
+}
+
+// OpenAISynthesizer generates MP3 speech with the OpenAI speech API.
+type OpenAISynthesizer struct {
+	cli   openai.Client
+	voice openai.AudioSpeechNewParamsVoice
+}
+
+func NewOpenAISynthesizer(cli openai.Client) *OpenAISynthesizer {
+	return &OpenAISynthesizer{cli: cli, voice: openai.AudioSpeechNewParamsVoiceAlloy}
+}
+
+// Synthesize speaks the text. Long texts are split on sentence boundaries and the
+// resulting MP3 segments are concatenated.
+func (s *OpenAISynthesizer) Synthesize(ctx context.Context, text string) ([]byte, string, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "OpenAISynthesizer.Synthesize")
+	span.SetAttributes(
+		attribute.String("openai.model", openai.SpeechModelGPT4oMiniTTS),
+		attribute.Int("text.length", len(text)),
+	)
+	defer span.End()
+
+	var audio []byte
+	for _, chunk := range splitText(text, maxSpeechInput) {
+		resp, err := s.cli.Audio.Speech.New(ctx, openai.AudioSpeechNewParams{
+			Input:          chunk,
+			Model:          openai.SpeechModelGPT4oMiniTTS,
+			Voice:          s.voice,
+			Instructions:   openai.String("Speak like a friendly, upbeat travel assistant."),
+			ResponseFormat: openai.AudioSpeechNewParamsResponseFormatMP3,
+		})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "speech synthesis failed")
+			return nil, "", err
+		}
+
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to read speech audio")
+			return nil, "", fmt.Errorf("read speech audio: %w", err)
+		}
+
+		audio = append(audio, b...)
+	}
+
+	span.SetAttributes(attribute.Int("audio.size", len(audio)))
+	span.SetStatus(codes.Ok, "speech synthesized")
+	return audio, "audio/mpeg", nil
+}
+
+// splitText splits text into chunks of at most limit bytes, preferring to cut after
+// sentence endings, then at spaces.
+func splitText(text string, limit int) []string {
+	text = strings.TrimSpace(text)
+
+	var chunks []string
+	for len(text) > limit {
+		cut := strings.LastIndexAny(text[:limit], ".!?\n")
+		if cut <= 0 {
+			cut = strings.LastIndex(text[:limit], " ")
+		}
+		if cut <= 0 {
+			// No space either; cut at the limit without splitting a rune
+			cut = limit - 1
+			for cut > 0 && !utf8.RuneStart(text[cut+1]) {
+				cut--
+			}
+		}
+
+		chunks = append(chunks, strings.TrimSpace(text[:cut+1]))
+		text = strings.TrimSpace(text[cut+1:])
+	}
+
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+
+	return chunks
+}
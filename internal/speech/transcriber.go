@@ -0,0 +1,81 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const tracerName = "github.com/acai-travel/tech-challenge/internal/speech"
+
+// MaxAudioSize is the largest audio clip accepted for transcription, in bytes.
+const MaxAudioSize = 25 << 20
+
+// AudioFormats are the accepted audio file extensions.
+var AudioFormats = []string{"flac", "mp3", "mp4", "mpeg", "mpga", "m4a", "ogg", "wav", "webm"}
+
+// Transcriber turns recorded speech into text.
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio []byte, filename, language string) (string, error)
+}
+
+// WhisperTranscriber transcribes audio with the OpenAI transcription API.
+type WhisperTranscriber struct {
+	cli openai.Client
+}
+
+func NewWhisperTranscriber(cli openai.Client) *WhisperTranscriber {
+	return &WhisperTranscriber{cli: cli}
+}
+
+// Transcribe converts the audio clip to text. The filename extension tells the API the
+// audio format; language is an optional ISO-639-1 hint.
+func (t *WhisperTranscriber) Transcribe(ctx context.Context, audio []byte, filename, language string) (string, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "WhisperTranscriber.Transcribe")
+	span.SetAttributes(
+		attribute.String("openai.model", openai.AudioModelWhisper1),
+		attribute.Int("audio.size", len(audio)),
+	)
+	defer span.End()
+
+	params := openai.AudioTranscriptionNewParams{
+		File:  openai.File(bytes.NewReader(audio), filename, ""),
+		Model: openai.AudioModelWhisper1,
+	}
+	if language != "" {
+		params.Language = openai.String(language)
+	}
+
+	resp, err := t.cli.Audio.Transcriptions.New(ctx, params)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "transcription failed")
+		return "", err
+	}
+
+	text := strings.TrimSpace(resp.Text)
+	if text == "" {
+		err := errors.New("no speech detected in audio")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "empty transcript")
+		return "", err
+	}
+
+	span.SetAttributes(attribute.Int("transcript.length", len(text)))
+	span.SetStatus(codes.Ok, "audio transcribed")
+	return text, nil
+}
+
+// SupportedFormat reports whether the filename has an accepted audio extension.
+func SupportedFormat(filename string) bool {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(filename), "."))
+	return slices.Contains(AudioFormats, ext)
+}
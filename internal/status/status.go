@@ -0,0 +1,317 @@
+// Package status summarizes the live state of a server from its metrics, as a minimal
+// ops dashboard: replies in flight, tool error rates, OpenAI latency, cache hit rates
+// and queue depths.
+package status
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/slo"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// Window is the period rates and percentiles are computed over.
+const Window = 5 * time.Minute
+
+// QueueDepth returns the number of items waiting in a queue.
+type QueueDepth func(ctx context.Context) (int64, error)
+
+// Status is the live state of a server.
+type Status struct {
+	Host   string    `json:"host"`
+	At     time.Time `json:"at"`
+	Window string    `json:"window"`
+
+	Replies Replies          `json:"replies"`
+	Tools   map[string]Tool  `json:"tools"`
+	OpenAI  OpenAI           `json:"openai"`
+	Caches  map[string]Cache `json:"caches"`
+	// Queues are the depths of the queues, by name
+	Queues map[string]int64 `json:"queues"`
+}
+
+// Replies are the replies of the server.
+type Replies struct {
+	InFlight int64 `json:"in_flight"`
+}
+
+// Tool is the state of a tool executed within the last 5 minutes.
+type Tool struct {
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// OpenAI are the OpenAI calls of the server. Latency percentiles are the upper bounds
+// of the buckets of the openai.requests.duration histogram they fall in.
+type OpenAI struct {
+	InFlight  int64       `json:"in_flight"`
+	Calls     uint64      `json:"calls"`
+	LatencyMs Percentiles `json:"latency_ms"`
+}
+
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+// Cache are the lookups in a cache over the window.
+type Cache struct {
+	Lookups int64   `json:"lookups"`
+	Hits    int64   `json:"hits"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// snapshot is a reading of the cumulative metrics.
+type snapshot struct {
+	at      time.Time
+	calls   uint64
+	latency []uint64
+	bounds  []float64
+	caches  map[string]Cache
+}
+
+// Board computes the status of the server. It samples the metrics every 30 seconds, to
+// compute rates and percentiles over the last Window.
+type Board struct {
+	collector slo.Collector
+	queues    map[string]QueueDepth
+	host      string
+	interval  time.Duration
+	now       func() time.Time
+
+	mu sync.Mutex
+	// samples are the snapshots of the last Window, oldest first
+	samples []snapshot
+}
+
+// NewBoard returns a board of the metrics of collector and of the depths of queues,
+// by name.
+func NewBoard(collector slo.Collector, queues map[string]QueueDepth) *Board {
+	host, _ := os.Hostname()
+	return &Board{
+		collector: collector,
+		queues:    queues,
+		host:      host,
+		interval:  30 * time.Second,
+		now:       time.Now,
+	}
+}
+
+// Run samples the metrics on every tick until ctx is cancelled.
+func (b *Board) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		b.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick samples the metrics.
+func (b *Board) Tick(ctx context.Context) {
+	var rm metricdata.ResourceMetrics
+	if err := b.collector.Collect(ctx, &rm); err != nil {
+		slog.ErrorContext(ctx, "Failed to collect status metrics", "error", err)
+		return
+	}
+	now := b.now()
+	s := takeSnapshot(&rm, now)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.samples = append(b.samples, s)
+	keep := 0
+	for i, sample := range b.samples {
+		if !sample.at.After(now.Add(-Window)) {
+			keep = i
+		}
+	}
+	b.samples = b.samples[keep:]
+}
+
+// base returns the last sample at or before the start of the window ending at now, or
+// else the first one, or else an empty one.
+func (b *Board) base(now time.Time) snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.samples) == 0 {
+		return snapshot{}
+	}
+	base := b.samples[0]
+	for _, s := range b.samples {
+		if s.at.After(now.Add(-Window)) {
+			break
+		}
+		base = s
+	}
+	return base
+}
+
+// Status returns the status of the server now. The depth of a queue failing to be
+// read is left out.
+func (b *Board) Status(ctx context.Context) (*Status, error) {
+	var rm metricdata.ResourceMetrics
+	if err := b.collector.Collect(ctx, &rm); err != nil {
+		return nil, err
+	}
+	now := b.now()
+	current := takeSnapshot(&rm, now)
+	base := b.base(now)
+
+	st := &Status{
+		Host:   b.host,
+		At:     now,
+		Window: Window.String(),
+		Tools:  map[string]Tool{},
+		Caches: map[string]Cache{},
+		Queues: map[string]int64{},
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch m.Name {
+			case "assistant.reply.in_flight":
+				st.Replies.InFlight += sumInt64(m.Data)
+			case "openai.requests.in_flight":
+				st.OpenAI.InFlight += sumInt64(m.Data)
+			case "openai.requests.queued":
+				st.Queues["openai_slots"] += sumInt64(m.Data)
+			case "tool.execution.error_ratio":
+				if g, ok := m.Data.(metricdata.Gauge[float64]); ok {
+					for _, dp := range g.DataPoints {
+						name, _ := dp.Attributes.Value("tool.name")
+						st.Tools[name.AsString()] = Tool{ErrorRate: dp.Value}
+					}
+				}
+			}
+		}
+	}
+
+	deltas, calls := current.latencyDeltas(base)
+	st.OpenAI.Calls = calls
+	st.OpenAI.LatencyMs = Percentiles{
+		P50: quantile(current.bounds, deltas, 0.5),
+		P90: quantile(current.bounds, deltas, 0.9),
+		P99: quantile(current.bounds, deltas, 0.99),
+	}
+	for name, c := range current.caches {
+		prev := base.caches[name]
+		c.Lookups -= prev.Lookups
+		c.Hits -= prev.Hits
+		if c.Lookups > 0 {
+			c.HitRate = float64(c.Hits) / float64(c.Lookups)
+		}
+		st.Caches[name] = c
+	}
+
+	for name, depth := range b.queues {
+		n, err := depth(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to read queue depth", "queue", name, "error", err)
+			continue
+		}
+		st.Queues[name] = n
+	}
+	return st, nil
+}
+
+// takeSnapshot reads the cumulative OpenAI latency buckets and cache lookups of rm.
+func takeSnapshot(rm *metricdata.ResourceMetrics, at time.Time) snapshot {
+	s := snapshot{at: at, caches: map[string]Cache{}}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch m.Name {
+			case "openai.requests.duration":
+				h, ok := m.Data.(metricdata.Histogram[float64])
+				if !ok {
+					continue
+				}
+				for _, dp := range h.DataPoints {
+					if s.bounds == nil {
+						s.bounds = dp.Bounds
+						s.latency = make([]uint64, len(dp.BucketCounts))
+					}
+					s.calls += dp.Count
+					for i, n := range dp.BucketCounts {
+						if i < len(s.latency) {
+							s.latency[i] += n
+						}
+					}
+				}
+			case "cache.lookups":
+				sum, ok := m.Data.(metricdata.Sum[int64])
+				if !ok {
+					continue
+				}
+				for _, dp := range sum.DataPoints {
+					name, _ := dp.Attributes.Value("cache.name")
+					hit, _ := dp.Attributes.Value("cache.hit")
+					c := s.caches[name.AsString()]
+					c.Lookups += dp.Value
+					if hit.AsBool() {
+						c.Hits += dp.Value
+					}
+					s.caches[name.AsString()] = c
+				}
+			}
+		}
+	}
+	return s
+}
+
+// latencyDeltas returns the OpenAI calls of each latency bucket since base, and their
+// total.
+func (s snapshot) latencyDeltas(base snapshot) ([]uint64, uint64) {
+	deltas := make([]uint64, len(s.latency))
+	for i, n := range s.latency {
+		if i < len(base.latency) {
+			n -= base.latency[i]
+		}
+		deltas[i] = n
+	}
+	return deltas, s.calls - base.calls
+}
+
+// quantile returns the upper bound of the bucket the q quantile of counts falls in,
+// the largest bound for the unbounded bucket, and 0 without counts.
+func quantile(bounds []float64, counts []uint64, q float64) float64 {
+	var total uint64
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 || len(bounds) == 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+	var seen uint64
+	for i, n := range counts {
+		seen += n
+		if float64(seen) >= rank {
+			return bounds[min(i, len(bounds)-1)]
+		}
+	}
+	return bounds[len(bounds)-1]
+}
+
+func sumInt64(data metricdata.Aggregation) int64 {
+	sum, ok := data.(metricdata.Sum[int64])
+	if !ok {
+		return 0
+	}
+	var total int64
+	for _, dp := range sum.DataPoints {
+		total += dp.Value
+	}
+	return total
+}
@@ -0,0 +1,130 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestBoard(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	inFlight, _ := meter.Int64UpDownCounter("assistant.reply.in_flight")
+	queued, _ := meter.Int64UpDownCounter("openai.requests.queued")
+	duration, _ := meter.Float64Histogram("openai.requests.duration", metric.WithExplicitBucketBoundaries(100, 1000, 5000, 20000))
+	lookups, _ := meter.Int64Counter("cache.lookups")
+	_, _ = meter.Float64ObservableGauge("tool.execution.error_ratio", metric.WithFloat64Callback(func(_ context.Context, o metric.Float64Observer) error {
+		o.Observe(0.25, metric.WithAttributes(attribute.String("tool.name", "search_flights")))
+		return nil
+	}))
+	ctx := context.Background()
+
+	lookup := func(n int, hit bool) {
+		lookups.Add(ctx, int64(n), metric.WithAttributes(attribute.String("cache.name", "flights"), attribute.Bool("cache.hit", hit)))
+	}
+	calls := func(n int, ms float64) {
+		for range n {
+			duration.Record(ctx, ms, metric.WithAttributes(attribute.String("model", "gpt-4.1")))
+		}
+	}
+
+	b := NewBoard(reader, map[string]QueueDepth{
+		"replies":        func(context.Context) (int64, error) { return 4, nil },
+		"replies:nordic": func(context.Context) (int64, error) { return 0, errors.New("unreachable") },
+	})
+	now := time.Date(2025, 10, 18, 10, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	// Calls and lookups older than the window don't count
+	calls(50, 15000)
+	lookup(10, false)
+	b.Tick(ctx)
+	now = now.Add(3 * time.Minute)
+	b.Tick(ctx)
+
+	calls(90, 800)
+	calls(10, 4000)
+	lookup(30, true)
+	lookup(10, false)
+	inFlight.Add(ctx, 3)
+	inFlight.Add(ctx, -1)
+	queued.Add(ctx, 2)
+	now = now.Add(3 * time.Minute)
+	b.Tick(ctx)
+
+	st, err := b.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Replies.InFlight != 2 {
+		t.Errorf("replies in flight = %d, want 2", st.Replies.InFlight)
+	}
+	if st.OpenAI.Calls != 100 || st.OpenAI.LatencyMs != (Percentiles{P50: 1000, P90: 1000, P99: 5000}) {
+		t.Errorf("OpenAI = %+v, want the 100 calls of the window", st.OpenAI)
+	}
+	if c := st.Caches["flights"]; c != (Cache{Lookups: 40, Hits: 30, HitRate: 0.75}) {
+		t.Errorf("flights cache = %+v, want 30 hits of 40 lookups", c)
+	}
+	if tool := st.Tools["search_flights"]; tool.ErrorRate != 0.25 {
+		t.Errorf("search_flights = %+v, want a 0.25 error rate", tool)
+	}
+	if len(st.Queues) != 2 || st.Queues["replies"] != 4 || st.Queues["openai_slots"] != 2 {
+		t.Errorf("queues = %v, want the replies and OpenAI slots queued, without the failing one", st.Queues)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	bounds := []float64{100, 1000}
+	for _, tc := range []struct {
+		counts []uint64
+		q      float64
+		want   float64
+	}{
+		{[]uint64{0, 0, 0}, 0.5, 0},
+		{[]uint64{5, 5, 0}, 0.5, 100},
+		{[]uint64{5, 5, 0}, 0.9, 1000},
+		{[]uint64{0, 0, 3}, 0.5, 1000},
+	} {
+		if got := quantile(bounds, tc.counts, tc.q); got != tc.want {
+			t.Errorf("quantile(%v, %v) = %v, want %v", tc.counts, tc.q, got, tc.want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	_ = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	h := Handler("secret", NewBoard(reader, nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal/status", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want 401", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/internal/status", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	h.ServeHTTP(rec, req)
+	var st Status
+	if err := json.NewDecoder(rec.Body).Decode(&st); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, error = %v", rec.Code, err)
+	}
+	if st.Window != "5m0s" || st.OpenAI.Calls != 0 {
+		t.Errorf("status = %+v, want an idle server", st)
+	}
+
+	rec = httptest.NewRecorder()
+	Handler("", nil).ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("without admin token: status = %d, want 404", rec.Code)
+	}
+}
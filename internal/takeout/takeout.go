@@ -0,0 +1,192 @@
+// Package takeout packs all the data of a user into a bundle they can download, and
+// restores it, for data portability: a zip of JSON files, one per kind of data, in the
+// relaxed Extended JSON of Mongo so that it reads like plain JSON and restores as it was
+// stored.
+package takeout
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/acai-travel/tech-challenge/internal/profile"
+	"github.com/acai-travel/tech-challenge/internal/reminder"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// Version is the version of the bundles written, the latest one Read accepts.
+	Version = 1
+
+	ContentType = "application/zip"
+
+	// MaxSize bounds the bundles Read accepts, and MaxUnpackedSize what they unpack to.
+	MaxSize         = 32 << 20
+	MaxUnpackedSize = 256 << 20
+)
+
+// ErrInvalid is returned reading a bundle that isn't one, or is too large.
+var ErrInvalid = errors.New("invalid takeout bundle")
+
+// Bundle is all the data of a user. Each field is written to its own file, named after
+// its bson key.
+type Bundle struct {
+	Manifest Manifest `bson:"manifest"`
+	// Profile is nil for users who never stored one.
+	Profile       *profile.Profile       `bson:"profile,omitempty"`
+	Conversations []*model.Conversation  `bson:"conversations"`
+	Itineraries   []*itinerary.Itinerary `bson:"itineraries"`
+	Reminders     []*reminder.Reminder   `bson:"reminders"`
+}
+
+// Manifest describes a bundle.
+type Manifest struct {
+	Version    int       `bson:"version"`
+	UserID     string    `bson:"user_id"`
+	ExportedAt time.Time `bson:"exported_at"`
+}
+
+// Filename returns the name of a bundle exported at the given time, e.g.
+// "takeout-2025-06-01.zip".
+func Filename(at time.Time) string {
+	return "takeout-" + at.UTC().Format("2006-01-02") + ".zip"
+}
+
+// Write writes b to w as a zip.
+func Write(w io.Writer, b *Bundle) error {
+	data, err := bson.Marshal(b)
+	if err != nil {
+		return err
+	}
+	var doc bson.D
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, e := range doc {
+		// Documents only at the top level of Extended JSON, so each file wraps its field
+		content, err := bson.MarshalExtJSONIndent(bson.D{e}, false, false, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", e.Key, err)
+		}
+		f, err := zw.Create(e.Key + ".json")
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Read reads a bundle written by Write, of the Version or an earlier one.
+func Read(data []byte) (*Bundle, error) {
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalid, MaxSize)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	var doc bson.D
+	var unpacked int64
+	for _, f := range zr.File {
+		if path.Ext(f.Name) != ".json" || strings.Contains(f.Name, "/") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		// The sizes in the zip can't be trusted, only what it unpacks to
+		content, err := io.ReadAll(io.LimitReader(rc, MaxUnpackedSize-unpacked+1))
+		_ = rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, f.Name, err)
+		}
+		if unpacked += int64(len(content)); unpacked > MaxUnpackedSize {
+			return nil, fmt.Errorf("%w: unpacks to more than %d bytes", ErrInvalid, MaxUnpackedSize)
+		}
+
+		var fields bson.D
+		if err := bson.UnmarshalExtJSON(content, false, &fields); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, f.Name, err)
+		}
+		doc = append(doc, fields...)
+	}
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	var b Bundle
+	if err := bson.Unmarshal(raw, &b); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if b.Manifest.Version < 1 || b.Manifest.Version > Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalid, b.Manifest.Version)
+	}
+	return &b, nil
+}
+
+// Assign makes the data of b that of userID, as restored at now: everything gets a new
+// ID, so that restoring a bundle adds copies next to what the user still has rather
+// than conflicting with it. Itineraries of conversations missing from b are dropped.
+// Reminders being sent are scheduled again, and those that came due before now are
+// marked failed rather than delivered late.
+func (b *Bundle) Assign(userID string, now time.Time) {
+	if b.Profile != nil {
+		b.Profile.UserID = userID
+	}
+
+	ids := make(map[primitive.ObjectID]primitive.ObjectID, len(b.Conversations))
+	for _, c := range b.Conversations {
+		id := primitive.NewObjectID()
+		ids[c.ID] = id
+		c.ID = id
+		c.UserID = userID
+		c.Revision = 0
+		// Replies are only generated for requests of the user
+		c.Queued = nil
+		for _, m := range c.Messages {
+			m.ID = primitive.NewObjectID()
+		}
+	}
+
+	itineraries := b.Itineraries[:0]
+	for _, it := range b.Itineraries {
+		id, ok := ids[it.ConversationID]
+		if !ok {
+			continue
+		}
+		it.ConversationID = id
+		it.UserID = userID
+		itineraries = append(itineraries, it)
+	}
+	b.Itineraries = itineraries
+
+	for _, rem := range b.Reminders {
+		rem.ID = primitive.NewObjectID()
+		rem.UserID = userID
+		rem.ConversationID = ids[rem.ConversationID]
+		rem.ClaimedAt = time.Time{}
+		if rem.Status == reminder.StatusSending {
+			rem.Status = reminder.StatusScheduled
+		}
+		if rem.Status == reminder.StatusScheduled && rem.DueAt.Before(now) {
+			rem.Status = reminder.StatusFailed
+			rem.LastError = "due before it was restored"
+		}
+	}
+}
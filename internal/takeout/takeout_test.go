@@ -0,0 +1,135 @@
+package takeout
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/acai-travel/tech-challenge/internal/profile"
+	"github.com/acai-travel/tech-challenge/internal/reminder"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testBundle() *Bundle {
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	conv := &model.Conversation{
+		ID:        primitive.NewObjectID(),
+		UserID:    "alice",
+		Title:     "Weekend in Lisbon",
+		CreatedAt: at,
+		UpdatedAt: at,
+		Revision:  7,
+		Messages: []*model.Message{
+			{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "Where should I eat?", CreatedAt: at},
+			{ID: primitive.NewObjectID(), Role: model.RoleAssistant, Content: "Try Alfama.", CreatedAt: at.Add(time.Minute)},
+		},
+	}
+	return &Bundle{
+		Manifest:      Manifest{Version: Version, UserID: "alice", ExportedAt: at},
+		Profile:       &profile.Profile{UserID: "alice", Email: "alice@example.com", Channels: []string{"email"}, UpdatedAt: at},
+		Conversations: []*model.Conversation{conv},
+		Itineraries: []*itinerary.Itinerary{
+			{ConversationID: conv.ID, UserID: "alice", Items: []itinerary.Item{{ID: primitive.NewObjectID(), Kind: itinerary.KindActivity, Title: "Fado show", StartAt: at, Timezone: "Europe/Lisbon"}}},
+			{ConversationID: primitive.NewObjectID(), UserID: "alice"},
+		},
+		Reminders: []*reminder.Reminder{
+			{ID: primitive.NewObjectID(), UserID: "alice", ConversationID: conv.ID, Message: "Pack", DueAt: at.Add(-time.Hour), Status: reminder.StatusScheduled},
+			{ID: primitive.NewObjectID(), UserID: "alice", Message: "Check in", DueAt: at.Add(time.Hour), Status: reminder.StatusSending},
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	b := testBundle()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, b); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want := []string{"manifest.json", "profile.json", "conversations.json", "itineraries.json", "reminders.json"}
+	if len(names) != len(want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("files = %v, want %v", names, want)
+		}
+	}
+
+	got, err := Read(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Manifest != b.Manifest || got.Profile.Email != "alice@example.com" {
+		t.Errorf("manifest = %+v, profile = %+v", got.Manifest, got.Profile)
+	}
+	if len(got.Conversations) != 1 || len(got.Conversations[0].Messages) != 2 || got.Conversations[0].Messages[1].Content != "Try Alfama." {
+		t.Fatalf("conversations = %+v", got.Conversations)
+	}
+	if c := got.Conversations[0]; c.ID != b.Conversations[0].ID || !c.CreatedAt.Equal(b.Conversations[0].CreatedAt) {
+		t.Errorf("conversation = %+v, want it as written", c)
+	}
+	if len(got.Itineraries) != 2 || got.Itineraries[0].Items[0].Title != "Fado show" || len(got.Reminders) != 2 {
+		t.Errorf("itineraries = %+v, reminders = %+v", got.Itineraries, got.Reminders)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	var newer bytes.Buffer
+	b := testBundle()
+	b.Manifest.Version = Version + 1
+	if err := Write(&newer, b); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{
+		"not a zip":     []byte("hello"),
+		"too large":     make([]byte, MaxSize+1),
+		"newer version": newer.Bytes(),
+	} {
+		if _, err := Read(data); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: Read() error = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+func TestBundle_Assign(t *testing.T) {
+	b := testBundle()
+	oldConv := b.Conversations[0].ID
+	oldMessage := b.Conversations[0].Messages[0].ID
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	b.Assign("bob", now)
+
+	c := b.Conversations[0]
+	if c.ID == oldConv || c.UserID != "bob" || c.Revision != 0 || c.Messages[0].ID == oldMessage {
+		t.Errorf("conversation = %+v, want a copy owned by bob", c)
+	}
+	if b.Profile.UserID != "bob" {
+		t.Errorf("profile user = %q, want bob", b.Profile.UserID)
+	}
+	if len(b.Itineraries) != 1 || b.Itineraries[0].ConversationID != c.ID || b.Itineraries[0].UserID != "bob" {
+		t.Errorf("itineraries = %+v, want the one of the conversation only", b.Itineraries)
+	}
+
+	due, sending := b.Reminders[0], b.Reminders[1]
+	if due.ConversationID != c.ID || due.UserID != "bob" || due.Status != reminder.StatusFailed {
+		t.Errorf("reminder due before the import = %+v, want it failed", due)
+	}
+	if !sending.ConversationID.IsZero() || sending.Status != reminder.StatusScheduled {
+		t.Errorf("reminder being sent = %+v, want it scheduled again", sending)
+	}
+}
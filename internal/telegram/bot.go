@@ -0,0 +1,295 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/acai-travel/tech-challenge/internal/httpx"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/relay"
+	"github.com/google/uuid"
+	"github.com/twitchtv/twirp"
+)
+
+// typingInterval renews the typing indicator of a chat, which lasts five seconds.
+const typingInterval = 4 * time.Second
+
+const helpText = "I'm your travel assistant. Tell me where you'd like to go and I'll help you plan: " +
+	"flights, hotels, weather and what to do there.\n\n" +
+	"/newtrip - start planning another trip\n" +
+	"/weather <place> - get the weather forecast of a place\n" +
+	"/help - show this message"
+
+// Progress follows the steps of replies being generated.
+type Progress interface {
+	// Follow calls step with the message of each step of the reply to the request sent
+	// with attemptID, until the reply is done or ctx is canceled.
+	Follow(ctx context.Context, userID, attemptID string, step func(message string)) error
+}
+
+// Bot answers the messages of Telegram chats with the chat service. Each chat plans its
+// trips as its own user, "telegram:<chat id>", so the members of a group share them.
+type Bot struct {
+	api      *Client
+	chat     pb.ChatService
+	chats    ChatStore
+	progress Progress
+	tenantID string
+	now      func() time.Time
+
+	// locks serializes the messages of each chat, which continue the same conversation
+	locks sync.Map
+}
+
+func NewBot(api *Client, chat pb.ChatService, chats ChatStore, progress Progress, tenantID string) *Bot {
+	return &Bot{api: api, chat: chat, chats: chats, progress: progress, tenantID: tenantID, now: time.Now}
+}
+
+// Run polls the updates of the bot and answers their messages until ctx is canceled.
+func (b *Bot) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	var offset int64
+	for {
+		updates, err := b.api.GetUpdates(ctx, offset)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			wait := 5 * time.Second
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+				wait = apiErr.RetryAfter
+			}
+			slog.WarnContext(ctx, "Failed to get Telegram updates", "error", err, "retry_in", wait)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.ID + 1
+			msg := u.Message
+			if msg == nil || strings.TrimSpace(msg.Text) == "" || msg.From != nil && msg.From.IsBot {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				b.Handle(ctx, msg)
+			}()
+		}
+	}
+}
+
+// Handle answers a message, a command or a message of the trip of its chat.
+func (b *Bot) Handle(ctx context.Context, msg *Message) {
+	mu, _ := b.locks.LoadOrStore(msg.Chat.ID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	chatID := msg.Chat.ID
+	text := strings.TrimSpace(msg.Text)
+	command, args := parseCommand(text)
+
+	var err error
+	switch command {
+	case "":
+		err = b.ask(ctx, chatID, text)
+	case "start", "help":
+		_, err = b.api.SendMessage(ctx, chatID, helpText, nil)
+	case "newtrip":
+		if err = b.chats.SaveChat(ctx, chatID, "", b.now()); err != nil {
+			break
+		}
+		if args == "" {
+			_, err = b.api.SendMessage(ctx, chatID, "Where to next? Tell me about the trip: destination, dates and who's coming.", nil)
+			break
+		}
+		err = b.ask(ctx, chatID, args)
+	case "weather":
+		if args == "" {
+			_, err = b.api.SendMessage(ctx, chatID, "Which place? For example: /weather Lisbon", nil)
+			break
+		}
+		err = b.ask(ctx, chatID, "What's the weather forecast in "+args+"?")
+	default:
+		_, err = b.api.SendMessage(ctx, chatID, "I don't know that command.\n\n"+helpText, nil)
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to answer Telegram message", "telegram_chat_id", chatID, "error", err)
+		_, _ = b.api.SendMessage(ctx, chatID, "Sorry, something went wrong. Please try again in a moment.", nil)
+	}
+}
+
+// parseCommand splits a command like "/weather@AcaiBot Lisbon" into its name and
+// arguments. Messages that are not commands have no name.
+func parseCommand(text string) (string, string) {
+	if !strings.HasPrefix(text, "/") {
+		return "", ""
+	}
+	command, args, _ := strings.Cut(text[1:], " ")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), strings.TrimSpace(args)
+}
+
+// ask sends text to the conversation of the chat, starting one if it has none, and
+// sends the reply back. The steps of the reply are shown in a message replaced by the
+// reply once ready.
+func (b *Bot) ask(ctx context.Context, chatID int64, text string) error {
+	state, err := b.chats.DescribeChat(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat: %w", err)
+	}
+	conversationID := ""
+	if state != nil {
+		conversationID = state.ConversationID
+	}
+
+	userID := "telegram:" + strconv.FormatInt(chatID, 10)
+	ctx, err = b.withIdentity(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	attemptID := uuid.NewString()
+	status := b.showProgress(ctx, chatID, userID, attemptID)
+
+	newID, out, err := relay.Send(ctx, b.chat, conversationID, text, attemptID)
+	if err != nil {
+		status.stop()
+		return err
+	}
+	if newID != conversationID {
+		if err := b.chats.SaveChat(ctx, chatID, newID, b.now()); err != nil {
+			status.stop()
+			return fmt.Errorf("failed to save chat: %w", err)
+		}
+	}
+
+	messageID := status.stop()
+	return b.send(ctx, chatID, messageID, out.GetReply(), out.GetSuggestions())
+}
+
+// withIdentity returns ctx with the headers calling the chat service as userID, of the
+// tenant of the bot.
+func (b *Bot) withIdentity(ctx context.Context, userID string) (context.Context, error) {
+	header := make(http.Header)
+	header.Set(httpx.UserIDHeader, userID)
+	if b.tenantID != "" {
+		header.Set(httpx.TenantIDHeader, b.tenantID)
+	}
+	return twirp.WithHTTPRequestHeaders(ctx, header)
+}
+
+// send sends reply to a chat, in as many messages as it takes, replacing the message
+// messageID if set. Suggestions are offered as buttons under the last one.
+func (b *Bot) send(ctx context.Context, chatID, messageID int64, reply string, suggestions []string) error {
+	// Telegram counts the UTF-16 code units of messages
+	parts := relay.Split(reply, MaxMessage, utf16.RuneLen)
+	if len(parts) == 0 {
+		parts = []string{"…"}
+	}
+	for i, part := range parts {
+		var keyboard []string
+		if i == len(parts)-1 {
+			keyboard = suggestions
+		}
+		// Edited messages can't have a keyboard
+		if i == 0 && messageID != 0 && len(keyboard) == 0 {
+			if err := b.api.EditMessage(ctx, chatID, messageID, part); err == nil {
+				continue
+			}
+		}
+		if i == 0 && messageID != 0 {
+			_ = b.api.DeleteMessage(ctx, chatID, messageID)
+		}
+		if _, err := b.api.SendMessage(ctx, chatID, part, keyboard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// progressStatus shows the progress of a reply in a chat.
+type progressStatus struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	messageID int64
+}
+
+// stop stops showing the progress, and returns the message showing the last step, if
+// any.
+func (s *progressStatus) stop() int64 {
+	s.cancel()
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messageID
+}
+
+// showProgress shows the bot typing, and the steps of the reply in a message edited as
+// they come, until stopped.
+func (b *Bot) showProgress(ctx context.Context, chatID int64, userID, attemptID string) *progressStatus {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s := &progressStatus{cancel: cancel, done: make(chan struct{})}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(typingInterval)
+		defer ticker.Stop()
+		for {
+			_ = b.api.Typing(ctx, chatID)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if b.progress != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := b.progress.Follow(ctx, userID, attemptID, func(step string) {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				if s.messageID == 0 {
+					id, err := b.api.SendMessage(ctx, chatID, step, nil)
+					if err == nil {
+						s.messageID = id
+					}
+					return
+				}
+				_ = b.api.EditMessage(ctx, chatID, s.messageID, step)
+			})
+			if err != nil && ctx.Err() == nil {
+				slog.WarnContext(ctx, "Failed to follow reply progress", "telegram_chat_id", chatID, "error", err)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(s.done)
+	}()
+	return s
+}
@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/httpx"
+	"github.com/acai-travel/tech-challenge/internal/progress"
+)
+
+// ProgressStream follows replies with the server-sent events of the chat service's
+// /progress/<attempt_id> streams.
+type ProgressStream struct {
+	baseURL  string
+	tenantID string
+	http     *http.Client
+}
+
+func NewProgressStream(cfg *Config) *ProgressStream {
+	return &ProgressStream{baseURL: strings.TrimSuffix(cfg.ChatURL, "/"), tenantID: cfg.TenantID, http: http.DefaultClient}
+}
+
+func (p *ProgressStream) Follow(ctx context.Context, userID, attemptID string, step func(message string)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/progress/"+attemptID, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(httpx.UserIDHeader, userID)
+	if p.tenantID != "" {
+		req.Header.Set(httpx.TenantIDHeader, p.tenantID)
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("progress stream returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var e progress.Event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			continue
+		}
+		if e.Final() {
+			return nil
+		}
+		if e.Type == progress.EventStep && e.Message != "" {
+			step(e.Message)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
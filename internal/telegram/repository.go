@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName     = "github.com/acai-travel/tech-challenge/internal/telegram"
+	chatCollection = "telegram_chats"
+)
+
+// ChatState is the trip a Telegram chat is planning.
+type ChatState struct {
+	ChatID int64 `bson:"_id"`
+	// ConversationID is empty until the chat starts a trip, and after /newtrip
+	ConversationID string    `bson:"conversation_id,omitempty"`
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+// ChatStore stores the conversations of chats.
+type ChatStore interface {
+	// DescribeChat returns the state of a chat, nil if it never planned a trip.
+	DescribeChat(ctx context.Context, chatID int64) (*ChatState, error)
+	// SaveChat sets the conversation of a chat, empty to start over.
+	SaveChat(ctx context.Context, chatID int64, conversationID string, now time.Time) error
+}
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) DescribeChat(ctx context.Context, chatID int64) (*ChatState, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeChat")
+	span.SetAttributes(attribute.String("telegram.chat_id", strconv.FormatInt(chatID, 10)))
+	defer span.End()
+
+	var state ChatState
+	err := r.conn.Collection(chatCollection).FindOne(ctx, bson.M{"_id": chatID}).Decode(&state)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "no chat")
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to describe chat")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "chat described")
+	return &state, nil
+}
+
+func (r *Repository) SaveChat(ctx context.Context, chatID int64, conversationID string, now time.Time) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SaveChat")
+	span.SetAttributes(attribute.String("telegram.chat_id", strconv.FormatInt(chatID, 10)))
+	defer span.End()
+
+	_, err := r.conn.Collection(chatCollection).UpdateOne(ctx,
+		bson.M{"_id": chatID},
+		bson.M{"$set": bson.M{"conversation_id": conversationID, "updated_at": now}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to save chat")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "chat saved")
+	return nil
+}
@@ -0,0 +1,195 @@
+// Package telegram bridges Telegram chats to the chat service: each chat with the bot
+// plans a trip in a conversation, and replies are sent back as they are ready, with
+// the steps of the assistant shown while it works.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the URL of the Telegram Bot API.
+const DefaultAPIURL = "https://api.telegram.org"
+
+// MaxMessage is the longest text of a Telegram message, in characters.
+const MaxMessage = 4096
+
+// Config configures the bot.
+type Config struct {
+	// Token is the token BotFather gave the bot
+	Token string
+	// APIURL is the URL of the Bot API, DefaultAPIURL unless testing
+	APIURL string
+	// ChatURL is the URL of the chat service
+	ChatURL string
+	// TenantID is the tenant the bot's users plan with, empty for the default tenant
+	TenantID string
+}
+
+// ConfigFromEnv returns the configuration of TELEGRAM_BOT_TOKEN, API_URL and
+// TELEGRAM_TENANT_ID.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		Token:    strings.TrimSpace(os.Getenv("TELEGRAM_BOT_TOKEN")),
+		APIURL:   DefaultAPIURL,
+		ChatURL:  os.Getenv("API_URL"),
+		TenantID: os.Getenv("TELEGRAM_TENANT_ID"),
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("TELEGRAM_BOT_TOKEN is required")
+	}
+	if cfg.ChatURL == "" {
+		cfg.ChatURL = "http://localhost:8080"
+	}
+	return cfg, nil
+}
+
+// Update is an update of the Bot API; the bot only handles messages.
+type Update struct {
+	ID      int64    `json:"update_id"`
+	Message *Message `json:"message,omitempty"`
+}
+
+// Message is a message of a chat.
+type Message struct {
+	ID   int64  `json:"message_id"`
+	From *User  `json:"from,omitempty"`
+	Chat Chat   `json:"chat"`
+	Text string `json:"text,omitempty"`
+}
+
+// User is a Telegram user.
+type User struct {
+	ID    int64 `json:"id"`
+	IsBot bool  `json:"is_bot"`
+}
+
+// Chat is a private chat with the bot, or a group the bot is in.
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+// Client calls the Bot API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewClient(cfg *Config) *Client {
+	// Long polls last pollTimeout, and the client gives them some slack
+	return &Client{
+		baseURL: strings.TrimSuffix(cfg.APIURL, "/") + "/bot" + cfg.Token,
+		http:    &http.Client{Timeout: pollTimeout + 10*time.Second},
+	}
+}
+
+// pollTimeout is how long GetUpdates waits for updates.
+const pollTimeout = 30 * time.Second
+
+// GetUpdates returns the updates from offset on, waiting up to pollTimeout for some.
+func (c *Client) GetUpdates(ctx context.Context, offset int64) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(pollTimeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// SendMessage sends text to a chat, with keyboard as buttons answering it if any, and
+// returns the ID of the message.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string, keyboard []string) (int64, error) {
+	params := map[string]any{"chat_id": chatID, "text": text}
+	if len(keyboard) > 0 {
+		rows := make([][]map[string]string, len(keyboard))
+		for i, k := range keyboard {
+			rows[i] = []map[string]string{{"text": k}}
+		}
+		params["reply_markup"] = map[string]any{"keyboard": rows, "one_time_keyboard": true, "resize_keyboard": true}
+	}
+	var msg Message
+	if err := c.call(ctx, "sendMessage", params, &msg); err != nil {
+		return 0, err
+	}
+	return msg.ID, nil
+}
+
+// EditMessage replaces the text of a message sent by the bot.
+func (c *Client) EditMessage(ctx context.Context, chatID, messageID int64, text string) error {
+	return c.call(ctx, "editMessageText", map[string]any{"chat_id": chatID, "message_id": messageID, "text": text}, nil)
+}
+
+// DeleteMessage deletes a message sent by the bot.
+func (c *Client) DeleteMessage(ctx context.Context, chatID, messageID int64) error {
+	return c.call(ctx, "deleteMessage", map[string]any{"chat_id": chatID, "message_id": messageID}, nil)
+}
+
+// Typing shows the bot as typing in a chat, for five seconds or until it sends a message.
+func (c *Client) Typing(ctx context.Context, chatID int64) error {
+	return c.call(ctx, "sendChatAction", map[string]any{"chat_id": chatID, "action": "typing"}, nil)
+}
+
+// APIError is an error returned by the Bot API.
+type APIError struct {
+	Code        int
+	Description string
+	// RetryAfter is how long to wait before retrying, when rate limited
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram: %d %s", e.Code, e.Description)
+}
+
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The URL holds the token, which must not end up in logs
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("telegram %s: invalid response with status %d: %w", method, resp.StatusCode, err)
+	}
+	if !out.OK {
+		return &APIError{Code: out.ErrorCode, Description: out.Description, RetryAfter: time.Duration(out.Parameters.RetryAfter) * time.Second}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(out.Result, result)
+}
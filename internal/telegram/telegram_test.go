@@ -0,0 +1,232 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/httpx"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/progress"
+	"github.com/twitchtv/twirp"
+)
+
+// sentMessage is a call of the fake Bot API.
+type sentMessage struct {
+	Method   string
+	Text     string
+	Keyboard bool
+}
+
+// fakeTelegram records the calls of the Bot API.
+type fakeTelegram struct {
+	mu    sync.Mutex
+	calls []sentMessage
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Text        string          `json:"text"`
+		ReplyMarkup json.RawMessage `json:"reply_markup"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&params)
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if !strings.HasPrefix(r.URL.Path, "/botsecret/") {
+		w.Write([]byte(`{"ok": false, "error_code": 401, "description": "Unauthorized"}`))
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if method != "sendChatAction" {
+		f.calls = append(f.calls, sentMessage{Method: method, Text: params.Text, Keyboard: len(params.ReplyMarkup) > 0})
+	}
+	w.Write([]byte(`{"ok": true, "result": {"message_id": 42, "chat": {"id": 7}}}`))
+}
+
+func (f *fakeTelegram) sent() []sentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]sentMessage(nil), f.calls...)
+}
+
+// fakeChat answers every message with the same reply, from the conversations it knows.
+type fakeChat struct {
+	pb.ChatService
+	conversations map[string]bool
+	messages      []string
+	userID        string
+	// stepped is closed once the step of the reply is shown
+	stepped chan struct{}
+}
+
+func (f *fakeChat) StartConversation(ctx context.Context, req *pb.StartConversationRequest) (*pb.StartConversationResponse, error) {
+	header, _ := twirp.HTTPRequestHeaders(ctx)
+	f.userID = header.Get(httpx.UserIDHeader)
+	<-f.stepped
+	f.messages = append(f.messages, req.GetMessage())
+	f.conversations["conv-2"] = true
+	return &pb.StartConversationResponse{ConversationId: "conv-2", Reply: "Lisbon is lovely in October.", Suggestions: []string{"Find flights"}}, nil
+}
+
+func (f *fakeChat) ContinueConversation(ctx context.Context, req *pb.ContinueConversationRequest) (*pb.ContinueConversationResponse, error) {
+	if !f.conversations[req.GetConversationId()] {
+		return nil, twirp.NotFoundError("conversation not found")
+	}
+	<-f.stepped
+	f.messages = append(f.messages, req.GetMessage())
+	return &pb.ContinueConversationResponse{Reply: "Sunny, 22°C."}, nil
+}
+
+// memoryChats stores chats in memory.
+type memoryChats map[int64]string
+
+func (m memoryChats) DescribeChat(_ context.Context, chatID int64) (*ChatState, error) {
+	id, ok := m[chatID]
+	if !ok {
+		return nil, nil
+	}
+	return &ChatState{ChatID: chatID, ConversationID: id}, nil
+}
+
+func (m memoryChats) SaveChat(_ context.Context, chatID int64, conversationID string, _ time.Time) error {
+	m[chatID] = conversationID
+	return nil
+}
+
+// stepProgress reports a single step of the reply, then closes stepped.
+type stepProgress struct {
+	stepped chan struct{}
+}
+
+func (p stepProgress) Follow(_ context.Context, _, _ string, step func(string)) error {
+	step("Checking the weather forecast…")
+	close(p.stepped)
+	return nil
+}
+
+func newTestBot(t *testing.T) (*Bot, *fakeTelegram, *fakeChat, memoryChats) {
+	t.Helper()
+	tg := &fakeTelegram{}
+	srv := httptest.NewServer(tg)
+	t.Cleanup(srv.Close)
+
+	stepped := make(chan struct{})
+	chat := &fakeChat{conversations: map[string]bool{"conv-1": true}, stepped: stepped}
+	chats := memoryChats{}
+	api := NewClient(&Config{Token: "secret", APIURL: srv.URL})
+	return NewBot(api, chat, chats, stepProgress{stepped: stepped}, ""), tg, chat, chats
+}
+
+func TestBot_Handle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("starts a conversation", func(t *testing.T) {
+		bot, tg, chat, chats := newTestBot(t)
+		bot.Handle(ctx, &Message{Chat: Chat{ID: 7}, Text: "Weekend in Lisbon?"})
+
+		if chats[7] != "conv-2" {
+			t.Errorf("chat is on conversation %q, want conv-2", chats[7])
+		}
+		if chat.userID != "telegram:7" {
+			t.Errorf("chat service called as %q", chat.userID)
+		}
+		// The step is shown, then replaced by the reply with its suggestions
+		got := tg.sent()
+		want := []sentMessage{
+			{Method: "sendMessage", Text: "Checking the weather forecast…"},
+			{Method: "deleteMessage"},
+			{Method: "sendMessage", Text: "Lisbon is lovely in October.", Keyboard: true},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("calls = %+v, want %+v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("call %d = %+v, want %+v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("continues the conversation of the chat", func(t *testing.T) {
+		bot, tg, chat, chats := newTestBot(t)
+		chats[7] = "conv-1"
+		bot.Handle(ctx, &Message{Chat: Chat{ID: 7}, Text: "/weather@AcaiBot Lisbon"})
+
+		if len(chat.messages) != 1 || chat.messages[0] != "What's the weather forecast in Lisbon?" {
+			t.Errorf("messages = %q", chat.messages)
+		}
+		got := tg.sent()
+		if last := got[len(got)-1]; last.Method != "editMessageText" || last.Text != "Sunny, 22°C." {
+			t.Errorf("reply sent with %+v", last)
+		}
+	})
+
+	t.Run("starts over when the conversation is gone", func(t *testing.T) {
+		bot, _, chat, chats := newTestBot(t)
+		chats[7] = "deleted"
+		bot.Handle(ctx, &Message{Chat: Chat{ID: 7}, Text: "Hello again"})
+
+		if chats[7] != "conv-2" || len(chat.messages) != 1 {
+			t.Errorf("chat is on conversation %q after %q", chats[7], chat.messages)
+		}
+	})
+
+	t.Run("starts a new trip", func(t *testing.T) {
+		bot, tg, chat, chats := newTestBot(t)
+		chats[7] = "conv-1"
+		bot.Handle(ctx, &Message{Chat: Chat{ID: 7}, Text: "/newtrip"})
+
+		if chats[7] != "" || len(chat.messages) != 0 {
+			t.Errorf("chat is still on conversation %q", chats[7])
+		}
+		if got := tg.sent(); len(got) != 1 || !strings.HasPrefix(got[0].Text, "Where to next?") {
+			t.Errorf("calls = %+v", got)
+		}
+	})
+
+	t.Run("asks for the place of the weather", func(t *testing.T) {
+		bot, tg, chat, _ := newTestBot(t)
+		bot.Handle(ctx, &Message{Chat: Chat{ID: 7}, Text: "/weather"})
+
+		if len(chat.messages) != 0 {
+			t.Errorf("messages = %q", chat.messages)
+		}
+		if got := tg.sent(); len(got) != 1 || !strings.Contains(got[0].Text, "Which place?") {
+			t.Errorf("calls = %+v", got)
+		}
+	})
+}
+
+func TestClient_Error(t *testing.T) {
+	srv := httptest.NewServer(&fakeTelegram{})
+	defer srv.Close()
+
+	api := NewClient(&Config{Token: "wrong", APIURL: srv.URL})
+	_, err := api.SendMessage(context.Background(), 7, "Hi", nil)
+	if apiErr, ok := err.(*APIError); !ok || apiErr.Code != 401 {
+		t.Errorf("SendMessage() error = %v, want a 401 APIError", err)
+	}
+}
+
+func TestProgressStream_Follow(t *testing.T) {
+	hub := progress.NewHub()
+	srv := httptest.NewServer(httpx.Identity()(progress.Handler(hub)))
+	defer srv.Close()
+
+	hub.Publish("telegram:7", "attempt-1", progress.Event{Type: progress.EventStep, Tool: "get_weather_forecast", Message: "Checking the weather forecast…"})
+	hub.Publish("telegram:7", "attempt-1", progress.Event{Type: progress.EventDone})
+
+	var steps []string
+	err := NewProgressStream(&Config{ChatURL: srv.URL}).Follow(context.Background(), "telegram:7", "attempt-1", func(step string) {
+		steps = append(steps, step)
+	})
+	if err != nil || len(steps) != 1 || steps[0] != "Checking the weather forecast…" {
+		t.Errorf("Follow() = %v, steps %q", err, steps)
+	}
+}
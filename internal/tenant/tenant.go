@@ -0,0 +1,180 @@
+// Package tenant configures the travel brands served by one deployment. Each tenant gets
+// its own Mongo database, Amadeus credentials and assistant settings, and requests are
+// routed to the tenant named by their X-Tenant-ID header.
+package tenant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/pdf"
+	"github.com/acai-travel/tech-challenge/internal/slack"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"github.com/acai-travel/tech-challenge/internal/whatsapp"
+)
+
+// validID are tenant IDs: short lowercase slugs, safe in database names.
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// PathPrefix routes requests under /tenants/<id>/ to tenant <id>, for clients that can't
+// set headers, like browsers opening share links or progress streams.
+const PathPrefix = "/tenants/"
+
+// Config is the configuration of a tenant. Empty fields keep the deployment's defaults.
+type Config struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Database is the Mongo database of the tenant's data, "<default database>_<id>"
+	// when empty.
+	Database string `json:"database,omitempty"`
+	// Amadeus replaces AMADEUS_API_KEY and AMADEUS_API_SECRET for the tenant's tools.
+	Amadeus *tools.AmadeusCredentials `json:"amadeus,omitempty"`
+	// ReplyModel is the OpenAI model replying to the tenant's users.
+	ReplyModel string `json:"reply_model,omitempty"`
+	// Instructions are added to the assistant's system prompt, typically to speak for
+	// the brand.
+	Instructions string `json:"instructions,omitempty"`
+	// ToolPolicy restricts the tools of the tenant's users, on top of TOOL_POLICY_FILE.
+	ToolPolicy *tools.Policy `json:"tool_policy,omitempty"`
+	// Branding brands the tenant's printable trip documents. Its name defaults to Name.
+	Branding *pdf.Branding `json:"branding,omitempty"`
+	// WhatsApp is the tenant's WhatsApp Business number, replacing the WHATSAPP_*
+	// variables.
+	WhatsApp *whatsapp.Config `json:"whatsapp,omitempty"`
+	// Slack is the tenant's Slack app, replacing the SLACK_* variables.
+	Slack *slack.Config `json:"slack,omitempty"`
+}
+
+// DatabaseName returns the name of the tenant's Mongo database, next to the default
+// database base.
+func (c Config) DatabaseName(base string) string {
+	if c.Database != "" {
+		return c.Database
+	}
+	return base + "_" + c.ID
+}
+
+// Validate reports whether the configuration is usable.
+func (c Config) Validate() error {
+	if !validID.MatchString(c.ID) {
+		return fmt.Errorf("tenant ID must be a lowercase slug of at most 32 characters, got %q", c.ID)
+	}
+	if c.Amadeus != nil && (c.Amadeus.APIKey == "" || c.Amadeus.APISecret == "") {
+		return fmt.Errorf("tenant %q: amadeus needs an api_key and an api_secret", c.ID)
+	}
+	if c.ToolPolicy != nil {
+		if err := c.ToolPolicy.Validate(); err != nil {
+			return fmt.Errorf("tenant %q: %w", c.ID, err)
+		}
+	}
+	if c.Branding != nil {
+		if err := c.Branding.Validate(); err != nil {
+			return fmt.Errorf("tenant %q: branding: %w", c.ID, err)
+		}
+	}
+	if c.WhatsApp != nil {
+		if err := c.WhatsApp.Validate(); err != nil {
+			return fmt.Errorf("tenant %q: %w", c.ID, err)
+		}
+	}
+	if c.Slack != nil {
+		if err := c.Slack.Validate(); err != nil {
+			return fmt.Errorf("tenant %q: %w", c.ID, err)
+		}
+	}
+	return nil
+}
+
+// DocumentBranding returns the branding of the tenant's printable documents.
+func (c Config) DocumentBranding() pdf.Branding {
+	var b pdf.Branding
+	if c.Branding != nil {
+		b = *c.Branding
+	}
+	if b.Name == "" {
+		b.Name = c.Name
+	}
+	return b
+}
+
+// LoadFromEnv reads the tenants from the JSON array in the file at TENANTS_FILE. There
+// are none when it is unset, and every request goes to the default tenant.
+func LoadFromEnv() ([]Config, error) {
+	path := os.Getenv("TENANTS_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read TENANTS_FILE: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse reads tenants from a JSON array, checking that they are valid and that their
+// IDs and databases are unique.
+func Parse(data []byte) ([]Config, error) {
+	var tenants []Config
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("parse tenants: %w", err)
+	}
+
+	ids := make(map[string]bool, len(tenants))
+	databases := make(map[string]bool, len(tenants))
+	for _, t := range tenants {
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+		if ids[t.ID] {
+			return nil, fmt.Errorf("tenant %q is configured twice", t.ID)
+		}
+		ids[t.ID] = true
+
+		if t.Database != "" {
+			if databases[t.Database] {
+				return nil, fmt.Errorf("tenant %q: database %q is used by another tenant", t.ID, t.Database)
+			}
+			databases[t.Database] = true
+		}
+	}
+	return tenants, nil
+}
+
+// Handler routes requests to the handler of their tenant, named by the request context
+// or the path under PathPrefix, and requests without one to fallback, the default
+// tenant's. Requests to unknown tenants, or naming two, are not found.
+func Handler(tenants map[string]http.Handler, fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := auth.TenantID(r.Context())
+		if rest, ok := strings.CutPrefix(r.URL.Path, PathPrefix); ok {
+			pathID, rest, _ := strings.Cut(rest, "/")
+			if id != "" && id != pathID {
+				http.Error(w, "unknown tenant", http.StatusNotFound)
+				return
+			}
+			id = pathID
+
+			r2 := r.Clone(auth.WithTenantID(r.Context(), id))
+			r2.URL.Path = "/" + rest
+			r2.URL.RawPath = ""
+			r = r2
+		}
+		if id == "" {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		h, ok := tenants[id]
+		if !ok {
+			http.Error(w, "unknown tenant", http.StatusNotFound)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,95 @@
+package tenant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "tenants", data: `[{"id": "sunny-tours", "amadeus": {"api_key": "k", "api_secret": "s"}}, {"id": "nordic", "database": "nordic"}]`},
+		{name: "none", data: `[]`},
+		{name: "invalid ID", data: `[{"id": "Sunny Tours"}]`, wantErr: true},
+		{name: "missing ID", data: `[{"name": "Sunny Tours"}]`, wantErr: true},
+		{name: "duplicate ID", data: `[{"id": "nordic"}, {"id": "nordic"}]`, wantErr: true},
+		{name: "shared database", data: `[{"id": "a", "database": "brands"}, {"id": "b", "database": "brands"}]`, wantErr: true},
+		{name: "partial credentials", data: `[{"id": "nordic", "amadeus": {"api_key": "k"}}]`, wantErr: true},
+		{name: "tool policy", data: `[{"id": "nordic", "tool_policy": {"rules": [{"deny": ["search_transfers"]}]}}]`},
+		{name: "invalid tool policy", data: `[{"id": "nordic", "tool_policy": {"rules": [{"constraints": {"get_weather_forecast": [{"max": 3}]}}]}}]`, wantErr: true},
+		{name: "branding", data: `[{"id": "nordic", "branding": {"name": "Nordic Trails", "color": "#1d4e89"}}]`},
+		{name: "invalid branding color", data: `[{"id": "nordic", "branding": {"color": "blue"}}]`, wantErr: true},
+		{name: "whatsapp", data: `[{"id": "nordic", "whatsapp": {"phone_number_id": "1234", "access_token": "t", "app_secret": "s", "verify_token": "v"}}]`},
+		{name: "whatsapp without credentials", data: `[{"id": "nordic", "whatsapp": {"phone_number_id": "1234"}}]`, wantErr: true},
+		{name: "slack", data: `[{"id": "nordic", "slack": {"bot_token": "xoxb-1", "signing_secret": "s"}}]`},
+		{name: "slack without signing secret", data: `[{"id": "nordic", "slack": {"bot_token": "xoxb-1"}}]`, wantErr: true},
+		{name: "not an array", data: `{"id": "nordic"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_DatabaseName(t *testing.T) {
+	if got := (Config{ID: "nordic"}).DatabaseName("acai"); got != "acai_nordic" {
+		t.Errorf("DatabaseName() = %q, want %q", got, "acai_nordic")
+	}
+	if got := (Config{ID: "nordic", Database: "nordic"}).DatabaseName("acai"); got != "nordic" {
+		t.Errorf("DatabaseName() = %q, want %q", got, "nordic")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	named := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(name + " " + r.URL.Path))
+		})
+	}
+	h := Handler(map[string]http.Handler{"nordic": named("nordic")}, named("default"))
+
+	tests := []struct {
+		name       string
+		tenant     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "default", path: "/twirp/acai.chat.ChatService/ListConversations", wantStatus: http.StatusOK, wantBody: "default /twirp/acai.chat.ChatService/ListConversations"},
+		{name: "header", tenant: "nordic", path: "/twirp/acai.chat.ChatService/ListConversations", wantStatus: http.StatusOK, wantBody: "nordic /twirp/acai.chat.ChatService/ListConversations"},
+		{name: "unknown header", tenant: "sunny-tours", path: "/twirp/acai.chat.ChatService/ListConversations", wantStatus: http.StatusNotFound},
+		{name: "path", path: "/tenants/nordic/shared/abc", wantStatus: http.StatusOK, wantBody: "nordic /shared/abc"},
+		{name: "path and same header", tenant: "nordic", path: "/tenants/nordic/shared/abc", wantStatus: http.StatusOK, wantBody: "nordic /shared/abc"},
+		{name: "path and other header", tenant: "sunny-tours", path: "/tenants/nordic/shared/abc", wantStatus: http.StatusNotFound},
+		{name: "unknown path", path: "/tenants/sunny-tours/shared/abc", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.tenant != "" {
+				r = r.WithContext(auth.WithTenantID(r.Context(), tt.tenant))
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pricing"
+)
+
+// Hosts of the Amadeus API environments. The test environment serves cached sandbox
+// data with lower rate limits; production needs production credentials.
+const (
+	AmadeusTestHost       = "test.api.amadeus.com"
+	AmadeusProductionHost = "api.amadeus.com"
+)
+
+const (
+	// amadeusMaxAttempts bounds the attempts of a request Amadeus rate-limits.
+	amadeusMaxAttempts = 3
+	// amadeusMaxRetryAfter caps the wait asked for by Retry-After, so a long wait
+	// fails the tool call instead of eating the reply budget.
+	amadeusMaxRetryAfter = 5 * time.Second
+)
+
+// amadeusBackoff is the wait before the first retry without Retry-After, doubled on
+// every attempt.
+var amadeusBackoff = 500 * time.Millisecond
+
+// AmadeusBaseURL returns the base URL of the Amadeus API: AMADEUS_BASE_URL when set,
+// or the host of the AMADEUS_ENV environment, "test" (the default) or "production".
+func AmadeusBaseURL() (string, error) {
+	if u := strings.TrimSpace(os.Getenv("AMADEUS_BASE_URL")); u != "" {
+		return strings.TrimRight(u, "/"), nil
+	}
+
+	switch env := strings.ToLower(strings.TrimSpace(os.Getenv("AMADEUS_ENV"))); env {
+	case "", "test":
+		return "https://" + AmadeusTestHost, nil
+	case "production":
+		return "https://" + AmadeusProductionHost, nil
+	default:
+		return "", fmt.Errorf("unknown AMADEUS_ENV %q, want test or production", env)
+	}
+}
+
+// AmadeusCredentials are the Amadeus API credentials of a tenant, used instead of the
+// environment's.
+type AmadeusCredentials struct {
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+	// BaseURL defaults to the environment's, see AmadeusBaseURL.
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+type amadeusCredentialsKey struct{}
+
+// WithAmadeusCredentials returns middleware making the Amadeus tools use creds instead
+// of AMADEUS_API_KEY and AMADEUS_API_SECRET.
+func WithAmadeusCredentials(creds AmadeusCredentials) Middleware {
+	return func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			return next(context.WithValue(ctx, amadeusCredentialsKey{}, creds), name, args)
+		}
+	}
+}
+
+// amadeusToken returns the base URL of the configured Amadeus environment and an access
+// token for the credentials of the tool call, or else for the credentials in
+// AMADEUS_API_KEY and AMADEUS_API_SECRET.
+func amadeusToken(ctx context.Context, httpClient *http.Client) (baseURL, token string, err error) {
+	baseURL, err = AmadeusBaseURL()
+	if err != nil {
+		return "", "", err
+	}
+
+	apiKey := os.Getenv("AMADEUS_API_KEY")
+	apiSecret := os.Getenv("AMADEUS_API_SECRET")
+	if creds, ok := ctx.Value(amadeusCredentialsKey{}).(AmadeusCredentials); ok {
+		apiKey, apiSecret = creds.APIKey, creds.APISecret
+		if creds.BaseURL != "" {
+			baseURL = strings.TrimRight(creds.BaseURL, "/")
+		}
+	}
+	if apiKey == "" || apiSecret == "" {
+		return "", "", fmt.Errorf("amadeus API credentials not configured - please set AMADEUS_API_KEY and AMADEUS_API_SECRET environment variables")
+	}
+
+	token, err = FetchAmadeusToken(ctx, httpClient, baseURL, apiKey, apiSecret)
+	if err != nil {
+		return "", "", err
+	}
+	return baseURL, token, nil
+}
+
+// doAmadeus sends the request built by newReq, retrying when Amadeus rate-limits it
+// (429) or is briefly unavailable (503). Retries wait for Retry-After when given, or
+// back off exponentially.
+func doAmadeus(ctx context.Context, httpClient *http.Client, newReq func() (*http.Request, error)) (*http.Response, error) {
+	backoff := amadeusBackoff
+	for attempt := 1; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, fmt.Errorf("build request: %w", err)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable || attempt == amadeusMaxAttempts {
+			return resp, nil
+		}
+
+		wait := backoff
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			wait = d
+		}
+		resp.Body.Close()
+		if wait > amadeusMaxRetryAfter {
+			return nil, fmt.Errorf("rate limited by Amadeus, retry after %s", wait)
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// amadeusError describes a failed Amadeus API response, with the message of its first
+// error when it has one.
+func amadeusError(status int, body []byte) error {
+	var apiErr struct {
+		Errors []struct {
+			Detail string `json:"detail"`
+			Title  string `json:"title"`
+		} `json:"errors"`
+	}
+	_ = json.Unmarshal(body, &apiErr)
+
+	if len(apiErr.Errors) > 0 {
+		if msg := cmp.Or(apiErr.Errors[0].Detail, apiErr.Errors[0].Title); msg != "" {
+			return fmt.Errorf("api error (status %d): %s", status, msg)
+		}
+	}
+	return fmt.Errorf("api error: status %d, body: %s", status, string(body))
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(header string) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// rates converts the prices of offers to the currency of the user.
+var rates pricing.Rates = pricing.NewECBRates()
+
+// withConverted formats price, followed by its amount in currency when it is in another
+// currency and the conversion succeeds, like "65.00 GBP ≈ 75.40 EUR".
+func withConverted(ctx context.Context, price pricing.Price, currency string) string {
+	if currency == "" || price.Total.Currency == currency {
+		return price.String()
+	}
+	converted, err := pricing.Convert(ctx, rates, price.Total, currency)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to convert price", "from", price.Total.Currency, "to", currency, "error", err)
+		return price.String()
+	}
+	return price.Converted(converted)
+}
@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAmadeusBaseURL(t *testing.T) {
+	tests := []struct {
+		env, baseURL string
+		want         string
+		wantErr      bool
+	}{
+		{want: "https://test.api.amadeus.com"},
+		{env: "production", want: "https://api.amadeus.com"},
+		{env: "Test", want: "https://test.api.amadeus.com"},
+		{env: "production", baseURL: "http://localhost:9000/", want: "http://localhost:9000"},
+		{env: "staging", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env+tt.baseURL, func(t *testing.T) {
+			t.Setenv("AMADEUS_ENV", tt.env)
+			t.Setenv("AMADEUS_BASE_URL", tt.baseURL)
+
+			got, err := AmadeusBaseURL()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AmadeusBaseURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AmadeusBaseURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchFlightDestinations_RateLimited(t *testing.T) {
+	defer func(b time.Duration) { amadeusBackoff = b }(amadeusBackoff)
+	amadeusBackoff = time.Millisecond
+
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"errors": [{"status": 429, "title": "Too many requests"}]}`, http.StatusTooManyRequests)
+			return
+		}
+		if attempts == 2 {
+			http.Error(w, `{"errors": [{"status": 503, "title": "Service unavailable"}]}`, http.StatusServiceUnavailable)
+			return
+		}
+
+		if got := r.URL.Query().Get("currencyCode"); got != "USD" {
+			t.Errorf("currencyCode = %q, want USD", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		_, _ = fmt.Fprint(w, `{"data": [{"itineraries": [{"segments": [{"departure": {"iataCode": "BCN", "at": "2025-10-18T14:30:00"}, "arrival": {"iataCode": "JFK"}}]}], "price": {"currency": "USD", "total": "420.00"}}]}`)
+	}))
+	defer srv.Close()
+
+	flights, err := FetchFlightDestinations(context.Background(), srv.Client(), srv.URL, "token", FlightQuery{
+		Origin:        "BCN",
+		Destination:   "JFK",
+		DepartureDate: "2025-10-18",
+		Currency:      "USD",
+	})
+	if err != nil {
+		t.Fatalf("FetchFlightDestinations() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("got %d attempts, want 3", attempts)
+	}
+	if len(flights) != 1 || flights[0].Price.String() != "420.00 USD" {
+		t.Errorf("flights = %+v, want one offer at 420.00 USD", flights)
+	}
+}
+
+func TestFetchFlightDestinations_RateLimitExhausted(t *testing.T) {
+	defer func(b time.Duration) { amadeusBackoff = b }(amadeusBackoff)
+	amadeusBackoff = time.Millisecond
+
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, `{"errors": [{"status": 429, "detail": "Rate limit exceeded"}]}`, http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	_, err := FetchFlightDestinations(context.Background(), srv.Client(), srv.URL, "token", FlightQuery{
+		Origin:        "BCN",
+		Destination:   "JFK",
+		DepartureDate: "2025-10-18",
+	})
+	if err == nil {
+		t.Fatal("FetchFlightDestinations() error = nil, want the rate limit error")
+	}
+	if attempts != amadeusMaxAttempts {
+		t.Errorf("got %d attempts, want %d", attempts, amadeusMaxAttempts)
+	}
+}
+
+func TestWithAmadeusCredentials(t *testing.T) {
+	t.Setenv("AMADEUS_API_KEY", "")
+	t.Setenv("AMADEUS_API_SECRET", "")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("client_id") != "tenant-key" || r.PostForm.Get("client_secret") != "tenant-secret" {
+			http.Error(w, `{"error": "invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"access_token": "tenant-token"}`)
+	}))
+	defer srv.Close()
+
+	if _, _, err := amadeusToken(context.Background(), srv.Client()); err == nil {
+		t.Error("amadeusToken() without credentials succeeded, want an error")
+	}
+
+	var baseURL, token string
+	tool := WithAmadeusCredentials(AmadeusCredentials{APIKey: "tenant-key", APISecret: "tenant-secret", BaseURL: srv.URL + "/"})(
+		func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			var err error
+			baseURL, token, err = amadeusToken(ctx, srv.Client())
+			return "", err
+		})
+	if _, err := tool(context.Background(), "get_flight_prices", nil); err != nil {
+		t.Fatalf("amadeusToken() error = %v", err)
+	}
+	if baseURL != srv.URL || token != "tenant-token" {
+		t.Errorf("amadeusToken() = %q, %q, want the tenant's base URL and token", baseURL, token)
+	}
+}
@@ -0,0 +1,269 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/budget"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pricing"
+	"github.com/openai/openai-go/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// setTripBudgetArgs are the arguments of set_trip_budget.
+type setTripBudgetArgs struct {
+	Amount   float64 `json:"amount" description:"Total the user wants to spend on the trip" jsonschema:"required,minimum=1"`
+	Currency string  `json:"currency" description:"ISO 4217 code of the currency of the budget (e.g., 'EUR'). Leave empty for the user's default currency."`
+}
+
+// SetTripBudgetTool sets the target budget of the trip planned in the conversation
+type SetTripBudgetTool struct {
+	conv  *model.Conversation
+	store budget.Store
+	now   func() time.Time
+}
+
+func NewSetTripBudgetTool(conv *model.Conversation, store budget.Store) *SetTripBudgetTool {
+	return &SetTripBudgetTool{conv: conv, store: store, now: time.Now}
+}
+
+func (t *SetTripBudgetTool) Name() string {
+	return "set_trip_budget"
+}
+
+func (t *SetTripBudgetTool) Description() string {
+	return "Sets or changes the total budget of the trip, e.g. when the user says 'I want to spend at most 2000 euros'. Expenses are tracked against it."
+}
+
+func (t *SetTripBudgetTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[setTripBudgetArgs](),
+	})
+}
+
+func (t *SetTripBudgetTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[setTripBudgetArgs](args)
+	if err != nil {
+		return "", err
+	}
+	if payload.Amount <= 0 {
+		return NeedsClarification("amount", "How much do you want to spend on the trip in total?"), nil
+	}
+
+	currency, clarification := budgetCurrency(payload.Currency, "")
+	if clarification != "" {
+		return clarification, nil
+	}
+	if !pricing.ValidCurrency(currency) {
+		return "", fmt.Errorf("currency must be an ISO 4217 code like EUR, got %q", currency)
+	}
+	target := pricing.FromFloat(payload.Amount, currency)
+
+	b, err := t.store.SetTarget(ctx, t.conv.ID, t.conv.UserID, target, t.now())
+	if err != nil {
+		return "", fmt.Errorf("failed to set budget: %w", err)
+	}
+	return budgetStatus(ctx, "Trip budget set to "+target.String()+".", b)
+}
+
+// addExpenseArgs are the arguments of add_expense.
+type addExpenseArgs struct {
+	Category    string  `json:"category" description:"Kind of expense" jsonschema:"required,enum=flight|lodging|transport|activity|food|other"`
+	Description string  `json:"description" description:"What the expense is for (e.g., 'Flight BCN → LIS on 18 Oct')" jsonschema:"required"`
+	Amount      float64 `json:"amount" description:"Price of the expense, taxes and fees included" jsonschema:"required,minimum=0"`
+	Currency    string  `json:"currency" description:"ISO 4217 code of the currency of the price (e.g., 'EUR'). Leave empty for the currency of the budget."`
+	Estimated   bool    `json:"estimated" description:"Whether the price is an estimate of something not booked yet, like a flight offer or a hotel the user is considering"`
+}
+
+// AddExpenseTool adds an expense to the budget of the trip planned in the conversation
+type AddExpenseTool struct {
+	conv  *model.Conversation
+	store budget.Store
+	now   func() time.Time
+}
+
+func NewAddExpenseTool(conv *model.Conversation, store budget.Store) *AddExpenseTool {
+	return &AddExpenseTool{conv: conv, store: store, now: time.Now}
+}
+
+func (t *AddExpenseTool) Name() string {
+	return "add_expense"
+}
+
+func (t *AddExpenseTool) Description() string {
+	return "Adds an expense to the trip budget, booked or estimated, e.g. once the user picks a flight or hotel, or tells what they booked. Returns where the budget stands."
+}
+
+func (t *AddExpenseTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[addExpenseArgs](),
+	})
+}
+
+func (t *AddExpenseTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[addExpenseArgs](args)
+	if err != nil {
+		return "", err
+	}
+
+	description := strings.TrimSpace(payload.Description)
+	if description == "" {
+		return NeedsClarification("description", "What is the expense for?"), nil
+	}
+	category := payload.Category
+	if !budget.ValidCategory(category) {
+		category = budget.CategoryOther
+	}
+
+	// Expenses are in the currency of the budget unless told otherwise
+	var budgetCurrencyCode string
+	if strings.TrimSpace(payload.Currency) == "" {
+		b, err := t.store.DescribeBudget(ctx, t.conv.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get budget: %w", err)
+		}
+		if b != nil {
+			budgetCurrencyCode = b.Target.Currency
+		}
+	}
+	currency, clarification := budgetCurrency(payload.Currency, budgetCurrencyCode)
+	if clarification != "" {
+		return clarification, nil
+	}
+	if !pricing.ValidCurrency(currency) {
+		return "", fmt.Errorf("currency must be an ISO 4217 code like EUR, got %q", currency)
+	}
+
+	e := budget.Expense{
+		ID:          primitive.NewObjectID(),
+		Category:    category,
+		Description: description,
+		Amount:      pricing.FromFloat(payload.Amount, currency),
+		Estimated:   payload.Estimated,
+		AddedAt:     t.now(),
+	}
+	b, err := t.store.AddExpense(ctx, t.conv.ID, t.conv.UserID, e)
+	if errors.Is(err, budget.ErrTooManyExpenses) {
+		return "", fmt.Errorf("the budget already has %d expenses, the most it can track", budget.MaxExpenses)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to add expense: %w", err)
+	}
+
+	kind := "Expense"
+	if e.Estimated {
+		kind = "Estimated expense"
+	}
+	return budgetStatus(ctx, fmt.Sprintf("%s added: %s, %s.", kind, description, e.Amount), b)
+}
+
+// GetBudgetStatusTool reports where the budget of the trip planned in the conversation
+// stands
+type GetBudgetStatusTool struct {
+	conv  *model.Conversation
+	store budget.Store
+}
+
+func NewGetBudgetStatusTool(conv *model.Conversation, store budget.Store) *GetBudgetStatusTool {
+	return &GetBudgetStatusTool{conv: conv, store: store}
+}
+
+func (t *GetBudgetStatusTool) Name() string {
+	return "get_budget_status"
+}
+
+func (t *GetBudgetStatusTool) Description() string {
+	return "Gets the trip budget: the target, what was spent or estimated so far by category, and what remains."
+}
+
+func (t *GetBudgetStatusTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[struct{}](),
+	})
+}
+
+func (t *GetBudgetStatusTool) Execute(ctx context.Context, _ json.RawMessage) (string, error) {
+	b, err := t.store.DescribeBudget(ctx, t.conv.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get budget: %w", err)
+	}
+	if b == nil {
+		return "No budget has been set for this trip, and no expenses added.", nil
+	}
+	return budgetStatus(ctx, "", b)
+}
+
+// budgetCurrency returns the currency of an amount: fallback when empty, then the default
+// currency, or a clarification asking for it when there is neither.
+func budgetCurrency(currency, fallback string) (string, string) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = fallback
+	}
+	if currency == "" {
+		currency = strings.ToUpper(strings.TrimSpace(os.Getenv("AMADEUS_CURRENCY")))
+	}
+	if currency == "" {
+		return "", NeedsClarification("currency", "Which currency is that in?")
+	}
+	return currency, ""
+}
+
+// budgetStatus formats where b stands after what was done, converting expenses in other
+// currencies to the currency of the budget.
+func budgetStatus(ctx context.Context, done string, b *budget.Budget) (string, error) {
+	st, err := b.Status(ctx, rates)
+	if err != nil {
+		return "", fmt.Errorf("failed to total the budget: %w", err)
+	}
+
+	var lines []string
+	if done != "" {
+		lines = append(lines, done)
+	}
+	if st.Target.IsZero() {
+		lines = append(lines, "No target budget set.")
+	} else {
+		lines = append(lines, "Budget: "+st.Target.String())
+	}
+	if len(b.Expenses) == 0 {
+		return strings.Join(append(lines, "No expenses yet."), "\n"), nil
+	}
+
+	spent := "Spent: " + st.Spent.String()
+	if st.Booked != st.Spent {
+		estimated, _ := st.Spent.Sub(st.Booked)
+		spent += fmt.Sprintf(" (%s booked, %s estimated)", st.Booked, estimated)
+	}
+	lines = append(lines, spent)
+
+	var categories []string
+	for _, c := range budget.Categories {
+		if amount, ok := st.ByCategory[c]; ok {
+			categories = append(categories, c+" "+amount.String())
+		}
+	}
+	lines = append(lines, "By category: "+strings.Join(categories, ", "))
+
+	switch {
+	case st.Target.IsZero():
+	case st.Remaining.Amount < 0:
+		over := pricing.Money{Amount: -st.Remaining.Amount, Currency: st.Remaining.Currency}
+		lines = append(lines, "Over budget by "+over.String())
+	default:
+		lines = append(lines, "Remaining: "+st.Remaining.String())
+	}
+	return strings.Join(lines, "\n"), nil
+}
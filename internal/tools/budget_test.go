@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/budget"
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pricing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryBudgets stores budgets in memory.
+type memoryBudgets map[primitive.ObjectID]*budget.Budget
+
+func (m memoryBudgets) DescribeBudget(_ context.Context, id primitive.ObjectID) (*budget.Budget, error) {
+	return m[id], nil
+}
+
+func (m memoryBudgets) SetTarget(_ context.Context, id primitive.ObjectID, userID string, target pricing.Money, now time.Time) (*budget.Budget, error) {
+	b := m.budget(id, userID, now)
+	b.Target = target
+	return b, nil
+}
+
+func (m memoryBudgets) AddExpense(_ context.Context, id primitive.ObjectID, userID string, e budget.Expense) (*budget.Budget, error) {
+	b := m.budget(id, userID, e.AddedAt)
+	b.Expenses = append(b.Expenses, e)
+	return b, nil
+}
+
+func (m memoryBudgets) budget(id primitive.ObjectID, userID string, now time.Time) *budget.Budget {
+	if m[id] == nil {
+		m[id] = &budget.Budget{ConversationID: id, UserID: userID, CreatedAt: now}
+	}
+	m[id].UpdatedAt = now
+	return m[id]
+}
+
+// usdRates converts from USD to EUR only.
+type usdRates struct{}
+
+func (usdRates) Rate(_ context.Context, from, to string) (float64, error) {
+	if from == "USD" && to == "EUR" {
+		return 0.9, nil
+	}
+	return 0, fmt.Errorf("no rate from %s to %s", from, to)
+}
+
+func TestBudgetTools(t *testing.T) {
+	defer func(r pricing.Rates) { rates = r }(rates)
+	rates = usdRates{}
+	t.Setenv("AMADEUS_CURRENCY", "")
+
+	ctx := context.Background()
+	conv := &model.Conversation{ID: primitive.NewObjectID(), UserID: "alice"}
+	store := memoryBudgets{}
+	set, add, status := NewSetTripBudgetTool(conv, store), NewAddExpenseTool(conv, store), NewGetBudgetStatusTool(conv, store)
+
+	execute := func(tool Tool, args string) string {
+		t.Helper()
+		got, err := tool.Execute(ctx, json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("%s(%s) error = %v", tool.Name(), args, err)
+		}
+		return got
+	}
+
+	if got := execute(status, `{}`); !strings.HasPrefix(got, "No budget") {
+		t.Errorf("get_budget_status without a budget = %q", got)
+	}
+	if got := execute(set, `{"amount": 1000}`); !strings.Contains(got, "needs_clarification") {
+		t.Errorf("set_trip_budget without a currency = %q, want a clarification", got)
+	}
+
+	execute(set, `{"amount": 1000, "currency": "eur"}`)
+	// Without a currency, the expense is in the currency of the budget
+	execute(add, `{"category": "flight", "description": "Flight BCN → LIS", "amount": 432.2}`)
+	got := execute(add, `{"category": "lodging", "description": "Hotel in Alfama", "amount": 600, "currency": "USD", "estimated": true}`)
+
+	want := "Estimated expense added: Hotel in Alfama, 600.00 USD.\n" +
+		"Budget: 1000.00 EUR\n" +
+		"Spent: 972.20 EUR (432.20 EUR booked, 540.00 EUR estimated)\n" +
+		"By category: flight 432.20 EUR, lodging 540.00 EUR\n" +
+		"Remaining: 27.80 EUR"
+	if got != want {
+		t.Errorf("add_expense = %q, want %q", got, want)
+	}
+
+	execute(add, `{"category": "activity", "description": "Fado show", "amount": 80}`)
+	if got := execute(status, `{}`); !strings.HasSuffix(got, "Over budget by 52.20 EUR") {
+		t.Errorf("get_budget_status over budget = %q", got)
+	}
+}
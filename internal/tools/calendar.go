@@ -52,6 +52,13 @@ func (t *GetTodayDateTool) Execute(ctx context.Context, args json.RawMessage) (s
 	return time.Now().Format(time.RFC3339), nil
 }
 
+// holidaysArgs are the arguments of get_holidays.
+type holidaysArgs struct {
+	BeforeDate time.Time `json:"before_date,omitempty" description:"Optional date in RFC3339 format to get holidays before this date. If not provided, all holidays will be returned."`
+	AfterDate  time.Time `json:"after_date,omitempty" description:"Optional date in RFC3339 format to get holidays after this date. If not provided, all holidays will be returned."`
+	MaxCount   int       `json:"max_count,omitempty" description:"Optional maximum number of holidays to return. If not provided, all holidays will be returned." jsonschema:"minimum=1"`
+}
+
 // GetHolidaysTool retrieves local bank and public holidays
 type GetHolidaysTool struct{}
 
@@ -63,6 +70,14 @@ func (t *GetHolidaysTool) Name() string {
 	return "get_holidays"
 }
 
+func (t *GetHolidaysTool) Source() string {
+	return "OfficeHolidays"
+}
+
+func (t *GetHolidaysTool) TTL() time.Duration {
+	return 7 * 24 * time.Hour
+}
+
 func (t *GetHolidaysTool) Description() string {
 	return "Gets local bank and public holidays. Each line is a single holiday in the format 'YYYY-MM-DD: Holiday Name'."
 }
@@ -71,23 +86,7 @@ func (t *GetHolidaysTool) Definition() openai.ChatCompletionToolUnionParam {
 	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
 		Name:        t.Name(),
 		Description: openai.String(t.Description()),
-		Parameters: openai.FunctionParameters{
-			"type": "object",
-			"properties": map[string]any{
-				"before_date": map[string]string{
-					"type":        "string",
-					"description": "Optional date in RFC3339 format to get holidays before this date. If not provided, all holidays will be returned.",
-				},
-				"after_date": map[string]string{
-					"type":        "string",
-					"description": "Optional date in RFC3339 format to get holidays after this date. If not provided, all holidays will be returned.",
-				},
-				"max_count": map[string]string{
-					"type":        "integer",
-					"description": "Optional maximum number of holidays to return. If not provided, all holidays will be returned.",
-				},
-			},
-		},
+		Parameters:  Schema[holidaysArgs](),
 	})
 }
 
@@ -117,14 +116,9 @@ func (t *GetHolidaysTool) Execute(ctx context.Context, args json.RawMessage) (st
 	}
 	sort.Slice(datedEvents, func(i, j int) bool { return datedEvents[i].date.Before(datedEvents[j].date) })
 
-	var payload struct {
-		BeforeDate time.Time `json:"before_date,omitempty"`
-		AfterDate  time.Time `json:"after_date,omitempty"`
-		MaxCount   int       `json:"max_count,omitempty"`
-	}
-
-	if err := json.Unmarshal(args, &payload); err != nil {
-		return "", fmt.Errorf("failed to parse tool call arguments: %w", err)
+	payload, err := DecodeArgs[holidaysArgs](args)
+	if err != nil {
+		return "", err
 	}
 
 	var holidays []string
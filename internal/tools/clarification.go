@@ -0,0 +1,35 @@
+package tools
+
+import "encoding/json"
+
+// StatusNeedsClarification marks a tool result that asks the model to get missing
+// information from the user instead of guessing it.
+const StatusNeedsClarification = "needs_clarification"
+
+// Clarification is the result of a tool that can't run without more information from
+// the user.
+type Clarification struct {
+	Status   string `json:"status"`
+	Missing  string `json:"missing"`
+	Question string `json:"question"`
+}
+
+// NeedsClarification returns a tool result asking the model to put question to the
+// user, to fill in the missing argument.
+func NeedsClarification(missing, question string) string {
+	b, _ := json.Marshal(Clarification{
+		Status:   StatusNeedsClarification,
+		Missing:  missing,
+		Question: question,
+	})
+	return string(b)
+}
+
+// ParseClarification reports whether a tool result is a clarification request.
+func ParseClarification(result string) (Clarification, bool) {
+	var c Clarification
+	if err := json.Unmarshal([]byte(result), &c); err != nil || c.Status != StatusNeedsClarification {
+		return Clarification{}, false
+	}
+	return c, true
+}
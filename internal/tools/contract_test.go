@@ -0,0 +1,312 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/vcr"
+)
+
+// contracts are the third-party responses the tools decode. Each has a recorded
+// response, <name>.json, and the JSON schema of the API, <name>.schema.json, in
+// testdata/contracts. When an API changes, update its schema: the test then tells
+// whether the struct decoding it still can.
+var contracts = []contract{
+	{name: "weatherapi_current", path: "/v1/current.json", newResponse: func() any { return &currentWeatherResponse{} }},
+	{name: "weatherapi_forecast", path: "/v1/forecast.json", newResponse: func() any { return &forecastResponse{} }},
+	{name: "amadeus_token", path: "/v1/security/oauth2/token", newResponse: func() any { return &AmadeusTokenResponse{} }},
+	{name: "amadeus_flight_offers", path: "/v2/shopping/flight-offers", newResponse: func() any { return &flightOffersResponse{} }},
+	{name: "amadeus_flight_status", path: "/v2/schedule/flights", newResponse: func() any { return &flightStatusResponse{} }},
+	{name: "amadeus_transfer_offers", path: "/v1/shopping/transfer-offers", newResponse: func() any { return &transferOffersResponse{} }},
+}
+
+type contract struct {
+	name string
+	// path of the endpoint, to check the cassettes recorded from it
+	path string
+	// newResponse returns the struct decoding the response
+	newResponse func() any
+}
+
+func TestContracts(t *testing.T) {
+	for _, c := range contracts {
+		t.Run(c.name, func(t *testing.T) {
+			schema := loadSchema(t, filepath.Join("testdata", "contracts", c.name+".schema.json"))
+			recorded, err := os.ReadFile(filepath.Join("testdata", "contracts", c.name+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, problem := range schema.validate(recorded) {
+				t.Errorf("recorded response doesn't match the schema: %s", problem)
+			}
+			for _, problem := range schema.decodable(reflect.TypeOf(c.newResponse())) {
+				t.Errorf("response struct doesn't match the schema: %s", problem)
+			}
+			if err := json.Unmarshal(recorded, c.newResponse()); err != nil {
+				t.Errorf("decoding the recorded response: %v", err)
+			}
+		})
+	}
+}
+
+// TestContracts_Cassettes checks the responses of the cassettes replayed by the tool
+// tests against the schemas of their endpoints, so that they don't drift from the API.
+func TestContracts_Cassettes(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "cassettes", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		cassette, err := vcr.Open(path, vcr.ModeReplay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, in := range cassette.Interactions() {
+			u, err := url.Parse(in.Request.URL)
+			if err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			i := slices.IndexFunc(contracts, func(c contract) bool { return c.path == u.Path })
+			if i < 0 || in.Response.Status != 200 {
+				continue
+			}
+			schema := loadSchema(t, filepath.Join("testdata", "contracts", contracts[i].name+".schema.json"))
+			for _, problem := range schema.validate([]byte(in.Response.Body)) {
+				t.Errorf("%s: response of %s doesn't match the schema: %s", filepath.Base(path), u.Path, problem)
+			}
+		}
+	}
+}
+
+func TestSchemaDecodable(t *testing.T) {
+	schema := &jsonSchema{}
+	if err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"day": {
+				"type": "object",
+				"properties": {"daily_chance_of_rain": {"type": ["integer", "string"]}}
+			}
+		}
+	}`), schema); err != nil {
+		t.Fatal(err)
+	}
+	schema.root = schema
+
+	type flexible struct {
+		Day struct {
+			DailyChanceOfRain IntOrString `json:"daily_chance_of_rain"`
+		} `json:"day"`
+	}
+	if problems := schema.decodable(reflect.TypeFor[flexible]()); len(problems) > 0 {
+		t.Errorf("decodable() of an IntOrString = %v, want none", problems)
+	}
+
+	type strict struct {
+		Day struct {
+			DailyChanceOfRain int    `json:"daily_chance_of_rain"`
+			Totalprecip       string `json:"totalprecip_mm"`
+		} `json:"day"`
+	}
+	problems := schema.decodable(reflect.TypeFor[strict]())
+	want := []string{
+		"day.daily_chance_of_rain: can't decode a string",
+		"day.totalprecip_mm: not in the schema",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("decodable() of an int = %v, want %v", problems, want)
+	}
+	for i := range want {
+		if !strings.HasPrefix(problems[i], want[i]) {
+			t.Errorf("problem %d = %q, want %q", i, problems[i], want[i])
+		}
+	}
+}
+
+// jsonSchema is the subset of JSON Schema the contracts use: types, properties,
+// required properties, items, enums and local references to $defs.
+type jsonSchema struct {
+	Ref        string                 `json:"$ref"`
+	Type       schemaTypes            `json:"type"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Required   []string               `json:"required"`
+	Items      *jsonSchema            `json:"items"`
+	Enum       []any                  `json:"enum"`
+	Defs       map[string]*jsonSchema `json:"$defs"`
+
+	root *jsonSchema
+}
+
+// schemaTypes is the type of a schema, a single type or a list of them.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+func loadSchema(t *testing.T, path string) *jsonSchema {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := &jsonSchema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	schema.root = schema
+	return schema
+}
+
+// resolve follows the reference of s, if any.
+func (s *jsonSchema) resolve() *jsonSchema {
+	if s.Ref == "" {
+		return s
+	}
+	if def, ok := s.root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]; ok {
+		return def
+	}
+	panic(fmt.Sprintf("unresolved schema reference %s", s.Ref))
+}
+
+// child returns sub, a subschema of s, resolved.
+func (s *jsonSchema) child(sub *jsonSchema) *jsonSchema {
+	sub.root = s.root
+	return sub.resolve()
+}
+
+// validate returns the problems of document against s.
+func (s *jsonSchema) validate(document []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(document))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return []string{err.Error()}
+	}
+	return s.validateValue("$", v)
+}
+
+func (s *jsonSchema) validateValue(path string, v any) []string {
+	s = s.resolve()
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(typ string) bool { return matchesType(typ, v) }) {
+		return []string{fmt.Sprintf("%s: got %s, want %s", path, jsonType(v), strings.Join(s.Type, " or "))}
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(v) }) {
+		return []string{fmt.Sprintf("%s: %v is not one of %v", path, v, s.Enum)}
+	}
+
+	var problems []string
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing %s", path, name))
+			}
+		}
+		for name, prop := range s.Properties {
+			if value, ok := v[name]; ok {
+				problems = append(problems, s.child(prop).validateValue(path+"."+name, value)...)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			items := s.child(s.Items)
+			for i, item := range v {
+				problems = append(problems, items.validateValue(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+	}
+	return problems
+}
+
+// jsonType returns the JSON Schema type of v, decoded with numbers as json.Number.
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func matchesType(typ string, v any) bool {
+	got := jsonType(v)
+	return got == typ || (typ == "number" && got == "integer")
+}
+
+// samples are values of each JSON Schema type, to check that a field can decode them.
+var samples = map[string]string{
+	"null":    `null`,
+	"boolean": `true`,
+	"string":  `"1"`,
+	"integer": `1`,
+	"number":  `1.5`,
+	"array":   `[]`,
+	"object":  `{}`,
+}
+
+// decodable returns the problems decoding documents of s into t: the fields of t
+// missing from the schema, and those that fail to decode a type the schema allows.
+func (s *jsonSchema) decodable(t reflect.Type) []string {
+	return s.decodableAt("", t)
+}
+
+func (s *jsonSchema) decodableAt(path string, t reflect.Type) []string {
+	s = s.resolve()
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		if t.Kind() == reflect.Slice {
+			if s.Items == nil {
+				return nil
+			}
+			s = s.child(s.Items)
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || len(s.Properties) == 0 {
+		return nil
+	}
+
+	var problems []string
+	for _, f := range fields(t) {
+		name := strings.TrimPrefix(path+"."+f.name, ".")
+		prop, ok := s.Properties[f.name]
+		if !ok {
+			problems = append(problems, name+": not in the schema")
+			continue
+		}
+		prop = s.child(prop)
+		ft := t.Field(f.index).Type
+		for _, typ := range prop.Type {
+			if err := json.Unmarshal([]byte(samples[typ]), reflect.New(ft).Interface()); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: can't decode a %s into %s: %v", name, typ, ft, err))
+			}
+		}
+		problems = append(problems, prop.decodableAt(name, ft)...)
+	}
+	return problems
+}
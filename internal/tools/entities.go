@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+// entityArgs are the tool arguments remembered as conversation entities. Tools share
+// argument names, so one struct covers all of them.
+type entityArgs struct {
+	Location      string `json:"location"`
+	Origin        string `json:"origin"`
+	Destination   string `json:"destination"`
+	DepartureDate string `json:"departureDate"`
+	MaxPrice      int    `json:"maxPrice"`
+}
+
+// TrackEntities returns middleware remembering the locations, airports, dates and
+// budgets of successful tool calls on conv, for the tools to use as defaults in later
+// messages.
+func TrackEntities(conv *model.Conversation) Middleware {
+	return func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			result, err := next(ctx, name, args)
+			if err != nil {
+				return result, err
+			}
+			if _, ok := ParseClarification(result); ok {
+				return result, nil
+			}
+
+			var a entityArgs
+			if err := json.Unmarshal(args, &a); err != nil {
+				return result, nil
+			}
+			conv.Entities.Merge(model.Entities{
+				Location:    strings.TrimSpace(a.Location),
+				Origin:      strings.ToUpper(strings.TrimSpace(a.Origin)),
+				Destination: strings.ToUpper(strings.TrimSpace(a.Destination)),
+				Date:        strings.TrimSpace(a.DepartureDate),
+				Budget:      a.MaxPrice,
+			})
+			return result, nil
+		}
+	}
+}
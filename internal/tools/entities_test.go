@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+func TestTrackEntities(t *testing.T) {
+	ctx := context.Background()
+
+	conv := &model.Conversation{Entities: model.Entities{Location: "Lisbon", Budget: 300}}
+	r := NewRegistry()
+	r.Register(echoTool{name: "search"})
+	r.Register(clarifyTool{})
+	r.Use(TrackEntities(conv))
+
+	if _, err := r.Execute(ctx, "search", []byte(`{"origin":" bcn ","destination":"lis","departureDate":"2025-10-18"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := model.Entities{Location: "Lisbon", Origin: "BCN", Destination: "LIS", Date: "2025-10-18", Budget: 300}
+	if conv.Entities != want {
+		t.Errorf("entities = %+v, want %+v", conv.Entities, want)
+	}
+
+	// Calls asking for clarification are not remembered
+	if _, err := r.Execute(ctx, "clarify", []byte(`{"location":"Porto"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conv.Entities.Location != "Lisbon" {
+		t.Errorf("location = %q after a clarification, want %q", conv.Entities.Location, "Lisbon")
+	}
+}
+
+type clarifyTool struct{ echoTool }
+
+func (clarifyTool) Name() string { return "clarify" }
+func (clarifyTool) Execute(context.Context, json.RawMessage) (string, error) {
+	return NeedsClarification("location", "Where?"), nil
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"sync"
+	"time"
+)
+
+// errorRateWindow is the period over which the tool error ratio gauge is computed.
+const errorRateWindow = 5 * time.Minute
+
+// errorRates tracks tool outcomes in one-minute buckets, to derive a per-tool error
+// ratio over the last errorRateWindow that can be alerted on directly.
+type errorRates struct {
+	mu    sync.Mutex
+	tools map[string]*outcomeWindow
+}
+
+type outcomeWindow struct {
+	buckets [int(errorRateWindow / time.Minute)]outcomeBucket
+}
+
+type outcomeBucket struct {
+	minute int64
+	total  int64
+	errors int64
+}
+
+func newErrorRates() *errorRates {
+	return &errorRates{tools: make(map[string]*outcomeWindow)}
+}
+
+// record counts one execution of the tool.
+func (e *errorRates) record(tool string, failed bool, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	w, ok := e.tools[tool]
+	if !ok {
+		w = &outcomeWindow{}
+		e.tools[tool] = w
+	}
+
+	minute := now.Unix() / 60
+	b := &w.buckets[minute%int64(len(w.buckets))]
+	if b.minute != minute {
+		*b = outcomeBucket{minute: minute}
+	}
+	b.total++
+	if failed {
+		b.errors++
+	}
+}
+
+// ratios returns the error ratio of each tool executed within the window.
+func (e *errorRates) ratios(now time.Time) map[string]float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	oldest := now.Unix()/60 - int64(len(outcomeWindow{}.buckets)) + 1
+	out := make(map[string]float64, len(e.tools))
+	for tool, w := range e.tools {
+		var total, errors int64
+		for _, b := range w.buckets {
+			if b.minute >= oldest {
+				total += b.total
+				errors += b.errors
+			}
+		}
+		if total > 0 {
+			out[tool] = float64(errors) / float64(total)
+		}
+	}
+	return out
+}
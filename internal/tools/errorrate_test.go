@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestErrorRates(t *testing.T) {
+	rates := newErrorRates()
+	now := time.Date(2025, 6, 1, 12, 0, 30, 0, time.UTC)
+
+	rates.record("get_weather", true, now.Add(-10*time.Minute)) // outside the window
+	rates.record("get_weather", true, now.Add(-2*time.Minute))
+	rates.record("get_weather", false, now.Add(-time.Minute))
+	rates.record("get_weather", false, now)
+	rates.record("get_weather", false, now)
+	rates.record("get_holidays", false, now)
+
+	got := rates.ratios(now)
+	if got["get_weather"] != 0.25 {
+		t.Errorf("get_weather ratio = %v, want 0.25", got["get_weather"])
+	}
+	if got["get_holidays"] != 0 {
+		t.Errorf("get_holidays ratio = %v, want 0", got["get_holidays"])
+	}
+
+	// Tools without executions in the window are not reported
+	if got := rates.ratios(now.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("ratios after the window = %v, want none", got)
+	}
+}
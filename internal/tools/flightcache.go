@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+)
+
+// flightCacheTTL is how long flight search results are reused within a conversation.
+// It is kept short as prices and availability change quickly.
+const flightCacheTTL = 10 * time.Minute
+
+// flightSearch identifies a flight search of a conversation.
+type flightSearch struct {
+	conversation string
+	origin       string
+	destination  string
+	date         string
+	maxPrice     int
+	currency     string
+}
+
+func (s flightSearch) String() string {
+	return fmt.Sprintf("%s/%s-%s/%s/%d/%s", s.conversation, s.origin, s.destination, s.date, s.maxPrice, s.currency)
+}
+
+// cachedFlights are the results of a flight search and when they were fetched.
+type cachedFlights struct {
+	flights   []FlightDestination
+	fetchedAt time.Time
+}
+
+// flightCache keeps the recent flight search results of each conversation, so asking
+// again for the same flights doesn't hit Amadeus, and runs identical searches made at
+// the same time (parallel tool calls) only once.
+type flightCache struct {
+	mu      sync.Mutex
+	entries map[flightSearch]cachedFlights
+	group   singleflight.Group
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// flightResults is shared by the flight tools of all conversations.
+var flightResults = newFlightCache(flightCacheTTL)
+
+func newFlightCache(ttl time.Duration) *flightCache {
+	return &flightCache{
+		entries: make(map[flightSearch]cachedFlights),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// search returns the cached results of s, or runs fetch and caches its results. cached
+// reports whether the results come from an earlier search.
+func (c *flightCache) search(ctx context.Context, s flightSearch, fetch func(context.Context) ([]FlightDestination, error)) (result cachedFlights, cached bool, err error) {
+	if result, ok := c.get(s); ok {
+		recordCacheLookup(ctx, "flights", true)
+		return result, true, nil
+	}
+	recordCacheLookup(ctx, "flights", false)
+
+	v, err, _ := c.group.Do(s.String(), func() (any, error) {
+		flights, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result := cachedFlights{flights: flights, fetchedAt: c.now()}
+		c.put(s, result)
+		return result, nil
+	})
+	if err != nil {
+		return cachedFlights{}, false, err
+	}
+	return v.(cachedFlights), false, nil
+}
+
+func (c *flightCache) get(s flightSearch) (cachedFlights, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, ok := c.entries[s]
+	if !ok || c.now().Sub(result.fetchedAt) >= c.ttl {
+		return cachedFlights{}, false
+	}
+	return result, true
+}
+
+// put caches the results of s, dropping the expired entries on the way.
+func (c *flightCache) put(s flightSearch, result cachedFlights) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, e := range c.entries {
+		if now.Sub(e.fetchedAt) >= c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[s] = result
+}
+
+// forget drops the cached results of a conversation.
+func (c *flightCache) forget(conversationID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k := range c.entries {
+		if k.conversation == conversationID {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// recordCacheLookup counts a lookup in the cache name of the tools.
+func recordCacheLookup(ctx context.Context, name string, hit bool) {
+	if cacheLookups != nil {
+		cacheLookups.Add(ctx, 1, metric.WithAttributes(attribute.String("cache.name", name), attribute.Bool("cache.hit", hit)))
+	}
+}
+
+// ForgetConversation drops what the tools cached for a conversation, like its flight
+// searches, once it is closed.
+func ForgetConversation(conversationID string) {
+	flightResults.forget(conversationID)
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pricing"
+)
+
+func TestFlightCache_search(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	c := newFlightCache(10 * time.Minute)
+	c.now = func() time.Time { return now }
+
+	var calls int
+	fetch := func(context.Context) ([]FlightDestination, error) {
+		calls++
+		return []FlightDestination{{Origin: "BCN", Destination: "LIS", Price: pricing.Price{Total: pricing.Money{Amount: 8900, Currency: "EUR"}}}}, nil
+	}
+	search := flightSearch{conversation: "c1", origin: "BCN", destination: "LIS", date: "2025-10-18"}
+
+	if _, cached, err := c.search(context.Background(), search, fetch); err != nil || cached {
+		t.Fatalf("first search() cached = %v, err = %v, want a fresh result", cached, err)
+	}
+
+	now = now.Add(5 * time.Minute)
+	result, cached, err := c.search(context.Background(), search, fetch)
+	if err != nil || !cached || calls != 1 {
+		t.Errorf("repeated search() cached = %v, err = %v, fetched %d times, want the cached result", cached, err, calls)
+	}
+	if want := now.Add(-5 * time.Minute); !result.fetchedAt.Equal(want) {
+		t.Errorf("cached result fetched at %v, want %v", result.fetchedAt, want)
+	}
+
+	other := search
+	other.conversation = "c2"
+	if _, cached, _ := c.search(context.Background(), other, fetch); cached {
+		t.Error("search() of another conversation reused the cached result")
+	}
+
+	other = search
+	other.maxPrice = 100
+	if _, cached, _ := c.search(context.Background(), other, fetch); cached {
+		t.Error("search() with other filters reused the cached result")
+	}
+
+	now = now.Add(5 * time.Minute)
+	if _, cached, _ := c.search(context.Background(), search, fetch); cached {
+		t.Error("search() after the TTL reused the expired result")
+	}
+	if calls != 4 {
+		t.Errorf("fetched %d times, want 4", calls)
+	}
+}
+
+func TestFlightCache_search_Errors(t *testing.T) {
+	c := newFlightCache(10 * time.Minute)
+	search := flightSearch{conversation: "c1", origin: "BCN", destination: "LIS", date: "2025-10-18"}
+
+	_, _, err := c.search(context.Background(), search, func(context.Context) ([]FlightDestination, error) {
+		return nil, errors.New("upstream down")
+	})
+	if err == nil {
+		t.Fatal("search() error = nil, want the fetch error")
+	}
+
+	if _, cached, err := c.search(context.Background(), search, func(context.Context) ([]FlightDestination, error) {
+		return nil, nil
+	}); err != nil || cached {
+		t.Errorf("search() after a failure cached = %v, err = %v, want a fresh search", cached, err)
+	}
+}
+
+func TestFlightCache_search_Dedup(t *testing.T) {
+	c := newFlightCache(10 * time.Minute)
+	search := flightSearch{conversation: "c1", origin: "BCN", destination: "LIS", date: "2025-10-18"}
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) ([]FlightDestination, error) {
+		calls.Add(1)
+		<-release
+		return nil, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := c.search(context.Background(), search, fetch); err != nil {
+				t.Errorf("search() error = %v", err)
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("identical concurrent searches fetched %d times, want 1", got)
+	}
+}
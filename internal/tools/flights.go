@@ -1,10 +1,12 @@
 package tools
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -12,6 +14,8 @@ import (
 	"time"
 
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pricing"
+	"github.com/acai-travel/tech-challenge/internal/vcr"
 	"github.com/openai/openai-go/v2"
 )
 
@@ -28,11 +32,11 @@ type FlightDestination struct {
 	Destination   string
 	DepartureDate string
 	ReturnDate    string
-	Price         string
+	Price         pricing.Price
 }
 
-// FetchAmadeusToken retrieves an OAuth2 access token from Amadeus API
-func FetchAmadeusToken(ctx context.Context, httpClient *http.Client, apiKey, apiSecret string) (string, error) {
+// FetchAmadeusToken retrieves an OAuth2 access token from the Amadeus API at baseURL
+func FetchAmadeusToken(ctx context.Context, httpClient *http.Client, baseURL, apiKey, apiSecret string) (string, error) {
 	if apiKey == "" {
 		return "", fmt.Errorf("missing AMADEUS_API_KEY")
 	}
@@ -40,22 +44,23 @@ func FetchAmadeusToken(ctx context.Context, httpClient *http.Client, apiKey, api
 		return "", fmt.Errorf("missing AMADEUS_API_SECRET")
 	}
 
-	u := "https://test.api.amadeus.com/v1/security/oauth2/token"
+	u := baseURL + "/v1/security/oauth2/token"
 
 	formData := url.Values{}
 	formData.Set("grant_type", "client_credentials")
 	formData.Set("client_id", apiKey)
 	formData.Set("client_secret", apiSecret)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(formData.Encode()))
-	if err != nil {
-		return "", fmt.Errorf("build request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := httpClient.Do(req)
+	resp, err := doAmadeus(ctx, httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(formData.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -93,42 +98,87 @@ func FetchAmadeusToken(ctx context.Context, httpClient *http.Client, apiKey, api
 	return tokenResp.AccessToken, nil
 }
 
-// FetchFlightDestinations calls Amadeus flight-offers endpoint
-func FetchFlightDestinations(ctx context.Context, httpClient *http.Client, token, origin, destination, departureDate string, maxPrice int) ([]FlightDestination, error) {
+// FlightQuery are the criteria of a flight offers search
+type FlightQuery struct {
+	Origin        string
+	Destination   string
+	DepartureDate string
+	MaxPrice      int
+	// Currency of the prices, ISO 4217. Empty keeps the currency of the origin country.
+	Currency string
+}
+
+// flightOffersResponse is the part of the Amadeus flight-offers response read.
+type flightOffersResponse struct {
+	Data []struct {
+		Type        string `json:"type"`
+		ID          string `json:"id"`
+		Itineraries []struct {
+			Duration string `json:"duration"`
+			Segments []struct {
+				Departure struct {
+					IataCode string `json:"iataCode"`
+					At       string `json:"at"`
+				} `json:"departure"`
+				Arrival struct {
+					IataCode string `json:"iataCode"`
+					At       string `json:"at"`
+				} `json:"arrival"`
+				CarrierCode string `json:"carrierCode"`
+				Number      string `json:"number"`
+			} `json:"segments"`
+		} `json:"itineraries"`
+		Price struct {
+			Currency string `json:"currency"`
+			Total    string `json:"total"`
+			Base     string `json:"base"`
+		} `json:"price"`
+	} `json:"data"`
+}
+
+// FetchFlightDestinations calls the flight-offers endpoint of the Amadeus API at baseURL
+func FetchFlightDestinations(ctx context.Context, httpClient *http.Client, baseURL, token string, query FlightQuery) ([]FlightDestination, error) {
 	if token == "" {
 		return nil, fmt.Errorf("missing access token")
 	}
-	if origin == "" {
+	if query.Origin == "" {
 		return nil, fmt.Errorf("missing origin")
 	}
-	if destination == "" {
+	if query.Destination == "" {
 		return nil, fmt.Errorf("missing destination")
 	}
-	if departureDate == "" {
+	if query.DepartureDate == "" {
 		return nil, fmt.Errorf("missing departure date")
 	}
 
-	u := url.URL{Scheme: "https", Host: "test.api.amadeus.com", Path: "/v2/shopping/flight-offers"}
+	u, err := url.Parse(baseURL + "/v2/shopping/flight-offers")
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
 	q := u.Query()
-	q.Set("originLocationCode", origin)
-	q.Set("destinationLocationCode", destination)
-	q.Set("departureDate", departureDate)
+	q.Set("originLocationCode", query.Origin)
+	q.Set("destinationLocationCode", query.Destination)
+	q.Set("departureDate", query.DepartureDate)
 	q.Set("adults", "1")
 	q.Set("max", "10") // Limit to 10 offers
-	if maxPrice > 0 {
-		q.Set("maxPrice", fmt.Sprintf("%d", maxPrice))
+	if query.MaxPrice > 0 {
+		q.Set("maxPrice", fmt.Sprintf("%d", query.MaxPrice))
 	}
-	u.RawQuery = q.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("build request: %w", err)
+	if query.Currency != "" {
+		q.Set("currencyCode", query.Currency)
 	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	u.RawQuery = q.Encode()
 
-	resp, err := httpClient.Do(req)
+	resp, err := doAmadeus(ctx, httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		return req, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -138,54 +188,10 @@ func FetchFlightDestinations(ctx context.Context, httpClient *http.Client, token
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		// Try to extract error message from Amadeus API response
-		var apiErr struct {
-			Errors []struct {
-				Detail string `json:"detail"`
-				Title  string `json:"title"`
-				Status int    `json:"status"`
-				Code   int    `json:"code"`
-			} `json:"errors"`
-		}
-		_ = json.Unmarshal(body, &apiErr)
-		if len(apiErr.Errors) > 0 {
-			errMsg := apiErr.Errors[0].Detail
-			if errMsg == "" {
-				errMsg = apiErr.Errors[0].Title
-			}
-			if errMsg != "" {
-				return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, errMsg)
-			}
-		}
-		return nil, fmt.Errorf("api error: status %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	var data struct {
-		Data []struct {
-			Type        string `json:"type"`
-			ID          string `json:"id"`
-			Itineraries []struct {
-				Duration string `json:"duration"`
-				Segments []struct {
-					Departure struct {
-						IataCode string `json:"iataCode"`
-						At       string `json:"at"`
-					} `json:"departure"`
-					Arrival struct {
-						IataCode string `json:"iataCode"`
-						At       string `json:"at"`
-					} `json:"arrival"`
-					CarrierCode string `json:"carrierCode"`
-					Number      string `json:"number"`
-				} `json:"segments"`
-			} `json:"itineraries"`
-			Price struct {
-				Currency string `json:"currency"`
-				Total    string `json:"total"`
-				Base     string `json:"base"`
-			} `json:"price"`
-		} `json:"data"`
+		return nil, amadeusError(resp.StatusCode, body)
 	}
+
+	var data flightOffersResponse
 	if err := json.Unmarshal(body, &data); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
@@ -199,17 +205,32 @@ func FetchFlightDestinations(ctx context.Context, httpClient *http.Client, token
 		firstSegment := offer.Itineraries[0].Segments[0]
 		lastSegment := offer.Itineraries[0].Segments[len(offer.Itineraries[0].Segments)-1]
 
+		price, err := pricing.ParseOffer(offer.Price.Total, offer.Price.Base, offer.Price.Currency)
+		if err != nil {
+			slog.WarnContext(ctx, "Skipping flight offer with invalid price", "offer_id", offer.ID, "error", err)
+			continue
+		}
+
 		out = append(out, FlightDestination{
 			Origin:        firstSegment.Departure.IataCode,
 			Destination:   lastSegment.Arrival.IataCode,
 			DepartureDate: firstSegment.Departure.At,
 			ReturnDate:    "", // One-way flights for now
-			Price:         offer.Price.Total + " " + offer.Price.Currency,
+			Price:         price,
 		})
 	}
 	return out, nil
 }
 
+// flightPricesArgs are the arguments of get_flight_prices.
+type flightPricesArgs struct {
+	Origin        string `json:"origin" description:"IATA code of the origin airport (e.g., 'BCN' for Barcelona, 'NYC' for New York). Leave empty to reuse the previous search's."`
+	Destination   string `json:"destination" description:"IATA code of the destination airport (e.g., 'MAD' for Madrid, 'LON' for London). Leave empty to reuse the previous search's."`
+	DepartureDate string `json:"departureDate" description:"Departure date in YYYY-MM-DD format (e.g., '2025-10-18'). Leave empty to reuse the previous search's."`
+	MaxPrice      int    `json:"maxPrice" description:"Maximum price per traveler in the currency of the prices (optional)" jsonschema:"minimum=1"`
+	Currency      string `json:"currency" description:"ISO 4217 code of the currency to show prices in (e.g., 'EUR', 'USD'). Leave empty for the user's default currency."`
+}
+
 // GetFlightPricesTool retrieves flight destinations and prices
 type GetFlightPricesTool struct {
 	httpClient *http.Client
@@ -218,7 +239,7 @@ type GetFlightPricesTool struct {
 
 func NewGetFlightPricesTool(conv *model.Conversation) *GetFlightPricesTool {
 	return &GetFlightPricesTool{
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: vcr.Transport},
 		conv:       conv,
 	}
 }
@@ -227,6 +248,14 @@ func (t *GetFlightPricesTool) Name() string {
 	return "get_flight_prices"
 }
 
+func (t *GetFlightPricesTool) Source() string {
+	return "Amadeus"
+}
+
+func (t *GetFlightPricesTool) TTL() time.Duration {
+	return 30 * time.Minute
+}
+
 func (t *GetFlightPricesTool) Description() string {
 	return "Search for flight offers and prices between two cities on a specific date. Returns available flights with pricing information."
 }
@@ -235,82 +264,95 @@ func (t *GetFlightPricesTool) Definition() openai.ChatCompletionToolUnionParam {
 	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
 		Name:        t.Name(),
 		Description: openai.String(t.Description()),
-		Parameters: openai.FunctionParameters{
-			"type": "object",
-			"properties": map[string]any{
-				"origin": map[string]string{
-					"type":        "string",
-					"description": "IATA code of the origin airport (e.g., 'BCN' for Barcelona, 'NYC' for New York)",
-				},
-				"destination": map[string]string{
-					"type":        "string",
-					"description": "IATA code of the destination airport (e.g., 'MAD' for Madrid, 'LON' for London)",
-				},
-				"departureDate": map[string]string{
-					"type":        "string",
-					"description": "Departure date in YYYY-MM-DD format (e.g., '2025-10-18')",
-				},
-				"maxPrice": map[string]any{
-					"type":        "integer",
-					"description": "Maximum price per traveler in the currency of the origin country (optional)",
-				},
-			},
-			"required": []string{"origin", "destination", "departureDate"},
-		},
+		Parameters:  Schema[flightPricesArgs](),
 	})
 }
 
 func (t *GetFlightPricesTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
-	var payload struct {
-		Origin        string `json:"origin"`
-		Destination   string `json:"destination"`
-		DepartureDate string `json:"departureDate"`
-		MaxPrice      int    `json:"maxPrice"`
-	}
-	if err := json.Unmarshal(args, &payload); err != nil {
-		return "", fmt.Errorf("failed to parse tool call arguments: %w", err)
+	payload, err := DecodeArgs[flightPricesArgs](args)
+	if err != nil {
+		return "", err
 	}
 
+	// Missing arguments default to the ones of the previous search in the conversation
+	entities := t.conv.Entities
+
 	origin := strings.TrimSpace(strings.ToUpper(payload.Origin))
+	if origin == "" {
+		origin = entities.Origin
+	}
 	if origin == "" {
 		return "", fmt.Errorf("origin is required")
 	}
 
 	destination := strings.TrimSpace(strings.ToUpper(payload.Destination))
+	if destination == "" {
+		destination = entities.Destination
+	}
 	if destination == "" {
 		return "", fmt.Errorf("destination is required")
 	}
 
 	departureDate := strings.TrimSpace(payload.DepartureDate)
+	if departureDate == "" {
+		departureDate = entities.Date
+	}
 	if departureDate == "" {
 		return "", fmt.Errorf("departure date is required")
 	}
 
 	maxPrice := payload.MaxPrice
+	if maxPrice == 0 {
+		maxPrice = entities.Budget
+	}
 
-	// Get API credentials
-	apiKey := os.Getenv("AMADEUS_API_KEY")
-	apiSecret := os.Getenv("AMADEUS_API_SECRET")
+	currency := strings.TrimSpace(strings.ToUpper(cmp.Or(payload.Currency, os.Getenv("AMADEUS_CURRENCY"))))
+	if currency != "" && !pricing.ValidCurrency(currency) {
+		return "", fmt.Errorf("currency must be an ISO 4217 code like EUR, got %q", currency)
+	}
 
-	// Check if credentials are set
-	if apiKey == "" || apiSecret == "" {
-		return "", fmt.Errorf("amadeus API credentials not configured - please set AMADEUS_API_KEY and AMADEUS_API_SECRET environment variables")
+	// Identical searches of the conversation reuse recent results
+	search := flightSearch{
+		conversation: t.conv.ID.Hex(),
+		origin:       origin,
+		destination:  destination,
+		date:         departureDate,
+		maxPrice:     maxPrice,
+		currency:     currency,
 	}
+	result, cached, err := flightResults.search(ctx, search, func(ctx context.Context) ([]FlightDestination, error) {
+		baseURL, token, err := amadeusToken(ctx, t.httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("flight search failed: %w", err)
+		}
 
-	// Fetch OAuth2 token
-	token, err := FetchAmadeusToken(ctx, t.httpClient, apiKey, apiSecret)
+		// Fetch flight destinations
+		flights, err := FetchFlightDestinations(ctx, t.httpClient, baseURL, token, FlightQuery{
+			Origin:        origin,
+			Destination:   destination,
+			DepartureDate: departureDate,
+			MaxPrice:      maxPrice,
+			Currency:      currency,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("flight search failed: %w", err)
+		}
+		return flights, nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("flight search failed: %w", err)
+		return "", err
 	}
+	flights := result.flights
 
-	// Fetch flight destinations
-	flights, err := FetchFlightDestinations(ctx, t.httpClient, token, origin, destination, departureDate, maxPrice)
-	if err != nil {
-		return "", fmt.Errorf("flight search failed: %w", err)
+	// Cached results say how old they are, so the model can mention it
+	note := ""
+	if cached {
+		note = fmt.Sprintf("\n(Cached result of an identical search at %s UTC, %s ago.)",
+			result.fetchedAt.UTC().Format("15:04"), time.Since(result.fetchedAt).Round(time.Minute))
 	}
 
 	if len(flights) == 0 {
-		return "No flights found matching your criteria.", nil
+		return "No flights found matching your criteria." + note, nil
 	}
 
 	// Format response
@@ -339,5 +381,5 @@ func (t *GetFlightPricesTool) Execute(ctx context.Context, args json.RawMessage)
 		lines = append(lines, flightInfo)
 	}
 
-	return strings.Join(lines, "\n"), nil
+	return strings.Join(lines, "\n") + note, nil
 }
@@ -0,0 +1,269 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/vcr"
+	"github.com/openai/openai-go/v2"
+)
+
+// FlightStatus is the schedule of a dated flight, with the delays reported so far
+type FlightStatus struct {
+	Flight string
+	Date   string
+	Points []FlightPoint
+}
+
+// FlightPoint is an airport of a flight, where it departs and/or arrives
+type FlightPoint struct {
+	Airport string
+	// Departure and Arrival are the scheduled times, empty when the flight doesn't
+	// depart or arrive there.
+	Departure      string
+	DepartureDelay time.Duration
+	Arrival        string
+	ArrivalDelay   time.Duration
+}
+
+// flightNumberPattern matches flight numbers like "IB3402", "U2 1234" or "BAW123": an
+// IATA (two characters, at least one a letter) or ICAO (three letters) airline code and
+// up to four digits.
+var flightNumberPattern = regexp.MustCompile(`^([A-Z]{3}|[A-Z][A-Z0-9]|[0-9][A-Z])\s*(\d{1,4})$`)
+
+// parseFlightNumber splits a flight number into its airline code and number.
+func parseFlightNumber(s string) (carrier, number string, ok bool) {
+	m := flightNumberPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// flightStatusResponse is the part of the Amadeus schedule/flights response read.
+type flightStatusResponse struct {
+	Data []struct {
+		FlightPoints []struct {
+			IataCode  string `json:"iataCode"`
+			Departure *struct {
+				Timings []flightTiming `json:"timings"`
+			} `json:"departure"`
+			Arrival *struct {
+				Timings []flightTiming `json:"timings"`
+			} `json:"arrival"`
+		} `json:"flightPoints"`
+	} `json:"data"`
+}
+
+// flightTiming is a time of a flight point, like its scheduled departure (qualifier STD).
+type flightTiming struct {
+	Qualifier string `json:"qualifier"`
+	Value     string `json:"value"`
+	Delays    []struct {
+		Duration string `json:"duration"`
+	} `json:"delays"`
+}
+
+// FetchFlightStatus calls the On-Demand Flight Status endpoint of the Amadeus API at
+// baseURL. It returns nil when no flight matches.
+func FetchFlightStatus(ctx context.Context, httpClient *http.Client, baseURL, token, carrier, number, date string) (*FlightStatus, error) {
+	if token == "" {
+		return nil, fmt.Errorf("missing access token")
+	}
+
+	u, err := url.Parse(baseURL + "/v2/schedule/flights")
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	q := u.Query()
+	q.Set("carrierCode", carrier)
+	q.Set("flightNumber", number)
+	q.Set("scheduledDepartureDate", date)
+	u.RawQuery = q.Encode()
+
+	resp, err := doAmadeus(ctx, httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, amadeusError(resp.StatusCode, body)
+	}
+
+	var data flightStatusResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(data.Data) == 0 {
+		return nil, nil
+	}
+
+	// The scheduled time of a timing and its delay, if any
+	scheduled := func(timings []flightTiming) (string, time.Duration) {
+		for _, t := range timings {
+			var delay time.Duration
+			for _, d := range t.Delays {
+				delay += parseISODuration(d.Duration)
+			}
+			return t.Value, delay
+		}
+		return "", 0
+	}
+
+	status := &FlightStatus{Flight: carrier + number, Date: date}
+	for _, p := range data.Data[0].FlightPoints {
+		point := FlightPoint{Airport: p.IataCode}
+		if p.Departure != nil {
+			point.Departure, point.DepartureDelay = scheduled(p.Departure.Timings)
+		}
+		if p.Arrival != nil {
+			point.Arrival, point.ArrivalDelay = scheduled(p.Arrival.Timings)
+		}
+		status.Points = append(status.Points, point)
+	}
+	return status, nil
+}
+
+// isoDurationPattern matches the hours and minutes of ISO 8601 durations like "PT1H5M".
+var isoDurationPattern = regexp.MustCompile(`^PT(?:(\d+)H)?(?:(\d+)M)?$`)
+
+// parseISODuration parses the hours and minutes of an ISO 8601 duration, 0 when it
+// doesn't match.
+func parseISODuration(s string) time.Duration {
+	m := isoDurationPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+}
+
+// String describes the flight for the model, one airport per line.
+func (s *FlightStatus) String() string {
+	lines := []string{fmt.Sprintf("Flight %s on %s:", s.Flight, s.Date)}
+	for _, p := range s.Points {
+		if p.Departure != "" {
+			lines = append(lines, fmt.Sprintf("Departs %s at %s%s", p.Airport, clockTime(p.Departure), delayNote(p.DepartureDelay)))
+		}
+		if p.Arrival != "" {
+			lines = append(lines, fmt.Sprintf("Arrives %s at %s%s", p.Airport, clockTime(p.Arrival), delayNote(p.ArrivalDelay)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// clockTime extracts HH:MM from an ISO datetime like 2025-10-18T14:30+02:00.
+func clockTime(datetime string) string {
+	if len(datetime) >= 16 {
+		return datetime[11:16]
+	}
+	return datetime
+}
+
+func delayNote(delay time.Duration) string {
+	if delay <= 0 {
+		return " (on time)"
+	}
+	return fmt.Sprintf(" (delayed %d min)", int(delay.Minutes()))
+}
+
+// flightStatusArgs are the arguments of get_flight_status.
+type flightStatusArgs struct {
+	FlightNumber string `json:"flightNumber" description:"Flight number, airline code followed by the number (e.g., 'IB3402', 'U2 1234')"`
+	Date         string `json:"date" description:"Scheduled departure date in YYYY-MM-DD format (e.g., '2025-10-18'). Leave empty for today."`
+}
+
+// GetFlightStatusTool looks up the schedule and delays of a flight
+type GetFlightStatusTool struct {
+	httpClient *http.Client
+}
+
+func NewGetFlightStatusTool() *GetFlightStatusTool {
+	return &GetFlightStatusTool{
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: vcr.Transport},
+	}
+}
+
+func (t *GetFlightStatusTool) Name() string {
+	return "get_flight_status"
+}
+
+func (t *GetFlightStatusTool) Source() string {
+	return "Amadeus"
+}
+
+func (t *GetFlightStatusTool) TTL() time.Duration {
+	return 5 * time.Minute
+}
+
+func (t *GetFlightStatusTool) Description() string {
+	return "Get the status of a flight on a given date: its scheduled departure and arrival times and any reported delays."
+}
+
+func (t *GetFlightStatusTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[flightStatusArgs](),
+	})
+}
+
+func (t *GetFlightStatusTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[flightStatusArgs](args)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(payload.FlightNumber) == "" {
+		return NeedsClarification("flightNumber", "What is the flight number, e.g. IB3402?"), nil
+	}
+	carrier, number, ok := parseFlightNumber(payload.FlightNumber)
+	if !ok {
+		return "", fmt.Errorf("invalid flight number %q, expected an airline code followed by the number, like IB3402", payload.FlightNumber)
+	}
+
+	date := strings.TrimSpace(payload.Date)
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
+
+	baseURL, token, err := amadeusToken(ctx, t.httpClient)
+	if err != nil {
+		return "", fmt.Errorf("flight status lookup failed: %w", err)
+	}
+
+	status, err := FetchFlightStatus(ctx, t.httpClient, baseURL, token, carrier, number, date)
+	if err != nil {
+		return "", fmt.Errorf("flight status lookup failed: %w", err)
+	}
+	if status == nil {
+		return fmt.Sprintf("No flight %s%s found on %s.", carrier, number, date), nil
+	}
+
+	return status.String(), nil
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseFlightNumber(t *testing.T) {
+	tests := []struct {
+		in              string
+		carrier, number string
+		ok              bool
+	}{
+		{in: "IB3402", carrier: "IB", number: "3402", ok: true},
+		{in: "u2 1234", carrier: "U2", number: "1234", ok: true},
+		{in: "BAW123", carrier: "BAW", number: "123", ok: true},
+		{in: "3402"},
+		{in: "IB34021"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			carrier, number, ok := parseFlightNumber(tt.in)
+			if carrier != tt.carrier || number != tt.number || ok != tt.ok {
+				t.Errorf("parseFlightNumber(%q) = %q, %q, %v, want %q, %q, %v", tt.in, carrier, number, ok, tt.carrier, tt.number, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFetchFlightStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/schedule/flights" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("carrierCode") != "IB" || q.Get("flightNumber") != "3402" || q.Get("scheduledDepartureDate") != "2025-10-18" {
+			t.Errorf("query = %v", q)
+		}
+		_, _ = fmt.Fprint(w, `{"data": [{"flightPoints": [
+			{"iataCode": "BCN", "departure": {"timings": [{"qualifier": "STD", "value": "2025-10-18T14:30+02:00", "delays": [{"duration": "PT25M"}]}]}},
+			{"iataCode": "MAD", "arrival": {"timings": [{"qualifier": "STA", "value": "2025-10-18T15:45+02:00"}]}}
+		]}]}`)
+	}))
+	defer srv.Close()
+
+	status, err := FetchFlightStatus(context.Background(), srv.Client(), srv.URL, "token", "IB", "3402", "2025-10-18")
+	if err != nil {
+		t.Fatalf("FetchFlightStatus() error = %v", err)
+	}
+
+	want := "Flight IB3402 on 2025-10-18:\nDeparts BCN at 14:30 (delayed 25 min)\nArrives MAD at 15:45 (on time)"
+	if got := status.String(); got != want {
+		t.Errorf("status = %q, want %q", got, want)
+	}
+}
+
+func TestFetchFlightStatus_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"data": []}`)
+	}))
+	defer srv.Close()
+
+	status, err := FetchFlightStatus(context.Background(), srv.Client(), srv.URL, "token", "IB", "9999", "2025-10-18")
+	if err != nil || status != nil {
+		t.Errorf("FetchFlightStatus() = %v, %v, want no flight", status, err)
+	}
+}
@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/gcal"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/openai/openai-go/v2"
+)
+
+// Calendar reads and writes the calendars users connected.
+type Calendar interface {
+	AuthCodeURL(userID string) string
+	UpcomingEvents(ctx context.Context, userID string, from, to time.Time, query string) ([]gcal.Event, error)
+	PutEvent(ctx context.Context, userID string, e gcal.Event) (bool, error)
+}
+
+// calendarNotConnected tells the model how the user can connect their calendar.
+func calendarNotConnected(cal Calendar, userID string) string {
+	return "The user's Google Calendar is not connected. They can connect it by opening " + cal.AuthCodeURL(userID) + " and then ask again."
+}
+
+// getCalendarTripsArgs are the arguments of get_calendar_trips.
+type getCalendarTripsArgs struct {
+	Days  int    `json:"days" description:"How many days ahead to look, 90 by default" jsonschema:"minimum=1,maximum=365"`
+	Query string `json:"query" description:"Only events matching this text, e.g. 'flight' or a destination. Leave empty for every event."`
+}
+
+// GetCalendarTripsTool lists the upcoming events of the user's Google Calendar
+type GetCalendarTripsTool struct {
+	conv *model.Conversation
+	cal  Calendar
+	now  func() time.Time
+}
+
+func NewGetCalendarTripsTool(conv *model.Conversation, cal Calendar) *GetCalendarTripsTool {
+	return &GetCalendarTripsTool{conv: conv, cal: cal, now: time.Now}
+}
+
+func (t *GetCalendarTripsTool) Name() string {
+	return "get_calendar_trips"
+}
+
+func (t *GetCalendarTripsTool) Description() string {
+	return "Lists the upcoming events of the user's Google Calendar, to find their planned trips, flights and bookings, or when they are free to travel."
+}
+
+func (t *GetCalendarTripsTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[getCalendarTripsArgs](),
+	})
+}
+
+func (t *GetCalendarTripsTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[getCalendarTripsArgs](args)
+	if err != nil {
+		return "", err
+	}
+	if t.conv.UserID == "" {
+		return "", fmt.Errorf("google calendar is only available to signed-in users")
+	}
+
+	days := payload.Days
+	if days <= 0 || days > 365 {
+		days = 90
+	}
+	now := t.now()
+	events, err := t.cal.UpcomingEvents(ctx, t.conv.UserID, now, now.AddDate(0, 0, days), strings.TrimSpace(payload.Query))
+	if errors.Is(err, gcal.ErrNotConnected) {
+		return calendarNotConnected(t.cal, t.conv.UserID), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to list calendar events: %w", err)
+	}
+	if len(events) == 0 {
+		return fmt.Sprintf("No events in the user's calendar in the next %d days.", days), nil
+	}
+
+	lines := []string{fmt.Sprintf("Events in the next %d days:", days)}
+	for _, e := range events {
+		line := "- " + eventTime(e.Start) + ": " + e.Summary
+		if e.Location != "" {
+			line += " (" + e.Location + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// eventTime formats the start or end of an event in its own timezone.
+func eventTime(et gcal.EventTime) string {
+	if et.Date != "" {
+		if d, err := time.Parse(time.DateOnly, et.Date); err == nil {
+			return d.Format("Mon 02 Jan 2006") + ", all day"
+		}
+		return et.Date
+	}
+	t, err := time.Parse(time.RFC3339, et.DateTime)
+	if err != nil {
+		return et.DateTime
+	}
+	if loc, err := time.LoadLocation(et.TimeZone); err == nil && et.TimeZone != "" {
+		return t.In(loc).Format("Mon 02 Jan 2006 15:04") + " (" + et.TimeZone + ")"
+	}
+	return t.Format("Mon 02 Jan 2006 15:04 -07:00")
+}
+
+// AddItineraryToCalendarTool writes the items of the trip itinerary to the user's Google
+// Calendar
+type AddItineraryToCalendarTool struct {
+	conv        *model.Conversation
+	itineraries itinerary.Store
+	cal         Calendar
+}
+
+func NewAddItineraryToCalendarTool(conv *model.Conversation, itineraries itinerary.Store, cal Calendar) *AddItineraryToCalendarTool {
+	return &AddItineraryToCalendarTool{conv: conv, itineraries: itineraries, cal: cal}
+}
+
+func (t *AddItineraryToCalendarTool) Name() string {
+	return "add_itinerary_to_calendar"
+}
+
+func (t *AddItineraryToCalendarTool) Description() string {
+	return "Writes the flights, stays, transfers and activities of the trip itinerary to the user's Google Calendar, once they confirmed them. Items already in the calendar are updated, not duplicated."
+}
+
+func (t *AddItineraryToCalendarTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[struct{}](),
+	})
+}
+
+func (t *AddItineraryToCalendarTool) Execute(ctx context.Context, _ json.RawMessage) (string, error) {
+	if t.conv.UserID == "" {
+		return "", fmt.Errorf("google calendar is only available to signed-in users")
+	}
+
+	it, err := t.itineraries.DescribeItinerary(ctx, t.conv.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get itinerary: %w", err)
+	}
+	if it == nil || len(it.Items) == 0 {
+		return "The itinerary is empty: add its items with add_itinerary_item first.", nil
+	}
+
+	var created, updated int
+	for _, item := range it.Sorted() {
+		// Events are named after their items, so writing them again updates them
+		ok, err := t.cal.PutEvent(ctx, t.conv.UserID, calendarEvent(item))
+		if errors.Is(err, gcal.ErrNotConnected) {
+			return calendarNotConnected(t.cal, t.conv.UserID), nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to add %q to the calendar after %d items: %w", item.Title, created+updated, err)
+		}
+		if ok {
+			created++
+		} else {
+			updated++
+		}
+	}
+	return fmt.Sprintf("Google Calendar updated: %d events added, %d updated.", created, updated), nil
+}
+
+// calendarEvent returns the event of an itinerary item. Items without an end are
+// events without duration.
+func calendarEvent(item itinerary.Item) gcal.Event {
+	end, endTZ := item.EndAt, item.EndTimezone
+	if end.IsZero() {
+		end = item.StartAt
+	}
+	if endTZ == "" {
+		endTZ = item.Timezone
+	}
+	return gcal.Event{
+		ID:          item.ID.Hex(),
+		Summary:     item.Title,
+		Location:    item.Location,
+		Description: item.Details,
+		Start:       gcal.EventTime{DateTime: item.StartAt.Format(time.RFC3339), TimeZone: item.Timezone},
+		End:         gcal.EventTime{DateTime: end.Format(time.RFC3339), TimeZone: endTZ},
+	}
+}
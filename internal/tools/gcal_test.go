@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/gcal"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryCalendar is the calendar of a single user, connected or not.
+type memoryCalendar struct {
+	connected bool
+	events    map[string]gcal.Event
+}
+
+func (c *memoryCalendar) AuthCodeURL(userID string) string {
+	return "https://accounts.example.com/auth?user=" + userID
+}
+
+func (c *memoryCalendar) UpcomingEvents(_ context.Context, _ string, _, _ time.Time, _ string) ([]gcal.Event, error) {
+	if !c.connected {
+		return nil, gcal.ErrNotConnected
+	}
+	var events []gcal.Event
+	for _, e := range c.events {
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (c *memoryCalendar) PutEvent(_ context.Context, _ string, e gcal.Event) (bool, error) {
+	if !c.connected {
+		return false, gcal.ErrNotConnected
+	}
+	_, exists := c.events[e.ID]
+	c.events[e.ID] = e
+	return !exists, nil
+}
+
+func TestCalendarTools(t *testing.T) {
+	ctx := context.Background()
+	conv := &model.Conversation{ID: primitive.NewObjectID(), UserID: "alice"}
+	cal := &memoryCalendar{events: make(map[string]gcal.Event)}
+	itineraries := memoryItineraries{}
+	read, write := NewGetCalendarTripsTool(conv, cal), NewAddItineraryToCalendarTool(conv, itineraries, cal)
+
+	if got, err := read.Execute(ctx, json.RawMessage(`{}`)); err != nil || !strings.Contains(got, "https://accounts.example.com/auth?user=alice") {
+		t.Errorf("get_calendar_trips before connecting = %q, %v, want the link to connect", got, err)
+	}
+
+	cal.connected = true
+	start, _ := itinerary.ParseLocal("2025-10-18T10:05", "Europe/Madrid")
+	_, _ = itineraries.AddItem(ctx, conv.ID, conv.UserID, itinerary.Item{ID: primitive.NewObjectID(), Kind: itinerary.KindFlight, Title: "Flight TP1039 BCN → LIS", StartAt: start.UTC(), Timezone: "Europe/Madrid"})
+
+	for _, want := range []string{"1 events added, 0 updated", "0 events added, 1 updated"} {
+		if got, err := write.Execute(ctx, json.RawMessage(`{}`)); err != nil || !strings.Contains(got, want) {
+			t.Errorf("add_itinerary_to_calendar = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	got, err := read.Execute(ctx, json.RawMessage(`{"days": 30}`))
+	if err != nil || !strings.Contains(got, "- Sat 18 Oct 2025 10:05 (Europe/Madrid): Flight TP1039 BCN → LIS") {
+		t.Errorf("get_calendar_trips = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/geo"
+)
+
+// maxPlaceChoices bounds the places offered when a name is ambiguous.
+const maxPlaceChoices = 5
+
+// places resolves the place names of the tools of all conversations.
+var places geo.Geocoder = geo.NewWeatherAPIGeocoder()
+
+// locate resolves a place name of the conversation to a canonical name and coordinates,
+// reusing what it was resolved to earlier in the conversation. When the name matches
+// several places, it returns a clarification result asking which one is meant for the
+// missing field instead. Names the geocoder doesn't find are kept as they are, without
+// coordinates, for the weather API to make sense of.
+func locate(ctx context.Context, conv *model.Conversation, geocoder geo.Geocoder, field, name string) (place model.Place, clarification string, err error) {
+	name = strings.TrimSpace(name)
+	if p, ok := conv.Place(name); ok {
+		return p, "", nil
+	}
+
+	matches, err := geocoder.Search(ctx, name)
+	if err != nil {
+		return model.Place{}, "", fmt.Errorf("locate %q: %w", name, err)
+	}
+
+	candidates := geo.Candidates(name, matches)
+	switch len(candidates) {
+	case 0:
+		return model.Place{Query: name, Name: name}, "", nil
+	case 1:
+		c := candidates[0]
+		place = model.Place{Query: name, Name: c.Canonical(), Lat: c.Lat, Lon: c.Lon}
+		conv.RememberPlace(place)
+		// "Springfield, Illinois" also settles what "Springfield" means from now on
+		if base, _, qualified := strings.Cut(name, ","); qualified {
+			bare := place
+			bare.Query = strings.TrimSpace(base)
+			conv.RememberPlace(bare)
+		}
+		return place, "", nil
+	}
+
+	choices := make([]string, 0, maxPlaceChoices)
+	for _, c := range candidates[:min(len(candidates), maxPlaceChoices)] {
+		choices = append(choices, c.Canonical())
+	}
+	return model.Place{}, NeedsClarification(field, fmt.Sprintf("Which %s do you mean: %s?", name, strings.Join(choices, "; "))), nil
+}
+
+// placeQuery returns how to look a place up in the weather API: by its coordinates
+// when known, by name otherwise.
+func placeQuery(p model.Place) string {
+	if !p.Located() {
+		return p.Name
+	}
+	return strconv.FormatFloat(p.Lat, 'f', 4, 64) + "," + strconv.FormatFloat(p.Lon, 'f', 4, 64)
+}
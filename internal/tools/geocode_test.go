@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/geo"
+)
+
+// stubGeocoder answers searches from a map of names to matches. Coordinates find
+// themselves, like with the real geocoder.
+type stubGeocoder map[string][]geo.Place
+
+func (g stubGeocoder) Search(_ context.Context, query string) ([]geo.Place, error) {
+	if lat, lon, ok := geo.ParseCoordinates(query); ok {
+		return []geo.Place{{Name: query, Lat: lat, Lon: lon}}, nil
+	}
+	return g[query], nil
+}
+
+func TestLocate(t *testing.T) {
+	geocoder := stubGeocoder{
+		"Springfield": {
+			{Name: "Springfield", Region: "Illinois", Country: "United States of America", Lat: 39.8, Lon: -89.64},
+			{Name: "Springfield", Region: "Missouri", Country: "United States of America", Lat: 37.22, Lon: -93.3},
+			{Name: "Springfield", Region: "Tasmania", Country: "Australia", Lat: -41.2, Lon: 147.5},
+		},
+		"Springfield, Illinois": {
+			{Name: "Springfield", Region: "Illinois", Country: "United States of America", Lat: 39.8, Lon: -89.64},
+		},
+		"Lisbon": {
+			{Name: "Lisbon", Region: "Lisboa", Country: "Portugal", Lat: 38.72, Lon: -9.13},
+			{Name: "Lisbon", Region: "Maine", Country: "United States of America", Lat: 44.03, Lon: -70.1},
+		},
+	}
+	ctx := context.Background()
+	conv := &model.Conversation{}
+
+	// Namesakes in the same country are ambiguous
+	_, clarification, err := locate(ctx, conv, geocoder, "location", "Springfield")
+	if err != nil {
+		t.Fatalf("locate() error = %v", err)
+	}
+	c, ok := ParseClarification(clarification)
+	if want := "Which Springfield do you mean: Springfield, Illinois, United States of America; Springfield, Missouri, United States of America?"; !ok || c.Question != want {
+		t.Fatalf("locate() clarification = %q, want the question %q", clarification, want)
+	}
+
+	// The answer settles what "Springfield" means for the rest of the conversation
+	place, clarification, err := locate(ctx, conv, geocoder, "location", "Springfield, Illinois")
+	if err != nil || clarification != "" {
+		t.Fatalf("locate() = %q, %v, want a place", clarification, err)
+	}
+	want := model.Place{Query: "Springfield, Illinois", Name: "Springfield, Illinois, United States of America", Lat: 39.8, Lon: -89.64}
+	if place != want {
+		t.Errorf("locate() = %+v, want %+v", place, want)
+	}
+	if place, clarification, _ = locate(ctx, conv, geocoder, "location", "springfield"); clarification != "" || place.Name != want.Name {
+		t.Errorf("locate() after the answer = %+v, %q, want the remembered %s", place, clarification, want.Name)
+	}
+
+	// The best match wins over namesakes abroad
+	if place, clarification, _ = locate(ctx, conv, geocoder, "location", "Lisbon"); clarification != "" || place.Name != "Lisbon, Lisboa, Portugal" {
+		t.Errorf("locate() = %+v, %q, want Lisbon, Portugal", place, clarification)
+	}
+
+	// Unknown names are kept as they are
+	if place, clarification, _ = locate(ctx, conv, geocoder, "location", "90210"); clarification != "" || place.Located() || place.Name != "90210" {
+		t.Errorf("locate() = %+v, %q, want the name as is", place, clarification)
+	}
+	if got := placeQuery(place); got != "90210" {
+		t.Errorf("placeQuery() = %q, want the name", got)
+	}
+	if got := placeQuery(want); got != "39.8000,-89.6400" {
+		t.Errorf("placeQuery() = %q, want the coordinates", got)
+	}
+}
+
+func TestGetWeatherTool_Ambiguous(t *testing.T) {
+	tool := NewGetWeatherTool(&model.Conversation{})
+	tool.geocoder = stubGeocoder{"Springfield": {
+		{Name: "Springfield", Region: "Illinois", Country: "United States of America"},
+		{Name: "Springfield", Region: "Missouri", Country: "United States of America"},
+	}}
+
+	got, err := tool.Execute(context.Background(), json.RawMessage(`{"location": "Springfield"}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if c, ok := ParseClarification(got); !ok || c.Missing != "location" {
+		t.Errorf("Execute() = %q, want a clarification of the location", got)
+	}
+}
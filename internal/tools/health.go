@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// HealthRequirements are the vaccination and testing rules of entering a country.
+type HealthRequirements struct {
+	Country string
+	// Required vaccinations, whichever country the traveler comes from
+	Required []string
+	// RequiredFrom are vaccinations required when coming from a country with a risk of
+	// the disease (yellow fever). FromRisk tells whether that applies to the traveler:
+	// nil when the country they come from is unknown.
+	RequiredFrom []string
+	FromRisk     *bool
+	Recommended  []string
+	Testing      string
+	Notes        string
+	// AsOf is when the requirements were last checked, YYYY-MM-DD.
+	AsOf string
+}
+
+// HealthProvider looks up health entry requirements. Official sources change often and
+// differ by region, so the tool only depends on this interface.
+type HealthProvider interface {
+	// Source names where the requirements come from, for citations.
+	Source() string
+	// Requirements returns the requirements of entering country (an ISO 3166 code or
+	// name) when coming from another country, which may be empty. It returns nil when
+	// the country is unknown.
+	Requirements(ctx context.Context, country, from string) (*HealthRequirements, error)
+}
+
+//go:embed health_requirements.json
+var healthDataset []byte
+
+// StaticHealthProvider answers from a dataset embedded in the binary, updated by hand.
+type StaticHealthProvider struct {
+	once    sync.Once
+	dataset healthData
+	err     error
+}
+
+type healthData struct {
+	AsOf            string                   `json:"as_of"`
+	YellowFeverRisk []string                 `json:"yellow_fever_risk"`
+	Countries       map[string]healthCountry `json:"countries"`
+}
+
+type healthCountry struct {
+	Name             string   `json:"name"`
+	Aliases          []string `json:"aliases"`
+	Required         []string `json:"required"`
+	RequiredFromRisk []string `json:"required_from_risk"`
+	Recommended      []string `json:"recommended"`
+	Testing          string   `json:"testing"`
+	Notes            string   `json:"notes"`
+}
+
+func NewStaticHealthProvider() *StaticHealthProvider {
+	return &StaticHealthProvider{}
+}
+
+func (p *StaticHealthProvider) Source() string {
+	return "Travel health dataset"
+}
+
+func (p *StaticHealthProvider) Requirements(_ context.Context, country, from string) (*HealthRequirements, error) {
+	p.once.Do(func() {
+		if err := json.Unmarshal(healthDataset, &p.dataset); err != nil {
+			p.err = fmt.Errorf("decode health dataset: %w", err)
+		}
+	})
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	code, c, ok := p.lookup(country)
+	if !ok {
+		return nil, nil
+	}
+
+	req := &HealthRequirements{
+		Country:      c.Name,
+		Required:     c.Required,
+		RequiredFrom: c.RequiredFromRisk,
+		Recommended:  c.Recommended,
+		// COVID-19 entry rules have been lifted everywhere in the dataset
+		Testing: cmp.Or(c.Testing, "no testing or vaccination required"),
+		Notes:   c.Notes,
+		AsOf:    p.dataset.AsOf,
+	}
+	// Countries the dataset doesn't cover can still be given by code
+	fromCode := strings.ToUpper(strings.TrimSpace(from))
+	if c, _, ok := p.lookup(from); ok {
+		fromCode = c
+	}
+	if len(fromCode) == 2 && fromCode != code {
+		risk := slices.Contains(p.dataset.YellowFeverRisk, fromCode)
+		req.FromRisk = &risk
+	}
+	return req, nil
+}
+
+// lookup finds a country of the dataset by ISO 3166 code, name or alias.
+func (p *StaticHealthProvider) lookup(country string) (string, healthCountry, bool) {
+	country = strings.TrimSpace(country)
+	if country == "" {
+		return "", healthCountry{}, false
+	}
+	if c, ok := p.dataset.Countries[strings.ToUpper(country)]; ok {
+		return strings.ToUpper(country), c, true
+	}
+	for code, c := range p.dataset.Countries {
+		if strings.EqualFold(c.Name, country) || slices.ContainsFunc(c.Aliases, func(a string) bool { return strings.EqualFold(a, country) }) {
+			return code, c, true
+		}
+	}
+	return "", healthCountry{}, false
+}
+
+// healthArgs are the arguments of get_health_requirements.
+type healthArgs struct {
+	Destination   string `json:"destination" description:"Country the traveler is going to, as a name or ISO 3166 code (e.g., 'Kenya', 'KE')" jsonschema:"required"`
+	TravelingFrom string `json:"travelingFrom" description:"ISO 3166 code of the country the traveler arrives from (e.g., 'ES'). Optional, some vaccinations depend on it."`
+}
+
+// GetHealthRequirementsTool looks up the vaccinations and tests required to enter a country
+type GetHealthRequirementsTool struct {
+	provider HealthProvider
+}
+
+func NewGetHealthRequirementsTool(provider HealthProvider) *GetHealthRequirementsTool {
+	return &GetHealthRequirementsTool{provider: provider}
+}
+
+func (t *GetHealthRequirementsTool) Name() string {
+	return "get_health_requirements"
+}
+
+func (t *GetHealthRequirementsTool) Source() string {
+	return t.provider.Source()
+}
+
+func (t *GetHealthRequirementsTool) TTL() time.Duration {
+	return 24 * time.Hour
+}
+
+func (t *GetHealthRequirementsTool) Description() string {
+	return "Get the health entry requirements of a destination country: required and recommended vaccinations and COVID-19 testing rules."
+}
+
+func (t *GetHealthRequirementsTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[healthArgs](),
+	})
+}
+
+func (t *GetHealthRequirementsTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[healthArgs](args)
+	if err != nil {
+		return "", err
+	}
+
+	destination := strings.TrimSpace(payload.Destination)
+	if destination == "" {
+		return NeedsClarification("destination", "Which country are you traveling to?"), nil
+	}
+
+	req, err := t.provider.Requirements(ctx, destination, payload.TravelingFrom)
+	if err != nil {
+		return "", fmt.Errorf("health requirements lookup failed: %w", err)
+	}
+	if req == nil {
+		return fmt.Sprintf("No health entry requirements on record for %s. The traveler should check with the destination's embassy or an official travel health service.", destination), nil
+	}
+
+	lines := []string{fmt.Sprintf("Health entry requirements for %s (as of %s):", req.Country, req.AsOf)}
+
+	required := slices.Clone(req.Required)
+	switch {
+	case len(req.RequiredFrom) == 0:
+	case req.FromRisk == nil:
+		for _, v := range req.RequiredFrom {
+			required = append(required, v+" (if arriving from a country with yellow fever risk)")
+		}
+	case *req.FromRisk:
+		for _, v := range req.RequiredFrom {
+			required = append(required, v+" (the traveler arrives from a country with yellow fever risk)")
+		}
+	}
+	if len(required) == 0 {
+		lines = append(lines, "Required vaccinations: none")
+	} else {
+		lines = append(lines, "Required vaccinations: "+strings.Join(required, ", "))
+	}
+	if len(req.Recommended) > 0 {
+		lines = append(lines, "Recommended vaccinations: "+strings.Join(req.Recommended, ", "))
+	}
+	if req.Testing != "" {
+		lines = append(lines, "COVID-19: "+req.Testing)
+	}
+	if req.Notes != "" {
+		lines = append(lines, "Notes: "+req.Notes)
+	}
+	lines = append(lines, "Requirements change often; tell the traveler to confirm with an official source before traveling.")
+
+	return strings.Join(lines, "\n"), nil
+}
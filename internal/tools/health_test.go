@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStaticHealthProvider_Requirements(t *testing.T) {
+	p := NewStaticHealthProvider()
+	ctx := context.Background()
+
+	tests := []struct {
+		country, from string
+		want          string
+		wantFromRisk  *bool
+	}{
+		{country: "KE", want: "Kenya"},
+		{country: "kenya", from: "Brazil", want: "Kenya", wantFromRisk: ptr(true)},
+		{country: "Kenya", from: "ES", want: "Kenya", wantFromRisk: ptr(false)},
+		{country: "Kenya", from: "UG", want: "Kenya", wantFromRisk: ptr(true)},
+		{country: "usa", want: "United States"},
+		{country: "Atlantis"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country+"/"+tt.from, func(t *testing.T) {
+			req, err := p.Requirements(ctx, tt.country, tt.from)
+			if err != nil {
+				t.Fatalf("Requirements() error = %v", err)
+			}
+			if tt.want == "" {
+				if req != nil {
+					t.Errorf("Requirements() = %+v, want nil for an unknown country", req)
+				}
+				return
+			}
+			if req == nil || req.Country != tt.want {
+				t.Fatalf("Requirements() = %+v, want %s", req, tt.want)
+			}
+			if (req.FromRisk == nil) != (tt.wantFromRisk == nil) || req.FromRisk != nil && *req.FromRisk != *tt.wantFromRisk {
+				t.Errorf("FromRisk = %v, want %v", req.FromRisk, tt.wantFromRisk)
+			}
+			if req.AsOf == "" {
+				t.Error("AsOf is empty, want the date of the dataset")
+			}
+		})
+	}
+}
+
+func TestGetHealthRequirementsTool_Execute(t *testing.T) {
+	tool := NewGetHealthRequirementsTool(NewStaticHealthProvider())
+	ctx := context.Background()
+
+	got, err := tool.Execute(ctx, json.RawMessage(`{"destination": "Kenya", "travelingFrom": "Spain"}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(got, "Required vaccinations: none") {
+		t.Errorf("Execute() = %q, want no yellow fever requirement coming from Spain", got)
+	}
+
+	got, err = tool.Execute(ctx, json.RawMessage(`{"destination": "Kenya", "travelingFrom": "UG"}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(got, "Required vaccinations: yellow fever (the traveler arrives from a country with yellow fever risk)") {
+		t.Errorf("Execute() = %q, want yellow fever required coming from Uganda", got)
+	}
+
+	got, err = tool.Execute(ctx, json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if c, ok := ParseClarification(got); !ok || c.Missing != "destination" {
+		t.Errorf("Execute() = %q, want a clarification of the destination", got)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
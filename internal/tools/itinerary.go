@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"github.com/openai/openai-go/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// addItineraryItemArgs are the arguments of add_itinerary_item.
+type addItineraryItemArgs struct {
+	Kind        string `json:"kind" description:"Kind of item" jsonschema:"required,enum=flight|lodging|transfer|activity"`
+	Title       string `json:"title" description:"Short title of the item as it should appear in a calendar (e.g., 'Flight TP1039 BCN → LIS', 'Check-in Hotel Alfama')" jsonschema:"required"`
+	Location    string `json:"location" description:"Address or place of the item, e.g. the departure airport of a flight"`
+	Details     string `json:"details" description:"Other details worth keeping, like a booking reference"`
+	StartAt     string `json:"start_at" description:"Local date and time the item starts, in the format YYYY-MM-DDTHH:MM" jsonschema:"required"`
+	Timezone    string `json:"timezone" description:"IANA timezone of start_at, where the item starts (e.g., 'Europe/Madrid' for a flight departing from Barcelona)" jsonschema:"required"`
+	EndAt       string `json:"end_at" description:"Local date and time the item ends, in the format YYYY-MM-DDTHH:MM. Leave empty when unknown."`
+	EndTimezone string `json:"end_timezone" description:"IANA timezone of end_at when it differs from timezone, like the arrival airport of a flight"`
+}
+
+// AddItineraryItemTool adds a flight, stay, transfer or activity to the itinerary of the
+// trip planned in the conversation
+type AddItineraryItemTool struct {
+	conv  *model.Conversation
+	store itinerary.Store
+	now   func() time.Time
+}
+
+func NewAddItineraryItemTool(conv *model.Conversation, store itinerary.Store) *AddItineraryItemTool {
+	return &AddItineraryItemTool{conv: conv, store: store, now: time.Now}
+}
+
+func (t *AddItineraryItemTool) Name() string {
+	return "add_itinerary_item"
+}
+
+func (t *AddItineraryItemTool) Description() string {
+	return "Adds a flight, hotel check-in, transfer or activity the user settled on to the trip itinerary, which they can export to their calendar."
+}
+
+func (t *AddItineraryItemTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[addItineraryItemArgs](),
+	})
+}
+
+func (t *AddItineraryItemTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[addItineraryItemArgs](args)
+	if err != nil {
+		return "", err
+	}
+
+	title := strings.TrimSpace(payload.Title)
+	if title == "" {
+		return NeedsClarification("title", "What should the item be called?"), nil
+	}
+	kind := payload.Kind
+	if !itinerary.ValidKind(kind) {
+		kind = itinerary.KindActivity
+	}
+	if strings.TrimSpace(payload.StartAt) == "" {
+		return NeedsClarification("start_at", "When does it start?"), nil
+	}
+
+	start, err := itinerary.ParseLocal(payload.StartAt, payload.Timezone)
+	if err != nil {
+		return "", err
+	}
+	item := itinerary.Item{
+		ID:       primitive.NewObjectID(),
+		Kind:     kind,
+		Title:    title,
+		Location: strings.TrimSpace(payload.Location),
+		Details:  strings.TrimSpace(payload.Details),
+		StartAt:  start.UTC(),
+		Timezone: start.Location().String(),
+		AddedAt:  t.now(),
+	}
+
+	if strings.TrimSpace(payload.EndAt) != "" {
+		tz := payload.EndTimezone
+		if strings.TrimSpace(tz) == "" {
+			tz = payload.Timezone
+		}
+		end, err := itinerary.ParseLocal(payload.EndAt, tz)
+		if err != nil {
+			return "", err
+		}
+		if end.Before(start) {
+			return "", fmt.Errorf("end_at %s is before start_at %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+		}
+		item.EndAt = end.UTC()
+		if l := end.Location().String(); l != item.Timezone {
+			item.EndTimezone = l
+		}
+	}
+
+	it, err := t.store.AddItem(ctx, t.conv.ID, t.conv.UserID, item)
+	if errors.Is(err, itinerary.ErrTooManyItems) {
+		return "", fmt.Errorf("the itinerary already has %d items, the most it can hold", itinerary.MaxItems)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to add itinerary item: %w", err)
+	}
+
+	when := start.Format("Mon 02 Jan 2006 15:04") + " (" + item.Timezone + ")"
+	return fmt.Sprintf("Added to the itinerary: %s, %s. The itinerary has %d items.", title, when, len(it.Items)), nil
+}
+
+// GetBookingEmailAddressTool tells the address the user can forward the booking
+// confirmations of the trip to
+type GetBookingEmailAddressTool struct {
+	address string
+}
+
+// NewGetBookingEmailAddressTool returns the tool telling address, the forwarding address
+// of the trip of the conversation.
+func NewGetBookingEmailAddressTool(address string) *GetBookingEmailAddressTool {
+	return &GetBookingEmailAddressTool{address: address}
+}
+
+func (t *GetBookingEmailAddressTool) Name() string {
+	return "get_booking_email_address"
+}
+
+func (t *GetBookingEmailAddressTool) Description() string {
+	return "Gets the email address the user can forward booking confirmations (flights, hotels, tours) to, so their reservations are added to the trip itinerary."
+}
+
+func (t *GetBookingEmailAddressTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[struct{}](),
+	})
+}
+
+func (t *GetBookingEmailAddressTool) Execute(context.Context, json.RawMessage) (string, error) {
+	return "Booking confirmations forwarded to " + t.address + " are added to the itinerary of this trip, usually within a minute.", nil
+}
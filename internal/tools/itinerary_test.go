@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/itinerary"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryItineraries stores itineraries in memory.
+type memoryItineraries map[primitive.ObjectID]*itinerary.Itinerary
+
+func (m memoryItineraries) DescribeItinerary(_ context.Context, id primitive.ObjectID) (*itinerary.Itinerary, error) {
+	return m[id], nil
+}
+
+func (m memoryItineraries) AddItem(_ context.Context, id primitive.ObjectID, userID string, item itinerary.Item) (*itinerary.Itinerary, error) {
+	if m[id] == nil {
+		m[id] = &itinerary.Itinerary{ConversationID: id, UserID: userID}
+	}
+	m[id].Items = append(m[id].Items, item)
+	return m[id], nil
+}
+
+func TestAddItineraryItemTool(t *testing.T) {
+	conv := &model.Conversation{ID: primitive.NewObjectID(), UserID: "alice"}
+	store := memoryItineraries{}
+	tool := NewAddItineraryItemTool(conv, store)
+
+	got, err := tool.Execute(context.Background(), json.RawMessage(`{"kind": "flight", "title": "Flight TP1039 BCN → LIS",
+		"start_at": "2025-10-18T10:05", "timezone": "Europe/Madrid", "end_at": "2025-10-18T10:55", "end_timezone": "Europe/Lisbon"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Added to the itinerary: Flight TP1039 BCN → LIS, Sat 18 Oct 2025 10:05 (Europe/Madrid)."; !strings.HasPrefix(got, want) {
+		t.Errorf("Execute() = %q, want prefix %q", got, want)
+	}
+
+	item := store[conv.ID].Items[0]
+	if item.StartAt.Format("15:04") != "08:05" || item.EndAt.Format("15:04") != "09:55" || item.EndTimezone != "Europe/Lisbon" {
+		t.Errorf("stored item = %+v, want times in UTC with the arrival timezone", item)
+	}
+
+	for _, args := range []string{
+		`{"kind": "activity", "title": "Fado show", "start_at": "2025-10-18T21:00", "timezone": "Lisbon"}`,
+		`{"kind": "activity", "title": "Fado show", "start_at": "2025-10-18T21:00", "timezone": "Europe/Lisbon", "end_at": "2025-10-18T20:00"}`,
+	} {
+		if _, err := tool.Execute(context.Background(), json.RawMessage(args)); err == nil {
+			t.Errorf("Execute(%s) succeeded", args)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/acai-travel/tech-challenge/internal/vcr"
+)
+
+// ToolFunc executes the tool called name with the provided arguments.
+type ToolFunc func(ctx context.Context, name string, args json.RawMessage) (string, error)
+
+// Middleware wraps a ToolFunc to apply a cross-cutting concern, such as caching,
+// redaction or retries, to every tool of a registry.
+type Middleware func(next ToolFunc) ToolFunc
+
+// chain wraps the execution of t in middleware, the first one being the outermost.
+func chain(t Tool, middleware []Middleware) ToolFunc {
+	next := func(ctx context.Context, _ string, args json.RawMessage) (string, error) {
+		return t.Execute(ctx, args)
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+	return next
+}
+
+// PostProcess returns middleware transforming the successful results of the named
+// tools, or of every tool when no names are given.
+func PostProcess(fn func(name, result string) string, names ...string) Middleware {
+	only := make(map[string]bool, len(names))
+	for _, name := range names {
+		only[name] = true
+	}
+
+	return func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			result, err := next(ctx, name, args)
+			if err != nil || (len(only) > 0 && !only[name]) {
+				return result, err
+			}
+			return fn(name, result), nil
+		}
+	}
+}
+
+// TruncateResults returns middleware cutting tool results down to max bytes, so
+// a verbose upstream response doesn't fill the model's context.
+func TruncateResults(max int) Middleware {
+	return PostProcess(func(_, result string) string {
+		if len(result) <= max {
+			return result
+		}
+		return result[:max] + "... [truncated]"
+	})
+}
+
+// WithTransport returns middleware sending the requests of the tools to third-party
+// APIs through rt, like a vcr.Cassette recording or replaying them.
+func WithTransport(rt http.RoundTripper) Middleware {
+	return func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			return next(vcr.WithTransport(ctx, rt), name, args)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/vcr"
+	"github.com/openai/openai-go/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type echoTool struct{ name string }
+
+func (t echoTool) Name() string        { return t.name }
+func (t echoTool) Description() string { return "" }
+func (t echoTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{Name: t.name})
+}
+func (t echoTool) Execute(_ context.Context, args json.RawMessage) (string, error) {
+	return string(args), nil
+}
+
+func TestRegistry_Use(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies middleware in order", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register(echoTool{name: "echo"})
+
+		tag := func(s string) Middleware {
+			return func(next ToolFunc) ToolFunc {
+				return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+					result, err := next(ctx, name, args)
+					return s + "(" + result + ")", err
+				}
+			}
+		}
+		r.Use(tag("outer"), tag("inner"))
+
+		got, err := r.Execute(ctx, "echo", []byte("x"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "outer(inner(x))" {
+			t.Errorf("result = %q, want %q", got, "outer(inner(x))")
+		}
+	})
+
+	t.Run("post-processes only the named tools", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register(echoTool{name: "echo"})
+		r.Register(echoTool{name: "other"})
+		r.Use(PostProcess(func(_, result string) string { return strings.ToUpper(result) }, "echo"))
+
+		if got, _ := r.Execute(ctx, "echo", []byte("abc")); got != "ABC" {
+			t.Errorf("echo result = %q, want %q", got, "ABC")
+		}
+		if got, _ := r.Execute(ctx, "other", []byte("abc")); got != "abc" {
+			t.Errorf("other result = %q, want %q", got, "abc")
+		}
+	})
+
+	t.Run("truncates long results", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register(echoTool{name: "echo"})
+		r.Use(TruncateResults(3))
+
+		if got, _ := r.Execute(ctx, "echo", []byte("abcdef")); got != "abc... [truncated]" {
+			t.Errorf("result = %q, want %q", got, "abc... [truncated]")
+		}
+	})
+}
+
+func TestWithTransport(t *testing.T) {
+	// The cassettes were recorded with real keys, which replaying doesn't need
+	t.Setenv("WEATHER_API_KEY", "replay")
+	t.Setenv("AMADEUS_API_KEY", "replay")
+	t.Setenv("AMADEUS_API_SECRET", "replay")
+	t.Setenv("AMADEUS_ENV", "")
+	t.Setenv("AMADEUS_BASE_URL", "")
+	t.Setenv("AMADEUS_CURRENCY", "")
+	ctx := context.Background()
+
+	tests := []struct {
+		cassette string
+		tool     string
+		args     string
+		want     string
+	}{
+		{
+			cassette: "weather_lisbon.json",
+			tool:     "get_weather",
+			args:     `{"location": "Lisbon"}`,
+			want:     "Lisbon, Lisboa, Portugal: 19°C, Partly cloudy. Feels 18°C. Wind 14 kph. Humidity 72%",
+		},
+		{
+			cassette: "flights_bcn_lis.json",
+			tool:     "get_flight_prices",
+			args:     `{"origin": "BCN", "destination": "LIS", "departureDate": "2025-11-14"}`,
+			want:     "Found 2 flight options from BCN to LIS on 2025-11-14:\n1. BCN → LIS at 07:10: 59.38 EUR (incl. 18.38 EUR taxes and fees)\n2. BCN → LIS at 18:45: 74.10 EUR (incl. 19.10 EUR taxes and fees)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cassette, func(t *testing.T) {
+			cassette, err := vcr.Open(filepath.Join("testdata", "cassettes", tt.cassette), vcr.ModeReplay)
+			if err != nil {
+				t.Fatal(err)
+			}
+			conv := &model.Conversation{ID: primitive.NewObjectID()}
+			r := NewRegistry()
+			r.Register(NewGetWeatherTool(conv))
+			r.Register(NewGetFlightPricesTool(conv))
+			r.Use(WithTransport(cassette))
+
+			got, err := r.Execute(ctx, tt.tool, []byte(tt.args))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
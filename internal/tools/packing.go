@@ -0,0 +1,292 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/geo"
+	"github.com/acai-travel/tech-challenge/internal/vcr"
+	"github.com/openai/openai-go/v2"
+)
+
+// maxForecastDays is how far ahead forecasts go; later trip days are packed for
+// without weather data.
+const maxForecastDays = 7
+
+// PackingList is a packing checklist for a trip, grouped by category.
+type PackingList struct {
+	Destination string            `json:"destination"`
+	StartDate   string            `json:"start_date"`
+	EndDate     string            `json:"end_date"`
+	Days        int               `json:"days"`
+	Weather     *PackingWeather   `json:"weather"`
+	Categories  []PackingCategory `json:"categories"`
+	Notes       []string          `json:"notes,omitempty"`
+}
+
+// PackingWeather summarises the forecast of the trip days it covers.
+type PackingWeather struct {
+	DaysCovered     int      `json:"days_covered"`
+	MinC            float64  `json:"min_c"`
+	MaxC            float64  `json:"max_c"`
+	MaxChanceOfRain int      `json:"max_chance_of_rain"`
+	Conditions      []string `json:"conditions"`
+}
+
+// PackingCategory groups the items of a packing list, e.g. clothing.
+type PackingCategory struct {
+	Name  string        `json:"name"`
+	Items []PackingItem `json:"items"`
+}
+
+// PackingItem is an item to pack, with why it is needed when it depends on the trip.
+type PackingItem struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// packingArgs are the arguments of generate_packing_list.
+type packingArgs struct {
+	Location  string `json:"location" description:"City or region of the trip. Leave empty to use the location discussed earlier in the conversation."`
+	StartDate string `json:"startDate" description:"First day of the trip in YYYY-MM-DD format. Leave empty to use the travel date discussed earlier."`
+	EndDate   string `json:"endDate" description:"Last day of the trip in YYYY-MM-DD format" jsonschema:"required"`
+	TripType  string `json:"tripType" description:"Kind of trip, adding the items it needs (optional)" jsonschema:"enum=leisure|business|beach|hiking|ski"`
+}
+
+// GeneratePackingListTool builds a packing checklist from the forecast of the trip
+type GeneratePackingListTool struct {
+	conv     *model.Conversation
+	geocoder geo.Geocoder
+	forecast func(ctx context.Context, location string, days int) ([]ForecastDay, error)
+	now      func() time.Time
+}
+
+func NewGeneratePackingListTool(conv *model.Conversation) *GeneratePackingListTool {
+	httpClient := &http.Client{Timeout: 5 * time.Second, Transport: vcr.Transport}
+	return &GeneratePackingListTool{
+		conv:     conv,
+		geocoder: places,
+		forecast: func(ctx context.Context, location string, days int) ([]ForecastDay, error) {
+			return FetchForecast(ctx, httpClient, os.Getenv("WEATHER_API_KEY"), location, days)
+		},
+		now: time.Now,
+	}
+}
+
+func (t *GeneratePackingListTool) Name() string {
+	return "generate_packing_list"
+}
+
+func (t *GeneratePackingListTool) Source() string {
+	return "WeatherAPI"
+}
+
+func (t *GeneratePackingListTool) TTL() time.Duration {
+	return 3 * time.Hour
+}
+
+func (t *GeneratePackingListTool) Description() string {
+	return "Generate a packing checklist for a trip from the weather forecast of its destination and dates. Returns JSON with the items grouped by category; present it to the user as a checklist."
+}
+
+func (t *GeneratePackingListTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[packingArgs](),
+	})
+}
+
+func (t *GeneratePackingListTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[packingArgs](args)
+	if err != nil {
+		return "", err
+	}
+
+	location := resolveLocation(t.conv, payload.Location)
+	if location == "" {
+		return NeedsClarification("location", "Where are you traveling to?"), nil
+	}
+
+	startDate := strings.TrimSpace(payload.StartDate)
+	if startDate == "" {
+		startDate = t.conv.Entities.Date
+	}
+	if startDate == "" {
+		return NeedsClarification("startDate", "When does your trip start?"), nil
+	}
+	endDate := strings.TrimSpace(payload.EndDate)
+	if endDate == "" {
+		return NeedsClarification("endDate", "When does your trip end?"), nil
+	}
+
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return "", fmt.Errorf("invalid startDate %q, expected YYYY-MM-DD", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return "", fmt.Errorf("invalid endDate %q, expected YYYY-MM-DD", endDate)
+	}
+	if end.Before(start) {
+		return "", fmt.Errorf("endDate %s is before startDate %s", endDate, startDate)
+	}
+
+	place, clarification, err := locate(ctx, t.conv, t.geocoder, "location", location)
+	if err != nil {
+		return "", fmt.Errorf("forecast lookup failed: %w", err)
+	}
+	if clarification != "" {
+		return clarification, nil
+	}
+
+	list := PackingList{
+		Destination: place.Name,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Days:        int(end.Sub(start).Hours()/24) + 1,
+	}
+
+	// The forecast covers today and the next days, of which only the trip's count
+	today := t.now().UTC().Truncate(24 * time.Hour)
+	if days := int(end.Sub(today).Hours()/24) + 1; days > 0 && !start.After(today.AddDate(0, 0, maxForecastDays-1)) {
+		fds, err := t.forecast(ctx, placeQuery(place), min(days, maxForecastDays))
+		if err != nil {
+			return "", fmt.Errorf("forecast lookup failed: %w", err)
+		}
+		list.Weather = summarizeForecast(fds, startDate, endDate)
+	}
+	switch {
+	case list.Weather == nil:
+		list.Notes = append(list.Notes, "No forecast is available for these dates yet; check the weather again closer to the trip.")
+	case list.Weather.DaysCovered < list.Days:
+		list.Notes = append(list.Notes, fmt.Sprintf("The forecast only covers %d of the %d trip days.", list.Weather.DaysCovered, list.Days))
+	}
+
+	list.Categories = packingCategories(list.Days, list.Weather, payload.TripType)
+
+	out, err := json.Marshal(list)
+	if err != nil {
+		return "", fmt.Errorf("encode packing list: %w", err)
+	}
+	return string(out), nil
+}
+
+// summarizeForecast summarises the forecast days between startDate and endDate, or
+// returns nil when none are.
+func summarizeForecast(fds []ForecastDay, startDate, endDate string) *PackingWeather {
+	var w *PackingWeather
+	for _, d := range fds {
+		if d.Date < startDate || d.Date > endDate {
+			continue
+		}
+		if w == nil {
+			w = &PackingWeather{MinC: d.MinC, MaxC: d.MaxC}
+		}
+		w.DaysCovered++
+		w.MinC = min(w.MinC, d.MinC)
+		w.MaxC = max(w.MaxC, d.MaxC)
+		w.MaxChanceOfRain = max(w.MaxChanceOfRain, d.ChanceOfRain)
+		if !slices.Contains(w.Conditions, d.Condition) {
+			w.Conditions = append(w.Conditions, d.Condition)
+		}
+	}
+	return w
+}
+
+// packingCategories builds the checklist of a trip of days days, for its weather when
+// known and its trip type.
+func packingCategories(days int, w *PackingWeather, tripType string) []PackingCategory {
+	// Pack for a week at most, doing laundry on longer trips
+	outfits := min(days, 7)
+
+	clothing := []PackingItem{
+		{Item: "Underwear", Quantity: outfits + 1},
+		{Item: "Socks", Quantity: outfits + 1},
+		{Item: "Tops", Quantity: outfits},
+		{Item: "Trousers or skirts", Quantity: max(outfits/3, 1)},
+		{Item: "Sleepwear", Quantity: 1},
+		{Item: "Comfortable walking shoes", Quantity: 1},
+	}
+	var weather []PackingItem
+	if w != nil {
+		if w.MaxC >= 24 {
+			clothing = append(clothing, PackingItem{Item: "Shorts", Quantity: max(outfits/2, 1), Reason: fmt.Sprintf("highs of %.0f°C", w.MaxC)})
+			weather = append(weather,
+				PackingItem{Item: "Sunscreen", Reason: fmt.Sprintf("highs of %.0f°C", w.MaxC)},
+				PackingItem{Item: "Sunglasses"},
+				PackingItem{Item: "Sun hat"},
+			)
+		}
+		switch {
+		case w.MinC <= 0:
+			clothing = append(clothing,
+				PackingItem{Item: "Warm winter coat", Quantity: 1, Reason: fmt.Sprintf("lows of %.0f°C", w.MinC)},
+				PackingItem{Item: "Thermal layers", Quantity: 2},
+				PackingItem{Item: "Gloves, scarf and beanie"},
+			)
+		case w.MinC <= 10:
+			clothing = append(clothing,
+				PackingItem{Item: "Warm jacket", Quantity: 1, Reason: fmt.Sprintf("lows of %.0f°C", w.MinC)},
+				PackingItem{Item: "Sweater", Quantity: 2},
+			)
+		case w.MinC <= 16:
+			clothing = append(clothing, PackingItem{Item: "Light jacket or cardigan", Quantity: 1, Reason: fmt.Sprintf("cool evenings, %.0f°C", w.MinC)})
+		}
+		if w.MaxChanceOfRain >= 40 {
+			weather = append(weather,
+				PackingItem{Item: "Compact umbrella", Reason: fmt.Sprintf("up to %d%% chance of rain", w.MaxChanceOfRain)},
+				PackingItem{Item: "Waterproof jacket"},
+			)
+		}
+	} else {
+		clothing = append(clothing, PackingItem{Item: "A layer for cooler evenings", Quantity: 1})
+	}
+
+	categories := []PackingCategory{
+		{Name: "Documents", Items: []PackingItem{
+			{Item: "Passport or ID card"},
+			{Item: "Tickets and booking confirmations"},
+			{Item: "Travel insurance details"},
+			{Item: "Payment cards and some cash"},
+		}},
+		{Name: "Clothing", Items: clothing},
+		{Name: "Toiletries", Items: []PackingItem{
+			{Item: "Toothbrush and toothpaste"},
+			{Item: "Deodorant"},
+			{Item: "Prescription medication"},
+			{Item: "Small first-aid kit"},
+		}},
+		{Name: "Electronics", Items: []PackingItem{
+			{Item: "Phone and charger"},
+			{Item: "Power adapter"},
+		}},
+	}
+	if len(weather) > 0 {
+		categories = append(categories, PackingCategory{Name: "Weather", Items: weather})
+	}
+
+	var extra []PackingItem
+	switch tripType {
+	case "business":
+		extra = []PackingItem{{Item: "Business outfits", Quantity: outfits}, {Item: "Laptop and charger"}, {Item: "Business cards"}}
+	case "beach":
+		extra = []PackingItem{{Item: "Swimwear", Quantity: 2}, {Item: "Beach towel"}, {Item: "Flip-flops"}}
+	case "hiking":
+		extra = []PackingItem{{Item: "Hiking boots"}, {Item: "Daypack"}, {Item: "Reusable water bottle"}, {Item: "Blister plasters"}}
+	case "ski":
+		extra = []PackingItem{{Item: "Ski jacket and trousers"}, {Item: "Goggles"}, {Item: "Thermal base layers", Quantity: 2}, {Item: "Lip balm with SPF"}}
+	}
+	if len(extra) > 0 {
+		categories = append(categories, PackingCategory{Name: "Activities", Items: extra})
+	}
+	return categories
+}
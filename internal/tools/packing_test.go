@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+func TestGeneratePackingListTool_Execute(t *testing.T) {
+	var requested int
+	tool := NewGeneratePackingListTool(&model.Conversation{Entities: model.Entities{Location: "London", Date: "2025-11-11"}})
+	tool.geocoder = stubGeocoder{}
+	tool.now = func() time.Time { return time.Date(2025, 11, 10, 9, 0, 0, 0, time.UTC) }
+	tool.forecast = func(_ context.Context, location string, days int) ([]ForecastDay, error) {
+		if location != "London" {
+			t.Errorf("forecast location = %q, want the remembered London", location)
+		}
+		requested = days
+		return []ForecastDay{
+			{Date: "2025-11-10", Condition: "Sunny", MinC: 12, MaxC: 26},
+			{Date: "2025-11-11", Condition: "Moderate rain", MinC: 8, MaxC: 12, ChanceOfRain: 89},
+			{Date: "2025-11-12", Condition: "Partly cloudy", MinC: 6, MaxC: 10, ChanceOfRain: 20},
+		}, nil
+	}
+
+	got, err := tool.Execute(context.Background(), json.RawMessage(`{"endDate": "2025-11-12", "tripType": "business"}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var list PackingList
+	if err := json.Unmarshal([]byte(got), &list); err != nil {
+		t.Fatalf("Execute() = %q, want a JSON packing list: %v", got, err)
+	}
+	if requested != 3 {
+		t.Errorf("requested %d forecast days, want 3 to reach the end of the trip", requested)
+	}
+	if list.StartDate != "2025-11-11" || list.Days != 2 {
+		t.Errorf("trip = %s, %d days, want 2 days from the remembered 2025-11-11", list.StartDate, list.Days)
+	}
+	// The sunny day before the trip doesn't count
+	if w := list.Weather; w == nil || w.DaysCovered != 2 || w.MinC != 6 || w.MaxC != 12 || w.MaxChanceOfRain != 89 {
+		t.Errorf("weather = %+v, want the 2 trip days, 6–12°C and 89%% rain", list.Weather)
+	}
+
+	items := map[string]bool{}
+	for _, c := range list.Categories {
+		for _, item := range c.Items {
+			items[item.Item] = true
+		}
+	}
+	for _, want := range []string{"Warm jacket", "Compact umbrella", "Laptop and charger", "Passport or ID card"} {
+		if !items[want] {
+			t.Errorf("packing list misses %q", want)
+		}
+	}
+	if items["Sunscreen"] {
+		t.Error("packing list has sunscreen for a cold trip")
+	}
+}
+
+func TestGeneratePackingListTool_Execute_BeyondForecast(t *testing.T) {
+	tool := NewGeneratePackingListTool(&model.Conversation{})
+	tool.geocoder = stubGeocoder{}
+	tool.now = func() time.Time { return time.Date(2025, 11, 10, 9, 0, 0, 0, time.UTC) }
+	tool.forecast = func(context.Context, string, int) ([]ForecastDay, error) {
+		t.Error("forecast fetched for a trip beyond the forecast")
+		return nil, nil
+	}
+
+	got, err := tool.Execute(context.Background(), json.RawMessage(`{"location": "Lisbon", "startDate": "2025-12-20", "endDate": "2025-12-27"}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var list PackingList
+	if err := json.Unmarshal([]byte(got), &list); err != nil {
+		t.Fatalf("Execute() = %q, want a JSON packing list: %v", got, err)
+	}
+	if list.Weather != nil || len(list.Notes) == 0 {
+		t.Errorf("weather = %+v, notes = %v, want no weather and a note saying so", list.Weather, list.Notes)
+	}
+	i := slices.IndexFunc(list.Categories, func(c PackingCategory) bool { return c.Name == "Clothing" })
+	if i < 0 || list.Categories[i].Items[0].Quantity != 8 {
+		t.Errorf("categories = %+v, want clothing for a week plus one", list.Categories)
+	}
+}
@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StatusDenied marks a tool result refused by a Policy.
+const StatusDenied = "denied"
+
+// Policy restricts the tools of a registry, and the arguments they may be called with,
+// by the user and persona of the conversation. Every rule matching a conversation
+// applies.
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule is a rule of a Policy. Empty matchers match every conversation.
+type PolicyRule struct {
+	// Users are the user IDs the rule applies to.
+	Users []string `json:"users,omitempty"`
+	// Personas are the personas the rule applies to, "" for conversations without one.
+	Personas []string `json:"personas,omitempty"`
+	// Allow names the only tools that may be called, any when empty.
+	Allow []string `json:"allow,omitempty"`
+	// Deny names tools that may not be called.
+	Deny []string `json:"deny,omitempty"`
+	// Constraints bound the arguments of tools, by tool name.
+	Constraints map[string][]Constraint `json:"constraints,omitempty"`
+}
+
+// Constraint bounds a top-level argument of a tool. Arguments left out or empty aren't
+// checked, as tools fill them from the conversation.
+type Constraint struct {
+	Arg string `json:"arg"`
+	// Min and Max bound numbers.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// OneOf lists the allowed values of strings, compared without case.
+	OneOf []string `json:"one_of,omitempty"`
+}
+
+// Subject is who a tool is called for.
+type Subject struct {
+	UserID  string
+	Persona string
+}
+
+// Denial is the result of a tool call refused by a Policy, for the model to answer
+// without it.
+type Denial struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// Denied returns a tool result telling the model the call was refused and why.
+func Denied(reason string) string {
+	b, _ := json.Marshal(Denial{Status: StatusDenied, Reason: reason})
+	return string(b)
+}
+
+// ParseDenial reports whether a tool result is a call refused by a Policy.
+func ParseDenial(result string) (Denial, bool) {
+	var d Denial
+	if err := json.Unmarshal([]byte(result), &d); err != nil || d.Status != StatusDenied {
+		return Denial{}, false
+	}
+	return d, true
+}
+
+// LoadPolicyFromEnv reads the policy in the JSON file at TOOL_POLICY_FILE, nil when it
+// is unset.
+func LoadPolicyFromEnv() (*Policy, error) {
+	path := os.Getenv("TOOL_POLICY_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read TOOL_POLICY_FILE: %w", err)
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse tool policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate reports whether the policy is usable.
+func (p *Policy) Validate() error {
+	for i, rule := range p.Rules {
+		for name, constraints := range rule.Constraints {
+			for _, c := range constraints {
+				if c.Arg == "" {
+					return fmt.Errorf("tool policy rule %d: constraint of %s needs an arg", i, name)
+				}
+				if c.Min != nil && c.Max != nil && *c.Min > *c.Max {
+					return fmt.Errorf("tool policy rule %d: %s.%s has min above max", i, name, c.Arg)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Merge returns the rules of p followed by those of other, either of which may be nil.
+func (p *Policy) Merge(other *Policy) *Policy {
+	switch {
+	case p == nil:
+		return other
+	case other == nil:
+		return p
+	}
+	return &Policy{Rules: slices.Concat(p.Rules, other.Rules)}
+}
+
+// matches reports whether the rule applies to s.
+func (r PolicyRule) matches(s Subject) bool {
+	return (len(r.Users) == 0 || slices.Contains(r.Users, s.UserID)) &&
+		(len(r.Personas) == 0 || slices.Contains(r.Personas, s.Persona))
+}
+
+// Allowed reports whether the tool called name may be used for s.
+func (p *Policy) Allowed(s Subject, name string) bool {
+	if p == nil {
+		return true
+	}
+	for _, rule := range p.Rules {
+		if !rule.matches(s) {
+			continue
+		}
+		if slices.Contains(rule.Deny, name) || (len(rule.Allow) > 0 && !slices.Contains(rule.Allow, name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Restrict removes the tools the policy doesn't allow for s from r, so the model isn't
+// offered them.
+func (p *Policy) Restrict(r *Registry, s Subject) {
+	if p == nil {
+		return
+	}
+	var allowed []string
+	for _, name := range r.List() {
+		if p.Allowed(s, name) {
+			allowed = append(allowed, name)
+		}
+	}
+	r.Retain(allowed...)
+}
+
+// check returns why a call of the tool called name with args is refused for s, empty
+// when it isn't.
+func (p *Policy) check(s Subject, name string, args json.RawMessage) string {
+	if !p.Allowed(s, name) {
+		return fmt.Sprintf("%s is not available to this user", name)
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(args, &values); err != nil {
+		// Malformed arguments are left to the tool to report
+		return ""
+	}
+	for _, rule := range p.Rules {
+		if !rule.matches(s) {
+			continue
+		}
+		for _, c := range rule.Constraints[name] {
+			if reason := c.check(values[c.Arg]); reason != "" {
+				return fmt.Sprintf("%s of %s %s", c.Arg, name, reason)
+			}
+		}
+	}
+	return ""
+}
+
+// check returns why value breaks the constraint, empty when it doesn't.
+func (c Constraint) check(value any) string {
+	switch v := value.(type) {
+	case float64:
+		if c.Min != nil && v < *c.Min {
+			return fmt.Sprintf("must be at least %g", *c.Min)
+		}
+		if c.Max != nil && v > *c.Max {
+			return fmt.Sprintf("must be at most %g", *c.Max)
+		}
+	case string:
+		if v == "" || len(c.OneOf) == 0 {
+			return ""
+		}
+		if !slices.ContainsFunc(c.OneOf, func(allowed string) bool { return strings.EqualFold(allowed, v) }) {
+			return fmt.Sprintf("must be one of %s", strings.Join(c.OneOf, ", "))
+		}
+	}
+	return ""
+}
+
+// EnforcePolicy returns middleware refusing the tool calls p doesn't allow for s. A
+// refused call isn't an error: its result tells the model why, for it to answer
+// without the tool or ask the user for something allowed.
+func EnforcePolicy(p *Policy, s Subject) Middleware {
+	return func(next ToolFunc) ToolFunc {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			if p == nil {
+				return next(ctx, name, args)
+			}
+			if reason := p.check(s, name, args); reason != "" {
+				recordDenial(ctx, name)
+				return Denied(reason), nil
+			}
+			return next(ctx, name, args)
+		}
+	}
+}
+
+// recordDenial counts a call of the tool called name refused by the policy, on the tool
+// span too.
+func recordDenial(ctx context.Context, name string) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("tool.denied", true))
+	if deniedCounter != nil {
+		deniedCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("tool.name", name)))
+	}
+}
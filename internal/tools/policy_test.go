@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `{"rules": [
+	{"deny": ["search_transfers"], "constraints": {
+		"get_weather_forecast": [{"arg": "days", "max": 3}],
+		"get_flight_prices": [{"arg": "origin", "one_of": ["BCN", "MAD"]}, {"arg": "maxPrice", "min": 50}]
+	}},
+	{"users": ["intern"], "allow": ["get_weather", "get_weather_forecast"]},
+	{"personas": ["backpacker"], "deny": ["get_weather"]}
+]}`
+
+func TestPolicy(t *testing.T) {
+	var p Policy
+	if err := json.Unmarshal([]byte(testPolicy), &p); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	newRegistry := func(s Subject) *Registry {
+		r := NewRegistry()
+		for _, name := range []string{"get_weather", "get_weather_forecast", "get_flight_prices", "search_transfers"} {
+			r.Register(echoTool{name: name})
+		}
+		p.Restrict(r, s)
+		r.Use(EnforcePolicy(&p, s))
+		return r
+	}
+
+	t.Run("restricts the tools offered", func(t *testing.T) {
+		tests := []struct {
+			subject Subject
+			want    []string
+		}{
+			{Subject{UserID: "traveller"}, []string{"get_flight_prices", "get_weather", "get_weather_forecast"}},
+			{Subject{UserID: "intern"}, []string{"get_weather", "get_weather_forecast"}},
+			{Subject{UserID: "intern", Persona: "backpacker"}, []string{"get_weather_forecast"}},
+		}
+		for _, tt := range tests {
+			got := newRegistry(tt.subject).List()
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("tools of %+v = %v, want %v", tt.subject, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("refuses calls breaking constraints", func(t *testing.T) {
+		r := newRegistry(Subject{UserID: "traveller"})
+		tests := []struct {
+			name, args string
+			denied     string
+		}{
+			{"get_weather_forecast", `{"location": "Lisbon", "days": 3}`, ""},
+			{"get_weather_forecast", `{"location": "Lisbon", "days": 7}`, "days of get_weather_forecast must be at most 3"},
+			{"get_flight_prices", `{"origin": "bcn", "destination": "LIS"}`, ""},
+			{"get_flight_prices", `{"destination": "LIS"}`, ""},
+			{"get_flight_prices", `{"origin": "JFK", "destination": "LIS"}`, "origin of get_flight_prices must be one of BCN, MAD"},
+			{"get_flight_prices", `{"origin": "BCN", "maxPrice": 20}`, "maxPrice of get_flight_prices must be at least 50"},
+		}
+		for _, tt := range tests {
+			result, err := r.Execute(context.Background(), tt.name, json.RawMessage(tt.args))
+			if err != nil {
+				t.Fatalf("Execute(%s) error = %v", tt.args, err)
+			}
+			d, denied := ParseDenial(result)
+			switch {
+			case tt.denied == "" && denied:
+				t.Errorf("Execute(%s) = %q, want it run", tt.args, result)
+			case tt.denied != "" && d.Reason != tt.denied:
+				t.Errorf("Execute(%s) = %q, want it denied with %q", tt.args, result, tt.denied)
+			}
+		}
+	})
+
+	t.Run("refuses tools it doesn't allow", func(t *testing.T) {
+		// A registry built without Restrict, like a custom one, still enforces the policy
+		r := NewRegistry()
+		r.Register(echoTool{name: "search_transfers"})
+		s := Subject{UserID: "traveller"}
+		r.Use(EnforcePolicy(&p, s))
+
+		result, err := r.Execute(context.Background(), "search_transfers", json.RawMessage(`{}`))
+		if d, ok := ParseDenial(result); err != nil || !ok || !strings.Contains(d.Reason, "not available") {
+			t.Errorf("Execute() = %q, %v, want it denied", result, err)
+		}
+	})
+
+	t.Run("no policy allows everything", func(t *testing.T) {
+		var none *Policy
+		if !none.Allowed(Subject{}, "search_transfers") {
+			t.Error("Allowed() without a policy = false")
+		}
+		if merged := none.Merge(&p); merged != &p {
+			t.Error("Merge() into no policy isn't the other policy")
+		}
+	})
+}
+
+func TestLoadPolicyFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "policy.json")
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(valid, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte(`{"rules": [{"constraints": {"get_weather_forecast": [{"arg": "days", "min": 5, "max": 3}]}}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path      string
+		wantRules int
+		wantErr   bool
+	}{
+		{path: "", wantRules: 0},
+		{path: valid, wantRules: 3},
+		{path: invalid, wantErr: true},
+		{path: filepath.Join(dir, "missing.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("TOOL_POLICY_FILE", tt.path)
+		p, err := LoadPolicyFromEnv()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("LoadPolicyFromEnv(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+		}
+		if p != nil && len(p.Rules) != tt.wantRules {
+			t.Errorf("LoadPolicyFromEnv(%q) has %d rules, want %d", tt.path, len(p.Rules), tt.wantRules)
+		}
+	}
+}
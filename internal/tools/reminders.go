@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/reminder"
+	"github.com/openai/openai-go/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReminderStore persists scheduled reminders.
+type ReminderStore interface {
+	CreateReminder(ctx context.Context, rem *reminder.Reminder) error
+}
+
+// setReminderArgs are the arguments of set_reminder.
+type setReminderArgs struct {
+	Message  string `json:"message" description:"What to remind the user about, written as the reminder itself (e.g. 'Check in for your flight to Lisbon')." jsonschema:"required"`
+	DueAt    string `json:"due_at" description:"Local date and time of the reminder in the format YYYY-MM-DDTHH:MM. Use get_today_date to resolve relative times." jsonschema:"required"`
+	Timezone string `json:"timezone" description:"IANA timezone of due_at, e.g. 'Europe/Lisbon'. Use the timezone the user is in at that time. Defaults to UTC."`
+}
+
+// SetReminderTool schedules a reminder delivered through the user's notification channels
+type SetReminderTool struct {
+	conv  *model.Conversation
+	store ReminderStore
+	now   func() time.Time
+}
+
+func NewSetReminderTool(conv *model.Conversation, store ReminderStore) *SetReminderTool {
+	return &SetReminderTool{conv: conv, store: store, now: time.Now}
+}
+
+func (t *SetReminderTool) Name() string {
+	return "set_reminder"
+}
+
+func (t *SetReminderTool) Description() string {
+	return "Schedules a reminder for the user, e.g. 'remind me to check in 24h before my flight'. The reminder is sent to the user's email or Slack at the given local time."
+}
+
+func (t *SetReminderTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[setReminderArgs](),
+	})
+}
+
+func (t *SetReminderTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[setReminderArgs](args)
+	if err != nil {
+		return "", err
+	}
+
+	if t.conv.UserID == "" {
+		return "", fmt.Errorf("reminders are only available to signed-in users")
+	}
+
+	message := strings.TrimSpace(payload.Message)
+	if message == "" {
+		return "", fmt.Errorf("reminder message is required")
+	}
+
+	dueAt, loc, err := reminder.ParseDueAt(payload.DueAt, payload.Timezone)
+	if err != nil {
+		return "", err
+	}
+
+	now := t.now()
+	if !dueAt.After(now) {
+		return "", fmt.Errorf("reminder time %s is in the past, it is now %s", dueAt.Format(time.RFC3339), now.In(loc).Format(time.RFC3339))
+	}
+
+	rem := &reminder.Reminder{
+		ID:             primitive.NewObjectID(),
+		UserID:         t.conv.UserID,
+		ConversationID: t.conv.ID,
+		Message:        message,
+		DueAt:          dueAt.UTC(),
+		Timezone:       loc.String(),
+		Status:         reminder.StatusScheduled,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := t.store.CreateReminder(ctx, rem); err != nil {
+		return "", fmt.Errorf("failed to schedule reminder: %w", err)
+	}
+
+	return fmt.Sprintf("Reminder scheduled for %s (%s): %s", dueAt.Format("Mon 02 Jan 2006 15:04"), loc, message), nil
+}
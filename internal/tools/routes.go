@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/geo"
+	"github.com/acai-travel/tech-challenge/internal/vcr"
+)
+
+// TravelMode is how a route is traveled.
+type TravelMode string
+
+const (
+	TravelModeDrive   TravelMode = "drive"
+	TravelModeTransit TravelMode = "transit"
+	TravelModeWalk    TravelMode = "walk"
+)
+
+// Route is the estimated travel time and distance between two places.
+type Route struct {
+	From     string
+	To       string
+	Duration time.Duration
+	// DistanceMeters is 0 when the provider doesn't report it.
+	DistanceMeters int
+}
+
+// RouteProvider estimates travel times. Providers differ in coverage and cost, so the
+// travel time tool only depends on this interface.
+type RouteProvider interface {
+	// Source names the routing service, for citations.
+	Source() string
+	// Route estimates traveling from one place to another, given as names, addresses or
+	// "lat,lon" coordinates.
+	Route(ctx context.Context, from, to string, mode TravelMode) (Route, error)
+}
+
+// NewRouteProviderFromEnv returns the Google Distance Matrix provider when
+// GOOGLE_MAPS_API_KEY is set, else the OSRM one.
+func NewRouteProviderFromEnv() RouteProvider {
+	if key := os.Getenv("GOOGLE_MAPS_API_KEY"); key != "" {
+		return NewGoogleRouteProvider(key)
+	}
+	return NewOSRMRouteProvider()
+}
+
+// GoogleRouteProvider estimates travel times with the Google Distance Matrix API, which
+// supports every travel mode and resolves place names itself.
+type GoogleRouteProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+}
+
+func NewGoogleRouteProvider(apiKey string) *GoogleRouteProvider {
+	return &GoogleRouteProvider{
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: vcr.Transport},
+		baseURL:    "https://maps.googleapis.com",
+		apiKey:     apiKey,
+	}
+}
+
+func (p *GoogleRouteProvider) Source() string {
+	return "Google Maps"
+}
+
+var googleModes = map[TravelMode]string{
+	TravelModeDrive:   "driving",
+	TravelModeTransit: "transit",
+	TravelModeWalk:    "walking",
+}
+
+func (p *GoogleRouteProvider) Route(ctx context.Context, from, to string, mode TravelMode) (Route, error) {
+	u, err := url.Parse(p.baseURL + "/maps/api/distancematrix/json")
+	if err != nil {
+		return Route{}, fmt.Errorf("build request: %w", err)
+	}
+	q := u.Query()
+	q.Set("origins", from)
+	q.Set("destinations", to)
+	q.Set("mode", googleModes[mode])
+	q.Set("key", p.apiKey)
+	u.RawQuery = q.Encode()
+
+	var data struct {
+		Status       string   `json:"status"`
+		ErrorMessage string   `json:"error_message"`
+		Origins      []string `json:"origin_addresses"`
+		Destinations []string `json:"destination_addresses"`
+		Rows         []struct {
+			Elements []struct {
+				Status   string `json:"status"`
+				Duration struct {
+					Value int `json:"value"`
+				} `json:"duration"`
+				Distance struct {
+					Value int `json:"value"`
+				} `json:"distance"`
+			} `json:"elements"`
+		} `json:"rows"`
+	}
+	if err := getJSON(ctx, p.httpClient, u.String(), &data); err != nil {
+		return Route{}, err
+	}
+	if data.Status != "OK" {
+		return Route{}, fmt.Errorf("api error: %s %s", data.Status, data.ErrorMessage)
+	}
+	if len(data.Rows) == 0 || len(data.Rows[0].Elements) == 0 {
+		return Route{}, fmt.Errorf("no route found")
+	}
+	el := data.Rows[0].Elements[0]
+	if el.Status != "OK" {
+		return Route{}, fmt.Errorf("no route found: %s", el.Status)
+	}
+
+	route := Route{From: from, To: to, Duration: time.Duration(el.Duration.Value) * time.Second, DistanceMeters: el.Distance.Value}
+	if len(data.Origins) > 0 && data.Origins[0] != "" {
+		route.From = data.Origins[0]
+	}
+	if len(data.Destinations) > 0 && data.Destinations[0] != "" {
+		route.To = data.Destinations[0]
+	}
+	return route, nil
+}
+
+// OSRMRouteProvider estimates travel times with an OSRM server, the public demo one
+// unless OSRM_BASE_URL is set. OSRM has no public transport and routes coordinates, so
+// place names are geocoded first, taking their best match.
+type OSRMRouteProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	geocoder   geo.Geocoder
+}
+
+func NewOSRMRouteProvider() *OSRMRouteProvider {
+	baseURL := strings.TrimRight(os.Getenv("OSRM_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = "https://router.project-osrm.org"
+	}
+	return &OSRMRouteProvider{
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: vcr.Transport},
+		baseURL:    baseURL,
+		geocoder:   places,
+	}
+}
+
+func (p *OSRMRouteProvider) Source() string {
+	return "OSRM"
+}
+
+var osrmProfiles = map[TravelMode]string{
+	TravelModeDrive: "driving",
+	TravelModeWalk:  "foot",
+}
+
+func (p *OSRMRouteProvider) Route(ctx context.Context, from, to string, mode TravelMode) (Route, error) {
+	profile, ok := osrmProfiles[mode]
+	if !ok {
+		return Route{}, fmt.Errorf("%s travel times are not available", mode)
+	}
+
+	route := Route{From: from, To: to}
+	var coords []string
+	for _, place := range []*string{&route.From, &route.To} {
+		matches, err := p.geocoder.Search(ctx, *place)
+		if err != nil {
+			return Route{}, fmt.Errorf("locate %q: %w", *place, err)
+		}
+		if len(matches) == 0 {
+			return Route{}, fmt.Errorf("locate %q: place not found", *place)
+		}
+		best := matches[0]
+		if _, _, ok := geo.ParseCoordinates(*place); !ok {
+			*place = best.Canonical()
+		}
+		// OSRM takes longitude first
+		coords = append(coords, strconv.FormatFloat(best.Lon, 'f', 6, 64)+","+strconv.FormatFloat(best.Lat, 'f', 6, 64))
+	}
+
+	var data struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Routes  []struct {
+			Duration float64 `json:"duration"`
+			Distance float64 `json:"distance"`
+		} `json:"routes"`
+	}
+	u := fmt.Sprintf("%s/route/v1/%s/%s?overview=false", p.baseURL, profile, strings.Join(coords, ";"))
+	if err := getJSON(ctx, p.httpClient, u, &data); err != nil {
+		return Route{}, err
+	}
+	if data.Code != "Ok" || len(data.Routes) == 0 {
+		return Route{}, fmt.Errorf("no route found: %s %s", data.Code, data.Message)
+	}
+
+	route.Duration = time.Duration(data.Routes[0].Duration) * time.Second
+	route.DistanceMeters = int(data.Routes[0].Distance)
+	return route, nil
+}
+
+// getJSON fetches u and decodes its JSON body into v.
+func getJSON(ctx context.Context, httpClient *http.Client, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("api error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGoogleRouteProvider_Route(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("origins") != "Lisbon Airport" || q.Get("destinations") != "Alfama, Lisbon" || q.Get("mode") != "transit" || q.Get("key") != "key" {
+			t.Errorf("query = %v", q)
+		}
+		_, _ = fmt.Fprint(w, `{"status": "OK", "origin_addresses": ["Humberto Delgado Airport, Lisbon"], "destination_addresses": ["Alfama, Lisbon, Portugal"],
+			"rows": [{"elements": [{"status": "OK", "duration": {"value": 1980}, "distance": {"value": 9300}}]}]}`)
+	}))
+	defer srv.Close()
+
+	p := NewGoogleRouteProvider("key")
+	p.baseURL = srv.URL
+
+	route, err := p.Route(context.Background(), "Lisbon Airport", "Alfama, Lisbon", TravelModeTransit)
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	want := Route{From: "Humberto Delgado Airport, Lisbon", To: "Alfama, Lisbon, Portugal", Duration: 33 * time.Minute, DistanceMeters: 9300}
+	if route != want {
+		t.Errorf("Route() = %+v, want %+v", route, want)
+	}
+}
+
+func TestOSRMRouteProvider_Route(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := "/route/v1/driving/-9.134200,38.774200;-9.130000,38.711000"; r.URL.Path != want {
+			t.Errorf("path = %q, want %q", r.URL.Path, want)
+		}
+		_, _ = fmt.Fprint(w, `{"code": "Ok", "routes": [{"duration": 1260.4, "distance": 8120.7}]}`)
+	}))
+	defer srv.Close()
+
+	p := NewOSRMRouteProvider()
+	p.baseURL = srv.URL
+	p.geocoder = stubGeocoder{"Alfama": {{Name: "Alfama", Country: "Portugal", Lat: 38.711, Lon: -9.13}}}
+
+	route, err := p.Route(context.Background(), "38.7742,-9.1342", "Alfama", TravelModeDrive)
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	want := Route{From: "38.7742,-9.1342", To: "Alfama, Portugal", Duration: 1260 * time.Second, DistanceMeters: 8120}
+	if route != want {
+		t.Errorf("Route() = %+v, want %+v", route, want)
+	}
+
+	if _, err := p.Route(context.Background(), "Alfama", "Alfama", TravelModeTransit); err == nil {
+		t.Error("Route() by transit error = nil, want an error as OSRM has no public transport")
+	}
+}
@@ -0,0 +1,224 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Tool arguments are declared as Go structs. Each field is a parameter named after its
+// json tag, described by the description tag and constrained by the jsonschema tag:
+//
+//	type args struct {
+//		Location string `json:"location" description:"City or region" jsonschema:"required"`
+//		Days     int    `json:"days" jsonschema:"minimum=1,maximum=7"`
+//		Units    string `json:"units" jsonschema:"enum=metric|imperial"`
+//	}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema returns the OpenAI parameter schema of the arguments struct T.
+func Schema[T any]() openai.FunctionParameters {
+	return openai.FunctionParameters(objectSchema(reflect.TypeFor[T]()))
+}
+
+// DecodeArgs parses tool call arguments into T, rejecting values outside the bounds
+// and enums of its jsonschema tags. Required fields are left to the tool, which may
+// resolve missing ones from the conversation.
+func DecodeArgs[T any](args json.RawMessage) (T, error) {
+	var v T
+	if len(args) == 0 {
+		return v, nil
+	}
+	if err := json.Unmarshal(args, &v); err != nil {
+		return v, fmt.Errorf("failed to parse tool call arguments: %w", err)
+	}
+	if err := validateStruct(reflect.ValueOf(v)); err != nil {
+		return v, fmt.Errorf("invalid tool call arguments: %w", err)
+	}
+	return v, nil
+}
+
+// field is a struct field exposed as a tool parameter.
+type field struct {
+	name        string
+	index       int
+	description string
+	required    bool
+	minimum     *float64
+	maximum     *float64
+	enum        []string
+}
+
+// fields lists the parameters of struct type t, skipping unexported and json:"-" fields.
+func fields(t reflect.Type) []field {
+	var out []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		f := field{name: name, index: i, description: sf.Tag.Get("description")}
+		for _, opt := range strings.Split(sf.Tag.Get("jsonschema"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch key {
+			case "required":
+				f.required = true
+			case "minimum":
+				f.minimum = parseBound(t, sf, value)
+			case "maximum":
+				f.maximum = parseBound(t, sf, value)
+			case "enum":
+				f.enum = strings.Split(value, "|")
+			}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// parseBound parses a minimum or maximum option. Tags are fixed at compile time, so
+// an invalid one is a programming error.
+func parseBound(t reflect.Type, sf reflect.StructField, value string) *float64 {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(fmt.Sprintf("tools: invalid jsonschema bound %q on %s.%s", value, t.Name(), sf.Name))
+	}
+	return &n
+}
+
+func objectSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	properties := map[string]any{}
+	required := []string{}
+	for _, f := range fields(t) {
+		prop := typeSchema(t.Field(f.index).Type)
+		if f.description != "" {
+			prop["description"] = f.description
+		}
+		if f.minimum != nil {
+			prop["minimum"] = *f.minimum
+		}
+		if f.maximum != nil {
+			prop["maximum"] = *f.maximum
+		}
+		if len(f.enum) > 0 {
+			prop["enum"] = f.enum
+		}
+		properties[f.name] = prop
+
+		if f.required {
+			required = append(required, f.name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return objectSchema(t)
+	default:
+		panic(fmt.Sprintf("tools: unsupported argument type %s", t))
+	}
+}
+
+// validateStruct checks the bounds and enums of the fields of v. Zero values are
+// treated as omitted and not checked.
+func validateStruct(v reflect.Value) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || v.Type() == timeType {
+		return nil
+	}
+
+	for _, f := range fields(v.Type()) {
+		fv := v.Field(f.index)
+		if fv.IsZero() {
+			continue
+		}
+
+		switch fv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if err := checkBounds(f, float64(fv.Int())); err != nil {
+				return err
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if err := checkBounds(f, float64(fv.Uint())); err != nil {
+				return err
+			}
+		case reflect.Float32, reflect.Float64:
+			if err := checkBounds(f, fv.Float()); err != nil {
+				return err
+			}
+		case reflect.String:
+			if len(f.enum) > 0 && !slices.Contains(f.enum, fv.String()) {
+				return fmt.Errorf("%s must be one of %s", f.name, strings.Join(f.enum, ", "))
+			}
+		case reflect.Struct, reflect.Pointer:
+			if err := validateStruct(fv); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func checkBounds(f field, n float64) error {
+	if f.minimum != nil && n < *f.minimum {
+		return fmt.Errorf("%s must be at least %v", f.name, *f.minimum)
+	}
+	if f.maximum != nil && n > *f.maximum {
+		return fmt.Errorf("%s must be at most %v", f.name, *f.maximum)
+	}
+	return nil
+}
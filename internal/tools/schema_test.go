@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testArgs struct {
+	Location string    `json:"location" description:"City name" jsonschema:"required"`
+	Days     int       `json:"days,omitempty" jsonschema:"minimum=1,maximum=7"`
+	Units    string    `json:"units" jsonschema:"enum=metric|imperial"`
+	Stops    []string  `json:"stops"`
+	After    time.Time `json:"after"`
+	internal string
+}
+
+func TestSchema(t *testing.T) {
+	got := Schema[testArgs]()
+
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"location": map[string]any{"type": "string", "description": "City name"},
+			"days":     map[string]any{"type": "integer", "minimum": 1.0, "maximum": 7.0},
+			"units":    map[string]any{"type": "string", "enum": []string{"metric", "imperial"}},
+			"stops":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"after":    map[string]any{"type": "string", "format": "date-time"},
+		},
+		"required": []string{"location"},
+	}
+
+	if diff := cmp.Diff(want, map[string]any(got)); diff != "" {
+		t.Errorf("Schema() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		want    testArgs
+		wantErr bool
+	}{
+		{name: "valid", args: `{"location":"Lisbon","days":3,"units":"metric"}`, want: testArgs{Location: "Lisbon", Days: 3, Units: "metric"}},
+		{name: "empty arguments", args: ``, want: testArgs{}},
+		{name: "omitted optional fields are not validated", args: `{"location":"Lisbon"}`, want: testArgs{Location: "Lisbon"}},
+		{name: "above maximum", args: `{"location":"Lisbon","days":10}`, wantErr: true},
+		{name: "unknown enum value", args: `{"units":"kelvin"}`, wantErr: true},
+		{name: "wrong type", args: `{"days":"three"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeArgs[testArgs](json.RawMessage(tt.args))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("DecodeArgs() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeArgs() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(testArgs{})); diff != "" {
+				t.Errorf("DecodeArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -28,15 +29,38 @@ type Tool interface {
 	Execute(ctx context.Context, args json.RawMessage) (string, error)
 }
 
+// Sourced is implemented by tools whose results come from an external data source,
+// which replies cite next to the facts they take from it.
+type Sourced interface {
+	// Source names the data source, e.g. "WeatherAPI".
+	Source() string
+}
+
+// Perishable is implemented by tools whose results go stale, like the current weather.
+// Replies built on them can be refreshed once their TTL has passed.
+type Perishable interface {
+	// TTL is how long a result stays fresh.
+	TTL() time.Duration
+}
+
 const (
 	meterName  = "github.com/acai-travel/tech-challenge/internal/tools"
 	tracerName = "github.com/acai-travel/tech-challenge/internal/tools"
 )
 
+// durationBuckets are the tool latency histogram boundaries in milliseconds, spread
+// over the range of external API calls: from cached lookups to slow upstreams
+// hitting their timeout.
+var durationBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+
 var (
 	executionCounter  metric.Int64Counter
 	durationHistogram metric.Float64Histogram
 	errorCounter      metric.Int64Counter
+	deniedCounter     metric.Int64Counter
+	cacheLookups      metric.Int64Counter
+
+	toolErrorRates = newErrorRates()
 )
 
 func init() {
@@ -57,6 +81,7 @@ func init() {
 		"tool.execution.duration",
 		metric.WithDescription("Duration of tool executions in milliseconds"),
 		metric.WithUnit("ms"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
 	)
 	if err != nil {
 		// If metric creation fails, the histogram will be nil and won't record anything
@@ -70,13 +95,46 @@ func init() {
 	if err != nil {
 		// If metric creation fails, the counter will be nil and won't record anything
 	}
+
+	deniedCounter, err = meter.Int64Counter(
+		"tool.execution.denied",
+		metric.WithDescription("Total number of tool calls refused by the tool policy"),
+		metric.WithUnit("{execution}"),
+	)
+	if err != nil {
+		// If metric creation fails, the counter will be nil and won't record anything
+	}
+
+	cacheLookups, err = meter.Int64Counter(
+		"cache.lookups",
+		metric.WithDescription("Number of lookups in the caches of the tools, by cache.name and cache.hit"),
+		metric.WithUnit("{lookup}"),
+	)
+	if err != nil {
+		// If metric creation fails, the counter will be nil and won't record anything
+	}
+
+	// The error ratio is derived from the recent outcomes of each tool, so alerts
+	// don't need to compute it from the counters
+	_, _ = meter.Float64ObservableGauge(
+		"tool.execution.error_ratio",
+		metric.WithDescription("Ratio of failed tool executions over the last 5 minutes"),
+		metric.WithUnit("1"),
+		metric.WithFloat64Callback(func(_ context.Context, o metric.Float64Observer) error {
+			for name, ratio := range toolErrorRates.ratios(time.Now()) {
+				o.Observe(ratio, metric.WithAttributes(attribute.String("tool.name", name)))
+			}
+			return nil
+		}),
+	)
 }
 
 // Registry manages a collection of tools and provides methods to register,
 // retrieve, and list them. It serves as the central hub for all available tools.
 type Registry struct {
-	mu    sync.RWMutex
-	tools map[string]Tool
+	mu         sync.RWMutex
+	tools      map[string]Tool
+	middleware []Middleware
 }
 
 func NewRegistry() *Registry {
@@ -93,6 +151,25 @@ func (r *Registry) Register(t Tool) {
 	r.tools[t.Name()] = t
 }
 
+// Retain removes the tools not named, keeping only a subset of the registered tools.
+func (r *Registry) Retain(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.tools {
+		if !slices.Contains(names, name) {
+			delete(r.tools, name)
+		}
+	}
+}
+
+// Use appends middleware wrapping the execution of every tool in the registry. The
+// first middleware added is the outermost one.
+func (r *Registry) Use(mw ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, mw...)
+}
+
 // Get retrieves a tool by name. Returns the tool and true if found, nil and false otherwise.
 func (r *Registry) Get(name string) (Tool, bool) {
 	r.mu.RLock()
@@ -101,6 +178,31 @@ func (r *Registry) Get(name string) (Tool, bool) {
 	return t, ok
 }
 
+// Source returns the data source of a tool, or "" when it has none or is not found.
+func (r *Registry) Source(name string) string {
+	t, ok := r.Get(name)
+	if !ok {
+		return ""
+	}
+	if s, ok := t.(Sourced); ok {
+		return s.Source()
+	}
+	return ""
+}
+
+// TTL returns how long the results of a tool stay fresh, or 0 when they don't go stale
+// or the tool is not found.
+func (r *Registry) TTL(name string) time.Duration {
+	t, ok := r.Get(name)
+	if !ok {
+		return 0
+	}
+	if p, ok := t.(Perishable); ok {
+		return p.TTL()
+	}
+	return 0
+}
+
 // Definitions returns all tool definitions in a format suitable for OpenAI API calls
 func (r *Registry) Definitions() []openai.ChatCompletionToolUnionParam {
 	r.mu.RLock()
@@ -131,6 +233,7 @@ func (r *Registry) Execute(ctx context.Context, name string, args json.RawMessag
 	// Get tool
 	r.mu.RLock()
 	tool, ok := r.tools[name]
+	middleware := r.middleware
 	r.mu.RUnlock()
 
 	if !ok {
@@ -149,16 +252,21 @@ func (r *Registry) Execute(ctx context.Context, name string, args json.RawMessag
 		return "", err
 	}
 
-	// Execute tool
-	result, err := tool.Execute(ctx, args)
+	// Execute tool through the middleware chain
+	result, err := chain(tool, middleware)(ctx, name, args)
 
-	// Calculate duration
-	duration := float64(time.Since(startTime).Milliseconds())
+	// Calculate duration, keeping sub-millisecond precision for the low buckets
+	duration := float64(time.Since(startTime)) / float64(time.Millisecond)
 
-	// Prepare common attributes
+	// Prepare common attributes. Failed executions carry error.type, so the error
+	// rate of each tool can also be derived from the count and duration metrics.
 	attrs := []attribute.KeyValue{
 		attribute.String("tool.name", name),
 	}
+	if err != nil {
+		attrs = append(attrs, attribute.String("error.type", "execution_failed"))
+	}
+	toolErrorRates.record(name, err != nil, time.Now())
 
 	// Record metrics and span status
 	if err != nil {
@@ -182,7 +290,8 @@ func (r *Registry) Execute(ctx context.Context, name string, args json.RawMessag
 		executionCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
 	}
 
-	// Record execution duration
+	// Record execution duration. The context carries the tool span, so sampled
+	// traces are attached to the histogram as exemplars.
 	if durationHistogram != nil {
 		durationHistogram.Record(ctx, duration, metric.WithAttributes(attrs...))
 	}
@@ -0,0 +1,305 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pricing"
+	"github.com/acai-travel/tech-challenge/internal/vcr"
+	"github.com/openai/openai-go/v2"
+)
+
+// Transfer types of the Amadeus Transfer Search API, as offered to the model
+var transferTypes = map[string]string{
+	"private":         "PRIVATE",
+	"shared":          "SHARED",
+	"taxi":            "TAXI",
+	"airport_express": "AIRPORT_EXPRESS",
+	"airport_bus":     "AIRPORT_BUS",
+}
+
+// TransferQuery are the criteria of a transfer offers search. Pickup and Dropoff are
+// IATA airport codes or street addresses in City and CountryCode.
+type TransferQuery struct {
+	Pickup      string
+	Dropoff     string
+	City        string
+	CountryCode string
+	// DateTime is the local pickup time, YYYY-MM-DDTHH:MM:SS.
+	DateTime   string
+	Passengers int
+	// Type is an Amadeus transfer type, e.g. PRIVATE. Empty searches all types.
+	Type string
+}
+
+// TransferOffer represents a single transfer offer with price
+type TransferOffer struct {
+	Type     string
+	Vehicle  string
+	Seats    int
+	Provider string
+	Price    pricing.Price
+}
+
+// airportCodePattern matches IATA airport codes, telling them apart from addresses.
+var airportCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// transferOffersResponse is the part of the Amadeus transfer-offers response read.
+type transferOffersResponse struct {
+	Data []struct {
+		TransferType string `json:"transferType"`
+		Vehicle      struct {
+			Description string `json:"description"`
+			Seats       []struct {
+				Count int `json:"count"`
+			} `json:"seats"`
+		} `json:"vehicle"`
+		ServiceProvider struct {
+			Name string `json:"name"`
+		} `json:"serviceProvider"`
+		Quotation struct {
+			MonetaryAmount string `json:"monetaryAmount"`
+			CurrencyCode   string `json:"currencyCode"`
+			Base           struct {
+				MonetaryAmount string `json:"monetaryAmount"`
+			} `json:"base"`
+		} `json:"quotation"`
+	} `json:"data"`
+}
+
+// FetchTransferOffers calls the transfer-offers endpoint of the Amadeus API at baseURL
+func FetchTransferOffers(ctx context.Context, httpClient *http.Client, baseURL, token string, query TransferQuery) ([]TransferOffer, error) {
+	if token == "" {
+		return nil, fmt.Errorf("missing access token")
+	}
+	if query.Pickup == "" {
+		return nil, fmt.Errorf("missing pickup")
+	}
+	if query.Dropoff == "" {
+		return nil, fmt.Errorf("missing dropoff")
+	}
+	if query.DateTime == "" {
+		return nil, fmt.Errorf("missing pickup time")
+	}
+
+	req := map[string]any{
+		"startDateTime": query.DateTime,
+		"passengers":    max(query.Passengers, 1),
+	}
+	if query.Type != "" {
+		req["transferType"] = query.Type
+	}
+	for prefix, place := range map[string]string{"start": query.Pickup, "end": query.Dropoff} {
+		if airportCodePattern.MatchString(place) {
+			req[prefix+"LocationCode"] = place
+			continue
+		}
+		if query.City == "" || query.CountryCode == "" {
+			return nil, fmt.Errorf("missing city or country code of the address %q", place)
+		}
+		req[prefix+"AddressLine"] = place
+		req[prefix+"CityName"] = query.City
+		req[prefix+"CountryCode"] = query.CountryCode
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	resp, err := doAmadeus(ctx, httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v1/shopping/transfer-offers", strings.NewReader(string(payload)))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, amadeusError(resp.StatusCode, body)
+	}
+
+	var data transferOffersResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	out := make([]TransferOffer, 0, len(data.Data))
+	for _, offer := range data.Data {
+		price, err := pricing.ParseOffer(offer.Quotation.MonetaryAmount, offer.Quotation.Base.MonetaryAmount, offer.Quotation.CurrencyCode)
+		if err != nil {
+			slog.WarnContext(ctx, "Skipping transfer offer with invalid price", "provider", offer.ServiceProvider.Name, "error", err)
+			continue
+		}
+
+		seats := 0
+		for _, s := range offer.Vehicle.Seats {
+			seats += s.Count
+		}
+		out = append(out, TransferOffer{
+			Type:     strings.ToLower(offer.TransferType),
+			Vehicle:  offer.Vehicle.Description,
+			Seats:    seats,
+			Provider: offer.ServiceProvider.Name,
+			Price:    price,
+		})
+	}
+	return out, nil
+}
+
+// transfersArgs are the arguments of search_transfers.
+type transfersArgs struct {
+	Pickup      string `json:"pickup" description:"IATA code of the pickup airport (e.g., 'CDG') or the street address to pick up at. Leave empty for the destination airport of the previous flight search."`
+	Dropoff     string `json:"dropoff" description:"Street address (e.g., '10 Rue de Rivoli') or IATA code of the airport to drop off at"`
+	City        string `json:"city" description:"City of the street address (e.g., 'Paris'), required when pickup or dropoff is an address"`
+	CountryCode string `json:"countryCode" description:"ISO 3166 country code of the street address (e.g., 'FR'), required when pickup or dropoff is an address"`
+	DateTime    string `json:"dateTime" description:"Local pickup date and time in YYYY-MM-DDTHH:MM format (e.g., '2025-10-18T14:30')"`
+	Passengers  int    `json:"passengers" description:"Number of passengers (optional, 1 by default)" jsonschema:"minimum=1"`
+	Type        string `json:"type" description:"Kind of transfer (optional, all kinds by default)" jsonschema:"enum=private|shared|taxi|airport_express|airport_bus"`
+}
+
+// SearchTransfersTool retrieves airport transfer offers and prices
+type SearchTransfersTool struct {
+	httpClient *http.Client
+	conv       *model.Conversation
+}
+
+func NewSearchTransfersTool(conv *model.Conversation) *SearchTransfersTool {
+	return &SearchTransfersTool{
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: vcr.Transport},
+		conv:       conv,
+	}
+}
+
+func (t *SearchTransfersTool) Name() string {
+	return "search_transfers"
+}
+
+func (t *SearchTransfersTool) Source() string {
+	return "Amadeus"
+}
+
+func (t *SearchTransfersTool) TTL() time.Duration {
+	return 30 * time.Minute
+}
+
+func (t *SearchTransfersTool) Description() string {
+	return "Search for transfer offers (private cars, shared shuttles, taxis, airport trains and buses) between an airport and an address at a given time. Returns available transfers with vehicles and prices."
+}
+
+func (t *SearchTransfersTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[transfersArgs](),
+	})
+}
+
+func (t *SearchTransfersTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[transfersArgs](args)
+	if err != nil {
+		return "", err
+	}
+
+	// Transfers usually start at the airport the traveler flies to
+	pickup := strings.TrimSpace(payload.Pickup)
+	if pickup == "" {
+		pickup = t.conv.Entities.Destination
+	}
+	if pickup == "" {
+		return NeedsClarification("pickup", "Where should the transfer pick you up: which airport or address?"), nil
+	}
+	dropoff := strings.TrimSpace(payload.Dropoff)
+	if dropoff == "" {
+		return NeedsClarification("dropoff", "Where should the transfer take you?"), nil
+	}
+	if airportCodePattern.MatchString(strings.ToUpper(pickup)) {
+		pickup = strings.ToUpper(pickup)
+	}
+	if airportCodePattern.MatchString(strings.ToUpper(dropoff)) {
+		dropoff = strings.ToUpper(dropoff)
+	}
+
+	dateTime := strings.TrimSpace(payload.DateTime)
+	if dateTime == "" {
+		return NeedsClarification("dateTime", "What day and time do you need the transfer?"), nil
+	}
+	at, err := time.Parse("2006-01-02T15:04", dateTime)
+	if err != nil {
+		return "", fmt.Errorf("invalid dateTime %q, expected YYYY-MM-DDTHH:MM", dateTime)
+	}
+
+	baseURL, token, err := amadeusToken(ctx, t.httpClient)
+	if err != nil {
+		return "", fmt.Errorf("transfer search failed: %w", err)
+	}
+
+	offers, err := FetchTransferOffers(ctx, t.httpClient, baseURL, token, TransferQuery{
+		Pickup:      pickup,
+		Dropoff:     dropoff,
+		City:        strings.TrimSpace(payload.City),
+		CountryCode: strings.ToUpper(strings.TrimSpace(payload.CountryCode)),
+		DateTime:    at.Format("2006-01-02T15:04:05"),
+		Passengers:  payload.Passengers,
+		Type:        transferTypes[payload.Type],
+	})
+	if err != nil {
+		return "", fmt.Errorf("transfer search failed: %w", err)
+	}
+
+	if len(offers) == 0 {
+		return "No transfers found matching your criteria.", nil
+	}
+
+	// Limit to the first 5 offers
+	if len(offers) > 5 {
+		offers = offers[:5]
+	}
+
+	// Prices in other currencies are shown in the default currency too
+	currency := strings.ToUpper(strings.TrimSpace(os.Getenv("AMADEUS_CURRENCY")))
+
+	// Format response
+	lines := make([]string, 0, len(offers)+1)
+	lines = append(lines, fmt.Sprintf("Found %d transfer option%s from %s to %s at %s:",
+		len(offers),
+		map[bool]string{true: "s", false: ""}[len(offers) != 1],
+		pickup,
+		dropoff,
+		at.Format("2006-01-02 15:04")))
+
+	for i, o := range offers {
+		info := fmt.Sprintf("%d. %s", i+1, o.Type)
+		if o.Vehicle != "" {
+			info += ", " + o.Vehicle
+		}
+		if o.Seats > 0 {
+			info += fmt.Sprintf(" (%d seats)", o.Seats)
+		}
+		if o.Provider != "" {
+			info += " by " + o.Provider
+		}
+		lines = append(lines, info+": "+withConverted(ctx, o.Price, currency))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+func TestSearchTransfersTool_Execute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			_, _ = fmt.Fprint(w, `{"access_token": "token"}`)
+		case "/v1/shopping/transfer-offers":
+			var req map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			want := map[string]any{
+				"startLocationCode": "CDG",
+				"endAddressLine":    "10 Rue de Rivoli",
+				"endCityName":       "Paris",
+				"endCountryCode":    "FR",
+				"startDateTime":     "2025-11-14T10:30:00",
+				"passengers":        float64(2),
+				"transferType":      "PRIVATE",
+			}
+			for k, v := range want {
+				if req[k] != v {
+					t.Errorf("request %s = %v, want %v", k, req[k], v)
+				}
+			}
+			_, _ = fmt.Fprint(w, `{"data": [{"transferType": "PRIVATE", "vehicle": {"description": "Mercedes-Benz E-Class", "seats": [{"count": 3}]}, "serviceProvider": {"name": "Paris Cars"}, "quotation": {"monetaryAmount": "65.00", "currencyCode": "EUR"}}]}`)
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("AMADEUS_BASE_URL", srv.URL)
+	t.Setenv("AMADEUS_API_KEY", "key")
+	t.Setenv("AMADEUS_API_SECRET", "secret")
+
+	// The pickup defaults to the destination airport of the conversation
+	conv := &model.Conversation{Entities: model.Entities{Destination: "CDG"}}
+	tool := NewSearchTransfersTool(conv)
+	tool.httpClient = srv.Client()
+
+	got, err := tool.Execute(context.Background(), json.RawMessage(`{"dropoff": "10 Rue de Rivoli", "city": "Paris", "countryCode": "fr", "dateTime": "2025-11-14T10:30", "passengers": 2, "type": "private"}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := "Found 1 transfer option from CDG to 10 Rue de Rivoli at 2025-11-14 10:30:\n1. private, Mercedes-Benz E-Class (3 seats) by Paris Cars: 65.00 EUR"
+	if got != want {
+		t.Errorf("Execute() = %q, want %q", got, want)
+	}
+}
+
+func TestSearchTransfersTool_Execute_Clarification(t *testing.T) {
+	tool := NewSearchTransfersTool(&model.Conversation{})
+
+	tests := []struct {
+		args, field string
+	}{
+		{args: `{"dropoff": "10 Rue de Rivoli", "dateTime": "2025-11-14T10:30"}`, field: "pickup"},
+		{args: `{"pickup": "CDG", "dateTime": "2025-11-14T10:30"}`, field: "dropoff"},
+		{args: `{"pickup": "CDG", "dropoff": "ORY"}`, field: "dateTime"},
+	}
+	for _, tt := range tests {
+		got, err := tool.Execute(context.Background(), json.RawMessage(tt.args))
+		if err != nil {
+			t.Fatalf("Execute(%s) error = %v", tt.args, err)
+		}
+		c, ok := ParseClarification(got)
+		if !ok || c.Missing != tt.field {
+			t.Errorf("Execute(%s) = %q, want a clarification of %s", tt.args, got, tt.field)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// travelTimeTTL is how long travel time estimates are reused. Routes barely change, but
+// estimates of some providers account for the traffic of the hour.
+const travelTimeTTL = time.Hour
+
+// routeKey identifies a route estimate of a provider.
+type routeKey struct {
+	source string
+	from   string
+	to     string
+	mode   TravelMode
+}
+
+type cachedRoute struct {
+	route     Route
+	fetchedAt time.Time
+}
+
+// routeCache keeps recent route estimates, shared by all conversations as they don't
+// depend on who asks.
+type routeCache struct {
+	mu      sync.Mutex
+	entries map[routeKey]cachedRoute
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// travelTimes is shared by the travel time tools of all conversations.
+var travelTimes = newRouteCache(travelTimeTTL)
+
+func newRouteCache(ttl time.Duration) *routeCache {
+	return &routeCache{
+		entries: make(map[routeKey]cachedRoute),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// route returns the cached estimate of the route, or asks provider and caches its
+// estimate. Places differing only in case share estimates.
+func (c *routeCache) route(ctx context.Context, provider RouteProvider, from, to string, mode TravelMode) (Route, error) {
+	k := routeKey{source: provider.Source(), from: strings.ToLower(from), to: strings.ToLower(to), mode: mode}
+	c.mu.Lock()
+	e, ok := c.entries[k]
+	c.mu.Unlock()
+	if ok && c.now().Sub(e.fetchedAt) < c.ttl {
+		recordCacheLookup(ctx, "travel_times", true)
+		return e.route, nil
+	}
+	recordCacheLookup(ctx, "travel_times", false)
+
+	route, err := provider.Route(ctx, from, to, mode)
+	if err != nil {
+		return Route{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, e := range c.entries {
+		if now.Sub(e.fetchedAt) >= c.ttl {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[k] = cachedRoute{route: route, fetchedAt: now}
+	return route, nil
+}
+
+// travelTimeArgs are the arguments of get_travel_time.
+type travelTimeArgs struct {
+	From string `json:"from" description:"Place to start from, as specific as possible (e.g., 'Lisbon Airport', 'Alfama, Lisbon')" jsonschema:"required"`
+	To   string `json:"to" description:"Place to go to, as specific as possible (e.g., 'Praça do Comércio, Lisbon')" jsonschema:"required"`
+	Mode string `json:"mode" description:"How the traveler gets there (optional, drive by default)" jsonschema:"enum=drive|transit|walk"`
+}
+
+// GetTravelTimeTool estimates the travel time and distance between two places
+type GetTravelTimeTool struct {
+	provider RouteProvider
+	cache    *routeCache
+}
+
+func NewGetTravelTimeTool(provider RouteProvider) *GetTravelTimeTool {
+	return &GetTravelTimeTool{provider: provider, cache: travelTimes}
+}
+
+func (t *GetTravelTimeTool) Name() string {
+	return "get_travel_time"
+}
+
+func (t *GetTravelTimeTool) Source() string {
+	return t.provider.Source()
+}
+
+func (t *GetTravelTimeTool) TTL() time.Duration {
+	return travelTimeTTL
+}
+
+func (t *GetTravelTimeTool) Description() string {
+	return "Estimate how long it takes to get from one place to another by car, public transport or on foot, and how far it is. Use it for questions like 'how long from the airport to the old town?'."
+}
+
+func (t *GetTravelTimeTool) Definition() openai.ChatCompletionToolUnionParam {
+	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        t.Name(),
+		Description: openai.String(t.Description()),
+		Parameters:  Schema[travelTimeArgs](),
+	})
+}
+
+var travelModeVerbs = map[TravelMode]string{
+	TravelModeDrive:   "Driving",
+	TravelModeTransit: "By public transport",
+	TravelModeWalk:    "Walking",
+}
+
+func (t *GetTravelTimeTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	payload, err := DecodeArgs[travelTimeArgs](args)
+	if err != nil {
+		return "", err
+	}
+
+	from := strings.TrimSpace(payload.From)
+	if from == "" {
+		return NeedsClarification("from", "Where would you be starting from?"), nil
+	}
+	to := strings.TrimSpace(payload.To)
+	if to == "" {
+		return NeedsClarification("to", "Where do you want to go?"), nil
+	}
+	mode := TravelMode(payload.Mode)
+	if mode == "" {
+		mode = TravelModeDrive
+	}
+
+	route, err := t.cache.route(ctx, t.provider, from, to, mode)
+	if err != nil {
+		return "", fmt.Errorf("travel time lookup failed: %w", err)
+	}
+
+	result := fmt.Sprintf("%s from %s to %s: about %s", travelModeVerbs[mode], route.From, route.To, formatTravelTime(route.Duration))
+	if route.DistanceMeters > 0 {
+		result += fmt.Sprintf(" (%.1f km)", float64(route.DistanceMeters)/1000)
+	}
+	return result + ".", nil
+}
+
+// formatTravelTime rounds d to minutes, like "1 h 5 min" or "25 min".
+func formatTravelTime(d time.Duration) string {
+	minutes := max(int(d.Round(time.Minute).Minutes()), 1)
+	if minutes < 60 {
+		return fmt.Sprintf("%d min", minutes)
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%d h", minutes/60)
+	}
+	return fmt.Sprintf("%d h %d min", minutes/60, minutes%60)
+}
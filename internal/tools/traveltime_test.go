@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// countingRouteProvider answers every route with 25 minutes, counting the calls.
+type countingRouteProvider struct {
+	calls int
+}
+
+func (p *countingRouteProvider) Source() string {
+	return "Test routes"
+}
+
+func (p *countingRouteProvider) Route(_ context.Context, from, to string, mode TravelMode) (Route, error) {
+	p.calls++
+	return Route{From: from, To: to, Duration: 25 * time.Minute, DistanceMeters: 12400}, nil
+}
+
+func TestGetTravelTimeTool_Execute(t *testing.T) {
+	provider := &countingRouteProvider{}
+	tool := NewGetTravelTimeTool(provider)
+	tool.cache = newRouteCache(time.Hour)
+
+	got, err := tool.Execute(context.Background(), json.RawMessage(`{"from": "Lisbon Airport", "to": "Alfama"}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := "Driving from Lisbon Airport to Alfama: about 25 min (12.4 km)."; got != want {
+		t.Errorf("Execute() = %q, want %q", got, want)
+	}
+
+	// The same route is answered from the cache, another mode is not
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"from": "lisbon airport", "to": "alfama", "mode": "drive"}`)); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"from": "Lisbon Airport", "to": "Alfama", "mode": "walk"}`)); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if provider.calls != 2 {
+		t.Errorf("provider called %d times, want 2", provider.calls)
+	}
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"from": "Lisbon Airport", "to": "Alfama", "mode": "fly"}`)); err == nil {
+		t.Error("Execute() with an unknown mode error = nil, want an error")
+	}
+}
+
+func TestFormatTravelTime(t *testing.T) {
+	tests := map[time.Duration]string{
+		20 * time.Second:                "1 min",
+		25*time.Minute + 40*time.Second: "26 min",
+		time.Hour:                       "1 h",
+		time.Hour + 5*time.Minute:       "1 h 5 min",
+	}
+	for d, want := range tests {
+		if got := formatTravelTime(d); got != want {
+			t.Errorf("formatTravelTime(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
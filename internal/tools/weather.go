@@ -8,12 +8,13 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/geo"
+	"github.com/acai-travel/tech-challenge/internal/vcr"
 	"github.com/openai/openai-go/v2"
 )
 
@@ -73,6 +74,22 @@ func (v *IntOrString) UnmarshalJSON(b []byte) error {
 	return fmt.Errorf("unexpected value for IntOrString: %s", string(b))
 }
 
+// currentWeatherResponse is the part of the WeatherAPI current.json response read.
+type currentWeatherResponse struct {
+	Location struct {
+		Name string `json:"name"`
+	} `json:"location"`
+	Current struct {
+		TempC      float64 `json:"temp_c"`
+		FeelslikeC float64 `json:"feelslike_c"`
+		WindKph    float64 `json:"wind_kph"`
+		Humidity   int     `json:"humidity"`
+		Condition  struct {
+			Text string `json:"text"`
+		} `json:"condition"`
+	} `json:"current"`
+}
+
 // FetchCurrentWeather calls WeatherAPI current weather endpoint for a location.
 func FetchCurrentWeather(ctx context.Context, httpClient *http.Client, apiKey, location string) (CurrentWeather, error) {
 	var zero CurrentWeather
@@ -119,20 +136,7 @@ func FetchCurrentWeather(ctx context.Context, httpClient *http.Client, apiKey, l
 		return zero, fmt.Errorf("api error: status %d", resp.StatusCode)
 	}
 
-	var data struct {
-		Location struct {
-			Name string `json:"name"`
-		} `json:"location"`
-		Current struct {
-			TempC      float64 `json:"temp_c"`
-			FeelslikeC float64 `json:"feelslike_c"`
-			WindKph    float64 `json:"wind_kph"`
-			Humidity   int     `json:"humidity"`
-			Condition  struct {
-				Text string `json:"text"`
-			} `json:"condition"`
-		} `json:"current"`
-	}
+	var data currentWeatherResponse
 	if err := json.Unmarshal(body, &data); err != nil {
 		return zero, fmt.Errorf("decode response: %w", err)
 	}
@@ -147,6 +151,23 @@ func FetchCurrentWeather(ctx context.Context, httpClient *http.Client, apiKey, l
 	}, nil
 }
 
+// forecastResponse is the part of the WeatherAPI forecast.json response read.
+type forecastResponse struct {
+	Forecast struct {
+		Forecastday []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MaxtempC          float64     `json:"maxtemp_c"`
+				MintempC          float64     `json:"mintemp_c"`
+				DailyChanceOfRain IntOrString `json:"daily_chance_of_rain"`
+				Condition         struct {
+					Text string `json:"text"`
+				} `json:"condition"`
+			} `json:"day"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
+}
+
 // FetchForecast calls WeatherAPI forecast endpoint for a location and number of days.
 func FetchForecast(ctx context.Context, httpClient *http.Client, apiKey, location string, days int) ([]ForecastDay, error) {
 	if apiKey == "" {
@@ -196,21 +217,7 @@ func FetchForecast(ctx context.Context, httpClient *http.Client, apiKey, locatio
 		return nil, fmt.Errorf("api error: status %d", resp.StatusCode)
 	}
 
-	var data struct {
-		Forecast struct {
-			Forecastday []struct {
-				Date string `json:"date"`
-				Day  struct {
-					MaxtempC          float64     `json:"maxtemp_c"`
-					MintempC          float64     `json:"mintemp_c"`
-					DailyChanceOfRain IntOrString `json:"daily_chance_of_rain"`
-					Condition         struct {
-						Text string `json:"text"`
-					} `json:"condition"`
-				} `json:"day"`
-			} `json:"forecastday"`
-		} `json:"forecast"`
-	}
+	var data forecastResponse
 	if err := json.Unmarshal(body, &data); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
@@ -228,16 +235,36 @@ func FetchForecast(ctx context.Context, httpClient *http.Client, apiKey, locatio
 	return out, nil
 }
 
+// weatherArgs are the arguments of get_weather.
+type weatherArgs struct {
+	Location string `json:"location" description:"City or region named by the user. Leave empty to use the location discussed earlier in the conversation."`
+}
+
+// resolveLocation returns the location to look up: the one given by the model, else the
+// location remembered from the conversation, else WEATHER_DEFAULT_LOCATION. It is empty
+// when none is known and the user must be asked.
+func resolveLocation(conv *model.Conversation, location string) string {
+	if location = strings.TrimSpace(location); location != "" {
+		return location
+	}
+	if conv.Entities.Location != "" {
+		return conv.Entities.Location
+	}
+	return strings.TrimSpace(os.Getenv("WEATHER_DEFAULT_LOCATION"))
+}
+
 // GetWeatherTool retrieves current weather for a location
 type GetWeatherTool struct {
 	httpClient *http.Client
 	conv       *model.Conversation
+	geocoder   geo.Geocoder
 }
 
 func NewGetWeatherTool(conv *model.Conversation) *GetWeatherTool {
 	return &GetWeatherTool{
-		httpClient: &http.Client{Timeout: 5 * time.Second},
+		httpClient: &http.Client{Timeout: 5 * time.Second, Transport: vcr.Transport},
 		conv:       conv,
+		geocoder:   places,
 	}
 }
 
@@ -245,6 +272,14 @@ func (t *GetWeatherTool) Name() string {
 	return "get_weather"
 }
 
+func (t *GetWeatherTool) Source() string {
+	return "WeatherAPI"
+}
+
+func (t *GetWeatherTool) TTL() time.Duration {
+	return 15 * time.Minute
+}
+
 func (t *GetWeatherTool) Description() string {
 	return "Get weather at the given location"
 }
@@ -253,70 +288,60 @@ func (t *GetWeatherTool) Definition() openai.ChatCompletionToolUnionParam {
 	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
 		Name:        t.Name(),
 		Description: openai.String(t.Description()),
-		Parameters: openai.FunctionParameters{
-			"type": "object",
-			"properties": map[string]any{
-				"location": map[string]string{
-					"type": "string",
-				},
-			},
-			"required": []string{"location"},
-		},
+		Parameters:  Schema[weatherArgs](),
 	})
 }
 
 func (t *GetWeatherTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
-	var payload struct {
-		Location string `json:"location"`
-	}
-	if err := json.Unmarshal(args, &payload); err != nil {
-		return "", fmt.Errorf("failed to parse tool call arguments: %w", err)
+	payload, err := DecodeArgs[weatherArgs](args)
+	if err != nil {
+		return "", err
 	}
 
-	// Resolve location: payload -> parse last user message -> env default
-	resolvedLocation := strings.TrimSpace(payload.Location)
+	resolvedLocation := resolveLocation(t.conv, payload.Location)
 	if resolvedLocation == "" {
-		// try to parse "weather in X" from last user message
-		for i := len(t.conv.Messages) - 1; i >= 0; i-- {
-			if t.conv.Messages[i].Role == model.RoleUser {
-				re := regexp.MustCompile(`(?i)weather\s+in\s+([^?.!]+)`) // naive extraction
-				if m := re.FindStringSubmatch(t.conv.Messages[i].Content); len(m) == 2 {
-					resolvedLocation = strings.TrimSpace(m[1])
-					break
-				}
-			}
-		}
+		return NeedsClarification("location", "Which city or region would you like the weather for?"), nil
 	}
-	if resolvedLocation == "" {
-		resolvedLocation = os.Getenv("WEATHER_DEFAULT_LOCATION")
+
+	place, clarification, err := locate(ctx, t.conv, t.geocoder, "location", resolvedLocation)
+	if err != nil {
+		return "", fmt.Errorf("weather lookup failed: %w", err)
 	}
-	if resolvedLocation == "" {
-		return "", fmt.Errorf("weather lookup failed: please provide a location (e.g., 'weather in Paris')")
+	if clarification != "" {
+		return clarification, nil
 	}
 
 	apiKey := os.Getenv("WEATHER_API_KEY")
-	cw, err := FetchCurrentWeather(ctx, t.httpClient, apiKey, resolvedLocation)
+	cw, err := FetchCurrentWeather(ctx, t.httpClient, apiKey, placeQuery(place))
 	if err != nil {
 		return "", fmt.Errorf("weather lookup failed: %w", err)
 	}
-	name := cw.Location
-	if name == "" {
-		name = resolvedLocation
+	name := place.Name
+	if !place.Located() && cw.Location != "" {
+		name = cw.Location
 	}
 	result := fmt.Sprintf("%s: %.0f°C, %s. Feels %.0f°C. Wind %.0f kph. Humidity %d%%", name, cw.TempC, cw.Condition, cw.FeelsLikeC, cw.WindKph, cw.Humidity)
 	return result, nil
 }
 
+// forecastArgs are the arguments of get_weather_forecast.
+type forecastArgs struct {
+	Location string `json:"location" description:"City or region named by the user. Leave empty to use the location discussed earlier in the conversation."`
+	Days     int    `json:"days" jsonschema:"minimum=1,maximum=7"`
+}
+
 // GetWeatherForecastTool retrieves weather forecast for a location
 type GetWeatherForecastTool struct {
 	httpClient *http.Client
 	conv       *model.Conversation
+	geocoder   geo.Geocoder
 }
 
 func NewGetWeatherForecastTool(conv *model.Conversation) *GetWeatherForecastTool {
 	return &GetWeatherForecastTool{
-		httpClient: &http.Client{Timeout: 5 * time.Second},
+		httpClient: &http.Client{Timeout: 5 * time.Second, Transport: vcr.Transport},
 		conv:       conv,
+		geocoder:   places,
 	}
 }
 
@@ -324,6 +349,14 @@ func (t *GetWeatherForecastTool) Name() string {
 	return "get_weather_forecast"
 }
 
+func (t *GetWeatherForecastTool) Source() string {
+	return "WeatherAPI"
+}
+
+func (t *GetWeatherForecastTool) TTL() time.Duration {
+	return 3 * time.Hour
+}
+
 func (t *GetWeatherForecastTool) Description() string {
 	return "Get forecast for the given location"
 }
@@ -332,56 +365,19 @@ func (t *GetWeatherForecastTool) Definition() openai.ChatCompletionToolUnionPara
 	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
 		Name:        t.Name(),
 		Description: openai.String(t.Description()),
-		Parameters: openai.FunctionParameters{
-			"type": "object",
-			"properties": map[string]any{
-				"location": map[string]string{
-					"type": "string",
-				},
-				"days": map[string]any{
-					"type":    "integer",
-					"minimum": 1,
-					"maximum": 7,
-				},
-			},
-			"required": []string{"location"},
-		},
+		Parameters:  Schema[forecastArgs](),
 	})
 }
 
 func (t *GetWeatherForecastTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
-	var payload struct {
-		Location string `json:"location"`
-		Days     int    `json:"days"`
-	}
-	if err := json.Unmarshal(args, &payload); err != nil {
-		return "", fmt.Errorf("failed to parse tool call arguments: %w", err)
+	payload, err := DecodeArgs[forecastArgs](args)
+	if err != nil {
+		return "", err
 	}
 
-	resolvedLocation := strings.TrimSpace(payload.Location)
+	resolvedLocation := resolveLocation(t.conv, payload.Location)
 	if resolvedLocation == "" {
-		for i := len(t.conv.Messages) - 1; i >= 0; i-- {
-			if t.conv.Messages[i].Role == model.RoleUser {
-				// try to catch phrases like "forecast for X" or "X forecast"
-				re := regexp.MustCompile(`(?i)(?:forecast\s+(?:for\s+)?)?in\s+([^?.!]+)|(?i)([^?.!]+)\s+forecast`)
-				if m := re.FindStringSubmatch(t.conv.Messages[i].Content); len(m) >= 2 {
-					cand := strings.TrimSpace(m[1])
-					if cand == "" && len(m) >= 3 {
-						cand = strings.TrimSpace(m[2])
-					}
-					if cand != "" {
-						resolvedLocation = cand
-						break
-					}
-				}
-			}
-		}
-	}
-	if resolvedLocation == "" {
-		resolvedLocation = os.Getenv("WEATHER_DEFAULT_LOCATION")
-	}
-	if resolvedLocation == "" {
-		return "", fmt.Errorf("forecast lookup failed: please provide a location (e.g., '3-day forecast for Barcelona')")
+		return NeedsClarification("location", "Which city or region would you like the forecast for?"), nil
 	}
 
 	days := payload.Days
@@ -402,14 +398,22 @@ func (t *GetWeatherForecastTool) Execute(ctx context.Context, args json.RawMessa
 		days = 7
 	}
 
+	place, clarification, err := locate(ctx, t.conv, t.geocoder, "location", resolvedLocation)
+	if err != nil {
+		return "", fmt.Errorf("forecast lookup failed: %w", err)
+	}
+	if clarification != "" {
+		return clarification, nil
+	}
+
 	apiKey := os.Getenv("WEATHER_API_KEY")
-	fds, err := FetchForecast(ctx, t.httpClient, apiKey, resolvedLocation, days)
+	fds, err := FetchForecast(ctx, t.httpClient, apiKey, placeQuery(place), days)
 	if err != nil {
 		return "", fmt.Errorf("forecast lookup failed: %w", err)
 	}
 	// Build a concise multi-line summary
 	lines := make([]string, 0, len(fds)+1)
-	lines = append(lines, fmt.Sprintf("%s forecast (%d day%s):", resolvedLocation, len(fds), map[bool]string{true: "s", false: ""}[len(fds) != 1]))
+	lines = append(lines, fmt.Sprintf("%s forecast (%d day%s):", place.Name, len(fds), map[bool]string{true: "s", false: ""}[len(fds) != 1]))
 	for _, d := range fds {
 		lines = append(lines, fmt.Sprintf("%s: %s, %.0f–%.0f°C, rain %d%%", d.Date, d.Condition, d.MinC, d.MaxC, d.ChanceOfRain))
 	}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+)
+
+func TestResolveLocation(t *testing.T) {
+	t.Setenv("WEATHER_DEFAULT_LOCATION", "")
+
+	conv := &model.Conversation{}
+	if got := resolveLocation(conv, "  "); got != "" {
+		t.Errorf("resolveLocation() without any location = %q, want empty", got)
+	}
+
+	conv.Entities.Location = "Lisbon"
+	if got := resolveLocation(conv, ""); got != "Lisbon" {
+		t.Errorf("resolveLocation() = %q, want the last location %q", got, "Lisbon")
+	}
+	if got := resolveLocation(conv, " Porto "); got != "Porto" {
+		t.Errorf("resolveLocation() = %q, want the given location %q", got, "Porto")
+	}
+
+	t.Setenv("WEATHER_DEFAULT_LOCATION", "Barcelona")
+	if got := resolveLocation(&model.Conversation{}, ""); got != "Barcelona" {
+		t.Errorf("resolveLocation() = %q, want the default location %q", got, "Barcelona")
+	}
+}
+
+func TestGetWeatherTool_NeedsClarification(t *testing.T) {
+	t.Setenv("WEATHER_DEFAULT_LOCATION", "")
+
+	conv := &model.Conversation{
+		Messages: []*model.Message{{Role: model.RoleUser, Content: "What's the weather in there?"}},
+	}
+
+	for _, tool := range []Tool{NewGetWeatherTool(conv), NewGetWeatherForecastTool(conv)} {
+		result, err := tool.Execute(context.Background(), []byte(`{}`))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tool.Name(), err)
+		}
+
+		c, ok := ParseClarification(result)
+		if !ok {
+			t.Fatalf("%s: result %q is not a clarification", tool.Name(), result)
+		}
+		if c.Missing != "location" || c.Question == "" {
+			t.Errorf("%s: clarification = %+v, want a question for the location", tool.Name(), c)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Package vcr records the calls of tools to third-party APIs into cassettes, and
+// replays them, so that weather and flight behaviors can be reproduced without API keys
+// or network access. Credentials are redacted before anything is written.
+package vcr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode is how a cassette handles requests.
+type Mode string
+
+const (
+	// ModeReplay answers requests with the recorded responses, failing on the others.
+	ModeReplay Mode = "replay"
+	// ModeRecord sends requests to the APIs and records them with their responses.
+	ModeRecord Mode = "record"
+)
+
+// Redacted replaces the credentials of recorded requests and responses.
+const Redacted = "REDACTED"
+
+// ErrNoInteraction is returned replaying a request that wasn't recorded.
+var ErrNoInteraction = errors.New("vcr: no recorded interaction")
+
+// ModeFromEnv returns the mode of VCR_MODE, replay by default.
+func ModeFromEnv() (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(os.Getenv("VCR_MODE")))); m {
+	case "":
+		return ModeReplay, nil
+	case ModeReplay, ModeRecord:
+		return m, nil
+	default:
+		return "", fmt.Errorf("VCR_MODE must be %s or %s, got %q", ModeReplay, ModeRecord, m)
+	}
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Its headers are left out, as they mostly carry
+// credentials.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// key identifies the recorded interactions a request matches.
+func (r Request) key() string {
+	return r.Method + " " + r.URL + "\n" + r.Body
+}
+
+// Cassette is an http.RoundTripper recording interactions to a JSON file, or replaying
+// them from it. Identical requests are replayed in the order they were recorded, the
+// last response answering any further ones.
+type Cassette struct {
+	path string
+	mode Mode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     map[int]bool
+}
+
+// Open opens the cassette at path: in replay mode it must exist, and in record mode it
+// starts empty, being written by Save.
+func Open(path string, mode Mode) (*Cassette, error) {
+	c := &Cassette{path: path, mode: mode, next: http.DefaultTransport, replayed: map[int]bool{}}
+	switch mode {
+	case ModeReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("vcr: %w", err)
+		}
+		var file struct {
+			Interactions []Interaction `json:"interactions"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("vcr: invalid cassette %s: %w", path, err)
+		}
+		c.interactions = file.Interactions
+	case ModeRecord:
+	default:
+		return nil, fmt.Errorf("vcr: unknown mode %q", mode)
+	}
+	return c, nil
+}
+
+// Mode returns the mode of the cassette.
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+// Interactions returns the interactions of the cassette.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded := Request{Method: req.Method, URL: redactURL(req.URL)}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		recorded.Body = redactBody(req.Header.Get("Content-Type"), body)
+	}
+
+	if c.mode == ModeReplay {
+		resp, ok := c.replay(recorded)
+		if !ok {
+			return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+			StatusCode:    resp.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {resp.ContentType}},
+			Body:          io.NopCloser(strings.NewReader(resp.Body)),
+			ContentLength: int64(len(resp.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	contentType := resp.Header.Get("Content-Type")
+	c.mu.Lock()
+	c.interactions = append(c.interactions, Interaction{
+		Request:  recorded,
+		Response: Response{Status: resp.StatusCode, ContentType: contentType, Body: redactBody(contentType, body)},
+	})
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// replay returns the response of the first interaction matching req not replayed yet,
+// or else of the last one matching it.
+func (c *Cassette) replay(req Request) (Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last := -1
+	for i, in := range c.interactions {
+		if in.Request.key() != req.key() {
+			continue
+		}
+		if !c.replayed[i] {
+			c.replayed[i] = true
+			return in.Response, true
+		}
+		last = i
+	}
+	if last < 0 {
+		return Response{}, false
+	}
+	return c.interactions[last].Response, true
+}
+
+// Save writes the interactions recorded to the cassette's file. It does nothing in
+// replay mode.
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+	c.mu.Lock()
+	data, err := json.MarshalIndent(map[string]any{"interactions": c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// sensitive are the names of query parameters and body fields holding credentials.
+var sensitive = map[string]bool{
+	"key":           true,
+	"api_key":       true,
+	"apikey":        true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"token":         true,
+	"client_id":     true,
+	"client_secret": true,
+	"secret":        true,
+	"password":      true,
+}
+
+// redactURL returns u with the values of its sensitive query parameters redacted, its
+// parameters sorted.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	q := u.Query()
+	for name := range q {
+		if sensitive[strings.ToLower(name)] {
+			q[name] = []string{Redacted}
+		}
+	}
+	redacted.RawQuery = q.Encode()
+	return redacted.String()
+}
+
+// redactBody returns body with the values of its sensitive fields redacted, for form
+// and JSON bodies.
+func redactBody(contentType string, body []byte) string {
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return string(body)
+		}
+		for name := range form {
+			if sensitive[strings.ToLower(name)] {
+				form[name] = []string{Redacted}
+			}
+		}
+		return form.Encode()
+	case strings.Contains(contentType, "json"):
+		var v any
+		if err := json.Unmarshal(body, &v); err != nil {
+			return string(body)
+		}
+		out, err := json.Marshal(redactJSON(v))
+		if err != nil {
+			return string(body)
+		}
+		return string(out)
+	}
+	return string(body)
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for name, value := range v {
+			if _, ok := value.(string); ok && sensitive[strings.ToLower(name)] {
+				v[name] = Redacted
+			} else {
+				v[name] = redactJSON(value)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return v
+}
+
+type transportKey struct{}
+
+// WithTransport makes the requests of the clients using Transport go through rt, like
+// a cassette, when made with the returned context.
+func WithTransport(ctx context.Context, rt http.RoundTripper) context.Context {
+	return context.WithValue(ctx, transportKey{}, rt)
+}
+
+// Transport sends requests through the transport of their context, see WithTransport,
+// or else through http.DefaultTransport. The clients of third-party APIs use it.
+var Transport http.RoundTripper = contextTransport{}
+
+type contextTransport struct{}
+
+func (contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt, ok := req.Context().Value(transportKey{}).(http.RoundTripper); ok {
+		return rt.RoundTrip(req)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
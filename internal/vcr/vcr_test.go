@@ -0,0 +1,115 @@
+package vcr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassette(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/token" {
+			w.Write([]byte(`{"access_token": "live-token", "expires_in": 1799}`))
+			return
+		}
+		w.Write([]byte(`{"temp_c": ` + strings.Repeat("1", calls) + `}`))
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "cassettes", "weather.json")
+
+	get := func(t *testing.T, rt http.RoundTripper, target string) string {
+		t.Helper()
+		client := &http.Client{Transport: Transport}
+		req, _ := http.NewRequestWithContext(WithTransport(context.Background(), rt), http.MethodGet, target, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", target, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	// Recording
+	rec, err := Open(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get(t, rec, srv.URL+"/current?q=Lisbon&key=live-key")
+	get(t, rec, srv.URL+"/current?q=Lisbon&key=live-key")
+	resp, err := (&http.Client{Transport: rec}).PostForm(srv.URL+"/token", url.Values{"grant_type": {"client_credentials"}, "client_secret": {"live-secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"live-key", "live-secret", "live-token"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette records %q:\n%s", secret, data)
+		}
+	}
+
+	// Replaying, with other keys
+	play, err := Open(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := calls
+	if got := get(t, play, srv.URL+"/current?key=other-key&q=Lisbon"); got != `{"temp_c":1}` {
+		t.Errorf("first replay = %s", got)
+	}
+	if got := get(t, play, srv.URL+"/current?key=other-key&q=Lisbon"); got != `{"temp_c":11}` {
+		t.Errorf("second replay = %s", got)
+	}
+	// Further identical requests get the last response
+	if got := get(t, play, srv.URL+"/current?key=other-key&q=Lisbon"); got != `{"temp_c":11}` {
+		t.Errorf("third replay = %s", got)
+	}
+	if calls != before {
+		t.Errorf("replaying called the API %d times", calls-before)
+	}
+
+	_, err = (&http.Client{Transport: play}).Get(srv.URL + "/current?q=Porto")
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("unrecorded request: error = %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.json"), ModeReplay); err == nil {
+		t.Error("Open() of a missing cassette in replay mode succeeded")
+	}
+	if _, err := Open("cassette.json", "rewind"); err == nil {
+		t.Error("Open() with an unknown mode succeeded")
+	}
+}
+
+func TestModeFromEnv(t *testing.T) {
+	for value, want := range map[string]Mode{"": ModeReplay, "record": ModeRecord, " Replay ": ModeReplay} {
+		t.Setenv("VCR_MODE", value)
+		if got, err := ModeFromEnv(); err != nil || got != want {
+			t.Errorf("ModeFromEnv() with %q = %q, %v", value, got, err)
+		}
+	}
+	t.Setenv("VCR_MODE", "live")
+	if _, err := ModeFromEnv(); err == nil {
+		t.Error("ModeFromEnv() accepted live")
+	}
+}
@@ -0,0 +1,127 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/relay"
+)
+
+// Message is a message of a user to the number.
+type Message struct {
+	ID   string
+	From string
+	// Name is the name of the user's WhatsApp profile
+	Name string
+	// Type is "text" for the messages answered, others being images, audio, locations…
+	Type string
+	Text string
+	At   time.Time
+}
+
+// Bridge answers the messages of WhatsApp users, each in their own conversation as the
+// user "whatsapp:<wa id>".
+type Bridge struct {
+	client   *Client
+	chat     relay.Chat
+	threads  ThreadStore
+	tenantID string
+	now      func() time.Time
+
+	// locks serializes the messages of each user, which continue the same conversation
+	locks sync.Map
+	wg    sync.WaitGroup
+}
+
+func NewBridge(client *Client, chat relay.Chat, threads ThreadStore, tenantID string) *Bridge {
+	return &Bridge{client: client, chat: chat, threads: threads, tenantID: tenantID, now: time.Now}
+}
+
+// Dispatch answers msg in the background, as webhook deliveries must be acknowledged
+// at once.
+func (b *Bridge) Dispatch(msg Message) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), relay.ReplyTimeout)
+		defer cancel()
+		if err := b.Handle(ctx, msg); err != nil {
+			slog.ErrorContext(ctx, "Failed to answer WhatsApp message", "tenant_id", b.tenantID, "message_id", msg.ID, "error", err)
+			_ = b.client.SendText(ctx, msg.From, "Sorry, something went wrong. Please try again in a moment.")
+		}
+	}()
+}
+
+// Wait blocks until the messages dispatched are answered.
+func (b *Bridge) Wait() {
+	b.wg.Wait()
+}
+
+// Handle answers msg in the thread of its user, starting a conversation for their
+// first message or after "/newtrip". Messages delivered twice are answered once.
+func (b *Bridge) Handle(ctx context.Context, msg Message) error {
+	mu, _ := b.locks.LoadOrStore(msg.From, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	thread, err := b.threads.DescribeThread(ctx, msg.From)
+	if err != nil {
+		return fmt.Errorf("failed to get thread: %w", err)
+	}
+	if thread == nil {
+		thread = &Thread{WaID: msg.From}
+	}
+	if thread.LastMessageID == msg.ID {
+		return nil
+	}
+	thread.LastMessageID = msg.ID
+	if msg.Name != "" {
+		thread.Name = msg.Name
+	}
+	thread.LastMessageAt = msg.At
+	if thread.LastMessageAt.IsZero() {
+		thread.LastMessageAt = b.now()
+	}
+	thread.UpdatedAt = b.now()
+
+	text := strings.TrimSpace(msg.Text)
+	switch {
+	case msg.Type != "text" || text == "":
+		if err := b.threads.SaveThread(ctx, thread); err != nil {
+			return fmt.Errorf("failed to save thread: %w", err)
+		}
+		return b.client.SendText(ctx, msg.From, "I can only read text messages for now. Tell me about your trip in words!")
+	case strings.EqualFold(text, "/newtrip"):
+		thread.ConversationID = ""
+		if err := b.threads.SaveThread(ctx, thread); err != nil {
+			return fmt.Errorf("failed to save thread: %w", err)
+		}
+		return b.client.SendText(ctx, msg.From, "Where to next? Tell me about the trip: destination, dates and who's coming.")
+	}
+
+	ctx = auth.WithUserID(ctx, UserPrefix+msg.From)
+	if b.tenantID != "" {
+		ctx = auth.WithTenantID(ctx, b.tenantID)
+	}
+
+	conversationID, reply, err := relay.Send(ctx, b.chat, thread.ConversationID, text, msg.ID)
+	if err != nil {
+		return err
+	}
+	thread.ConversationID = conversationID
+	if err := b.threads.SaveThread(ctx, thread); err != nil {
+		return fmt.Errorf("failed to save thread: %w", err)
+	}
+
+	for _, part := range relay.Split(reply.GetReply(), MaxText, relay.Chars) {
+		if err := b.client.SendText(ctx, msg.From, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/notify"
+	"github.com/acai-travel/tech-challenge/internal/profile"
+)
+
+// Channel sends alerts to the WhatsApp of users: as a message while their reply window
+// is open, and as the template of the event type afterwards.
+type Channel struct {
+	client    *Client
+	threads   ThreadStore
+	templates map[notify.EventType]Template
+	now       func() time.Time
+}
+
+func NewChannel(cfg *Config, client *Client, threads ThreadStore) *Channel {
+	return &Channel{client: client, threads: threads, templates: cfg.Templates, now: time.Now}
+}
+
+func (c *Channel) Name() string {
+	return notify.ChannelWhatsApp
+}
+
+func (c *Channel) Send(ctx context.Context, p *profile.Profile, evt notify.Event) error {
+	waID, ok := recipient(p)
+	if !ok {
+		return notify.ErrNotConfigured
+	}
+
+	thread, err := c.threads.DescribeThread(ctx, waID)
+	if err != nil {
+		return fmt.Errorf("failed to get thread: %w", err)
+	}
+	if thread != nil && c.now().Sub(thread.LastMessageAt) < ReplyWindow {
+		err := c.client.SendText(ctx, waID, fmt.Sprintf("*%s*\n%s", evt.Subject(), evt.Text()))
+		if !errors.Is(err, ErrWindowClosed) {
+			return err
+		}
+	}
+
+	tmpl, ok := c.templates[evt.Type]
+	if !ok {
+		return fmt.Errorf("no whatsapp template for %s alerts, which can't be sent outside the reply window", evt.Type)
+	}
+	return c.client.SendTemplate(ctx, waID, tmpl, evt.Text())
+}
+
+// recipient returns the WhatsApp ID alerts of the user of p are sent to: the number of
+// their profile, or the number they write from.
+func recipient(p *profile.Profile) (string, bool) {
+	if p.WhatsAppNumber != "" {
+		return NormalizeNumber(p.WhatsAppNumber)
+	}
+	if waID, ok := strings.CutPrefix(p.UserID, UserPrefix); ok {
+		return waID, true
+	}
+	return "", false
+}
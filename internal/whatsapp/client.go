@@ -0,0 +1,103 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrWindowClosed is returned sending a free-form message to a user whose reply window
+// has closed.
+var ErrWindowClosed = errors.New("the 24-hour reply window of the user is closed")
+
+// codeWindowClosed is the error code of the Cloud API for messages sent outside the
+// reply window.
+const codeWindowClosed = 131047
+
+// Client sends messages from the number of a tenant.
+type Client struct {
+	cfg  *Config
+	http *http.Client
+}
+
+func NewClient(cfg *Config) *Client {
+	return &Client{cfg: cfg, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// SendText sends text to the user waID.
+func (c *Client) SendText(ctx context.Context, waID, text string) error {
+	return c.send(ctx, map[string]any{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                waID,
+		"type":              "text",
+		"text":              map[string]any{"body": text, "preview_url": false},
+	})
+}
+
+// SendTemplate sends the template tmpl to the user waID, with text as the parameter of
+// its body. Templates can be sent outside the reply window.
+func (c *Client) SendTemplate(ctx context.Context, waID string, tmpl Template, text string) error {
+	return c.send(ctx, map[string]any{
+		"messaging_product": "whatsapp",
+		"to":                waID,
+		"type":              "template",
+		"template": map[string]any{
+			"name":     tmpl.Name,
+			"language": map[string]string{"code": tmpl.Language},
+			"components": []map[string]any{{
+				"type":       "body",
+				"parameters": []map[string]string{{"type": "text", "text": templateParameter(text)}},
+			}},
+		},
+	})
+}
+
+// templateParameter returns text as a template parameter, which can't have line breaks,
+// tabs or more than four consecutive spaces.
+func templateParameter(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func (c *Client) send(ctx context.Context, message map[string]any) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	apiURL := c.cfg.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(apiURL, "/")+"/"+c.cfg.PhoneNumberID+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.cfg.AccessToken)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("whatsapp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var out struct {
+		Error struct {
+			Message string `json:"message"`
+			Code    int    `json:"code"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	if out.Error.Code == codeWindowClosed {
+		return ErrWindowClosed
+	}
+	return fmt.Errorf("whatsapp error: status %d, code %d: %s", resp.StatusCode, out.Error.Code, out.Error.Message)
+}
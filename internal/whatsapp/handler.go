@@ -0,0 +1,130 @@
+package whatsapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPayload bounds the size of webhook deliveries.
+const maxPayload = 1 << 20
+
+// payload is a webhook delivery of the Cloud API.
+type payload struct {
+	Entry []struct {
+		Changes []struct {
+			Field string `json:"field"`
+			Value struct {
+				Metadata struct {
+					PhoneNumberID string `json:"phone_number_id"`
+				} `json:"metadata"`
+				Contacts []struct {
+					WaID    string `json:"wa_id"`
+					Profile struct {
+						Name string `json:"name"`
+					} `json:"profile"`
+				} `json:"contacts"`
+				Messages []struct {
+					ID        string `json:"id"`
+					From      string `json:"from"`
+					Timestamp string `json:"timestamp"`
+					Type      string `json:"type"`
+					Text      struct {
+						Body string `json:"body"`
+					} `json:"text"`
+				} `json:"messages"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+// messages returns the messages of p to the number phoneNumberID. Status updates of
+// the messages sent are ignored.
+func (p *payload) messages(phoneNumberID string) []Message {
+	var out []Message
+	for _, e := range p.Entry {
+		for _, c := range e.Changes {
+			if c.Field != "messages" || c.Value.Metadata.PhoneNumberID != phoneNumberID {
+				continue
+			}
+			names := make(map[string]string, len(c.Value.Contacts))
+			for _, contact := range c.Value.Contacts {
+				names[contact.WaID] = contact.Profile.Name
+			}
+			for _, m := range c.Value.Messages {
+				msg := Message{ID: m.ID, From: m.From, Name: names[m.From], Type: m.Type, Text: m.Text.Body}
+				if sec, err := strconv.ParseInt(m.Timestamp, 10, 64); err == nil {
+					msg.At = time.Unix(sec, 0)
+				}
+				out = append(out, msg)
+			}
+		}
+	}
+	return out
+}
+
+// Handler is the webhook of the number of cfg: it answers the subscription challenge
+// of the Cloud API, and dispatches the messages of deliveries signed with the app
+// secret to bridge.
+func Handler(cfg *Config, bridge *Bridge) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			q := r.URL.Query()
+			if q.Get("hub.mode") != "subscribe" || subtle.ConstantTimeCompare([]byte(q.Get("hub.verify_token")), []byte(cfg.VerifyToken)) != 1 {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = io.WriteString(w, q.Get("hub.challenge"))
+		case http.MethodPost:
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayload))
+			if err != nil {
+				http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			if !validSignature(cfg.AppSecret, body, r.Header.Get("X-Hub-Signature-256")) {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			var p payload
+			if err := json.Unmarshal(body, &p); err != nil {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			for _, msg := range p.messages(cfg.PhoneNumberID) {
+				slog.InfoContext(r.Context(), "Received WhatsApp message", "message_id", msg.ID, "type", msg.Type)
+				bridge.Dispatch(msg)
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// validSignature reports whether signature, "sha256=<hex>", is the HMAC of body with the
+// app secret.
+func validSignature(secret string, body []byte, signature string) bool {
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
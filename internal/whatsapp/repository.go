@@ -0,0 +1,60 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	tracerName       = "github.com/acai-travel/tech-challenge/internal/whatsapp"
+	threadCollection = "whatsapp_threads"
+)
+
+type Repository struct {
+	conn *mongo.Database
+}
+
+func NewRepository(conn *mongo.Database) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) DescribeThread(ctx context.Context, waID string) (*Thread, error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.DescribeThread")
+	defer span.End()
+
+	var t Thread
+	err := r.conn.Collection(threadCollection).FindOne(ctx, bson.M{"_id": waID}).Decode(&t)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.SetStatus(codes.Ok, "no thread")
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to describe thread")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "thread described")
+	return &t, nil
+}
+
+func (r *Repository) SaveThread(ctx context.Context, t *Thread) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Repository.SaveThread")
+	defer span.End()
+
+	_, err := r.conn.Collection(threadCollection).ReplaceOne(ctx, bson.M{"_id": t.WaID}, t, options.Replace().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to save thread")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "thread saved")
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package whatsapp connects WhatsApp Business numbers through the WhatsApp Cloud API:
+// the messages of each WhatsApp user are answered in a conversation of their own, and
+// alerts reach them as template messages once the 24-hour window WhatsApp allows free
+// replies in has closed.
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/notify"
+)
+
+// DefaultAPIURL is the URL of the Graph API the Cloud API is part of.
+const DefaultAPIURL = "https://graph.facebook.com/v21.0"
+
+// WebhookPath is where the Cloud API delivers messages, under the path of the tenant.
+const WebhookPath = "/whatsapp/webhook"
+
+// MaxText is the longest body of a text message, in characters.
+const MaxText = 4096
+
+// ReplyWindow is how long after a user's last message free-form messages can be sent
+// to them; only templates can be sent afterwards.
+const ReplyWindow = 24 * time.Hour
+
+// UserPrefix prefixes the WhatsApp ID of users to make their user IDs.
+const UserPrefix = "whatsapp:"
+
+// Config is the WhatsApp Business number of a tenant, from its app in the Meta
+// developer dashboard.
+type Config struct {
+	PhoneNumberID string `json:"phone_number_id"`
+	// AccessToken is a system user token with the whatsapp_business_messaging permission
+	AccessToken string `json:"access_token"`
+	// AppSecret signs the webhook deliveries of the app
+	AppSecret string `json:"app_secret"`
+	// VerifyToken is the token entered when subscribing the webhook
+	VerifyToken string `json:"verify_token"`
+	// Templates are the approved templates alerts are sent with outside the reply
+	// window, by event type
+	Templates map[notify.EventType]Template `json:"templates,omitempty"`
+
+	APIURL string `json:"-"`
+}
+
+// Template is a message template approved by WhatsApp. Its body has a single
+// parameter, {{1}}, filled with the text of the alert.
+type Template struct {
+	Name     string `json:"name"`
+	Language string `json:"language"`
+}
+
+// ConfigFromEnv returns the number of the default tenant, configured by
+// WHATSAPP_PHONE_NUMBER_ID, WHATSAPP_ACCESS_TOKEN, WHATSAPP_APP_SECRET,
+// WHATSAPP_VERIFY_TOKEN and WHATSAPP_TEMPLATES, a JSON object of templates by event
+// type; nil without a phone number ID.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		PhoneNumberID: os.Getenv("WHATSAPP_PHONE_NUMBER_ID"),
+		AccessToken:   os.Getenv("WHATSAPP_ACCESS_TOKEN"),
+		AppSecret:     os.Getenv("WHATSAPP_APP_SECRET"),
+		VerifyToken:   os.Getenv("WHATSAPP_VERIFY_TOKEN"),
+	}
+	if cfg.PhoneNumberID == "" {
+		return nil, nil
+	}
+	if v := os.Getenv("WHATSAPP_TEMPLATES"); v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.Templates); err != nil {
+			return nil, fmt.Errorf("invalid WHATSAPP_TEMPLATES: %w", err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate reports whether the configuration is usable.
+func (c *Config) Validate() error {
+	if c.PhoneNumberID == "" || c.AccessToken == "" || c.AppSecret == "" || c.VerifyToken == "" {
+		return errors.New("whatsapp needs a phone_number_id, an access_token, an app_secret and a verify_token")
+	}
+	for t, tmpl := range c.Templates {
+		if !t.Alert() {
+			return fmt.Errorf("whatsapp template for %q: not an alert event type", t)
+		}
+		if tmpl.Name == "" || tmpl.Language == "" {
+			return fmt.Errorf("whatsapp template for %q needs a name and a language", t)
+		}
+	}
+	return nil
+}
+
+// Thread is the conversation with a WhatsApp user.
+type Thread struct {
+	// WaID is the WhatsApp ID of the user, their phone number in international format
+	// without the "+"
+	WaID string `bson:"_id"`
+	Name string `bson:"name,omitempty"`
+	// ConversationID is empty until the user's first message is answered
+	ConversationID string `bson:"conversation_id,omitempty"`
+	// LastMessageID is the last message answered, as deliveries are retried
+	LastMessageID string `bson:"last_message_id,omitempty"`
+	// LastMessageAt opens the reply window
+	LastMessageAt time.Time `bson:"last_message_at"`
+	UpdatedAt     time.Time `bson:"updated_at"`
+}
+
+// ThreadStore stores the threads of a number.
+type ThreadStore interface {
+	// DescribeThread returns the thread with a user, nil when they never wrote.
+	DescribeThread(ctx context.Context, waID string) (*Thread, error)
+	SaveThread(ctx context.Context, t *Thread) error
+}
+
+var waIDPattern = regexp.MustCompile(`^[1-9][0-9]{7,14}$`)
+
+// NormalizeNumber returns a phone number in international format as a WhatsApp ID, its
+// digits without the "+", or false if it isn't one.
+func NormalizeNumber(number string) (string, bool) {
+	digits := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '(' || r == ')' || r == '.' {
+			return -1
+		}
+		return r
+	}, strings.TrimPrefix(strings.TrimSpace(number), "+"))
+	return digits, waIDPattern.MatchString(digits)
+}
@@ -0,0 +1,291 @@
+package whatsapp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/auth"
+	"github.com/acai-travel/tech-challenge/internal/notify"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/profile"
+	"github.com/twitchtv/twirp"
+)
+
+// fakeGraph records the messages sent through the Cloud API. Free-form messages fail
+// once closed is set, like outside the reply window.
+type fakeGraph struct {
+	mu     sync.Mutex
+	sent   []map[string]any
+	closed bool
+}
+
+func (f *fakeGraph) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/1234/messages" || r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var msg map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&msg)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed && msg["type"] == "text" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "Re-engagement message", "code": 131047}}`))
+		return
+	}
+	f.sent = append(f.sent, msg)
+	w.Write([]byte(`{"messages": [{"id": "wamid.out"}]}`))
+}
+
+// texts returns the bodies of the text messages sent, and the names of the templates.
+func (f *fakeGraph) texts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, m := range f.sent {
+		switch m["type"] {
+		case "text":
+			out = append(out, m["text"].(map[string]any)["body"].(string))
+		case "template":
+			out = append(out, "template:"+m["template"].(map[string]any)["name"].(string))
+		}
+	}
+	return out
+}
+
+// fakeChat answers every message with the same reply.
+type fakeChat struct {
+	conversations map[string]bool
+	requests      []string
+	userID        string
+}
+
+func (f *fakeChat) StartConversation(ctx context.Context, req *pb.StartConversationRequest) (*pb.StartConversationResponse, error) {
+	f.userID = auth.UserID(ctx)
+	f.requests = append(f.requests, "start:"+req.GetMessage())
+	f.conversations["conv-2"] = true
+	return &pb.StartConversationResponse{ConversationId: "conv-2", Reply: "Lisbon is lovely in October."}, nil
+}
+
+func (f *fakeChat) ContinueConversation(ctx context.Context, req *pb.ContinueConversationRequest) (*pb.ContinueConversationResponse, error) {
+	if !f.conversations[req.GetConversationId()] {
+		return nil, twirp.NotFoundError("conversation not found")
+	}
+	f.requests = append(f.requests, "continue:"+req.GetMessage())
+	return &pb.ContinueConversationResponse{Reply: "Sunny, 22°C."}, nil
+}
+
+// memoryThreads stores threads in memory.
+type memoryThreads struct {
+	mu      sync.Mutex
+	threads map[string]Thread
+}
+
+func (m *memoryThreads) DescribeThread(_ context.Context, waID string) (*Thread, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.threads[waID]
+	if !ok {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (m *memoryThreads) SaveThread(_ context.Context, t *Thread) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.threads[t.WaID] = *t
+	return nil
+}
+
+func newTestConfig(t *testing.T, graph *fakeGraph) *Config {
+	t.Helper()
+	srv := httptest.NewServer(graph)
+	t.Cleanup(srv.Close)
+	return &Config{
+		PhoneNumberID: "1234",
+		AccessToken:   "token",
+		AppSecret:     "app-secret",
+		VerifyToken:   "verify-me",
+		Templates:     map[notify.EventType]Template{notify.EventItineraryUpdated: {Name: "itinerary_update", Language: "en_US"}},
+		APIURL:        srv.URL,
+	}
+}
+
+func TestBridge_Handle(t *testing.T) {
+	ctx := context.Background()
+	graph := &fakeGraph{}
+	cfg := newTestConfig(t, graph)
+	chat := &fakeChat{conversations: map[string]bool{}}
+	threads := &memoryThreads{threads: map[string]Thread{}}
+	bridge := NewBridge(NewClient(cfg), chat, threads, "nordic")
+
+	msg := Message{ID: "wamid.1", From: "34600123456", Name: "Ana", Type: "text", Text: "Weekend in Lisbon?", At: time.Now()}
+	if err := bridge.Handle(ctx, msg); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	// Deliveries are retried, and answered once
+	if err := bridge.Handle(ctx, msg); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if err := bridge.Handle(ctx, Message{ID: "wamid.2", From: "34600123456", Type: "text", Text: "And the weather?"}); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if err := bridge.Handle(ctx, Message{ID: "wamid.3", From: "34600123456", Type: "image"}); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	if got := strings.Join(chat.requests, ", "); got != "start:Weekend in Lisbon?, continue:And the weather?" {
+		t.Errorf("requests = %s", got)
+	}
+	if chat.userID != "whatsapp:34600123456" {
+		t.Errorf("conversation started as %q", chat.userID)
+	}
+	thread := threads.threads["34600123456"]
+	if thread.ConversationID != "conv-2" || thread.Name != "Ana" {
+		t.Errorf("thread = %+v", thread)
+	}
+	got := graph.texts()
+	if len(got) != 3 || got[0] != "Lisbon is lovely in October." || got[1] != "Sunny, 22°C." || !strings.HasPrefix(got[2], "I can only read text") {
+		t.Errorf("sent %q", got)
+	}
+
+	if err := bridge.Handle(ctx, Message{ID: "wamid.4", From: "34600123456", Type: "text", Text: "/newtrip"}); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if thread := threads.threads["34600123456"]; thread.ConversationID != "" {
+		t.Errorf("thread still on %q after /newtrip", thread.ConversationID)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	graph := &fakeGraph{}
+	cfg := newTestConfig(t, graph)
+	chat := &fakeChat{conversations: map[string]bool{}}
+	bridge := NewBridge(NewClient(cfg), chat, &memoryThreads{threads: map[string]Thread{}}, "")
+	h := Handler(cfg, bridge)
+
+	t.Run("verifies the subscription", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?hub.mode=subscribe&hub.verify_token=verify-me&hub.challenge=1158201444", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "1158201444" {
+			t.Errorf("got %d %q", rec.Code, rec.Body.String())
+		}
+
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=1", nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("wrong verify token: got %d", rec.Code)
+		}
+	})
+
+	body := `{"object": "whatsapp_business_account", "entry": [{"changes": [{"field": "messages", "value": {
+		"metadata": {"phone_number_id": "1234"},
+		"contacts": [{"wa_id": "34600123456", "profile": {"name": "Ana"}}],
+		"messages": [{"id": "wamid.1", "from": "34600123456", "timestamp": "1760000000", "type": "text", "text": {"body": "Hi"}}]
+	}}]}]}`
+
+	t.Run("rejects unsigned deliveries", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", "sha256=00")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("got %d", rec.Code)
+		}
+	})
+
+	t.Run("answers the messages of deliveries", func(t *testing.T) {
+		mac := hmac.New(sha256.New, []byte("app-secret"))
+		mac.Write([]byte(body))
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d", rec.Code)
+		}
+
+		bridge.Wait()
+		if len(chat.requests) != 1 || chat.requests[0] != "start:Hi" {
+			t.Errorf("requests = %q", chat.requests)
+		}
+	})
+}
+
+func TestChannel_Send(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC)
+	graph := &fakeGraph{}
+	cfg := newTestConfig(t, graph)
+	threads := &memoryThreads{threads: map[string]Thread{
+		"34600123456": {WaID: "34600123456", LastMessageAt: now.Add(-time.Hour)},
+		"34600999999": {WaID: "34600999999", LastMessageAt: now.Add(-48 * time.Hour)},
+	}}
+	c := NewChannel(cfg, NewClient(cfg), threads)
+	c.now = func() time.Time { return now }
+	evt := notify.NewEvent(notify.EventItineraryUpdated, "whatsapp:34600123456", map[string]any{"message": "Your BCN → LIS flight was added to the itinerary."})
+
+	// Within the reply window, alerts are messages
+	if err := c.Send(ctx, &profile.Profile{UserID: "whatsapp:34600123456"}, evt); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	// Outside, templates
+	if err := c.Send(ctx, &profile.Profile{UserID: "alice", WhatsAppNumber: "+34 600 999 999"}, evt); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	// And when WhatsApp says the window closed
+	graph.closed = true
+	if err := c.Send(ctx, &profile.Profile{UserID: "whatsapp:34600123456"}, evt); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	got := graph.texts()
+	if len(got) != 3 || got[0] != "*Your itinerary was updated*\nYour BCN → LIS flight was added to the itinerary." || got[1] != "template:itinerary_update" || got[2] != "template:itinerary_update" {
+		t.Errorf("sent %q", got)
+	}
+
+	if err := c.Send(ctx, &profile.Profile{UserID: "alice"}, evt); err != notify.ErrNotConfigured {
+		t.Errorf("Send() without a number = %v, want ErrNotConfigured", err)
+	}
+	reminder := notify.NewEvent(notify.EventReminder, "alice", nil)
+	if err := c.Send(ctx, &profile.Profile{UserID: "alice", WhatsAppNumber: "+34600999999"}, reminder); err == nil {
+		t.Error("Send() without a template outside the reply window succeeded")
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	for number, want := range map[string]string{"+34 600-123-456": "34600123456", "34600123456": "34600123456", "+1 (415) 555-0100": "14155550100"} {
+		if got, ok := NormalizeNumber(number); !ok || got != want {
+			t.Errorf("NormalizeNumber(%q) = %q, %v", number, got, ok)
+		}
+	}
+	for _, number := range []string{"", "+0034600123456", "600", "call me"} {
+		if _, ok := NormalizeNumber(number); ok {
+			t.Errorf("NormalizeNumber(%q) accepted", number)
+		}
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := Config{PhoneNumberID: "1234", AccessToken: "token", AppSecret: "secret", VerifyToken: "verify"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	cfg.Templates = map[notify.EventType]Template{notify.EventReplyReady: {Name: "reply", Language: "en"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("template of a non-alert event accepted")
+	}
+	if err := (&Config{PhoneNumberID: "1234"}).Validate(); err == nil {
+		t.Error("config without credentials accepted")
+	}
+}